@@ -4,6 +4,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"home-library/internal/app"
 	"home-library/pkg/config"
+	"os"
 	"time"
 	_ "time/tzdata"
 )
@@ -23,6 +24,14 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msgf("failed to create application")
 	}
+
+	if len(os.Args) > 1 {
+		if err := application.RunCommand(os.Args[1:]); err != nil {
+			log.Fatal().Err(err).Msgf("failed to run command %s", os.Args[1])
+		}
+		return
+	}
+
 	if err := application.Start(); err != nil {
 		log.Fatal().Err(err).Msgf("failed to start application")
 	}
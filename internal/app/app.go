@@ -2,13 +2,19 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 	"home-library/internal/server"
+	"home-library/pkg/blobstore"
 	"home-library/pkg/config"
+	"home-library/pkg/events"
+	"home-library/pkg/jobs"
+	"home-library/pkg/scheduler"
 	"home-library/pkg/storage"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -16,9 +22,14 @@ import (
 )
 
 type App struct {
-	db   *sqlx.DB
-	echo *echo.Echo
-	cfg  config.Config
+	db        *sqlx.DB
+	blobs     blobstore.Store
+	echo      *echo.Echo
+	scheduler *scheduler.Scheduler
+	queue     *jobs.Queue
+	workers   *jobs.Pool
+	events    *events.Dispatcher
+	cfg       config.Config
 }
 
 func NewApp(cfg config.Config) (*App, error) {
@@ -27,10 +38,24 @@ func NewApp(cfg config.Config) (*App, error) {
 		return nil, err
 	}
 
+	blobs, err := blobstore.New(&cfg.BlobStore)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	queue := jobs.NewQueue(db)
+	workers := jobs.NewPool(queue, cfg.Jobs)
+
 	return &App{
-		cfg:  cfg,
-		echo: server.NewEchoServer(&cfg.HTTPServer),
-		db:   db,
+		cfg:       cfg,
+		echo:      server.NewEchoServer(&cfg.HTTPServer),
+		db:        db,
+		blobs:     blobs,
+		scheduler: scheduler.New(),
+		queue:     queue,
+		workers:   workers,
+		events:    events.NewDispatcher(db, queue, workers),
 	}, nil
 }
 
@@ -38,18 +63,38 @@ func (app *App) Start() error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	// Start returns once the shutdown is complete, not as soon as the
+	// server stops accepting requests.
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		<-quit
 
 		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(app.cfg.Application.ShutdownTimeout)*time.Second)
 		defer cancel()
 
+		// Running jobs drain while the server finishes its requests.
+		drained := make(chan error, 1)
+		go func() { drained <- app.workers.Stop(ctx) }()
+
 		if err := app.echo.Shutdown(ctx); err != nil {
 			log.Error().Err(err).Msg("failed to shutdown server")
 		} else {
 			log.Info().Msg("server was successfully shutdown")
 		}
 
+		if err := <-drained; err != nil {
+			log.Error().Err(err).Msg("failed to drain background jobs")
+		} else {
+			log.Info().Msg("background jobs were successfully drained")
+		}
+
+		if err := app.scheduler.Stop(ctx); err != nil {
+			log.Error().Err(err).Msg("failed to stop scheduled jobs")
+		} else {
+			log.Info().Msg("scheduled jobs were successfully stopped")
+		}
+
 		if err := app.db.Close(); err != nil {
 			log.Error().Err(err).Msg("failed to close database connection")
 		} else {
@@ -60,11 +105,15 @@ func (app *App) Start() error {
 	if err := app.startService(); err != nil {
 		return err
 	}
+	app.scheduler.Start()
+	app.workers.Start()
 
 	address := fmt.Sprintf("%s:%d", app.cfg.HTTPServer.Host, app.cfg.HTTPServer.Port)
-	if err := app.echo.StartTLS(address, app.cfg.SSL.CertFile, app.cfg.SSL.KeyFile); err != nil {
+	err := app.echo.StartTLS(address, app.cfg.SSL.CertFile, app.cfg.SSL.KeyFile)
+	if !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 
+	<-done
 	return nil
 }
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	archiveRepository "home-library/internal/services/archive/repository"
+	archiveUseCases "home-library/internal/services/archive/usecases"
+	householdRepository "home-library/internal/services/household/repository"
+	wishlistRepository "home-library/internal/services/wishlist/repository"
+	"home-library/migrations"
+	"home-library/pkg/transaction"
+	"os"
+	"path/filepath"
+)
+
+// RunCommand runs a one-off maintenance command instead of the HTTP server.
+func (app *App) RunCommand(args []string) error {
+	defer func() {
+		if err := app.db.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close database connection")
+		}
+	}()
+
+	switch args[0] {
+	case "export":
+		return app.exportCommand(args[1:])
+	case "import":
+		return app.importCommand(args[1:])
+	case "migrate":
+		return app.migrateCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q, expected export, import or migrate", args[0])
+	}
+}
+
+// migrateCommand runs a goose command, "up" when none is given, against the
+// migrations built into the binary.
+func (app *App) migrateCommand(args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	return migrations.Run(context.Background(), app.db.DB, app.cfg.Application.PhoneRegion, command, args...)
+}
+
+func (app *App) archiveUseCase() archiveUseCases.UseCase {
+	return archiveUseCases.NewUseCase(
+		archiveRepository.NewRepository(app.db),
+		householdRepository.NewRepository(app.db),
+		wishlistRepository.NewRepository(app.db),
+		app.blobs,
+		transaction.NewManager(app.db),
+	)
+}
+
+func (app *App) exportCommand(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	household := flags.String("household", "", "ID of the household to export")
+	output := flags.String("out", "-", "archive path, - for stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	householdID, err := uuid.Parse(*household)
+	if err != nil {
+		return fmt.Errorf("invalid -household: %w", err)
+	}
+
+	if *output == "-" {
+		if err := app.archiveUseCase().ExportHousehold(context.Background(), householdID, os.Stdout); err != nil {
+			return err
+		}
+	} else if err := app.exportFile(householdID, *output); err != nil {
+		return err
+	}
+
+	log.Info().Str("household_id", householdID.String()).Str("out", *output).Msg("household was successfully exported")
+	return nil
+}
+
+// exportFile writes the archive next to path and renames it into place once
+// complete, so a failed export never leaves a truncated archive at path.
+func (app *App) exportFile(householdID uuid.UUID, path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := app.archiveUseCase().ExportHousehold(context.Background(), householdID, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (app *App) importCommand(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	household := flags.String("household", "", "ID of the household to import into")
+	input := flags.String("in", "", "archive path")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	householdID, err := uuid.Parse(*household)
+	if err != nil {
+		return fmt.Errorf("invalid -household: %w", err)
+	}
+
+	f, err := os.Open(*input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	result, err := app.archiveUseCase().ImportHousehold(context.Background(), householdID, f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("household_id", householdID.String()).
+		Int("wishlist_items", result.WishlistItems).
+		Int("books", result.Books).
+		Int("copies", result.Copies).
+		Int("loans", result.Loans).
+		Int("readings", result.Readings).
+		Int("ebooks", result.Ebooks).
+		Int("skipped_items", result.SkippedItems).
+		Msg("household was successfully imported")
+	return nil
+}
@@ -1,12 +1,115 @@
 package app
 
 import (
+	"context"
 	"github.com/labstack/echo/v4"
+	archiveHTTPDelivery "home-library/internal/services/archive/delivery/http/v1"
+	archiveRepository "home-library/internal/services/archive/repository"
+	archiveUseCases "home-library/internal/services/archive/usecases"
+	auditHTTPDelivery "home-library/internal/services/audit/delivery/http/v1"
+	auditRepository "home-library/internal/services/audit/repository"
+	auditUseCases "home-library/internal/services/audit/usecases"
+	bookImportHTTPDelivery "home-library/internal/services/bookimport/delivery/http/v1"
+	bookImportRepository "home-library/internal/services/bookimport/repository"
+	bookImportUseCases "home-library/internal/services/bookimport/usecases"
+	catalogHTTPDelivery "home-library/internal/services/catalog/delivery/http/v1"
+	catalogRepository "home-library/internal/services/catalog/repository"
+	catalogUseCases "home-library/internal/services/catalog/usecases"
+	coverHTTPDelivery "home-library/internal/services/cover/delivery/http/v1"
+	coverRepository "home-library/internal/services/cover/repository"
+	coverUseCases "home-library/internal/services/cover/usecases"
+	duplicateHTTPDelivery "home-library/internal/services/duplicate/delivery/http/v1"
+	duplicateRepository "home-library/internal/services/duplicate/repository"
+	duplicateUseCases "home-library/internal/services/duplicate/usecases"
+	ebookHTTPDelivery "home-library/internal/services/ebook/delivery/http/v1"
+	ebookRepository "home-library/internal/services/ebook/repository"
+	ebookUseCases "home-library/internal/services/ebook/usecases"
+	eventHTTPDelivery "home-library/internal/services/event/delivery/http/v1"
+	eventRepository "home-library/internal/services/event/repository"
+	eventUseCases "home-library/internal/services/event/usecases"
+	friendHTTPDelivery "home-library/internal/services/friend/delivery/http/v1"
+	friendRepository "home-library/internal/services/friend/repository"
+	friendUseCases "home-library/internal/services/friend/usecases"
+	goalHTTPDelivery "home-library/internal/services/goal/delivery/http/v1"
+	goalRepository "home-library/internal/services/goal/repository"
+	goalUseCases "home-library/internal/services/goal/usecases"
+	householdHTTPDelivery "home-library/internal/services/household/delivery/http/v1"
+	householdRepository "home-library/internal/services/household/repository"
+	householdUseCases "home-library/internal/services/household/usecases"
+	jobHTTPDelivery "home-library/internal/services/job/delivery/http/v1"
+	jobUseCases "home-library/internal/services/job/usecases"
+	labelHTTPDelivery "home-library/internal/services/label/delivery/http/v1"
+	labelRepository "home-library/internal/services/label/repository"
+	labelUseCases "home-library/internal/services/label/usecases"
+	loanHTTPDelivery "home-library/internal/services/loan/delivery/http/v1"
+	loanRepository "home-library/internal/services/loan/repository"
+	loanUseCases "home-library/internal/services/loan/usecases"
+	notificationHTTPDelivery "home-library/internal/services/notification/delivery/http/v1"
+	notificationRepository "home-library/internal/services/notification/repository"
+	notificationUseCases "home-library/internal/services/notification/usecases"
+	opdsHTTPDelivery "home-library/internal/services/opds/delivery/http/v1"
+	opdsUseCases "home-library/internal/services/opds/usecases"
+	quoteHTTPDelivery "home-library/internal/services/quote/delivery/http/v1"
+	quoteRepository "home-library/internal/services/quote/repository"
+	quoteUseCases "home-library/internal/services/quote/usecases"
+	readingHTTPDelivery "home-library/internal/services/reading/delivery/http/v1"
+	readingRepository "home-library/internal/services/reading/repository"
+	readingUseCases "home-library/internal/services/reading/usecases"
+	recommendationHTTPDelivery "home-library/internal/services/recommendation/delivery/http/v1"
+	recommendationRepository "home-library/internal/services/recommendation/repository"
+	recommendationUseCases "home-library/internal/services/recommendation/usecases"
+	reminderHTTPDelivery "home-library/internal/services/reminder/delivery/http/v1"
+	reminderRepository "home-library/internal/services/reminder/repository"
+	reminderUseCases "home-library/internal/services/reminder/usecases"
+	scanHTTPDelivery "home-library/internal/services/scan/delivery/http/v1"
+	scanRepository "home-library/internal/services/scan/repository"
+	scanUseCases "home-library/internal/services/scan/usecases"
+	statsHTTPDelivery "home-library/internal/services/stats/delivery/http/v1"
+	statsRepository "home-library/internal/services/stats/repository"
+	statsUseCases "home-library/internal/services/stats/usecases"
 	userHTTPDelivery "home-library/internal/services/user/delivery/http/v1"
 	userRepository "home-library/internal/services/user/repository"
 	userUseCases "home-library/internal/services/user/usecases"
+	webhookHTTPDelivery "home-library/internal/services/webhook/delivery/http/v1"
+	webhookRepository "home-library/internal/services/webhook/repository"
+	webhookUseCases "home-library/internal/services/webhook/usecases"
+	wishlistHTTPDelivery "home-library/internal/services/wishlist/delivery/http/v1"
+	wishlistRepository "home-library/internal/services/wishlist/repository"
+	wishlistUseCases "home-library/internal/services/wishlist/usecases"
 	"home-library/pkg/jwt"
+	"home-library/pkg/transaction"
+	"home-library/pkg/webhooks"
 	"net/http"
+	"time"
+)
+
+const (
+	// finishedJobsRetention is how long completed jobs stay visible to the
+	// administrators.
+	finishedJobsRetention = 7 * 24 * time.Hour
+
+	// eventRelayInterval bounds how long a recorded event waits before its
+	// subscribers get it.
+	eventRelayInterval     = time.Second
+	relayedEventsRetention = 7 * 24 * time.Hour
+	eventStreamInterval    = time.Second
+
+	// webhookTimeout bounds a single webhook request; home automation
+	// receivers answer quickly or not at all.
+	webhookTimeout             = 10 * time.Second
+	webhookDeliveriesRetention = 30 * 24 * time.Hour
+
+	// readNotificationsRetention is how long a notification is kept once
+	// its user has seen it; unread ones stay until they are read.
+	readNotificationsRetention = 90 * 24 * time.Hour
+
+	// reminderInterval is how late a loan reminder may be, and how soon
+	// after quiet hours end it goes out.
+	reminderInterval = 15 * time.Minute
+
+	// duplicateDetectionInterval is how often every household is searched
+	// for duplicates; members can also ask for a run after an import.
+	duplicateDetectionInterval = 24 * time.Hour
 )
 
 func (app *App) startService() error {
@@ -17,13 +120,214 @@ func (app *App) startService() error {
 	})
 
 	var (
-		jwtService = jwt.NewJWT(app.cfg.JWT)
+		jwtService   = jwt.NewJWT(app.cfg.JWT)
+		transactions = transaction.NewManager(app.db)
 
 		userRepo        = userRepository.NewRepository(app.db)
-		userUC          = userUseCases.NewUseCase(userRepo, jwtService)
+		userUC          = userUseCases.NewUseCase(userRepo, jwtService, app.cfg.Application.PhoneRegion)
 		userHTTPHandler = userHTTPDelivery.NewHandler(userUC)
 	)
 	userHTTPHandler.UserRoutes(domain)
 
+	authorized := domain.Group("", jwt.Middleware(jwtService))
+
+	var (
+		householdRepo        = householdRepository.NewRepository(app.db)
+		householdUC          = householdUseCases.NewUseCase(householdRepo, transactions)
+		householdHTTPHandler = householdHTTPDelivery.NewHandler(householdUC)
+	)
+	householdHTTPHandler.HouseholdRoutes(authorized)
+
+	var (
+		wishlistRepo        = wishlistRepository.NewRepository(app.db)
+		wishlistUC          = wishlistUseCases.NewUseCase(wishlistRepo)
+		wishlistHTTPHandler = wishlistHTTPDelivery.NewHandler(wishlistUC)
+	)
+	wishlistHTTPHandler.WishlistRoutes(authorized)
+
+	var (
+		archiveRepo        = archiveRepository.NewRepository(app.db)
+		archiveUC          = archiveUseCases.NewUseCase(archiveRepo, householdRepo, wishlistRepo, app.blobs, transactions)
+		archiveHTTPHandler = archiveHTTPDelivery.NewHandler(archiveUC)
+	)
+	archiveHTTPHandler.ArchiveRoutes(authorized)
+
+	var (
+		coverRepo        = coverRepository.NewRepository(app.db)
+		coverUC          = coverUseCases.NewUseCase(coverRepo, app.blobs)
+		coverHTTPHandler = coverHTTPDelivery.NewHandler(coverUC)
+	)
+	coverHTTPHandler.CoverRoutes(domain, authorized)
+
+	var (
+		catalogRepo        = catalogRepository.NewRepository(app.db)
+		catalogUC          = catalogUseCases.NewUseCase(catalogRepo, householdRepo, coverUC, wishlistRepo, transactions)
+		catalogHTTPHandler = catalogHTTPDelivery.NewHandler(catalogUC)
+	)
+	catalogHTTPHandler.CatalogRoutes(authorized)
+
+	var (
+		labelRepo        = labelRepository.NewRepository(app.db)
+		labelUC          = labelUseCases.NewUseCase(labelRepo, catalogRepo, householdRepo, app.cfg.Application.PublicURL)
+		labelHTTPHandler = labelHTTPDelivery.NewHandler(labelUC)
+	)
+	labelHTTPHandler.LabelRoutes(authorized)
+
+	var (
+		notificationRepo        = notificationRepository.NewRepository(app.db)
+		notificationUC          = notificationUseCases.NewUseCase(notificationRepo)
+		notificationHTTPHandler = notificationHTTPDelivery.NewHandler(notificationUC)
+	)
+	notificationHTTPHandler.NotificationRoutes(authorized)
+
+	var (
+		reminderRepo        = reminderRepository.NewRepository(app.db)
+		reminderUC          = reminderUseCases.NewUseCase(reminderRepo, notificationUC, transactions, app.cfg.Application.TimeZone)
+		reminderHTTPHandler = reminderHTTPDelivery.NewHandler(reminderUC)
+	)
+	reminderHTTPHandler.ReminderRoutes(authorized)
+
+	var (
+		friendRepo        = friendRepository.NewRepository(app.db)
+		friendUC          = friendUseCases.NewUseCase(friendRepo, householdRepo, notificationUC, transactions)
+		friendHTTPHandler = friendHTTPDelivery.NewHandler(friendUC)
+	)
+	friendHTTPHandler.FriendRoutes(authorized)
+
+	var (
+		loanRepo        = loanRepository.NewRepository(app.db)
+		loanUC          = loanUseCases.NewUseCase(loanRepo, householdRepo, friendRepo, notificationUC, transactions)
+		loanHTTPHandler = loanHTTPDelivery.NewHandler(loanUC)
+	)
+	loanHTTPHandler.LoanRoutes(authorized)
+
+	var (
+		readingRepo        = readingRepository.NewRepository(app.db)
+		readingUC          = readingUseCases.NewUseCase(readingRepo, householdRepo)
+		readingHTTPHandler = readingHTTPDelivery.NewHandler(readingUC)
+	)
+	readingHTTPHandler.ReadingRoutes(authorized)
+
+	var (
+		goalRepo        = goalRepository.NewRepository(app.db)
+		goalUC          = goalUseCases.NewUseCase(goalRepo, householdRepo, app.cfg.Application.TimeZone)
+		goalHTTPHandler = goalHTTPDelivery.NewHandler(goalUC)
+	)
+	goalHTTPHandler.GoalRoutes(authorized)
+
+	var (
+		recommendationRepo        = recommendationRepository.NewRepository(app.db)
+		recommendationUC          = recommendationUseCases.NewUseCase(recommendationRepo, householdRepo)
+		recommendationHTTPHandler = recommendationHTTPDelivery.NewHandler(recommendationUC)
+	)
+	recommendationHTTPHandler.RecommendationRoutes(authorized)
+
+	var (
+		ebookRepo        = ebookRepository.NewRepository(app.db)
+		ebookUC          = ebookUseCases.NewUseCase(ebookRepo, app.blobs, coverUC)
+		ebookHTTPHandler = ebookHTTPDelivery.NewHandler(ebookUC)
+	)
+	ebookHTTPHandler.EbookRoutes(authorized)
+
+	var (
+		bookImportRepo        = bookImportRepository.NewRepository(app.db)
+		bookImportUC          = bookImportUseCases.NewUseCase(bookImportRepo, catalogRepo, readingRepo, householdRepo, wishlistRepo, ebookUC, app.blobs, app.queue, transactions)
+		bookImportHTTPHandler = bookImportHTTPDelivery.NewHandler(bookImportUC)
+	)
+	bookImportHTTPHandler.BookImportRoutes(authorized)
+	app.workers.Handle(bookImportUseCases.ImportJob, bookImportUC.Run)
+
+	var (
+		opdsUC          = opdsUseCases.NewUseCase(ebookRepo, ebookUC)
+		opdsHTTPHandler = opdsHTTPDelivery.NewHandler(opdsUC)
+	)
+	opdsHTTPHandler.OpdsRoutes(domain.Group("/opds", userHTTPHandler.BasicAuth("Home Library")))
+
+	var (
+		quoteRepo        = quoteRepository.NewRepository(app.db)
+		quoteUC          = quoteUseCases.NewUseCase(quoteRepo, ebookRepo)
+		quoteHTTPHandler = quoteHTTPDelivery.NewHandler(quoteUC)
+	)
+	quoteHTTPHandler.QuoteRoutes(authorized)
+
+	var (
+		scanRepo        = scanRepository.NewRepository(app.db)
+		scanUC          = scanUseCases.NewUseCase(scanRepo, householdRepo, ebookRepo, wishlistRepo, catalogUC, loanUC)
+		scanHTTPHandler = scanHTTPDelivery.NewHandler(scanUC)
+	)
+	scanHTTPHandler.ScanRoutes(authorized)
+
+	var (
+		auditRepo        = auditRepository.NewRepository(app.db)
+		auditUC          = auditUseCases.NewUseCase(auditRepo, householdRepo, transactions)
+		auditHTTPHandler = auditHTTPDelivery.NewHandler(auditUC)
+	)
+	auditHTTPHandler.AuditRoutes(authorized)
+
+	var (
+		duplicateRepo        = duplicateRepository.NewRepository(app.db)
+		duplicateUC          = duplicateUseCases.NewUseCase(duplicateRepo, householdRepo, coverUC, app.queue, transactions)
+		duplicateHTTPHandler = duplicateHTTPDelivery.NewHandler(duplicateUC)
+	)
+	duplicateHTTPHandler.DuplicateRoutes(authorized)
+	app.workers.Handle(duplicateUseCases.DetectJob, duplicateUC.Run)
+
+	var (
+		statsRepo        = statsRepository.NewRepository(app.db)
+		statsUC          = statsUseCases.NewUseCase(statsRepo, householdRepo, app.cfg.Application.TimeZone)
+		statsHTTPHandler = statsHTTPDelivery.NewHandler(statsUC)
+	)
+	statsHTTPHandler.StatsRoutes(authorized)
+
+	var (
+		jobUC          = jobUseCases.NewUseCase(app.queue)
+		jobHTTPHandler = jobHTTPDelivery.NewHandler(jobUC)
+	)
+	jobHTTPHandler.JobRoutes(authorized.Group("/admin", userHTTPHandler.AdminOnly))
+
+	var (
+		webhookRepo        = webhookRepository.NewRepository(app.db)
+		webhookUC          = webhookUseCases.NewUseCase(webhookRepo, householdRepo, app.queue, webhooks.NewSender(webhookTimeout))
+		webhookHTTPHandler = webhookHTTPDelivery.NewHandler(webhookUC)
+	)
+	webhookHTTPHandler.WebhookRoutes(authorized)
+	app.events.Subscribe("webhooks", webhookUC.Fanout)
+	app.workers.Handle(webhookUseCases.DeliveryJob, webhookUC.Deliver)
+
+	var (
+		eventRepo        = eventRepository.NewRepository(app.db)
+		eventUC          = eventUseCases.NewUseCase(eventRepo, householdRepo)
+		eventHTTPHandler = eventHTTPDelivery.NewHandler(eventUC)
+	)
+	eventHTTPHandler.EventRoutes(authorized)
+	// Streams never finish on their own; end them as soon as shutdown starts
+	// so that the server does not wait for them until the timeout.
+	app.echo.TLSServer.RegisterOnShutdown(eventUC.Close)
+	app.scheduler.Add("stream events", eventStreamInterval, eventUC.Poll)
+
+	app.scheduler.Add("prune finished jobs", time.Hour, func(ctx context.Context) error {
+		_, err := app.queue.Prune(ctx, time.Now().Add(-finishedJobsRetention))
+		return err
+	})
+	app.scheduler.Add("relay domain events", eventRelayInterval, app.events.Relay)
+	app.scheduler.Add("prune relayed events", time.Hour, func(ctx context.Context) error {
+		_, err := app.events.Prune(ctx, time.Now().Add(-relayedEventsRetention))
+		return err
+	})
+	app.scheduler.Add("prune webhook deliveries", time.Hour, func(ctx context.Context) error {
+		_, err := webhookRepo.DeleteDeliveriesBefore(ctx, time.Now().Add(-webhookDeliveriesRetention))
+		return err
+	})
+	app.scheduler.Add("send loan reminders", reminderInterval, reminderUC.Remind)
+	app.scheduler.Add("prune read notifications", time.Hour, func(ctx context.Context) error {
+		_, err := notificationRepo.DeleteReadBefore(ctx, time.Now().Add(-readNotificationsRetention))
+		return err
+	})
+	app.scheduler.Add("detect duplicates", duplicateDetectionInterval, duplicateUC.DetectAll)
+	app.scheduler.Add("prune scan sessions", time.Hour, func(ctx context.Context) error {
+		_, err := scanRepo.DeleteSessionsBefore(ctx, time.Now().Add(-scanUseCases.SessionTTL))
+		return err
+	})
+
 	return nil
 }
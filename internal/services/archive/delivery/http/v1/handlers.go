@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/archive/dtos"
+	"home-library/internal/services/archive/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+	"time"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) Export(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	filename := fmt.Sprintf("home-library-%s.zip", time.Now().Format("2006-01-02"))
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, "application/zip")
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	err := h.u.Export(c.Request().Context(), userID, c.Response())
+	if err == nil {
+		return nil
+	}
+
+	// Once the archive has started streaming the status line is gone, so all
+	// that is left is to log and cut the response short.
+	if c.Response().Committed {
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("failed to stream export archive")
+		return nil
+	}
+
+	header.Del(echo.HeaderContentDisposition)
+	return h.handleError(c, err, "failed to export household")
+}
+
+func (h *handler) Import(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	file, err := c.FormFile("archive")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Файл архива не передан", nil))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to open uploaded archive")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+	defer src.Close()
+
+	result, err := h.u.Import(c.Request().Context(), userID, src, file.Size)
+	if err != nil {
+		return h.handleError(c, err, "failed to import household")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	case errors.Is(err, customErrors.ErrHouseholdNotEmpty):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Импорт возможен только в пустую библиотеку", nil))
+	case errors.Is(err, customErrors.ErrArchiveInvalid):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Архив повреждён", nil))
+	case errors.Is(err, customErrors.ErrArchiveVersion):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неподдерживаемая версия архива", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
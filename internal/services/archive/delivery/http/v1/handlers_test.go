@@ -0,0 +1,199 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"home-library/internal/services/archive/dtos"
+	customErrors "home-library/pkg/errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) Export(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	args := m.Called(ctx, userID, w)
+	if data, ok := args.Get(0).([]byte); ok {
+		_, _ = w.Write(data)
+	}
+	return args.Error(1)
+}
+
+func (m *MockUseCase) ExportHousehold(ctx context.Context, householdID uuid.UUID, w io.Writer) error {
+	args := m.Called(ctx, householdID, w)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) Import(ctx context.Context, userID uuid.UUID, r io.ReaderAt, size int64) (*dtos.ImportResponse, error) {
+	args := m.Called(ctx, userID, r, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.ImportResponse), args.Error(1)
+}
+
+func (m *MockUseCase) ImportHousehold(ctx context.Context, householdID uuid.UUID, r io.ReaderAt, size int64) (*dtos.ImportResponse, error) {
+	args := m.Called(ctx, householdID, r, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.ImportResponse), args.Error(1)
+}
+
+func newContext(e *echo.Echo, req *http.Request, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func newUploadRequest(t *testing.T, field string, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(field, "home-library.zip")
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/import", &body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestExport(t *testing.T) {
+	e := echo.New()
+
+	t.Run("streams archive", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Export", mock.Anything, userID, mock.Anything).Return([]byte("PK\x03\x04"), nil)
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/export", nil), userID)
+		err := h.Export(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/zip", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "attachment; filename=\"home-library-")
+		assert.Equal(t, "PK\x03\x04", rec.Body.String())
+	})
+
+	t.Run("not in household", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Export", mock.Anything, userID, mock.Anything).Return(nil, customErrors.ErrHouseholdNotFound)
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/export", nil), userID)
+		err := h.Export(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentDisposition))
+	})
+
+	t.Run("failure after streaming started", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Export", mock.Anything, userID, mock.Anything).Return([]byte("PK"), errors.New("db error"))
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/export", nil), userID)
+		err := h.Export(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "PK", rec.Body.String())
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/export", nil), uuid.Nil)
+		err := h.Export(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestImport(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully import archive", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Import", mock.Anything, userID, mock.Anything, int64(4)).Return(&dtos.ImportResponse{WishlistItems: 3, SkippedItems: 1}, nil)
+
+		c, rec := newContext(e, newUploadRequest(t, "archive", []byte("PK..")), userID)
+		err := h.Import(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response dtos.ImportResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.WishlistItems)
+		assert.Equal(t, 1, response.SkippedItems)
+	})
+
+	t.Run("missing archive", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, newUploadRequest(t, "file", []byte("PK..")), uuid.New())
+		err := h.Import(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"not an owner", customErrors.ErrHouseholdForbidden, http.StatusForbidden},
+		{"household not empty", customErrors.ErrHouseholdNotEmpty, http.StatusConflict},
+		{"broken archive", customErrors.ErrArchiveInvalid, http.StatusBadRequest},
+		{"unsupported version", customErrors.ErrArchiveVersion, http.StatusBadRequest},
+		{"internal error", errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			h := NewHandler(mockUseCase)
+			userID := uuid.New()
+
+			mockUseCase.On("Import", mock.Anything, userID, mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			c, rec := newContext(e, newUploadRequest(t, "archive", []byte("PK..")), userID)
+			err := h.Import(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func (h *handler) ArchiveRoutes(domain *echo.Group) {
+	domain.GET("/export", h.Export)
+	// An archive carries the household's ebooks and covers; the limit
+	// matches the one for a Calibre library import.
+	domain.POST("/import", h.Import, middleware.BodyLimit("2049M"))
+}
@@ -0,0 +1,18 @@
+package dtos
+
+type ImportResponse struct {
+	WishlistItems int `json:"wishlist_items"`
+	Locations     int `json:"locations"`
+	Books         int `json:"books"`
+	Copies        int `json:"copies"`
+	Loans         int `json:"loans"`
+	Readings      int `json:"readings"`
+	Reviews       int `json:"reviews"`
+	Audits        int `json:"audits"`
+	Ebooks        int `json:"ebooks"`
+	Quotes        int `json:"quotes"`
+	// SkippedItems counts the rows of people who are not members of the
+	// target household, wishlist items that have neither an ISBN nor a
+	// title, and ebooks whose file is missing from the archive.
+	SkippedItems int `json:"skipped_items"`
+}
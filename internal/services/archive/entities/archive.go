@@ -0,0 +1,241 @@
+package entities
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SchemaVersion is bumped whenever the archive layout changes in a way older
+// importers cannot read. Version 1 archives hold only members and wishlists.
+const SchemaVersion = 2
+
+const (
+	ManifestFile    = "manifest.json"
+	MembersFile     = "members.json"
+	WishlistFile    = "wishlist.json"
+	WishlistCSVFile = "wishlist.csv"
+	LocationsFile   = "locations.json"
+	BooksFile       = "books.json"
+	BooksCSVFile    = "books.csv"
+	CopiesFile      = "copies.json"
+	LoansFile       = "loans.json"
+	ReadingsFile    = "readings.json"
+	ReviewsFile     = "reviews.json"
+	AuditsFile      = "audits.json"
+	EbooksFile      = "ebooks.json"
+	QuotesFile      = "quotes.json"
+)
+
+type Manifest struct {
+	SchemaVersion int       `json:"schema_version"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Household     Household `json:"household"`
+}
+
+type Household struct {
+	Name string `json:"name"`
+}
+
+// Member identifies users by email, since user IDs are meaningless on
+// another server.
+type Member struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Role      string `json:"role"`
+}
+
+type WishlistItem struct {
+	OwnerEmail string `json:"owner_email"`
+	ISBN       string `json:"isbn,omitempty"`
+	Title      string `json:"title,omitempty"`
+	Author     string `json:"author,omitempty"`
+	Priority   string `json:"priority"`
+	Notes      string `json:"notes,omitempty"`
+	// The purchase is left out of the CSV, which lists what is wished for.
+	PurchasePrice *float64   `json:"purchase_price,omitempty"`
+	PurchasedAt   *time.Time `json:"purchased_at,omitempty"`
+	PurchaseStore string     `json:"purchase_store,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+var WishlistCSVHeader = []string{"owner_email", "isbn", "title", "author", "priority", "notes", "created_at"}
+
+func (i WishlistItem) CSVRecord() []string {
+	return []string{i.OwnerEmail, i.ISBN, i.Title, i.Author, i.Priority, i.Notes, i.CreatedAt.Format(time.RFC3339)}
+}
+
+// Library is the household data of an archive besides its members and
+// wishlists. Rows refer to each other by the IDs they had when exported, and
+// to people by email; an import gives them fresh IDs and the IDs of the
+// members with those emails. Covers and ebook files are stored in the
+// archive under their blob store keys.
+type Library struct {
+	Locations []Location
+	Books     []Book
+	Copies    []Copy
+	Loans     []Loan
+	Readings  []Reading
+	Reviews   []Review
+	Audits    []Audit
+	Ebooks    []Ebook
+	Quotes    []Quote
+}
+
+// Location and Copy keep their short IDs, so the labels already printed
+// still scan after an import.
+type Location struct {
+	LocationID uuid.UUID `db:"location_id" json:"location_id"`
+	ShortID    string    `db:"short_id" json:"short_id"`
+	Name       string    `db:"name" json:"name"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Book struct {
+	BookID        uuid.UUID      `db:"book_id" json:"book_id"`
+	Title         string         `db:"title" json:"title"`
+	Authors       pq.StringArray `db:"authors" json:"authors"`
+	ISBN          string         `db:"isbn" json:"isbn,omitempty"`
+	Language      string         `db:"language" json:"language,omitempty"`
+	Publisher     string         `db:"publisher" json:"publisher,omitempty"`
+	PublishedYear *int           `db:"published_year" json:"published_year,omitempty"`
+	Pages         *int           `db:"pages" json:"pages,omitempty"`
+	Series        string         `db:"series" json:"series,omitempty"`
+	SeriesIndex   *float64       `db:"series_index" json:"series_index,omitempty"`
+	Tags          pq.StringArray `db:"tags" json:"tags"`
+	Description   string         `db:"description" json:"description,omitempty"`
+	CoverID       *uuid.UUID     `db:"cover_id" json:"cover_id,omitempty"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+var BooksCSVHeader = []string{"title", "authors", "isbn", "language", "publisher", "published_year", "pages", "series", "series_index", "tags"}
+
+// CSVRecord lists the authors and tags separated by semicolons.
+func (b Book) CSVRecord() []string {
+	record := []string{b.Title, strings.Join(b.Authors, "; "), b.ISBN, b.Language, b.Publisher, "", "", b.Series, "", strings.Join(b.Tags, "; ")}
+	if b.PublishedYear != nil {
+		record[5] = strconv.Itoa(*b.PublishedYear)
+	}
+	if b.Pages != nil {
+		record[6] = strconv.Itoa(*b.Pages)
+	}
+	if b.SeriesIndex != nil {
+		record[8] = strconv.FormatFloat(*b.SeriesIndex, 'f', -1, 64)
+	}
+	return record
+}
+
+type Copy struct {
+	CopyID        uuid.UUID  `db:"copy_id" json:"copy_id"`
+	ShortID       string     `db:"short_id" json:"short_id"`
+	BookID        uuid.UUID  `db:"book_id" json:"book_id"`
+	LocationID    *uuid.UUID `db:"location_id" json:"location_id,omitempty"`
+	Notes         string     `db:"notes" json:"notes,omitempty"`
+	PurchasePrice *float64   `db:"purchase_price" json:"purchase_price,omitempty"`
+	PurchasedAt   *time.Time `db:"purchased_at" json:"purchased_at,omitempty"`
+	PurchaseStore string     `db:"purchase_store" json:"purchase_store,omitempty"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Loan keeps only the borrower's name: the friend household and user a loan
+// may link to do not exist on another server.
+type Loan struct {
+	LoanID       uuid.UUID  `db:"loan_id" json:"loan_id"`
+	CopyID       uuid.UUID  `db:"copy_id" json:"copy_id"`
+	BorrowerName string     `db:"borrower_name" json:"borrower_name"`
+	LentBy       uuid.UUID  `db:"lent_by" json:"-"`
+	LentByEmail  string     `db:"lent_by_email" json:"lent_by_email"`
+	LentAt       time.Time  `db:"lent_at" json:"lent_at"`
+	DueAt        *time.Time `db:"due_at" json:"due_at,omitempty"`
+	ReturnedAt   *time.Time `db:"returned_at" json:"returned_at,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+type Reading struct {
+	BookID     uuid.UUID  `db:"book_id" json:"book_id"`
+	UserID     uuid.UUID  `db:"user_id" json:"-"`
+	UserEmail  string     `db:"user_email" json:"user_email"`
+	StartedAt  *time.Time `db:"started_at" json:"started_at,omitempty"`
+	FinishedAt *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+type Review struct {
+	BookID    uuid.UUID `db:"book_id" json:"book_id"`
+	UserID    uuid.UUID `db:"user_id" json:"-"`
+	UserEmail string    `db:"user_email" json:"user_email"`
+	Rating    int       `db:"rating" json:"rating"`
+	Text      string    `db:"text" json:"text,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Audit struct {
+	AuditID        uuid.UUID   `db:"audit_id" json:"audit_id"`
+	LocationID     uuid.UUID   `db:"location_id" json:"location_id"`
+	StartedBy      uuid.UUID   `db:"started_by" json:"-"`
+	StartedByEmail string      `db:"started_by_email" json:"started_by_email"`
+	Status         string      `db:"status" json:"status"`
+	Found          int         `db:"found" json:"found"`
+	Misplaced      int         `db:"misplaced" json:"misplaced"`
+	Missing        int         `db:"missing" json:"missing"`
+	Unknown        int         `db:"unknown" json:"unknown"`
+	CreatedAt      time.Time   `db:"created_at" json:"created_at"`
+	ClosedAt       *time.Time  `db:"closed_at" json:"closed_at,omitempty"`
+	Scans          []AuditScan `db:"-" json:"scans"`
+}
+
+type AuditScan struct {
+	AuditID   uuid.UUID `db:"audit_id" json:"-"`
+	Code      string    `db:"code" json:"code"`
+	ScannedAt time.Time `db:"scanned_at" json:"scanned_at"`
+}
+
+type Ebook struct {
+	FileID       uuid.UUID      `db:"file_id" json:"file_id"`
+	OwnerID      uuid.UUID      `db:"owner_id" json:"-"`
+	OwnerEmail   string         `db:"owner_email" json:"owner_email"`
+	SHA256       string         `db:"sha256" json:"sha256"`
+	Format       string         `db:"format" json:"format"`
+	Size         int64          `db:"size" json:"size"`
+	OriginalName string         `db:"original_name" json:"original_name,omitempty"`
+	Title        string         `db:"title" json:"title,omitempty"`
+	Authors      pq.StringArray `db:"authors" json:"authors"`
+	Language     string         `db:"language" json:"language,omitempty"`
+	ISBN         string         `db:"isbn" json:"isbn,omitempty"`
+	Description  string         `db:"description" json:"description,omitempty"`
+	Series       string         `db:"series" json:"series,omitempty"`
+	SeriesIndex  *float64       `db:"series_index" json:"series_index,omitempty"`
+	Tags         pq.StringArray `db:"tags" json:"tags"`
+	CoverID      *uuid.UUID     `db:"cover_id" json:"cover_id,omitempty"`
+	BookID       *uuid.UUID     `db:"book_id" json:"book_id,omitempty"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+type Quote struct {
+	UserID        uuid.UUID  `db:"user_id" json:"-"`
+	UserEmail     string     `db:"user_email" json:"user_email"`
+	BookID        *uuid.UUID `db:"book_id" json:"book_id,omitempty"`
+	FileID        *uuid.UUID `db:"file_id" json:"file_id,omitempty"`
+	Source        string     `db:"source" json:"source"`
+	Kind          string     `db:"kind" json:"kind"`
+	BookTitle     string     `db:"book_title" json:"book_title,omitempty"`
+	BookAuthor    string     `db:"book_author" json:"book_author,omitempty"`
+	Text          string     `db:"text" json:"text,omitempty"`
+	Note          string     `db:"note" json:"note,omitempty"`
+	Chapter       string     `db:"chapter" json:"chapter,omitempty"`
+	Location      string     `db:"location" json:"location,omitempty"`
+	Page          int        `db:"page" json:"page,omitempty"`
+	HighlightedAt *time.Time `db:"highlighted_at" json:"highlighted_at,omitempty"`
+	Fingerprint   string     `db:"fingerprint" json:"fingerprint"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
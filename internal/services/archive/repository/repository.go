@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/archive/entities"
+	"home-library/pkg/transaction"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Repository reads and writes the library of a household across the tables
+// of the services owning it. The wishlists, ebooks and quotes of a household
+// are those of its members, passed as memberIDs.
+type Repository interface {
+	// HasLibrary reports whether the household has any of the data an
+	// import restores.
+	HasLibrary(ctx context.Context, householdID uuid.UUID, memberIDs []uuid.UUID) (bool, error)
+	GetLibrary(ctx context.Context, householdID uuid.UUID, memberIDs []uuid.UUID) (*entities.Library, error)
+	// RestoreLibrary inserts every row of the library into the household,
+	// in the transaction of the context or else in one of its own. The rows
+	// must already carry fresh IDs and the IDs of members.
+	RestoreLibrary(ctx context.Context, householdID uuid.UUID, library *entities.Library) error
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) HasLibrary(ctx context.Context, householdID uuid.UUID, memberIDs []uuid.UUID) (bool, error) {
+	// Copies, loans, readings, reviews and audits all hang off a book or a
+	// location of the household.
+	query := `
+		SELECT EXISTS (SELECT 1 FROM books WHERE household_id = $1)
+			OR EXISTS (SELECT 1 FROM locations WHERE household_id = $1)
+			OR EXISTS (SELECT 1 FROM wishlist_items WHERE user_id = ANY($2::uuid[]))
+			OR EXISTS (SELECT 1 FROM ebook_files WHERE owner_id = ANY($2::uuid[]))
+			OR EXISTS (SELECT 1 FROM quotes WHERE user_id = ANY($2::uuid[]))
+	`
+
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, query, householdID, idArray(memberIDs))
+	return exists, err
+}
+
+func (r *repository) GetLibrary(ctx context.Context, householdID uuid.UUID, memberIDs []uuid.UUID) (*entities.Library, error) {
+	library := &entities.Library{
+		Locations: make([]entities.Location, 0),
+		Books:     make([]entities.Book, 0),
+		Copies:    make([]entities.Copy, 0),
+		Loans:     make([]entities.Loan, 0),
+		Readings:  make([]entities.Reading, 0),
+		Reviews:   make([]entities.Review, 0),
+		Audits:    make([]entities.Audit, 0),
+		Ebooks:    make([]entities.Ebook, 0),
+		Quotes:    make([]entities.Quote, 0),
+	}
+	members := idArray(memberIDs)
+
+	queries := []struct {
+		dest  any
+		query string
+		arg   any
+	}{
+		{&library.Locations, `
+			SELECT location_id, short_id, name, created_at, updated_at
+			FROM locations WHERE household_id = $1 ORDER BY created_at
+		`, householdID},
+		{&library.Books, `
+			SELECT book_id, title, authors, isbn, language, publisher, published_year, pages,
+				series, series_index, tags, description, cover_id, created_at, updated_at
+			FROM books WHERE household_id = $1 ORDER BY created_at
+		`, householdID},
+		{&library.Copies, `
+			SELECT copy_id, short_id, book_id, location_id, notes, purchase_price, purchased_at,
+				purchase_store, created_at, updated_at
+			FROM copies WHERE household_id = $1 ORDER BY created_at
+		`, householdID},
+		{&library.Loans, `
+			SELECT l.loan_id, l.copy_id, l.borrower_name, l.lent_by, u.email AS lent_by_email,
+				l.lent_at, l.due_at, l.returned_at, l.created_at
+			FROM loans l
+			JOIN users u ON u.user_id = l.lent_by
+			WHERE l.household_id = $1 ORDER BY l.lent_at
+		`, householdID},
+		{&library.Readings, `
+			SELECT r.book_id, r.user_id, u.email AS user_email, r.started_at, r.finished_at,
+				r.created_at, r.updated_at
+			FROM readings r
+			JOIN users u ON u.user_id = r.user_id
+			WHERE r.household_id = $1 ORDER BY r.created_at
+		`, householdID},
+		{&library.Reviews, `
+			SELECT r.book_id, r.user_id, u.email AS user_email, r.rating, r.text, r.created_at, r.updated_at
+			FROM reviews r
+			JOIN users u ON u.user_id = r.user_id
+			WHERE r.household_id = $1 ORDER BY r.created_at
+		`, householdID},
+		{&library.Audits, `
+			SELECT a.audit_id, a.location_id, a.started_by, u.email AS started_by_email, a.status,
+				a.found, a.misplaced, a.missing, a.unknown, a.created_at, a.closed_at
+			FROM audits a
+			JOIN users u ON u.user_id = a.started_by
+			WHERE a.household_id = $1 ORDER BY a.created_at
+		`, householdID},
+		{&library.Ebooks, `
+			SELECT f.file_id, f.owner_id, u.email AS owner_email, f.sha256, f.format, f.size,
+				f.original_name, f.title, f.authors, f.language, f.isbn, f.description, f.series,
+				f.series_index, f.tags, f.cover_id, f.book_id, f.created_at, f.updated_at
+			FROM ebook_files f
+			JOIN users u ON u.user_id = f.owner_id
+			WHERE f.owner_id = ANY($1::uuid[]) ORDER BY f.created_at
+		`, members},
+		{&library.Quotes, `
+			SELECT q.user_id, u.email AS user_email, q.book_id, q.file_id, q.source, q.kind, q.book_title,
+				q.book_author, q.text, q.note, q.chapter, q.location, q.page, q.highlighted_at,
+				q.fingerprint, q.created_at
+			FROM quotes q
+			JOIN users u ON u.user_id = q.user_id
+			WHERE q.user_id = ANY($1::uuid[]) ORDER BY q.created_at
+		`, members},
+	}
+	for _, q := range queries {
+		if err := r.db.SelectContext(ctx, q.dest, q.query, q.arg); err != nil {
+			return nil, err
+		}
+	}
+
+	var scans []entities.AuditScan
+	query := `
+		SELECT s.audit_id, s.code, s.scanned_at
+		FROM audit_scans s
+		JOIN audits a ON a.audit_id = s.audit_id
+		WHERE a.household_id = $1
+		ORDER BY s.scanned_at
+	`
+	if err := r.db.SelectContext(ctx, &scans, query, householdID); err != nil {
+		return nil, err
+	}
+
+	scansByAudit := make(map[uuid.UUID][]entities.AuditScan, len(library.Audits))
+	for _, scan := range scans {
+		scansByAudit[scan.AuditID] = append(scansByAudit[scan.AuditID], scan)
+	}
+	for i := range library.Audits {
+		library.Audits[i].Scans = scansByAudit[library.Audits[i].AuditID]
+		if library.Audits[i].Scans == nil {
+			library.Audits[i].Scans = make([]entities.AuditScan, 0)
+		}
+	}
+
+	return library, nil
+}
+
+func (r *repository) RestoreLibrary(ctx context.Context, householdID uuid.UUID, library *entities.Library) error {
+	return r.db.InTx(ctx, nil, func(tx *sqlx.Tx) error {
+		for _, location := range library.Locations {
+			query := `
+				INSERT INTO locations (location_id, household_id, short_id, name, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`
+			_, err := tx.ExecContext(ctx, query,
+				location.LocationID, householdID, location.ShortID, location.Name, location.CreatedAt, location.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		// The covers come first for the books and ebook files to refer to;
+		// a cover of an ebook counts as uploaded by its owner.
+		for _, ebook := range library.Ebooks {
+			if err := restoreCover(ctx, tx, ebook.CoverID, &ebook.OwnerID); err != nil {
+				return err
+			}
+		}
+		for _, book := range library.Books {
+			if err := restoreCover(ctx, tx, book.CoverID, nil); err != nil {
+				return err
+			}
+		}
+
+		for _, book := range library.Books {
+			query := `
+				INSERT INTO books (
+					book_id, household_id, title, authors, isbn, language, publisher,
+					published_year, pages, series, series_index, tags, description,
+					cover_id, created_at, updated_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			`
+			_, err := tx.ExecContext(ctx, query,
+				book.BookID, householdID, book.Title, book.Authors, book.ISBN, book.Language, book.Publisher,
+				book.PublishedYear, book.Pages, book.Series, book.SeriesIndex, book.Tags, book.Description,
+				book.CoverID, book.CreatedAt, book.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, copy := range library.Copies {
+			query := `
+				INSERT INTO copies (
+					copy_id, book_id, household_id, short_id, location_id, notes, purchase_price,
+					purchased_at, purchase_store, created_at, updated_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			`
+			_, err := tx.ExecContext(ctx, query,
+				copy.CopyID, copy.BookID, householdID, copy.ShortID, copy.LocationID, copy.Notes, copy.PurchasePrice,
+				copy.PurchasedAt, copy.PurchaseStore, copy.CreatedAt, copy.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, loan := range library.Loans {
+			query := `
+				INSERT INTO loans (
+					loan_id, household_id, copy_id, borrower_name, lent_by, lent_at, due_at, returned_at, created_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			`
+			_, err := tx.ExecContext(ctx, query,
+				loan.LoanID, householdID, loan.CopyID, loan.BorrowerName, loan.LentBy, loan.LentAt, loan.DueAt,
+				loan.ReturnedAt, loan.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, reading := range library.Readings {
+			query := `
+				INSERT INTO readings (
+					reading_id, household_id, book_id, user_id, started_at, finished_at, created_at, updated_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			`
+			_, err := tx.ExecContext(ctx, query,
+				uuid.New(), householdID, reading.BookID, reading.UserID, reading.StartedAt, reading.FinishedAt,
+				reading.CreatedAt, reading.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, review := range library.Reviews {
+			query := `
+				INSERT INTO reviews (household_id, book_id, user_id, rating, text, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`
+			_, err := tx.ExecContext(ctx, query,
+				householdID, review.BookID, review.UserID, review.Rating, review.Text, review.CreatedAt, review.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, audit := range library.Audits {
+			query := `
+				INSERT INTO audits (
+					audit_id, household_id, location_id, started_by, status, found, misplaced,
+					missing, unknown, created_at, closed_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			`
+			_, err := tx.ExecContext(ctx, query,
+				audit.AuditID, householdID, audit.LocationID, audit.StartedBy, audit.Status, audit.Found, audit.Misplaced,
+				audit.Missing, audit.Unknown, audit.CreatedAt, audit.ClosedAt)
+			if err != nil {
+				return err
+			}
+
+			for _, scan := range audit.Scans {
+				query := `INSERT INTO audit_scans (audit_id, code, scanned_at) VALUES ($1, $2, $3)`
+				if _, err := tx.ExecContext(ctx, query, audit.AuditID, scan.Code, scan.ScannedAt); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, ebook := range library.Ebooks {
+			query := `
+				INSERT INTO ebook_files (
+					file_id, owner_id, sha256, format, size, original_name, title, authors, language,
+					isbn, description, series, series_index, tags, cover_id, book_id, created_at, updated_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+			`
+			_, err := tx.ExecContext(ctx, query,
+				ebook.FileID, ebook.OwnerID, ebook.SHA256, ebook.Format, ebook.Size, ebook.OriginalName, ebook.Title,
+				ebook.Authors, ebook.Language, ebook.ISBN, ebook.Description, ebook.Series, ebook.SeriesIndex,
+				ebook.Tags, ebook.CoverID, ebook.BookID, ebook.CreatedAt, ebook.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, quote := range library.Quotes {
+			query := `
+				INSERT INTO quotes (
+					quote_id, user_id, book_id, file_id, source, kind, book_title, book_author, text, note,
+					chapter, location, page, highlighted_at, fingerprint, created_at
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			`
+			_, err := tx.ExecContext(ctx, query,
+				uuid.New(), quote.UserID, quote.BookID, quote.FileID, quote.Source, quote.Kind, quote.BookTitle, quote.BookAuthor,
+				quote.Text, quote.Note, quote.Chapter, quote.Location, quote.Page, quote.HighlightedAt,
+				quote.Fingerprint, quote.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func restoreCover(ctx context.Context, tx *sqlx.Tx, coverID *uuid.UUID, uploadedBy *uuid.UUID) error {
+	if coverID == nil {
+		return nil
+	}
+
+	query := `
+		INSERT INTO covers (cover_id, uploaded_by) VALUES ($1, $2)
+		ON CONFLICT (cover_id) DO NOTHING
+	`
+	_, err := tx.ExecContext(ctx, query, *coverID, uploadedBy)
+	return err
+}
+
+func idArray(ids []uuid.UUID) pq.StringArray {
+	array := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		array[i] = id.String()
+	}
+	return array
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/archive/entities"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestHasLibrary(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	householdID, userID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM books WHERE household_id = \$1\).+wishlist_items WHERE user_id = ANY\(\$2::uuid\[\]\)`).
+		WithArgs(householdID, pq.StringArray{userID.String()}).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	exists, err := repo.HasLibrary(context.Background(), householdID, []uuid.UUID{userID})
+
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreLibrary(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("a failing row rolls the whole library back", func(t *testing.T) {
+		householdID := uuid.New()
+		library := &entities.Library{
+			Locations: []entities.Location{{LocationID: uuid.New(), ShortID: "K7M2Q9X0", Name: "Гостиная"}},
+			Books:     []entities.Book{{BookID: uuid.New(), Title: "Солярис", Authors: pq.StringArray{}, Tags: pq.StringArray{}, CreatedAt: time.Now()}},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO locations").
+			WithArgs(library.Locations[0].LocationID, householdID, "K7M2Q9X0", "Гостиная", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO books").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		err := repo.RestoreLibrary(context.Background(), householdID, library)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("covers are recorded before the rows using them", func(t *testing.T) {
+		householdID, ownerID, coverID := uuid.New(), uuid.New(), uuid.New()
+		library := &entities.Library{
+			Books:  []entities.Book{{BookID: uuid.New(), Title: "Солярис", Authors: pq.StringArray{}, Tags: pq.StringArray{}, CoverID: &coverID}},
+			Ebooks: []entities.Ebook{{FileID: uuid.New(), OwnerID: ownerID, Authors: pq.StringArray{}, Tags: pq.StringArray{}, CoverID: &coverID}},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO covers \(cover_id, uploaded_by\) VALUES \(\$1, \$2\)\s+ON CONFLICT \(cover_id\) DO NOTHING`).
+			WithArgs(coverID, &ownerID).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO covers").
+			WithArgs(coverID, nil).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO books").
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		err := repo.RestoreLibrary(context.Background(), householdID, library)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,349 @@
+package usecases
+
+import (
+	"archive/zip"
+	"encoding/hex"
+	"home-library/internal/services/archive/dtos"
+	"home-library/internal/services/archive/entities"
+	coverEntities "home-library/internal/services/cover/entities"
+	ebookEntities "home-library/internal/services/ebook/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/shortid"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// restore turns the library of an archive into rows of the target
+// household: every row gets a fresh ID, so an archive can be restored next
+// to the household it came from, and the people it names become members.
+// References the archive cannot satisfy make it invalid; rows of people who
+// are not members are skipped.
+type restore struct {
+	// files indexes the archive by name.
+	files    map[string]*zip.File
+	members  map[string]uuid.UUID
+	response *dtos.ImportResponse
+
+	// library is the result, with the IDs it will have once inserted.
+	library entities.Library
+	// covers maps the archived cover IDs kept to their new IDs.
+	covers map[uuid.UUID]uuid.UUID
+
+	locations map[uuid.UUID]uuid.UUID
+	books     map[uuid.UUID]uuid.UUID
+	copies    map[uuid.UUID]uuid.UUID
+	ebooks    map[uuid.UUID]uuid.UUID
+}
+
+func newRestore(zr *zip.Reader, members map[string]uuid.UUID, response *dtos.ImportResponse) *restore {
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, file := range zr.File {
+		files[file.Name] = file
+	}
+
+	return &restore{
+		files:     files,
+		members:   members,
+		response:  response,
+		covers:    make(map[uuid.UUID]uuid.UUID),
+		locations: make(map[uuid.UUID]uuid.UUID),
+		books:     make(map[uuid.UUID]uuid.UUID),
+		copies:    make(map[uuid.UUID]uuid.UUID),
+		ebooks:    make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+func (r *restore) remap(archived *entities.Library) error {
+	steps := []func(*entities.Library) error{
+		r.remapLocations,
+		r.remapBooks,
+		r.remapCopies,
+		r.remapLoans,
+		r.remapReading,
+		r.remapAudits,
+		r.remapEbooks,
+		r.remapQuotes,
+	}
+	for _, step := range steps {
+		if err := step(archived); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *restore) remapLocations(archived *entities.Library) error {
+	shortIDs := make(map[string]bool, len(archived.Locations))
+	for _, location := range archived.Locations {
+		shortID, err := uniqueShortID(location.ShortID, shortIDs)
+		if err != nil {
+			return err
+		}
+
+		r.locations[location.LocationID] = uuid.New()
+		location.LocationID = r.locations[location.LocationID]
+		location.ShortID = shortID
+		r.library.Locations = append(r.library.Locations, location)
+	}
+	r.response.Locations = len(r.library.Locations)
+	return nil
+}
+
+func (r *restore) remapBooks(archived *entities.Library) error {
+	for _, book := range archived.Books {
+		if book.Title == "" {
+			return errors.ErrArchiveInvalid
+		}
+
+		r.books[book.BookID] = uuid.New()
+		book.BookID = r.books[book.BookID]
+		book.Authors = nonNil(book.Authors)
+		book.Tags = nonNil(book.Tags)
+		book.CoverID = r.cover(book.CoverID)
+		r.library.Books = append(r.library.Books, book)
+	}
+	r.response.Books = len(r.library.Books)
+	return nil
+}
+
+func (r *restore) remapCopies(archived *entities.Library) error {
+	shortIDs := make(map[string]bool, len(archived.Copies))
+	for _, copy := range archived.Copies {
+		bookID, ok := r.books[copy.BookID]
+		if !ok {
+			return errors.ErrArchiveInvalid
+		}
+		if copy.LocationID != nil {
+			locationID, ok := r.locations[*copy.LocationID]
+			if !ok {
+				return errors.ErrArchiveInvalid
+			}
+			copy.LocationID = &locationID
+		}
+		shortID, err := uniqueShortID(copy.ShortID, shortIDs)
+		if err != nil {
+			return err
+		}
+
+		r.copies[copy.CopyID] = uuid.New()
+		copy.CopyID = r.copies[copy.CopyID]
+		copy.BookID = bookID
+		copy.ShortID = shortID
+		r.library.Copies = append(r.library.Copies, copy)
+	}
+	r.response.Copies = len(r.library.Copies)
+	return nil
+}
+
+func (r *restore) remapLoans(archived *entities.Library) error {
+	for _, loan := range archived.Loans {
+		copyID, ok := r.copies[loan.CopyID]
+		if !ok {
+			return errors.ErrArchiveInvalid
+		}
+		lentBy, ok := r.member(loan.LentByEmail)
+		if !ok {
+			continue
+		}
+
+		loan.LoanID = uuid.New()
+		loan.CopyID = copyID
+		loan.LentBy = lentBy
+		r.library.Loans = append(r.library.Loans, loan)
+	}
+	r.response.Loans = len(r.library.Loans)
+	return nil
+}
+
+func (r *restore) remapReading(archived *entities.Library) error {
+	for _, reading := range archived.Readings {
+		bookID, ok := r.books[reading.BookID]
+		if !ok {
+			return errors.ErrArchiveInvalid
+		}
+		userID, ok := r.member(reading.UserEmail)
+		if !ok {
+			continue
+		}
+
+		reading.BookID = bookID
+		reading.UserID = userID
+		r.library.Readings = append(r.library.Readings, reading)
+	}
+	r.response.Readings = len(r.library.Readings)
+
+	for _, review := range archived.Reviews {
+		bookID, ok := r.books[review.BookID]
+		if !ok || review.Rating < 1 || review.Rating > 5 {
+			return errors.ErrArchiveInvalid
+		}
+		userID, ok := r.member(review.UserEmail)
+		if !ok {
+			continue
+		}
+
+		review.BookID = bookID
+		review.UserID = userID
+		r.library.Reviews = append(r.library.Reviews, review)
+	}
+	r.response.Reviews = len(r.library.Reviews)
+	return nil
+}
+
+func (r *restore) remapAudits(archived *entities.Library) error {
+	for _, audit := range archived.Audits {
+		locationID, ok := r.locations[audit.LocationID]
+		if !ok {
+			return errors.ErrArchiveInvalid
+		}
+		startedBy, ok := r.member(audit.StartedByEmail)
+		if !ok {
+			continue
+		}
+
+		// The scans keep the short IDs read, which the copies kept too.
+		audit.AuditID = uuid.New()
+		audit.LocationID = locationID
+		audit.StartedBy = startedBy
+		r.library.Audits = append(r.library.Audits, audit)
+	}
+	r.response.Audits = len(r.library.Audits)
+	return nil
+}
+
+func (r *restore) remapEbooks(archived *entities.Library) error {
+	for _, ebook := range archived.Ebooks {
+		if !validSHA256(ebook.SHA256) || !validFormat(ebook.Format) {
+			return errors.ErrArchiveInvalid
+		}
+		ownerID, ok := r.member(ebook.OwnerEmail)
+		if !ok {
+			continue
+		}
+		if _, ok := r.files[ebookKey(ebook.SHA256)]; !ok {
+			r.response.SkippedItems++
+			continue
+		}
+
+		r.ebooks[ebook.FileID] = uuid.New()
+		ebook.FileID = r.ebooks[ebook.FileID]
+		ebook.OwnerID = ownerID
+		ebook.Authors = nonNil(ebook.Authors)
+		ebook.Tags = nonNil(ebook.Tags)
+		ebook.CoverID = r.cover(ebook.CoverID)
+		// A file may be attached to a book of a household its owner has
+		// since left, which the archive does not hold.
+		if ebook.BookID != nil {
+			bookID, ok := r.books[*ebook.BookID]
+			if ok {
+				ebook.BookID = &bookID
+			} else {
+				ebook.BookID = nil
+			}
+		}
+		r.library.Ebooks = append(r.library.Ebooks, ebook)
+	}
+	r.response.Ebooks = len(r.library.Ebooks)
+	return nil
+}
+
+func (r *restore) remapQuotes(archived *entities.Library) error {
+	for _, quote := range archived.Quotes {
+		userID, ok := r.member(quote.UserEmail)
+		if !ok {
+			continue
+		}
+
+		// A quote outlives its ebook and its book, as it does when they
+		// are deleted.
+		if quote.FileID != nil {
+			if fileID, ok := r.ebooks[*quote.FileID]; ok {
+				quote.FileID = &fileID
+			} else {
+				quote.FileID = nil
+			}
+		}
+		if quote.BookID != nil {
+			if bookID, ok := r.books[*quote.BookID]; ok {
+				quote.BookID = &bookID
+			} else {
+				quote.BookID = nil
+			}
+		}
+		quote.UserID = userID
+		r.library.Quotes = append(r.library.Quotes, quote)
+	}
+	r.response.Quotes = len(r.library.Quotes)
+	return nil
+}
+
+// member returns the member with the email, counting the row as skipped if
+// there is none.
+func (r *restore) member(email string) (uuid.UUID, bool) {
+	userID, ok := r.members[strings.ToLower(email)]
+	if !ok {
+		r.response.SkippedItems++
+	}
+	return userID, ok
+}
+
+// cover returns the new ID of an archived cover, or nil if the archive does
+// not have it. Books and ebooks sharing a cover keep sharing it.
+func (r *restore) cover(coverID *uuid.UUID) *uuid.UUID {
+	if coverID == nil {
+		return nil
+	}
+	if _, ok := r.files[coverEntities.VariantLarge.Key(*coverID)]; !ok {
+		return nil
+	}
+
+	newID, ok := r.covers[*coverID]
+	if !ok {
+		newID = uuid.New()
+		r.covers[*coverID] = newID
+	}
+	return &newID
+}
+
+// uniqueShortID checks an archived short ID and that seen does not have it
+// yet.
+func uniqueShortID(id string, seen map[string]bool) (string, error) {
+	id, err := shortid.Normalize(id)
+	if err != nil || seen[id] {
+		return "", errors.ErrArchiveInvalid
+	}
+	seen[id] = true
+	return id, nil
+}
+
+func validSHA256(sum string) bool {
+	decoded, err := hex.DecodeString(sum)
+	return err == nil && len(decoded) == 32 && sum == strings.ToLower(sum)
+}
+
+func validFormat(format string) bool {
+	switch ebookEntities.Format(format) {
+	case ebookEntities.FormatEPUB, ebookEntities.FormatPDF, ebookEntities.FormatFB2, ebookEntities.FormatFB2Zip:
+		return true
+	default:
+		return false
+	}
+}
+
+func nonNil(values pq.StringArray) pq.StringArray {
+	if values == nil {
+		return pq.StringArray{}
+	}
+	return values
+}
+
+func ebookKey(sha256 string) string {
+	file := ebookEntities.EbookFile{SHA256: sha256}
+	return file.BlobKey()
+}
+
+func ebookContentType(format string) string {
+	return ebookEntities.Format(format).ContentType()
+}
@@ -0,0 +1,514 @@
+package usecases
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	stdErrors "errors"
+	"home-library/internal/services/archive/dtos"
+	"home-library/internal/services/archive/entities"
+	"home-library/internal/services/archive/repository"
+	coverEntities "home-library/internal/services/cover/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	wishlistEntities "home-library/internal/services/wishlist/entities"
+	wishlistRepository "home-library/internal/services/wishlist/repository"
+	"home-library/pkg/blobstore"
+	"home-library/pkg/errors"
+	"home-library/pkg/transaction"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type UseCase interface {
+	Export(ctx context.Context, userID uuid.UUID, w io.Writer) error
+	ExportHousehold(ctx context.Context, householdID uuid.UUID, w io.Writer) error
+	Import(ctx context.Context, userID uuid.UUID, r io.ReaderAt, size int64) (*dtos.ImportResponse, error)
+	ImportHousehold(ctx context.Context, householdID uuid.UUID, r io.ReaderAt, size int64) (*dtos.ImportResponse, error)
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	wishlists  wishlistRepository.Repository
+	store      blobstore.Store
+	tx         transaction.Transactor
+}
+
+func NewUseCase(
+	r repository.Repository,
+	households householdRepository.Repository,
+	wishlists wishlistRepository.Repository,
+	store blobstore.Store,
+	tx transaction.Transactor,
+) UseCase {
+	return &useCase{r: r, households: households, wishlists: wishlists, store: store, tx: tx}
+}
+
+// Export writes the caller's household archive. It holds every member's
+// wishlist, readings and quotes, so only those who can edit the library may
+// take it. Nothing is written to w if the caller may not.
+func (u *useCase) Export(ctx context.Context, userID uuid.UUID, w io.Writer) error {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	if !member.Role.CanEditLibrary() {
+		return errors.ErrHouseholdForbidden
+	}
+
+	return u.ExportHousehold(ctx, member.HouseholdID, w)
+}
+
+func (u *useCase) ExportHousehold(ctx context.Context, householdID uuid.UUID, w io.Writer) error {
+	household, err := u.households.GetHousehold(ctx, householdID)
+	if err != nil {
+		return mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+
+	members, err := u.households.GetMembers(ctx, householdID)
+	if err != nil {
+		return err
+	}
+
+	archiveMembers := make([]entities.Member, len(members))
+	memberIDs := make([]uuid.UUID, len(members))
+	wishlist := make([]entities.WishlistItem, 0)
+	for i, member := range members {
+		memberIDs[i] = member.UserID
+		archiveMembers[i] = entities.Member{
+			Email:     member.Email,
+			FirstName: member.FirstName,
+			LastName:  member.LastName,
+			Role:      string(member.Role),
+		}
+
+		items, err := u.wishlists.GetItemsByUser(ctx, member.UserID)
+		if err != nil {
+			return err
+		}
+		// Reservations are left out on purpose: they are tied to user IDs and
+		// would spoil the surprise for anyone reading the archive.
+		for _, item := range items {
+			wishlist = append(wishlist, entities.WishlistItem{
+				OwnerEmail:    member.Email,
+				ISBN:          item.ISBN,
+				Title:         item.Title,
+				Author:        item.Author,
+				Priority:      string(item.Priority),
+				Notes:         item.Notes,
+				PurchasePrice: item.PurchasePrice,
+				PurchasedAt:   item.PurchasedAt,
+				PurchaseStore: item.PurchaseStore,
+				CreatedAt:     item.CreatedAt,
+			})
+		}
+	}
+
+	library, err := u.r.GetLibrary(ctx, householdID, memberIDs)
+	if err != nil {
+		return err
+	}
+
+	manifest := entities.Manifest{
+		SchemaVersion: entities.SchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		Household:     entities.Household{Name: household.Name},
+	}
+
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		v    any
+	}{
+		{entities.ManifestFile, manifest},
+		{entities.MembersFile, archiveMembers},
+		{entities.WishlistFile, wishlist},
+		{entities.LocationsFile, library.Locations},
+		{entities.BooksFile, library.Books},
+		{entities.CopiesFile, library.Copies},
+		{entities.LoansFile, library.Loans},
+		{entities.ReadingsFile, library.Readings},
+		{entities.ReviewsFile, library.Reviews},
+		{entities.AuditsFile, library.Audits},
+		{entities.EbooksFile, library.Ebooks},
+		{entities.QuotesFile, library.Quotes},
+	}
+	for _, file := range files {
+		if err := writeJSON(zw, file.name, file.v); err != nil {
+			return err
+		}
+	}
+	if err := writeWishlistCSV(zw, wishlist); err != nil {
+		return err
+	}
+	if err := writeBooksCSV(zw, library.Books); err != nil {
+		return err
+	}
+
+	for _, key := range blobKeys(library) {
+		if err := u.writeBlob(ctx, zw, key); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func (u *useCase) Import(ctx context.Context, userID uuid.UUID, r io.ReaderAt, size int64) (*dtos.ImportResponse, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	if !member.Role.CanManageMembers() {
+		return nil, errors.ErrHouseholdForbidden
+	}
+
+	return u.ImportHousehold(ctx, member.HouseholdID, r, size)
+}
+
+// ImportHousehold restores an archive into a household that has no library
+// data yet. Wishlists, readings, reviews, loans, audits, ebooks and quotes
+// are matched to members by email; those of people who are not in the
+// household are skipped.
+func (u *useCase) ImportHousehold(ctx context.Context, householdID uuid.UUID, r io.ReaderAt, size int64) (*dtos.ImportResponse, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.ErrArchiveInvalid
+	}
+
+	var manifest entities.Manifest
+	if err := readJSON(zr, entities.ManifestFile, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion < 1 || manifest.SchemaVersion > entities.SchemaVersion {
+		return nil, errors.ErrArchiveVersion
+	}
+
+	var wishlist []entities.WishlistItem
+	if err := readJSON(zr, entities.WishlistFile, &wishlist); err != nil {
+		return nil, err
+	}
+	library, err := readLibrary(zr, manifest.SchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := u.households.GetMembers(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	membersByEmail := make(map[string]uuid.UUID, len(members))
+	memberIDs := make([]uuid.UUID, len(members))
+	for i, member := range members {
+		membersByEmail[strings.ToLower(member.Email)] = member.UserID
+		memberIDs[i] = member.UserID
+	}
+
+	// Checked early so a household that is not empty costs no upload, and
+	// again in the transaction, which makes the check hold for the restore.
+	if err := u.requireEmpty(ctx, householdID, memberIDs); err != nil {
+		return nil, err
+	}
+
+	response := &dtos.ImportResponse{}
+	restore := newRestore(zr, membersByEmail, response)
+	if err := restore.remap(library); err != nil {
+		return nil, err
+	}
+
+	var items []*wishlistEntities.WishlistItem
+	for _, archived := range wishlist {
+		userID, ok := membersByEmail[strings.ToLower(archived.OwnerEmail)]
+		if !ok || (archived.ISBN == "" && archived.Title == "") {
+			response.SkippedItems++
+			continue
+		}
+
+		item := wishlistEntities.NewWishlistItem(userID)
+		item.ISBN = archived.ISBN
+		item.Title = archived.Title
+		item.Author = archived.Author
+		item.Notes = archived.Notes
+		if archived.PurchasePrice != nil && *archived.PurchasePrice >= 0 {
+			item.PurchasePrice = archived.PurchasePrice
+		}
+		item.PurchasedAt = archived.PurchasedAt
+		item.PurchaseStore = archived.PurchaseStore
+		switch priority := wishlistEntities.Priority(archived.Priority); priority {
+		case wishlistEntities.PriorityLow, wishlistEntities.PriorityMedium, wishlistEntities.PriorityHigh:
+			item.Priority = priority
+		}
+		if !archived.CreatedAt.IsZero() {
+			item.CreatedAt = archived.CreatedAt
+		}
+		items = append(items, item)
+	}
+
+	// Blobs are stored outside the transaction, which may run more than
+	// once, and removed if it fails.
+	stored, err := u.storeBlobs(ctx, restore)
+	if err != nil {
+		u.cleanup(stored)
+		return nil, err
+	}
+
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		if err := u.requireEmpty(ctx, householdID, memberIDs); err != nil {
+			return err
+		}
+		if err := u.r.RestoreLibrary(ctx, householdID, &restore.library); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if _, err := u.wishlists.CreateItem(ctx, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		u.cleanup(stored)
+		return nil, err
+	}
+	response.WishlistItems = len(items)
+
+	return response, nil
+}
+
+func (u *useCase) requireEmpty(ctx context.Context, householdID uuid.UUID, memberIDs []uuid.UUID) error {
+	hasLibrary, err := u.r.HasLibrary(ctx, householdID, memberIDs)
+	if err != nil {
+		return err
+	}
+	if hasLibrary {
+		return errors.ErrHouseholdNotEmpty
+	}
+	return nil
+}
+
+// storeBlobs copies the covers and ebook files of the restored library from
+// the archive to the blob store and returns the keys it stored. Ebook files
+// are stored by content, so one already there is kept as is.
+func (u *useCase) storeBlobs(ctx context.Context, restore *restore) ([]string, error) {
+	var stored []string
+	for archivedID, coverID := range restore.covers {
+		for _, variant := range coverEntities.Variants {
+			file, ok := restore.files[variant.Key(archivedID)]
+			if !ok {
+				continue
+			}
+			if err := u.putBlob(ctx, file, variant.Key(coverID), "image/jpeg", ""); err != nil {
+				return stored, err
+			}
+			stored = append(stored, variant.Key(coverID))
+		}
+	}
+
+	for _, ebook := range restore.library.Ebooks {
+		key := ebookKey(ebook.SHA256)
+		if _, err := u.store.Stat(ctx, key); err == nil {
+			continue
+		} else if !stdErrors.Is(err, blobstore.ErrNotFound) {
+			return stored, err
+		}
+		if err := u.putBlob(ctx, restore.files[key], key, ebookContentType(ebook.Format), ebook.SHA256); err != nil {
+			return stored, err
+		}
+		stored = append(stored, key)
+	}
+
+	return stored, nil
+}
+
+// putBlob stores a file of the archive under key. A non-empty sum is the
+// SHA-256 the content must have.
+func (u *useCase) putBlob(ctx context.Context, file *zip.File, key string, contentType string, sum string) error {
+	f, err := file.Open()
+	if err != nil {
+		return errors.ErrArchiveInvalid
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if err := u.store.Put(ctx, key, io.TeeReader(f, hash), int64(file.UncompressedSize64), contentType); err != nil {
+		return err
+	}
+	if sum != "" && hex.EncodeToString(hash.Sum(nil)) != sum {
+		if err := u.store.Delete(ctx, key); err != nil {
+			return err
+		}
+		return errors.ErrArchiveInvalid
+	}
+	return nil
+}
+
+// cleanup removes the blobs of a failed import. It runs detached from the
+// request context, which is likely what failed the import.
+func (u *useCase) cleanup(keys []string) {
+	for _, key := range keys {
+		if err := u.store.Delete(context.Background(), key); err != nil {
+			log.Error().Err(err).Str("key", key).Msg("failed to clean up imported blob")
+		}
+	}
+}
+
+// writeBlob copies a stored object into the archive under its key. A
+// missing object is left out: the import drops covers and ebooks it cannot
+// find.
+func (u *useCase) writeBlob(ctx context.Context, zw *zip.Writer, key string) error {
+	object, err := u.store.Get(ctx, key)
+	if stdErrors.Is(err, blobstore.ErrNotFound) {
+		log.Warn().Str("key", key).Msg("blob to export is missing")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	// Covers and ebooks are compressed already.
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: key, Method: zip.Store, Modified: object.LastModified})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, object)
+	return err
+}
+
+// blobKeys lists the covers and ebook files of the library, each once.
+func blobKeys(library *entities.Library) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	addCover := func(coverID *uuid.UUID) {
+		if coverID == nil {
+			return
+		}
+		for _, variant := range coverEntities.Variants {
+			add(variant.Key(*coverID))
+		}
+	}
+	for _, book := range library.Books {
+		addCover(book.CoverID)
+	}
+	for _, ebook := range library.Ebooks {
+		addCover(ebook.CoverID)
+		add(ebookKey(ebook.SHA256))
+	}
+
+	return keys
+}
+
+// readLibrary reads the library files, which version 1 archives do not
+// have.
+func readLibrary(zr *zip.Reader, version int) (*entities.Library, error) {
+	library := &entities.Library{}
+	if version < 2 {
+		return library, nil
+	}
+
+	files := []struct {
+		name string
+		v    any
+	}{
+		{entities.LocationsFile, &library.Locations},
+		{entities.BooksFile, &library.Books},
+		{entities.CopiesFile, &library.Copies},
+		{entities.LoansFile, &library.Loans},
+		{entities.ReadingsFile, &library.Readings},
+		{entities.ReviewsFile, &library.Reviews},
+		{entities.AuditsFile, &library.Audits},
+		{entities.EbooksFile, &library.Ebooks},
+		{entities.QuotesFile, &library.Quotes},
+	}
+	for _, file := range files {
+		if err := readJSON(zr, file.name, file.v); err != nil {
+			return nil, err
+		}
+	}
+	return library, nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeWishlistCSV(zw *zip.Writer, items []entities.WishlistItem) error {
+	f, err := zw.Create(entities.WishlistCSVFile)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(f)
+	if err := w.Write(entities.WishlistCSVHeader); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := w.Write(item.CSVRecord()); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeBooksCSV(zw *zip.Writer, books []entities.Book) error {
+	f, err := zw.Create(entities.BooksCSVFile)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(f)
+	if err := w.Write(entities.BooksCSVHeader); err != nil {
+		return err
+	}
+	for _, book := range books {
+		if err := w.Write(book.CSVRecord()); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func readJSON(zr *zip.Reader, name string, v interface{}) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return errors.ErrArchiveInvalid
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return errors.ErrArchiveInvalid
+	}
+	return nil
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
@@ -0,0 +1,549 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"home-library/internal/services/archive/dtos"
+	"home-library/internal/services/archive/entities"
+	coverEntities "home-library/internal/services/cover/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	wishlistEntities "home-library/internal/services/wishlist/entities"
+	"home-library/pkg/blobstore"
+	customErrors "home-library/pkg/errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) HasLibrary(ctx context.Context, householdID uuid.UUID, memberIDs []uuid.UUID) (bool, error) {
+	args := m.Called(ctx, householdID, memberIDs)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) GetLibrary(ctx context.Context, householdID uuid.UUID, memberIDs []uuid.UUID) (*entities.Library, error) {
+	args := m.Called(ctx, householdID, memberIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Library), args.Error(1)
+}
+
+func (m *MockRepository) RestoreLibrary(ctx context.Context, householdID uuid.UUID, library *entities.Library) error {
+	return m.Called(ctx, householdID, library).Error(0)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) CreateItem(ctx context.Context, item *wishlistEntities.WishlistItem) (uuid.UUID, error) {
+	args := m.Called(ctx, item)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetItemsByUser(ctx context.Context, userID uuid.UUID) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetSharedItem(ctx context.Context, itemID uuid.UUID, viewerID uuid.UUID) (*wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, itemID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetSharedItemsByUser(ctx context.Context, ownerID uuid.UUID, viewerID uuid.UUID) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, ownerID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) FindItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbns []string) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) TakeItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbn string) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) UpdateItem(ctx context.Context, item *wishlistEntities.WishlistItem) error {
+	return m.Called(ctx, item).Error(0)
+}
+
+func (m *MockWishlistRepository) DeleteItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, itemID, userID).Error(0)
+}
+
+func (m *MockWishlistRepository) ReserveItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID, reservedAt time.Time) error {
+	return m.Called(ctx, itemID, userID, reservedAt).Error(0)
+}
+
+func (m *MockWishlistRepository) CancelReservation(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, itemID, userID).Error(0)
+}
+
+type passthroughTx struct{}
+
+func (passthroughTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	households *MockHouseholdRepository
+	wishlists  *MockWishlistRepository
+	store      blobstore.Store
+}
+
+func newUseCase(t *testing.T) (UseCase, *mocks) {
+	store, err := blobstore.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	m := &mocks{
+		repo:       new(MockRepository),
+		households: new(MockHouseholdRepository),
+		wishlists:  new(MockWishlistRepository),
+		store:      store,
+	}
+	return NewUseCase(m.repo, m.households, m.wishlists, m.store, passthroughTx{}), m
+}
+
+func (m *mocks) put(t *testing.T, key string, data string) {
+	require.NoError(t, m.store.Put(context.Background(), key, strings.NewReader(data), int64(len(data)), ""))
+}
+
+func (m *mocks) get(t *testing.T, key string) string {
+	object, err := m.store.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// ebookData is the content of the archived ebook, with its SHA-256.
+const (
+	ebookData   = "epub"
+	ebookSHA256 = "83e895b8f0af41ca0905b4e89c6979eab60b36a62bdb8efc4730dfd446aded7f"
+)
+
+// archivedIDs are the IDs rows have in the exported archive.
+var archivedIDs = struct {
+	location, book, copy, cover, file uuid.UUID
+}{uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+
+func exportArchive(t *testing.T) []byte {
+	useCase, m := newUseCase(t)
+
+	householdID, ownerID, memberID := uuid.New(), uuid.New(), uuid.New()
+	reservedBy := uuid.New()
+	createdAt := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	year := 1972
+
+	m.households.On("GetHousehold", mock.Anything, householdID).Return(&householdEntities.Household{HouseholdID: householdID, Name: "Koveshnikovs"}, nil)
+	m.households.On("GetMembers", mock.Anything, householdID).Return([]householdEntities.Member{
+		{UserID: ownerID, Email: "evgeny@example.com", FirstName: "Evgeny", Role: householdEntities.RoleOwner},
+		{UserID: memberID, Email: "anna@example.com", FirstName: "Anna", Role: householdEntities.RoleViewer},
+	}, nil)
+	m.wishlists.On("GetItemsByUser", mock.Anything, ownerID).Return([]wishlistEntities.WishlistItem{
+		{ISBN: "9780441013593", Priority: wishlistEntities.PriorityHigh, ReservedBy: &reservedBy, CreatedAt: createdAt},
+	}, nil)
+	m.wishlists.On("GetItemsByUser", mock.Anything, memberID).Return([]wishlistEntities.WishlistItem{
+		{Title: "Солярис", Author: "Лем", Priority: wishlistEntities.PriorityLow, Notes: "в твёрдой обложке", CreatedAt: createdAt},
+	}, nil)
+	m.repo.On("GetLibrary", mock.Anything, householdID, []uuid.UUID{ownerID, memberID}).Return(&entities.Library{
+		Locations: []entities.Location{{LocationID: archivedIDs.location, ShortID: "K7M2Q9X0", Name: "Гостиная"}},
+		Books: []entities.Book{{
+			BookID: archivedIDs.book, Title: "Пикник на обочине", Authors: pq.StringArray{"Аркадий Стругацкий", "Борис Стругацкий"},
+			PublishedYear: &year, Tags: pq.StringArray{}, CoverID: &archivedIDs.cover,
+		}},
+		Copies: []entities.Copy{{CopyID: archivedIDs.copy, ShortID: "A1B2C3D4", BookID: archivedIDs.book, LocationID: &archivedIDs.location}},
+		Loans:  []entities.Loan{{LoanID: uuid.New(), CopyID: archivedIDs.copy, BorrowerName: "Оля", LentBy: ownerID, LentByEmail: "evgeny@example.com", LentAt: createdAt}},
+		Readings: []entities.Reading{
+			{BookID: archivedIDs.book, UserID: ownerID, UserEmail: "evgeny@example.com", FinishedAt: &createdAt},
+			{BookID: archivedIDs.book, UserID: memberID, UserEmail: "anna@example.com"},
+		},
+		Reviews: []entities.Review{{BookID: archivedIDs.book, UserID: ownerID, UserEmail: "evgeny@example.com", Rating: 5}},
+		Audits: []entities.Audit{{
+			AuditID: uuid.New(), LocationID: archivedIDs.location, StartedBy: ownerID, StartedByEmail: "evgeny@example.com", Status: "closed",
+			Found: 1, Scans: []entities.AuditScan{{Code: "A1B2C3D4", ScannedAt: createdAt}},
+		}},
+		Ebooks: []entities.Ebook{{
+			FileID: archivedIDs.file, OwnerID: ownerID, OwnerEmail: "evgeny@example.com", SHA256: ebookSHA256, Format: "epub",
+			Size: int64(len(ebookData)), Title: "Пикник на обочине", Authors: pq.StringArray{}, Tags: pq.StringArray{},
+			BookID: &archivedIDs.book,
+		}},
+		Quotes: []entities.Quote{{UserID: ownerID, UserEmail: "evgeny@example.com", BookID: &archivedIDs.book, FileID: &archivedIDs.file, Source: "koreader", Kind: "highlight", Text: "Счастья для всех, даром", Fingerprint: strings.Repeat("f", 64)}},
+	}, nil)
+
+	for _, variant := range coverEntities.Variants {
+		m.put(t, variant.Key(archivedIDs.cover), string(variant))
+	}
+	m.put(t, ebookKey(ebookSHA256), ebookData)
+
+	var buf bytes.Buffer
+	require.NoError(t, useCase.ExportHousehold(context.Background(), householdID, &buf))
+	return buf.Bytes()
+}
+
+func TestExportHousehold(t *testing.T) {
+	data := exportArchive(t)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+
+	names := make([]string, len(zr.File))
+	for i, f := range zr.File {
+		names[i] = f.Name
+	}
+	assert.Equal(t, []string{
+		entities.ManifestFile, entities.MembersFile, entities.WishlistFile, entities.LocationsFile, entities.BooksFile,
+		entities.CopiesFile, entities.LoansFile, entities.ReadingsFile, entities.ReviewsFile, entities.AuditsFile,
+		entities.EbooksFile, entities.QuotesFile, entities.WishlistCSVFile, entities.BooksCSVFile,
+		coverEntities.VariantLarge.Key(archivedIDs.cover),
+		coverEntities.VariantMedium.Key(archivedIDs.cover),
+		coverEntities.VariantThumbnail.Key(archivedIDs.cover),
+		ebookKey(ebookSHA256),
+	}, names)
+
+	f, err := zr.Open(entities.WishlistCSVFile)
+	require.NoError(t, err)
+	csv, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Contains(t, string(csv), "evgeny@example.com,9780441013593,,,high,,2024-12-01T10:00:00Z")
+	assert.Contains(t, string(csv), "anna@example.com,,Солярис,Лем,low,в твёрдой обложке,")
+	assert.NotContains(t, string(csv), "reserved")
+
+	f, err = zr.Open(entities.BooksCSVFile)
+	require.NoError(t, err)
+	csv, err = io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Contains(t, string(csv), "Пикник на обочине,Аркадий Стругацкий; Борис Стругацкий,,,,1972,,,,")
+
+	f, err = zr.Open(entities.LoansFile)
+	require.NoError(t, err)
+	loans, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Contains(t, string(loans), `"lent_by_email": "evgeny@example.com"`)
+	assert.NotContains(t, string(loans), `"lent_by":`)
+}
+
+func TestImportHousehold(t *testing.T) {
+	t.Run("restores the library under fresh IDs for matching members", func(t *testing.T) {
+		data := exportArchive(t)
+		useCase, m := newUseCase(t)
+		householdID, userID := uuid.New(), uuid.New()
+
+		m.households.On("GetMembers", mock.Anything, householdID).Return([]householdEntities.Member{
+			{UserID: userID, Email: "Evgeny@Example.com", Role: householdEntities.RoleOwner},
+		}, nil)
+		m.repo.On("HasLibrary", mock.Anything, householdID, []uuid.UUID{userID}).Return(false, nil)
+		var restored *entities.Library
+		m.repo.On("RestoreLibrary", mock.Anything, householdID, mock.MatchedBy(func(library *entities.Library) bool {
+			restored = library
+			return true
+		})).Return(nil)
+		m.wishlists.On("CreateItem", mock.Anything, mock.MatchedBy(func(item *wishlistEntities.WishlistItem) bool {
+			return item.UserID == userID &&
+				item.ISBN == "9780441013593" &&
+				item.Priority == wishlistEntities.PriorityHigh &&
+				item.ReservedBy == nil &&
+				item.CreatedAt.Equal(time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC))
+		})).Return(uuid.New(), nil).Once()
+
+		result, err := useCase.ImportHousehold(context.Background(), householdID, bytes.NewReader(data), int64(len(data)))
+
+		require.NoError(t, err)
+		assert.Equal(t, dtos.ImportResponse{
+			WishlistItems: 1, Locations: 1, Books: 1, Copies: 1, Loans: 1, Readings: 1, Reviews: 1, Audits: 1, Ebooks: 1, Quotes: 1,
+			// Anna's wishlist item and reading.
+			SkippedItems: 2,
+		}, *result)
+		m.wishlists.AssertExpectations(t)
+
+		require.NotNil(t, restored)
+		location, book, copy := restored.Locations[0], restored.Books[0], restored.Copies[0]
+		assert.NotEqual(t, archivedIDs.location, location.LocationID)
+		assert.Equal(t, "K7M2Q9X0", location.ShortID)
+		assert.NotEqual(t, archivedIDs.book, book.BookID)
+		assert.Equal(t, book.BookID, copy.BookID)
+		assert.Equal(t, &location.LocationID, copy.LocationID)
+		assert.Equal(t, "A1B2C3D4", copy.ShortID)
+		assert.Equal(t, copy.CopyID, restored.Loans[0].CopyID)
+		assert.Equal(t, userID, restored.Loans[0].LentBy)
+		assert.Equal(t, book.BookID, restored.Readings[0].BookID)
+		assert.Equal(t, userID, restored.Reviews[0].UserID)
+		assert.Equal(t, location.LocationID, restored.Audits[0].LocationID)
+		assert.Equal(t, &restored.Ebooks[0].FileID, restored.Quotes[0].FileID)
+		assert.Equal(t, &book.BookID, restored.Ebooks[0].BookID)
+		assert.Equal(t, &book.BookID, restored.Quotes[0].BookID)
+
+		require.NotNil(t, book.CoverID)
+		assert.NotEqual(t, archivedIDs.cover, *book.CoverID)
+		assert.Equal(t, "thumbnail", m.get(t, coverEntities.VariantThumbnail.Key(*book.CoverID)))
+		assert.Equal(t, ebookData, m.get(t, ebookKey(ebookSHA256)))
+	})
+
+	t.Run("failed restore removes the stored blobs", func(t *testing.T) {
+		data := exportArchive(t)
+		useCase, m := newUseCase(t)
+		householdID, userID := uuid.New(), uuid.New()
+
+		m.households.On("GetMembers", mock.Anything, householdID).Return([]householdEntities.Member{{UserID: userID, Email: "evgeny@example.com"}}, nil)
+		m.repo.On("HasLibrary", mock.Anything, householdID, []uuid.UUID{userID}).Return(false, nil)
+		m.repo.On("RestoreLibrary", mock.Anything, householdID, mock.Anything).Return(assert.AnError)
+
+		_, err := useCase.ImportHousehold(context.Background(), householdID, bytes.NewReader(data), int64(len(data)))
+
+		assert.ErrorIs(t, err, assert.AnError)
+		_, err = m.store.Stat(context.Background(), ebookKey(ebookSHA256))
+		assert.ErrorIs(t, err, blobstore.ErrNotFound)
+		m.wishlists.AssertNotCalled(t, "CreateItem", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failing wishlist item undoes the restore", func(t *testing.T) {
+		data := exportArchive(t)
+		useCase, m := newUseCase(t)
+		householdID, userID := uuid.New(), uuid.New()
+
+		m.households.On("GetMembers", mock.Anything, householdID).Return([]householdEntities.Member{{UserID: userID, Email: "evgeny@example.com"}}, nil)
+		m.repo.On("HasLibrary", mock.Anything, householdID, []uuid.UUID{userID}).Return(false, nil)
+		m.repo.On("RestoreLibrary", mock.Anything, householdID, mock.Anything).Return(nil)
+		m.wishlists.On("CreateItem", mock.Anything, mock.Anything).Return(uuid.Nil, assert.AnError)
+
+		_, err := useCase.ImportHousehold(context.Background(), householdID, bytes.NewReader(data), int64(len(data)))
+
+		assert.ErrorIs(t, err, assert.AnError)
+		_, err = m.store.Stat(context.Background(), ebookKey(ebookSHA256))
+		assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	})
+
+	t.Run("household is not empty", func(t *testing.T) {
+		data := exportArchive(t)
+		useCase, m := newUseCase(t)
+		householdID, userID := uuid.New(), uuid.New()
+
+		m.households.On("GetMembers", mock.Anything, householdID).Return([]householdEntities.Member{{UserID: userID}}, nil)
+		m.repo.On("HasLibrary", mock.Anything, householdID, []uuid.UUID{userID}).Return(true, nil)
+
+		_, err := useCase.ImportHousehold(context.Background(), householdID, bytes.NewReader(data), int64(len(data)))
+
+		assert.Equal(t, customErrors.ErrHouseholdNotEmpty, err)
+		m.repo.AssertNotCalled(t, "RestoreLibrary", mock.Anything, mock.Anything, mock.Anything)
+		m.wishlists.AssertNotCalled(t, "CreateItem", mock.Anything, mock.Anything)
+	})
+
+	t.Run("copy of a book missing from the archive", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		files := map[string]string{
+			entities.ManifestFile: `{"schema_version": 2}`,
+			entities.WishlistFile: `[]`,
+			entities.CopiesFile:   `[{"copy_id": "` + uuid.NewString() + `", "short_id": "A1B2C3D4", "book_id": "` + uuid.NewString() + `"}]`,
+		}
+		for _, name := range []string{entities.LocationsFile, entities.BooksFile, entities.LoansFile, entities.ReadingsFile,
+			entities.ReviewsFile, entities.AuditsFile, entities.EbooksFile, entities.QuotesFile} {
+			files[name] = `[]`
+		}
+		for name, content := range files {
+			f, _ := zw.Create(name)
+			_, _ = f.Write([]byte(content))
+		}
+		require.NoError(t, zw.Close())
+
+		useCase, m := newUseCase(t)
+		householdID := uuid.New()
+		m.households.On("GetMembers", mock.Anything, householdID).Return([]householdEntities.Member{}, nil)
+		m.repo.On("HasLibrary", mock.Anything, householdID, []uuid.UUID{}).Return(false, nil)
+
+		_, err := useCase.ImportHousehold(context.Background(), householdID, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+		assert.Equal(t, customErrors.ErrArchiveInvalid, err)
+		m.repo.AssertNotCalled(t, "RestoreLibrary", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("newer schema version", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		f, _ := zw.Create(entities.ManifestFile)
+		_, _ = f.Write([]byte(`{"schema_version": 99}`))
+		require.NoError(t, zw.Close())
+
+		useCase, _ := newUseCase(t)
+
+		_, err := useCase.ImportHousehold(context.Background(), uuid.New(), bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+		assert.Equal(t, customErrors.ErrArchiveVersion, err)
+	})
+
+	t.Run("not a zip archive", func(t *testing.T) {
+		data := []byte("definitely not a zip")
+		useCase, _ := newUseCase(t)
+
+		_, err := useCase.ImportHousehold(context.Background(), uuid.New(), bytes.NewReader(data), int64(len(data)))
+
+		assert.Equal(t, customErrors.ErrArchiveInvalid, err)
+	})
+}
+
+func TestImport(t *testing.T) {
+	t.Run("only the owner can import", func(t *testing.T) {
+		useCase, m := newUseCase(t)
+		userID := uuid.New()
+
+		m.households.On("GetMembership", mock.Anything, userID).Return(&householdEntities.Member{UserID: userID, Role: householdEntities.RoleEditor}, nil)
+
+		_, err := useCase.Import(context.Background(), userID, bytes.NewReader(nil), 0)
+
+		assert.Equal(t, customErrors.ErrHouseholdForbidden, err)
+	})
+}
+
+func TestExport(t *testing.T) {
+	t.Run("not in household", func(t *testing.T) {
+		useCase, m := newUseCase(t)
+		userID := uuid.New()
+
+		m.households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+
+		var buf bytes.Buffer
+		err := useCase.Export(context.Background(), userID, &buf)
+
+		assert.Equal(t, customErrors.ErrHouseholdNotFound, err)
+		assert.Zero(t, buf.Len())
+	})
+
+	t.Run("viewer", func(t *testing.T) {
+		useCase, m := newUseCase(t)
+		userID := uuid.New()
+
+		m.households.On("GetMembership", mock.Anything, userID).
+			Return(&householdEntities.Member{HouseholdID: uuid.New(), UserID: userID, Role: householdEntities.RoleViewer}, nil)
+
+		var buf bytes.Buffer
+		err := useCase.Export(context.Background(), userID, &buf)
+
+		assert.Equal(t, customErrors.ErrHouseholdForbidden, err)
+		assert.Zero(t, buf.Len())
+		m.households.AssertNotCalled(t, "GetMembers", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,194 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/audit/dtos"
+	"home-library/internal/services/audit/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) StartAudit(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.StartAuditRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	audit, err := h.u.StartAudit(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to start audit")
+	}
+
+	if audit.Resumed {
+		return c.JSON(http.StatusOK, audit)
+	}
+	return c.JSON(http.StatusCreated, audit)
+}
+
+func (h *handler) GetAudits(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var request dtos.ListAuditsRequest
+	if err := c.Bind(&request); err != nil {
+		log.Error().Err(err).Msg("failed to bind query")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := request.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	audits, err := h.u.GetAudits(c.Request().Context(), userID, request)
+	if err != nil {
+		return h.handleError(c, err, "failed to get audits")
+	}
+
+	return c.JSON(http.StatusOK, audits)
+}
+
+func (h *handler) GetReport(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	auditID, err := uuid.Parse(c.Param("audit_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	report, err := h.u.GetReport(c.Request().Context(), userID, auditID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get audit report")
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func (h *handler) AddScans(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	auditID, err := uuid.Parse(c.Param("audit_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.ScansRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	result, err := h.u.AddScans(c.Request().Context(), userID, auditID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to add audit scans")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *handler) ApplyCorrections(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	auditID, err := uuid.Parse(c.Param("audit_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.CorrectionsRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	report, err := h.u.ApplyCorrections(c.Request().Context(), userID, auditID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to apply audit corrections")
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func (h *handler) CloseAudit(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	auditID, err := uuid.Parse(c.Param("audit_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	audit, err := h.u.CloseAudit(c.Request().Context(), userID, auditID)
+	if err != nil {
+		return h.handleError(c, err, "failed to close audit")
+	}
+
+	return c.JSON(http.StatusOK, audit)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrAuditNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Инвентаризация не найдена", nil))
+	case errors.Is(err, customErrors.ErrAuditOpen):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Инвентаризация этого места уже идёт", nil))
+	case errors.Is(err, customErrors.ErrAuditClosed):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Инвентаризация завершена", nil))
+	case errors.Is(err, customErrors.ErrAuditCorrection):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Экземпляр не числится среди расхождений", nil))
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	case errors.Is(err, customErrors.ErrLocationNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Место хранения не найдено", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"context"
+	"home-library/internal/services/audit/dtos"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) StartAudit(ctx context.Context, userID uuid.UUID, payload dtos.StartAuditRequest) (*dtos.AuditResponse, error) {
+	args := m.Called(ctx, userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.AuditResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetAudits(ctx context.Context, userID uuid.UUID, request dtos.ListAuditsRequest) ([]dtos.AuditResponse, error) {
+	args := m.Called(ctx, userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.AuditResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetReport(ctx context.Context, userID uuid.UUID, auditID uuid.UUID) (*dtos.ReportResponse, error) {
+	args := m.Called(ctx, userID, auditID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.ReportResponse), args.Error(1)
+}
+
+func (m *MockUseCase) AddScans(ctx context.Context, userID uuid.UUID, auditID uuid.UUID, payload dtos.ScansRequest) (*dtos.ScansResponse, error) {
+	args := m.Called(ctx, userID, auditID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.ScansResponse), args.Error(1)
+}
+
+func (m *MockUseCase) ApplyCorrections(ctx context.Context, userID uuid.UUID, auditID uuid.UUID, payload dtos.CorrectionsRequest) (*dtos.ReportResponse, error) {
+	args := m.Called(ctx, userID, auditID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.ReportResponse), args.Error(1)
+}
+
+func (m *MockUseCase) CloseAudit(ctx context.Context, userID uuid.UUID, auditID uuid.UUID) (*dtos.AuditResponse, error) {
+	args := m.Called(ctx, userID, auditID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.AuditResponse), args.Error(1)
+}
+
+func newContext(e *echo.Echo, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/audits", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestStartAudit(t *testing.T) {
+	e := echo.New()
+
+	t.Run("new audit is created", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, locationID := uuid.New(), uuid.New()
+
+		mockUseCase.On("StartAudit", mock.Anything, userID, dtos.StartAuditRequest{LocationID: locationID}).
+			Return(&dtos.AuditResponse{AuditID: uuid.New(), LocationID: locationID}, nil)
+
+		c, rec := newContext(e, `{"location_id": "`+locationID.String()+`"}`, userID)
+		err := h.StartAudit(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("open audit is resumed", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, locationID := uuid.New(), uuid.New()
+
+		mockUseCase.On("StartAudit", mock.Anything, userID, dtos.StartAuditRequest{LocationID: locationID}).
+			Return(&dtos.AuditResponse{AuditID: uuid.New(), LocationID: locationID, Resumed: true}, nil)
+
+		c, rec := newContext(e, `{"location_id": "`+locationID.String()+`"}`, userID)
+		err := h.StartAudit(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"resumed":true`)
+	})
+
+	t.Run("location is required", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{}`, uuid.New())
+		err := h.StartAudit(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestAddScans(t *testing.T) {
+	e := echo.New()
+
+	t.Run("closed audit conflicts", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, auditID := uuid.New(), uuid.New()
+
+		mockUseCase.On("AddScans", mock.Anything, userID, auditID, dtos.ScansRequest{Codes: []string{"K7M2Q9XD"}}).
+			Return(nil, customErrors.ErrAuditClosed)
+
+		c, rec := newContext(e, `{"codes": ["K7M2Q9XD"]}`, userID)
+		c.SetParamNames("audit_id")
+		c.SetParamValues(auditID.String())
+		err := h.AddScans(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("no codes", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{"codes": []}`, uuid.New())
+		c.SetParamNames("audit_id")
+		c.SetParamValues(uuid.New().String())
+		err := h.AddScans(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestApplyCorrections(t *testing.T) {
+	e := echo.New()
+
+	t.Run("nothing to correct", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{}`, uuid.New())
+		c.SetParamNames("audit_id")
+		c.SetParamValues(uuid.New().String())
+		err := h.ApplyCorrections(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("copy outside the report", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, auditID, copyID := uuid.New(), uuid.New(), uuid.New()
+
+		mockUseCase.On("ApplyCorrections", mock.Anything, userID, auditID, dtos.CorrectionsRequest{Move: []uuid.UUID{copyID}}).
+			Return(nil, customErrors.ErrAuditCorrection)
+
+		c, rec := newContext(e, `{"move": ["`+copyID.String()+`"]}`, userID)
+		c.SetParamNames("audit_id")
+		c.SetParamValues(auditID.String())
+		err := h.ApplyCorrections(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
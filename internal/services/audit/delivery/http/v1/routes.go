@@ -0,0 +1,12 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) AuditRoutes(domain *echo.Group) {
+	domain.POST("/audits", h.StartAudit)
+	domain.GET("/audits", h.GetAudits)
+	domain.GET("/audits/:audit_id", h.GetReport)
+	domain.POST("/audits/:audit_id/scans", h.AddScans)
+	domain.POST("/audits/:audit_id/corrections", h.ApplyCorrections)
+	domain.POST("/audits/:audit_id/close", h.CloseAudit)
+}
@@ -0,0 +1,120 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/audit/entities"
+	"time"
+)
+
+type StartAuditRequest struct {
+	LocationID uuid.UUID `json:"location_id" validate:"required"`
+}
+
+func (r *StartAuditRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type ListAuditsRequest struct {
+	LocationID string `query:"location_id" validate:"omitempty,uuid"`
+}
+
+func (r *ListAuditsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// ScansRequest carries what the scanner read, as it read it: short IDs typed
+// from labels or the URLs of their QR codes.
+type ScansRequest struct {
+	Codes []string `json:"codes" validate:"required,min=1,max=500,dive,required,max=512"`
+}
+
+func (r *ScansRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type ScansResponse struct {
+	Accepted int `json:"accepted"`
+	// Rejected are the codes that are not copy labels.
+	Rejected []string `json:"rejected"`
+}
+
+// CorrectionsRequest fixes the catalog from a report: misplaced copies are
+// moved to the audited location, and missing ones are taken off it.
+type CorrectionsRequest struct {
+	Move     []uuid.UUID `json:"move" validate:"required_without=Unshelve,max=1000"`
+	Unshelve []uuid.UUID `json:"unshelve" validate:"max=1000"`
+}
+
+func (r *CorrectionsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type AuditResponse struct {
+	AuditID      uuid.UUID       `json:"audit_id"`
+	LocationID   uuid.UUID       `json:"location_id"`
+	LocationName string          `json:"location_name"`
+	StartedBy    uuid.UUID       `json:"started_by"`
+	Status       entities.Status `json:"status"`
+	Scanned      int             `json:"scanned"`
+	// The counts are filled in when the audit is closed.
+	Found     int        `json:"found"`
+	Misplaced int        `json:"misplaced"`
+	Missing   int        `json:"missing"`
+	Unknown   int        `json:"unknown"`
+	CreatedAt time.Time  `json:"created_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	// Resumed tells that starting the audit found one already open.
+	Resumed bool `json:"resumed,omitempty"`
+}
+
+func NewAuditResponse(audit entities.Audit) AuditResponse {
+	return AuditResponse{
+		AuditID:      audit.AuditID,
+		LocationID:   audit.LocationID,
+		LocationName: audit.LocationName,
+		StartedBy:    audit.StartedBy,
+		Status:       audit.Status,
+		Scanned:      audit.Scanned,
+		Found:        audit.Found,
+		Misplaced:    audit.Misplaced,
+		Missing:      audit.Missing,
+		Unknown:      audit.Unknown,
+		CreatedAt:    audit.CreatedAt,
+		ClosedAt:     audit.ClosedAt,
+	}
+}
+
+type CopyItem struct {
+	CopyID  uuid.UUID `json:"copy_id"`
+	ShortID string    `json:"short_id"`
+	Title   string    `json:"title"`
+}
+
+func NewCopyItem(copy entities.Copy) CopyItem {
+	return CopyItem{CopyID: copy.CopyID, ShortID: copy.ShortID, Title: copy.Title}
+}
+
+// MisplacedItem is a copy found in the audited location that the catalog
+// keeps elsewhere, or nowhere.
+type MisplacedItem struct {
+	CopyItem
+	ExpectedLocationID   *uuid.UUID `json:"expected_location_id"`
+	ExpectedLocationName *string    `json:"expected_location_name"`
+}
+
+type ReportResponse struct {
+	Audit     AuditResponse   `json:"audit"`
+	Found     []CopyItem      `json:"found"`
+	Misplaced []MisplacedItem `json:"misplaced"`
+	// Missing are the copies kept in the location that were not scanned.
+	// Those on loan are listed apart, as they are not expected there.
+	Missing []CopyItem `json:"missing"`
+	OnLoan  []CopyItem `json:"on_loan"`
+	// Unknown are scanned short IDs no copy of the household has.
+	Unknown []string `json:"unknown"`
+}
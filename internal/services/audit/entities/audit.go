@@ -0,0 +1,62 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusOpen   Status = "open"
+	StatusClosed Status = "closed"
+)
+
+// Audit is a stocktake of one location. The counts are those of its report
+// when it was closed.
+type Audit struct {
+	AuditID     uuid.UUID  `db:"audit_id"`
+	HouseholdID uuid.UUID  `db:"household_id"`
+	LocationID  uuid.UUID  `db:"location_id"`
+	StartedBy   uuid.UUID  `db:"started_by"`
+	Status      Status     `db:"status"`
+	Found       int        `db:"found"`
+	Misplaced   int        `db:"misplaced"`
+	Missing     int        `db:"missing"`
+	Unknown     int        `db:"unknown"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ClosedAt    *time.Time `db:"closed_at"`
+	// LocationName and Scanned are read along with the audit and not
+	// stored.
+	LocationName string `db:"location_name"`
+	Scanned      int    `db:"scanned"`
+}
+
+func NewAudit(householdID uuid.UUID, locationID uuid.UUID, userID uuid.UUID) *Audit {
+	return &Audit{
+		AuditID:     uuid.New(),
+		HouseholdID: householdID,
+		LocationID:  locationID,
+		StartedBy:   userID,
+		Status:      StatusOpen,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Scan is a copy label read during an audit, by its short ID.
+type Scan struct {
+	AuditID   uuid.UUID `db:"audit_id"`
+	Code      string    `db:"code"`
+	ScannedAt time.Time `db:"scanned_at"`
+}
+
+// Copy is a copy of the household as the catalog has it.
+type Copy struct {
+	CopyID       uuid.UUID  `db:"copy_id"`
+	ShortID      string     `db:"short_id"`
+	Title        string     `db:"title"`
+	LocationID   *uuid.UUID `db:"location_id"`
+	LocationName *string    `db:"location_name"`
+	OnLoan       bool       `db:"on_loan"`
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/audit/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/storage"
+	"home-library/pkg/transaction"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// auditsLimit is how many of its latest audits a household sees.
+const auditsLimit = 100
+
+type Repository interface {
+	CreateAudit(ctx context.Context, audit *entities.Audit) error
+	GetAudit(ctx context.Context, householdID uuid.UUID, auditID uuid.UUID) (*entities.Audit, error)
+	GetOpenAudit(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*entities.Audit, error)
+	// GetAudits lists the latest audits of the household, or of one of its
+	// locations, newest first.
+	GetAudits(ctx context.Context, householdID uuid.UUID, locationID *uuid.UUID) ([]entities.Audit, error)
+	// CloseAudit saves the counts of an open audit and closes it.
+	CloseAudit(ctx context.Context, audit *entities.Audit) error
+
+	// AddScans records the scans; a code scanned again keeps its latest
+	// time.
+	AddScans(ctx context.Context, scans []entities.Scan) error
+	GetScans(ctx context.Context, auditID uuid.UUID) ([]entities.Scan, error)
+
+	GetCopies(ctx context.Context, householdID uuid.UUID) ([]entities.Copy, error)
+	// MoveCopies puts the copies in the location, or in none, and returns
+	// how many it moved.
+	MoveCopies(ctx context.Context, householdID uuid.UUID, copyIDs []uuid.UUID, locationID *uuid.UUID) (int64, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+var constraints = storage.Constraints{
+	"idx_audits_open_location": errors.ErrAuditOpen,
+	"audits_location_fkey":     errors.ErrLocationNotFound,
+}
+
+const selectAudits = `
+	SELECT a.*, l.name AS location_name,
+		(SELECT count(*) FROM audit_scans s WHERE s.audit_id = a.audit_id) AS scanned
+	FROM audits a
+	JOIN locations l ON l.location_id = a.location_id
+`
+
+func (r *repository) CreateAudit(ctx context.Context, audit *entities.Audit) error {
+	query := `
+		INSERT INTO audits (audit_id, household_id, location_id, started_by, status, created_at)
+		VALUES (:audit_id, :household_id, :location_id, :started_by, :status, :created_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, audit)
+	return constraints.Map(err)
+}
+
+func (r *repository) GetAudit(ctx context.Context, householdID uuid.UUID, auditID uuid.UUID) (*entities.Audit, error) {
+	var audit entities.Audit
+	query := selectAudits + `WHERE a.household_id = $1 AND a.audit_id = $2`
+
+	err := r.db.GetContext(ctx, &audit, query, householdID, auditID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &audit, nil
+}
+
+func (r *repository) GetOpenAudit(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*entities.Audit, error) {
+	var audit entities.Audit
+	query := selectAudits + `WHERE a.household_id = $1 AND a.location_id = $2 AND a.status = 'open'`
+
+	err := r.db.GetContext(ctx, &audit, query, householdID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &audit, nil
+}
+
+func (r *repository) GetAudits(ctx context.Context, householdID uuid.UUID, locationID *uuid.UUID) ([]entities.Audit, error) {
+	audits := make([]entities.Audit, 0)
+	query := selectAudits + `
+		WHERE a.household_id = $1 AND ($2::uuid IS NULL OR a.location_id = $2)
+		ORDER BY a.created_at DESC
+		LIMIT $3
+	`
+
+	err := r.db.SelectContext(ctx, &audits, query, householdID, locationID, auditsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return audits, nil
+}
+
+func (r *repository) CloseAudit(ctx context.Context, audit *entities.Audit) error {
+	query := `
+		UPDATE audits
+		SET status = 'closed', found = :found, misplaced = :misplaced, missing = :missing, unknown = :unknown,
+			closed_at = :closed_at
+		WHERE household_id = :household_id AND audit_id = :audit_id AND status = 'open'
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, audit)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) AddScans(ctx context.Context, scans []entities.Scan) error {
+	if len(scans) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO audit_scans (audit_id, code, scanned_at)
+		VALUES (:audit_id, :code, :scanned_at)
+		ON CONFLICT (audit_id, code) DO UPDATE SET scanned_at = GREATEST(audit_scans.scanned_at, EXCLUDED.scanned_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, scans)
+	return err
+}
+
+func (r *repository) GetScans(ctx context.Context, auditID uuid.UUID) ([]entities.Scan, error) {
+	scans := make([]entities.Scan, 0)
+	query := `SELECT * FROM audit_scans WHERE audit_id = $1 ORDER BY scanned_at`
+
+	err := r.db.SelectContext(ctx, &scans, query, auditID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scans, nil
+}
+
+func (r *repository) GetCopies(ctx context.Context, householdID uuid.UUID) ([]entities.Copy, error) {
+	copies := make([]entities.Copy, 0)
+	query := `
+		SELECT c.copy_id, c.short_id, b.title, c.location_id, l.name AS location_name,
+			EXISTS (SELECT 1 FROM loans o WHERE o.copy_id = c.copy_id AND o.returned_at IS NULL) AS on_loan
+		FROM copies c
+		JOIN books b ON b.book_id = c.book_id
+		LEFT JOIN locations l ON l.location_id = c.location_id
+		WHERE c.household_id = $1
+	`
+
+	err := r.db.SelectContext(ctx, &copies, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return copies, nil
+}
+
+func (r *repository) MoveCopies(ctx context.Context, householdID uuid.UUID, copyIDs []uuid.UUID, locationID *uuid.UUID) (int64, error) {
+	if len(copyIDs) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		UPDATE copies
+		SET location_id = $3, updated_at = NOW()
+		WHERE household_id = $1 AND copy_id = ANY($2::uuid[])
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, idArray(copyIDs), locationID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func idArray(ids []uuid.UUID) pq.StringArray {
+	array := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		array[i] = id.String()
+	}
+	return array
+}
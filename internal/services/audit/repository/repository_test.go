@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/audit/entities"
+	"home-library/pkg/errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestCreateAudit(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("second open audit of a location conflicts", func(t *testing.T) {
+		audit := entities.NewAudit(uuid.New(), uuid.New(), uuid.New())
+
+		mock.ExpectExec(`INSERT INTO audits`).
+			WithArgs(audit.AuditID, audit.HouseholdID, audit.LocationID, audit.StartedBy, audit.Status, audit.CreatedAt).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_audits_open_location"})
+
+		err := repo.CreateAudit(context.Background(), audit)
+
+		assert.ErrorIs(t, err, errors.ErrAuditOpen)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMoveCopies(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("copies are taken off their shelf", func(t *testing.T) {
+		householdID, copyID := uuid.New(), uuid.New()
+
+		mock.ExpectExec(`UPDATE copies SET location_id = \$3, updated_at = NOW\(\) WHERE household_id = \$1 AND copy_id = ANY\(\$2::uuid\[\]\)`).
+			WithArgs(householdID, pq.StringArray{copyID.String()}, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		moved, err := repo.MoveCopies(context.Background(), householdID, []uuid.UUID{copyID}, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), moved)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,380 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/audit/dtos"
+	"home-library/internal/services/audit/entities"
+	"home-library/internal/services/audit/repository"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/pkg/audit"
+	"home-library/pkg/errors"
+	"home-library/pkg/scancode"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	// StartAudit opens an audit of the location, or resumes the one already
+	// open there.
+	StartAudit(ctx context.Context, userID uuid.UUID, payload dtos.StartAuditRequest) (*dtos.AuditResponse, error)
+	GetAudits(ctx context.Context, userID uuid.UUID, request dtos.ListAuditsRequest) ([]dtos.AuditResponse, error)
+	GetReport(ctx context.Context, userID uuid.UUID, auditID uuid.UUID) (*dtos.ReportResponse, error)
+	AddScans(ctx context.Context, userID uuid.UUID, auditID uuid.UUID, payload dtos.ScansRequest) (*dtos.ScansResponse, error)
+	// ApplyCorrections fixes the catalog from the report and returns the
+	// report as it is afterwards.
+	ApplyCorrections(ctx context.Context, userID uuid.UUID, auditID uuid.UUID, payload dtos.CorrectionsRequest) (*dtos.ReportResponse, error)
+	CloseAudit(ctx context.Context, userID uuid.UUID, auditID uuid.UUID) (*dtos.AuditResponse, error)
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	tx         transaction.Transactor
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository, tx transaction.Transactor) UseCase {
+	return &useCase{r: r, households: households, tx: tx}
+}
+
+func (u *useCase) StartAudit(ctx context.Context, userID uuid.UUID, payload dtos.StartAuditRequest) (*dtos.AuditResponse, error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := u.r.GetOpenAudit(ctx, member.HouseholdID, payload.LocationID)
+	if err == nil {
+		response := dtos.NewAuditResponse(*open)
+		response.Resumed = true
+		return &response, nil
+	}
+	if !stdErrors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	started := entities.NewAudit(member.HouseholdID, payload.LocationID, userID)
+	if err := u.r.CreateAudit(ctx, started); err != nil {
+		return nil, err
+	}
+
+	created, err := u.r.GetAudit(ctx, member.HouseholdID, started.AuditID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dtos.NewAuditResponse(*created)
+	return &response, nil
+}
+
+func (u *useCase) GetAudits(ctx context.Context, userID uuid.UUID, request dtos.ListAuditsRequest) ([]dtos.AuditResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var locationID *uuid.UUID
+	if request.LocationID != "" {
+		id, err := uuid.Parse(request.LocationID)
+		if err != nil {
+			return nil, errors.ErrLocationNotFound
+		}
+		locationID = &id
+	}
+
+	audits, err := u.r.GetAudits(ctx, member.HouseholdID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.AuditResponse, len(audits))
+	for i, audit := range audits {
+		response[i] = dtos.NewAuditResponse(audit)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) GetReport(ctx context.Context, userID uuid.UUID, auditID uuid.UUID) (*dtos.ReportResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stocktake, err := u.audit(ctx, member.HouseholdID, auditID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := u.report(ctx, stocktake)
+	if err != nil {
+		return nil, err
+	}
+
+	return report.response(), nil
+}
+
+func (u *useCase) AddScans(ctx context.Context, userID uuid.UUID, auditID uuid.UUID, payload dtos.ScansRequest) (*dtos.ScansResponse, error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stocktake, err := u.openAudit(ctx, member.HouseholdID, auditID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.ScansResponse{Rejected: make([]string, 0)}
+	scans := make([]entities.Scan, 0, len(payload.Codes))
+	seen := make(map[string]bool, len(payload.Codes))
+	now := time.Now()
+	for _, raw := range payload.Codes {
+		code := scancode.Parse(raw)
+		if code.Kind != scancode.KindCopy {
+			response.Rejected = append(response.Rejected, raw)
+			continue
+		}
+		response.Accepted++
+		if seen[code.Value] {
+			continue
+		}
+		seen[code.Value] = true
+		scans = append(scans, entities.Scan{AuditID: stocktake.AuditID, Code: code.Value, ScannedAt: now})
+	}
+
+	if err := u.r.AddScans(ctx, scans); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (u *useCase) ApplyCorrections(ctx context.Context, userID uuid.UUID, auditID uuid.UUID, payload dtos.CorrectionsRequest) (*dtos.ReportResponse, error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var report *report
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		stocktake, err := u.openAudit(ctx, member.HouseholdID, auditID)
+		if err != nil {
+			return err
+		}
+
+		before, err := u.report(ctx, stocktake)
+		if err != nil {
+			return err
+		}
+		if !before.misplaced(payload.Move) || !before.missing(payload.Unshelve) {
+			return errors.ErrAuditCorrection
+		}
+
+		if _, err := u.r.MoveCopies(ctx, member.HouseholdID, payload.Move, &stocktake.LocationID); err != nil {
+			return err
+		}
+		if _, err := u.r.MoveCopies(ctx, member.HouseholdID, payload.Unshelve, nil); err != nil {
+			return err
+		}
+
+		report, err = u.report(ctx, stocktake)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report.response(), nil
+}
+
+func (u *useCase) CloseAudit(ctx context.Context, userID uuid.UUID, auditID uuid.UUID) (*dtos.AuditResponse, error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stocktake, err := u.openAudit(ctx, member.HouseholdID, auditID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := u.report(ctx, stocktake)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stocktake.Status, stocktake.ClosedAt = entities.StatusClosed, &now
+	stocktake.Found = len(report.found)
+	stocktake.Misplaced = len(report.misplacedCopies)
+	stocktake.Missing = len(report.missingCopies)
+	stocktake.Unknown = len(report.unknown)
+
+	if err := u.r.CloseAudit(ctx, stocktake); err != nil {
+		return nil, mapNoRows(err, errors.ErrAuditClosed)
+	}
+
+	response := dtos.NewAuditResponse(*stocktake)
+	return &response, nil
+}
+
+// report reconciles the scans of the audit with the catalog as it is now.
+type report struct {
+	audit           *entities.Audit
+	found           []entities.Copy
+	misplacedCopies []entities.Copy
+	missingCopies   []entities.Copy
+	onLoan          []entities.Copy
+	unknown         []string
+}
+
+func (u *useCase) report(ctx context.Context, stocktake *entities.Audit) (*report, error) {
+	copies, err := u.r.GetCopies(ctx, stocktake.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	scans, err := u.r.GetScans(ctx, stocktake.AuditID)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]entities.Copy, len(copies))
+	expected := make(map[string]string, len(copies))
+	for _, copy := range copies {
+		byCode[copy.ShortID] = copy
+		expected[copy.ShortID] = locationKey(copy.LocationID)
+	}
+
+	location := stocktake.LocationID.String()
+	audited := make([]audit.Scan, len(scans))
+	for i, scan := range scans {
+		audited[i] = audit.Scan{Location: location, Copy: scan.Code, At: scan.ScannedAt}
+	}
+
+	reconciled := audit.Reconcile(expected, []string{location}, audited)
+
+	result := &report{audit: stocktake, unknown: reconciled.Unknown}
+	for _, code := range reconciled.Found {
+		result.found = append(result.found, byCode[code])
+	}
+	for _, misplaced := range reconciled.Misplaced {
+		result.misplacedCopies = append(result.misplacedCopies, byCode[misplaced.Copy])
+	}
+	for _, missing := range reconciled.Missing {
+		if copy := byCode[missing.Copy]; copy.OnLoan {
+			result.onLoan = append(result.onLoan, copy)
+		} else {
+			result.missingCopies = append(result.missingCopies, copy)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *report) misplaced(copyIDs []uuid.UUID) bool {
+	return contains(r.misplacedCopies, copyIDs)
+}
+
+func (r *report) missing(copyIDs []uuid.UUID) bool {
+	return contains(r.missingCopies, copyIDs)
+}
+
+func (r *report) response() *dtos.ReportResponse {
+	response := &dtos.ReportResponse{
+		Audit:     dtos.NewAuditResponse(*r.audit),
+		Found:     copyItems(r.found),
+		Misplaced: make([]dtos.MisplacedItem, len(r.misplacedCopies)),
+		Missing:   copyItems(r.missingCopies),
+		OnLoan:    copyItems(r.onLoan),
+		Unknown:   r.unknown,
+	}
+	for i, copy := range r.misplacedCopies {
+		response.Misplaced[i] = dtos.MisplacedItem{
+			CopyItem:             dtos.NewCopyItem(copy),
+			ExpectedLocationID:   copy.LocationID,
+			ExpectedLocationName: copy.LocationName,
+		}
+	}
+	return response
+}
+
+func copyItems(copies []entities.Copy) []dtos.CopyItem {
+	items := make([]dtos.CopyItem, len(copies))
+	for i, copy := range copies {
+		items[i] = dtos.NewCopyItem(copy)
+	}
+	return items
+}
+
+func contains(copies []entities.Copy, copyIDs []uuid.UUID) bool {
+	listed := make(map[uuid.UUID]bool, len(copies))
+	for _, copy := range copies {
+		listed[copy.CopyID] = true
+	}
+	for _, id := range copyIDs {
+		if !listed[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// locationKey names a location for reconciliation; copies kept nowhere share
+// the empty one.
+func locationKey(locationID *uuid.UUID) string {
+	if locationID == nil {
+		return ""
+	}
+	return locationID.String()
+}
+
+func (u *useCase) audit(ctx context.Context, householdID uuid.UUID, auditID uuid.UUID) (*entities.Audit, error) {
+	stocktake, err := u.r.GetAudit(ctx, householdID, auditID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrAuditNotFound)
+	}
+	return stocktake, nil
+}
+
+func (u *useCase) openAudit(ctx context.Context, householdID uuid.UUID, auditID uuid.UUID) (*entities.Audit, error) {
+	stocktake, err := u.audit(ctx, householdID, auditID)
+	if err != nil {
+		return nil, err
+	}
+	if stocktake.Status != entities.StatusOpen {
+		return nil, errors.ErrAuditClosed
+	}
+	return stocktake, nil
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+func (u *useCase) editor(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanEditLibrary() {
+		return nil, errors.ErrHouseholdForbidden
+	}
+	return member, nil
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
@@ -0,0 +1,402 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/audit/dtos"
+	"home-library/internal/services/audit/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateAudit(ctx context.Context, audit *entities.Audit) error {
+	return m.Called(ctx, audit).Error(0)
+}
+
+func (m *MockRepository) GetAudit(ctx context.Context, householdID uuid.UUID, auditID uuid.UUID) (*entities.Audit, error) {
+	args := m.Called(ctx, householdID, auditID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Audit), args.Error(1)
+}
+
+func (m *MockRepository) GetOpenAudit(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*entities.Audit, error) {
+	args := m.Called(ctx, householdID, locationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Audit), args.Error(1)
+}
+
+func (m *MockRepository) GetAudits(ctx context.Context, householdID uuid.UUID, locationID *uuid.UUID) ([]entities.Audit, error) {
+	args := m.Called(ctx, householdID, locationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Audit), args.Error(1)
+}
+
+func (m *MockRepository) CloseAudit(ctx context.Context, audit *entities.Audit) error {
+	return m.Called(ctx, audit).Error(0)
+}
+
+func (m *MockRepository) AddScans(ctx context.Context, scans []entities.Scan) error {
+	return m.Called(ctx, scans).Error(0)
+}
+
+func (m *MockRepository) GetScans(ctx context.Context, auditID uuid.UUID) ([]entities.Scan, error) {
+	args := m.Called(ctx, auditID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Scan), args.Error(1)
+}
+
+func (m *MockRepository) GetCopies(ctx context.Context, householdID uuid.UUID) ([]entities.Copy, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Copy), args.Error(1)
+}
+
+func (m *MockRepository) MoveCopies(ctx context.Context, householdID uuid.UUID, copyIDs []uuid.UUID, locationID *uuid.UUID) (int64, error) {
+	args := m.Called(ctx, householdID, copyIDs, locationID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+// passthroughTx runs the unit of work directly.
+type passthroughTx struct{}
+
+func (passthroughTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	households *MockHouseholdRepository
+}
+
+func newUseCase() (UseCase, mocks) {
+	m := mocks{new(MockRepository), new(MockHouseholdRepository)}
+	return NewUseCase(m.repo, m.households, passthroughTx{}), m
+}
+
+func (m mocks) member(userID uuid.UUID, householdID uuid.UUID, role householdEntities.Role) {
+	m.households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: role}, nil)
+}
+
+// shelf sets up an open audit of a shelf holding one found, one missing and
+// one lent copy, with a misplaced copy and an unknown code scanned.
+type shelf struct {
+	audit                           *entities.Audit
+	hall                            uuid.UUID
+	found, missing, lent, misplaced entities.Copy
+}
+
+func (m mocks) shelf(householdID uuid.UUID) shelf {
+	locationID, hall := uuid.New(), uuid.New()
+	hallName := "Прихожая"
+	s := shelf{
+		audit: &entities.Audit{
+			AuditID: uuid.New(), HouseholdID: householdID, LocationID: locationID,
+			LocationName: "Кабинет", Status: entities.StatusOpen,
+		},
+		hall:      hall,
+		found:     entities.Copy{CopyID: uuid.New(), ShortID: "AAAAAAAA", Title: "Мастер и Маргарита", LocationID: &locationID},
+		missing:   entities.Copy{CopyID: uuid.New(), ShortID: "BBBBBBBB", Title: "Белая гвардия", LocationID: &locationID},
+		lent:      entities.Copy{CopyID: uuid.New(), ShortID: "CCCCCCCC", Title: "Собачье сердце", LocationID: &locationID, OnLoan: true},
+		misplaced: entities.Copy{CopyID: uuid.New(), ShortID: "DDDDDDDD", Title: "Записки юного врача", LocationID: &hall, LocationName: &hallName},
+	}
+
+	now := time.Now()
+	m.repo.On("GetAudit", mock.Anything, householdID, s.audit.AuditID).Return(s.audit, nil)
+	m.repo.On("GetCopies", mock.Anything, householdID).
+		Return([]entities.Copy{s.found, s.missing, s.lent, s.misplaced}, nil)
+	m.repo.On("GetScans", mock.Anything, s.audit.AuditID).Return([]entities.Scan{
+		{AuditID: s.audit.AuditID, Code: "AAAAAAAA", ScannedAt: now},
+		{AuditID: s.audit.AuditID, Code: "DDDDDDDD", ScannedAt: now},
+		{AuditID: s.audit.AuditID, Code: "ZZZZZZZZ", ScannedAt: now},
+	}, nil)
+	return s
+}
+
+func TestStartAudit(t *testing.T) {
+	t.Run("new audit is started", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, locationID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		var started *entities.Audit
+		m.repo.On("GetOpenAudit", mock.Anything, householdID, locationID).Return(nil, sql.ErrNoRows)
+		m.repo.On("CreateAudit", mock.Anything, mock.MatchedBy(func(a *entities.Audit) bool {
+			started = a
+			return a.HouseholdID == householdID && a.LocationID == locationID && a.StartedBy == userID &&
+				a.Status == entities.StatusOpen
+		})).Return(nil)
+		m.repo.On("GetAudit", mock.Anything, householdID, mock.MatchedBy(func(id uuid.UUID) bool {
+			return started != nil && id == started.AuditID
+		})).Return(&entities.Audit{HouseholdID: householdID, LocationID: locationID, LocationName: "Кабинет", Status: entities.StatusOpen}, nil)
+
+		audit, err := u.StartAudit(context.Background(), userID, dtos.StartAuditRequest{LocationID: locationID})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Кабинет", audit.LocationName)
+		assert.False(t, audit.Resumed)
+	})
+
+	t.Run("open audit is resumed", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, locationID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		open := &entities.Audit{AuditID: uuid.New(), HouseholdID: householdID, LocationID: locationID, Status: entities.StatusOpen, Scanned: 12}
+		m.repo.On("GetOpenAudit", mock.Anything, householdID, locationID).Return(open, nil)
+
+		audit, err := u.StartAudit(context.Background(), userID, dtos.StartAuditRequest{LocationID: locationID})
+
+		require.NoError(t, err)
+		assert.Equal(t, open.AuditID, audit.AuditID)
+		assert.Equal(t, 12, audit.Scanned)
+		assert.True(t, audit.Resumed)
+		m.repo.AssertNotCalled(t, "CreateAudit", mock.Anything, mock.Anything)
+	})
+
+	t.Run("viewers cannot audit", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+		m.member(userID, uuid.New(), householdEntities.RoleViewer)
+
+		_, err := u.StartAudit(context.Background(), userID, dtos.StartAuditRequest{LocationID: uuid.New()})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+	})
+}
+
+func TestGetReport(t *testing.T) {
+	u, m := newUseCase()
+	userID, householdID := uuid.New(), uuid.New()
+	m.member(userID, householdID, householdEntities.RoleViewer)
+	s := m.shelf(householdID)
+
+	report, err := u.GetReport(context.Background(), userID, s.audit.AuditID)
+
+	require.NoError(t, err)
+	require.Len(t, report.Found, 1)
+	assert.Equal(t, s.found.CopyID, report.Found[0].CopyID)
+	require.Len(t, report.Misplaced, 1)
+	assert.Equal(t, s.misplaced.CopyID, report.Misplaced[0].CopyID)
+	assert.Equal(t, &s.hall, report.Misplaced[0].ExpectedLocationID)
+	assert.Equal(t, "Прихожая", *report.Misplaced[0].ExpectedLocationName)
+	require.Len(t, report.Missing, 1)
+	assert.Equal(t, s.missing.CopyID, report.Missing[0].CopyID)
+	require.Len(t, report.OnLoan, 1)
+	assert.Equal(t, s.lent.CopyID, report.OnLoan[0].CopyID)
+	assert.Equal(t, []string{"ZZZZZZZZ"}, report.Unknown)
+}
+
+func TestAddScans(t *testing.T) {
+	t.Run("copy labels are recorded once", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, auditID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetAudit", mock.Anything, householdID, auditID).
+			Return(&entities.Audit{AuditID: auditID, HouseholdID: householdID, Status: entities.StatusOpen}, nil)
+		m.repo.On("AddScans", mock.Anything, mock.MatchedBy(func(scans []entities.Scan) bool {
+			return len(scans) == 2 && scans[0].Code == "K7M2Q9XD" && scans[1].Code == "K7M2Q9X0" &&
+				scans[0].AuditID == auditID
+		})).Return(nil)
+
+		result, err := u.AddScans(context.Background(), userID, auditID, dtos.ScansRequest{Codes: []string{
+			"https://library.example/copies/K7M2Q9XD",
+			"k7m2-q9xo",
+			"K7M2Q9XD",
+			"https://library.example/shelves/S1S1S1S1",
+		}})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Accepted)
+		assert.Equal(t, []string{"https://library.example/shelves/S1S1S1S1"}, result.Rejected)
+	})
+
+	t.Run("closed audits take no scans", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, auditID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetAudit", mock.Anything, householdID, auditID).
+			Return(&entities.Audit{AuditID: auditID, HouseholdID: householdID, Status: entities.StatusClosed}, nil)
+
+		_, err := u.AddScans(context.Background(), userID, auditID, dtos.ScansRequest{Codes: []string{"K7M2Q9XD"}})
+
+		assert.ErrorIs(t, err, errors.ErrAuditClosed)
+		m.repo.AssertNotCalled(t, "AddScans", mock.Anything, mock.Anything)
+	})
+
+	t.Run("audit of another household is not found", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, auditID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetAudit", mock.Anything, householdID, auditID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.AddScans(context.Background(), userID, auditID, dtos.ScansRequest{Codes: []string{"K7M2Q9XD"}})
+
+		assert.ErrorIs(t, err, errors.ErrAuditNotFound)
+	})
+}
+
+func TestApplyCorrections(t *testing.T) {
+	t.Run("misplaced copies move in and missing ones move out", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID := uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+		s := m.shelf(householdID)
+
+		m.repo.On("MoveCopies", mock.Anything, householdID, []uuid.UUID{s.misplaced.CopyID}, &s.audit.LocationID).Return(int64(1), nil)
+		m.repo.On("MoveCopies", mock.Anything, householdID, []uuid.UUID{s.missing.CopyID}, (*uuid.UUID)(nil)).Return(int64(1), nil)
+
+		_, err := u.ApplyCorrections(context.Background(), userID, s.audit.AuditID, dtos.CorrectionsRequest{
+			Move:     []uuid.UUID{s.misplaced.CopyID},
+			Unshelve: []uuid.UUID{s.missing.CopyID},
+		})
+
+		require.NoError(t, err)
+		m.repo.AssertNumberOfCalls(t, "MoveCopies", 2)
+	})
+
+	t.Run("copies outside the report are refused", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID := uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+		s := m.shelf(householdID)
+
+		_, err := u.ApplyCorrections(context.Background(), userID, s.audit.AuditID, dtos.CorrectionsRequest{
+			Unshelve: []uuid.UUID{s.lent.CopyID},
+		})
+
+		assert.ErrorIs(t, err, errors.ErrAuditCorrection)
+		m.repo.AssertNotCalled(t, "MoveCopies", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCloseAudit(t *testing.T) {
+	u, m := newUseCase()
+	userID, householdID := uuid.New(), uuid.New()
+	m.member(userID, householdID, householdEntities.RoleEditor)
+	s := m.shelf(householdID)
+
+	m.repo.On("CloseAudit", mock.Anything, mock.MatchedBy(func(a *entities.Audit) bool {
+		return a.Status == entities.StatusClosed && a.ClosedAt != nil &&
+			a.Found == 1 && a.Misplaced == 1 && a.Missing == 1 && a.Unknown == 1
+	})).Return(nil)
+
+	audit, err := u.CloseAudit(context.Background(), userID, s.audit.AuditID)
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.StatusClosed, audit.Status)
+}
@@ -0,0 +1,153 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/bookimport/dtos"
+	"home-library/internal/services/bookimport/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"mime/multipart"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) PreviewImport(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	request, file, err := h.bindUpload(c)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return nil
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to open uploaded export")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+	defer src.Close()
+
+	preview, err := h.u.PreviewImport(c.Request().Context(), userID, request, src, file.Size)
+	if err != nil {
+		return h.handleError(c, err, "failed to preview import")
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+func (h *handler) StartImport(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	request, file, err := h.bindUpload(c)
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return nil
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to open uploaded export")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+	defer src.Close()
+
+	importID, err := h.u.StartImport(c.Request().Context(), userID, request, src, file.Size)
+	if err != nil {
+		return h.handleError(c, err, "failed to start import")
+	}
+
+	return c.JSON(http.StatusAccepted, dtos.StartImportResponse{ImportID: importID})
+}
+
+// bindUpload reads the form fields and the file of an import. A nil file
+// means the response has been written.
+func (h *handler) bindUpload(c echo.Context) (dtos.ImportRequest, *multipart.FileHeader, error) {
+	var request dtos.ImportRequest
+	if err := c.Bind(&request); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return request, nil, c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := request.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return request, nil, c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return request, nil, c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Файл для импорта не передан", nil))
+	}
+
+	return request, file, nil
+}
+
+func (h *handler) GetImports(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	imports, err := h.u.GetImports(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get imports")
+	}
+
+	return c.JSON(http.StatusOK, imports)
+}
+
+func (h *handler) GetImport(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	importID, err := uuid.Parse(c.Param("import_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	bookImport, err := h.u.GetImport(c.Request().Context(), userID, importID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get import")
+	}
+
+	return c.JSON(http.StatusOK, bookImport)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrImportNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Импорт не найден", nil))
+	case errors.Is(err, customErrors.ErrImportTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, dtos.NewErrorResponse(http.StatusRequestEntityTooLarge, "Файл слишком большой", nil))
+	case errors.Is(err, customErrors.ErrImportMalformed):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Файл не является экспортом в указанном формате", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
@@ -0,0 +1,221 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"home-library/internal/services/bookimport/dtos"
+	"home-library/internal/services/bookimport/entities"
+	"home-library/pkg/bookimport"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jobs"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) PreviewImport(ctx context.Context, userID uuid.UUID, request dtos.ImportRequest, r io.ReaderAt, size int64) (*dtos.PreviewResponse, error) {
+	data, _ := io.ReadAll(io.NewSectionReader(r, 0, size))
+	args := m.Called(ctx, userID, request, string(data))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.PreviewResponse), args.Error(1)
+}
+
+func (m *MockUseCase) StartImport(ctx context.Context, userID uuid.UUID, request dtos.ImportRequest, r io.ReaderAt, size int64) (uuid.UUID, error) {
+	data, _ := io.ReadAll(io.NewSectionReader(r, 0, size))
+	args := m.Called(ctx, userID, request, string(data), size)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) GetImports(ctx context.Context, userID uuid.UUID) ([]dtos.ImportResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.ImportResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetImport(ctx context.Context, userID uuid.UUID, importID uuid.UUID) (*dtos.ImportResponse, error) {
+	args := m.Called(ctx, userID, importID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.ImportResponse), args.Error(1)
+}
+
+func (m *MockUseCase) Run(ctx context.Context, job *jobs.Job) error {
+	return m.Called(ctx, job).Error(0)
+}
+
+func newContext(e *echo.Echo, req *http.Request, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func uploadRequest(t *testing.T, target string, fields map[string]string, content string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	part, err := writer.CreateFormFile("file", "goodreads_library_export.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestPreviewImport(t *testing.T) {
+	e := echo.New()
+	fields := map[string]string{"format": "goodreads", "duplicates": "update"}
+	request := dtos.ImportRequest{Format: bookimport.FormatGoodreads, Duplicates: entities.ResolutionUpdate}
+
+	t.Run("successfully preview", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		expected := &dtos.PreviewResponse{Create: 1, Rows: []dtos.RowResponse{{Line: 2, Title: "Dune", Action: entities.ActionCreate}}}
+		mockUseCase.On("PreviewImport", mock.Anything, userID, request, "export").Return(expected, nil)
+
+		c, rec := newContext(e, uploadRequest(t, "/imports/preview", fields, "export"), userID)
+		err := h.PreviewImport(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response dtos.PreviewResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, *expected, response)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, uploadRequest(t, "/imports/preview", map[string]string{"format": "kindle"}, "export"), uuid.New())
+		err := h.PreviewImport(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("PreviewImport", mock.Anything, userID, request, "export").Return(nil, customErrors.ErrImportMalformed)
+
+		c, rec := newContext(e, uploadRequest(t, "/imports/preview", fields, "export"), userID)
+		err := h.PreviewImport(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestStartImport(t *testing.T) {
+	e := echo.New()
+	fields := map[string]string{"format": "librarything"}
+	request := dtos.ImportRequest{Format: bookimport.FormatLibraryThing}
+
+	t.Run("successfully start", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, importID := uuid.New(), uuid.New()
+
+		mockUseCase.On("StartImport", mock.Anything, userID, request, "export", int64(len("export"))).Return(importID, nil)
+
+		c, rec := newContext(e, uploadRequest(t, "/imports", fields, "export"), userID)
+		err := h.StartImport(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+
+		var response dtos.StartImportResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, importID, response.ImportID)
+	})
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{"not a member", customErrors.ErrHouseholdNotFound, http.StatusNotFound},
+		{"too large", customErrors.ErrImportTooLarge, http.StatusRequestEntityTooLarge},
+		{"viewer", customErrors.ErrHouseholdForbidden, http.StatusForbidden},
+		{"internal error", errors.New("database is down"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			h := NewHandler(mockUseCase)
+			userID := uuid.New()
+
+			mockUseCase.On("StartImport", mock.Anything, userID, request, "export", mock.Anything).Return(uuid.Nil, test.err)
+
+			c, rec := newContext(e, uploadRequest(t, "/imports", fields, "export"), userID)
+			err := h.StartImport(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestGetImport(t *testing.T) {
+	e := echo.New()
+
+	t.Run("not found", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, importID := uuid.New(), uuid.New()
+
+		mockUseCase.On("GetImport", mock.Anything, userID, importID).Return(nil, customErrors.ErrImportNotFound)
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/", nil), userID)
+		c.SetParamNames("import_id")
+		c.SetParamValues(importID.String())
+		err := h.GetImport(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/", nil), uuid.New())
+		c.SetParamNames("import_id")
+		c.SetParamValues("latest")
+		err := h.GetImport(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
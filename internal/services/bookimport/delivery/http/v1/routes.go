@@ -0,0 +1,15 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func (h *handler) BookImportRoutes(domain *echo.Group) {
+	// The limits fit a Calibre library with room for the multipart envelope;
+	// the use case holds the other formats to less.
+	domain.POST("/imports/preview", h.PreviewImport, middleware.BodyLimit("2049M"))
+	domain.POST("/imports", h.StartImport, middleware.BodyLimit("2049M"))
+	domain.GET("/imports", h.GetImports)
+	domain.GET("/imports/:import_id", h.GetImport)
+}
@@ -0,0 +1,89 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/bookimport/entities"
+	"home-library/pkg/bookimport"
+	"time"
+)
+
+// ImportRequest comes as form fields along with the uploaded file, a CSV
+// export or a zipped Calibre library.
+type ImportRequest struct {
+	Format bookimport.Format `form:"format" validate:"required,oneof=goodreads librarything calibre"`
+	// Duplicates defaults to skip.
+	Duplicates entities.Resolution `form:"duplicates" validate:"omitempty,oneof=skip update create"`
+}
+
+func (r *ImportRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type RowResponse struct {
+	Line    int             `json:"line"`
+	Title   string          `json:"title,omitempty"`
+	Authors []string        `json:"authors,omitempty"`
+	ISBN    string          `json:"isbn,omitempty"`
+	Action  entities.Action `json:"action"`
+	// BookID and BookTitle name the catalog book an update or a duplicate
+	// matched.
+	BookID    *uuid.UUID `json:"book_id,omitempty"`
+	BookTitle string     `json:"book_title,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// PreviewResponse counts every row of the file but lists only the first
+// ones of a large file.
+type PreviewResponse struct {
+	Create    int           `json:"create"`
+	Update    int           `json:"update"`
+	Duplicate int           `json:"duplicate"`
+	Errors    int           `json:"errors"`
+	Rows      []RowResponse `json:"rows"`
+	Truncated bool          `json:"truncated"`
+}
+
+type StartImportResponse struct {
+	ImportID uuid.UUID `json:"import_id"`
+}
+
+type RowErrorResponse struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type ImportResponse struct {
+	ImportID   uuid.UUID           `json:"import_id"`
+	CreatedBy  uuid.UUID           `json:"created_by"`
+	Format     bookimport.Format   `json:"format"`
+	Duplicates entities.Resolution `json:"duplicates"`
+	Status     entities.Status     `json:"status"`
+	Created    int                 `json:"created"`
+	Updated    int                 `json:"updated"`
+	Skipped    int                 `json:"skipped"`
+	Failed     int                 `json:"failed"`
+	Error      string              `json:"error,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	FinishedAt *time.Time          `json:"finished_at"`
+	// RowErrors are only filled in when a single import is fetched.
+	RowErrors []RowErrorResponse `json:"row_errors,omitempty"`
+}
+
+func NewImportResponse(bookImport entities.Import) ImportResponse {
+	return ImportResponse{
+		ImportID:   bookImport.ImportID,
+		CreatedBy:  bookImport.CreatedBy,
+		Format:     bookImport.Format,
+		Duplicates: bookImport.Duplicates,
+		Status:     bookImport.Status,
+		Created:    bookImport.Created,
+		Updated:    bookImport.Updated,
+		Skipped:    bookImport.Skipped,
+		Failed:     bookImport.Failed,
+		Error:      bookImport.Error,
+		CreatedAt:  bookImport.CreatedAt,
+		FinishedAt: bookImport.FinishedAt,
+	}
+}
@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"home-library/pkg/bookimport"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Resolution is what an import does with a row that resembles a book of the
+// catalog by title and authors but shares no ISBN with it.
+type Resolution string
+
+const (
+	ResolutionSkip   Resolution = "skip"
+	ResolutionUpdate Resolution = "update"
+	ResolutionCreate Resolution = "create"
+)
+
+// Action is what an import does, or would do, with a row.
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionDuplicate Action = "duplicate"
+	ActionError     Action = "error"
+)
+
+// Import is an export of another service being loaded into the catalog of a
+// household by a background job.
+type Import struct {
+	ImportID    uuid.UUID         `db:"import_id"`
+	HouseholdID uuid.UUID         `db:"household_id"`
+	CreatedBy   uuid.UUID         `db:"created_by"`
+	Format      bookimport.Format `db:"format"`
+	Duplicates  Resolution        `db:"duplicates"`
+	Status      Status            `db:"status"`
+	Created     int               `db:"created"`
+	Updated     int               `db:"updated"`
+	Skipped     int               `db:"skipped"`
+	Failed      int               `db:"failed"`
+	// Error tells why an import failed as a whole.
+	Error      string     `db:"error"`
+	CreatedAt  time.Time  `db:"created_at"`
+	FinishedAt *time.Time `db:"finished_at"`
+}
+
+func NewImport(householdID uuid.UUID, userID uuid.UUID, format bookimport.Format, duplicates Resolution) *Import {
+	return &Import{
+		ImportID:    uuid.New(),
+		HouseholdID: householdID,
+		CreatedBy:   userID,
+		Format:      format,
+		Duplicates:  duplicates,
+		Status:      StatusPending,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// BlobKey is where the uploaded file waits for the job.
+func (i *Import) BlobKey() string {
+	return "imports/" + i.ImportID.String()
+}
+
+// ContentType is the type the uploaded file is stored with.
+func (i *Import) ContentType() string {
+	if i.Format == bookimport.FormatCalibre {
+		return "application/zip"
+	}
+	return "text/csv"
+}
+
+// Count tallies the outcome of a row.
+func (i *Import) Count(action Action) {
+	switch action {
+	case ActionCreate:
+		i.Created++
+	case ActionUpdate:
+		i.Updated++
+	case ActionDuplicate:
+		i.Skipped++
+	case ActionError:
+		i.Failed++
+	}
+}
+
+// RowError is a row the import could not load.
+type RowError struct {
+	ImportID uuid.UUID `db:"import_id"`
+	Line     int       `db:"line"`
+	Message  string    `db:"message"`
+}
+
+// Source links a row of an export to the book it became, by the ID the other
+// service gave the row.
+type Source struct {
+	HouseholdID uuid.UUID         `db:"household_id"`
+	Format      bookimport.Format `db:"format"`
+	SourceID    string            `db:"source_id"`
+	BookID      uuid.UUID         `db:"book_id"`
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/bookimport/entities"
+	"home-library/pkg/bookimport"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// importsLimit is how many of its latest imports a household sees.
+const importsLimit = 50
+
+type Repository interface {
+	CreateImport(ctx context.Context, bookImport *entities.Import) error
+	GetImport(ctx context.Context, householdID uuid.UUID, importID uuid.UUID) (*entities.Import, error)
+	// GetImportByID is for the import job, which acts for no particular
+	// member.
+	GetImportByID(ctx context.Context, importID uuid.UUID) (*entities.Import, error)
+	// GetImports lists the latest imports of the household, newest first.
+	GetImports(ctx context.Context, householdID uuid.UUID) ([]entities.Import, error)
+	// FinishImport saves the counts of a pending import along with its row
+	// errors and marks it done.
+	FinishImport(ctx context.Context, bookImport *entities.Import, rowErrors []entities.RowError) error
+	FailImport(ctx context.Context, importID uuid.UUID, message string, now time.Time) error
+	GetRowErrors(ctx context.Context, importID uuid.UUID) ([]entities.RowError, error)
+
+	// GetSources lists the books earlier imports of the format created or
+	// updated.
+	GetSources(ctx context.Context, householdID uuid.UUID, format bookimport.Format) ([]entities.Source, error)
+	// SaveSource links the source ID to the book, replacing an earlier link.
+	SaveSource(ctx context.Context, source *entities.Source) error
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateImport(ctx context.Context, bookImport *entities.Import) error {
+	query := `
+		INSERT INTO book_imports (import_id, household_id, created_by, format, duplicates, status, created_at)
+		VALUES (:import_id, :household_id, :created_by, :format, :duplicates, :status, :created_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, bookImport)
+	return err
+}
+
+func (r *repository) GetImport(ctx context.Context, householdID uuid.UUID, importID uuid.UUID) (*entities.Import, error) {
+	var bookImport entities.Import
+	query := `SELECT * FROM book_imports WHERE household_id = $1 AND import_id = $2`
+
+	err := r.db.GetContext(ctx, &bookImport, query, householdID, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bookImport, nil
+}
+
+func (r *repository) GetImportByID(ctx context.Context, importID uuid.UUID) (*entities.Import, error) {
+	var bookImport entities.Import
+	query := `SELECT * FROM book_imports WHERE import_id = $1`
+
+	err := r.db.GetContext(ctx, &bookImport, query, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bookImport, nil
+}
+
+func (r *repository) GetImports(ctx context.Context, householdID uuid.UUID) ([]entities.Import, error) {
+	imports := make([]entities.Import, 0)
+	query := `
+		SELECT * FROM book_imports
+		WHERE household_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	err := r.db.SelectContext(ctx, &imports, query, householdID, importsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return imports, nil
+}
+
+func (r *repository) FinishImport(ctx context.Context, bookImport *entities.Import, rowErrors []entities.RowError) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE book_imports
+			SET status = :status, created = :created, updated = :updated, skipped = :skipped, failed = :failed,
+				finished_at = :finished_at
+			WHERE import_id = :import_id AND status = 'pending'
+		`
+		result, err := tx.NamedExecContext(ctx, query, bookImport)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+
+		if len(rowErrors) == 0 {
+			return nil
+		}
+
+		query = `
+			INSERT INTO book_import_errors (import_id, line, message)
+			VALUES (:import_id, :line, :message)
+		`
+		_, err = tx.NamedExecContext(ctx, query, rowErrors)
+		return err
+	})
+}
+
+func (r *repository) FailImport(ctx context.Context, importID uuid.UUID, message string, now time.Time) error {
+	query := `
+		UPDATE book_imports
+		SET status = 'failed', error = $2, finished_at = $3
+		WHERE import_id = $1 AND status = 'pending'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, importID, message, now)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) GetRowErrors(ctx context.Context, importID uuid.UUID) ([]entities.RowError, error) {
+	rowErrors := make([]entities.RowError, 0)
+	query := `SELECT * FROM book_import_errors WHERE import_id = $1 ORDER BY line`
+
+	err := r.db.SelectContext(ctx, &rowErrors, query, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	return rowErrors, nil
+}
+
+func (r *repository) GetSources(ctx context.Context, householdID uuid.UUID, format bookimport.Format) ([]entities.Source, error) {
+	sources := make([]entities.Source, 0)
+	query := `SELECT * FROM book_import_sources WHERE household_id = $1 AND format = $2`
+
+	err := r.db.SelectContext(ctx, &sources, query, householdID, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+func (r *repository) SaveSource(ctx context.Context, source *entities.Source) error {
+	query := `
+		INSERT INTO book_import_sources (household_id, format, source_id, book_id)
+		VALUES (:household_id, :format, :source_id, :book_id)
+		ON CONFLICT (household_id, format, source_id) DO UPDATE SET book_id = EXCLUDED.book_id
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, source)
+	return err
+}
+
+func (r *repository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.db.InTx(ctx, nil, fn)
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/bookimport/entities"
+	"home-library/pkg/bookimport"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func finishedImport() *entities.Import {
+	bookImport := entities.NewImport(uuid.New(), uuid.New(), bookimport.FormatGoodreads, entities.ResolutionSkip)
+	now := time.Now()
+	bookImport.Status, bookImport.FinishedAt = entities.StatusDone, &now
+	bookImport.Created, bookImport.Failed = 2, 1
+	return bookImport
+}
+
+func TestFinishImport(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("saves the counts with the row errors", func(t *testing.T) {
+		bookImport := finishedImport()
+		rowErrors := []entities.RowError{
+			{ImportID: bookImport.ImportID, Line: 3, Message: "title is missing"},
+			{ImportID: bookImport.ImportID, Line: 7, Message: "title is missing"},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE book_imports .+ WHERE import_id = \? AND status = 'pending'`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO book_import_errors \(import_id, line, message\) VALUES \(\?, \?, \?\),\(\?, \?, \?\)`).
+			WithArgs(bookImport.ImportID, 3, "title is missing", bookImport.ImportID, 7, "title is missing").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		err := repo.FinishImport(context.Background(), bookImport, rowErrors)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("import no longer pending", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE book_imports`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.FinishImport(context.Background(), finishedImport(), nil)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFailImport(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("import no longer pending", func(t *testing.T) {
+		importID, now := uuid.New(), time.Now()
+
+		mock.ExpectExec(`UPDATE book_imports SET status = 'failed', error = \$2, finished_at = \$3 WHERE import_id = \$1 AND status = 'pending'`).
+			WithArgs(importID, "connection reset", now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.FailImport(context.Background(), importID, "connection reset", now)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveSource(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("relinks the source", func(t *testing.T) {
+		source := &entities.Source{HouseholdID: uuid.New(), Format: bookimport.FormatCalibre, SourceID: "6f1b1c34", BookID: uuid.New()}
+
+		mock.ExpectExec(`INSERT INTO book_import_sources .+ ON CONFLICT \(household_id, format, source_id\) DO UPDATE SET book_id = EXCLUDED.book_id`).
+			WithArgs(source.HouseholdID, source.Format, source.SourceID, source.BookID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SaveSource(context.Background(), source)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
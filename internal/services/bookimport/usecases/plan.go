@@ -0,0 +1,188 @@
+package usecases
+
+import (
+	"home-library/internal/services/bookimport/entities"
+	catalogEntities "home-library/internal/services/catalog/entities"
+	"home-library/pkg/bookimport"
+	"home-library/pkg/dedupe"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// Limits of the catalog; longer values from an export are cut short and
+// extra authors and tags are left out.
+const (
+	maxTitle       = 255
+	maxAuthors     = 20
+	maxTags        = 50
+	maxTag         = 100
+	maxReview      = 10000
+	maxDescription = 10000
+)
+
+// maxSourceID is the longest source ID a row is linked to its book by.
+const maxSourceID = 64
+
+var languageValidator = validator.New()
+
+// planner decides what happens to the rows of an export, matching them
+// against the catalog and against the rows before them.
+type planner struct {
+	index      *dedupe.Index
+	books      map[string]*catalogEntities.Book
+	sources    map[string]uuid.UUID
+	duplicates entities.Resolution
+}
+
+func newPlanner(books []catalogEntities.Book, sources []entities.Source, duplicates entities.Resolution) *planner {
+	p := &planner{
+		index:      dedupe.NewIndex(nil),
+		books:      make(map[string]*catalogEntities.Book, len(books)),
+		sources:    make(map[string]uuid.UUID, len(sources)),
+		duplicates: duplicates,
+	}
+	for i := range books {
+		p.add(&books[i])
+	}
+	for _, source := range sources {
+		p.sources[source.SourceID] = source.BookID
+	}
+	return p
+}
+
+// add makes a book created by the import a match for the later rows.
+func (p *planner) add(book *catalogEntities.Book) {
+	id := book.BookID.String()
+	p.books[id] = book
+	p.index.Add(dedupe.Book{ID: id, Title: book.Title, Authors: book.Authors, ISBNs: []string{book.ISBN}})
+}
+
+// link remembers the book the record became. It reports whether the link
+// is new and so has to be saved.
+func (p *planner) link(record *bookimport.Record, book *catalogEntities.Book) bool {
+	if record.SourceID == "" || len(record.SourceID) > maxSourceID || p.sources[record.SourceID] == book.BookID {
+		return false
+	}
+	p.sources[record.SourceID] = book.BookID
+	return true
+}
+
+// plan matches the record by the book an earlier import of the row became
+// or by ISBN, both making it an update of the book, then by title and
+// authors, which makes it a duplicate resolved as the import says. It
+// returns the matched book unless a new one is due.
+func (p *planner) plan(record *bookimport.Record) (entities.Action, *catalogEntities.Book) {
+	if bookID, ok := p.sources[record.SourceID]; ok && record.SourceID != "" {
+		if book, ok := p.books[bookID.String()]; ok {
+			return entities.ActionUpdate, book
+		}
+	}
+
+	pair, ok := p.index.Match(dedupe.Book{
+		Title:   record.Title,
+		Authors: record.Authors,
+		ISBNs:   []string{record.ISBN13, record.ISBN10},
+	}, dedupe.DefaultThreshold)
+	if !ok {
+		return entities.ActionCreate, nil
+	}
+
+	book := p.books[pair.A]
+	if slices.Contains(pair.Reasons, dedupe.ReasonISBN) {
+		return entities.ActionUpdate, book
+	}
+
+	switch p.duplicates {
+	case entities.ResolutionUpdate:
+		return entities.ActionUpdate, book
+	case entities.ResolutionCreate:
+		return entities.ActionCreate, nil
+	default:
+		return entities.ActionDuplicate, book
+	}
+}
+
+func newBook(householdID uuid.UUID, record *bookimport.Record) *catalogEntities.Book {
+	book := catalogEntities.NewBook(householdID)
+	book.Title = truncate(record.Title, maxTitle)
+	merge(book, record)
+	return book
+}
+
+// merge fills in what the book lacks from the record and adds the record's
+// tags. It reports whether the book changed.
+func merge(book *catalogEntities.Book, record *bookimport.Record) bool {
+	changed := false
+
+	if len(book.Authors) == 0 && len(record.Authors) > 0 {
+		for _, author := range record.Authors[:min(len(record.Authors), maxAuthors)] {
+			book.Authors = append(book.Authors, truncate(author, maxTitle))
+		}
+		changed = true
+	}
+	if book.ISBN == "" {
+		if code := firstNonEmpty(record.ISBN13, record.ISBN10); code != "" {
+			book.ISBN = code
+			changed = true
+		}
+	}
+	if book.Publisher == "" && record.Publisher != "" {
+		book.Publisher = truncate(record.Publisher, maxTitle)
+		changed = true
+	}
+	// LibraryThing names languages in English rather than tagging them.
+	if book.Language == "" && languageValidator.Var(record.Language, "bcp47_language_tag") == nil {
+		book.Language = record.Language
+		changed = true
+	}
+	if book.PublishedYear == nil && record.Year > 0 && record.Year <= 9999 {
+		year := record.Year
+		book.PublishedYear = &year
+		changed = true
+	}
+	if book.Pages == nil && record.Pages > 0 {
+		pages := record.Pages
+		book.Pages = &pages
+		changed = true
+	}
+	if book.Series == "" && record.Series != "" {
+		book.Series = truncate(record.Series, maxTitle)
+		book.SeriesIndex = record.SeriesIndex
+		changed = true
+	}
+	if book.Description == "" && record.Description != "" {
+		book.Description = truncate(record.Description, maxDescription)
+		changed = true
+	}
+
+	for _, tag := range record.Tags {
+		tag = truncate(tag, maxTag)
+		if len(book.Tags) >= maxTags || slices.ContainsFunc(book.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			continue
+		}
+		book.Tags = append(book.Tags, tag)
+		changed = true
+	}
+
+	return changed
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
@@ -0,0 +1,757 @@
+package usecases
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/bookimport/dtos"
+	"home-library/internal/services/bookimport/entities"
+	"home-library/internal/services/bookimport/repository"
+	catalogEntities "home-library/internal/services/catalog/entities"
+	catalogRepository "home-library/internal/services/catalog/repository"
+	ebookDtos "home-library/internal/services/ebook/dtos"
+	ebookUseCases "home-library/internal/services/ebook/usecases"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	readingEntities "home-library/internal/services/reading/entities"
+	readingRepository "home-library/internal/services/reading/repository"
+	wishlistRepository "home-library/internal/services/wishlist/repository"
+	"home-library/pkg/blobstore"
+	"home-library/pkg/bookimport"
+	"home-library/pkg/errors"
+	"home-library/pkg/jobs"
+	"home-library/pkg/transaction"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// ImportJob is the kind of the job loading an uploaded export.
+const ImportJob = "book_import"
+
+const (
+	// A large export is a few thousand rows loaded in one transaction.
+	importTimeout  = 30 * time.Minute
+	importAttempts = 3
+
+	// maxPreviewRows bounds the rows a preview lists; all of them are
+	// counted.
+	maxPreviewRows = 500
+
+	maxExportSize = 50 << 20
+	// A Calibre library comes with its ebook files.
+	maxLibrarySize = 2 << 30
+)
+
+// Queue is the part of jobs.Queue imports need.
+type Queue interface {
+	Enqueue(ctx context.Context, kind string, payload any, opts jobs.Options) (uuid.UUID, error)
+}
+
+type UseCase interface {
+	// PreviewImport tells what importing the export would do to each row,
+	// without changing anything.
+	PreviewImport(ctx context.Context, userID uuid.UUID, request dtos.ImportRequest, r io.ReaderAt, size int64) (*dtos.PreviewResponse, error)
+	// StartImport keeps the export and queues the job loading it.
+	StartImport(ctx context.Context, userID uuid.UUID, request dtos.ImportRequest, r io.ReaderAt, size int64) (uuid.UUID, error)
+	GetImports(ctx context.Context, userID uuid.UUID) ([]dtos.ImportResponse, error)
+	GetImport(ctx context.Context, userID uuid.UUID, importID uuid.UUID) (*dtos.ImportResponse, error)
+	// Run is the handler of ImportJob.
+	Run(ctx context.Context, job *jobs.Job) error
+}
+
+type importPayload struct {
+	ImportID uuid.UUID `json:"import_id"`
+}
+
+type useCase struct {
+	r          repository.Repository
+	catalog    catalogRepository.Repository
+	readings   readingRepository.Repository
+	households householdRepository.Repository
+	wishlists  wishlistRepository.Repository
+	ebooks     ebookUseCases.UseCase
+	store      blobstore.Store
+	queue      Queue
+	tx         transaction.Transactor
+}
+
+func NewUseCase(
+	r repository.Repository,
+	catalog catalogRepository.Repository,
+	readings readingRepository.Repository,
+	households householdRepository.Repository,
+	wishlists wishlistRepository.Repository,
+	ebooks ebookUseCases.UseCase,
+	store blobstore.Store,
+	queue Queue,
+	tx transaction.Transactor,
+) UseCase {
+	return &useCase{
+		r:          r,
+		catalog:    catalog,
+		readings:   readings,
+		households: households,
+		wishlists:  wishlists,
+		ebooks:     ebooks,
+		store:      store,
+		queue:      queue,
+		tx:         tx,
+	}
+}
+
+func (u *useCase) PreviewImport(ctx context.Context, userID uuid.UUID, request dtos.ImportRequest, r io.ReaderAt, size int64) (*dtos.PreviewResponse, error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, _, err := open(request.Format, r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	t := target{householdID: member.HouseholdID, format: request.Format, duplicates: resolution(request.Duplicates)}
+	preview := &dtos.PreviewResponse{Rows: make([]dtos.RowResponse, 0)}
+	err = u.process(ctx, t, reader, func(row row) {
+		switch row.action {
+		case entities.ActionCreate:
+			preview.Create++
+		case entities.ActionUpdate:
+			preview.Update++
+		case entities.ActionDuplicate:
+			preview.Duplicate++
+		case entities.ActionError:
+			preview.Errors++
+		}
+
+		if len(preview.Rows) == maxPreviewRows {
+			preview.Truncated = true
+			return
+		}
+		preview.Rows = append(preview.Rows, row.response())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return preview, nil
+}
+
+func (u *useCase) StartImport(ctx context.Context, userID uuid.UUID, request dtos.ImportRequest, r io.ReaderAt, size int64) (uuid.UUID, error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// A file of another format is turned down now rather than by the job.
+	if _, _, err := open(request.Format, r, size); err != nil {
+		return uuid.Nil, err
+	}
+
+	bookImport := entities.NewImport(member.HouseholdID, userID, request.Format, resolution(request.Duplicates))
+	if err := u.store.Put(ctx, bookImport.BlobKey(), io.NewSectionReader(r, 0, size), size, bookImport.ContentType()); err != nil {
+		return uuid.Nil, err
+	}
+
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		if err := u.r.CreateImport(ctx, bookImport); err != nil {
+			return err
+		}
+
+		_, err := u.queue.Enqueue(ctx, ImportJob, importPayload{ImportID: bookImport.ImportID}, jobs.Options{
+			MaxAttempts: importAttempts,
+			Timeout:     importTimeout,
+		})
+		return err
+	})
+	if err != nil {
+		u.deleteFile(ctx, bookImport)
+		return uuid.Nil, err
+	}
+
+	return bookImport.ImportID, nil
+}
+
+func (u *useCase) GetImports(ctx context.Context, userID uuid.UUID) ([]dtos.ImportResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	imports, err := u.r.GetImports(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.ImportResponse, len(imports))
+	for i, bookImport := range imports {
+		result[i] = dtos.NewImportResponse(bookImport)
+	}
+
+	return result, nil
+}
+
+func (u *useCase) GetImport(ctx context.Context, userID uuid.UUID, importID uuid.UUID) (*dtos.ImportResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookImport, err := u.r.GetImport(ctx, member.HouseholdID, importID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrImportNotFound)
+	}
+
+	rowErrors, err := u.r.GetRowErrors(ctx, importID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dtos.NewImportResponse(*bookImport)
+	response.RowErrors = make([]dtos.RowErrorResponse, len(rowErrors))
+	for i, rowError := range rowErrors {
+		response.RowErrors[i] = dtos.RowErrorResponse{Line: rowError.Line, Message: rowError.Message}
+	}
+
+	return &response, nil
+}
+
+// Run loads the whole export in one transaction, so a failed attempt leaves
+// nothing behind for the next one. The import is marked failed once the
+// job has used up its attempts.
+func (u *useCase) Run(ctx context.Context, job *jobs.Job) error {
+	var payload importPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	bookImport, err := u.r.GetImportByID(ctx, payload.ImportID)
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if bookImport.Status != entities.StatusPending {
+		return nil
+	}
+
+	if err := u.load(ctx, bookImport); err != nil {
+		if job.Attempts >= job.MaxAttempts {
+			if err := u.r.FailImport(ctx, bookImport.ImportID, err.Error(), time.Now()); err != nil {
+				log.Error().Err(err).Str("import_id", bookImport.ImportID.String()).Msg("failed to mark book import failed")
+			}
+			u.deleteFile(ctx, bookImport)
+		}
+		return err
+	}
+
+	u.deleteFile(ctx, bookImport)
+	return nil
+}
+
+func (u *useCase) load(ctx context.Context, bookImport *entities.Import) error {
+	// The readings, ratings and ebook files of the export only go to a
+	// member still in the household.
+	userID := bookImport.CreatedBy
+	if _, err := u.households.GetMember(ctx, bookImport.HouseholdID, userID); stdErrors.Is(err, sql.ErrNoRows) {
+		userID = uuid.Nil
+	} else if err != nil {
+		return err
+	}
+
+	file, size, err := u.download(ctx, bookImport)
+	if err != nil {
+		return err
+	}
+	defer removeTemp(file)
+
+	// Ebook files are stored before the transaction, which may run more than
+	// once and is not held open through the uploads, and discarded unless it
+	// saves them.
+	staged, err := u.stageFiles(ctx, bookImport.Format, file, size, userID)
+	if err != nil {
+		u.discardFiles(staged, nil)
+		return err
+	}
+
+	var saved map[string]bool
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		reader, _, err := open(bookImport.Format, file, size)
+		if err != nil {
+			return err
+		}
+
+		// The transaction may run again, so the counts start over with it.
+		result := *bookImport
+		var rowErrors []entities.RowError
+		t := target{
+			householdID: bookImport.HouseholdID,
+			format:      bookImport.Format,
+			duplicates:  bookImport.Duplicates,
+			apply:       true,
+			userID:      userID,
+			createdBy:   bookImport.CreatedBy,
+			files:       staged,
+			saved:       make(map[string]bool),
+		}
+		err = u.process(ctx, t, reader, func(row row) {
+			result.Count(row.action)
+			if row.err != nil {
+				rowErrors = append(rowErrors, entities.RowError{ImportID: bookImport.ImportID, Line: row.line, Message: row.err.Error()})
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		result.Status = entities.StatusDone
+		result.FinishedAt = &now
+		if err := u.r.FinishImport(ctx, &result, rowErrors); err != nil {
+			return err
+		}
+		saved = t.saved
+		return nil
+	})
+	if err != nil {
+		saved = nil
+	}
+	u.discardFiles(staged, saved)
+	return err
+}
+
+// download copies the upload of an import to a temporary file, since a zip
+// archive cannot be read as a stream.
+func (u *useCase) download(ctx context.Context, bookImport *entities.Import) (*os.File, int64, error) {
+	object, err := u.store.Get(ctx, bookImport.BlobKey())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer object.Close()
+
+	return spool(object)
+}
+
+// open reads an upload as an export of the format. A Calibre library also
+// gives the tree its ebook files are in.
+func open(format bookimport.Format, r io.ReaderAt, size int64) (bookimport.Reader, fs.FS, error) {
+	if format != bookimport.FormatCalibre {
+		if size > maxExportSize {
+			return nil, nil, errors.ErrImportTooLarge
+		}
+		reader, err := bookimport.NewReader(format, io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return nil, nil, errors.ErrImportMalformed
+		}
+		return reader, nil, nil
+	}
+
+	if size > maxLibrarySize {
+		return nil, nil, errors.ErrImportTooLarge
+	}
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, errors.ErrImportMalformed
+	}
+	reader, err := bookimport.NewCalibreReader(archive)
+	if err != nil {
+		return nil, nil, errors.ErrImportMalformed
+	}
+	return reader, archive, nil
+}
+
+// row is what happens to one row of an export. The book is the one created
+// or the one matched.
+type row struct {
+	line   int
+	action entities.Action
+	record *bookimport.Record
+	book   *catalogEntities.Book
+	err    error
+}
+
+func (r row) response() dtos.RowResponse {
+	response := dtos.RowResponse{Line: r.line, Action: r.action}
+	if r.record != nil {
+		response.Title = r.record.Title
+		response.Authors = r.record.Authors
+		response.ISBN = firstNonEmpty(r.record.ISBN13, r.record.ISBN10)
+	}
+	if r.book != nil && r.action != entities.ActionCreate {
+		response.BookID = &r.book.BookID
+		response.BookTitle = r.book.Title
+	}
+	if r.err != nil {
+		response.Error = r.err.Error()
+	}
+	return response
+}
+
+// target tells process where the rows of an export go.
+type target struct {
+	householdID uuid.UUID
+	format      bookimport.Format
+	duplicates  entities.Resolution
+	// apply is unset for a preview, which changes nothing.
+	apply bool
+	// userID is the member whose log gets the rows' readings and ratings
+	// and who owns their ebook files, if not nil.
+	userID uuid.UUID
+	// createdBy started the import; the book.added events name them.
+	createdBy uuid.UUID
+	// files holds the staged ebook files of a Calibre export by their names
+	// in the library; process adds those it saves to saved.
+	files map[string]*ebookUseCases.StagedFile
+	saved map[string]bool
+}
+
+// process goes through the rows of an export and tells visit what happens
+// to each. Only an applied target changes the catalog, giving every new
+// book of a table export a copy and linking the rows to their books.
+func (u *useCase) process(ctx context.Context, t target, reader bookimport.Reader, visit func(row)) error {
+	books, err := u.catalog.FindBooks(ctx, t.householdID, catalogEntities.BookFilter{})
+	if err != nil {
+		return err
+	}
+	sources, err := u.r.GetSources(ctx, t.householdID, t.format)
+	if err != nil {
+		return err
+	}
+	planner := newPlanner(books, sources, t.duplicates)
+
+	var readingLog *readingLog
+	if t.apply && t.userID != uuid.Nil {
+		if readingLog, err = u.readingLog(ctx, t.householdID, t.userID); err != nil {
+			return err
+		}
+	}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		var rowErr *bookimport.RowError
+		if stdErrors.As(err, &rowErr) {
+			visit(row{line: rowErr.Line, action: entities.ActionError, err: rowErr.Err})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		action, book := planner.plan(record)
+		switch action {
+		case entities.ActionCreate:
+			book = newBook(t.householdID, record)
+			if t.apply {
+				if _, err := u.catalog.CreateBook(ctx, t.createdBy, book); err != nil {
+					return err
+				}
+				// The books of a Calibre library are ebooks, not copies
+				// on a shelf, and stay on the wishlists.
+				if t.format != bookimport.FormatCalibre {
+					if err := u.addCopy(ctx, t, book); err != nil {
+						return err
+					}
+				}
+			}
+			planner.add(book)
+		case entities.ActionUpdate:
+			if merge(book, record) && t.apply {
+				book.UpdatedAt = time.Now()
+				if err := u.catalog.UpdateBook(ctx, book); err != nil {
+					return err
+				}
+			}
+		}
+
+		if action != entities.ActionDuplicate && t.apply {
+			if planner.link(record, book) {
+				source := &entities.Source{HouseholdID: t.householdID, Format: t.format, SourceID: record.SourceID, BookID: book.BookID}
+				if err := u.r.SaveSource(ctx, source); err != nil {
+					return err
+				}
+			}
+			if readingLog != nil {
+				if err := readingLog.add(ctx, book, record); err != nil {
+					return err
+				}
+			}
+			if len(t.files) > 0 {
+				if err := u.addFiles(ctx, t, book, record.Files); err != nil {
+					return err
+				}
+			}
+		}
+
+		visit(row{line: record.Line, action: action, record: record, book: book})
+	}
+}
+
+// addCopy gives a new book its copy, taking the book off the wishlists of
+// the household as adding it by hand does.
+func (u *useCase) addCopy(ctx context.Context, t target, book *catalogEntities.Book) error {
+	copy := catalogEntities.NewCopy(t.householdID, book.BookID)
+	if book.ISBN != "" {
+		items, err := u.wishlists.TakeItemsByISBN(ctx, t.createdBy, book.ISBN)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			copy.TakePurchase(item.PurchasePrice, item.PurchasedAt, item.PurchaseStore)
+		}
+	}
+
+	_, err := u.catalog.CreateCopy(ctx, copy)
+	return err
+}
+
+// addFiles saves the staged ebook files of a book for the member. The
+// ebook service keeps one file per contents, so importing a library again
+// adds none. A file it did add takes the metadata of the book, which
+// Calibre keeps more carefully than the files themselves, and any file not
+// yet attached to a book is attached to this one.
+func (u *useCase) addFiles(ctx context.Context, t target, book *catalogEntities.Book, names []string) error {
+	for _, name := range names {
+		staged, ok := t.files[name]
+		if !ok {
+			continue
+		}
+		file, created, err := u.ebooks.SaveFile(ctx, staged)
+		if err != nil {
+			return err
+		}
+
+		if created {
+			t.saved[name] = true
+			err = u.ebooks.UpdateFile(ctx, t.userID, file.FileID, ebookDtos.UpdateEbookRequest{
+				Title:       book.Title,
+				Authors:     book.Authors,
+				Language:    book.Language,
+				ISBN:        book.ISBN,
+				Description: book.Description,
+				Series:      book.Series,
+				SeriesIndex: book.SeriesIndex,
+				Tags:        book.Tags,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if file.BookID != nil {
+			continue
+		}
+
+		err = u.ebooks.AttachFile(ctx, t.userID, file.FileID, ebookDtos.AttachEbookRequest{BookID: book.BookID})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stageFiles stores the ebook files of a Calibre library for the member, by
+// their names in the library. Formats the ebook service does not take are
+// left out. On failure it returns what it staged along with the error.
+func (u *useCase) stageFiles(ctx context.Context, format bookimport.Format, file *os.File, size int64, userID uuid.UUID) (map[string]*ebookUseCases.StagedFile, error) {
+	staged := make(map[string]*ebookUseCases.StagedFile)
+	if format != bookimport.FormatCalibre || userID == uuid.Nil {
+		return staged, nil
+	}
+
+	reader, library, err := open(format, file, size)
+	if err != nil {
+		return staged, err
+	}
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return staged, nil
+		}
+		var rowErr *bookimport.RowError
+		if stdErrors.As(err, &rowErr) {
+			continue
+		}
+		if err != nil {
+			return staged, err
+		}
+
+		for _, name := range record.Files {
+			if _, ok := staged[name]; ok {
+				continue
+			}
+			file, err := u.stageFile(ctx, library, userID, name)
+			if stdErrors.Is(err, errors.ErrEbookUnsupported) || stdErrors.Is(err, errors.ErrEbookTooLarge) {
+				continue
+			}
+			if err != nil {
+				return staged, err
+			}
+			staged[name] = file
+		}
+	}
+}
+
+func (u *useCase) stageFile(ctx context.Context, library fs.FS, userID uuid.UUID, name string) (*ebookUseCases.StagedFile, error) {
+	info, err := fs.Stat(library, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > ebookUseCases.MaxUploadSize {
+		return nil, errors.ErrEbookTooLarge
+	}
+
+	src, err := library.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	// Files in a zip archive can only be read as a stream.
+	file, size, err := spool(src)
+	if err != nil {
+		return nil, err
+	}
+	defer removeTemp(file)
+
+	return u.ebooks.StageFile(ctx, userID, path.Base(name), file, size)
+}
+
+// discardFiles removes the staged ebook files an import did not save. It
+// runs detached from the job context, which is likely what failed the
+// import.
+func (u *useCase) discardFiles(staged map[string]*ebookUseCases.StagedFile, saved map[string]bool) {
+	for name, file := range staged {
+		if !saved[name] {
+			u.ebooks.DiscardFile(context.Background(), file)
+		}
+	}
+}
+
+// readingLog adds the readings and ratings of imported rows to a member's
+// log. A reading finished the same day as one already logged is the same
+// reading, so importing an export again adds none; ratings replace the
+// member's review of the book.
+type readingLog struct {
+	readings    readingRepository.Repository
+	householdID uuid.UUID
+	userID      uuid.UUID
+	finished    map[string]bool
+}
+
+func (u *useCase) readingLog(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*readingLog, error) {
+	readings, err := u.readings.GetReadings(ctx, householdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	finished := make(map[string]bool, len(readings))
+	for _, reading := range readings {
+		if reading.FinishedAt != nil {
+			finished[finishedKey(reading.BookID, *reading.FinishedAt)] = true
+		}
+	}
+
+	return &readingLog{readings: u.readings, householdID: householdID, userID: userID, finished: finished}, nil
+}
+
+func (l *readingLog) add(ctx context.Context, book *catalogEntities.Book, record *bookimport.Record) error {
+	if record.DateRead != nil && !l.finished[finishedKey(book.BookID, *record.DateRead)] {
+		reading := readingEntities.NewReading(l.householdID, book.BookID, l.userID)
+		reading.FinishedAt = record.DateRead
+		if _, err := l.readings.CreateReading(ctx, reading); err != nil {
+			return err
+		}
+		l.finished[finishedKey(book.BookID, *record.DateRead)] = true
+	}
+
+	// LibraryThing allows half stars.
+	if rating := int(math.Round(record.Rating)); rating >= 1 && rating <= 5 {
+		review := readingEntities.NewReview(l.householdID, book.BookID, l.userID, rating, truncate(record.Review, maxReview))
+		if err := l.readings.SaveReview(ctx, review); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// spool copies r to a temporary file, to be removed with removeTemp.
+func spool(r io.Reader) (*os.File, int64, error) {
+	file, err := os.CreateTemp("", "book-import-*")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size, err := io.Copy(file, r)
+	if err != nil {
+		removeTemp(file)
+		return nil, 0, err
+	}
+
+	return file, size, nil
+}
+
+func removeTemp(file *os.File) {
+	file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		log.Error().Err(err).Str("path", file.Name()).Msg("failed to remove temporary file")
+	}
+}
+
+func finishedKey(bookID uuid.UUID, finishedAt time.Time) string {
+	return bookID.String() + "/" + finishedAt.UTC().Format(time.DateOnly)
+}
+
+// deleteFile removes the upload of an import that is over. A failure only
+// leaves an orphaned file behind.
+func (u *useCase) deleteFile(ctx context.Context, bookImport *entities.Import) {
+	if err := u.store.Delete(ctx, bookImport.BlobKey()); err != nil {
+		log.Error().Err(err).Str("import_id", bookImport.ImportID.String()).Msg("failed to delete book import file")
+	}
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+func (u *useCase) editor(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanEditLibrary() {
+		return nil, errors.ErrHouseholdForbidden
+	}
+	return member, nil
+}
+
+func resolution(duplicates entities.Resolution) entities.Resolution {
+	if duplicates == "" {
+		return entities.ResolutionSkip
+	}
+	return duplicates
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
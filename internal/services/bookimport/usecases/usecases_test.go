@@ -0,0 +1,981 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"home-library/internal/services/bookimport/dtos"
+	"home-library/internal/services/bookimport/entities"
+	catalogEntities "home-library/internal/services/catalog/entities"
+	ebookDtos "home-library/internal/services/ebook/dtos"
+	ebookEntities "home-library/internal/services/ebook/entities"
+	ebookUseCases "home-library/internal/services/ebook/usecases"
+	householdEntities "home-library/internal/services/household/entities"
+	readingEntities "home-library/internal/services/reading/entities"
+	wishlistEntities "home-library/internal/services/wishlist/entities"
+	"home-library/pkg/blobstore"
+	"home-library/pkg/bookimport"
+	"home-library/pkg/errors"
+	"home-library/pkg/jobs"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateImport(ctx context.Context, bookImport *entities.Import) error {
+	return m.Called(ctx, bookImport).Error(0)
+}
+
+func (m *MockRepository) GetImport(ctx context.Context, householdID uuid.UUID, importID uuid.UUID) (*entities.Import, error) {
+	args := m.Called(ctx, householdID, importID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Import), args.Error(1)
+}
+
+func (m *MockRepository) GetImportByID(ctx context.Context, importID uuid.UUID) (*entities.Import, error) {
+	args := m.Called(ctx, importID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Import), args.Error(1)
+}
+
+func (m *MockRepository) GetImports(ctx context.Context, householdID uuid.UUID) ([]entities.Import, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Import), args.Error(1)
+}
+
+func (m *MockRepository) FinishImport(ctx context.Context, bookImport *entities.Import, rowErrors []entities.RowError) error {
+	return m.Called(ctx, bookImport, rowErrors).Error(0)
+}
+
+func (m *MockRepository) FailImport(ctx context.Context, importID uuid.UUID, message string, now time.Time) error {
+	return m.Called(ctx, importID, message, now).Error(0)
+}
+
+func (m *MockRepository) GetRowErrors(ctx context.Context, importID uuid.UUID) ([]entities.RowError, error) {
+	args := m.Called(ctx, importID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.RowError), args.Error(1)
+}
+
+func (m *MockRepository) GetSources(ctx context.Context, householdID uuid.UUID, format bookimport.Format) ([]entities.Source, error) {
+	args := m.Called(ctx, householdID, format)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Source), args.Error(1)
+}
+
+func (m *MockRepository) SaveSource(ctx context.Context, source *entities.Source) error {
+	return m.Called(ctx, source).Error(0)
+}
+
+type MockCatalogRepository struct {
+	mock.Mock
+}
+
+func (m *MockCatalogRepository) CreateLocation(ctx context.Context, location *catalogEntities.Location) (uuid.UUID, error) {
+	args := m.Called(ctx, location)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetLocations(ctx context.Context, householdID uuid.UUID) ([]catalogEntities.Location, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]catalogEntities.Location), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*catalogEntities.Location, error) {
+	args := m.Called(ctx, householdID, locationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*catalogEntities.Location), args.Error(1)
+}
+
+func (m *MockCatalogRepository) RenameLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, locationID, name).Error(0)
+}
+
+func (m *MockCatalogRepository) DeleteLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) error {
+	return m.Called(ctx, householdID, locationID).Error(0)
+}
+
+func (m *MockCatalogRepository) CreateBook(ctx context.Context, userID uuid.UUID, book *catalogEntities.Book) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, book)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) (*catalogEntities.Book, error) {
+	args := m.Called(ctx, householdID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*catalogEntities.Book), args.Error(1)
+}
+
+func (m *MockCatalogRepository) FindBooks(ctx context.Context, householdID uuid.UUID, filter catalogEntities.BookFilter) ([]catalogEntities.Book, error) {
+	args := m.Called(ctx, householdID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]catalogEntities.Book), args.Error(1)
+}
+
+func (m *MockCatalogRepository) UpdateBook(ctx context.Context, book *catalogEntities.Book) error {
+	return m.Called(ctx, book).Error(0)
+}
+
+func (m *MockCatalogRepository) HasCover(ctx context.Context, householdID uuid.UUID, coverID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, householdID, coverID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCatalogRepository) DeleteBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) error {
+	return m.Called(ctx, householdID, bookID).Error(0)
+}
+
+func (m *MockCatalogRepository) CreateCopy(ctx context.Context, copy *catalogEntities.Copy) (uuid.UUID, error) {
+	args := m.Called(ctx, copy)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) (*catalogEntities.Copy, error) {
+	args := m.Called(ctx, householdID, copyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*catalogEntities.Copy), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetCopiesByBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) ([]catalogEntities.Copy, error) {
+	args := m.Called(ctx, householdID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]catalogEntities.Copy), args.Error(1)
+}
+
+func (m *MockCatalogRepository) UpdateCopy(ctx context.Context, copy *catalogEntities.Copy) error {
+	return m.Called(ctx, copy).Error(0)
+}
+
+func (m *MockCatalogRepository) MoveCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID, locationID *uuid.UUID) error {
+	return m.Called(ctx, householdID, copyID, locationID).Error(0)
+}
+
+func (m *MockCatalogRepository) DeleteCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) error {
+	return m.Called(ctx, householdID, copyID).Error(0)
+}
+
+type MockReadingRepository struct {
+	mock.Mock
+}
+
+func (m *MockReadingRepository) CreateReading(ctx context.Context, reading *readingEntities.Reading) (uuid.UUID, error) {
+	args := m.Called(ctx, reading)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockReadingRepository) GetReadings(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) ([]readingEntities.Reading, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]readingEntities.Reading), args.Error(1)
+}
+
+func (m *MockReadingRepository) UpdateReading(ctx context.Context, reading *readingEntities.Reading) error {
+	return m.Called(ctx, reading).Error(0)
+}
+
+func (m *MockReadingRepository) DeleteReading(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, readingID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID, readingID).Error(0)
+}
+
+func (m *MockReadingRepository) SaveReview(ctx context.Context, review *readingEntities.Review) error {
+	return m.Called(ctx, review).Error(0)
+}
+
+func (m *MockReadingRepository) GetReviews(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) ([]readingEntities.Review, error) {
+	args := m.Called(ctx, householdID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]readingEntities.Review), args.Error(1)
+}
+
+func (m *MockReadingRepository) DeleteReview(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, bookID, userID).Error(0)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+type MockEbookUseCase struct {
+	mock.Mock
+}
+
+func (m *MockEbookUseCase) UploadFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (*ebookDtos.EbookResponse, bool, error) {
+	data, _ := io.ReadAll(io.NewSectionReader(r, 0, size))
+	args := m.Called(ctx, userID, name, string(data))
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*ebookDtos.EbookResponse), args.Bool(1), args.Error(2)
+}
+
+func (m *MockEbookUseCase) StageFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (*ebookUseCases.StagedFile, error) {
+	data, _ := io.ReadAll(io.NewSectionReader(r, 0, size))
+	args := m.Called(ctx, userID, name, string(data))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ebookUseCases.StagedFile), args.Error(1)
+}
+
+func (m *MockEbookUseCase) SaveFile(ctx context.Context, staged *ebookUseCases.StagedFile) (*ebookDtos.EbookResponse, bool, error) {
+	args := m.Called(ctx, staged)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*ebookDtos.EbookResponse), args.Bool(1), args.Error(2)
+}
+
+func (m *MockEbookUseCase) DiscardFile(ctx context.Context, staged *ebookUseCases.StagedFile) {
+	m.Called(ctx, staged)
+}
+
+func (m *MockEbookUseCase) GetFiles(ctx context.Context, viewerID uuid.UUID) ([]ebookDtos.EbookResponse, error) {
+	args := m.Called(ctx, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ebookDtos.EbookResponse), args.Error(1)
+}
+
+func (m *MockEbookUseCase) GetFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*ebookDtos.EbookResponse, error) {
+	args := m.Called(ctx, viewerID, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ebookDtos.EbookResponse), args.Error(1)
+}
+
+func (m *MockEbookUseCase) UpdateFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload ebookDtos.UpdateEbookRequest) error {
+	return m.Called(ctx, userID, fileID, payload).Error(0)
+}
+
+func (m *MockEbookUseCase) AttachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload ebookDtos.AttachEbookRequest) error {
+	return m.Called(ctx, userID, fileID, payload).Error(0)
+}
+
+func (m *MockEbookUseCase) DetachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	return m.Called(ctx, userID, fileID).Error(0)
+}
+
+func (m *MockEbookUseCase) DeleteFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	return m.Called(ctx, userID, fileID).Error(0)
+}
+
+func (m *MockEbookUseCase) OpenFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*ebookEntities.EbookFile, *blobstore.Object, error) {
+	args := m.Called(ctx, viewerID, fileID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*ebookEntities.EbookFile), args.Get(1).(*blobstore.Object), args.Error(2)
+}
+
+type MockQueue struct {
+	mock.Mock
+}
+
+func (m *MockQueue) Enqueue(ctx context.Context, kind string, payload any, opts jobs.Options) (uuid.UUID, error) {
+	args := m.Called(ctx, kind, payload, opts)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+// passthroughTx runs the unit of work directly.
+type passthroughTx struct{}
+
+func (passthroughTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) CreateItem(ctx context.Context, item *wishlistEntities.WishlistItem) (uuid.UUID, error) {
+	args := m.Called(ctx, item)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetItemsByUser(ctx context.Context, userID uuid.UUID) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetSharedItem(ctx context.Context, itemID uuid.UUID, viewerID uuid.UUID) (*wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, itemID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetSharedItemsByUser(ctx context.Context, ownerID uuid.UUID, viewerID uuid.UUID) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, ownerID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) FindItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbns []string) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) TakeItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbn string) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) UpdateItem(ctx context.Context, item *wishlistEntities.WishlistItem) error {
+	return m.Called(ctx, item).Error(0)
+}
+
+func (m *MockWishlistRepository) DeleteItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, itemID, userID).Error(0)
+}
+
+func (m *MockWishlistRepository) ReserveItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID, reservedAt time.Time) error {
+	return m.Called(ctx, itemID, userID, reservedAt).Error(0)
+}
+
+func (m *MockWishlistRepository) CancelReservation(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, itemID, userID).Error(0)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	catalog    *MockCatalogRepository
+	readings   *MockReadingRepository
+	households *MockHouseholdRepository
+	wishlists  *MockWishlistRepository
+	ebooks     *MockEbookUseCase
+	queue      *MockQueue
+	store      blobstore.Store
+}
+
+func newUseCase(t *testing.T) (UseCase, *mocks) {
+	store, err := blobstore.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	m := &mocks{
+		repo:       new(MockRepository),
+		catalog:    new(MockCatalogRepository),
+		readings:   new(MockReadingRepository),
+		households: new(MockHouseholdRepository),
+		wishlists:  new(MockWishlistRepository),
+		ebooks:     new(MockEbookUseCase),
+		queue:      new(MockQueue),
+		store:      store,
+	}
+	return NewUseCase(m.repo, m.catalog, m.readings, m.households, m.wishlists, m.ebooks, m.store, m.queue, passthroughTx{}), m
+}
+
+func member(households *MockHouseholdRepository, userID uuid.UUID, householdID uuid.UUID, role householdEntities.Role) {
+	households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: role}, nil)
+}
+
+// export has a book of the catalog by ISBN, a new book, a row without a
+// title and a book of the catalog by title and author.
+const export = "\ufeff" + `Book Id,Title,Author,Author l-f,Additional Authors,ISBN,ISBN13,My Rating,Average Rating,Publisher,Binding,Number of Pages,Year Published,Original Publication Year,Date Read,Date Added,Bookshelves,Bookshelves with positions,Exclusive Shelf,My Review,Spoiler,Private Notes,Read Count,Owned Copies
+234225,Dune (Dune #1),Frank Herbert,"Herbert, Frank",,"=""0441013597""","=""9780441013593""",5,4.25,Ace,Paperback,658,2005,1965,2021/03/15,2020/12/01,"sci-fi, favorites, read","sci-fi (#3), favorites (#1), read (#120)",read,"Still great.",,,1,1
+5,"Пикник на обочине",Аркадий Стругацкий,"Стругацкий, Аркадий",Борис Стругацкий,"=""""","=""""",0,4.21,АСТ,Hardcover,320,2015,1972,,2022/01/10,to-read,to-read (#4),to-read,,,,0,0
+6,,Nobody,,,,,0,0,,,,,,,2022/01/10,,,,,,,0,0
+7,Solaris,Stanisław Lem,"Lem, Stanisław",,"=""""","=""""",0,4.0,,,,,,,2022/01/10,,,to-read,,,,0,0
+`
+
+func catalogBooks(householdID uuid.UUID) []catalogEntities.Book {
+	dune := catalogEntities.NewBook(householdID)
+	dune.Title = "Dune"
+	dune.Authors = []string{"Frank Herbert"}
+	dune.ISBN = "9780441013593"
+
+	solaris := catalogEntities.NewBook(householdID)
+	solaris.Title = "Solaris"
+	solaris.Authors = []string{"Stanisław Lem"}
+	solaris.Publisher = "Walker"
+
+	return []catalogEntities.Book{*dune, *solaris}
+}
+
+func TestPreviewImport(t *testing.T) {
+	userID, householdID := uuid.New(), uuid.New()
+	request := dtos.ImportRequest{Format: bookimport.FormatGoodreads}
+
+	t.Run("plans every row without changing anything", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleEditor)
+		books := catalogBooks(householdID)
+		m.catalog.On("FindBooks", mock.Anything, householdID, catalogEntities.BookFilter{}).Return(books, nil)
+		m.repo.On("GetSources", mock.Anything, householdID, bookimport.FormatGoodreads).Return(nil, nil)
+
+		preview, err := u.PreviewImport(context.Background(), userID, request, strings.NewReader(export), int64(len(export)))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, preview.Create)
+		assert.Equal(t, 1, preview.Update)
+		assert.Equal(t, 1, preview.Duplicate)
+		assert.Equal(t, 1, preview.Errors)
+		assert.False(t, preview.Truncated)
+		if assert.Len(t, preview.Rows, 4) {
+			assert.Equal(t, entities.ActionUpdate, preview.Rows[0].Action)
+			assert.Equal(t, books[0].BookID, *preview.Rows[0].BookID)
+			assert.Equal(t, "9780441013593", preview.Rows[0].ISBN)
+			assert.Equal(t, entities.ActionCreate, preview.Rows[1].Action)
+			assert.Nil(t, preview.Rows[1].BookID)
+			assert.Equal(t, entities.ActionError, preview.Rows[2].Action)
+			assert.Equal(t, 4, preview.Rows[2].Line)
+			assert.NotEmpty(t, preview.Rows[2].Error)
+			assert.Equal(t, entities.ActionDuplicate, preview.Rows[3].Action)
+			assert.Equal(t, books[1].BookID, *preview.Rows[3].BookID)
+		}
+		m.catalog.AssertNotCalled(t, "CreateBook", mock.Anything, mock.Anything, mock.Anything)
+		m.catalog.AssertNotCalled(t, "UpdateBook", mock.Anything, mock.Anything)
+	})
+
+	t.Run("title matches follow the resolution", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleEditor)
+		m.catalog.On("FindBooks", mock.Anything, householdID, catalogEntities.BookFilter{}).Return(catalogBooks(householdID), nil)
+		m.repo.On("GetSources", mock.Anything, householdID, bookimport.FormatGoodreads).Return(nil, nil)
+
+		preview, err := u.PreviewImport(context.Background(), userID, dtos.ImportRequest{
+			Format:     bookimport.FormatGoodreads,
+			Duplicates: entities.ResolutionCreate,
+		}, strings.NewReader(export), int64(len(export)))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, preview.Create)
+		assert.Equal(t, 1, preview.Update)
+		assert.Equal(t, 0, preview.Duplicate)
+	})
+
+	t.Run("rows of an earlier import update their books", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleEditor)
+		books := catalogBooks(householdID)
+		m.catalog.On("FindBooks", mock.Anything, householdID, catalogEntities.BookFilter{}).Return(books, nil)
+		m.repo.On("GetSources", mock.Anything, householdID, bookimport.FormatGoodreads).
+			Return([]entities.Source{{HouseholdID: householdID, Format: bookimport.FormatGoodreads, SourceID: "7", BookID: books[1].BookID}}, nil)
+
+		preview, err := u.PreviewImport(context.Background(), userID, request, strings.NewReader(export), int64(len(export)))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, preview.Update)
+		assert.Equal(t, 0, preview.Duplicate)
+		assert.Equal(t, books[1].BookID, *preview.Rows[3].BookID)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleEditor)
+
+		_, err := u.PreviewImport(context.Background(), userID, request, strings.NewReader(export), maxExportSize+1)
+
+		assert.ErrorIs(t, err, errors.ErrImportTooLarge)
+	})
+
+	t.Run("not an export of the format", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleEditor)
+
+		_, err := u.PreviewImport(context.Background(), userID, request, strings.NewReader("title;author\n"), 13)
+
+		assert.ErrorIs(t, err, errors.ErrImportMalformed)
+	})
+
+	t.Run("viewer", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleViewer)
+
+		_, err := u.PreviewImport(context.Background(), userID, request, strings.NewReader(export), int64(len(export)))
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+	})
+}
+
+func TestStartImport(t *testing.T) {
+	userID, householdID := uuid.New(), uuid.New()
+	request := dtos.ImportRequest{Format: bookimport.FormatGoodreads}
+
+	t.Run("keeps the file and queues the job", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleEditor)
+		var created *entities.Import
+		m.repo.On("CreateImport", mock.Anything, mock.AnythingOfType("*entities.Import")).
+			Run(func(args mock.Arguments) { created = args.Get(1).(*entities.Import) }).
+			Return(nil)
+		m.queue.On("Enqueue", mock.Anything, ImportJob, mock.Anything, jobs.Options{MaxAttempts: importAttempts, Timeout: importTimeout}).
+			Return(uuid.New(), nil)
+
+		importID, err := u.StartImport(context.Background(), userID, request, strings.NewReader(export), int64(len(export)))
+
+		require.NoError(t, err)
+		assert.Equal(t, created.ImportID, importID)
+		assert.Equal(t, householdID, created.HouseholdID)
+		assert.Equal(t, entities.ResolutionSkip, created.Duplicates)
+		assert.Equal(t, entities.StatusPending, created.Status)
+		m.queue.AssertCalled(t, "Enqueue", mock.Anything, ImportJob, importPayload{ImportID: importID}, mock.Anything)
+
+		object, err := m.store.Get(context.Background(), created.BlobKey())
+		require.NoError(t, err)
+		defer object.Close()
+		data, err := io.ReadAll(object)
+		require.NoError(t, err)
+		assert.Equal(t, export, string(data))
+	})
+
+	t.Run("removes the file when the job is not queued", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleEditor)
+		var created *entities.Import
+		m.repo.On("CreateImport", mock.Anything, mock.AnythingOfType("*entities.Import")).
+			Run(func(args mock.Arguments) { created = args.Get(1).(*entities.Import) }).
+			Return(nil)
+		m.queue.On("Enqueue", mock.Anything, ImportJob, mock.Anything, mock.Anything).Return(uuid.Nil, sql.ErrConnDone)
+
+		_, err := u.StartImport(context.Background(), userID, request, strings.NewReader(export), int64(len(export)))
+
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		_, err = m.store.Stat(context.Background(), created.BlobKey())
+		assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	})
+
+	t.Run("not an export of the format", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleEditor)
+
+		_, err := u.StartImport(context.Background(), userID, dtos.ImportRequest{Format: bookimport.FormatLibraryThing}, strings.NewReader(export), int64(len(export)))
+
+		assert.ErrorIs(t, err, errors.ErrImportMalformed)
+		m.repo.AssertNotCalled(t, "CreateImport", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetImport(t *testing.T) {
+	userID, householdID := uuid.New(), uuid.New()
+
+	t.Run("with row errors", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleViewer)
+		bookImport := entities.NewImport(householdID, userID, bookimport.FormatGoodreads, entities.ResolutionSkip)
+		m.repo.On("GetImport", mock.Anything, householdID, bookImport.ImportID).Return(bookImport, nil)
+		m.repo.On("GetRowErrors", mock.Anything, bookImport.ImportID).
+			Return([]entities.RowError{{ImportID: bookImport.ImportID, Line: 4, Message: "title is missing"}}, nil)
+
+		response, err := u.GetImport(context.Background(), userID, bookImport.ImportID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, bookImport.ImportID, response.ImportID)
+		assert.Equal(t, []dtos.RowErrorResponse{{Line: 4, Message: "title is missing"}}, response.RowErrors)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		u, m := newUseCase(t)
+		member(m.households, userID, householdID, householdEntities.RoleViewer)
+		importID := uuid.New()
+		m.repo.On("GetImport", mock.Anything, householdID, importID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.GetImport(context.Background(), userID, importID)
+
+		assert.ErrorIs(t, err, errors.ErrImportNotFound)
+	})
+}
+
+func importJob(t *testing.T, importID uuid.UUID, attempts int) *jobs.Job {
+	payload, err := json.Marshal(importPayload{ImportID: importID})
+	require.NoError(t, err)
+	return &jobs.Job{JobID: uuid.New(), Kind: ImportJob, Payload: payload, Attempts: attempts, MaxAttempts: importAttempts}
+}
+
+const solarisOPF = `<?xml version='1.0' encoding='utf-8'?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+    <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+        <dc:identifier opf:scheme="uuid">6f1b1c34-93a4-4bd0-a3ef-6f0f5d3c1a77</dc:identifier>
+        <dc:title>Solaris</dc:title>
+        <dc:creator opf:role="aut">Stanisław Lem</dc:creator>
+        <dc:subject>Science Fiction</dc:subject>
+        <meta name="calibre:series" content="Lem"/>
+        <meta name="calibre:series_index" content="2.0"/>
+    </metadata>
+</package>`
+
+// library zips a Calibre library of Solaris in two formats.
+func library(t *testing.T) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"Stanislaw Lem/Solaris (12)/metadata.opf": solarisOPF,
+		"Stanislaw Lem/Solaris (12)/Solaris.epub": "epub",
+		"Stanislaw Lem/Solaris (12)/Solaris.mobi": "mobi",
+	} {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestRun(t *testing.T) {
+	userID, householdID := uuid.New(), uuid.New()
+
+	upload := func(t *testing.T, m *mocks, bookImport *entities.Import) {
+		err := m.store.Put(context.Background(), bookImport.BlobKey(), strings.NewReader(export), int64(len(export)), "text/csv")
+		require.NoError(t, err)
+	}
+
+	t.Run("loads the export", func(t *testing.T) {
+		u, m := newUseCase(t)
+		bookImport := entities.NewImport(householdID, userID, bookimport.FormatGoodreads, entities.ResolutionSkip)
+		upload(t, m, bookImport)
+		books := catalogBooks(householdID)
+		dune, solaris := &books[0], &books[1]
+		finishedAt := time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)
+
+		m.repo.On("GetImportByID", mock.Anything, bookImport.ImportID).Return(bookImport, nil)
+		m.households.On("GetMember", mock.Anything, householdID, userID).
+			Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleEditor}, nil)
+		m.catalog.On("FindBooks", mock.Anything, householdID, catalogEntities.BookFilter{}).Return(books, nil)
+		m.repo.On("GetSources", mock.Anything, householdID, bookimport.FormatGoodreads).Return(nil, nil)
+		// Dune was already logged as read that day.
+		m.readings.On("GetReadings", mock.Anything, householdID, userID).
+			Return([]readingEntities.Reading{{BookID: dune.BookID, FinishedAt: &finishedAt}}, nil)
+		m.catalog.On("UpdateBook", mock.Anything, mock.AnythingOfType("*entities.Book")).Return(nil)
+		m.catalog.On("CreateBook", mock.Anything, mock.Anything, mock.AnythingOfType("*entities.Book")).Return(uuid.New(), nil)
+		m.catalog.On("CreateCopy", mock.Anything, mock.AnythingOfType("*entities.Copy")).Return(uuid.New(), nil)
+		m.readings.On("SaveReview", mock.Anything, mock.AnythingOfType("*entities.Review")).Return(nil)
+		m.repo.On("SaveSource", mock.Anything, mock.AnythingOfType("*entities.Source")).Return(nil)
+		var finished *entities.Import
+		var rowErrors []entities.RowError
+		m.repo.On("FinishImport", mock.Anything, mock.AnythingOfType("*entities.Import"), mock.Anything).
+			Run(func(args mock.Arguments) {
+				finished = args.Get(1).(*entities.Import)
+				rowErrors = args.Get(2).([]entities.RowError)
+			}).
+			Return(nil)
+
+		err := u.Run(context.Background(), importJob(t, bookImport.ImportID, 1))
+
+		require.NoError(t, err)
+		assert.Equal(t, entities.StatusDone, finished.Status)
+		assert.NotNil(t, finished.FinishedAt)
+		assert.Equal(t, 1, finished.Created)
+		assert.Equal(t, 1, finished.Updated)
+		assert.Equal(t, 1, finished.Skipped)
+		assert.Equal(t, 1, finished.Failed)
+		if assert.Len(t, rowErrors, 1) {
+			assert.Equal(t, 4, rowErrors[0].Line)
+		}
+
+		// The update fills in what Dune lacked; Solaris, a title match, is
+		// left alone.
+		m.catalog.AssertCalled(t, "UpdateBook", mock.Anything, mock.MatchedBy(func(book *catalogEntities.Book) bool {
+			return book.BookID == dune.BookID && book.Publisher == "Ace" && *book.Pages == 658
+		}))
+		assert.Equal(t, "Walker", solaris.Publisher)
+		m.catalog.AssertNumberOfCalls(t, "UpdateBook", 1)
+		m.catalog.AssertCalled(t, "CreateBook", mock.Anything, mock.Anything, mock.MatchedBy(func(book *catalogEntities.Book) bool {
+			return book.Title == "Пикник на обочине" && book.HouseholdID == householdID
+		}))
+		m.catalog.AssertNumberOfCalls(t, "CreateCopy", 1)
+		m.readings.AssertNotCalled(t, "CreateReading", mock.Anything, mock.Anything)
+		m.readings.AssertCalled(t, "SaveReview", mock.Anything, mock.MatchedBy(func(review *readingEntities.Review) bool {
+			return review.BookID == dune.BookID && review.UserID == userID && review.Rating == 5 && review.Text == "Still great."
+		}))
+		// The skipped duplicate is not linked to Solaris.
+		m.repo.AssertCalled(t, "SaveSource", mock.Anything, &entities.Source{
+			HouseholdID: householdID, Format: bookimport.FormatGoodreads, SourceID: "234225", BookID: dune.BookID,
+		})
+		m.repo.AssertNumberOfCalls(t, "SaveSource", 2)
+
+		_, err = m.store.Stat(context.Background(), bookImport.BlobKey())
+		assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	})
+
+	t.Run("loads a Calibre library", func(t *testing.T) {
+		u, m := newUseCase(t)
+		bookImport := entities.NewImport(householdID, userID, bookimport.FormatCalibre, entities.ResolutionSkip)
+		data := library(t)
+		require.NoError(t, m.store.Put(context.Background(), bookImport.BlobKey(), bytes.NewReader(data), int64(len(data)), bookImport.ContentType()))
+		fileID := uuid.New()
+
+		m.repo.On("GetImportByID", mock.Anything, bookImport.ImportID).Return(bookImport, nil)
+		m.households.On("GetMember", mock.Anything, householdID, userID).
+			Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleEditor}, nil)
+		m.catalog.On("FindBooks", mock.Anything, householdID, catalogEntities.BookFilter{}).Return(nil, nil)
+		m.repo.On("GetSources", mock.Anything, householdID, bookimport.FormatCalibre).Return(nil, nil)
+		m.readings.On("GetReadings", mock.Anything, householdID, userID).Return(nil, nil)
+		m.catalog.On("CreateBook", mock.Anything, mock.Anything, mock.AnythingOfType("*entities.Book")).Return(uuid.New(), nil)
+		m.repo.On("SaveSource", mock.Anything, mock.AnythingOfType("*entities.Source")).Return(nil)
+		staged := &ebookUseCases.StagedFile{}
+		m.ebooks.On("StageFile", mock.Anything, userID, "Solaris.epub", "epub").Return(staged, nil)
+		m.ebooks.On("StageFile", mock.Anything, userID, "Solaris.mobi", "mobi").Return(nil, errors.ErrEbookUnsupported)
+		m.ebooks.On("SaveFile", mock.Anything, staged).Return(&ebookDtos.EbookResponse{FileID: fileID}, true, nil)
+		m.ebooks.On("UpdateFile", mock.Anything, userID, fileID, mock.Anything).Return(nil)
+		m.ebooks.On("AttachFile", mock.Anything, userID, fileID, mock.Anything).Return(nil)
+		var finished *entities.Import
+		m.repo.On("FinishImport", mock.Anything, mock.AnythingOfType("*entities.Import"), mock.Anything).
+			Run(func(args mock.Arguments) { finished = args.Get(1).(*entities.Import) }).
+			Return(nil)
+
+		err := u.Run(context.Background(), importJob(t, bookImport.ImportID, 1))
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, finished.Created)
+		var book *catalogEntities.Book
+		m.catalog.AssertCalled(t, "CreateBook", mock.Anything, mock.Anything, mock.MatchedBy(func(b *catalogEntities.Book) bool {
+			book = b
+			return true
+		}))
+		assert.Equal(t, "Solaris", book.Title)
+		assert.Equal(t, "Lem", book.Series)
+		assert.Equal(t, 2.0, *book.SeriesIndex)
+		assert.Equal(t, []string{"Science Fiction"}, []string(book.Tags))
+		// An ebook is not a copy on a shelf.
+		m.catalog.AssertNotCalled(t, "CreateCopy", mock.Anything, mock.Anything)
+		m.repo.AssertCalled(t, "SaveSource", mock.Anything, &entities.Source{
+			HouseholdID: householdID, Format: bookimport.FormatCalibre, SourceID: "6f1b1c34-93a4-4bd0-a3ef-6f0f5d3c1a77", BookID: book.BookID,
+		})
+		m.ebooks.AssertCalled(t, "UpdateFile", mock.Anything, userID, fileID, mock.MatchedBy(func(payload ebookDtos.UpdateEbookRequest) bool {
+			return payload.Title == "Solaris" && payload.Series == "Lem" && payload.Authors[0] == "Stanisław Lem"
+		}))
+		m.ebooks.AssertCalled(t, "AttachFile", mock.Anything, userID, fileID, ebookDtos.AttachEbookRequest{BookID: book.BookID})
+		m.ebooks.AssertNotCalled(t, "DiscardFile", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed Calibre import discards its ebook files", func(t *testing.T) {
+		u, m := newUseCase(t)
+		bookImport := entities.NewImport(householdID, userID, bookimport.FormatCalibre, entities.ResolutionSkip)
+		data := library(t)
+		require.NoError(t, m.store.Put(context.Background(), bookImport.BlobKey(), bytes.NewReader(data), int64(len(data)), bookImport.ContentType()))
+		fileID := uuid.New()
+
+		m.repo.On("GetImportByID", mock.Anything, bookImport.ImportID).Return(bookImport, nil)
+		m.households.On("GetMember", mock.Anything, householdID, userID).
+			Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleEditor}, nil)
+		m.catalog.On("FindBooks", mock.Anything, householdID, catalogEntities.BookFilter{}).Return(nil, nil)
+		m.repo.On("GetSources", mock.Anything, householdID, bookimport.FormatCalibre).Return(nil, nil)
+		m.readings.On("GetReadings", mock.Anything, householdID, userID).Return(nil, nil)
+		m.catalog.On("CreateBook", mock.Anything, mock.Anything, mock.AnythingOfType("*entities.Book")).Return(uuid.New(), nil)
+		m.repo.On("SaveSource", mock.Anything, mock.AnythingOfType("*entities.Source")).Return(nil)
+		staged := &ebookUseCases.StagedFile{}
+		m.ebooks.On("StageFile", mock.Anything, userID, "Solaris.epub", "epub").Return(staged, nil)
+		m.ebooks.On("StageFile", mock.Anything, userID, "Solaris.mobi", "mobi").Return(nil, errors.ErrEbookUnsupported)
+		m.ebooks.On("SaveFile", mock.Anything, staged).Return(&ebookDtos.EbookResponse{FileID: fileID}, true, nil)
+		m.ebooks.On("UpdateFile", mock.Anything, userID, fileID, mock.Anything).Return(nil)
+		m.ebooks.On("AttachFile", mock.Anything, userID, fileID, mock.Anything).Return(nil)
+		m.repo.On("FinishImport", mock.Anything, mock.AnythingOfType("*entities.Import"), mock.Anything).Return(sql.ErrConnDone)
+		m.ebooks.On("DiscardFile", mock.Anything, staged).Return()
+
+		err := u.Run(context.Background(), importJob(t, bookImport.ImportID, 1))
+
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		m.ebooks.AssertCalled(t, "DiscardFile", mock.Anything, staged)
+	})
+
+	t.Run("creator left the household", func(t *testing.T) {
+		u, m := newUseCase(t)
+		bookImport := entities.NewImport(householdID, userID, bookimport.FormatGoodreads, entities.ResolutionSkip)
+		upload(t, m, bookImport)
+
+		m.repo.On("GetImportByID", mock.Anything, bookImport.ImportID).Return(bookImport, nil)
+		m.households.On("GetMember", mock.Anything, householdID, userID).Return(nil, sql.ErrNoRows)
+		m.catalog.On("FindBooks", mock.Anything, householdID, catalogEntities.BookFilter{}).Return(nil, nil)
+		m.repo.On("GetSources", mock.Anything, householdID, bookimport.FormatGoodreads).Return(nil, nil)
+		m.repo.On("SaveSource", mock.Anything, mock.AnythingOfType("*entities.Source")).Return(nil)
+		m.catalog.On("CreateBook", mock.Anything, mock.Anything, mock.AnythingOfType("*entities.Book")).Return(uuid.New(), nil)
+		m.catalog.On("CreateCopy", mock.Anything, mock.AnythingOfType("*entities.Copy")).Return(uuid.New(), nil)
+		m.wishlists.On("TakeItemsByISBN", mock.Anything, userID, "9780441013593").Return([]wishlistEntities.WishlistItem{}, nil)
+		m.repo.On("FinishImport", mock.Anything, mock.AnythingOfType("*entities.Import"), mock.Anything).Return(nil)
+
+		err := u.Run(context.Background(), importJob(t, bookImport.ImportID, 1))
+
+		require.NoError(t, err)
+		m.catalog.AssertNumberOfCalls(t, "CreateBook", 3)
+		m.readings.AssertNotCalled(t, "GetReadings", mock.Anything, mock.Anything, mock.Anything)
+		m.readings.AssertNotCalled(t, "SaveReview", mock.Anything, mock.Anything)
+	})
+
+	t.Run("already finished", func(t *testing.T) {
+		u, m := newUseCase(t)
+		bookImport := entities.NewImport(householdID, userID, bookimport.FormatGoodreads, entities.ResolutionSkip)
+		bookImport.Status = entities.StatusDone
+		m.repo.On("GetImportByID", mock.Anything, bookImport.ImportID).Return(bookImport, nil)
+
+		err := u.Run(context.Background(), importJob(t, bookImport.ImportID, 1))
+
+		assert.NoError(t, err)
+		m.catalog.AssertNotCalled(t, "FindBooks", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("last attempt fails the import", func(t *testing.T) {
+		u, m := newUseCase(t)
+		bookImport := entities.NewImport(householdID, userID, bookimport.FormatGoodreads, entities.ResolutionSkip)
+		upload(t, m, bookImport)
+
+		m.repo.On("GetImportByID", mock.Anything, bookImport.ImportID).Return(bookImport, nil)
+		m.households.On("GetMember", mock.Anything, householdID, userID).Return(nil, sql.ErrNoRows)
+		m.catalog.On("FindBooks", mock.Anything, householdID, catalogEntities.BookFilter{}).Return(nil, sql.ErrConnDone)
+		m.repo.On("FailImport", mock.Anything, bookImport.ImportID, sql.ErrConnDone.Error(), mock.Anything).Return(nil)
+
+		err := u.Run(context.Background(), importJob(t, bookImport.ImportID, importAttempts))
+
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		m.repo.AssertCalled(t, "FailImport", mock.Anything, bookImport.ImportID, sql.ErrConnDone.Error(), mock.Anything)
+		_, err = m.store.Stat(context.Background(), bookImport.BlobKey())
+		assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	})
+
+	t.Run("earlier attempt keeps the file for a retry", func(t *testing.T) {
+		u, m := newUseCase(t)
+		bookImport := entities.NewImport(householdID, userID, bookimport.FormatGoodreads, entities.ResolutionSkip)
+		upload(t, m, bookImport)
+
+		m.repo.On("GetImportByID", mock.Anything, bookImport.ImportID).Return(bookImport, nil)
+		m.households.On("GetMember", mock.Anything, householdID, userID).Return(nil, sql.ErrNoRows)
+		m.catalog.On("FindBooks", mock.Anything, householdID, catalogEntities.BookFilter{}).Return(nil, sql.ErrConnDone)
+
+		err := u.Run(context.Background(), importJob(t, bookImport.ImportID, 1))
+
+		assert.ErrorIs(t, err, sql.ErrConnDone)
+		m.repo.AssertNotCalled(t, "FailImport", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		_, err = m.store.Stat(context.Background(), bookImport.BlobKey())
+		assert.NoError(t, err)
+	})
+}
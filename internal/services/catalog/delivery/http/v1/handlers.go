@@ -0,0 +1,346 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/catalog/dtos"
+	"home-library/internal/services/catalog/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) CreateLocation(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.CreateLocationRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	locationID, err := h.u.CreateLocation(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to create location")
+	}
+
+	return c.JSON(http.StatusCreated, dtos.CreateLocationResponse{LocationID: locationID})
+}
+
+func (h *handler) GetLocations(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	locations, err := h.u.GetLocations(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get locations")
+	}
+
+	return c.JSON(http.StatusOK, locations)
+}
+
+func (h *handler) RenameLocation(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	locationID, err := uuid.Parse(c.Param("location_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.RenameLocationRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.RenameLocation(c.Request().Context(), userID, locationID, payload); err != nil {
+		return h.handleError(c, err, "failed to rename location")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) DeleteLocation(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	locationID, err := uuid.Parse(c.Param("location_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.DeleteLocation(c.Request().Context(), userID, locationID); err != nil {
+		return h.handleError(c, err, "failed to delete location")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) CreateBook(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.BookRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	bookID, err := h.u.CreateBook(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to create book")
+	}
+
+	return c.JSON(http.StatusCreated, dtos.CreateBookResponse{BookID: bookID})
+}
+
+func (h *handler) GetBooks(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var request dtos.ListBooksRequest
+	if err := c.Bind(&request); err != nil {
+		log.Error().Err(err).Msg("failed to bind query parameters")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := request.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	books, err := h.u.GetBooks(c.Request().Context(), userID, request)
+	if err != nil {
+		return h.handleError(c, err, "failed to get books")
+	}
+
+	return c.JSON(http.StatusOK, books)
+}
+
+func (h *handler) GetBook(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	bookID, err := uuid.Parse(c.Param("book_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	book, err := h.u.GetBook(c.Request().Context(), userID, bookID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get book")
+	}
+
+	return c.JSON(http.StatusOK, book)
+}
+
+func (h *handler) UpdateBook(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	bookID, err := uuid.Parse(c.Param("book_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.BookRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.UpdateBook(c.Request().Context(), userID, bookID, payload); err != nil {
+		return h.handleError(c, err, "failed to update book")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) DeleteBook(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	bookID, err := uuid.Parse(c.Param("book_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.DeleteBook(c.Request().Context(), userID, bookID); err != nil {
+		return h.handleError(c, err, "failed to delete book")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) AddCopy(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	bookID, err := uuid.Parse(c.Param("book_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.CopyRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	copyID, err := h.u.AddCopy(c.Request().Context(), userID, bookID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to add copy")
+	}
+
+	return c.JSON(http.StatusCreated, dtos.CreateCopyResponse{CopyID: copyID})
+}
+
+func (h *handler) UpdateCopy(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	copyID, err := uuid.Parse(c.Param("copy_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.CopyRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.UpdateCopy(c.Request().Context(), userID, copyID, payload); err != nil {
+		return h.handleError(c, err, "failed to update copy")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) MoveCopy(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	copyID, err := uuid.Parse(c.Param("copy_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.MoveCopyRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := h.u.MoveCopy(c.Request().Context(), userID, copyID, payload); err != nil {
+		return h.handleError(c, err, "failed to move copy")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) DeleteCopy(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	copyID, err := uuid.Parse(c.Param("copy_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.DeleteCopy(c.Request().Context(), userID, copyID); err != nil {
+		return h.handleError(c, err, "failed to delete copy")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Книга не найдена", nil))
+	case errors.Is(err, customErrors.ErrCopyNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Экземпляр не найден", nil))
+	case errors.Is(err, customErrors.ErrLocationNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Место хранения не найдено", nil))
+	case errors.Is(err, customErrors.ErrCoverNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Обложка не найдена", nil))
+	case errors.Is(err, customErrors.ErrLocationExists):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Место хранения с таким названием уже есть", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
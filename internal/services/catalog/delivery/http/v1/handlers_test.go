@@ -0,0 +1,262 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"home-library/internal/services/catalog/dtos"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) CreateLocation(ctx context.Context, userID uuid.UUID, payload dtos.CreateLocationRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) GetLocations(ctx context.Context, userID uuid.UUID) ([]dtos.LocationResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.LocationResponse), args.Error(1)
+}
+
+func (m *MockUseCase) RenameLocation(ctx context.Context, userID uuid.UUID, locationID uuid.UUID, payload dtos.RenameLocationRequest) error {
+	return m.Called(ctx, userID, locationID, payload).Error(0)
+}
+
+func (m *MockUseCase) DeleteLocation(ctx context.Context, userID uuid.UUID, locationID uuid.UUID) error {
+	return m.Called(ctx, userID, locationID).Error(0)
+}
+
+func (m *MockUseCase) CreateBook(ctx context.Context, userID uuid.UUID, payload dtos.BookRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) GetBooks(ctx context.Context, userID uuid.UUID, request dtos.ListBooksRequest) ([]dtos.BookResponse, error) {
+	args := m.Called(ctx, userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.BookResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetBook(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) (*dtos.BookResponse, error) {
+	args := m.Called(ctx, userID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.BookResponse), args.Error(1)
+}
+
+func (m *MockUseCase) UpdateBook(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.BookRequest) error {
+	return m.Called(ctx, userID, bookID, payload).Error(0)
+}
+
+func (m *MockUseCase) DeleteBook(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) error {
+	return m.Called(ctx, userID, bookID).Error(0)
+}
+
+func (m *MockUseCase) AddCopy(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.CopyRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, bookID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) UpdateCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload dtos.CopyRequest) error {
+	return m.Called(ctx, userID, copyID, payload).Error(0)
+}
+
+func (m *MockUseCase) MoveCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload dtos.MoveCopyRequest) error {
+	return m.Called(ctx, userID, copyID, payload).Error(0)
+}
+
+func (m *MockUseCase) DeleteCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID) error {
+	return m.Called(ctx, userID, copyID).Error(0)
+}
+
+func newContext(e *echo.Echo, method string, target string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestCreateBook(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully create book", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, bookID := uuid.New(), uuid.New()
+		payload := dtos.BookRequest{Title: "Солярис", Authors: []string{"Станислав Лем"}, ISBN: "9785170906307"}
+
+		jsonPayload, _ := json.Marshal(payload)
+		c, rec := newContext(e, http.MethodPost, "/books", string(jsonPayload), userID)
+
+		mockUseCase.On("CreateBook", context.Background(), userID, payload).Return(bookID, nil)
+
+		err := handler.CreateBook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response dtos.CreateBookResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, bookID, response.BookID)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("missing title", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		c, rec := newContext(e, http.MethodPost, "/books", `{"authors":["Станислав Лем"]}`, uuid.New())
+
+		err := handler.CreateBook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var response dtos.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.NotEmpty(t, response.ValidationErrors)
+		mockUseCase.AssertNotCalled(t, "CreateBook")
+	})
+
+	t.Run("viewer is forbidden", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		c, rec := newContext(e, http.MethodPost, "/books", `{"title":"Солярис"}`, userID)
+
+		mockUseCase.On("CreateBook", context.Background(), userID, dtos.BookRequest{Title: "Солярис"}).
+			Return(uuid.Nil, customErrors.ErrHouseholdForbidden)
+
+		err := handler.CreateBook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		c, rec := newContext(e, http.MethodPost, "/books", `{"title":"Солярис"}`, uuid.Nil)
+
+		err := handler.CreateBook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUseCase.AssertNotCalled(t, "CreateBook")
+	})
+}
+
+func TestGetBooks(t *testing.T) {
+	e := echo.New()
+
+	t.Run("query parameters are passed on", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+		request := dtos.ListBooksRequest{Query: "лем", Tag: "фантастика", Limit: 10}
+
+		c, rec := newContext(e, http.MethodGet, "/books?q=%D0%BB%D0%B5%D0%BC&tag=%D1%84%D0%B0%D0%BD%D1%82%D0%B0%D1%81%D1%82%D0%B8%D0%BA%D0%B0&limit=10", "", userID)
+
+		mockUseCase.On("GetBooks", context.Background(), userID, request).Return([]dtos.BookResponse{}, nil)
+
+		err := handler.GetBooks(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("invalid location", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		c, rec := newContext(e, http.MethodGet, "/books?location_id=shelf", "", uuid.New())
+
+		err := handler.GetBooks(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "GetBooks")
+	})
+}
+
+func TestAddCopy(t *testing.T) {
+	e := echo.New()
+
+	t.Run("book of another household", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, bookID := uuid.New(), uuid.New()
+
+		c, rec := newContext(e, http.MethodPost, "/", `{"notes":"подарок"}`, userID)
+		c.SetParamNames("book_id")
+		c.SetParamValues(bookID.String())
+
+		mockUseCase.On("AddCopy", context.Background(), userID, bookID, dtos.CopyRequest{Notes: "подарок"}).
+			Return(uuid.Nil, customErrors.ErrBookNotFound)
+
+		err := handler.AddCopy(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid purchase date", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		c, rec := newContext(e, http.MethodPost, "/", `{"purchased_at":"08.03.2024"}`, uuid.New())
+		c.SetParamNames("book_id")
+		c.SetParamValues(uuid.NewString())
+
+		err := handler.AddCopy(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "AddCopy")
+	})
+}
+
+func TestCreateLocation(t *testing.T) {
+	e := echo.New()
+
+	t.Run("name already taken", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		c, rec := newContext(e, http.MethodPost, "/locations", `{"name":"Гостиная"}`, userID)
+
+		mockUseCase.On("CreateLocation", context.Background(), userID, dtos.CreateLocationRequest{Name: "Гостиная"}).
+			Return(uuid.Nil, customErrors.ErrLocationExists)
+
+		err := handler.CreateLocation(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
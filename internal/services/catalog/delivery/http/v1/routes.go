@@ -0,0 +1,19 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) CatalogRoutes(domain *echo.Group) {
+	domain.POST("/locations", h.CreateLocation)
+	domain.GET("/locations", h.GetLocations)
+	domain.PUT("/locations/:location_id", h.RenameLocation)
+	domain.DELETE("/locations/:location_id", h.DeleteLocation)
+	domain.POST("/books", h.CreateBook)
+	domain.GET("/books", h.GetBooks)
+	domain.GET("/books/:book_id", h.GetBook)
+	domain.PUT("/books/:book_id", h.UpdateBook)
+	domain.DELETE("/books/:book_id", h.DeleteBook)
+	domain.POST("/books/:book_id/copies", h.AddCopy)
+	domain.PUT("/copies/:copy_id", h.UpdateCopy)
+	domain.PUT("/copies/:copy_id/location", h.MoveCopy)
+	domain.DELETE("/copies/:copy_id", h.DeleteCopy)
+}
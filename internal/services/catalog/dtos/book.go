@@ -0,0 +1,94 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/catalog/entities"
+	"time"
+)
+
+type BookRequest struct {
+	Title         string     `json:"title" validate:"required,max=255"`
+	Authors       []string   `json:"authors" validate:"max=20,dive,required,max=255"`
+	ISBN          string     `json:"isbn" validate:"omitempty,isbn"`
+	Language      string     `json:"language" validate:"omitempty,bcp47_language_tag"`
+	Publisher     string     `json:"publisher" validate:"max=255"`
+	PublishedYear *int       `json:"published_year" validate:"omitempty,gte=0,lte=9999"`
+	Pages         *int       `json:"pages" validate:"omitempty,gt=0"`
+	Series        string     `json:"series" validate:"max=255"`
+	SeriesIndex   *float64   `json:"series_index" validate:"omitempty,gte=0"`
+	Tags          []string   `json:"tags" validate:"max=50,dive,required,max=100"`
+	Description   string     `json:"description" validate:"max=10000"`
+	CoverID       *uuid.UUID `json:"cover_id"`
+}
+
+func (r *BookRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type CreateBookResponse struct {
+	BookID uuid.UUID `json:"book_id"`
+}
+
+type ListBooksRequest struct {
+	Query      string `query:"q" validate:"max=255"`
+	Author     string `query:"author" validate:"max=255"`
+	Tag        string `query:"tag" validate:"max=100"`
+	LocationID string `query:"location_id" validate:"omitempty,uuid"`
+	Limit      int    `query:"limit" validate:"gte=0,lte=100"`
+	Offset     int    `query:"offset" validate:"gte=0"`
+}
+
+func (r *ListBooksRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type BookResponse struct {
+	BookID        uuid.UUID      `json:"book_id"`
+	Title         string         `json:"title"`
+	Authors       []string       `json:"authors"`
+	ISBN          string         `json:"isbn"`
+	Language      string         `json:"language"`
+	Publisher     string         `json:"publisher"`
+	PublishedYear *int           `json:"published_year"`
+	Pages         *int           `json:"pages"`
+	Series        string         `json:"series"`
+	SeriesIndex   *float64       `json:"series_index"`
+	Tags          []string       `json:"tags"`
+	Description   string         `json:"description"`
+	CoverID       *uuid.UUID     `json:"cover_id"`
+	Copies        []CopyResponse `json:"copies,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+func NewBookResponse(book entities.Book) BookResponse {
+	authors := []string(book.Authors)
+	if authors == nil {
+		authors = []string{}
+	}
+	tags := []string(book.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return BookResponse{
+		BookID:        book.BookID,
+		Title:         book.Title,
+		Authors:       authors,
+		ISBN:          book.ISBN,
+		Language:      book.Language,
+		Publisher:     book.Publisher,
+		PublishedYear: book.PublishedYear,
+		Pages:         book.Pages,
+		Series:        book.Series,
+		SeriesIndex:   book.SeriesIndex,
+		Tags:          tags,
+		Description:   book.Description,
+		CoverID:       book.CoverID,
+		CreatedAt:     book.CreatedAt,
+		UpdatedAt:     book.UpdatedAt,
+	}
+}
@@ -0,0 +1,60 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/catalog/entities"
+)
+
+// DateLayout is the format of purchase dates in requests and responses.
+const DateLayout = "2006-01-02"
+
+type CopyRequest struct {
+	LocationID    *uuid.UUID `json:"location_id"`
+	Notes         string     `json:"notes" validate:"max=2000"`
+	PurchasePrice *float64   `json:"purchase_price" validate:"omitempty,gte=0,lt=100000000"`
+	PurchasedAt   string     `json:"purchased_at" validate:"omitempty,datetime=2006-01-02"`
+	PurchaseStore string     `json:"purchase_store" validate:"max=255"`
+}
+
+func (r *CopyRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// MoveCopyRequest puts a copy in another location, or in none when the
+// location is null.
+type MoveCopyRequest struct {
+	LocationID *uuid.UUID `json:"location_id"`
+}
+
+type CreateCopyResponse struct {
+	CopyID uuid.UUID `json:"copy_id"`
+}
+
+type CopyResponse struct {
+	CopyID        uuid.UUID  `json:"copy_id"`
+	BookID        uuid.UUID  `json:"book_id"`
+	ShortID       string     `json:"short_id"`
+	LocationID    *uuid.UUID `json:"location_id"`
+	Notes         string     `json:"notes,omitempty"`
+	PurchasePrice *float64   `json:"purchase_price,omitempty"`
+	PurchasedAt   string     `json:"purchased_at,omitempty"`
+	PurchaseStore string     `json:"purchase_store,omitempty"`
+}
+
+func NewCopyResponse(copy entities.Copy) CopyResponse {
+	response := CopyResponse{
+		CopyID:        copy.CopyID,
+		BookID:        copy.BookID,
+		ShortID:       copy.ShortID,
+		LocationID:    copy.LocationID,
+		Notes:         copy.Notes,
+		PurchasePrice: copy.PurchasePrice,
+		PurchaseStore: copy.PurchaseStore,
+	}
+	if copy.PurchasedAt != nil {
+		response.PurchasedAt = copy.PurchasedAt.Format(DateLayout)
+	}
+	return response
+}
@@ -0,0 +1,43 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/catalog/entities"
+)
+
+type CreateLocationRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+func (r *CreateLocationRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type CreateLocationResponse struct {
+	LocationID uuid.UUID `json:"location_id"`
+}
+
+type RenameLocationRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+func (r *RenameLocationRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type LocationResponse struct {
+	LocationID uuid.UUID `json:"location_id"`
+	ShortID    string    `json:"short_id"`
+	Name       string    `json:"name"`
+}
+
+func NewLocationResponse(location entities.Location) LocationResponse {
+	return LocationResponse{
+		LocationID: location.LocationID,
+		ShortID:    location.ShortID,
+		Name:       location.Name,
+	}
+}
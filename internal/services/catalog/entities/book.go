@@ -0,0 +1,53 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Book is a title in the household's catalog. The household may own any
+// number of copies of it, including none.
+type Book struct {
+	BookID        uuid.UUID      `db:"book_id"`
+	HouseholdID   uuid.UUID      `db:"household_id"`
+	Title         string         `db:"title"`
+	Authors       pq.StringArray `db:"authors"`
+	ISBN          string         `db:"isbn"`
+	Language      string         `db:"language"`
+	Publisher     string         `db:"publisher"`
+	PublishedYear *int           `db:"published_year"`
+	Pages         *int           `db:"pages"`
+	Series        string         `db:"series"`
+	SeriesIndex   *float64       `db:"series_index"`
+	Tags          pq.StringArray `db:"tags"`
+	Description   string         `db:"description"`
+	CoverID       *uuid.UUID     `db:"cover_id"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+func NewBook(householdID uuid.UUID) *Book {
+	now := time.Now()
+	return &Book{
+		BookID:      uuid.New(),
+		HouseholdID: householdID,
+		Authors:     pq.StringArray{},
+		Tags:        pq.StringArray{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// BookFilter narrows down a listing of books. Zero fields do not filter.
+type BookFilter struct {
+	Author string
+	Tag    string
+	// LocationID matches books with at least one copy in the location.
+	LocationID *uuid.UUID
+	// Query matches a substring of the title or of an author's name.
+	Query  string
+	Limit  int
+	Offset int
+}
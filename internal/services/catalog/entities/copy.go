@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Copy is a physical copy of a book.
+type Copy struct {
+	CopyID      uuid.UUID `db:"copy_id"`
+	BookID      uuid.UUID `db:"book_id"`
+	HouseholdID uuid.UUID `db:"household_id"`
+	// ShortID is printed on the copy's label; the repository draws it when
+	// the copy is created.
+	ShortID       string     `db:"short_id"`
+	LocationID    *uuid.UUID `db:"location_id"`
+	Notes         string     `db:"notes"`
+	PurchasePrice *float64   `db:"purchase_price"`
+	PurchasedAt   *time.Time `db:"purchased_at"`
+	PurchaseStore string     `db:"purchase_store"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+}
+
+func NewCopy(householdID uuid.UUID, bookID uuid.UUID) *Copy {
+	now := time.Now()
+	return &Copy{
+		CopyID:      uuid.New(),
+		BookID:      bookID,
+		HouseholdID: householdID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// TakePurchase fills in the parts of the purchase the copy does not have
+// yet, such as from the wishlist item the copy was bought for.
+func (c *Copy) TakePurchase(price *float64, purchasedAt *time.Time, store string) {
+	if c.PurchasePrice == nil {
+		c.PurchasePrice = price
+	}
+	if c.PurchasedAt == nil {
+		c.PurchasedAt = purchasedAt
+	}
+	if c.PurchaseStore == "" {
+		c.PurchaseStore = store
+	}
+}
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Location is a place copies are kept in, such as a shelf or a box.
+type Location struct {
+	LocationID  uuid.UUID `db:"location_id"`
+	HouseholdID uuid.UUID `db:"household_id"`
+	// ShortID is printed on the shelf label; the repository draws it when
+	// the location is created.
+	ShortID   string    `db:"short_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func NewLocation(householdID uuid.UUID, name string) *Location {
+	now := time.Now()
+	return &Location{
+		LocationID:  uuid.New(),
+		HouseholdID: householdID,
+		Name:        name,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
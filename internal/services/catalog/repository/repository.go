@@ -0,0 +1,419 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"fmt"
+	"home-library/internal/services/catalog/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/events"
+	"home-library/pkg/shortid"
+	"home-library/pkg/storage"
+	"home-library/pkg/transaction"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository methods take the caller's household ID, and entities carry it,
+// so a member can never read or change another household's catalog even if
+// handed a foreign book, copy or location ID.
+type Repository interface {
+	CreateLocation(ctx context.Context, location *entities.Location) (uuid.UUID, error)
+	GetLocations(ctx context.Context, householdID uuid.UUID) ([]entities.Location, error)
+	GetLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*entities.Location, error)
+	RenameLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID, name string) error
+	// DeleteLocation leaves the copies kept in the location without one.
+	DeleteLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) error
+
+	// CreateBook records a book.added event naming userID, the member who
+	// added the book.
+	CreateBook(ctx context.Context, userID uuid.UUID, book *entities.Book) (uuid.UUID, error)
+	GetBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) (*entities.Book, error)
+	FindBooks(ctx context.Context, householdID uuid.UUID, filter entities.BookFilter) ([]entities.Book, error)
+	UpdateBook(ctx context.Context, book *entities.Book) error
+	// HasCover tells whether the household may give its books the cover: a
+	// member uploaded it, or a book of the household has it already.
+	HasCover(ctx context.Context, householdID uuid.UUID, coverID uuid.UUID) (bool, error)
+	// DeleteBook deletes the book with its copies.
+	DeleteBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) error
+
+	CreateCopy(ctx context.Context, copy *entities.Copy) (uuid.UUID, error)
+	GetCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) (*entities.Copy, error)
+	GetCopiesByBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) ([]entities.Copy, error)
+	UpdateCopy(ctx context.Context, copy *entities.Copy) error
+	// MoveCopy changes only the location of the copy.
+	MoveCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID, locationID *uuid.UUID) error
+	DeleteCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) error
+}
+
+// constraints translates violations of the household-scoped foreign keys, so
+// a book or location of another household reads as a missing one.
+var constraints = storage.Constraints{
+	"locations_household_id_name_key": errors.ErrLocationExists,
+	"copies_book_fkey":                errors.ErrBookNotFound,
+	"copies_location_fkey":            errors.ErrLocationNotFound,
+	"books_cover_fkey":                errors.ErrCoverNotFound,
+}
+
+// shortIDAttempts bounds the draws of a short ID for a new copy or location;
+// with 40 random bits even a second one is rare.
+const shortIDAttempts = 5
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateLocation(ctx context.Context, location *entities.Location) (uuid.UUID, error) {
+	// As with copies, a taken short ID skips the insert and another is
+	// drawn. A taken name still fails.
+	query := `
+		INSERT INTO locations (location_id, household_id, short_id, name, created_at, updated_at)
+		VALUES (:location_id, :household_id, :short_id, :name, :created_at, :updated_at)
+		ON CONFLICT (household_id, short_id) DO NOTHING
+	`
+
+	for attempt := 0; attempt < shortIDAttempts; attempt++ {
+		id, err := shortid.New()
+		if err != nil {
+			return uuid.Nil, err
+		}
+		location.ShortID = id
+
+		result, err := r.db.NamedExecContext(ctx, query, location)
+		if err != nil {
+			return uuid.Nil, constraints.Map(err)
+		}
+		if err := requireAffected(result); err == nil {
+			return location.LocationID, nil
+		} else if !stdErrors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, err
+		}
+	}
+
+	return uuid.Nil, fmt.Errorf("no free short id for location %s", location.LocationID)
+}
+
+func (r *repository) GetLocations(ctx context.Context, householdID uuid.UUID) ([]entities.Location, error) {
+	locations := make([]entities.Location, 0)
+	query := `
+		SELECT * FROM locations
+		WHERE household_id = $1
+		ORDER BY name
+	`
+
+	err := r.db.SelectContext(ctx, &locations, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+func (r *repository) GetLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*entities.Location, error) {
+	var location entities.Location
+	query := `
+		SELECT * FROM locations
+		WHERE household_id = $1 AND location_id = $2
+	`
+
+	err := r.db.GetContext(ctx, &location, query, householdID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &location, nil
+}
+
+func (r *repository) RenameLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID, name string) error {
+	query := `
+		UPDATE locations
+		SET name = $3, updated_at = NOW()
+		WHERE household_id = $1 AND location_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, locationID, name)
+	if err != nil {
+		return constraints.Map(err)
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) DeleteLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE copies
+			SET location_id = NULL, updated_at = NOW()
+			WHERE household_id = $1 AND location_id = $2
+		`
+		if _, err := tx.ExecContext(ctx, query, householdID, locationID); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM locations WHERE household_id = $1 AND location_id = $2`, householdID, locationID)
+		if err != nil {
+			return err
+		}
+
+		return requireAffected(result)
+	})
+}
+
+func (r *repository) CreateBook(ctx context.Context, userID uuid.UUID, book *entities.Book) (uuid.UUID, error) {
+	query := `
+		INSERT INTO books (
+			book_id, household_id, title, authors, isbn, language, publisher,
+			published_year, pages, series, series_index, tags, description,
+			cover_id, created_at, updated_at
+		) VALUES (
+			:book_id, :household_id, :title, :authors, :isbn, :language, :publisher,
+			:published_year, :pages, :series, :series_index, :tags, :description,
+			:cover_id, :created_at, :updated_at
+		)
+	`
+
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, query, book); err != nil {
+			return constraints.Map(err)
+		}
+
+		return events.Record(ctx, tx, events.TypeBookAdded, userID, events.BookAdded{
+			BookID:  book.BookID,
+			Title:   book.Title,
+			Authors: book.Authors,
+		})
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return book.BookID, nil
+}
+
+func (r *repository) GetBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) (*entities.Book, error) {
+	var book entities.Book
+	query := `
+		SELECT * FROM books
+		WHERE household_id = $1 AND book_id = $2
+	`
+
+	err := r.db.GetContext(ctx, &book, query, householdID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+func (r *repository) FindBooks(ctx context.Context, householdID uuid.UUID, filter entities.BookFilter) ([]entities.Book, error) {
+	books := make([]entities.Book, 0)
+	conditions := []string{"b.household_id = $1"}
+	args := []interface{}{householdID}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.Author != "" {
+		conditions = append(conditions, arg(filter.Author)+" = ANY(b.authors)")
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, arg(filter.Tag)+" = ANY(b.tags)")
+	}
+	if filter.LocationID != nil {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM copies c WHERE c.book_id = b.book_id AND c.location_id = "+arg(*filter.LocationID)+")")
+	}
+	if filter.Query != "" {
+		pattern := arg("%" + likeEscaper.Replace(filter.Query) + "%")
+		conditions = append(conditions, "(b.title ILIKE "+pattern+" OR array_to_string(b.authors, ' ') ILIKE "+pattern+")")
+	}
+
+	query := `
+		SELECT b.* FROM books b
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY b.title, b.created_at`
+	if filter.Limit > 0 {
+		query += " LIMIT " + arg(filter.Limit) + " OFFSET " + arg(filter.Offset)
+	}
+
+	err := r.db.SelectContext(ctx, &books, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *repository) UpdateBook(ctx context.Context, book *entities.Book) error {
+	query := `
+		UPDATE books
+		SET title = :title, authors = :authors, isbn = :isbn, language = :language,
+			publisher = :publisher, published_year = :published_year, pages = :pages,
+			series = :series, series_index = :series_index, tags = :tags,
+			description = :description, cover_id = :cover_id, updated_at = :updated_at
+		WHERE household_id = :household_id AND book_id = :book_id
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, book)
+	if err != nil {
+		return constraints.Map(err)
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) HasCover(ctx context.Context, householdID uuid.UUID, coverID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM covers c
+			JOIN household_members m ON m.user_id = c.uploaded_by
+			WHERE c.cover_id = $2 AND m.household_id = $1
+		) OR EXISTS (
+			SELECT 1 FROM books WHERE household_id = $1 AND cover_id = $2
+		)
+	`
+
+	err := r.db.GetContext(ctx, &exists, query, householdID, coverID)
+	return exists, err
+}
+
+func (r *repository) DeleteBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM books WHERE household_id = $1 AND book_id = $2`, householdID, bookID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) CreateCopy(ctx context.Context, copy *entities.Copy) (uuid.UUID, error) {
+	// A short ID already taken in the household skips the insert, and the
+	// copy is tried again with another one.
+	query := `
+		INSERT INTO copies (
+			copy_id, book_id, household_id, short_id, location_id, notes,
+			purchase_price, purchased_at, purchase_store, created_at, updated_at
+		) VALUES (
+			:copy_id, :book_id, :household_id, :short_id, :location_id, :notes,
+			:purchase_price, :purchased_at, :purchase_store, :created_at, :updated_at
+		)
+		ON CONFLICT (household_id, short_id) DO NOTHING
+	`
+
+	for attempt := 0; attempt < shortIDAttempts; attempt++ {
+		id, err := shortid.New()
+		if err != nil {
+			return uuid.Nil, err
+		}
+		copy.ShortID = id
+
+		result, err := r.db.NamedExecContext(ctx, query, copy)
+		if err != nil {
+			return uuid.Nil, constraints.Map(err)
+		}
+		if err := requireAffected(result); err == nil {
+			return copy.CopyID, nil
+		} else if !stdErrors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, err
+		}
+	}
+
+	return uuid.Nil, fmt.Errorf("no free short id for copy %s", copy.CopyID)
+}
+
+func (r *repository) GetCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) (*entities.Copy, error) {
+	var copy entities.Copy
+	query := `
+		SELECT * FROM copies
+		WHERE household_id = $1 AND copy_id = $2
+	`
+
+	err := r.db.GetContext(ctx, &copy, query, householdID, copyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &copy, nil
+}
+
+func (r *repository) GetCopiesByBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) ([]entities.Copy, error) {
+	copies := make([]entities.Copy, 0)
+	query := `
+		SELECT * FROM copies
+		WHERE household_id = $1 AND book_id = $2
+		ORDER BY created_at
+	`
+
+	err := r.db.SelectContext(ctx, &copies, query, householdID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return copies, nil
+}
+
+func (r *repository) UpdateCopy(ctx context.Context, copy *entities.Copy) error {
+	query := `
+		UPDATE copies
+		SET location_id = :location_id, notes = :notes, purchase_price = :purchase_price,
+			purchased_at = :purchased_at, purchase_store = :purchase_store, updated_at = :updated_at
+		WHERE household_id = :household_id AND copy_id = :copy_id
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, copy)
+	if err != nil {
+		return constraints.Map(err)
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) MoveCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID, locationID *uuid.UUID) error {
+	query := `
+		UPDATE copies
+		SET location_id = $3, updated_at = NOW()
+		WHERE household_id = $1 AND copy_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, copyID, locationID)
+	if err != nil {
+		return constraints.Map(err)
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) DeleteCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM copies WHERE household_id = $1 AND copy_id = $2`, householdID, copyID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.db.InTx(ctx, nil, fn)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
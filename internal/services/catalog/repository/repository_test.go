@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/catalog/entities"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/shortid"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestCreateLocation(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("name taken in the household", func(t *testing.T) {
+		location := entities.NewLocation(uuid.New(), "Living room")
+
+		mock.ExpectExec("INSERT INTO locations").
+			WithArgs(location.LocationID, location.HouseholdID, sqlmock.AnyArg(), location.Name, location.CreatedAt, location.UpdatedAt).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "locations_household_id_name_key"})
+
+		id, err := repo.CreateLocation(context.Background(), location)
+
+		assert.ErrorIs(t, err, customErrors.ErrLocationExists)
+		assert.Equal(t, uuid.Nil, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("draws another short ID when one is taken", func(t *testing.T) {
+		location := entities.NewLocation(uuid.New(), "Living room")
+
+		mock.ExpectExec(`INSERT INTO locations .+ ON CONFLICT \(household_id, short_id\) DO NOTHING`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO locations`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		id, err := repo.CreateLocation(context.Background(), location)
+
+		assert.NoError(t, err)
+		assert.Equal(t, location.LocationID, id)
+		assert.Len(t, location.ShortID, shortid.Length)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteLocation(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("copies are moved out before the location is deleted", func(t *testing.T) {
+		householdID, locationID := uuid.New(), uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE copies").
+			WithArgs(householdID, locationID).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec("DELETE FROM locations").
+			WithArgs(householdID, locationID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.DeleteLocation(context.Background(), householdID, locationID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("location of another household is not deleted", func(t *testing.T) {
+		householdID, locationID := uuid.New(), uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE copies").
+			WithArgs(householdID, locationID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM locations").
+			WithArgs(householdID, locationID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.DeleteLocation(context.Background(), householdID, locationID)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateBook(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("book is recorded as added by the member", func(t *testing.T) {
+		userID := uuid.New()
+		book := entities.NewBook(uuid.New())
+		book.Title = "Пикник на обочине"
+		book.Authors = pq.StringArray{"Аркадий Стругацкий", "Борис Стругацкий"}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO books").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox_events").
+			WithArgs(sqlmock.AnyArg(), "book.added", userID,
+				`{"book_id":"`+book.BookID.String()+`","title":"Пикник на обочине","authors":["Аркадий Стругацкий","Борис Стругацкий"]}`,
+				sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		id, err := repo.CreateBook(context.Background(), userID, book)
+
+		assert.NoError(t, err)
+		assert.Equal(t, book.BookID, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHasCover(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	householdID, coverID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`JOIN household_members m ON m.user_id = c.uploaded_by\s+WHERE c.cover_id = \$2 AND m.household_id = \$1\s+\) OR EXISTS \(\s+SELECT 1 FROM books WHERE household_id = \$1 AND cover_id = \$2`).
+		WithArgs(householdID, coverID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	ok, err := repo.HasCover(context.Background(), householdID, coverID)
+
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindBooks(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	columns := []string{"book_id", "household_id", "title", "authors", "isbn", "language", "publisher", "published_year",
+		"pages", "series", "series_index", "tags", "description", "cover_id", "created_at", "updated_at"}
+
+	t.Run("filters are combined with the household", func(t *testing.T) {
+		householdID, locationID, now := uuid.New(), uuid.New(), time.Now()
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), householdID, "Пикник на обочине", "{\"Аркадий Стругацкий\",\"Борис Стругацкий\"}", "", "ru", "",
+				1972, 224, "", nil, "{}", "", nil, now, now)
+
+		mock.ExpectQuery(`WHERE b.household_id = \$1 AND \$2 = ANY\(b.authors\) AND EXISTS \(.+c.location_id = \$3\) AND \(b.title ILIKE \$4 .+\) ORDER BY b.title, b.created_at LIMIT \$5 OFFSET \$6`).
+			WithArgs(householdID, "Борис Стругацкий", locationID, `%50\%%`, 20, 40).
+			WillReturnRows(rows)
+
+		books, err := repo.FindBooks(context.Background(), householdID, entities.BookFilter{
+			Author:     "Борис Стругацкий",
+			LocationID: &locationID,
+			Query:      "50%",
+			Limit:      20,
+			Offset:     40,
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, books, 1)
+		assert.Equal(t, []string{"Аркадий Стругацкий", "Борис Стругацкий"}, []string(books[0].Authors))
+		assert.Equal(t, 224, *books[0].Pages)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateCopy(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	tests := []struct {
+		name       string
+		constraint string
+		err        error
+	}{
+		{"book of another household", "copies_book_fkey", customErrors.ErrBookNotFound},
+		{"location of another household", "copies_location_fkey", customErrors.ErrLocationNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copy := entities.NewCopy(uuid.New(), uuid.New())
+
+			mock.ExpectExec("INSERT INTO copies").
+				WillReturnError(&pq.Error{Code: "23503", Constraint: tt.constraint})
+
+			id, err := repo.CreateCopy(context.Background(), copy)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, uuid.Nil, id)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateCopyShortID(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("draws another short ID when one is taken", func(t *testing.T) {
+		copy := entities.NewCopy(uuid.New(), uuid.New())
+
+		mock.ExpectExec(`INSERT INTO copies .+ ON CONFLICT \(household_id, short_id\) DO NOTHING`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO copies`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		id, err := repo.CreateCopy(context.Background(), copy)
+
+		assert.NoError(t, err)
+		assert.Equal(t, copy.CopyID, id)
+		assert.Len(t, copy.ShortID, shortid.Length)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteBook(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("book of another household is not deleted", func(t *testing.T) {
+		householdID, bookID := uuid.New(), uuid.New()
+
+		mock.ExpectExec("DELETE FROM books").
+			WithArgs(householdID, bookID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteBook(context.Background(), householdID, bookID)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
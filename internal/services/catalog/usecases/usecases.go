@@ -0,0 +1,452 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/catalog/dtos"
+	"home-library/internal/services/catalog/entities"
+	"home-library/internal/services/catalog/repository"
+	coverUseCases "home-library/internal/services/cover/usecases"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	wishlistRepository "home-library/internal/services/wishlist/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/isbn"
+	"home-library/pkg/transaction"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultLimit is the page size of the book listing when the request does not
+// set one.
+const defaultLimit = 50
+
+type UseCase interface {
+	CreateLocation(ctx context.Context, userID uuid.UUID, payload dtos.CreateLocationRequest) (locationID uuid.UUID, err error)
+	GetLocations(ctx context.Context, userID uuid.UUID) ([]dtos.LocationResponse, error)
+	RenameLocation(ctx context.Context, userID uuid.UUID, locationID uuid.UUID, payload dtos.RenameLocationRequest) error
+	DeleteLocation(ctx context.Context, userID uuid.UUID, locationID uuid.UUID) error
+
+	// CreateBook takes the book off the wishlists of the household. A book
+	// that was wished for has been bought, so it gets a copy with the
+	// purchase recorded on the wishlist.
+	CreateBook(ctx context.Context, userID uuid.UUID, payload dtos.BookRequest) (bookID uuid.UUID, err error)
+	GetBooks(ctx context.Context, userID uuid.UUID, request dtos.ListBooksRequest) ([]dtos.BookResponse, error)
+	// GetBook returns the book along with its copies.
+	GetBook(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) (*dtos.BookResponse, error)
+	UpdateBook(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.BookRequest) error
+	DeleteBook(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) error
+
+	// AddCopy takes the book off the wishlists of the household too; the
+	// copy gets the parts of the wishlist purchase the request leaves out.
+	AddCopy(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.CopyRequest) (copyID uuid.UUID, err error)
+	UpdateCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload dtos.CopyRequest) error
+	MoveCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload dtos.MoveCopyRequest) error
+	DeleteCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID) error
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	covers     coverUseCases.UseCase
+	wishlists  wishlistRepository.Repository
+	tx         transaction.Transactor
+}
+
+func NewUseCase(
+	r repository.Repository,
+	households householdRepository.Repository,
+	covers coverUseCases.UseCase,
+	wishlists wishlistRepository.Repository,
+	tx transaction.Transactor,
+) UseCase {
+	return &useCase{r: r, households: households, covers: covers, wishlists: wishlists, tx: tx}
+}
+
+func (u *useCase) CreateLocation(ctx context.Context, userID uuid.UUID, payload dtos.CreateLocationRequest) (locationID uuid.UUID, err error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return u.r.CreateLocation(ctx, entities.NewLocation(member.HouseholdID, strings.TrimSpace(payload.Name)))
+}
+
+func (u *useCase) GetLocations(ctx context.Context, userID uuid.UUID) ([]dtos.LocationResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := u.r.GetLocations(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.LocationResponse, len(locations))
+	for i, location := range locations {
+		response[i] = dtos.NewLocationResponse(location)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) RenameLocation(ctx context.Context, userID uuid.UUID, locationID uuid.UUID, payload dtos.RenameLocationRequest) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = u.r.RenameLocation(ctx, member.HouseholdID, locationID, strings.TrimSpace(payload.Name))
+	return mapNoRows(err, errors.ErrLocationNotFound)
+}
+
+func (u *useCase) DeleteLocation(ctx context.Context, userID uuid.UUID, locationID uuid.UUID) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.DeleteLocation(ctx, member.HouseholdID, locationID), errors.ErrLocationNotFound)
+}
+
+func (u *useCase) CreateBook(ctx context.Context, userID uuid.UUID, payload dtos.BookRequest) (bookID uuid.UUID, err error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	book := entities.NewBook(member.HouseholdID)
+	applyBookRequest(book, payload)
+	if err := u.checkCover(ctx, member.HouseholdID, book.CoverID); err != nil {
+		return uuid.Nil, err
+	}
+
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		if _, err := u.r.CreateBook(ctx, userID, book); err != nil {
+			return err
+		}
+
+		copy := entities.NewCopy(member.HouseholdID, book.BookID)
+		wished, err := u.takeWished(ctx, userID, book.ISBN, copy)
+		if err != nil || !wished {
+			return err
+		}
+		_, err = u.r.CreateCopy(ctx, copy)
+		return err
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return book.BookID, nil
+}
+
+func (u *useCase) GetBooks(ctx context.Context, userID uuid.UUID, request dtos.ListBooksRequest) ([]dtos.BookResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := entities.BookFilter{
+		Author: strings.TrimSpace(request.Author),
+		Tag:    strings.TrimSpace(request.Tag),
+		Query:  strings.TrimSpace(request.Query),
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultLimit
+	}
+	if request.LocationID != "" {
+		locationID, err := uuid.Parse(request.LocationID)
+		if err != nil {
+			return nil, errors.ErrLocationNotFound
+		}
+		filter.LocationID = &locationID
+	}
+
+	books, err := u.r.FindBooks(ctx, member.HouseholdID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.BookResponse, len(books))
+	for i, book := range books {
+		response[i] = dtos.NewBookResponse(book)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) GetBook(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) (*dtos.BookResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := u.r.GetBook(ctx, member.HouseholdID, bookID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrBookNotFound)
+	}
+
+	copies, err := u.r.GetCopiesByBook(ctx, member.HouseholdID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dtos.NewBookResponse(*book)
+	response.Copies = make([]dtos.CopyResponse, len(copies))
+	for i, copy := range copies {
+		response.Copies[i] = dtos.NewCopyResponse(copy)
+	}
+
+	return &response, nil
+}
+
+func (u *useCase) UpdateBook(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.BookRequest) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	previous, err := u.r.GetBook(ctx, member.HouseholdID, bookID)
+	if err != nil {
+		return mapNoRows(err, errors.ErrBookNotFound)
+	}
+
+	book := &entities.Book{
+		BookID:      bookID,
+		HouseholdID: member.HouseholdID,
+		UpdatedAt:   time.Now(),
+	}
+	applyBookRequest(book, payload)
+	if book.CoverID != nil && (previous.CoverID == nil || *book.CoverID != *previous.CoverID) {
+		if err := u.checkCover(ctx, member.HouseholdID, book.CoverID); err != nil {
+			return err
+		}
+	}
+
+	if err := u.r.UpdateBook(ctx, book); err != nil {
+		return mapNoRows(err, errors.ErrBookNotFound)
+	}
+
+	if previous.CoverID != nil && (book.CoverID == nil || *book.CoverID != *previous.CoverID) {
+		u.deleteCover(ctx, *previous.CoverID)
+	}
+
+	return nil
+}
+
+func (u *useCase) DeleteBook(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	book, err := u.r.GetBook(ctx, member.HouseholdID, bookID)
+	if err != nil {
+		return mapNoRows(err, errors.ErrBookNotFound)
+	}
+
+	if err := u.r.DeleteBook(ctx, member.HouseholdID, bookID); err != nil {
+		return mapNoRows(err, errors.ErrBookNotFound)
+	}
+
+	if book.CoverID != nil {
+		u.deleteCover(ctx, *book.CoverID)
+	}
+
+	return nil
+}
+
+func (u *useCase) AddCopy(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.CopyRequest) (copyID uuid.UUID, err error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	copy := entities.NewCopy(member.HouseholdID, bookID)
+	applyCopyRequest(copy, payload)
+
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		book, err := u.r.GetBook(ctx, member.HouseholdID, bookID)
+		if err != nil {
+			return mapNoRows(err, errors.ErrBookNotFound)
+		}
+		if _, err := u.takeWished(ctx, userID, book.ISBN, copy); err != nil {
+			return err
+		}
+
+		// The foreign keys reject a location of another household.
+		_, err = u.r.CreateCopy(ctx, copy)
+		return err
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return copy.CopyID, nil
+}
+
+func (u *useCase) UpdateCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload dtos.CopyRequest) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	copy := &entities.Copy{
+		CopyID:      copyID,
+		HouseholdID: member.HouseholdID,
+		UpdatedAt:   time.Now(),
+	}
+	applyCopyRequest(copy, payload)
+
+	return mapNoRows(u.r.UpdateCopy(ctx, copy), errors.ErrCopyNotFound)
+}
+
+func (u *useCase) MoveCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload dtos.MoveCopyRequest) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.MoveCopy(ctx, member.HouseholdID, copyID, payload.LocationID), errors.ErrCopyNotFound)
+}
+
+func (u *useCase) DeleteCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.DeleteCopy(ctx, member.HouseholdID, copyID), errors.ErrCopyNotFound)
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+// editor returns the caller's membership if their role may change the
+// catalog.
+func (u *useCase) editor(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanEditLibrary() {
+		return nil, errors.ErrHouseholdForbidden
+	}
+	return member, nil
+}
+
+// takeWished takes the book with the ISBN off the wishlists of the
+// household and gives the copy bought for it the wishlist purchase. It tells
+// whether the book was wished for.
+func (u *useCase) takeWished(ctx context.Context, userID uuid.UUID, isbn string, copy *entities.Copy) (bool, error) {
+	if isbn == "" {
+		return false, nil
+	}
+
+	items, err := u.wishlists.TakeItemsByISBN(ctx, userID, isbn)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		copy.TakePurchase(item.PurchasePrice, item.PurchasedAt, item.PurchaseStore)
+	}
+
+	return len(items) > 0, nil
+}
+
+// checkCover makes sure a book is given a cover of its own household, not
+// one whose ID was seen elsewhere.
+func (u *useCase) checkCover(ctx context.Context, householdID uuid.UUID, coverID *uuid.UUID) error {
+	if coverID == nil {
+		return nil
+	}
+
+	ok, err := u.r.HasCover(ctx, householdID, *coverID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.ErrCoverNotFound
+	}
+	return nil
+}
+
+// deleteCover releases the cover a book had; the cover service keeps it
+// while an ebook file or another book still uses it. The book change has
+// been made already, so a failure only leaves an orphaned image behind.
+func (u *useCase) deleteCover(ctx context.Context, coverID uuid.UUID) {
+	if err := u.covers.DeleteCover(ctx, coverID); err != nil {
+		log.Error().Err(err).Str("cover_id", coverID.String()).Msg("failed to delete book cover")
+	}
+}
+
+func applyBookRequest(book *entities.Book, payload dtos.BookRequest) {
+	book.Title = strings.TrimSpace(payload.Title)
+	book.Authors = make([]string, 0, len(payload.Authors))
+	for _, author := range payload.Authors {
+		book.Authors = append(book.Authors, strings.TrimSpace(author))
+	}
+	book.ISBN = isbn.Normalize(payload.ISBN)
+	book.Language = payload.Language
+	book.Publisher = strings.TrimSpace(payload.Publisher)
+	book.PublishedYear = payload.PublishedYear
+	book.Pages = payload.Pages
+	book.Series = strings.TrimSpace(payload.Series)
+	book.SeriesIndex = payload.SeriesIndex
+	book.Tags = cleanTags(payload.Tags)
+	book.Description = strings.TrimSpace(payload.Description)
+	book.CoverID = payload.CoverID
+}
+
+func applyCopyRequest(copy *entities.Copy, payload dtos.CopyRequest) {
+	copy.LocationID = payload.LocationID
+	copy.Notes = strings.TrimSpace(payload.Notes)
+	copy.PurchasePrice = payload.PurchasePrice
+	copy.PurchaseStore = strings.TrimSpace(payload.PurchaseStore)
+	// The date has been validated along with the request.
+	if purchasedAt, err := time.Parse(dtos.DateLayout, payload.PurchasedAt); err == nil {
+		copy.PurchasedAt = &purchasedAt
+	}
+}
+
+func cleanTags(tags []string) []string {
+	cleaned := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = truncate(strings.TrimSpace(tag), 100)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		cleaned = append(cleaned, tag)
+	}
+	return cleaned
+}
+
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
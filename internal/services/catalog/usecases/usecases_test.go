@@ -0,0 +1,630 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/catalog/dtos"
+	"home-library/internal/services/catalog/entities"
+	coverDtos "home-library/internal/services/cover/dtos"
+	coverEntities "home-library/internal/services/cover/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	wishlistEntities "home-library/internal/services/wishlist/entities"
+	"home-library/pkg/blobstore"
+	"home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateLocation(ctx context.Context, location *entities.Location) (uuid.UUID, error) {
+	args := m.Called(ctx, location)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetLocations(ctx context.Context, householdID uuid.UUID) ([]entities.Location, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Location), args.Error(1)
+}
+
+func (m *MockRepository) GetLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*entities.Location, error) {
+	args := m.Called(ctx, householdID, locationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Location), args.Error(1)
+}
+
+func (m *MockRepository) RenameLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, locationID, name).Error(0)
+}
+
+func (m *MockRepository) DeleteLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) error {
+	return m.Called(ctx, householdID, locationID).Error(0)
+}
+
+func (m *MockRepository) CreateBook(ctx context.Context, userID uuid.UUID, book *entities.Book) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, book)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) (*entities.Book, error) {
+	args := m.Called(ctx, householdID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Book), args.Error(1)
+}
+
+func (m *MockRepository) FindBooks(ctx context.Context, householdID uuid.UUID, filter entities.BookFilter) ([]entities.Book, error) {
+	args := m.Called(ctx, householdID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Book), args.Error(1)
+}
+
+func (m *MockRepository) UpdateBook(ctx context.Context, book *entities.Book) error {
+	return m.Called(ctx, book).Error(0)
+}
+
+func (m *MockRepository) HasCover(ctx context.Context, householdID uuid.UUID, coverID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, householdID, coverID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) DeleteBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) error {
+	return m.Called(ctx, householdID, bookID).Error(0)
+}
+
+func (m *MockRepository) CreateCopy(ctx context.Context, copy *entities.Copy) (uuid.UUID, error) {
+	args := m.Called(ctx, copy)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) (*entities.Copy, error) {
+	args := m.Called(ctx, householdID, copyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Copy), args.Error(1)
+}
+
+func (m *MockRepository) GetCopiesByBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) ([]entities.Copy, error) {
+	args := m.Called(ctx, householdID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Copy), args.Error(1)
+}
+
+func (m *MockRepository) UpdateCopy(ctx context.Context, copy *entities.Copy) error {
+	return m.Called(ctx, copy).Error(0)
+}
+
+func (m *MockRepository) MoveCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID, locationID *uuid.UUID) error {
+	return m.Called(ctx, householdID, copyID, locationID).Error(0)
+}
+
+func (m *MockRepository) DeleteCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) error {
+	return m.Called(ctx, householdID, copyID).Error(0)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+type MockCoverUseCase struct {
+	mock.Mock
+}
+
+func (m *MockCoverUseCase) UploadCover(ctx context.Context, userID uuid.UUID, data []byte) (*coverDtos.CoverResponse, error) {
+	args := m.Called(ctx, userID, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*coverDtos.CoverResponse), args.Error(1)
+}
+
+func (m *MockCoverUseCase) GetCover(ctx context.Context, coverID uuid.UUID, variant coverEntities.Variant) (*blobstore.Object, error) {
+	args := m.Called(ctx, coverID, variant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*blobstore.Object), args.Error(1)
+}
+
+func (m *MockCoverUseCase) DeleteCover(ctx context.Context, coverID uuid.UUID) error {
+	return m.Called(ctx, coverID).Error(0)
+}
+
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) CreateItem(ctx context.Context, item *wishlistEntities.WishlistItem) (uuid.UUID, error) {
+	args := m.Called(ctx, item)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetItemsByUser(ctx context.Context, userID uuid.UUID) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetSharedItem(ctx context.Context, itemID uuid.UUID, viewerID uuid.UUID) (*wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, itemID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetSharedItemsByUser(ctx context.Context, ownerID uuid.UUID, viewerID uuid.UUID) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, ownerID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) FindItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbns []string) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) TakeItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbn string) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) UpdateItem(ctx context.Context, item *wishlistEntities.WishlistItem) error {
+	return m.Called(ctx, item).Error(0)
+}
+
+func (m *MockWishlistRepository) DeleteItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, itemID, userID).Error(0)
+}
+
+func (m *MockWishlistRepository) ReserveItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID, reservedAt time.Time) error {
+	return m.Called(ctx, itemID, userID, reservedAt).Error(0)
+}
+
+func (m *MockWishlistRepository) CancelReservation(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, itemID, userID).Error(0)
+}
+
+type passthroughTx struct{}
+
+func (passthroughTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	households *MockHouseholdRepository
+	covers     *MockCoverUseCase
+	wishlists  *MockWishlistRepository
+}
+
+func newUseCase() (UseCase, mocks) {
+	m := mocks{new(MockRepository), new(MockHouseholdRepository), new(MockCoverUseCase), new(MockWishlistRepository)}
+	return NewUseCase(m.repo, m.households, m.covers, m.wishlists, passthroughTx{}), m
+}
+
+func (m mocks) member(userID uuid.UUID, householdID uuid.UUID, role householdEntities.Role) {
+	m.households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: role}, nil)
+}
+
+func TestCreateBook(t *testing.T) {
+	t.Run("book is cleaned up and added to the household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("CreateBook", mock.Anything, userID, mock.MatchedBy(func(b *entities.Book) bool {
+			return b.HouseholdID == householdID && b.Title == "Солярис" && b.ISBN == "9785170906307" &&
+				assert.ObjectsAreEqual([]string{"Станислав Лем"}, []string(b.Authors)) &&
+				assert.ObjectsAreEqual([]string{"фантастика"}, []string(b.Tags))
+		})).Return(bookID, nil)
+		m.wishlists.On("TakeItemsByISBN", mock.Anything, userID, "9785170906307").Return([]wishlistEntities.WishlistItem{}, nil)
+
+		_, err := u.CreateBook(context.Background(), userID, dtos.BookRequest{
+			Title:   " Солярис ",
+			Authors: []string{" Станислав Лем"},
+			ISBN:    "978-5-17-090630-7",
+			Tags:    []string{"фантастика", "Фантастика ", " "},
+		})
+
+		assert.NoError(t, err)
+		m.repo.AssertExpectations(t)
+		// Nobody wished for the book, so it has no copy yet.
+		m.repo.AssertNotCalled(t, "CreateCopy", mock.Anything, mock.Anything)
+	})
+
+	t.Run("wished book leaves the wishlist with a copy of its purchase", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID := uuid.New(), uuid.New()
+		price, purchasedAt := 450.0, time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		var book *entities.Book
+		m.repo.On("CreateBook", mock.Anything, userID, mock.AnythingOfType("*entities.Book")).
+			Run(func(args mock.Arguments) { book = args.Get(2).(*entities.Book) }).
+			Return(uuid.New(), nil)
+		item := wishlistEntities.NewWishlistItem(uuid.New())
+		item.ISBN, item.PurchasePrice, item.PurchasedAt, item.PurchaseStore = "9785170906307", &price, &purchasedAt, "Буквоед"
+		m.wishlists.On("TakeItemsByISBN", mock.Anything, userID, "9785170906307").Return([]wishlistEntities.WishlistItem{*item}, nil)
+		m.repo.On("CreateCopy", mock.Anything, mock.MatchedBy(func(c *entities.Copy) bool {
+			return c.BookID == book.BookID && c.HouseholdID == householdID &&
+				*c.PurchasePrice == price && c.PurchasedAt.Equal(purchasedAt) && c.PurchaseStore == "Буквоед"
+		})).Return(uuid.New(), nil)
+
+		id, err := u.CreateBook(context.Background(), userID, dtos.BookRequest{Title: "Солярис", ISBN: "9785170906307"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, book.BookID, id)
+		m.repo.AssertExpectations(t)
+	})
+
+	t.Run("cover of another household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, coverID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("HasCover", mock.Anything, householdID, coverID).Return(false, nil)
+
+		_, err := u.CreateBook(context.Background(), userID, dtos.BookRequest{Title: "Солярис", CoverID: &coverID})
+
+		assert.ErrorIs(t, err, errors.ErrCoverNotFound)
+		m.repo.AssertNotCalled(t, "CreateBook", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("viewers cannot add books", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+		m.member(userID, uuid.New(), householdEntities.RoleViewer)
+
+		_, err := u.CreateBook(context.Background(), userID, dtos.BookRequest{Title: "Солярис"})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+		m.repo.AssertNotCalled(t, "CreateBook", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user outside a household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+		m.households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.CreateBook(context.Background(), userID, dtos.BookRequest{Title: "Солярис"})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdNotFound)
+	})
+}
+
+func TestGetBooks(t *testing.T) {
+	t.Run("filter gets the default page size and the location", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, locationID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleViewer)
+
+		m.repo.On("FindBooks", mock.Anything, householdID, entities.BookFilter{
+			Tag:        "фантастика",
+			LocationID: &locationID,
+			Limit:      defaultLimit,
+		}).Return([]entities.Book{{BookID: uuid.New(), Title: "Солярис"}}, nil)
+
+		books, err := u.GetBooks(context.Background(), userID, dtos.ListBooksRequest{Tag: " фантастика", LocationID: locationID.String()})
+
+		assert.NoError(t, err)
+		assert.Len(t, books, 1)
+		assert.Equal(t, []string{}, books[0].Authors)
+	})
+}
+
+func TestGetBook(t *testing.T) {
+	t.Run("book comes with its copies", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID := uuid.New(), uuid.New(), uuid.New()
+		purchasedAt := time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+		m.member(userID, householdID, householdEntities.RoleViewer)
+
+		m.repo.On("GetBook", mock.Anything, householdID, bookID).Return(&entities.Book{BookID: bookID, Title: "Солярис"}, nil)
+		m.repo.On("GetCopiesByBook", mock.Anything, householdID, bookID).
+			Return([]entities.Copy{{CopyID: uuid.New(), BookID: bookID, PurchasedAt: &purchasedAt}}, nil)
+
+		book, err := u.GetBook(context.Background(), userID, bookID)
+
+		assert.NoError(t, err)
+		assert.Len(t, book.Copies, 1)
+		assert.Equal(t, "2024-03-08", book.Copies[0].PurchasedAt)
+	})
+
+	t.Run("book of another household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleViewer)
+
+		m.repo.On("GetBook", mock.Anything, householdID, bookID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.GetBook(context.Background(), userID, bookID)
+
+		assert.ErrorIs(t, err, errors.ErrBookNotFound)
+	})
+}
+
+func TestUpdateBook(t *testing.T) {
+	t.Run("replaced cover is deleted", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID := uuid.New(), uuid.New(), uuid.New()
+		oldCover, newCover := uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		m.repo.On("GetBook", mock.Anything, householdID, bookID).Return(&entities.Book{BookID: bookID, CoverID: &oldCover}, nil)
+		m.repo.On("HasCover", mock.Anything, householdID, newCover).Return(true, nil)
+		m.repo.On("UpdateBook", mock.Anything, mock.MatchedBy(func(b *entities.Book) bool {
+			return b.BookID == bookID && b.HouseholdID == householdID && *b.CoverID == newCover
+		})).Return(nil)
+		m.covers.On("DeleteCover", mock.Anything, oldCover).Return(nil)
+
+		err := u.UpdateBook(context.Background(), userID, bookID, dtos.BookRequest{Title: "Солярис", CoverID: &newCover})
+
+		assert.NoError(t, err)
+		m.covers.AssertExpectations(t)
+	})
+
+	t.Run("kept cover is not deleted", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID, coverID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		m.repo.On("GetBook", mock.Anything, householdID, bookID).Return(&entities.Book{BookID: bookID, CoverID: &coverID}, nil)
+		m.repo.On("UpdateBook", mock.Anything, mock.Anything).Return(nil)
+
+		err := u.UpdateBook(context.Background(), userID, bookID, dtos.BookRequest{Title: "Солярис", CoverID: &coverID})
+
+		assert.NoError(t, err)
+		m.covers.AssertNotCalled(t, "DeleteCover", mock.Anything, mock.Anything)
+		m.repo.AssertNotCalled(t, "HasCover", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("cover of another household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID, coverID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		m.repo.On("GetBook", mock.Anything, householdID, bookID).Return(&entities.Book{BookID: bookID}, nil)
+		m.repo.On("HasCover", mock.Anything, householdID, coverID).Return(false, nil)
+
+		err := u.UpdateBook(context.Background(), userID, bookID, dtos.BookRequest{Title: "Солярис", CoverID: &coverID})
+
+		assert.ErrorIs(t, err, errors.ErrCoverNotFound)
+		m.repo.AssertNotCalled(t, "UpdateBook", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeleteBook(t *testing.T) {
+	t.Run("cover is deleted with the book", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID, coverID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetBook", mock.Anything, householdID, bookID).Return(&entities.Book{BookID: bookID, CoverID: &coverID}, nil)
+		m.repo.On("DeleteBook", mock.Anything, householdID, bookID).Return(nil)
+		m.covers.On("DeleteCover", mock.Anything, coverID).Return(nil)
+
+		err := u.DeleteBook(context.Background(), userID, bookID)
+
+		assert.NoError(t, err)
+		m.covers.AssertExpectations(t)
+	})
+}
+
+func TestAddCopy(t *testing.T) {
+	t.Run("copy is added with its purchase", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID, locationID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		price := 450.0
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetBook", mock.Anything, householdID, bookID).Return(&entities.Book{BookID: bookID}, nil)
+		var copy *entities.Copy
+		m.repo.On("CreateCopy", mock.Anything, mock.MatchedBy(func(c *entities.Copy) bool {
+			copy = c
+			return c.HouseholdID == householdID && c.BookID == bookID && *c.LocationID == locationID &&
+				*c.PurchasePrice == price && c.PurchasedAt.Format(dtos.DateLayout) == "2024-03-08" && c.PurchaseStore == "Буквоед"
+		})).Return(uuid.New(), nil)
+
+		id, err := u.AddCopy(context.Background(), userID, bookID, dtos.CopyRequest{
+			LocationID:    &locationID,
+			PurchasePrice: &price,
+			PurchasedAt:   "2024-03-08",
+			PurchaseStore: " Буквоед",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, copy.CopyID, id)
+		// A book without an ISBN cannot be on a wishlist.
+		m.wishlists.AssertNotCalled(t, "TakeItemsByISBN", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("wished book leaves the wishlist", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID := uuid.New(), uuid.New(), uuid.New()
+		wishedPrice, price := 500.0, 450.0
+		purchasedAt := time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC)
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetBook", mock.Anything, householdID, bookID).Return(&entities.Book{BookID: bookID, ISBN: "9785170906307"}, nil)
+		item := wishlistEntities.NewWishlistItem(uuid.New())
+		item.PurchasePrice, item.PurchasedAt, item.PurchaseStore = &wishedPrice, &purchasedAt, "Буквоед"
+		m.wishlists.On("TakeItemsByISBN", mock.Anything, userID, "9785170906307").Return([]wishlistEntities.WishlistItem{*item}, nil)
+		// The request's price wins; the wishlist fills in the rest.
+		m.repo.On("CreateCopy", mock.Anything, mock.MatchedBy(func(c *entities.Copy) bool {
+			return *c.PurchasePrice == price && c.PurchasedAt.Equal(purchasedAt) && c.PurchaseStore == "Буквоед"
+		})).Return(uuid.New(), nil)
+
+		_, err := u.AddCopy(context.Background(), userID, bookID, dtos.CopyRequest{PurchasePrice: &price})
+
+		assert.NoError(t, err)
+		m.repo.AssertExpectations(t)
+	})
+
+	t.Run("book of another household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetBook", mock.Anything, householdID, bookID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.AddCopy(context.Background(), userID, bookID, dtos.CopyRequest{})
+
+		assert.ErrorIs(t, err, errors.ErrBookNotFound)
+		m.repo.AssertNotCalled(t, "CreateCopy", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMoveCopy(t *testing.T) {
+	t.Run("copy is taken off its shelf", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, copyID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("MoveCopy", mock.Anything, householdID, copyID, (*uuid.UUID)(nil)).Return(nil)
+
+		err := u.MoveCopy(context.Background(), userID, copyID, dtos.MoveCopyRequest{})
+
+		assert.NoError(t, err)
+		m.repo.AssertExpectations(t)
+	})
+
+	t.Run("viewer cannot move copies", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, locationID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleViewer)
+
+		err := u.MoveCopy(context.Background(), userID, uuid.New(), dtos.MoveCopyRequest{LocationID: &locationID})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+		m.repo.AssertNotCalled(t, "MoveCopy", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeleteLocation(t *testing.T) {
+	t.Run("location of another household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, locationID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		m.repo.On("DeleteLocation", mock.Anything, householdID, locationID).Return(sql.ErrNoRows)
+
+		err := u.DeleteLocation(context.Background(), userID, locationID)
+
+		assert.ErrorIs(t, err, errors.ErrLocationNotFound)
+	})
+}
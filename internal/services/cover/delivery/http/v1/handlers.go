@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/cover/dtos"
+	"home-library/internal/services/cover/entities"
+	"home-library/internal/services/cover/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Cover variants are immutable, a new upload always gets a new cover ID.
+const cacheControl = "public, max-age=31536000, immutable"
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) UploadCover(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	file, err := c.FormFile("cover")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Файл обложки не передан", nil))
+	}
+	if file.Size > usecases.MaxUploadSize {
+		return h.handleError(c, customErrors.ErrCoverTooLarge, "cover is too large")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to open uploaded cover")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, usecases.MaxUploadSize+1))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to read uploaded cover")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	cover, err := h.u.UploadCover(c.Request().Context(), userID, data)
+	if err != nil {
+		return h.handleError(c, err, "failed to upload cover")
+	}
+
+	return c.JSON(http.StatusCreated, cover)
+}
+
+func (h *handler) GetCover(c echo.Context) error {
+	coverID, err := uuid.Parse(c.Param("cover_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+	variant := entities.Variant(c.Param("variant"))
+
+	etag := fmt.Sprintf("%q", fmt.Sprintf("%s-%s", coverID, variant))
+	if c.Request().Header.Get("If-None-Match") == etag {
+		c.Response().Header().Set(echo.HeaderCacheControl, cacheControl)
+		c.Response().Header().Set("ETag", etag)
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	object, err := h.u.GetCover(c.Request().Context(), coverID, variant)
+	if err != nil {
+		return h.handleError(c, err, "failed to get cover")
+	}
+	defer object.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderCacheControl, cacheControl)
+	header.Set("ETag", etag)
+	header.Set(echo.HeaderLastModified, object.LastModified.UTC().Format(http.TimeFormat))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(object.Size, 10))
+
+	return c.Stream(http.StatusOK, object.ContentType, object)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrCoverNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Обложка не найдена", nil))
+	case errors.Is(err, customErrors.ErrCoverTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, dtos.NewErrorResponse(http.StatusRequestEntityTooLarge, "Изображение слишком большое", nil))
+	case errors.Is(err, customErrors.ErrCoverUnsupported):
+		return c.JSON(http.StatusUnsupportedMediaType, dtos.NewErrorResponse(http.StatusUnsupportedMediaType, "Поддерживаются только JPEG, PNG, GIF и WebP", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
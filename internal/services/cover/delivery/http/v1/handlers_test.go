@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"home-library/internal/services/cover/dtos"
+	"home-library/internal/services/cover/entities"
+	"home-library/pkg/blobstore"
+	customErrors "home-library/pkg/errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) UploadCover(ctx context.Context, userID uuid.UUID, data []byte) (*dtos.CoverResponse, error) {
+	args := m.Called(ctx, userID, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.CoverResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetCover(ctx context.Context, coverID uuid.UUID, variant entities.Variant) (*blobstore.Object, error) {
+	args := m.Called(ctx, coverID, variant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*blobstore.Object), args.Error(1)
+}
+
+func (m *MockUseCase) DeleteCover(ctx context.Context, coverID uuid.UUID) error {
+	args := m.Called(ctx, coverID)
+	return args.Error(0)
+}
+
+func newUploadContext(t *testing.T, e *echo.Echo, content []byte, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("cover", "cover.jpg")
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/covers", &body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func newGetContext(e *echo.Echo, coverID string, variant string, etag string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("cover_id", "variant")
+	c.SetParamValues(coverID, variant)
+	return c, rec
+}
+
+func TestUploadCover(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully upload cover", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, coverID := uuid.New(), uuid.New()
+
+		mockUseCase.On("UploadCover", mock.Anything, userID, []byte("image")).Return(&dtos.CoverResponse{CoverID: coverID}, nil)
+
+		c, rec := newUploadContext(t, e, []byte("image"), userID)
+		err := h.UploadCover(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response dtos.CoverResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, coverID, response.CoverID)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newUploadContext(t, e, []byte("image"), uuid.Nil)
+		err := h.UploadCover(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"unsupported format", customErrors.ErrCoverUnsupported, http.StatusUnsupportedMediaType},
+		{"too large", customErrors.ErrCoverTooLarge, http.StatusRequestEntityTooLarge},
+		{"internal error", errors.New("storage is down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			h := NewHandler(mockUseCase)
+			userID := uuid.New()
+
+			mockUseCase.On("UploadCover", mock.Anything, userID, mock.Anything).Return(nil, tt.err)
+
+			c, rec := newUploadContext(t, e, []byte("image"), userID)
+			err := h.UploadCover(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+}
+
+func TestGetCover(t *testing.T) {
+	e := echo.New()
+
+	t.Run("serves image with caching headers", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		coverID := uuid.New()
+		modified := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		mockUseCase.On("GetCover", mock.Anything, coverID, entities.VariantMedium).Return(&blobstore.Object{
+			ReadCloser: io.NopCloser(strings.NewReader("jpeg")),
+			Info:       blobstore.Info{Size: 4, ContentType: "image/jpeg", LastModified: modified},
+		}, nil)
+
+		c, rec := newGetContext(e, coverID.String(), "medium", "")
+		err := h.GetCover(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "jpeg", rec.Body.String())
+		assert.Equal(t, "image/jpeg", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get(echo.HeaderCacheControl))
+		assert.Equal(t, `"`+coverID.String()+`-medium"`, rec.Header().Get("ETag"))
+		assert.Equal(t, "Thu, 02 Jan 2025 03:04:05 GMT", rec.Header().Get(echo.HeaderLastModified))
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		coverID := uuid.New()
+
+		c, rec := newGetContext(e, coverID.String(), "large", `"`+coverID.String()+`-large"`)
+		err := h.GetCover(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		mockUseCase.AssertNotCalled(t, "GetCover")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		coverID := uuid.New()
+
+		mockUseCase.On("GetCover", mock.Anything, coverID, entities.Variant("original")).Return(nil, customErrors.ErrCoverNotFound)
+
+		c, rec := newGetContext(e, coverID.String(), "original", "")
+		err := h.GetCover(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newGetContext(e, "abc", "large", "")
+		err := h.GetCover(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// CoverRoutes registers the upload on the authorized group and image
+// serving on the public one, <img> tags cannot send a bearer token.
+func (h *handler) CoverRoutes(public *echo.Group, authorized *echo.Group) {
+	// The limit leaves room for the multipart envelope around the file.
+	authorized.POST("/covers", h.UploadCover, middleware.BodyLimit("11M"))
+	public.GET("/covers/:cover_id/:variant", h.GetCover)
+}
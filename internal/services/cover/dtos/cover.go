@@ -0,0 +1,10 @@
+package dtos
+
+import "github.com/google/uuid"
+
+type CoverResponse struct {
+	CoverID   uuid.UUID `json:"cover_id"`
+	Thumbnail string    `json:"thumbnail"`
+	Medium    string    `json:"medium"`
+	Large     string    `json:"large"`
+}
@@ -0,0 +1,64 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cover is an uploaded cover, stored in every variant. Books and ebook
+// files may share it.
+type Cover struct {
+	CoverID uuid.UUID `db:"cover_id"`
+	// UploadedBy is nil for covers restored from an archive.
+	UploadedBy *uuid.UUID `db:"uploaded_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+func NewCover(uploadedBy uuid.UUID) *Cover {
+	return &Cover{
+		CoverID:    uuid.New(),
+		UploadedBy: &uploadedBy,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// Variant is one of the fixed sizes a cover is stored in. The original
+// upload itself is never kept.
+type Variant string
+
+const (
+	VariantThumbnail Variant = "thumbnail"
+	VariantMedium    Variant = "medium"
+	VariantLarge     Variant = "large"
+)
+
+// Variants lists the stored sizes, largest first.
+var Variants = []Variant{VariantLarge, VariantMedium, VariantThumbnail}
+
+// Bounds returns the box the variant is scaled down to fit into.
+func (v Variant) Bounds() (width int, height int) {
+	switch v {
+	case VariantThumbnail:
+		return 120, 180
+	case VariantMedium:
+		return 320, 480
+	default:
+		return 800, 1200
+	}
+}
+
+func (v Variant) IsValid() bool {
+	switch v {
+	case VariantThumbnail, VariantMedium, VariantLarge:
+		return true
+	default:
+		return false
+	}
+}
+
+// Key returns the blob store key of the variant.
+func (v Variant) Key(coverID uuid.UUID) string {
+	return fmt.Sprintf("covers/%s/%s.jpg", coverID, v)
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	stdErrors "errors"
+	"home-library/internal/services/cover/entities"
+	"home-library/pkg/storage"
+	"home-library/pkg/transaction"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	CreateCover(ctx context.Context, cover *entities.Cover) error
+	// DeleteUnusedCover deletes the cover unless a book or an ebook file
+	// uses it, and tells whether it did.
+	DeleteUnusedCover(ctx context.Context, coverID uuid.UUID) (bool, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateCover(ctx context.Context, cover *entities.Cover) error {
+	query := `
+		INSERT INTO covers (cover_id, uploaded_by, created_at)
+		VALUES (:cover_id, :uploaded_by, :created_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, cover)
+	return err
+}
+
+// DeleteUnusedCover checks the uses in the statement itself. A book taking
+// the cover concurrently is caught by the foreign keys on the cover, which
+// reject the delete once that book is committed.
+func (r *repository) DeleteUnusedCover(ctx context.Context, coverID uuid.UUID) (bool, error) {
+	query := `
+		DELETE FROM covers
+		WHERE cover_id = $1
+			AND NOT EXISTS (SELECT 1 FROM books WHERE cover_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM ebook_files WHERE cover_id = $1)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, coverID)
+	if stdErrors.Is(storage.MapError(err), storage.ErrForeignKeyViolation) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/cover/entities"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestCreateCover(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	cover := entities.NewCover(uuid.New())
+
+	mock.ExpectExec("INSERT INTO covers").
+		WithArgs(cover.CoverID, cover.UploadedBy, cover.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.CreateCover(context.Background(), cover))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteUnusedCover(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	query := `DELETE FROM covers\s+WHERE cover_id = \$1\s+AND NOT EXISTS \(SELECT 1 FROM books WHERE cover_id = \$1\)\s+AND NOT EXISTS \(SELECT 1 FROM ebook_files WHERE cover_id = \$1\)`
+
+	t.Run("unused", func(t *testing.T) {
+		coverID := uuid.New()
+		mock.ExpectExec(query).WithArgs(coverID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		deleted, err := repo.DeleteUnusedCover(context.Background(), coverID)
+
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("still used", func(t *testing.T) {
+		coverID := uuid.New()
+		mock.ExpectExec(query).WithArgs(coverID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		deleted, err := repo.DeleteUnusedCover(context.Background(), coverID)
+
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("taken by a concurrent book", func(t *testing.T) {
+		coverID := uuid.New()
+		mock.ExpectExec(query).WithArgs(coverID).
+			WillReturnError(&pq.Error{Code: "23503", Table: "books", Constraint: "books_cover_fkey"})
+
+		deleted, err := repo.DeleteUnusedCover(context.Background(), coverID)
+
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
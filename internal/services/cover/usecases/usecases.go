@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"home-library/internal/services/cover/dtos"
+	"home-library/internal/services/cover/entities"
+	"home-library/internal/services/cover/repository"
+	"home-library/pkg/blobstore"
+	"home-library/pkg/errors"
+	"home-library/pkg/imaging"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// MaxUploadSize is the largest accepted cover upload in bytes.
+const MaxUploadSize = 10 << 20
+
+const jpegQuality = 85
+
+type UseCase interface {
+	UploadCover(ctx context.Context, userID uuid.UUID, data []byte) (*dtos.CoverResponse, error)
+	GetCover(ctx context.Context, coverID uuid.UUID, variant entities.Variant) (*blobstore.Object, error)
+	// DeleteCover removes every variant of the cover once no book or ebook
+	// file uses it anymore, so callers release the cover after changing
+	// their own row. Deleting a missing cover is not an error.
+	DeleteCover(ctx context.Context, coverID uuid.UUID) error
+}
+
+type useCase struct {
+	r     repository.Repository
+	store blobstore.Store
+}
+
+func NewUseCase(r repository.Repository, store blobstore.Store) UseCase {
+	return &useCase{r: r, store: store}
+}
+
+// UploadCover decodes the uploaded image and stores every variant as a
+// freshly encoded JPEG, which also drops the EXIF data of the original.
+func (u *useCase) UploadCover(ctx context.Context, userID uuid.UUID, data []byte) (*dtos.CoverResponse, error) {
+	if len(data) > MaxUploadSize {
+		return nil, errors.ErrCoverTooLarge
+	}
+
+	img, err := imaging.Decode(data)
+	switch {
+	case stdErrors.Is(err, imaging.ErrTooLarge):
+		return nil, errors.ErrCoverTooLarge
+	case err != nil:
+		return nil, errors.ErrCoverUnsupported
+	}
+
+	cover := entities.NewCover(userID)
+	coverID := cover.CoverID
+	for _, variant := range entities.Variants {
+		// Variants are produced largest first, scaling each from the
+		// previous one is cheaper and indistinguishable at these sizes.
+		width, height := variant.Bounds()
+		img = imaging.Fit(img, width, height)
+
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, img, jpegQuality); err != nil {
+			return nil, err
+		}
+
+		if err := u.store.Put(ctx, variant.Key(coverID), &buf, int64(buf.Len()), "image/jpeg"); err != nil {
+			u.cleanup(coverID)
+			return nil, err
+		}
+	}
+
+	if err := u.r.CreateCover(ctx, cover); err != nil {
+		u.cleanup(coverID)
+		return nil, err
+	}
+
+	log.Info().Str("user_id", userID.String()).Str("cover_id", coverID.String()).Msg("cover uploaded")
+
+	return newCoverResponse(coverID), nil
+}
+
+func (u *useCase) GetCover(ctx context.Context, coverID uuid.UUID, variant entities.Variant) (*blobstore.Object, error) {
+	if !variant.IsValid() {
+		return nil, errors.ErrCoverNotFound
+	}
+
+	object, err := u.store.Get(ctx, variant.Key(coverID))
+	if stdErrors.Is(err, blobstore.ErrNotFound) {
+		return nil, errors.ErrCoverNotFound
+	}
+	return object, err
+}
+
+func (u *useCase) DeleteCover(ctx context.Context, coverID uuid.UUID) error {
+	deleted, err := u.r.DeleteUnusedCover(ctx, coverID)
+	if err != nil || !deleted {
+		return err
+	}
+
+	return u.deleteVariants(ctx, coverID)
+}
+
+func (u *useCase) deleteVariants(ctx context.Context, coverID uuid.UUID) error {
+	for _, variant := range entities.Variants {
+		if err := u.store.Delete(ctx, variant.Key(coverID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanup removes the variants of a half-stored cover. It runs detached from
+// the request context, which is likely what failed the upload.
+func (u *useCase) cleanup(coverID uuid.UUID) {
+	if err := u.deleteVariants(context.Background(), coverID); err != nil {
+		log.Error().Err(err).Str("cover_id", coverID.String()).Msg("failed to clean up cover variants")
+	}
+}
+
+func newCoverResponse(coverID uuid.UUID) *dtos.CoverResponse {
+	url := func(variant entities.Variant) string {
+		return fmt.Sprintf("/api/v1/covers/%s/%s", coverID, variant)
+	}
+
+	return &dtos.CoverResponse{
+		CoverID:   coverID,
+		Thumbnail: url(entities.VariantThumbnail),
+		Medium:    url(entities.VariantMedium),
+		Large:     url(entities.VariantLarge),
+	}
+}
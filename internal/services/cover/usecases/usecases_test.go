@@ -0,0 +1,216 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"home-library/internal/services/cover/entities"
+	"home-library/pkg/blobstore"
+	customErrors "home-library/pkg/errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateCover(ctx context.Context, cover *entities.Cover) error {
+	return m.Called(ctx, cover).Error(0)
+}
+
+func (m *MockRepository) DeleteUnusedCover(ctx context.Context, coverID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, coverID)
+	return args.Bool(0), args.Error(1)
+}
+
+// newRepository records every upload.
+func newRepository() *MockRepository {
+	repo := new(MockRepository)
+	repo.On("CreateCover", mock.Anything, mock.AnythingOfType("*entities.Cover")).Return(nil)
+	return repo
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// failingStore fails every Put after the first n and records deletions.
+type failingStore struct {
+	blobstore.Store
+	n       int
+	deleted []string
+}
+
+func (s *failingStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if s.n == 0 {
+		return errors.New("storage is down")
+	}
+	s.n--
+	return s.Store.Put(ctx, key, r, size, contentType)
+}
+
+func (s *failingStore) Delete(ctx context.Context, key string) error {
+	s.deleted = append(s.deleted, key)
+	return s.Store.Delete(ctx, key)
+}
+
+func TestUploadCover(t *testing.T) {
+	t.Run("stores resized variants", func(t *testing.T) {
+		store, err := blobstore.NewLocal(t.TempDir())
+		require.NoError(t, err)
+		useCase := NewUseCase(newRepository(), store)
+
+		cover, err := useCase.UploadCover(context.Background(), uuid.New(), encodePNG(t, 1000, 1500))
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/covers/"+cover.CoverID.String()+"/medium", cover.Medium)
+
+		for _, variant := range entities.Variants {
+			object, err := useCase.GetCover(context.Background(), cover.CoverID, variant)
+			require.NoError(t, err)
+
+			assert.Equal(t, "image/jpeg", object.ContentType)
+			cfg, err := jpeg.DecodeConfig(object)
+			require.NoError(t, err)
+			object.Close()
+
+			width, height := variant.Bounds()
+			assert.Equal(t, width, cfg.Width, variant)
+			assert.Equal(t, height, cfg.Height, variant)
+		}
+	})
+
+	t.Run("small image is not upscaled", func(t *testing.T) {
+		store, err := blobstore.NewLocal(t.TempDir())
+		require.NoError(t, err)
+		useCase := NewUseCase(newRepository(), store)
+
+		cover, err := useCase.UploadCover(context.Background(), uuid.New(), encodePNG(t, 200, 100))
+		require.NoError(t, err)
+
+		object, err := useCase.GetCover(context.Background(), cover.CoverID, entities.VariantLarge)
+		require.NoError(t, err)
+		defer object.Close()
+
+		cfg, err := jpeg.DecodeConfig(object)
+		require.NoError(t, err)
+		assert.Equal(t, 200, cfg.Width)
+		assert.Equal(t, 100, cfg.Height)
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		useCase := NewUseCase(nil, nil)
+
+		_, err := useCase.UploadCover(context.Background(), uuid.New(), []byte("<svg></svg>"))
+
+		assert.Equal(t, customErrors.ErrCoverUnsupported, err)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		useCase := NewUseCase(nil, nil)
+
+		_, err := useCase.UploadCover(context.Background(), uuid.New(), []byte(strings.Repeat("x", MaxUploadSize+1)))
+
+		assert.Equal(t, customErrors.ErrCoverTooLarge, err)
+	})
+
+	t.Run("removes stored variants on failure", func(t *testing.T) {
+		local, err := blobstore.NewLocal(t.TempDir())
+		require.NoError(t, err)
+		store := &failingStore{Store: local, n: 1}
+		repo := new(MockRepository)
+		useCase := NewUseCase(repo, store)
+
+		_, err = useCase.UploadCover(context.Background(), uuid.New(), encodePNG(t, 100, 100))
+
+		assert.EqualError(t, err, "storage is down")
+		assert.Len(t, store.deleted, len(entities.Variants))
+		repo.AssertNotCalled(t, "CreateCover", mock.Anything, mock.Anything)
+	})
+
+	t.Run("records the uploader", func(t *testing.T) {
+		store, err := blobstore.NewLocal(t.TempDir())
+		require.NoError(t, err)
+		repo := newRepository()
+		useCase := NewUseCase(repo, store)
+		userID := uuid.New()
+
+		cover, err := useCase.UploadCover(context.Background(), userID, encodePNG(t, 100, 100))
+		require.NoError(t, err)
+
+		repo.AssertCalled(t, "CreateCover", mock.Anything, mock.MatchedBy(func(c *entities.Cover) bool {
+			return c.CoverID == cover.CoverID && *c.UploadedBy == userID
+		}))
+	})
+}
+
+func TestGetCover(t *testing.T) {
+	store, err := blobstore.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	useCase := NewUseCase(newRepository(), store)
+
+	_, err = useCase.GetCover(context.Background(), uuid.New(), entities.VariantMedium)
+	assert.Equal(t, customErrors.ErrCoverNotFound, err)
+
+	_, err = useCase.GetCover(context.Background(), uuid.New(), entities.Variant("original"))
+	assert.Equal(t, customErrors.ErrCoverNotFound, err)
+}
+
+func TestDeleteCover(t *testing.T) {
+	t.Run("unused cover", func(t *testing.T) {
+		store, err := blobstore.NewLocal(t.TempDir())
+		require.NoError(t, err)
+		repo := newRepository()
+		useCase := NewUseCase(repo, store)
+
+		cover, err := useCase.UploadCover(context.Background(), uuid.New(), encodePNG(t, 100, 100))
+		require.NoError(t, err)
+		repo.On("DeleteUnusedCover", mock.Anything, cover.CoverID).Return(true, nil).Once()
+
+		require.NoError(t, useCase.DeleteCover(context.Background(), cover.CoverID))
+		for _, variant := range entities.Variants {
+			_, err := useCase.GetCover(context.Background(), cover.CoverID, variant)
+			assert.Equal(t, customErrors.ErrCoverNotFound, err)
+		}
+
+		repo.On("DeleteUnusedCover", mock.Anything, cover.CoverID).Return(false, nil)
+		assert.NoError(t, useCase.DeleteCover(context.Background(), cover.CoverID), "deleting twice is not an error")
+	})
+
+	t.Run("cover still used elsewhere", func(t *testing.T) {
+		store, err := blobstore.NewLocal(t.TempDir())
+		require.NoError(t, err)
+		repo := newRepository()
+		useCase := NewUseCase(repo, store)
+
+		cover, err := useCase.UploadCover(context.Background(), uuid.New(), encodePNG(t, 100, 100))
+		require.NoError(t, err)
+		repo.On("DeleteUnusedCover", mock.Anything, cover.CoverID).Return(false, nil)
+
+		require.NoError(t, useCase.DeleteCover(context.Background(), cover.CoverID))
+		for _, variant := range entities.Variants {
+			object, err := useCase.GetCover(context.Background(), cover.CoverID, variant)
+			require.NoError(t, err)
+			object.Close()
+		}
+	})
+}
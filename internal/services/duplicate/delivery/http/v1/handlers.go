@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/duplicate/dtos"
+	"home-library/internal/services/duplicate/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) GetBookDuplicates(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	pairs, err := h.u.GetBookDuplicates(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get duplicate books")
+	}
+
+	return c.JSON(http.StatusOK, pairs)
+}
+
+func (h *handler) GetAuthorDuplicates(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	pairs, err := h.u.GetAuthorDuplicates(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get duplicate authors")
+	}
+
+	return c.JSON(http.StatusOK, pairs)
+}
+
+func (h *handler) Detect(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	jobID, err := h.u.Detect(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to queue duplicate detection")
+	}
+
+	return c.JSON(http.StatusAccepted, dtos.DetectResponse{JobID: jobID})
+}
+
+func (h *handler) MergeBooks(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.MergeBooksRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.MergeBooks(c.Request().Context(), userID, payload); err != nil {
+		return h.handleError(c, err, "failed to merge books")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) MergeAuthors(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.MergeAuthorsRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.MergeAuthors(c.Request().Context(), userID, payload); err != nil {
+		return h.handleError(c, err, "failed to merge authors")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) DismissBooks(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.DismissBooksRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.DismissBooks(c.Request().Context(), userID, payload); err != nil {
+		return h.handleError(c, err, "failed to dismiss duplicate books")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) DismissAuthors(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.DismissAuthorsRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.DismissAuthors(c.Request().Context(), userID, payload); err != nil {
+		return h.handleError(c, err, "failed to dismiss duplicate authors")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Книга не найдена", nil))
+	case errors.Is(err, customErrors.ErrAuthorNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Автор не найден", nil))
+	case errors.Is(err, customErrors.ErrDuplicateNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Возможный дубликат не найден", nil))
+	case errors.Is(err, customErrors.ErrMergeSelf):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Нельзя объединить запись с самой собой", nil))
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
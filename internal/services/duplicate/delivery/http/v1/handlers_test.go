@@ -0,0 +1,165 @@
+package v1
+
+import (
+	"context"
+	"home-library/internal/services/duplicate/dtos"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jobs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) GetBookDuplicates(ctx context.Context, userID uuid.UUID) ([]dtos.BookPairResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.BookPairResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetAuthorDuplicates(ctx context.Context, userID uuid.UUID) ([]dtos.AuthorPairResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.AuthorPairResponse), args.Error(1)
+}
+
+func (m *MockUseCase) Detect(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) DetectAll(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockUseCase) MergeBooks(ctx context.Context, userID uuid.UUID, payload dtos.MergeBooksRequest) error {
+	return m.Called(ctx, userID, payload).Error(0)
+}
+
+func (m *MockUseCase) MergeAuthors(ctx context.Context, userID uuid.UUID, payload dtos.MergeAuthorsRequest) error {
+	return m.Called(ctx, userID, payload).Error(0)
+}
+
+func (m *MockUseCase) DismissBooks(ctx context.Context, userID uuid.UUID, payload dtos.DismissBooksRequest) error {
+	return m.Called(ctx, userID, payload).Error(0)
+}
+
+func (m *MockUseCase) DismissAuthors(ctx context.Context, userID uuid.UUID, payload dtos.DismissAuthorsRequest) error {
+	return m.Called(ctx, userID, payload).Error(0)
+}
+
+func (m *MockUseCase) Run(ctx context.Context, job *jobs.Job) error {
+	return m.Called(ctx, job).Error(0)
+}
+
+func newContext(e *echo.Echo, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/duplicates", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestDetect(t *testing.T) {
+	e := echo.New()
+	mockUseCase := new(MockUseCase)
+	h := NewHandler(mockUseCase)
+	userID, jobID := uuid.New(), uuid.New()
+
+	mockUseCase.On("Detect", mock.Anything, userID).Return(jobID, nil)
+
+	c, rec := newContext(e, "", userID)
+	err := h.Detect(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), jobID.String())
+}
+
+func TestMergeBooks(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully merge", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, keepID, mergeID := uuid.New(), uuid.New(), uuid.New()
+
+		mockUseCase.On("MergeBooks", mock.Anything, userID, dtos.MergeBooksRequest{KeepID: keepID, MergeID: mergeID}).Return(nil)
+
+		c, rec := newContext(e, `{"keep_id": "`+keepID.String()+`", "merge_id": "`+mergeID.String()+`"}`, userID)
+		err := h.MergeBooks(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("book merged into itself", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, bookID := uuid.New(), uuid.New()
+
+		mockUseCase.On("MergeBooks", mock.Anything, userID, dtos.MergeBooksRequest{KeepID: bookID, MergeID: bookID}).
+			Return(customErrors.ErrMergeSelf)
+
+		c, rec := newContext(e, `{"keep_id": "`+bookID.String()+`", "merge_id": "`+bookID.String()+`"}`, userID)
+		err := h.MergeBooks(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("missing book", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{"keep_id": "`+uuid.NewString()+`"}`, uuid.New())
+		err := h.MergeBooks(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestMergeAuthors(t *testing.T) {
+	e := echo.New()
+
+	t.Run("same name", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{"keep": "Лем", "merge": "Лем"}`, uuid.New())
+		err := h.MergeAuthors(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("name no book carries", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("MergeAuthors", mock.Anything, userID, dtos.MergeAuthorsRequest{Keep: "Станислав Лем", Merge: "S. Lem"}).
+			Return(customErrors.ErrAuthorNotFound)
+
+		c, rec := newContext(e, `{"keep": "Станислав Лем", "merge": "S. Lem"}`, userID)
+		err := h.MergeAuthors(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
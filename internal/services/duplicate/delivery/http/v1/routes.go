@@ -0,0 +1,13 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) DuplicateRoutes(domain *echo.Group) {
+	domain.GET("/duplicates/books", h.GetBookDuplicates)
+	domain.GET("/duplicates/authors", h.GetAuthorDuplicates)
+	domain.POST("/duplicates/detect", h.Detect)
+	domain.POST("/duplicates/books/merge", h.MergeBooks)
+	domain.POST("/duplicates/authors/merge", h.MergeAuthors)
+	domain.POST("/duplicates/books/dismiss", h.DismissBooks)
+	domain.POST("/duplicates/authors/dismiss", h.DismissAuthors)
+}
@@ -0,0 +1,86 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/duplicate/entities"
+	"time"
+)
+
+type BookItem struct {
+	BookID  uuid.UUID `json:"book_id"`
+	Title   string    `json:"title"`
+	Authors []string  `json:"authors"`
+	ISBN    string    `json:"isbn"`
+	Copies  int       `json:"copies"`
+}
+
+func NewBookItem(book entities.Book) BookItem {
+	return BookItem{BookID: book.BookID, Title: book.Title, Authors: book.Authors, ISBN: book.ISBN, Copies: book.Copies}
+}
+
+type BookPairResponse struct {
+	A          BookItem  `json:"a"`
+	B          BookItem  `json:"b"`
+	Score      float64   `json:"score"`
+	Reasons    []string  `json:"reasons"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+type AuthorItem struct {
+	Name  string `json:"name"`
+	Books int    `json:"books"`
+}
+
+type AuthorPairResponse struct {
+	A          AuthorItem `json:"a"`
+	B          AuthorItem `json:"b"`
+	Score      float64    `json:"score"`
+	DetectedAt time.Time  `json:"detected_at"`
+}
+
+type DetectResponse struct {
+	JobID uuid.UUID `json:"job_id"`
+}
+
+// MergeBooksRequest merges the book MergeID into KeepID, which is kept.
+type MergeBooksRequest struct {
+	KeepID  uuid.UUID `json:"keep_id" validate:"required"`
+	MergeID uuid.UUID `json:"merge_id" validate:"required"`
+}
+
+func (r *MergeBooksRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// MergeAuthorsRequest renames the author Merge to Keep on every book.
+type MergeAuthorsRequest struct {
+	Keep  string `json:"keep" validate:"required,max=255"`
+	Merge string `json:"merge" validate:"required,max=255,nefield=Keep"`
+}
+
+func (r *MergeAuthorsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type DismissBooksRequest struct {
+	A uuid.UUID `json:"a" validate:"required"`
+	B uuid.UUID `json:"b" validate:"required"`
+}
+
+func (r *DismissBooksRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type DismissAuthorsRequest struct {
+	A string `json:"a" validate:"required,max=255"`
+	B string `json:"b" validate:"required,max=255"`
+}
+
+func (r *DismissAuthorsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Book is a book of the catalog as duplicates are detected and shown.
+type Book struct {
+	BookID  uuid.UUID      `db:"book_id"`
+	Title   string         `db:"title"`
+	Authors pq.StringArray `db:"authors"`
+	ISBN    string         `db:"isbn"`
+	CoverID *uuid.UUID     `db:"cover_id"`
+	Copies  int            `db:"copies"`
+}
+
+// Author is a name found on the books of the household.
+type Author struct {
+	Name  string `db:"name"`
+	Books int    `db:"books"`
+}
+
+// BookPair is a candidate duplicate, with BookA the lesser ID.
+type BookPair struct {
+	HouseholdID uuid.UUID      `db:"household_id"`
+	BookA       uuid.UUID      `db:"book_a"`
+	BookB       uuid.UUID      `db:"book_b"`
+	Score       float64        `db:"score"`
+	Reasons     pq.StringArray `db:"reasons"`
+	Dismissed   bool           `db:"dismissed"`
+	DetectedAt  time.Time      `db:"detected_at"`
+}
+
+// AuthorPair is a candidate duplicate, with AuthorA the lesser name.
+type AuthorPair struct {
+	HouseholdID uuid.UUID `db:"household_id"`
+	AuthorA     string    `db:"author_a"`
+	AuthorB     string    `db:"author_b"`
+	Score       float64   `db:"score"`
+	Dismissed   bool      `db:"dismissed"`
+	DetectedAt  time.Time `db:"detected_at"`
+}
+
+func NewBookPair(householdID uuid.UUID, a uuid.UUID, b uuid.UUID, score float64, reasons []string, now time.Time) BookPair {
+	if b.String() < a.String() {
+		a, b = b, a
+	}
+	return BookPair{HouseholdID: householdID, BookA: a, BookB: b, Score: score, Reasons: reasons, DetectedAt: now}
+}
+
+func NewAuthorPair(householdID uuid.UUID, a string, b string, score float64, now time.Time) AuthorPair {
+	if b < a {
+		a, b = b, a
+	}
+	return AuthorPair{HouseholdID: householdID, AuthorA: a, AuthorB: b, Score: score, DetectedAt: now}
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/duplicate/entities"
+	"home-library/pkg/transaction"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// pairsLimit is how many candidates of each kind a household is shown.
+const pairsLimit = 200
+
+type Repository interface {
+	// GetHouseholds lists the households with books to look for duplicates
+	// among.
+	GetHouseholds(ctx context.Context) ([]uuid.UUID, error)
+	GetBooks(ctx context.Context, householdID uuid.UUID) ([]entities.Book, error)
+	GetBooksByID(ctx context.Context, householdID uuid.UUID, bookIDs []uuid.UUID) ([]entities.Book, error)
+	GetAuthors(ctx context.Context, householdID uuid.UUID) ([]entities.Author, error)
+
+	// DeletePending removes the candidates of the household that were not
+	// dismissed, before a detection run adds what it found.
+	DeletePending(ctx context.Context, householdID uuid.UUID) error
+	// AddBookPairs and AddAuthorPairs skip the pairs already dismissed.
+	AddBookPairs(ctx context.Context, pairs []entities.BookPair) error
+	AddAuthorPairs(ctx context.Context, pairs []entities.AuthorPair) error
+	// GetBookPairs and GetAuthorPairs list the candidates not dismissed, best
+	// first.
+	GetBookPairs(ctx context.Context, householdID uuid.UUID) ([]entities.BookPair, error)
+	GetAuthorPairs(ctx context.Context, householdID uuid.UUID) ([]entities.AuthorPair, error)
+	DismissBookPair(ctx context.Context, householdID uuid.UUID, a uuid.UUID, b uuid.UUID) error
+	DismissAuthorPair(ctx context.Context, householdID uuid.UUID, a string, b string) error
+
+	// MergeBooks moves everything attached to the book mergeID to keepID,
+	// fills the fields keepID leaves blank, and deletes mergeID. Loans follow
+	// the copies. It takes several statements, so callers run it in a
+	// transaction.
+	MergeBooks(ctx context.Context, householdID uuid.UUID, keepID uuid.UUID, mergeID uuid.UUID) error
+	// MergeAuthors replaces the name merge with keep on every book of the
+	// household and returns how many books it changed.
+	MergeAuthors(ctx context.Context, householdID uuid.UUID, keep string, merge string) (int64, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+const selectBooks = `
+	SELECT b.book_id, b.title, b.authors, b.isbn, b.cover_id,
+		(SELECT count(*) FROM copies c WHERE c.book_id = b.book_id) AS copies
+	FROM books b
+`
+
+func (r *repository) GetHouseholds(ctx context.Context) ([]uuid.UUID, error) {
+	households := make([]uuid.UUID, 0)
+	query := `SELECT DISTINCT household_id FROM books`
+
+	err := r.db.SelectContext(ctx, &households, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return households, nil
+}
+
+func (r *repository) GetBooks(ctx context.Context, householdID uuid.UUID) ([]entities.Book, error) {
+	books := make([]entities.Book, 0)
+	query := selectBooks + `WHERE b.household_id = $1 ORDER BY b.created_at`
+
+	err := r.db.SelectContext(ctx, &books, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *repository) GetBooksByID(ctx context.Context, householdID uuid.UUID, bookIDs []uuid.UUID) ([]entities.Book, error) {
+	books := make([]entities.Book, 0)
+	query := selectBooks + `WHERE b.household_id = $1 AND b.book_id = ANY($2::uuid[])`
+
+	err := r.db.SelectContext(ctx, &books, query, householdID, idArray(bookIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *repository) GetAuthors(ctx context.Context, householdID uuid.UUID) ([]entities.Author, error) {
+	authors := make([]entities.Author, 0)
+	query := `
+		SELECT a.name, count(*) AS books
+		FROM books b, unnest(b.authors) AS a(name)
+		WHERE b.household_id = $1
+		GROUP BY a.name
+		ORDER BY a.name
+	`
+
+	err := r.db.SelectContext(ctx, &authors, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
+
+func (r *repository) DeletePending(ctx context.Context, householdID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM duplicate_books WHERE household_id = $1 AND NOT dismissed`, householdID)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `DELETE FROM duplicate_authors WHERE household_id = $1 AND NOT dismissed`, householdID)
+	return err
+}
+
+func (r *repository) AddBookPairs(ctx context.Context, pairs []entities.BookPair) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO duplicate_books (household_id, book_a, book_b, score, reasons, detected_at)
+		VALUES (:household_id, :book_a, :book_b, :score, :reasons, :detected_at)
+		ON CONFLICT (book_a, book_b) DO NOTHING
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, pairs)
+	return err
+}
+
+func (r *repository) AddAuthorPairs(ctx context.Context, pairs []entities.AuthorPair) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO duplicate_authors (household_id, author_a, author_b, score, detected_at)
+		VALUES (:household_id, :author_a, :author_b, :score, :detected_at)
+		ON CONFLICT (household_id, author_a, author_b) DO NOTHING
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, pairs)
+	return err
+}
+
+func (r *repository) GetBookPairs(ctx context.Context, householdID uuid.UUID) ([]entities.BookPair, error) {
+	pairs := make([]entities.BookPair, 0)
+	query := `
+		SELECT * FROM duplicate_books
+		WHERE household_id = $1 AND NOT dismissed
+		ORDER BY score DESC, detected_at
+		LIMIT $2
+	`
+
+	err := r.db.SelectContext(ctx, &pairs, query, householdID, pairsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+func (r *repository) GetAuthorPairs(ctx context.Context, householdID uuid.UUID) ([]entities.AuthorPair, error) {
+	pairs := make([]entities.AuthorPair, 0)
+	query := `
+		SELECT * FROM duplicate_authors
+		WHERE household_id = $1 AND NOT dismissed
+		ORDER BY score DESC, detected_at
+		LIMIT $2
+	`
+
+	err := r.db.SelectContext(ctx, &pairs, query, householdID, pairsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+func (r *repository) DismissBookPair(ctx context.Context, householdID uuid.UUID, a uuid.UUID, b uuid.UUID) error {
+	query := `UPDATE duplicate_books SET dismissed = TRUE WHERE household_id = $1 AND book_a = $2 AND book_b = $3`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, a, b)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) DismissAuthorPair(ctx context.Context, householdID uuid.UUID, a string, b string) error {
+	query := `UPDATE duplicate_authors SET dismissed = TRUE WHERE household_id = $1 AND author_a = $2 AND author_b = $3`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, a, b)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) MergeBooks(ctx context.Context, householdID uuid.UUID, keepID uuid.UUID, mergeID uuid.UUID) error {
+	statements := []string{
+		// The kept book takes what it lacks from the merged one; the series
+		// comes with its index.
+		`UPDATE books k SET
+			authors = CASE WHEN cardinality(k.authors) = 0 THEN m.authors ELSE k.authors END,
+			isbn = CASE WHEN k.isbn = '' THEN m.isbn ELSE k.isbn END,
+			language = CASE WHEN k.language = '' THEN m.language ELSE k.language END,
+			publisher = CASE WHEN k.publisher = '' THEN m.publisher ELSE k.publisher END,
+			published_year = COALESCE(k.published_year, m.published_year),
+			pages = COALESCE(k.pages, m.pages),
+			series = CASE WHEN k.series = '' THEN m.series ELSE k.series END,
+			series_index = CASE WHEN k.series = '' THEN m.series_index ELSE k.series_index END,
+			tags = k.tags || ARRAY(SELECT t FROM unnest(m.tags) AS t WHERE t <> ALL (k.tags)),
+			description = CASE WHEN k.description = '' THEN m.description ELSE k.description END,
+			cover_id = COALESCE(k.cover_id, m.cover_id),
+			updated_at = NOW()
+		FROM books m
+		WHERE k.household_id = $1 AND k.book_id = $2 AND m.household_id = $1 AND m.book_id = $3`,
+		`UPDATE copies SET book_id = $2, updated_at = NOW() WHERE household_id = $1 AND book_id = $3`,
+		`UPDATE readings SET book_id = $2, updated_at = NOW() WHERE household_id = $1 AND book_id = $3`,
+		// A member who reviewed both books keeps the review of the kept one.
+		`DELETE FROM reviews m
+		WHERE m.household_id = $1 AND m.book_id = $3
+			AND EXISTS (SELECT 1 FROM reviews k WHERE k.book_id = $2 AND k.user_id = m.user_id)`,
+		`UPDATE reviews SET book_id = $2 WHERE household_id = $1 AND book_id = $3`,
+		`UPDATE book_import_sources SET book_id = $2 WHERE household_id = $1 AND book_id = $3`,
+		// Ebook files and quotes carry no household; the merged book stands
+		// for it.
+		`UPDATE ebook_files f SET book_id = $2, updated_at = NOW()
+		FROM books m
+		WHERE m.household_id = $1 AND m.book_id = $3 AND f.book_id = m.book_id`,
+		`UPDATE quotes q SET book_id = $2
+		FROM books m
+		WHERE m.household_id = $1 AND m.book_id = $3 AND q.book_id = m.book_id`,
+	}
+	for _, statement := range statements {
+		if _, err := r.db.ExecContext(ctx, statement, householdID, keepID, mergeID); err != nil {
+			return err
+		}
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM books WHERE household_id = $1 AND book_id = $2`, householdID, mergeID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) MergeAuthors(ctx context.Context, householdID uuid.UUID, keep string, merge string) (int64, error) {
+	// A book already listing the kept name only loses the merged one.
+	query := `
+		UPDATE books
+		SET authors = CASE WHEN $3 = ANY (authors) THEN array_remove(authors, $2) ELSE array_replace(authors, $2, $3) END,
+			updated_at = NOW()
+		WHERE household_id = $1 AND $2 = ANY (authors)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, merge, keep)
+	if err != nil {
+		return 0, err
+	}
+
+	merged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	query = `DELETE FROM duplicate_authors WHERE household_id = $1 AND (author_a = $2 OR author_b = $2)`
+	if _, err := r.db.ExecContext(ctx, query, householdID, merge); err != nil {
+		return 0, err
+	}
+
+	return merged, nil
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func idArray(ids []uuid.UUID) pq.StringArray {
+	array := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		array[i] = id.String()
+	}
+	return array
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestMergeBooks(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("everything moves to the kept book", func(t *testing.T) {
+		householdID, keepID, mergeID := uuid.New(), uuid.New(), uuid.New()
+
+		for _, statement := range []string{
+			`UPDATE books k SET .+ FROM books m WHERE k.household_id = \$1 AND k.book_id = \$2`,
+			`UPDATE copies SET book_id = \$2`,
+			`UPDATE readings SET book_id = \$2`,
+			`DELETE FROM reviews m`,
+			`UPDATE reviews SET book_id = \$2`,
+			`UPDATE book_import_sources SET book_id = \$2`,
+			`UPDATE ebook_files f SET book_id = \$2`,
+			`UPDATE quotes q SET book_id = \$2`,
+		} {
+			mock.ExpectExec(statement).WithArgs(householdID, keepID, mergeID).WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectExec(`DELETE FROM books WHERE household_id = \$1 AND book_id = \$2`).
+			WithArgs(householdID, mergeID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.MergeBooks(context.Background(), householdID, keepID, mergeID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("merged book already gone", func(t *testing.T) {
+		householdID, keepID, mergeID := uuid.New(), uuid.New(), uuid.New()
+
+		for i := 0; i < 8; i++ {
+			mock.ExpectExec(`.+`).WithArgs(householdID, keepID, mergeID).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectExec(`DELETE FROM books`).
+			WithArgs(householdID, mergeID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.MergeBooks(context.Background(), householdID, keepID, mergeID)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMergeAuthors(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	householdID := uuid.New()
+	mock.ExpectExec(`UPDATE books SET authors = CASE WHEN \$3 = ANY \(authors\) THEN array_remove\(authors, \$2\) ELSE array_replace\(authors, \$2, \$3\) END`).
+		WithArgs(householdID, "M. Bulgakov", "Михаил Булгаков").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`DELETE FROM duplicate_authors WHERE household_id = \$1 AND \(author_a = \$2 OR author_b = \$2\)`).
+		WithArgs(householdID, "M. Bulgakov").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	merged, err := repo.MergeAuthors(context.Background(), householdID, "Михаил Булгаков", "M. Bulgakov")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), merged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
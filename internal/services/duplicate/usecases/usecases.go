@@ -0,0 +1,355 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	coverUseCases "home-library/internal/services/cover/usecases"
+	"home-library/internal/services/duplicate/dtos"
+	"home-library/internal/services/duplicate/entities"
+	"home-library/internal/services/duplicate/repository"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/pkg/dedupe"
+	"home-library/pkg/errors"
+	"home-library/pkg/jobs"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// DetectJob is the kind of the job looking for duplicates in a household.
+const DetectJob = "detect_duplicates"
+
+const (
+	// maxPairs bounds the candidates of each kind a run keeps, the best ones.
+	maxPairs = 500
+
+	detectTimeout  = 10 * time.Minute
+	detectAttempts = 3
+)
+
+// Queue is the part of jobs.Queue the detector needs.
+type Queue interface {
+	Enqueue(ctx context.Context, kind string, payload any, opts jobs.Options) (uuid.UUID, error)
+}
+
+type UseCase interface {
+	GetBookDuplicates(ctx context.Context, userID uuid.UUID) ([]dtos.BookPairResponse, error)
+	GetAuthorDuplicates(ctx context.Context, userID uuid.UUID) ([]dtos.AuthorPairResponse, error)
+	// Detect queues a detection run for the user's household.
+	Detect(ctx context.Context, userID uuid.UUID) (uuid.UUID, error)
+	// DetectAll queues a detection run for every household with books; the
+	// scheduler calls it.
+	DetectAll(ctx context.Context) error
+	MergeBooks(ctx context.Context, userID uuid.UUID, payload dtos.MergeBooksRequest) error
+	MergeAuthors(ctx context.Context, userID uuid.UUID, payload dtos.MergeAuthorsRequest) error
+	DismissBooks(ctx context.Context, userID uuid.UUID, payload dtos.DismissBooksRequest) error
+	DismissAuthors(ctx context.Context, userID uuid.UUID, payload dtos.DismissAuthorsRequest) error
+	// Run is the handler of DetectJob.
+	Run(ctx context.Context, job *jobs.Job) error
+}
+
+type detectPayload struct {
+	HouseholdID uuid.UUID `json:"household_id"`
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	covers     coverUseCases.UseCase
+	queue      Queue
+	tx         transaction.Transactor
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository, covers coverUseCases.UseCase, queue Queue, tx transaction.Transactor) UseCase {
+	return &useCase{r: r, households: households, covers: covers, queue: queue, tx: tx}
+}
+
+func (u *useCase) GetBookDuplicates(ctx context.Context, userID uuid.UUID) ([]dtos.BookPairResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs, err := u.r.GetBookPairs(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookIDs := make([]uuid.UUID, 0, 2*len(pairs))
+	for _, pair := range pairs {
+		bookIDs = append(bookIDs, pair.BookA, pair.BookB)
+	}
+	books, err := u.r.GetBooksByID(ctx, member.HouseholdID, bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]entities.Book, len(books))
+	for _, book := range books {
+		byID[book.BookID] = book
+	}
+
+	response := make([]dtos.BookPairResponse, len(pairs))
+	for i, pair := range pairs {
+		response[i] = dtos.BookPairResponse{
+			A:          dtos.NewBookItem(byID[pair.BookA]),
+			B:          dtos.NewBookItem(byID[pair.BookB]),
+			Score:      pair.Score,
+			Reasons:    pair.Reasons,
+			DetectedAt: pair.DetectedAt,
+		}
+	}
+
+	return response, nil
+}
+
+func (u *useCase) GetAuthorDuplicates(ctx context.Context, userID uuid.UUID) ([]dtos.AuthorPairResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs, err := u.r.GetAuthorPairs(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := u.r.GetAuthors(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+	books := make(map[string]int, len(authors))
+	for _, author := range authors {
+		books[author.Name] = author.Books
+	}
+
+	// A name no book carries any more, since the last run, is left out.
+	response := make([]dtos.AuthorPairResponse, 0, len(pairs))
+	for _, pair := range pairs {
+		if books[pair.AuthorA] == 0 || books[pair.AuthorB] == 0 {
+			continue
+		}
+		response = append(response, dtos.AuthorPairResponse{
+			A:          dtos.AuthorItem{Name: pair.AuthorA, Books: books[pair.AuthorA]},
+			B:          dtos.AuthorItem{Name: pair.AuthorB, Books: books[pair.AuthorB]},
+			Score:      pair.Score,
+			DetectedAt: pair.DetectedAt,
+		})
+	}
+
+	return response, nil
+}
+
+func (u *useCase) Detect(ctx context.Context, userID uuid.UUID) (uuid.UUID, error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return u.enqueue(ctx, member.HouseholdID)
+}
+
+func (u *useCase) DetectAll(ctx context.Context) error {
+	households, err := u.r.GetHouseholds(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, householdID := range households {
+		if _, err := u.enqueue(ctx, householdID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *useCase) enqueue(ctx context.Context, householdID uuid.UUID) (uuid.UUID, error) {
+	return u.queue.Enqueue(ctx, DetectJob, detectPayload{HouseholdID: householdID}, jobs.Options{
+		MaxAttempts: detectAttempts,
+		Timeout:     detectTimeout,
+	})
+}
+
+func (u *useCase) Run(ctx context.Context, job *jobs.Job) error {
+	var payload detectPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	books, err := u.r.GetBooks(ctx, payload.HouseholdID)
+	if err != nil {
+		return err
+	}
+	authors, err := u.r.GetAuthors(ctx, payload.HouseholdID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	bookPairs := detectBooks(payload.HouseholdID, books, now)
+	authorPairs := detectAuthors(payload.HouseholdID, authors, now)
+
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		if err := u.r.DeletePending(ctx, payload.HouseholdID); err != nil {
+			return err
+		}
+		if err := u.r.AddBookPairs(ctx, bookPairs); err != nil {
+			return err
+		}
+		return u.r.AddAuthorPairs(ctx, authorPairs)
+	})
+}
+
+func detectBooks(householdID uuid.UUID, books []entities.Book, now time.Time) []entities.BookPair {
+	candidates := make([]dedupe.Book, len(books))
+	for i, book := range books {
+		candidates[i] = dedupe.Book{ID: book.BookID.String(), Title: book.Title, Authors: book.Authors}
+		if book.ISBN != "" {
+			candidates[i].ISBNs = []string{book.ISBN}
+		}
+	}
+
+	found := dedupe.Books(candidates, dedupe.DefaultThreshold)
+	pairs := make([]entities.BookPair, 0, min(len(found), maxPairs))
+	for _, pair := range found[:min(len(found), maxPairs)] {
+		reasons := make([]string, len(pair.Reasons))
+		for i, reason := range pair.Reasons {
+			reasons[i] = string(reason)
+		}
+		pairs = append(pairs, entities.NewBookPair(householdID, uuid.MustParse(pair.A), uuid.MustParse(pair.B), pair.Score, reasons, now))
+	}
+	return pairs
+}
+
+func detectAuthors(householdID uuid.UUID, authors []entities.Author, now time.Time) []entities.AuthorPair {
+	candidates := make([]dedupe.Author, len(authors))
+	for i, author := range authors {
+		candidates[i] = dedupe.Author{ID: author.Name, Name: author.Name}
+	}
+
+	found := dedupe.Authors(candidates, dedupe.DefaultThreshold)
+	pairs := make([]entities.AuthorPair, 0, min(len(found), maxPairs))
+	for _, pair := range found[:min(len(found), maxPairs)] {
+		pairs = append(pairs, entities.NewAuthorPair(householdID, pair.A, pair.B, pair.Score, now))
+	}
+	return pairs
+}
+
+func (u *useCase) MergeBooks(ctx context.Context, userID uuid.UUID, payload dtos.MergeBooksRequest) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if payload.KeepID == payload.MergeID {
+		return errors.ErrMergeSelf
+	}
+
+	var orphan *uuid.UUID
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		books, err := u.r.GetBooksByID(ctx, member.HouseholdID, []uuid.UUID{payload.KeepID, payload.MergeID})
+		if err != nil {
+			return err
+		}
+		if len(books) != 2 {
+			return errors.ErrBookNotFound
+		}
+
+		var keep, merge entities.Book
+		for _, book := range books {
+			if book.BookID == payload.KeepID {
+				keep = book
+			} else {
+				merge = book
+			}
+		}
+
+		// The kept book takes the merged one's cover only if it has none.
+		orphan = nil
+		if keep.CoverID != nil {
+			orphan = merge.CoverID
+		}
+
+		return mapNoRows(u.r.MergeBooks(ctx, member.HouseholdID, payload.KeepID, payload.MergeID), errors.ErrBookNotFound)
+	})
+	if err != nil {
+		return err
+	}
+
+	if orphan != nil {
+		if err := u.covers.DeleteCover(ctx, *orphan); err != nil {
+			log.Error().Err(err).Str("cover_id", orphan.String()).Msg("failed to delete merged book cover")
+		}
+	}
+
+	return nil
+}
+
+func (u *useCase) MergeAuthors(ctx context.Context, userID uuid.UUID, payload dtos.MergeAuthorsRequest) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		merged, err := u.r.MergeAuthors(ctx, member.HouseholdID, payload.Keep, payload.Merge)
+		if err != nil {
+			return err
+		}
+		if merged == 0 {
+			return errors.ErrAuthorNotFound
+		}
+		return nil
+	})
+}
+
+func (u *useCase) DismissBooks(ctx context.Context, userID uuid.UUID, payload dtos.DismissBooksRequest) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	pair := entities.NewBookPair(member.HouseholdID, payload.A, payload.B, 0, nil, time.Time{})
+	err = u.r.DismissBookPair(ctx, member.HouseholdID, pair.BookA, pair.BookB)
+	return mapNoRows(err, errors.ErrDuplicateNotFound)
+}
+
+func (u *useCase) DismissAuthors(ctx context.Context, userID uuid.UUID, payload dtos.DismissAuthorsRequest) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	pair := entities.NewAuthorPair(member.HouseholdID, payload.A, payload.B, 0, time.Time{})
+	err = u.r.DismissAuthorPair(ctx, member.HouseholdID, pair.AuthorA, pair.AuthorB)
+	return mapNoRows(err, errors.ErrDuplicateNotFound)
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+func (u *useCase) editor(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanEditLibrary() {
+		return nil, errors.ErrHouseholdForbidden
+	}
+	return member, nil
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
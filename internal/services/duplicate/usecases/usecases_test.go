@@ -0,0 +1,393 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	coverDtos "home-library/internal/services/cover/dtos"
+	coverEntities "home-library/internal/services/cover/entities"
+	"home-library/internal/services/duplicate/dtos"
+	"home-library/internal/services/duplicate/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/pkg/blobstore"
+	"home-library/pkg/errors"
+	"home-library/pkg/jobs"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetHouseholds(ctx context.Context) ([]uuid.UUID, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetBooks(ctx context.Context, householdID uuid.UUID) ([]entities.Book, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Book), args.Error(1)
+}
+
+func (m *MockRepository) GetBooksByID(ctx context.Context, householdID uuid.UUID, bookIDs []uuid.UUID) ([]entities.Book, error) {
+	args := m.Called(ctx, householdID, bookIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Book), args.Error(1)
+}
+
+func (m *MockRepository) GetAuthors(ctx context.Context, householdID uuid.UUID) ([]entities.Author, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Author), args.Error(1)
+}
+
+func (m *MockRepository) DeletePending(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockRepository) AddBookPairs(ctx context.Context, pairs []entities.BookPair) error {
+	return m.Called(ctx, pairs).Error(0)
+}
+
+func (m *MockRepository) AddAuthorPairs(ctx context.Context, pairs []entities.AuthorPair) error {
+	return m.Called(ctx, pairs).Error(0)
+}
+
+func (m *MockRepository) GetBookPairs(ctx context.Context, householdID uuid.UUID) ([]entities.BookPair, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.BookPair), args.Error(1)
+}
+
+func (m *MockRepository) GetAuthorPairs(ctx context.Context, householdID uuid.UUID) ([]entities.AuthorPair, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.AuthorPair), args.Error(1)
+}
+
+func (m *MockRepository) DismissBookPair(ctx context.Context, householdID uuid.UUID, a uuid.UUID, b uuid.UUID) error {
+	return m.Called(ctx, householdID, a, b).Error(0)
+}
+
+func (m *MockRepository) DismissAuthorPair(ctx context.Context, householdID uuid.UUID, a string, b string) error {
+	return m.Called(ctx, householdID, a, b).Error(0)
+}
+
+func (m *MockRepository) MergeBooks(ctx context.Context, householdID uuid.UUID, keepID uuid.UUID, mergeID uuid.UUID) error {
+	return m.Called(ctx, householdID, keepID, mergeID).Error(0)
+}
+
+func (m *MockRepository) MergeAuthors(ctx context.Context, householdID uuid.UUID, keep string, merge string) (int64, error) {
+	args := m.Called(ctx, householdID, keep, merge)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+type MockCoverUseCase struct {
+	mock.Mock
+}
+
+func (m *MockCoverUseCase) UploadCover(ctx context.Context, userID uuid.UUID, data []byte) (*coverDtos.CoverResponse, error) {
+	args := m.Called(ctx, userID, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*coverDtos.CoverResponse), args.Error(1)
+}
+
+func (m *MockCoverUseCase) GetCover(ctx context.Context, coverID uuid.UUID, variant coverEntities.Variant) (*blobstore.Object, error) {
+	args := m.Called(ctx, coverID, variant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*blobstore.Object), args.Error(1)
+}
+
+func (m *MockCoverUseCase) DeleteCover(ctx context.Context, coverID uuid.UUID) error {
+	return m.Called(ctx, coverID).Error(0)
+}
+
+type MockQueue struct {
+	mock.Mock
+}
+
+func (m *MockQueue) Enqueue(ctx context.Context, kind string, payload any, opts jobs.Options) (uuid.UUID, error) {
+	args := m.Called(ctx, kind, payload, opts)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+// passthroughTx runs the unit of work directly.
+type passthroughTx struct{}
+
+func (passthroughTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	households *MockHouseholdRepository
+	covers     *MockCoverUseCase
+	queue      *MockQueue
+}
+
+func newUseCase() (UseCase, mocks) {
+	m := mocks{new(MockRepository), new(MockHouseholdRepository), new(MockCoverUseCase), new(MockQueue)}
+	return NewUseCase(m.repo, m.households, m.covers, m.queue, passthroughTx{}), m
+}
+
+func (m mocks) member(userID uuid.UUID, householdID uuid.UUID, role householdEntities.Role) {
+	m.households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: role}, nil)
+}
+
+func TestRun(t *testing.T) {
+	u, m := newUseCase()
+	householdID := uuid.New()
+	first, second, other := uuid.New(), uuid.New(), uuid.New()
+
+	m.repo.On("GetBooks", mock.Anything, householdID).Return([]entities.Book{
+		{BookID: first, Title: "Мастер и Маргарита", Authors: pq.StringArray{"Михаил Булгаков"}, ISBN: "9785170987658"},
+		{BookID: second, Title: "The Master and Margarita", Authors: pq.StringArray{"Mikhail Bulgakov"}, ISBN: "5-17-098765-X"},
+		{BookID: other, Title: "Белая гвардия", Authors: pq.StringArray{"Михаил Булгаков"}},
+	}, nil)
+	m.repo.On("GetAuthors", mock.Anything, householdID).Return([]entities.Author{
+		{Name: "Mikhail Bulgakov", Books: 1},
+		{Name: "Михаил Булгаков", Books: 2},
+	}, nil)
+	m.repo.On("DeletePending", mock.Anything, householdID).Return(nil)
+	m.repo.On("AddBookPairs", mock.Anything, mock.MatchedBy(func(pairs []entities.BookPair) bool {
+		return len(pairs) == 1 && pairs[0].BookA.String() < pairs[0].BookB.String() &&
+			pairs[0].Score == 1 && pairs[0].Reasons[0] == "isbn" && pairs[0].HouseholdID == householdID
+	})).Return(nil)
+	m.repo.On("AddAuthorPairs", mock.Anything, mock.MatchedBy(func(pairs []entities.AuthorPair) bool {
+		return len(pairs) == 1 && pairs[0].AuthorA == "Mikhail Bulgakov" && pairs[0].AuthorB == "Михаил Булгаков"
+	})).Return(nil)
+
+	payload, _ := json.Marshal(detectPayload{HouseholdID: householdID})
+	err := u.Run(context.Background(), &jobs.Job{Kind: DetectJob, Payload: payload})
+
+	require.NoError(t, err)
+	m.repo.AssertExpectations(t)
+}
+
+func TestDetect(t *testing.T) {
+	t.Run("editor queues a run", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, jobID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.queue.On("Enqueue", mock.Anything, DetectJob, detectPayload{HouseholdID: householdID}, mock.Anything).Return(jobID, nil)
+
+		id, err := u.Detect(context.Background(), userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, jobID, id)
+	})
+
+	t.Run("viewers cannot", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+		m.member(userID, uuid.New(), householdEntities.RoleViewer)
+
+		_, err := u.Detect(context.Background(), userID)
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+	})
+}
+
+func TestMergeBooks(t *testing.T) {
+	t.Run("cover of the merged book is dropped when the kept one has its own", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, keepID, mergeID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		keepCover, mergeCover := uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetBooksByID", mock.Anything, householdID, []uuid.UUID{keepID, mergeID}).Return([]entities.Book{
+			{BookID: mergeID, CoverID: &mergeCover},
+			{BookID: keepID, CoverID: &keepCover},
+		}, nil)
+		m.repo.On("MergeBooks", mock.Anything, householdID, keepID, mergeID).Return(nil)
+		m.covers.On("DeleteCover", mock.Anything, mergeCover).Return(nil)
+
+		err := u.MergeBooks(context.Background(), userID, dtos.MergeBooksRequest{KeepID: keepID, MergeID: mergeID})
+
+		require.NoError(t, err)
+		m.covers.AssertExpectations(t)
+	})
+
+	t.Run("kept book without a cover takes the merged one's", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, keepID, mergeID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		mergeCover := uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetBooksByID", mock.Anything, householdID, []uuid.UUID{keepID, mergeID}).Return([]entities.Book{
+			{BookID: keepID},
+			{BookID: mergeID, CoverID: &mergeCover},
+		}, nil)
+		m.repo.On("MergeBooks", mock.Anything, householdID, keepID, mergeID).Return(nil)
+
+		err := u.MergeBooks(context.Background(), userID, dtos.MergeBooksRequest{KeepID: keepID, MergeID: mergeID})
+
+		require.NoError(t, err)
+		m.covers.AssertNotCalled(t, "DeleteCover", mock.Anything, mock.Anything)
+	})
+
+	t.Run("book of another household is not found", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, keepID, mergeID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetBooksByID", mock.Anything, householdID, []uuid.UUID{keepID, mergeID}).
+			Return([]entities.Book{{BookID: keepID}}, nil)
+
+		err := u.MergeBooks(context.Background(), userID, dtos.MergeBooksRequest{KeepID: keepID, MergeID: mergeID})
+
+		assert.ErrorIs(t, err, errors.ErrBookNotFound)
+		m.repo.AssertNotCalled(t, "MergeBooks", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("book cannot be merged into itself", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, bookID := uuid.New(), uuid.New()
+		m.member(userID, uuid.New(), householdEntities.RoleOwner)
+
+		err := u.MergeBooks(context.Background(), userID, dtos.MergeBooksRequest{KeepID: bookID, MergeID: bookID})
+
+		assert.ErrorIs(t, err, errors.ErrMergeSelf)
+	})
+}
+
+func TestMergeAuthors(t *testing.T) {
+	u, m := newUseCase()
+	userID, householdID := uuid.New(), uuid.New()
+	m.member(userID, householdID, householdEntities.RoleEditor)
+
+	m.repo.On("MergeAuthors", mock.Anything, householdID, "Михаил Булгаков", "M. Bulgakov").Return(int64(0), nil)
+
+	err := u.MergeAuthors(context.Background(), userID, dtos.MergeAuthorsRequest{Keep: "Михаил Булгаков", Merge: "M. Bulgakov"})
+
+	assert.ErrorIs(t, err, errors.ErrAuthorNotFound)
+}
+
+func TestDismissBooks(t *testing.T) {
+	u, m := newUseCase()
+	userID, householdID := uuid.New(), uuid.New()
+	a, b := uuid.MustParse("00000000-0000-0000-0000-000000000001"), uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	m.member(userID, householdID, householdEntities.RoleEditor)
+
+	m.repo.On("DismissBookPair", mock.Anything, householdID, a, b).Return(nil)
+
+	err := u.DismissBooks(context.Background(), userID, dtos.DismissBooksRequest{A: b, B: a})
+
+	assert.NoError(t, err)
+}
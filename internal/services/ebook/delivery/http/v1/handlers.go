@@ -0,0 +1,220 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/ebook/dtos"
+	"home-library/internal/services/ebook/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) UploadFile(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Файл книги не передан", nil))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to open uploaded ebook")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+	defer src.Close()
+
+	ebook, created, err := h.u.UploadFile(c.Request().Context(), userID, file.Filename, src, file.Size)
+	if err != nil {
+		return h.handleError(c, err, "failed to upload ebook")
+	}
+
+	if !created {
+		return c.JSON(http.StatusOK, ebook)
+	}
+	return c.JSON(http.StatusCreated, ebook)
+}
+
+func (h *handler) GetFiles(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	files, err := h.u.GetFiles(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get ebooks")
+	}
+
+	return c.JSON(http.StatusOK, files)
+}
+
+func (h *handler) GetFile(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	file, err := h.u.GetFile(c.Request().Context(), userID, fileID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get ebook")
+	}
+
+	return c.JSON(http.StatusOK, file)
+}
+
+func (h *handler) UpdateFile(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.UpdateEbookRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.UpdateFile(c.Request().Context(), userID, fileID, payload); err != nil {
+		return h.handleError(c, err, "failed to update ebook")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) AttachFile(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.AttachEbookRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.AttachFile(c.Request().Context(), userID, fileID, payload); err != nil {
+		return h.handleError(c, err, "failed to attach ebook")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) DetachFile(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.DetachFile(c.Request().Context(), userID, fileID); err != nil {
+		return h.handleError(c, err, "failed to detach ebook")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) DeleteFile(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.DeleteFile(c.Request().Context(), userID, fileID); err != nil {
+		return h.handleError(c, err, "failed to delete ebook")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) DownloadFile(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	file, object, err := h.u.OpenFile(c.Request().Context(), userID, fileID)
+	if err != nil {
+		return h.handleError(c, err, "failed to open ebook")
+	}
+	defer object.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": file.DownloadName()}))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(object.Size, 10))
+	header.Set("ETag", strconv.Quote(file.SHA256))
+
+	return c.Stream(http.StatusOK, file.Format.ContentType(), object)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrEbookNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Файл книги не найден", nil))
+	case errors.Is(err, customErrors.ErrBookNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Книга не найдена", nil))
+	case errors.Is(err, customErrors.ErrEbookTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, dtos.NewErrorResponse(http.StatusRequestEntityTooLarge, "Файл слишком большой", nil))
+	case errors.Is(err, customErrors.ErrEbookUnsupported):
+		return c.JSON(http.StatusUnsupportedMediaType, dtos.NewErrorResponse(http.StatusUnsupportedMediaType, "Поддерживаются только EPUB, PDF и FB2", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
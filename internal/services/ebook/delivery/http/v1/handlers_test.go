@@ -0,0 +1,311 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"home-library/internal/services/ebook/dtos"
+	"home-library/internal/services/ebook/entities"
+	"home-library/internal/services/ebook/usecases"
+	"home-library/pkg/blobstore"
+	customErrors "home-library/pkg/errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) UploadFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (*dtos.EbookResponse, bool, error) {
+	args := m.Called(ctx, userID, name, r, size)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*dtos.EbookResponse), args.Bool(1), args.Error(2)
+}
+
+func (m *MockUseCase) StageFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (*usecases.StagedFile, error) {
+	data, _ := io.ReadAll(io.NewSectionReader(r, 0, size))
+	args := m.Called(ctx, userID, name, string(data))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecases.StagedFile), args.Error(1)
+}
+
+func (m *MockUseCase) SaveFile(ctx context.Context, staged *usecases.StagedFile) (*dtos.EbookResponse, bool, error) {
+	args := m.Called(ctx, staged)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*dtos.EbookResponse), args.Bool(1), args.Error(2)
+}
+
+func (m *MockUseCase) DiscardFile(ctx context.Context, staged *usecases.StagedFile) {
+	m.Called(ctx, staged)
+}
+
+func (m *MockUseCase) GetFiles(ctx context.Context, viewerID uuid.UUID) ([]dtos.EbookResponse, error) {
+	args := m.Called(ctx, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.EbookResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*dtos.EbookResponse, error) {
+	args := m.Called(ctx, viewerID, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.EbookResponse), args.Error(1)
+}
+
+func (m *MockUseCase) UpdateFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload dtos.UpdateEbookRequest) error {
+	return m.Called(ctx, userID, fileID, payload).Error(0)
+}
+
+func (m *MockUseCase) AttachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload dtos.AttachEbookRequest) error {
+	return m.Called(ctx, userID, fileID, payload).Error(0)
+}
+
+func (m *MockUseCase) DetachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	return m.Called(ctx, userID, fileID).Error(0)
+}
+
+func (m *MockUseCase) DeleteFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	return m.Called(ctx, userID, fileID).Error(0)
+}
+
+func (m *MockUseCase) OpenFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*entities.EbookFile, *blobstore.Object, error) {
+	args := m.Called(ctx, viewerID, fileID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entities.EbookFile), args.Get(1).(*blobstore.Object), args.Error(2)
+}
+
+func newContext(e *echo.Echo, method string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func newUploadContext(t *testing.T, e *echo.Echo, name string, content []byte, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", name)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/ebooks", &body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", userID)
+	return c, rec
+}
+
+func TestUploadFile(t *testing.T) {
+	e := echo.New()
+
+	t.Run("new file", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, fileID := uuid.New(), uuid.New()
+
+		mockUseCase.On("UploadFile", mock.Anything, userID, "book.epub", mock.Anything, int64(4)).
+			Return(&dtos.EbookResponse{FileID: fileID, Title: "Трудно быть богом"}, true, nil)
+
+		c, rec := newUploadContext(t, e, "book.epub", []byte("PK.."), userID)
+		err := h.UploadFile(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response dtos.EbookResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, fileID, response.FileID)
+	})
+
+	t.Run("already uploaded", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("UploadFile", mock.Anything, userID, "book.epub", mock.Anything, int64(4)).
+			Return(&dtos.EbookResponse{FileID: uuid.New()}, false, nil)
+
+		c, rec := newUploadContext(t, e, "book.epub", []byte("PK.."), userID)
+		err := h.UploadFile(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"unsupported format", customErrors.ErrEbookUnsupported, http.StatusUnsupportedMediaType},
+		{"too large", customErrors.ErrEbookTooLarge, http.StatusRequestEntityTooLarge},
+		{"internal error", errors.New("storage is down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			h := NewHandler(mockUseCase)
+			userID := uuid.New()
+
+			mockUseCase.On("UploadFile", mock.Anything, userID, mock.Anything, mock.Anything, mock.Anything).Return(nil, false, tt.err)
+
+			c, rec := newUploadContext(t, e, "book.txt", []byte("text"), userID)
+			err := h.UploadFile(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+}
+
+func TestUpdateFile(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully update", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, fileID := uuid.New(), uuid.New()
+		payload := dtos.UpdateEbookRequest{Title: "Улитка на склоне", Authors: []string{"Аркадий Стругацкий"}, Language: "ru"}
+
+		mockUseCase.On("UpdateFile", mock.Anything, userID, fileID, payload).Return(nil)
+
+		c, rec := newContext(e, http.MethodPut, `{"title":"Улитка на склоне","authors":["Аркадий Стругацкий"],"language":"ru"}`, userID)
+		c.SetParamNames("file_id")
+		c.SetParamValues(fileID.String())
+		err := h.UpdateFile(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, http.MethodPut, `{"title":"","authors":[""]}`, uuid.New())
+		c.SetParamNames("file_id")
+		c.SetParamValues(uuid.New().String())
+		err := h.UpdateFile(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, fileID := uuid.New(), uuid.New()
+
+		mockUseCase.On("UpdateFile", mock.Anything, userID, fileID, mock.Anything).Return(customErrors.ErrEbookNotFound)
+
+		c, rec := newContext(e, http.MethodPut, `{"title":"Улитка на склоне"}`, userID)
+		c.SetParamNames("file_id")
+		c.SetParamValues(fileID.String())
+		err := h.UpdateFile(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestDownloadFile(t *testing.T) {
+	e := echo.New()
+
+	t.Run("streams file", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+		file := entities.NewEbookFile(uuid.New(), strings.Repeat("a", 64), entities.FormatEPUB, 4)
+		file.Title = "Пикник на обочине"
+
+		mockUseCase.On("OpenFile", mock.Anything, userID, file.FileID).Return(file, &blobstore.Object{
+			ReadCloser: io.NopCloser(strings.NewReader("PK..")),
+			Info:       blobstore.Info{Size: 4},
+		}, nil)
+
+		c, rec := newContext(e, http.MethodGet, "", userID)
+		c.SetParamNames("file_id")
+		c.SetParamValues(file.FileID.String())
+		err := h.DownloadFile(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "PK..", rec.Body.String())
+		assert.Equal(t, "application/epub+zip", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "4", rec.Header().Get(echo.HeaderContentLength))
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "attachment; filename*=utf-8''")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, fileID := uuid.New(), uuid.New()
+
+		mockUseCase.On("OpenFile", mock.Anything, userID, fileID).Return(nil, nil, customErrors.ErrEbookNotFound)
+
+		c, rec := newContext(e, http.MethodGet, "", userID)
+		c.SetParamNames("file_id")
+		c.SetParamValues(fileID.String())
+		err := h.DownloadFile(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, http.MethodGet, "", uuid.Nil)
+		err := h.DownloadFile(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestDeleteFile(t *testing.T) {
+	e := echo.New()
+	mockUseCase := new(MockUseCase)
+	h := NewHandler(mockUseCase)
+	userID, fileID := uuid.New(), uuid.New()
+
+	mockUseCase.On("DeleteFile", mock.Anything, userID, fileID).Return(nil)
+
+	c, rec := newContext(e, http.MethodDelete, "", userID)
+	c.SetParamNames("file_id")
+	c.SetParamValues(fileID.String())
+	err := h.DeleteFile(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
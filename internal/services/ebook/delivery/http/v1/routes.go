@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func (h *handler) EbookRoutes(domain *echo.Group) {
+	// The limit leaves room for the multipart envelope around the file.
+	domain.POST("/ebooks", h.UploadFile, middleware.BodyLimit("201M"))
+	domain.GET("/ebooks", h.GetFiles)
+	domain.GET("/ebooks/:file_id", h.GetFile)
+	domain.PUT("/ebooks/:file_id", h.UpdateFile)
+	domain.DELETE("/ebooks/:file_id", h.DeleteFile)
+	domain.PUT("/ebooks/:file_id/book", h.AttachFile)
+	domain.DELETE("/ebooks/:file_id/book", h.DetachFile)
+	domain.GET("/ebooks/:file_id/download", h.DownloadFile)
+}
@@ -0,0 +1,87 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/ebook/entities"
+	"time"
+)
+
+type UpdateEbookRequest struct {
+	Title       string   `json:"title" validate:"required,max=255"`
+	Authors     []string `json:"authors" validate:"max=20,dive,required,max=255"`
+	Language    string   `json:"language" validate:"omitempty,bcp47_language_tag"`
+	ISBN        string   `json:"isbn" validate:"omitempty,isbn"`
+	Description string   `json:"description" validate:"max=10000"`
+	Series      string   `json:"series" validate:"max=255"`
+	SeriesIndex *float64 `json:"series_index" validate:"omitempty,gte=0"`
+	Tags        []string `json:"tags" validate:"max=50,dive,required,max=100"`
+}
+
+func (r *UpdateEbookRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// AttachEbookRequest names the book of the catalog a file is of.
+type AttachEbookRequest struct {
+	BookID uuid.UUID `json:"book_id" validate:"required"`
+}
+
+func (r *AttachEbookRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type EbookResponse struct {
+	FileID       uuid.UUID  `json:"file_id"`
+	OwnerID      uuid.UUID  `json:"owner_id"`
+	Format       string     `json:"format"`
+	Size         int64      `json:"size"`
+	SHA256       string     `json:"sha256"`
+	OriginalName string     `json:"original_name"`
+	Title        string     `json:"title"`
+	Authors      []string   `json:"authors"`
+	Language     string     `json:"language"`
+	ISBN         string     `json:"isbn"`
+	Description  string     `json:"description"`
+	Series       string     `json:"series"`
+	SeriesIndex  *float64   `json:"series_index"`
+	Tags         []string   `json:"tags"`
+	CoverID      *uuid.UUID `json:"cover_id"`
+	BookID       *uuid.UUID `json:"book_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func NewEbookResponse(file entities.EbookFile) EbookResponse {
+	authors := []string(file.Authors)
+	if authors == nil {
+		authors = []string{}
+	}
+	tags := []string(file.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return EbookResponse{
+		FileID:       file.FileID,
+		OwnerID:      file.OwnerID,
+		Format:       string(file.Format),
+		Size:         file.Size,
+		SHA256:       file.SHA256,
+		OriginalName: file.OriginalName,
+		Title:        file.Title,
+		Authors:      authors,
+		Language:     file.Language,
+		ISBN:         file.ISBN,
+		Description:  file.Description,
+		Series:       file.Series,
+		SeriesIndex:  file.SeriesIndex,
+		Tags:         tags,
+		CoverID:      file.CoverID,
+		BookID:       file.BookID,
+		CreatedAt:    file.CreatedAt,
+		UpdatedAt:    file.UpdatedAt,
+	}
+}
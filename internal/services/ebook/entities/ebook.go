@@ -0,0 +1,119 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type Format string
+
+const (
+	FormatEPUB   Format = "epub"
+	FormatPDF    Format = "pdf"
+	FormatFB2    Format = "fb2"
+	FormatFB2Zip Format = "fb2.zip"
+)
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatEPUB:
+		return "application/epub+zip"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatFB2:
+		return "application/x-fictionbook+xml"
+	case FormatFB2Zip:
+		return "application/x-zip-compressed-fb2"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+type EbookFile struct {
+	FileID       uuid.UUID      `db:"file_id"`
+	OwnerID      uuid.UUID      `db:"owner_id"`
+	SHA256       string         `db:"sha256"`
+	Format       Format         `db:"format"`
+	Size         int64          `db:"size"`
+	OriginalName string         `db:"original_name"`
+	Title        string         `db:"title"`
+	Authors      pq.StringArray `db:"authors"`
+	Language     string         `db:"language"`
+	ISBN         string         `db:"isbn"`
+	Description  string         `db:"description"`
+	Series       string         `db:"series"`
+	SeriesIndex  *float64       `db:"series_index"`
+	Tags         pq.StringArray `db:"tags"`
+	CoverID      *uuid.UUID     `db:"cover_id"`
+	// BookID is the book of the owner's household catalog the file is of.
+	BookID    *uuid.UUID `db:"book_id"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+func NewEbookFile(ownerID uuid.UUID, sha256 string, format Format, size int64) *EbookFile {
+	now := time.Now()
+	return &EbookFile{
+		FileID:    uuid.New(),
+		OwnerID:   ownerID,
+		SHA256:    sha256,
+		Format:    format,
+		Size:      size,
+		Authors:   pq.StringArray{},
+		Tags:      pq.StringArray{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// BlobKey returns the blob store key of the file contents. Keys are derived
+// from the hash, so an upload that several users share is stored once.
+func (f *EbookFile) BlobKey() string {
+	return fmt.Sprintf("ebooks/%s/%s", f.SHA256[:2], f.SHA256)
+}
+
+// DownloadName returns the file name offered on download.
+func (f *EbookFile) DownloadName() string {
+	name := f.Title
+	if name == "" {
+		name = f.FileID.String()
+	}
+	if len(f.Authors) > 0 {
+		name = f.Authors[0] + " - " + name
+	}
+	return name + "." + string(f.Format)
+}
+
+// Filter narrows down a listing of ebook files. Zero fields do not filter.
+type Filter struct {
+	Author string
+	Series string
+	Tag    string
+	// ISBNs matches any of the numbers, so a book can be looked up by both
+	// its ISBN-10 and ISBN-13.
+	ISBNs []string
+	// Query matches a substring of the title or of an author's name.
+	Query string
+	// Recent orders by upload time, newest first, instead of by title.
+	Recent bool
+	Limit  int
+	Offset int
+}
+
+// Facet is a field ebook files are grouped by when browsing.
+type Facet string
+
+const (
+	FacetAuthor Facet = "author"
+	FacetSeries Facet = "series"
+	FacetTag    Facet = "tag"
+)
+
+// FacetValue is one group of a facet along with the number of its files.
+type FacetValue struct {
+	Name  string `db:"name"`
+	Count int    `db:"count"`
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"home-library/internal/services/ebook/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/events"
+	"home-library/pkg/storage"
+	"home-library/pkg/transaction"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	CreateFile(ctx context.Context, file *entities.EbookFile) (uuid.UUID, error)
+	GetFileByHash(ctx context.Context, ownerID uuid.UUID, sha256 string) (*entities.EbookFile, error)
+	GetSharedFile(ctx context.Context, fileID uuid.UUID, viewerID uuid.UUID) (*entities.EbookFile, error)
+	GetSharedFiles(ctx context.Context, viewerID uuid.UUID) ([]entities.EbookFile, error)
+	FindSharedFiles(ctx context.Context, viewerID uuid.UUID, filter entities.Filter) ([]entities.EbookFile, error)
+	GetSharedFacet(ctx context.Context, viewerID uuid.UUID, facet entities.Facet) ([]entities.FacetValue, error)
+	UpdateFile(ctx context.Context, file *entities.EbookFile) error
+	// HasBook tells whether the book is in the catalog of the owner's
+	// household.
+	HasBook(ctx context.Context, ownerID uuid.UUID, bookID uuid.UUID) (bool, error)
+	// SetBook attaches the file to the book, or detaches it when bookID is
+	// nil.
+	SetBook(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID, bookID *uuid.UUID) error
+	DeleteFile(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID) (*entities.EbookFile, error)
+	HashInUse(ctx context.Context, sha256 string) (bool, error)
+}
+
+// visibleToViewer limits ebook_files aliased as e to files of the viewer
+// passed as $1 and of the other members of the viewer's household.
+const visibleToViewer = `
+	(e.owner_id = $1 OR e.owner_id IN (
+		SELECT owner_member.user_id FROM household_members owner_member
+		JOIN household_members viewer_member ON viewer_member.household_id = owner_member.household_id
+		WHERE viewer_member.user_id = $1
+	))
+`
+
+// facetColumns maps facets onto the SQL expression yielding their values.
+var facetColumns = map[entities.Facet]string{
+	entities.FacetAuthor: "unnest(e.authors)",
+	entities.FacetSeries: "NULLIF(e.series, '')",
+	entities.FacetTag:    "unnest(e.tags)",
+}
+
+// constraints translates violations of the ebook_files constraints.
+var constraints = storage.Constraints{
+	"ebook_files_owner_id_sha256_key": errors.ErrEbookExists,
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateFile(ctx context.Context, file *entities.EbookFile) (uuid.UUID, error) {
+	query := `
+		INSERT INTO ebook_files (
+			file_id, owner_id, sha256, format, size, original_name,
+			title, authors, language, isbn, description, series,
+			series_index, tags, cover_id, created_at, updated_at
+		) VALUES (
+			:file_id, :owner_id, :sha256, :format, :size, :original_name,
+			:title, :authors, :language, :isbn, :description, :series,
+			:series_index, :tags, :cover_id, :created_at, :updated_at
+		)
+	`
+
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, query, file); err != nil {
+			return err
+		}
+
+		return events.Record(ctx, tx, events.TypeEbookAdded, file.OwnerID, events.EbookAdded{
+			FileID:  file.FileID,
+			Title:   file.Title,
+			Authors: file.Authors,
+			Format:  string(file.Format),
+		})
+	})
+	if err != nil {
+		return uuid.Nil, constraints.Map(err)
+	}
+
+	return file.FileID, nil
+}
+
+func (r *repository) GetFileByHash(ctx context.Context, ownerID uuid.UUID, sha256 string) (*entities.EbookFile, error) {
+	var file entities.EbookFile
+	query := `SELECT * FROM ebook_files WHERE owner_id = $1 AND sha256 = $2`
+
+	err := r.db.GetContext(ctx, &file, query, ownerID, sha256)
+	if err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func (r *repository) GetSharedFile(ctx context.Context, fileID uuid.UUID, viewerID uuid.UUID) (*entities.EbookFile, error) {
+	var file entities.EbookFile
+	query := `
+		SELECT e.* FROM ebook_files e
+		WHERE e.file_id = $2 AND ` + visibleToViewer
+
+	err := r.db.GetContext(ctx, &file, query, viewerID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func (r *repository) GetSharedFiles(ctx context.Context, viewerID uuid.UUID) ([]entities.EbookFile, error) {
+	files := make([]entities.EbookFile, 0)
+	query := `
+		SELECT e.* FROM ebook_files e
+		WHERE ` + visibleToViewer + `
+		ORDER BY e.title, e.created_at
+	`
+
+	err := r.db.SelectContext(ctx, &files, query, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (r *repository) FindSharedFiles(ctx context.Context, viewerID uuid.UUID, filter entities.Filter) ([]entities.EbookFile, error) {
+	files := make([]entities.EbookFile, 0)
+	conditions := []string{visibleToViewer}
+	args := []interface{}{viewerID}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.Author != "" {
+		conditions = append(conditions, arg(filter.Author)+" = ANY(e.authors)")
+	}
+	if filter.Series != "" {
+		conditions = append(conditions, "e.series = "+arg(filter.Series))
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, arg(filter.Tag)+" = ANY(e.tags)")
+	}
+	if len(filter.ISBNs) > 0 {
+		conditions = append(conditions, "e.isbn = ANY("+arg(pq.StringArray(filter.ISBNs))+")")
+	}
+	if filter.Query != "" {
+		pattern := arg("%" + likeEscaper.Replace(filter.Query) + "%")
+		conditions = append(conditions, "(e.title ILIKE "+pattern+" OR array_to_string(e.authors, ' ') ILIKE "+pattern+")")
+	}
+
+	order := "e.title, e.created_at"
+	switch {
+	case filter.Recent:
+		order = "e.created_at DESC"
+	case filter.Series != "":
+		order = "e.series_index NULLS LAST, e.title"
+	}
+
+	query := `
+		SELECT e.* FROM ebook_files e
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + order
+	if filter.Limit > 0 {
+		query += " LIMIT " + arg(filter.Limit) + " OFFSET " + arg(filter.Offset)
+	}
+
+	err := r.db.SelectContext(ctx, &files, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (r *repository) GetSharedFacet(ctx context.Context, viewerID uuid.UUID, facet entities.Facet) ([]entities.FacetValue, error) {
+	column, ok := facetColumns[facet]
+	if !ok {
+		return nil, fmt.Errorf("unknown ebook facet %q", facet)
+	}
+
+	values := make([]entities.FacetValue, 0)
+	query := `
+		SELECT name, count(*) AS count FROM (
+			SELECT ` + column + ` AS name FROM ebook_files e
+			WHERE ` + visibleToViewer + `
+		) facet
+		WHERE name IS NOT NULL
+		GROUP BY name
+		ORDER BY name
+	`
+
+	err := r.db.SelectContext(ctx, &values, query, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (r *repository) UpdateFile(ctx context.Context, file *entities.EbookFile) error {
+	query := `
+		UPDATE ebook_files
+		SET title = :title, authors = :authors, language = :language,
+			isbn = :isbn, description = :description, series = :series,
+			series_index = :series_index, tags = :tags, updated_at = :updated_at
+		WHERE file_id = :file_id AND owner_id = :owner_id
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, file)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) HasBook(ctx context.Context, ownerID uuid.UUID, bookID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM books b
+			JOIN household_members m ON m.household_id = b.household_id
+			WHERE b.book_id = $2 AND m.user_id = $1
+		)
+	`
+
+	err := r.db.GetContext(ctx, &exists, query, ownerID, bookID)
+	return exists, err
+}
+
+func (r *repository) SetBook(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID, bookID *uuid.UUID) error {
+	query := `
+		UPDATE ebook_files
+		SET book_id = $3, updated_at = NOW()
+		WHERE file_id = $1 AND owner_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, fileID, ownerID, bookID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+// DeleteFile returns the deleted row so the caller can clean up its blob
+// and cover.
+func (r *repository) DeleteFile(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID) (*entities.EbookFile, error) {
+	var file entities.EbookFile
+	query := `DELETE FROM ebook_files WHERE file_id = $1 AND owner_id = $2 RETURNING *`
+
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := tx.GetContext(ctx, &file, query, fileID, ownerID); err != nil {
+			return err
+		}
+
+		return events.Record(ctx, tx, events.TypeEbookDeleted, file.OwnerID, events.EbookDeleted{
+			FileID: file.FileID,
+			Title:  file.Title,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func (r *repository) HashInUse(ctx context.Context, sha256 string) (bool, error) {
+	var inUse bool
+	query := `SELECT EXISTS (SELECT 1 FROM ebook_files WHERE sha256 = $1)`
+
+	err := r.db.GetContext(ctx, &inUse, query, sha256)
+	if err != nil {
+		return false, err
+	}
+
+	return inUse, nil
+}
+
+func (r *repository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.db.InTx(ctx, nil, fn)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"home-library/internal/services/ebook/entities"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var fileColumns = []string{
+	"file_id", "owner_id", "sha256", "format", "size", "original_name",
+	"title", "authors", "language", "isbn", "description", "series",
+	"series_index", "tags", "cover_id", "created_at", "updated_at",
+}
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func fileRow(fileID uuid.UUID, ownerID uuid.UUID, sha256 string) []driver.Value {
+	now := time.Now()
+	return []driver.Value{
+		fileID, ownerID, sha256, "epub", int64(1024), "book.epub",
+		"Пикник на обочине", "{\"Аркадий Стругацкий\",\"Борис Стругацкий\"}", "ru", "", "", "", nil, "{sf}", nil, now, now,
+	}
+}
+
+func TestCreateFile(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("create file", func(t *testing.T) {
+		file := entities.NewEbookFile(uuid.New(), strings.Repeat("a", 64), entities.FormatEPUB, 1024)
+		file.Title = "Пикник на обочине"
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO ebook_files").
+			WithArgs(
+				file.FileID,
+				file.OwnerID,
+				file.SHA256,
+				file.Format,
+				file.Size,
+				file.OriginalName,
+				file.Title,
+				file.Authors,
+				file.Language,
+				file.ISBN,
+				file.Description,
+				file.Series,
+				file.SeriesIndex,
+				file.Tags,
+				file.CoverID,
+				file.CreatedAt,
+				file.UpdatedAt,
+			).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox_events").
+			WithArgs(sqlmock.AnyArg(), "ebook.added", file.OwnerID, `{"file_id":"`+file.FileID.String()+`","title":"Пикник на обочине","authors":[],"format":"epub"}`, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		id, err := repo.CreateFile(context.Background(), file)
+
+		assert.NoError(t, err)
+		assert.Equal(t, file.FileID, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		file := entities.NewEbookFile(uuid.New(), strings.Repeat("a", 64), entities.FormatPDF, 1024)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO ebook_files").
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		id, err := repo.CreateFile(context.Background(), file)
+
+		assert.Error(t, err)
+		assert.Equal(t, uuid.Nil, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetFileByHash(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	ownerID, fileID, sum := uuid.New(), uuid.New(), strings.Repeat("b", 64)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM ebook_files WHERE owner_id = $1 AND sha256 = $2")).
+		WithArgs(ownerID, sum).
+		WillReturnRows(sqlmock.NewRows(fileColumns).AddRow(fileRow(fileID, ownerID, sum)...))
+
+	file, err := repo.GetFileByHash(context.Background(), ownerID, sum)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fileID, file.FileID)
+	assert.Equal(t, entities.FormatEPUB, file.Format)
+	assert.Equal(t, []string{"Аркадий Стругацкий", "Борис Стругацкий"}, []string(file.Authors))
+	assert.Equal(t, []string{"sf"}, []string(file.Tags))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSharedFile(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("visible to household", func(t *testing.T) {
+		ownerID, viewerID, fileID := uuid.New(), uuid.New(), uuid.New()
+
+		mock.ExpectQuery("SELECT e.\\* FROM ebook_files e\\s+WHERE e.file_id = \\$2 AND\\s+\\(e.owner_id = \\$1 OR").
+			WithArgs(viewerID, fileID).
+			WillReturnRows(sqlmock.NewRows(fileColumns).AddRow(fileRow(fileID, ownerID, strings.Repeat("c", 64))...))
+
+		file, err := repo.GetSharedFile(context.Background(), fileID, viewerID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ownerID, file.OwnerID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not visible", func(t *testing.T) {
+		mock.ExpectQuery("SELECT e.\\* FROM ebook_files e").
+			WillReturnError(sql.ErrNoRows)
+
+		file, err := repo.GetSharedFile(context.Background(), uuid.New(), uuid.New())
+
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.Nil(t, file)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetSharedFiles(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	viewerID := uuid.New()
+
+	mock.ExpectQuery("SELECT e.\\* FROM ebook_files e\\s+WHERE\\s+\\(e.owner_id = \\$1 OR e.owner_id IN").
+		WithArgs(viewerID).
+		WillReturnRows(sqlmock.NewRows(fileColumns).
+			AddRow(fileRow(uuid.New(), viewerID, strings.Repeat("d", 64))...).
+			AddRow(fileRow(uuid.New(), uuid.New(), strings.Repeat("e", 64))...))
+
+	files, err := repo.GetSharedFiles(context.Background(), viewerID)
+
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateFile(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("not found", func(t *testing.T) {
+		file := &entities.EbookFile{FileID: uuid.New(), OwnerID: uuid.New(), Title: "Улитка на склоне"}
+
+		mock.ExpectExec("UPDATE ebook_files").
+			WithArgs(file.Title, file.Authors, file.Language, file.ISBN, file.Description, file.Series, file.SeriesIndex, file.Tags, file.UpdatedAt, file.FileID, file.OwnerID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdateFile(context.Background(), file)
+
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetBook(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	fileID, ownerID, bookID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT EXISTS \(\s+SELECT 1 FROM books b\s+JOIN household_members m`).
+		WithArgs(ownerID, bookID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`UPDATE ebook_files\s+SET book_id = \$3`).
+		WithArgs(fileID, ownerID, &bookID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE ebook_files\s+SET book_id = \$3`).
+		WithArgs(fileID, ownerID, nil).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := repo.HasBook(context.Background(), ownerID, bookID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, repo.SetBook(context.Background(), fileID, ownerID, &bookID))
+	assert.Equal(t, sql.ErrNoRows, repo.SetBook(context.Background(), fileID, ownerID, nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteFile(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	ownerID, fileID, sum := uuid.New(), uuid.New(), strings.Repeat("f", 64)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM ebook_files WHERE file_id = $1 AND owner_id = $2 RETURNING *")).
+		WithArgs(fileID, ownerID).
+		WillReturnRows(sqlmock.NewRows(fileColumns).AddRow(fileRow(fileID, ownerID, sum)...))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(sqlmock.AnyArg(), "ebook.deleted", ownerID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	file, err := repo.DeleteFile(context.Background(), fileID, ownerID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, sum, file.SHA256)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHashInUse(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	sum := strings.Repeat("0", 64)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM ebook_files WHERE sha256 = $1)")).
+		WithArgs(sum).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	inUse, err := repo.HashInUse(context.Background(), sum)
+
+	assert.NoError(t, err)
+	assert.True(t, inUse)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindSharedFiles(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("by author in a page", func(t *testing.T) {
+		viewerID := uuid.New()
+
+		mock.ExpectQuery(`\$2 = ANY\(e.authors\)\s+ORDER BY e.title, e.created_at LIMIT \$3 OFFSET \$4`).
+			WithArgs(viewerID, "Борис Стругацкий", 50, 100).
+			WillReturnRows(sqlmock.NewRows(fileColumns).AddRow(fileRow(uuid.New(), viewerID, strings.Repeat("1", 64))...))
+
+		files, err := repo.FindSharedFiles(context.Background(), viewerID, entities.Filter{Author: "Борис Стругацкий", Limit: 50, Offset: 100})
+
+		assert.NoError(t, err)
+		assert.Len(t, files, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("series in reading order", func(t *testing.T) {
+		viewerID := uuid.New()
+
+		mock.ExpectQuery(`e.series = \$2\s+ORDER BY e.series_index NULLS LAST, e.title$`).
+			WithArgs(viewerID, "Мир Полудня").
+			WillReturnRows(sqlmock.NewRows(fileColumns))
+
+		files, err := repo.FindSharedFiles(context.Background(), viewerID, entities.Filter{Series: "Мир Полудня"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, files)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("search escapes wildcards", func(t *testing.T) {
+		viewerID := uuid.New()
+
+		mock.ExpectQuery(`\(e.title ILIKE \$2 OR array_to_string\(e.authors, ' '\) ILIKE \$2\)\s+ORDER BY e.created_at DESC`).
+			WithArgs(viewerID, `%100\% fun\_%`).
+			WillReturnRows(sqlmock.NewRows(fileColumns))
+
+		_, err := repo.FindSharedFiles(context.Background(), viewerID, entities.Filter{Query: "100% fun_", Recent: true})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("by any form of isbn", func(t *testing.T) {
+		viewerID := uuid.New()
+
+		mock.ExpectQuery(`e.isbn = ANY\(\$2\)\s+ORDER BY e.title, e.created_at$`).
+			WithArgs(viewerID, pq.StringArray{"9780441013593", "0441013597"}).
+			WillReturnRows(sqlmock.NewRows(fileColumns))
+
+		_, err := repo.FindSharedFiles(context.Background(), viewerID, entities.Filter{ISBNs: []string{"9780441013593", "0441013597"}})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetSharedFacet(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("tags", func(t *testing.T) {
+		viewerID := uuid.New()
+
+		mock.ExpectQuery(`SELECT name, count\(\*\) AS count FROM \(\s+SELECT unnest\(e.tags\) AS name`).
+			WithArgs(viewerID).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "count"}).AddRow("sf", 12).AddRow("детектив", 3))
+
+		values, err := repo.GetSharedFacet(context.Background(), viewerID, entities.FacetTag)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []entities.FacetValue{{Name: "sf", Count: 12}, {Name: "детектив", Count: 3}}, values)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unknown facet", func(t *testing.T) {
+		_, err := repo.GetSharedFacet(context.Background(), uuid.New(), entities.Facet("publisher"))
+
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
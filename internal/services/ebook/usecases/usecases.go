@@ -0,0 +1,419 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	stdErrors "errors"
+	coverUseCases "home-library/internal/services/cover/usecases"
+	"home-library/internal/services/ebook/dtos"
+	"home-library/internal/services/ebook/entities"
+	"home-library/internal/services/ebook/repository"
+	"home-library/pkg/blobstore"
+	"home-library/pkg/epub"
+	"home-library/pkg/errors"
+	"home-library/pkg/fb2"
+	"home-library/pkg/isbn"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// MaxUploadSize is the largest accepted ebook file in bytes. Scanned PDFs
+// are the reason it is this generous.
+const MaxUploadSize = 200 << 20
+
+type UseCase interface {
+	// UploadFile stores the file and returns it with created set to false
+	// when the user had already uploaded the same contents.
+	UploadFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (file *dtos.EbookResponse, created bool, err error)
+	// StageFile stores the blobs of an upload without saving the file, so
+	// that a caller can save it in a transaction with SaveFile. DiscardFile
+	// removes the blobs of a staged file that was not saved.
+	StageFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (*StagedFile, error)
+	SaveFile(ctx context.Context, staged *StagedFile) (file *dtos.EbookResponse, created bool, err error)
+	DiscardFile(ctx context.Context, staged *StagedFile)
+	GetFiles(ctx context.Context, viewerID uuid.UUID) ([]dtos.EbookResponse, error)
+	GetFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*dtos.EbookResponse, error)
+	UpdateFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload dtos.UpdateEbookRequest) error
+	// AttachFile tells which book of the household catalog the user's file
+	// is of; DetachFile forgets it.
+	AttachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload dtos.AttachEbookRequest) error
+	DetachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error
+	DeleteFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error
+	OpenFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*entities.EbookFile, *blobstore.Object, error)
+}
+
+type useCase struct {
+	r      repository.Repository
+	store  blobstore.Store
+	covers coverUseCases.UseCase
+}
+
+func NewUseCase(r repository.Repository, store blobstore.Store, covers coverUseCases.UseCase) UseCase {
+	return &useCase{r: r, store: store, covers: covers}
+}
+
+// StagedFile is an upload whose blobs are stored but which is not saved.
+type StagedFile struct {
+	file *entities.EbookFile
+	// existing is set when the user already had the contents, in which case
+	// nothing was stored.
+	existing bool
+}
+
+func (u *useCase) UploadFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (*dtos.EbookResponse, bool, error) {
+	staged, err := u.StageFile(ctx, userID, name, r, size)
+	if err != nil {
+		return nil, false, err
+	}
+
+	file, created, err := u.SaveFile(ctx, staged)
+	if err != nil || !created {
+		u.DiscardFile(ctx, staged)
+	}
+	return file, created, err
+}
+
+func (u *useCase) StageFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (*StagedFile, error) {
+	if size > MaxUploadSize {
+		return nil, errors.ErrEbookTooLarge
+	}
+	if size <= 0 {
+		return nil, errors.ErrEbookUnsupported
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(r, 0, size)); err != nil {
+		return nil, err
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	existing, err := u.r.GetFileByHash(ctx, userID, sum)
+	switch {
+	case err == nil:
+		return &StagedFile{file: existing, existing: true}, nil
+	case !stdErrors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	format, info, err := inspect(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	file := entities.NewEbookFile(userID, sum, format, size)
+	if name = strings.TrimSpace(name); name != "" {
+		file.OriginalName = truncate(path.Base(strings.ReplaceAll(name, "\\", "/")), 255)
+	}
+	file.Title = truncate(info.title, 255)
+	if file.Title == "" {
+		file.Title = truncate(strings.TrimSuffix(file.OriginalName, "."+string(format)), 255)
+	}
+	for _, author := range info.authors {
+		if author = truncate(strings.TrimSpace(author), 255); author != "" {
+			file.Authors = append(file.Authors, author)
+		}
+	}
+	file.Language = truncate(info.language, 35)
+	file.ISBN = info.isbn
+	file.Description = truncate(info.description, 10000)
+	file.Series = truncate(info.series, 255)
+	file.SeriesIndex = info.seriesIndex
+	file.Tags = cleanTags(info.tags)
+
+	if len(info.cover) > 0 {
+		cover, err := u.covers.UploadCover(ctx, userID, info.cover)
+		if err != nil {
+			// A broken cover is not worth rejecting the book for.
+			log.Warn().Err(err).Str("sha256", sum).Msg("failed to store ebook cover")
+		} else {
+			file.CoverID = &cover.CoverID
+		}
+	}
+
+	staged := &StagedFile{file: file}
+	// The blob may already be there if another user uploaded the same file.
+	if _, err := u.store.Stat(ctx, file.BlobKey()); stdErrors.Is(err, blobstore.ErrNotFound) {
+		err := u.store.Put(ctx, file.BlobKey(), io.NewSectionReader(r, 0, size), size, format.ContentType())
+		if err != nil {
+			u.DiscardFile(ctx, staged)
+			return nil, err
+		}
+	} else if err != nil {
+		u.DiscardFile(ctx, staged)
+		return nil, err
+	}
+
+	return staged, nil
+}
+
+func (u *useCase) SaveFile(ctx context.Context, staged *StagedFile) (*dtos.EbookResponse, bool, error) {
+	if staged.existing {
+		response := dtos.NewEbookResponse(*staged.file)
+		return &response, false, nil
+	}
+
+	// The user may have saved the same contents since the file was staged.
+	existing, err := u.r.GetFileByHash(ctx, staged.file.OwnerID, staged.file.SHA256)
+	switch {
+	case err == nil:
+		response := dtos.NewEbookResponse(*existing)
+		return &response, false, nil
+	case !stdErrors.Is(err, sql.ErrNoRows):
+		return nil, false, err
+	}
+
+	if _, err := u.r.CreateFile(ctx, staged.file); stdErrors.Is(err, errors.ErrEbookExists) {
+		// The same file was uploaded concurrently and won the race.
+		existing, err := u.r.GetFileByHash(ctx, staged.file.OwnerID, staged.file.SHA256)
+		if err != nil {
+			return nil, false, err
+		}
+		response := dtos.NewEbookResponse(*existing)
+		return &response, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	response := dtos.NewEbookResponse(*staged.file)
+	return &response, true, nil
+}
+
+func (u *useCase) DiscardFile(ctx context.Context, staged *StagedFile) {
+	if !staged.existing {
+		u.release(ctx, staged.file)
+	}
+}
+
+func (u *useCase) GetFiles(ctx context.Context, viewerID uuid.UUID) ([]dtos.EbookResponse, error) {
+	files, err := u.r.GetSharedFiles(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.EbookResponse, len(files))
+	for i, file := range files {
+		response[i] = dtos.NewEbookResponse(file)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) GetFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*dtos.EbookResponse, error) {
+	file, err := u.r.GetSharedFile(ctx, fileID, viewerID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrEbookNotFound)
+	}
+
+	response := dtos.NewEbookResponse(*file)
+	return &response, nil
+}
+
+func (u *useCase) UpdateFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload dtos.UpdateEbookRequest) error {
+	file := &entities.EbookFile{
+		FileID:      fileID,
+		OwnerID:     userID,
+		Title:       strings.TrimSpace(payload.Title),
+		Authors:     make([]string, 0, len(payload.Authors)),
+		Language:    payload.Language,
+		ISBN:        isbn.Normalize(payload.ISBN),
+		Description: strings.TrimSpace(payload.Description),
+		Series:      strings.TrimSpace(payload.Series),
+		SeriesIndex: payload.SeriesIndex,
+		Tags:        cleanTags(payload.Tags),
+		UpdatedAt:   time.Now(),
+	}
+	for _, author := range payload.Authors {
+		file.Authors = append(file.Authors, strings.TrimSpace(author))
+	}
+
+	return mapNoRows(u.r.UpdateFile(ctx, file), errors.ErrEbookNotFound)
+}
+
+func (u *useCase) AttachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload dtos.AttachEbookRequest) error {
+	ok, err := u.r.HasBook(ctx, userID, payload.BookID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.ErrBookNotFound
+	}
+
+	return mapNoRows(u.r.SetBook(ctx, fileID, userID, &payload.BookID), errors.ErrEbookNotFound)
+}
+
+func (u *useCase) DetachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	return mapNoRows(u.r.SetBook(ctx, fileID, userID, nil), errors.ErrEbookNotFound)
+}
+
+func (u *useCase) DeleteFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	file, err := u.r.DeleteFile(ctx, fileID, userID)
+	if err != nil {
+		return mapNoRows(err, errors.ErrEbookNotFound)
+	}
+
+	u.release(ctx, file)
+	return nil
+}
+
+// release removes the blob and cover of a file no longer saved, unless
+// other files still use them.
+func (u *useCase) release(ctx context.Context, file *entities.EbookFile) {
+	// The blob is shared by every upload of the same contents. An upload
+	// racing with this check can lose its blob; it is re-stored the next
+	// time the file is uploaded.
+	inUse, err := u.r.HashInUse(ctx, file.SHA256)
+	if err != nil {
+		log.Error().Err(err).Str("sha256", file.SHA256).Msg("failed to check ebook blob usage")
+		return
+	}
+	if !inUse {
+		if err := u.store.Delete(ctx, file.BlobKey()); err != nil {
+			log.Error().Err(err).Str("sha256", file.SHA256).Msg("failed to delete ebook blob")
+		}
+	}
+
+	// A book of the catalog may have taken the cover, in which case the
+	// cover service keeps it.
+	if file.CoverID != nil {
+		if err := u.covers.DeleteCover(ctx, *file.CoverID); err != nil {
+			log.Error().Err(err).Str("cover_id", file.CoverID.String()).Msg("failed to delete ebook cover")
+		}
+	}
+}
+
+func (u *useCase) OpenFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*entities.EbookFile, *blobstore.Object, error) {
+	file, err := u.r.GetSharedFile(ctx, fileID, viewerID)
+	if err != nil {
+		return nil, nil, mapNoRows(err, errors.ErrEbookNotFound)
+	}
+
+	object, err := u.store.Get(ctx, file.BlobKey())
+	if stdErrors.Is(err, blobstore.ErrNotFound) {
+		log.Error().Str("file_id", fileID.String()).Str("sha256", file.SHA256).Msg("ebook blob is missing")
+		return nil, nil, errors.ErrEbookNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, object, nil
+}
+
+// details is the metadata pre-filled from the file itself.
+type details struct {
+	title       string
+	authors     []string
+	language    string
+	isbn        string
+	description string
+	series      string
+	seriesIndex *float64
+	tags        []string
+	cover       []byte
+}
+
+// inspect detects the format from the contents, the file name and the
+// client's content type are not trusted.
+func inspect(r io.ReaderAt, size int64) (entities.Format, *details, error) {
+	header := make([]byte, 8)
+	n, _ := r.ReadAt(header, 0)
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte("%PDF-")):
+		return entities.FormatPDF, &details{}, nil
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")):
+		if book, err := epub.Open(r, size); err == nil {
+			return entities.FormatEPUB, epubDetails(book), nil
+		}
+		if description, err := fb2.ParseZip(r, size); err == nil {
+			return entities.FormatFB2Zip, fb2Details(description), nil
+		}
+	default:
+		if description, err := fb2.Parse(io.NewSectionReader(r, 0, size)); err == nil {
+			return entities.FormatFB2, fb2Details(description), nil
+		}
+	}
+
+	return "", nil, errors.ErrEbookUnsupported
+}
+
+func epubDetails(book *epub.Book) *details {
+	metadata := book.Metadata
+	info := &details{
+		title:       metadata.Title,
+		authors:     metadata.Authors,
+		language:    metadata.Language,
+		isbn:        metadata.ISBN,
+		description: metadata.Description,
+		series:      metadata.Series,
+		tags:        metadata.Subjects,
+		cover:       book.Cover,
+	}
+	if metadata.Series != "" && metadata.SeriesIndex > 0 {
+		index := metadata.SeriesIndex
+		info.seriesIndex = &index
+	}
+	return info
+}
+
+func fb2Details(description *fb2.Description) *details {
+	authors := make([]string, len(description.Authors))
+	for i, author := range description.Authors {
+		authors[i] = author.Name()
+	}
+
+	info := &details{
+		title:       description.Title,
+		authors:     authors,
+		language:    description.Language,
+		isbn:        description.ISBN,
+		description: description.Annotation,
+		series:      description.Series,
+		tags:        description.Genres,
+		cover:       description.Cover,
+	}
+	if description.Series != "" && description.SeriesIndex > 0 {
+		index := float64(description.SeriesIndex)
+		info.seriesIndex = &index
+	}
+	return info
+}
+
+// cleanTags trims tags and drops empty and repeated ones, keeping the order.
+func cleanTags(tags []string) []string {
+	cleaned := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = truncate(strings.TrimSpace(tag), 100)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		cleaned = append(cleaned, tag)
+	}
+	return cleaned
+}
+
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit])
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
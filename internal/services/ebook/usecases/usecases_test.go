@@ -0,0 +1,466 @@
+package usecases
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"home-library/internal/services/cover/dtos"
+	coverEntities "home-library/internal/services/cover/entities"
+	ebookDtos "home-library/internal/services/ebook/dtos"
+	"home-library/internal/services/ebook/entities"
+	"home-library/pkg/blobstore"
+	customErrors "home-library/pkg/errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateFile(ctx context.Context, file *entities.EbookFile) (uuid.UUID, error) {
+	args := m.Called(ctx, file)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetFileByHash(ctx context.Context, ownerID uuid.UUID, sha256 string) (*entities.EbookFile, error) {
+	args := m.Called(ctx, ownerID, sha256)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) GetSharedFile(ctx context.Context, fileID uuid.UUID, viewerID uuid.UUID) (*entities.EbookFile, error) {
+	args := m.Called(ctx, fileID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) GetSharedFiles(ctx context.Context, viewerID uuid.UUID) ([]entities.EbookFile, error) {
+	args := m.Called(ctx, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) FindSharedFiles(ctx context.Context, viewerID uuid.UUID, filter entities.Filter) ([]entities.EbookFile, error) {
+	args := m.Called(ctx, viewerID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) GetSharedFacet(ctx context.Context, viewerID uuid.UUID, facet entities.Facet) ([]entities.FacetValue, error) {
+	args := m.Called(ctx, viewerID, facet)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.FacetValue), args.Error(1)
+}
+
+func (m *MockRepository) UpdateFile(ctx context.Context, file *entities.EbookFile) error {
+	return m.Called(ctx, file).Error(0)
+}
+
+func (m *MockRepository) HasBook(ctx context.Context, ownerID uuid.UUID, bookID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, ownerID, bookID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) SetBook(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID, bookID *uuid.UUID) error {
+	return m.Called(ctx, fileID, ownerID, bookID).Error(0)
+}
+
+func (m *MockRepository) DeleteFile(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID) (*entities.EbookFile, error) {
+	args := m.Called(ctx, fileID, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) HashInUse(ctx context.Context, sha256 string) (bool, error) {
+	args := m.Called(ctx, sha256)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockCoverUseCase struct {
+	mock.Mock
+}
+
+func (m *MockCoverUseCase) UploadCover(ctx context.Context, userID uuid.UUID, data []byte) (*dtos.CoverResponse, error) {
+	args := m.Called(ctx, userID, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.CoverResponse), args.Error(1)
+}
+
+func (m *MockCoverUseCase) GetCover(ctx context.Context, coverID uuid.UUID, variant coverEntities.Variant) (*blobstore.Object, error) {
+	args := m.Called(ctx, coverID, variant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*blobstore.Object), args.Error(1)
+}
+
+func (m *MockCoverUseCase) DeleteCover(ctx context.Context, coverID uuid.UUID) error {
+	args := m.Called(ctx, coverID)
+	return args.Error(0)
+}
+
+func buildEPUB(t *testing.T) []byte {
+	files := []struct{ name, content string }{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", `<container><rootfiles><rootfile full-path="content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`},
+		{"content.opf", `<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+			<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+				<dc:title>Трудно быть богом</dc:title>
+				<dc:creator>Аркадий Стругацкий</dc:creator>
+				<dc:creator>Борис Стругацкий</dc:creator>
+				<dc:language>ru</dc:language>
+				<dc:subject>Фантастика</dc:subject>
+				<dc:subject>фантастика</dc:subject>
+				<dc:subject>Прогрессоры</dc:subject>
+				<meta name="calibre:series" content="Мир Полудня"/>
+				<meta name="calibre:series_index" content="3"/>
+			</metadata>
+			<manifest><item id="c" href="cover.jpg" media-type="image/jpeg" properties="cover-image"/></manifest>
+		</package>`},
+		{"cover.jpg", "cover bytes"},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		f, err := zw.Create(file.name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func newUseCase(t *testing.T) (UseCase, *MockRepository, *MockCoverUseCase, blobstore.Store) {
+	store, err := blobstore.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	repo := new(MockRepository)
+	covers := new(MockCoverUseCase)
+	return NewUseCase(repo, store, covers), repo, covers, store
+}
+
+func TestUploadFile(t *testing.T) {
+	t.Run("epub is prefilled from its metadata", func(t *testing.T) {
+		useCase, repo, covers, store := newUseCase(t)
+		userID, coverID := uuid.New(), uuid.New()
+		data := buildEPUB(t)
+
+		repo.On("GetFileByHash", mock.Anything, userID, hash(data)).Return(nil, sql.ErrNoRows)
+		covers.On("UploadCover", mock.Anything, userID, []byte("cover bytes")).Return(&dtos.CoverResponse{CoverID: coverID}, nil)
+		repo.On("CreateFile", mock.Anything, mock.AnythingOfType("*entities.EbookFile")).Return(uuid.New(), nil)
+
+		file, created, err := useCase.UploadFile(context.Background(), userID, `C:\Books\hard.epub`, bytes.NewReader(data), int64(len(data)))
+
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "epub", file.Format)
+		assert.Equal(t, "hard.epub", file.OriginalName)
+		assert.Equal(t, "Трудно быть богом", file.Title)
+		assert.Equal(t, []string{"Аркадий Стругацкий", "Борис Стругацкий"}, file.Authors)
+		assert.Equal(t, "ru", file.Language)
+		assert.Equal(t, []string{"Фантастика", "Прогрессоры"}, file.Tags)
+		assert.Equal(t, "Мир Полудня", file.Series)
+		assert.Equal(t, 3.0, *file.SeriesIndex)
+		assert.Equal(t, &coverID, file.CoverID)
+
+		info, err := store.Stat(context.Background(), "ebooks/"+file.SHA256[:2]+"/"+file.SHA256)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(data)), info.Size)
+		assert.Equal(t, "application/epub+zip", info.ContentType)
+	})
+
+	t.Run("same contents uploaded again", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		userID := uuid.New()
+		data := []byte("%PDF-1.7 scanned")
+		existing := entities.NewEbookFile(userID, hash(data), entities.FormatPDF, int64(len(data)))
+
+		repo.On("GetFileByHash", mock.Anything, userID, hash(data)).Return(existing, nil)
+
+		file, created, err := useCase.UploadFile(context.Background(), userID, "scan.pdf", bytes.NewReader(data), int64(len(data)))
+
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, existing.FileID, file.FileID)
+		repo.AssertNotCalled(t, "CreateFile", mock.Anything, mock.Anything)
+	})
+
+	t.Run("same contents uploaded concurrently", func(t *testing.T) {
+		useCase, repo, _, store := newUseCase(t)
+		userID := uuid.New()
+		data := []byte("%PDF-1.7 scanned")
+		existing := entities.NewEbookFile(userID, hash(data), entities.FormatPDF, int64(len(data)))
+
+		repo.On("GetFileByHash", mock.Anything, userID, hash(data)).Return(nil, sql.ErrNoRows).Twice()
+		repo.On("CreateFile", mock.Anything, mock.Anything).Return(uuid.Nil, customErrors.ErrEbookExists)
+		repo.On("GetFileByHash", mock.Anything, userID, hash(data)).Return(existing, nil).Once()
+		repo.On("HashInUse", mock.Anything, hash(data)).Return(true, nil)
+
+		file, created, err := useCase.UploadFile(context.Background(), userID, "scan.pdf", bytes.NewReader(data), int64(len(data)))
+
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, existing.FileID, file.FileID)
+		repo.AssertExpectations(t)
+		_, err = store.Stat(context.Background(), existing.BlobKey())
+		assert.NoError(t, err, "the blob of the winning upload must be kept")
+	})
+
+	t.Run("blob is discarded when the file cannot be saved", func(t *testing.T) {
+		useCase, repo, covers, store := newUseCase(t)
+		userID, coverID := uuid.New(), uuid.New()
+		data := buildEPUB(t)
+
+		repo.On("GetFileByHash", mock.Anything, userID, hash(data)).Return(nil, sql.ErrNoRows)
+		covers.On("UploadCover", mock.Anything, userID, mock.Anything).Return(&dtos.CoverResponse{CoverID: coverID}, nil)
+		repo.On("CreateFile", mock.Anything, mock.Anything).Return(uuid.Nil, sql.ErrConnDone)
+		repo.On("HashInUse", mock.Anything, hash(data)).Return(false, nil)
+		covers.On("DeleteCover", mock.Anything, coverID).Return(nil)
+
+		_, _, err := useCase.UploadFile(context.Background(), userID, "book.epub", bytes.NewReader(data), int64(len(data)))
+
+		assert.Equal(t, sql.ErrConnDone, err)
+		covers.AssertExpectations(t)
+		_, err = store.Stat(context.Background(), "ebooks/"+hash(data)[:2]+"/"+hash(data))
+		assert.Equal(t, blobstore.ErrNotFound, err)
+	})
+
+	t.Run("pdf title comes from the file name", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		userID := uuid.New()
+		data := []byte("%PDF-1.7 scanned")
+
+		repo.On("GetFileByHash", mock.Anything, userID, hash(data)).Return(nil, sql.ErrNoRows)
+		repo.On("CreateFile", mock.Anything, mock.Anything).Return(uuid.New(), nil)
+
+		file, _, err := useCase.UploadFile(context.Background(), userID, "Понедельник начинается в субботу.pdf", bytes.NewReader(data), int64(len(data)))
+
+		require.NoError(t, err)
+		assert.Equal(t, "pdf", file.Format)
+		assert.Equal(t, "Понедельник начинается в субботу", file.Title)
+		assert.Empty(t, file.Authors)
+	})
+
+	t.Run("fb2", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		userID := uuid.New()
+		data := []byte(`<?xml version="1.0" encoding="utf-8"?>
+			<FictionBook><description><title-info>
+				<author><first-name>Кир</first-name><last-name>Булычёв</last-name></author>
+				<genre>child_sf</genre>
+				<book-title>Сто лет тому вперёд</book-title><lang>ru</lang>
+				<sequence name="Приключения Алисы" number="2"/>
+			</title-info></description><body/></FictionBook>`)
+
+		repo.On("GetFileByHash", mock.Anything, userID, hash(data)).Return(nil, sql.ErrNoRows)
+		repo.On("CreateFile", mock.Anything, mock.Anything).Return(uuid.New(), nil)
+
+		file, _, err := useCase.UploadFile(context.Background(), userID, "book.fb2", bytes.NewReader(data), int64(len(data)))
+
+		require.NoError(t, err)
+		assert.Equal(t, "fb2", file.Format)
+		assert.Equal(t, "Сто лет тому вперёд", file.Title)
+		assert.Equal(t, []string{"Кир Булычёв"}, file.Authors)
+		assert.Equal(t, []string{"child_sf"}, file.Tags)
+		assert.Equal(t, "Приключения Алисы", file.Series)
+		assert.Equal(t, 2.0, *file.SeriesIndex)
+	})
+
+	t.Run("broken cover does not fail the upload", func(t *testing.T) {
+		useCase, repo, covers, _ := newUseCase(t)
+		userID := uuid.New()
+		data := buildEPUB(t)
+
+		repo.On("GetFileByHash", mock.Anything, userID, hash(data)).Return(nil, sql.ErrNoRows)
+		covers.On("UploadCover", mock.Anything, userID, mock.Anything).Return(nil, customErrors.ErrCoverUnsupported)
+		repo.On("CreateFile", mock.Anything, mock.Anything).Return(uuid.New(), nil)
+
+		file, created, err := useCase.UploadFile(context.Background(), userID, "book.epub", bytes.NewReader(data), int64(len(data)))
+
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Nil(t, file.CoverID)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		userID := uuid.New()
+		data := []byte("just some text")
+
+		repo.On("GetFileByHash", mock.Anything, userID, hash(data)).Return(nil, sql.ErrNoRows)
+
+		_, _, err := useCase.UploadFile(context.Background(), userID, "book.epub", bytes.NewReader(data), int64(len(data)))
+
+		assert.Equal(t, customErrors.ErrEbookUnsupported, err)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		useCase, _, _, _ := newUseCase(t)
+
+		_, _, err := useCase.UploadFile(context.Background(), uuid.New(), "huge.pdf", bytes.NewReader(nil), MaxUploadSize+1)
+
+		assert.Equal(t, customErrors.ErrEbookTooLarge, err)
+	})
+}
+
+func TestUpdateFile(t *testing.T) {
+	t.Run("file not found", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		userID, fileID := uuid.New(), uuid.New()
+
+		repo.On("UpdateFile", mock.Anything, mock.MatchedBy(func(file *entities.EbookFile) bool {
+			return file.FileID == fileID && file.OwnerID == userID && file.Title == "Град обреченный" && file.ISBN == "080442957X"
+		})).Return(sql.ErrNoRows)
+
+		err := useCase.UpdateFile(context.Background(), userID, fileID, ebookDtos.UpdateEbookRequest{Title: " Град обреченный ", ISBN: "080442957x"})
+
+		assert.Equal(t, customErrors.ErrEbookNotFound, err)
+	})
+
+	t.Run("stores hyphenated ISBN without separators", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+
+		repo.On("UpdateFile", mock.Anything, mock.MatchedBy(func(file *entities.EbookFile) bool {
+			return file.ISBN == "9783161484100"
+		})).Return(nil)
+
+		err := useCase.UpdateFile(context.Background(), uuid.New(), uuid.New(), ebookDtos.UpdateEbookRequest{ISBN: "978-3-16-148410-0"})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestAttachFile(t *testing.T) {
+	t.Run("book of another household", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		userID, bookID := uuid.New(), uuid.New()
+
+		repo.On("HasBook", mock.Anything, userID, bookID).Return(false, nil)
+
+		err := useCase.AttachFile(context.Background(), userID, uuid.New(), ebookDtos.AttachEbookRequest{BookID: bookID})
+
+		assert.Equal(t, customErrors.ErrBookNotFound, err)
+		repo.AssertNotCalled(t, "SetBook", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("file of another member", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		userID, fileID, bookID := uuid.New(), uuid.New(), uuid.New()
+
+		repo.On("HasBook", mock.Anything, userID, bookID).Return(true, nil)
+		repo.On("SetBook", mock.Anything, fileID, userID, &bookID).Return(sql.ErrNoRows)
+
+		err := useCase.AttachFile(context.Background(), userID, fileID, ebookDtos.AttachEbookRequest{BookID: bookID})
+
+		assert.Equal(t, customErrors.ErrEbookNotFound, err)
+	})
+
+	t.Run("detaches", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		userID, fileID := uuid.New(), uuid.New()
+
+		repo.On("SetBook", mock.Anything, fileID, userID, (*uuid.UUID)(nil)).Return(nil)
+
+		assert.NoError(t, useCase.DetachFile(context.Background(), userID, fileID))
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestDeleteFile(t *testing.T) {
+	for _, inUse := range []bool{false, true} {
+		useCase, repo, covers, store := newUseCase(t)
+		userID, coverID := uuid.New(), uuid.New()
+		file := entities.NewEbookFile(userID, hash([]byte("book")), entities.FormatPDF, 4)
+		file.CoverID = &coverID
+		require.NoError(t, store.Put(context.Background(), file.BlobKey(), strings.NewReader("book"), 4, ""))
+
+		repo.On("DeleteFile", mock.Anything, file.FileID, userID).Return(file, nil)
+		repo.On("HashInUse", mock.Anything, file.SHA256).Return(inUse, nil)
+		covers.On("DeleteCover", mock.Anything, coverID).Return(nil)
+
+		require.NoError(t, useCase.DeleteFile(context.Background(), userID, file.FileID))
+		covers.AssertExpectations(t)
+
+		_, err := store.Stat(context.Background(), file.BlobKey())
+		if inUse {
+			assert.NoError(t, err, "blob shared with another upload must be kept")
+		} else {
+			assert.Equal(t, blobstore.ErrNotFound, err)
+		}
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	t.Run("streams stored contents", func(t *testing.T) {
+		useCase, repo, _, store := newUseCase(t)
+		viewerID := uuid.New()
+		file := entities.NewEbookFile(uuid.New(), hash([]byte("book")), entities.FormatPDF, 4)
+		require.NoError(t, store.Put(context.Background(), file.BlobKey(), strings.NewReader("book"), 4, ""))
+
+		repo.On("GetSharedFile", mock.Anything, file.FileID, viewerID).Return(file, nil)
+
+		_, object, err := useCase.OpenFile(context.Background(), viewerID, file.FileID)
+		require.NoError(t, err)
+		defer object.Close()
+
+		data, err := io.ReadAll(object)
+		require.NoError(t, err)
+		assert.Equal(t, "book", string(data))
+	})
+
+	t.Run("not shared with viewer", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		fileID, viewerID := uuid.New(), uuid.New()
+
+		repo.On("GetSharedFile", mock.Anything, fileID, viewerID).Return(nil, sql.ErrNoRows)
+
+		_, _, err := useCase.OpenFile(context.Background(), viewerID, fileID)
+
+		assert.Equal(t, customErrors.ErrEbookNotFound, err)
+	})
+
+	t.Run("missing blob", func(t *testing.T) {
+		useCase, repo, _, _ := newUseCase(t)
+		viewerID := uuid.New()
+		file := entities.NewEbookFile(uuid.New(), hash([]byte("gone")), entities.FormatPDF, 4)
+
+		repo.On("GetSharedFile", mock.Anything, file.FileID, viewerID).Return(file, nil)
+
+		_, _, err := useCase.OpenFile(context.Background(), viewerID, file.FileID)
+
+		assert.True(t, errors.Is(err, customErrors.ErrEbookNotFound))
+	})
+}
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/event/dtos"
+	"home-library/internal/services/event/usecases"
+	"home-library/pkg/jwt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// heartbeatInterval keeps proxies from closing an idle stream.
+	heartbeatInterval = 15 * time.Second
+	// reconnectDelay is the retry hint sent to clients, in milliseconds.
+	reconnectDelay = 3000
+)
+
+type handler struct {
+	u         usecases.UseCase
+	heartbeat time.Duration
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u, heartbeat: heartbeatInterval}
+}
+
+// StreamEvents streams the events of the user's household as Server-Sent
+// Events. A client resumes with the Last-Event-ID header, or the
+// last_event_id query parameter where it cannot set headers.
+func (h *handler) StreamEvents(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+	var since int64
+	if lastEventID != "" {
+		var err error
+		since, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || since < 0 {
+			return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор события", nil))
+		}
+	}
+
+	ctx := c.Request().Context()
+	stream, err := h.u.Subscribe(ctx, userID, since)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID.String()).Msg("failed to subscribe to events")
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+	defer stream.Close()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	// Stops nginx from buffering the stream.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", reconnectDelay); err != nil {
+		return nil
+	}
+	for _, event := range stream.Backlog {
+		if err := writeEvent(w, event); err != nil {
+			return nil
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-stream.C:
+			if !ok {
+				return nil
+			}
+			if stream.Duplicate(event) {
+				continue
+			}
+			if err := writeEvent(w, event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		w.Flush()
+	}
+}
+
+func writeEvent(w *echo.Response, event dtos.EventResponse) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
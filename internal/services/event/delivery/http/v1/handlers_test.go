@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"home-library/internal/services/event/dtos"
+	"home-library/internal/services/event/usecases"
+	"home-library/pkg/events"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) Subscribe(ctx context.Context, userID uuid.UUID, lastEventID int64) (*usecases.Stream, error) {
+	args := m.Called(ctx, userID, lastEventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecases.Stream), args.Error(1)
+}
+
+func (m *MockUseCase) Poll(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *MockUseCase) Close() {
+	m.Called()
+}
+
+func newContext(e *echo.Echo, target string, lastEventID string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestStreamEvents(t *testing.T) {
+	e := echo.New()
+
+	t.Run("backlog, live events and heartbeats until the stream ends", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		h.heartbeat = time.Millisecond
+		userID := uuid.New()
+
+		live := make(chan dtos.EventResponse, 2)
+		live <- dtos.EventResponse{ID: 13, Type: events.TypeEbookDeleted, Payload: []byte(`{}`)}
+		live <- dtos.EventResponse{ID: 14, Type: events.TypeEbookAdded, Payload: []byte(`{}`)}
+		stream := &usecases.Stream{
+			Backlog: []dtos.EventResponse{{ID: 12, Type: events.TypeEbookAdded, Payload: []byte(`{}`)}},
+			C:       live,
+		}
+		mockUseCase.On("Subscribe", mock.Anything, userID, int64(11)).Return(stream, nil)
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			close(live)
+		}()
+
+		c, rec := newContext(e, "/events", "11", userID)
+		err := h.StreamEvents(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+
+		body := rec.Body.String()
+		assert.True(t, strings.HasPrefix(body, "retry: 3000\n\n"))
+		assert.Less(t, strings.Index(body, "id: 12\n"), strings.Index(body, "id: 13\n"), "backlog comes first")
+		assert.Contains(t, body, "id: 13\nevent: ebook.deleted\ndata: {\"id\":13,")
+		assert.Contains(t, body, "id: 14\n")
+		assert.Contains(t, body, ": heartbeat\n\n")
+	})
+
+	t.Run("resume from query parameter", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		live := make(chan dtos.EventResponse)
+		close(live)
+		mockUseCase.On("Subscribe", mock.Anything, userID, int64(5)).Return(&usecases.Stream{C: live}, nil)
+
+		c, rec := newContext(e, "/events?last_event_id=5", "", userID)
+		err := h.StreamEvents(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("invalid last event id", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, "/events", "abc", uuid.New())
+		err := h.StreamEvents(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, "/events", "", uuid.Nil)
+		err := h.StreamEvents(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("subscription fails", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Subscribe", mock.Anything, userID, int64(0)).Return(nil, errors.New("db down"))
+
+		c, rec := newContext(e, "/events", "", userID)
+		err := h.StreamEvents(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,7 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) EventRoutes(domain *echo.Group) {
+	domain.GET("/events", h.StreamEvents)
+}
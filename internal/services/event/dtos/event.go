@@ -0,0 +1,32 @@
+package dtos
+
+import (
+	"encoding/json"
+	"home-library/internal/services/event/entities"
+	"home-library/pkg/events"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventResponse is one event of the stream. ID orders the events and is
+// what a client sends back in Last-Event-ID to resume.
+type EventResponse struct {
+	ID         int64           `json:"id"`
+	EventID    uuid.UUID       `json:"event_id"`
+	Type       events.Type     `json:"type"`
+	UserID     uuid.UUID       `json:"user_id"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+func NewEventResponse(event entities.Event) EventResponse {
+	return EventResponse{
+		ID:         event.Seq,
+		EventID:    event.EventID,
+		Type:       event.Type,
+		UserID:     event.UserID,
+		Payload:    event.Payload,
+		OccurredAt: event.OccurredAt,
+	}
+}
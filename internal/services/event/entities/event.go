@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"home-library/pkg/events"
+
+	"github.com/google/uuid"
+)
+
+// Event is a recorded domain event with the household its user belongs to
+// now, which decides who may see it.
+type Event struct {
+	events.Event
+	HouseholdID *uuid.UUID `db:"household_id"`
+}
+
+// VisibleTo reports whether the event belongs in the stream of a user of
+// the given household, which is nil for a user outside any household.
+func (e *Event) VisibleTo(userID uuid.UUID, householdID *uuid.UUID) bool {
+	if e.UserID == userID {
+		return true
+	}
+	return householdID != nil && e.HouseholdID != nil && *householdID == *e.HouseholdID
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/event/entities"
+	"home-library/pkg/transaction"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	GetLatestSeq(ctx context.Context) (int64, error)
+	GetEventsAfter(ctx context.Context, seq int64, limit int) ([]entities.Event, error)
+	GetVisibleEventsAfter(ctx context.Context, seq int64, userID uuid.UUID, householdID *uuid.UUID, limit int) ([]entities.Event, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) GetLatestSeq(ctx context.Context) (int64, error) {
+	var seq int64
+	query := `SELECT COALESCE(MAX(seq), 0) FROM outbox_events`
+
+	err := r.db.GetContext(ctx, &seq, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+// GetEventsAfter returns the events following seq in order, each with the
+// current household of its user.
+func (r *repository) GetEventsAfter(ctx context.Context, seq int64, limit int) ([]entities.Event, error) {
+	list := make([]entities.Event, 0)
+	query := `
+		SELECT e.*, m.household_id FROM outbox_events e
+		LEFT JOIN household_members m ON m.user_id = e.user_id
+		WHERE e.seq > $1
+		ORDER BY e.seq
+		LIMIT $2
+	`
+
+	err := r.db.SelectContext(ctx, &list, query, seq, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetVisibleEventsAfter is GetEventsAfter limited to the events of the user
+// and of the household members.
+func (r *repository) GetVisibleEventsAfter(ctx context.Context, seq int64, userID uuid.UUID, householdID *uuid.UUID, limit int) ([]entities.Event, error) {
+	list := make([]entities.Event, 0)
+	query := `
+		SELECT e.*, m.household_id FROM outbox_events e
+		LEFT JOIN household_members m ON m.user_id = e.user_id
+		WHERE e.seq > $1 AND (e.user_id = $2 OR m.household_id = $3)
+		ORDER BY e.seq
+		LIMIT $4
+	`
+
+	err := r.db.SelectContext(ctx, &list, query, seq, userID, householdID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
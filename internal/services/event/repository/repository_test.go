@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+var eventColumns = []string{"seq", "event_id", "type", "user_id", "payload", "occurred_at", "dispatched_at", "household_id"}
+
+func TestGetLatestSeq(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(seq), 0) FROM outbox_events")).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(41))
+
+	seq, err := repo.GetLatestSeq(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(41), seq)
+}
+
+func TestGetVisibleEventsAfter(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	userID, memberID, householdID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE e.seq > $1 AND (e.user_id = $2 OR m.household_id = $3)")).
+		WithArgs(int64(10), userID, &householdID, 1000).
+		WillReturnRows(sqlmock.NewRows(eventColumns).
+			AddRow(11, uuid.New(), "ebook.added", memberID, []byte(`{"title":"Трудно быть богом"}`), time.Now(), nil, householdID))
+
+	list, err := repo.GetVisibleEventsAfter(context.Background(), 10, userID, &householdID, 1000)
+
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(11), list[0].Seq)
+	assert.Equal(t, memberID, list[0].UserID)
+	assert.Equal(t, &householdID, list[0].HouseholdID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,207 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/event/dtos"
+	"home-library/internal/services/event/entities"
+	"home-library/internal/services/event/repository"
+	householdRepository "home-library/internal/services/household/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// backlogLimit caps the events replayed to a resuming client; one that
+	// has been away longer should reload its data instead.
+	backlogLimit = 1000
+	pollLimit    = 500
+
+	// streamBuffer is how many events a client may fall behind before its
+	// stream is closed. It reconnects and resumes from where it stopped.
+	streamBuffer = 64
+
+	// gapWait is how long Poll holds back events behind a missing sequence
+	// number. Numbers are taken at insert, so a transaction that commits
+	// late shows up behind later ones; one that rolled back never does.
+	gapWait = 5 * time.Second
+)
+
+type UseCase interface {
+	// Subscribe opens a stream of the events visible to the user. A
+	// positive lastEventID replays the events after it first.
+	Subscribe(ctx context.Context, userID uuid.UUID, lastEventID int64) (*Stream, error)
+	// Poll fetches newly recorded events and broadcasts them to the open
+	// streams. It is meant to run every second or so.
+	Poll(ctx context.Context) error
+	// Close ends all streams and refuses new ones, for shutdown.
+	Close()
+}
+
+// Stream is one client's subscription. C is closed when the server shuts
+// down or the client fell too far behind.
+type Stream struct {
+	Backlog []dtos.EventResponse
+	C       <-chan dtos.EventResponse
+
+	replayed map[int64]bool
+	close    func()
+}
+
+// Duplicate reports whether a live event was already sent with the backlog.
+func (s *Stream) Duplicate(event dtos.EventResponse) bool {
+	return s.replayed[event.ID]
+}
+
+func (s *Stream) Close() {
+	if s.close != nil {
+		s.close()
+	}
+}
+
+type client struct {
+	userID      uuid.UUID
+	householdID *uuid.UUID
+	ch          chan dtos.EventResponse
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	now        func() time.Time
+
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	closed  bool
+
+	// cursor is the last sequence number broadcast; -1 until the first
+	// poll. Only Poll touches it and the gap fields.
+	cursor   int64
+	gapSince time.Time
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository) UseCase {
+	return &useCase{
+		r:          r,
+		households: households,
+		now:        time.Now,
+		clients:    make(map[*client]struct{}),
+		cursor:     -1,
+	}
+}
+
+func (u *useCase) Subscribe(ctx context.Context, userID uuid.UUID, lastEventID int64) (*Stream, error) {
+	var householdID *uuid.UUID
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil && !stdErrors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if member != nil {
+		householdID = &member.HouseholdID
+	}
+
+	c := &client{userID: userID, householdID: householdID, ch: make(chan dtos.EventResponse, streamBuffer)}
+	stream := &Stream{C: c.ch, replayed: make(map[int64]bool), close: func() { u.remove(c) }}
+
+	// Register before reading the backlog, so nothing recorded in between
+	// is lost; the overlap is filtered with Duplicate.
+	u.mu.Lock()
+	if u.closed {
+		close(c.ch)
+	} else {
+		u.clients[c] = struct{}{}
+	}
+	u.mu.Unlock()
+
+	if lastEventID > 0 {
+		backlog, err := u.r.GetVisibleEventsAfter(ctx, lastEventID, userID, householdID, backlogLimit)
+		if err != nil {
+			stream.Close()
+			return nil, err
+		}
+
+		stream.Backlog = make([]dtos.EventResponse, len(backlog))
+		for i, event := range backlog {
+			stream.Backlog[i] = dtos.NewEventResponse(event)
+			stream.replayed[event.Seq] = true
+		}
+	}
+
+	return stream, nil
+}
+
+func (u *useCase) Poll(ctx context.Context) error {
+	if u.cursor < 0 {
+		latest, err := u.r.GetLatestSeq(ctx)
+		if err != nil {
+			return err
+		}
+		u.cursor = latest
+		return nil
+	}
+
+	list, err := u.r.GetEventsAfter(ctx, u.cursor, pollLimit)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range list {
+		if event.Seq != u.cursor+1 {
+			if u.gapSince.IsZero() {
+				u.gapSince = u.now()
+			}
+			if u.now().Sub(u.gapSince) < gapWait {
+				return nil
+			}
+		}
+
+		u.gapSince = time.Time{}
+		u.cursor = event.Seq
+		u.broadcast(event)
+	}
+
+	return nil
+}
+
+func (u *useCase) Close() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.closed = true
+	for c := range u.clients {
+		delete(u.clients, c)
+		close(c.ch)
+	}
+}
+
+func (u *useCase) broadcast(event entities.Event) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	response := dtos.NewEventResponse(event)
+	for c := range u.clients {
+		if !event.VisibleTo(c.userID, c.householdID) {
+			continue
+		}
+
+		select {
+		case c.ch <- response:
+		default:
+			delete(u.clients, c)
+			close(c.ch)
+		}
+	}
+}
+
+func (u *useCase) remove(c *client) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.clients[c]; ok {
+		delete(u.clients, c)
+		close(c.ch)
+	}
+}
@@ -0,0 +1,321 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/event/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/pkg/events"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetLatestSeq(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) GetEventsAfter(ctx context.Context, seq int64, limit int) ([]entities.Event, error) {
+	args := m.Called(ctx, seq, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Event), args.Error(1)
+}
+
+func (m *MockRepository) GetVisibleEventsAfter(ctx context.Context, seq int64, userID uuid.UUID, householdID *uuid.UUID, limit int) ([]entities.Event, error) {
+	args := m.Called(ctx, seq, userID, householdID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Event), args.Error(1)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+func newEvent(seq int64, userID uuid.UUID, householdID *uuid.UUID) entities.Event {
+	return entities.Event{
+		Event:       events.Event{Seq: seq, EventID: uuid.New(), Type: events.TypeEbookAdded, UserID: userID, Payload: []byte(`{}`)},
+		HouseholdID: householdID,
+	}
+}
+
+func newUseCase(r *MockRepository, households *MockHouseholdRepository, cursor int64) *useCase {
+	u := NewUseCase(r, households).(*useCase)
+	u.cursor = cursor
+	return u
+}
+
+func TestSubscribe(t *testing.T) {
+	t.Run("replays the backlog", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, 0)
+		userID, householdID := uuid.New(), uuid.New()
+
+		households.On("GetMembership", mock.Anything, userID).Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID}, nil)
+		repo.On("GetVisibleEventsAfter", mock.Anything, int64(7), userID, &householdID, backlogLimit).
+			Return([]entities.Event{newEvent(8, userID, &householdID), newEvent(10, uuid.New(), &householdID)}, nil)
+
+		stream, err := u.Subscribe(context.Background(), userID, 7)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		require.Len(t, stream.Backlog, 2)
+		assert.Equal(t, int64(8), stream.Backlog[0].ID)
+		assert.True(t, stream.Duplicate(stream.Backlog[1]))
+	})
+
+	t.Run("user outside a household", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, 0)
+		userID := uuid.New()
+
+		households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+
+		stream, err := u.Subscribe(context.Background(), userID, 0)
+		require.NoError(t, err)
+		defer stream.Close()
+
+		assert.Empty(t, stream.Backlog)
+		repo.AssertNotCalled(t, "GetVisibleEventsAfter")
+	})
+
+	t.Run("after close", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, 0)
+		userID := uuid.New()
+
+		households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+		u.Close()
+
+		stream, err := u.Subscribe(context.Background(), userID, 0)
+		require.NoError(t, err)
+
+		_, ok := <-stream.C
+		assert.False(t, ok)
+		stream.Close()
+	})
+}
+
+func TestPoll(t *testing.T) {
+	subscribe := func(t *testing.T, u *useCase, households *MockHouseholdRepository, userID uuid.UUID, householdID *uuid.UUID) *Stream {
+		if householdID == nil {
+			households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+		} else {
+			households.On("GetMembership", mock.Anything, userID).Return(&householdEntities.Member{HouseholdID: *householdID, UserID: userID}, nil)
+		}
+		stream, err := u.Subscribe(context.Background(), userID, 0)
+		require.NoError(t, err)
+		t.Cleanup(stream.Close)
+		return stream
+	}
+
+	received := func(stream *Stream) []int64 {
+		var seqs []int64
+		for {
+			select {
+			case event := <-stream.C:
+				seqs = append(seqs, event.ID)
+			default:
+				return seqs
+			}
+		}
+	}
+
+	t.Run("starts at the latest event", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, -1)
+
+		repo.On("GetLatestSeq", mock.Anything).Return(int64(42), nil)
+
+		require.NoError(t, u.Poll(context.Background()))
+		assert.Equal(t, int64(42), u.cursor)
+		repo.AssertNotCalled(t, "GetEventsAfter")
+	})
+
+	t.Run("streams household events only", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, 5)
+		alice, bob, stranger := uuid.New(), uuid.New(), uuid.New()
+		household := uuid.New()
+
+		aliceStream := subscribe(t, u, households, alice, &household)
+		strangerStream := subscribe(t, u, households, stranger, nil)
+
+		repo.On("GetEventsAfter", mock.Anything, int64(5), pollLimit).Return([]entities.Event{
+			newEvent(6, bob, &household),
+			newEvent(7, stranger, nil),
+			newEvent(8, alice, &household),
+		}, nil)
+
+		require.NoError(t, u.Poll(context.Background()))
+
+		assert.Equal(t, []int64{6, 8}, received(aliceStream))
+		assert.Equal(t, []int64{7}, received(strangerStream))
+		assert.Equal(t, int64(8), u.cursor)
+	})
+
+	t.Run("waits for a gap to fill", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, 5)
+		now := time.Now()
+		u.now = func() time.Time { return now }
+		userID := uuid.New()
+		stream := subscribe(t, u, households, userID, nil)
+
+		repo.On("GetEventsAfter", mock.Anything, int64(5), pollLimit).Return([]entities.Event{
+			newEvent(6, userID, nil),
+			newEvent(8, userID, nil),
+		}, nil)
+
+		require.NoError(t, u.Poll(context.Background()))
+		assert.Equal(t, []int64{6}, received(stream))
+
+		repo.On("GetEventsAfter", mock.Anything, int64(6), pollLimit).Return([]entities.Event{
+			newEvent(8, userID, nil),
+		}, nil)
+
+		require.NoError(t, u.Poll(context.Background()))
+		assert.Empty(t, received(stream))
+
+		// The transaction holding 7 rolled back.
+		now = now.Add(gapWait)
+		require.NoError(t, u.Poll(context.Background()))
+		assert.Equal(t, []int64{8}, received(stream))
+	})
+
+	t.Run("drops clients that fall behind", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, 0)
+		userID := uuid.New()
+		stream := subscribe(t, u, households, userID, nil)
+
+		list := make([]entities.Event, streamBuffer+1)
+		for i := range list {
+			list[i] = newEvent(int64(i+1), userID, nil)
+		}
+		repo.On("GetEventsAfter", mock.Anything, int64(0), pollLimit).Return(list, nil)
+
+		require.NoError(t, u.Poll(context.Background()))
+
+		count := 0
+		for range stream.C {
+			count++
+		}
+		assert.Equal(t, streamBuffer, count)
+	})
+}
+
+func TestClose(t *testing.T) {
+	repo, households := new(MockRepository), new(MockHouseholdRepository)
+	u := newUseCase(repo, households, 0)
+	userID := uuid.New()
+
+	households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+	stream, err := u.Subscribe(context.Background(), userID, 0)
+	require.NoError(t, err)
+
+	u.Close()
+	_, ok := <-stream.C
+	assert.False(t, ok)
+
+	// Closing the stream afterwards is harmless.
+	stream.Close()
+}
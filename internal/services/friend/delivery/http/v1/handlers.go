@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/friend/dtos"
+	"home-library/internal/services/friend/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) RequestFriendship(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.RequestFriendshipRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	status, err := h.u.RequestFriendship(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to request friendship")
+	}
+
+	return c.JSON(http.StatusCreated, dtos.RequestFriendshipResponse{Status: status})
+}
+
+func (h *handler) GetFriends(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	friends, err := h.u.GetFriends(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get friends")
+	}
+
+	return c.JSON(http.StatusOK, friends)
+}
+
+func (h *handler) AcceptFriendship(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	householdID, err := uuid.Parse(c.Param("household_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.AcceptFriendship(c.Request().Context(), userID, householdID); err != nil {
+		return h.handleError(c, err, "failed to accept friendship")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) RemoveFriendship(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	householdID, err := uuid.Parse(c.Param("household_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.RemoveFriendship(c.Request().Context(), userID, householdID); err != nil {
+		return h.handleError(c, err, "failed to remove friendship")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrInvalidEmail):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Некорректный email", nil))
+	case errors.Is(err, customErrors.ErrFriendNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Пользователь с таким email не состоит в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrFriendshipNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Заявка в друзья не найдена", nil))
+	case errors.Is(err, customErrors.ErrFriendshipExists):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Вы уже друзья или заявка уже отправлена", nil))
+	case errors.Is(err, customErrors.ErrFriendOwnHousehold):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Нельзя добавить в друзья свою домашнюю библиотеку", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
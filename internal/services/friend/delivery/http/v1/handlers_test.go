@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"home-library/internal/services/friend/dtos"
+	"home-library/internal/services/friend/entities"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) RequestFriendship(ctx context.Context, userID uuid.UUID, payload dtos.RequestFriendshipRequest) (entities.Status, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(entities.Status), args.Error(1)
+}
+
+func (m *MockUseCase) GetFriends(ctx context.Context, userID uuid.UUID) ([]dtos.FriendResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.FriendResponse), args.Error(1)
+}
+
+func (m *MockUseCase) AcceptFriendship(ctx context.Context, userID uuid.UUID, householdID uuid.UUID) error {
+	return m.Called(ctx, userID, householdID).Error(0)
+}
+
+func (m *MockUseCase) RemoveFriendship(ctx context.Context, userID uuid.UUID, householdID uuid.UUID) error {
+	return m.Called(ctx, userID, householdID).Error(0)
+}
+
+func newContext(e *echo.Echo, method string, target string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestRequestFriendship(t *testing.T) {
+	e := echo.New()
+
+	t.Run("request is sent", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+		c, rec := newContext(e, http.MethodPost, "/friends", `{"email":"petrov@example.com"}`, userID)
+
+		mockUseCase.On("RequestFriendship", context.Background(), userID, dtos.RequestFriendshipRequest{Email: "petrov@example.com"}).
+			Return(entities.StatusPending, nil)
+
+		err := handler.RequestFriendship(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response dtos.RequestFriendshipResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, entities.StatusPending, response.Status)
+	})
+
+	t.Run("invalid email", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		c, rec := newContext(e, http.MethodPost, "/friends", `{"email":"petrov"}`, uuid.New())
+
+		err := handler.RequestFriendship(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "RequestFriendship", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already friends", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+		c, rec := newContext(e, http.MethodPost, "/friends", `{"email":"petrov@example.com"}`, userID)
+
+		mockUseCase.On("RequestFriendship", context.Background(), userID, mock.Anything).
+			Return(entities.Status(""), customErrors.ErrFriendshipExists)
+
+		err := handler.RequestFriendship(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestAcceptFriendship(t *testing.T) {
+	e := echo.New()
+
+	t.Run("no pending request", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, householdID := uuid.New(), uuid.New()
+		c, rec := newContext(e, http.MethodPost, "/friends/x/accept", "", userID)
+		c.SetParamNames("household_id")
+		c.SetParamValues(householdID.String())
+
+		mockUseCase.On("AcceptFriendship", context.Background(), userID, householdID).Return(customErrors.ErrFriendshipNotFound)
+
+		err := handler.AcceptFriendship(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,10 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) FriendRoutes(domain *echo.Group) {
+	domain.POST("/friends", h.RequestFriendship)
+	domain.GET("/friends", h.GetFriends)
+	domain.POST("/friends/:household_id/accept", h.AcceptFriendship)
+	domain.DELETE("/friends/:household_id", h.RemoveFriendship)
+}
@@ -0,0 +1,43 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/friend/entities"
+	"time"
+)
+
+// RequestFriendshipRequest names the household to befriend by the email of
+// any of its members.
+type RequestFriendshipRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (r *RequestFriendshipRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type RequestFriendshipResponse struct {
+	Status entities.Status `json:"status"`
+}
+
+type FriendResponse struct {
+	HouseholdID uuid.UUID       `json:"household_id"`
+	Name        string          `json:"name"`
+	Status      entities.Status `json:"status"`
+	Incoming    bool            `json:"incoming"`
+	CreatedAt   time.Time       `json:"created_at"`
+	AcceptedAt  *time.Time      `json:"accepted_at,omitempty"`
+}
+
+func NewFriendResponse(friend entities.Friend) FriendResponse {
+	return FriendResponse{
+		HouseholdID: friend.HouseholdID,
+		Name:        friend.Name,
+		Status:      friend.Status,
+		Incoming:    friend.Incoming,
+		CreatedAt:   friend.CreatedAt,
+		AcceptedAt:  friend.AcceptedAt,
+	}
+}
@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusAccepted Status = "accepted"
+)
+
+// Friendship lets two households browse each other's catalog and borrow
+// books. One household asks, the other accepts.
+type Friendship struct {
+	RequesterID uuid.UUID  `db:"requester_id"`
+	AddresseeID uuid.UUID  `db:"addressee_id"`
+	Status      Status     `db:"status"`
+	CreatedAt   time.Time  `db:"created_at"`
+	AcceptedAt  *time.Time `db:"accepted_at"`
+}
+
+func NewFriendship(requesterID uuid.UUID, addresseeID uuid.UUID) *Friendship {
+	return &Friendship{
+		RequesterID: requesterID,
+		AddresseeID: addresseeID,
+		Status:      StatusPending,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Friend is a friendship seen from one of its households.
+type Friend struct {
+	HouseholdID uuid.UUID `db:"household_id"`
+	Name        string    `db:"name"`
+	Status      Status    `db:"status"`
+	// Incoming is set when the other household asked.
+	Incoming   bool       `db:"incoming"`
+	CreatedAt  time.Time  `db:"created_at"`
+	AcceptedAt *time.Time `db:"accepted_at"`
+}
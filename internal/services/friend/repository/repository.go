@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/friend/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/storage"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository methods take the caller's household ID first, and only touch
+// friendships that household is part of.
+type Repository interface {
+	// FindHouseholdByEmail returns the household of the live user with the
+	// normalized email.
+	FindHouseholdByEmail(ctx context.Context, email string) (uuid.UUID, error)
+
+	CreateFriendship(ctx context.Context, friendship *entities.Friendship) error
+	GetFriends(ctx context.Context, householdID uuid.UUID) ([]entities.Friend, error)
+	// AcceptFriendship accepts the pending request the other household
+	// sent to this one.
+	AcceptFriendship(ctx context.Context, householdID uuid.UUID, requesterID uuid.UUID, now time.Time) error
+	// DeleteFriendship ends a friendship or drops a request, whichever
+	// household sent it.
+	DeleteFriendship(ctx context.Context, householdID uuid.UUID, otherID uuid.UUID) error
+	// AreFriends reports whether the households have an accepted friendship.
+	AreFriends(ctx context.Context, householdID uuid.UUID, otherID uuid.UUID) (bool, error)
+}
+
+// constraints translates a second request between the same households, in
+// either direction, and a household deleted meanwhile.
+var constraints = storage.Constraints{
+	"household_friendships_pkey":              errors.ErrFriendshipExists,
+	"idx_household_friendships_pair":          errors.ErrFriendshipExists,
+	"household_friendships_addressee_id_fkey": errors.ErrFriendNotFound,
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) FindHouseholdByEmail(ctx context.Context, email string) (uuid.UUID, error) {
+	var householdID uuid.UUID
+	query := `
+		SELECT m.household_id FROM household_members m
+		JOIN users u ON u.user_id = m.user_id
+		WHERE lower(u.email) = lower($1) AND u.deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &householdID, query, email)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return householdID, nil
+}
+
+func (r *repository) CreateFriendship(ctx context.Context, friendship *entities.Friendship) error {
+	query := `
+		INSERT INTO household_friendships (requester_id, addressee_id, status, created_at)
+		VALUES (:requester_id, :addressee_id, :status, :created_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, friendship)
+	return constraints.Map(err)
+}
+
+func (r *repository) GetFriends(ctx context.Context, householdID uuid.UUID) ([]entities.Friend, error) {
+	friends := make([]entities.Friend, 0)
+	query := `
+		SELECT h.household_id, h.name, f.status, f.addressee_id = $1 AS incoming, f.created_at, f.accepted_at
+		FROM household_friendships f
+		JOIN households h ON h.household_id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+		WHERE (f.requester_id = $1 OR f.addressee_id = $1) AND h.deleted_at IS NULL
+		ORDER BY f.status, h.name
+	`
+
+	err := r.db.SelectContext(ctx, &friends, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return friends, nil
+}
+
+func (r *repository) AcceptFriendship(ctx context.Context, householdID uuid.UUID, requesterID uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE household_friendships
+		SET status = 'accepted', accepted_at = $3
+		WHERE addressee_id = $1 AND requester_id = $2 AND status = 'pending'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, requesterID, now)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) DeleteFriendship(ctx context.Context, householdID uuid.UUID, otherID uuid.UUID) error {
+	query := `
+		DELETE FROM household_friendships
+		WHERE (requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, otherID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) AreFriends(ctx context.Context, householdID uuid.UUID, otherID uuid.UUID) (bool, error) {
+	var friends bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM household_friendships
+			WHERE status = 'accepted'
+				AND ((requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1))
+		)
+	`
+
+	err := r.db.GetContext(ctx, &friends, query, householdID, otherID)
+	if err != nil {
+		return false, err
+	}
+
+	return friends, nil
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/friend/entities"
+	customErrors "home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestCreateFriendship(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	tests := []struct {
+		name       string
+		code       pq.ErrorCode
+		constraint string
+		err        error
+	}{
+		{"same request again", "23505", "household_friendships_pkey", customErrors.ErrFriendshipExists},
+		{"request in the other direction", "23505", "idx_household_friendships_pair", customErrors.ErrFriendshipExists},
+		{"household deleted meanwhile", "23503", "household_friendships_addressee_id_fkey", customErrors.ErrFriendNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			friendship := entities.NewFriendship(uuid.New(), uuid.New())
+
+			mock.ExpectExec("INSERT INTO household_friendships").
+				WithArgs(friendship.RequesterID, friendship.AddresseeID, friendship.Status, friendship.CreatedAt).
+				WillReturnError(&pq.Error{Code: tt.code, Constraint: tt.constraint})
+
+			err := repo.CreateFriendship(context.Background(), friendship)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestAcceptFriendship(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("only the addressee accepts a pending request", func(t *testing.T) {
+		householdID, requesterID, now := uuid.New(), uuid.New(), time.Now()
+
+		mock.ExpectExec(`WHERE addressee_id = \$1 AND requester_id = \$2 AND status = 'pending'`).
+			WithArgs(householdID, requesterID, now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.AcceptFriendship(context.Background(), householdID, requesterID, now)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAreFriends(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("accepted friendship in either direction", func(t *testing.T) {
+		householdID, otherID := uuid.New(), uuid.New()
+
+		mock.ExpectQuery(`SELECT EXISTS .+status = 'accepted'`).
+			WithArgs(householdID, otherID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		friends, err := repo.AreFriends(context.Background(), householdID, otherID)
+
+		assert.NoError(t, err)
+		assert.True(t, friends)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
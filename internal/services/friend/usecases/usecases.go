@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/friend/dtos"
+	"home-library/internal/services/friend/entities"
+	"home-library/internal/services/friend/repository"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/pkg/contact"
+	"home-library/pkg/errors"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types sent to the owners of the other household.
+const (
+	NotificationRequested = "friendship.requested"
+	NotificationAccepted  = "friendship.accepted"
+)
+
+// Notifier tells users about changes that concern them; the notification
+// use case is the implementation.
+type Notifier interface {
+	Notify(ctx context.Context, userIDs []uuid.UUID, typ string, payload any) error
+}
+
+// Notice is the payload of the friendship notifications.
+type Notice struct {
+	HouseholdID uuid.UUID `json:"household_id"`
+	Name        string    `json:"name"`
+}
+
+type UseCase interface {
+	// RequestFriendship asks the household of the member with the email to
+	// become friends. If that household has already asked, the friendship
+	// is accepted instead.
+	RequestFriendship(ctx context.Context, userID uuid.UUID, payload dtos.RequestFriendshipRequest) (entities.Status, error)
+	GetFriends(ctx context.Context, userID uuid.UUID) ([]dtos.FriendResponse, error)
+	AcceptFriendship(ctx context.Context, userID uuid.UUID, householdID uuid.UUID) error
+	// RemoveFriendship declines or withdraws a request, or ends a
+	// friendship.
+	RemoveFriendship(ctx context.Context, userID uuid.UUID, householdID uuid.UUID) error
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	notifier   Notifier
+	tx         transaction.Transactor
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository, notifier Notifier, tx transaction.Transactor) UseCase {
+	return &useCase{r: r, households: households, notifier: notifier, tx: tx}
+}
+
+func (u *useCase) RequestFriendship(ctx context.Context, userID uuid.UUID, payload dtos.RequestFriendshipRequest) (entities.Status, error) {
+	member, err := u.manager(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	email, err := contact.NormalizeEmail(payload.Email)
+	if err != nil {
+		return "", err
+	}
+
+	otherID, err := u.r.FindHouseholdByEmail(ctx, email)
+	if err != nil {
+		return "", mapNoRows(err, errors.ErrFriendNotFound)
+	}
+	if otherID == member.HouseholdID {
+		return "", errors.ErrFriendOwnHousehold
+	}
+
+	var status entities.Status
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		err := u.r.AcceptFriendship(ctx, member.HouseholdID, otherID, time.Now())
+		switch {
+		case err == nil:
+			status = entities.StatusAccepted
+			return u.notifyOwners(ctx, otherID, member.HouseholdID, NotificationAccepted)
+		case !stdErrors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		if err := u.r.CreateFriendship(ctx, entities.NewFriendship(member.HouseholdID, otherID)); err != nil {
+			return err
+		}
+		status = entities.StatusPending
+		return u.notifyOwners(ctx, otherID, member.HouseholdID, NotificationRequested)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+func (u *useCase) GetFriends(ctx context.Context, userID uuid.UUID) ([]dtos.FriendResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	friends, err := u.r.GetFriends(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.FriendResponse, len(friends))
+	for i, friend := range friends {
+		response[i] = dtos.NewFriendResponse(friend)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) AcceptFriendship(ctx context.Context, userID uuid.UUID, householdID uuid.UUID) error {
+	member, err := u.manager(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		err := u.r.AcceptFriendship(ctx, member.HouseholdID, householdID, time.Now())
+		if err != nil {
+			return mapNoRows(err, errors.ErrFriendshipNotFound)
+		}
+
+		return u.notifyOwners(ctx, householdID, member.HouseholdID, NotificationAccepted)
+	})
+}
+
+func (u *useCase) RemoveFriendship(ctx context.Context, userID uuid.UUID, householdID uuid.UUID) error {
+	member, err := u.manager(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.DeleteFriendship(ctx, member.HouseholdID, householdID), errors.ErrFriendshipNotFound)
+}
+
+// notifyOwners tells the members of the household who manage its friends
+// about a change made by the other one.
+func (u *useCase) notifyOwners(ctx context.Context, householdID uuid.UUID, fromID uuid.UUID, typ string) error {
+	from, err := u.households.GetHousehold(ctx, fromID)
+	if err != nil {
+		return err
+	}
+
+	members, err := u.households.GetMembers(ctx, householdID)
+	if err != nil {
+		return err
+	}
+
+	var owners []uuid.UUID
+	for _, m := range members {
+		if m.Role.CanManageFriends() {
+			owners = append(owners, m.UserID)
+		}
+	}
+
+	return u.notifier.Notify(ctx, owners, typ, Notice{HouseholdID: from.HouseholdID, Name: from.Name})
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+// manager returns the caller's membership if their role may manage the
+// household's friends.
+func (u *useCase) manager(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManageFriends() {
+		return nil, errors.ErrHouseholdForbidden
+	}
+	return member, nil
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
@@ -0,0 +1,286 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/friend/dtos"
+	"home-library/internal/services/friend/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) FindHouseholdByEmail(ctx context.Context, email string) (uuid.UUID, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) CreateFriendship(ctx context.Context, friendship *entities.Friendship) error {
+	return m.Called(ctx, friendship).Error(0)
+}
+
+func (m *MockRepository) GetFriends(ctx context.Context, householdID uuid.UUID) ([]entities.Friend, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Friend), args.Error(1)
+}
+
+func (m *MockRepository) AcceptFriendship(ctx context.Context, householdID uuid.UUID, requesterID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, requesterID, now).Error(0)
+}
+
+func (m *MockRepository) DeleteFriendship(ctx context.Context, householdID uuid.UUID, otherID uuid.UUID) error {
+	return m.Called(ctx, householdID, otherID).Error(0)
+}
+
+func (m *MockRepository) AreFriends(ctx context.Context, householdID uuid.UUID, otherID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, householdID, otherID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, userIDs []uuid.UUID, typ string, payload any) error {
+	return m.Called(ctx, userIDs, typ, payload).Error(0)
+}
+
+// passthroughTx runs the unit of work directly.
+type passthroughTx struct{}
+
+func (passthroughTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	households *MockHouseholdRepository
+	notifier   *MockNotifier
+}
+
+func newUseCase() (UseCase, mocks) {
+	m := mocks{new(MockRepository), new(MockHouseholdRepository), new(MockNotifier)}
+	return NewUseCase(m.repo, m.households, m.notifier, passthroughTx{}), m
+}
+
+func (m mocks) member(userID uuid.UUID, householdID uuid.UUID, role householdEntities.Role) {
+	m.households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: role}, nil)
+}
+
+// owners makes ownerID the owner of householdID next to an editor, and
+// names fromID for the notice.
+func (m mocks) owners(householdID uuid.UUID, ownerID uuid.UUID, fromID uuid.UUID, name string) {
+	m.households.On("GetHousehold", mock.Anything, fromID).
+		Return(&householdEntities.Household{HouseholdID: fromID, Name: name}, nil)
+	m.households.On("GetMembers", mock.Anything, householdID).Return([]householdEntities.Member{
+		{UserID: ownerID, Role: householdEntities.RoleOwner},
+		{UserID: uuid.New(), Role: householdEntities.RoleEditor},
+	}, nil)
+}
+
+func TestRequestFriendship(t *testing.T) {
+	t.Run("request is sent to the owners of the other household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, otherID, ownerID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+		m.owners(otherID, ownerID, householdID, "Ивановы")
+
+		m.repo.On("FindHouseholdByEmail", mock.Anything, "petrov@example.com").Return(otherID, nil)
+		m.repo.On("AcceptFriendship", mock.Anything, householdID, otherID, mock.Anything).Return(sql.ErrNoRows)
+		m.repo.On("CreateFriendship", mock.Anything, mock.MatchedBy(func(f *entities.Friendship) bool {
+			return f.RequesterID == householdID && f.AddresseeID == otherID && f.Status == entities.StatusPending
+		})).Return(nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{ownerID}, NotificationRequested, Notice{HouseholdID: householdID, Name: "Ивановы"}).Return(nil)
+
+		status, err := u.RequestFriendship(context.Background(), userID, dtos.RequestFriendshipRequest{Email: " Petrov@Example.com"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.StatusPending, status)
+		m.notifier.AssertExpectations(t)
+	})
+
+	t.Run("request back accepts the pending one", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, otherID, ownerID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+		m.owners(otherID, ownerID, householdID, "Ивановы")
+
+		m.repo.On("FindHouseholdByEmail", mock.Anything, "petrov@example.com").Return(otherID, nil)
+		m.repo.On("AcceptFriendship", mock.Anything, householdID, otherID, mock.Anything).Return(nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{ownerID}, NotificationAccepted, mock.Anything).Return(nil)
+
+		status, err := u.RequestFriendship(context.Background(), userID, dtos.RequestFriendshipRequest{Email: "petrov@example.com"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.StatusAccepted, status)
+		m.repo.AssertNotCalled(t, "CreateFriendship", mock.Anything, mock.Anything)
+	})
+
+	t.Run("own household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID := uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		m.repo.On("FindHouseholdByEmail", mock.Anything, "me@example.com").Return(householdID, nil)
+
+		_, err := u.RequestFriendship(context.Background(), userID, dtos.RequestFriendshipRequest{Email: "me@example.com"})
+
+		assert.ErrorIs(t, err, errors.ErrFriendOwnHousehold)
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+		m.member(userID, uuid.New(), householdEntities.RoleOwner)
+
+		m.repo.On("FindHouseholdByEmail", mock.Anything, "nobody@example.com").Return(uuid.Nil, sql.ErrNoRows)
+
+		_, err := u.RequestFriendship(context.Background(), userID, dtos.RequestFriendshipRequest{Email: "nobody@example.com"})
+
+		assert.ErrorIs(t, err, errors.ErrFriendNotFound)
+	})
+
+	t.Run("editors cannot befriend households", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+		m.member(userID, uuid.New(), householdEntities.RoleEditor)
+
+		_, err := u.RequestFriendship(context.Background(), userID, dtos.RequestFriendshipRequest{Email: "petrov@example.com"})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+		m.repo.AssertNotCalled(t, "FindHouseholdByEmail", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAcceptFriendship(t *testing.T) {
+	t.Run("no pending request from the household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, otherID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		m.repo.On("AcceptFriendship", mock.Anything, householdID, otherID, mock.Anything).Return(sql.ErrNoRows)
+
+		err := u.AcceptFriendship(context.Background(), userID, otherID)
+
+		assert.ErrorIs(t, err, errors.ErrFriendshipNotFound)
+		m.notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRemoveFriendship(t *testing.T) {
+	t.Run("friendship is removed", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, otherID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		m.repo.On("DeleteFriendship", mock.Anything, householdID, otherID).Return(nil)
+
+		err := u.RemoveFriendship(context.Background(), userID, otherID)
+
+		assert.NoError(t, err)
+		m.repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"context"
+	"home-library/internal/services/goal/dtos"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/goals"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) CreateGoal(ctx context.Context, userID uuid.UUID, payload dtos.GoalRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) GetGoals(ctx context.Context, userID uuid.UUID, request dtos.ListGoalsRequest) ([]dtos.GoalResponse, error) {
+	args := m.Called(ctx, userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.GoalResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) (*dtos.GoalResponse, error) {
+	args := m.Called(ctx, userID, goalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.GoalResponse), args.Error(1)
+}
+
+func (m *MockUseCase) UpdateGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID, payload dtos.GoalRequest) error {
+	return m.Called(ctx, userID, goalID, payload).Error(0)
+}
+
+func (m *MockUseCase) DeleteGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) error {
+	return m.Called(ctx, userID, goalID).Error(0)
+}
+
+func newContext(e *echo.Echo, method string, target string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestCreateGoal(t *testing.T) {
+	e := echo.New()
+
+	t.Run("goal is created", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, goalID := uuid.New(), uuid.New()
+		c, rec := newContext(e, http.MethodPost, "/goals", `{"year":2025,"metric":"books","target":24}`, userID)
+
+		mockUseCase.On("CreateGoal", context.Background(), userID, dtos.GoalRequest{Year: 2025, Metric: goals.MetricBooks, Target: 24}).
+			Return(goalID, nil)
+
+		err := handler.CreateGoal(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), goalID.String())
+	})
+
+	t.Run("unknown metric", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		c, rec := newContext(e, http.MethodPost, "/goals", `{"year":2025,"metric":"hours","target":24}`, uuid.New())
+
+		err := handler.CreateGoal(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "CreateGoal", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetGoal(t *testing.T) {
+	e := echo.New()
+
+	t.Run("goal not found", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, goalID := uuid.New(), uuid.New()
+		c, rec := newContext(e, http.MethodGet, "/goals/x", "", userID)
+		c.SetParamNames("goal_id")
+		c.SetParamValues(goalID.String())
+
+		mockUseCase.On("GetGoal", context.Background(), userID, goalID).Return(nil, customErrors.ErrGoalNotFound)
+
+		err := handler.GetGoal(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,11 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) GoalRoutes(domain *echo.Group) {
+	domain.POST("/goals", h.CreateGoal)
+	domain.GET("/goals", h.GetGoals)
+	domain.GET("/goals/:goal_id", h.GetGoal)
+	domain.PUT("/goals/:goal_id", h.UpdateGoal)
+	domain.DELETE("/goals/:goal_id", h.DeleteGoal)
+}
@@ -0,0 +1,79 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/goal/entities"
+	"home-library/pkg/goals"
+)
+
+// GoalRequest is a plain target when the filters are empty and a challenge
+// otherwise.
+type GoalRequest struct {
+	Name     string       `json:"name" validate:"max=255"`
+	Year     int          `json:"year" validate:"required,gte=1,lte=9999"`
+	Metric   goals.Metric `json:"metric" validate:"required,oneof=books pages series"`
+	Target   int          `json:"target" validate:"required,gt=0,lte=1000000"`
+	Language string       `json:"language" validate:"omitempty,bcp47_language_tag"`
+	Author   string       `json:"author" validate:"max=255"`
+	Tag      string       `json:"tag" validate:"max=100"`
+}
+
+func (r *GoalRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type CreateGoalResponse struct {
+	GoalID uuid.UUID `json:"goal_id"`
+}
+
+type ListGoalsRequest struct {
+	// Year defaults to the current one.
+	Year int `query:"year" validate:"gte=0,lte=9999"`
+}
+
+func (r *ListGoalsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type ProgressResponse struct {
+	Done      int          `json:"done"`
+	Target    int          `json:"target"`
+	Expected  float64      `json:"expected"`
+	Projected int          `json:"projected"`
+	Status    goals.Status `json:"status"`
+}
+
+type GoalResponse struct {
+	GoalID   uuid.UUID        `json:"goal_id"`
+	Name     string           `json:"name"`
+	Year     int              `json:"year"`
+	Metric   goals.Metric     `json:"metric"`
+	Target   int              `json:"target"`
+	Language string           `json:"language,omitempty"`
+	Author   string           `json:"author,omitempty"`
+	Tag      string           `json:"tag,omitempty"`
+	Progress ProgressResponse `json:"progress"`
+}
+
+func NewGoalResponse(goal entities.Goal, progress goals.Progress) GoalResponse {
+	return GoalResponse{
+		GoalID:   goal.GoalID,
+		Name:     goal.Name,
+		Year:     goal.Year,
+		Metric:   goal.Metric,
+		Target:   goal.Target,
+		Language: goal.Language,
+		Author:   goal.Author,
+		Tag:      goal.Tag,
+		Progress: ProgressResponse{
+			Done:      progress.Done,
+			Target:    progress.Target,
+			Expected:  progress.Expected,
+			Projected: progress.Projected,
+			Status:    progress.Status,
+		},
+	}
+}
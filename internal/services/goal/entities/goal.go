@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"home-library/pkg/goals"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Goal is a member's yearly reading target. Name is free text the member
+// gives a challenge, such as "Read 5 books in English".
+type Goal struct {
+	GoalID    uuid.UUID    `db:"goal_id"`
+	UserID    uuid.UUID    `db:"user_id"`
+	Name      string       `db:"name"`
+	Year      int          `db:"year"`
+	Metric    goals.Metric `db:"metric"`
+	Target    int          `db:"target"`
+	Language  string       `db:"language"`
+	Author    string       `db:"author"`
+	Tag       string       `db:"tag"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+}
+
+func NewGoal(userID uuid.UUID) *Goal {
+	now := time.Now()
+	return &Goal{
+		GoalID:    uuid.New(),
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Rule is the goal as the evaluation sees it.
+func (g *Goal) Rule() goals.Goal {
+	return goals.Goal{
+		Year:     g.Year,
+		Metric:   g.Metric,
+		Target:   g.Target,
+		Language: g.Language,
+		Author:   g.Author,
+		Tag:      g.Tag,
+	}
+}
+
+// FinishedReading is a finished reading along with what goals filter and
+// count by.
+type FinishedReading struct {
+	FinishedAt time.Time      `db:"finished_at"`
+	Pages      *int           `db:"pages"`
+	Language   string         `db:"language"`
+	Authors    pq.StringArray `db:"authors"`
+	Tags       pq.StringArray `db:"tags"`
+	// CompletesSeries marks the reading after which the member has
+	// finished every book of its series in the household.
+	CompletesSeries bool `db:"completes_series"`
+}
+
+func (r FinishedReading) Entry() goals.Entry {
+	entry := goals.Entry{
+		FinishedAt:      r.FinishedAt,
+		Language:        r.Language,
+		Authors:         r.Authors,
+		Tags:            r.Tags,
+		CompletesSeries: r.CompletesSeries,
+	}
+	if r.Pages != nil {
+		entry.Pages = *r.Pages
+	}
+	return entry
+}
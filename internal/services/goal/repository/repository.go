@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/goal/entities"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository methods take the user ID so that members only see and change
+// their own goals.
+type Repository interface {
+	CreateGoal(ctx context.Context, goal *entities.Goal) (uuid.UUID, error)
+	GetGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) (*entities.Goal, error)
+	GetGoals(ctx context.Context, userID uuid.UUID, year int) ([]entities.Goal, error)
+	UpdateGoal(ctx context.Context, goal *entities.Goal) error
+	DeleteGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) error
+
+	// GetFinishedReadings lists the user's readings of household books
+	// finished in [from, to), oldest first.
+	GetFinishedReadings(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, from time.Time, to time.Time) ([]entities.FinishedReading, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateGoal(ctx context.Context, goal *entities.Goal) (uuid.UUID, error) {
+	query := `
+		INSERT INTO reading_goals (goal_id, user_id, name, year, metric, target, language, author, tag, created_at, updated_at)
+		VALUES (:goal_id, :user_id, :name, :year, :metric, :target, :language, :author, :tag, :created_at, :updated_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, goal)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return goal.GoalID, nil
+}
+
+func (r *repository) GetGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) (*entities.Goal, error) {
+	var goal entities.Goal
+	query := `SELECT * FROM reading_goals WHERE user_id = $1 AND goal_id = $2`
+
+	err := r.db.GetContext(ctx, &goal, query, userID, goalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goal, nil
+}
+
+func (r *repository) GetGoals(ctx context.Context, userID uuid.UUID, year int) ([]entities.Goal, error) {
+	goals := make([]entities.Goal, 0)
+	query := `
+		SELECT * FROM reading_goals
+		WHERE user_id = $1 AND year = $2
+		ORDER BY created_at
+	`
+
+	err := r.db.SelectContext(ctx, &goals, query, userID, year)
+	if err != nil {
+		return nil, err
+	}
+
+	return goals, nil
+}
+
+func (r *repository) UpdateGoal(ctx context.Context, goal *entities.Goal) error {
+	query := `
+		UPDATE reading_goals
+		SET name = :name, year = :year, metric = :metric, target = :target,
+			language = :language, author = :author, tag = :tag, updated_at = :updated_at
+		WHERE goal_id = :goal_id AND user_id = :user_id
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, goal)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) DeleteGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM reading_goals WHERE user_id = $1 AND goal_id = $2`, userID, goalID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) GetFinishedReadings(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, from time.Time, to time.Time) ([]entities.FinishedReading, error) {
+	readings := make([]entities.FinishedReading, 0)
+	// A reading completes its series when it is the last of the member's
+	// first readings of the series' books, and no book of the series in
+	// the household is left unread.
+	query := `
+		SELECT r.finished_at, b.pages, b.language, b.authors, b.tags,
+			b.series <> '' AND r.finished_at = (
+				SELECT max(first_finished_at) FROM (
+					SELECT min(sr.finished_at) AS first_finished_at
+					FROM readings sr
+					JOIN books sb ON sb.book_id = sr.book_id
+					WHERE sr.user_id = r.user_id AND sb.household_id = b.household_id AND sb.series = b.series
+						AND sr.finished_at IS NOT NULL
+					GROUP BY sr.book_id
+				) firsts
+			) AND NOT EXISTS (
+				SELECT 1 FROM books ub
+				WHERE ub.household_id = b.household_id AND ub.series = b.series AND NOT EXISTS (
+					SELECT 1 FROM readings ur
+					WHERE ur.book_id = ub.book_id AND ur.user_id = r.user_id AND ur.finished_at IS NOT NULL
+				)
+			) AS completes_series
+		FROM readings r
+		JOIN books b ON b.book_id = r.book_id
+		WHERE r.household_id = $1 AND r.user_id = $2 AND r.finished_at >= $3 AND r.finished_at < $4
+		ORDER BY r.finished_at
+	`
+
+	err := r.db.SelectContext(ctx, &readings, query, householdID, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return readings, nil
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
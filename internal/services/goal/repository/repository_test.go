@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/goal/entities"
+	"home-library/pkg/goals"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestUpdateGoal(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("goal of another user is not updated", func(t *testing.T) {
+		goal := entities.NewGoal(uuid.New())
+		goal.Year, goal.Metric, goal.Target = 2025, goals.MetricPages, 10000
+
+		mock.ExpectExec(`UPDATE reading_goals .+ WHERE goal_id = \? AND user_id = \?`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdateGoal(context.Background(), goal)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetFinishedReadings(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	columns := []string{"finished_at", "pages", "language", "authors", "tags", "completes_series"}
+
+	t.Run("readings of the year with their books", func(t *testing.T) {
+		householdID, userID := uuid.New(), uuid.New()
+		from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+		finished := time.Date(2025, time.February, 3, 20, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows(columns).
+			AddRow(finished, 384, "ru", "{\"Аркадий Стругацкий\",\"Борис Стругацкий\"}", "{фантастика}", true).
+			AddRow(finished.Add(time.Hour), nil, "", "{}", "{}", false)
+
+		mock.ExpectQuery(`FROM readings r JOIN books b ON b.book_id = r.book_id WHERE r.household_id = \$1 AND r.user_id = \$2 AND r.finished_at >= \$3 AND r.finished_at < \$4`).
+			WithArgs(householdID, userID, from, from.AddDate(1, 0, 0)).
+			WillReturnRows(rows)
+
+		readings, err := repo.GetFinishedReadings(context.Background(), householdID, userID, from, from.AddDate(1, 0, 0))
+
+		assert.NoError(t, err)
+		assert.Len(t, readings, 2)
+		assert.Equal(t, goals.Entry{
+			FinishedAt:      finished,
+			Pages:           384,
+			Language:        "ru",
+			Authors:         []string{"Аркадий Стругацкий", "Борис Стругацкий"},
+			Tags:            []string{"фантастика"},
+			CompletesSeries: true,
+		}, readings[0].Entry())
+		assert.Equal(t, 0, readings[1].Entry().Pages)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
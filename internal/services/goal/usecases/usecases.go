@@ -0,0 +1,180 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/goal/dtos"
+	"home-library/internal/services/goal/entities"
+	"home-library/internal/services/goal/repository"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/goals"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Goals are personal, but they count the member's readings of the
+// household's books, so they need a household all the same.
+type UseCase interface {
+	CreateGoal(ctx context.Context, userID uuid.UUID, payload dtos.GoalRequest) (goalID uuid.UUID, err error)
+	// GetGoals returns the goals of the year along with their progress.
+	GetGoals(ctx context.Context, userID uuid.UUID, request dtos.ListGoalsRequest) ([]dtos.GoalResponse, error)
+	GetGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) (*dtos.GoalResponse, error)
+	UpdateGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID, payload dtos.GoalRequest) error
+	DeleteGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) error
+}
+
+// useCase evaluates goals in the library's time zone, so that the year and
+// the share of it elapsed match the calendar on the household's wall.
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	timeZone   string
+	now        func() time.Time
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository, timeZone string) UseCase {
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+
+	return &useCase{r: r, households: households, timeZone: timeZone, now: time.Now}
+}
+
+func (u *useCase) CreateGoal(ctx context.Context, userID uuid.UUID, payload dtos.GoalRequest) (goalID uuid.UUID, err error) {
+	if _, err := u.membership(ctx, userID); err != nil {
+		return uuid.Nil, err
+	}
+
+	goal := entities.NewGoal(userID)
+	applyGoalRequest(goal, payload)
+
+	return u.r.CreateGoal(ctx, goal)
+}
+
+func (u *useCase) GetGoals(ctx context.Context, userID uuid.UUID, request dtos.ListGoalsRequest) ([]dtos.GoalResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(u.timeZone)
+	if err != nil {
+		return nil, err
+	}
+	now := u.now().In(location)
+
+	year := request.Year
+	if year == 0 {
+		year = now.Year()
+	}
+
+	list, err := u.r.GetGoals(ctx, userID, year)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return []dtos.GoalResponse{}, nil
+	}
+
+	entries, err := u.entries(ctx, member, year, location)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.GoalResponse, len(list))
+	for i, goal := range list {
+		response[i] = dtos.NewGoalResponse(goal, goals.Evaluate(goal.Rule(), entries, now, location))
+	}
+
+	return response, nil
+}
+
+func (u *useCase) GetGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) (*dtos.GoalResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	goal, err := u.r.GetGoal(ctx, userID, goalID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrGoalNotFound)
+	}
+
+	location, err := time.LoadLocation(u.timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := u.entries(ctx, member, goal.Year, location)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dtos.NewGoalResponse(*goal, goals.Evaluate(goal.Rule(), entries, u.now().In(location), location))
+	return &response, nil
+}
+
+func (u *useCase) UpdateGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID, payload dtos.GoalRequest) error {
+	if _, err := u.membership(ctx, userID); err != nil {
+		return err
+	}
+
+	goal := &entities.Goal{GoalID: goalID, UserID: userID, UpdatedAt: time.Now()}
+	applyGoalRequest(goal, payload)
+
+	return mapNoRows(u.r.UpdateGoal(ctx, goal), errors.ErrGoalNotFound)
+}
+
+func (u *useCase) DeleteGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) error {
+	if _, err := u.membership(ctx, userID); err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.DeleteGoal(ctx, userID, goalID), errors.ErrGoalNotFound)
+}
+
+// entries returns the member's readings finished in the year of the
+// location.
+func (u *useCase) entries(ctx context.Context, member *householdEntities.Member, year int, location *time.Location) ([]goals.Entry, error) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, location)
+	readings, err := u.r.GetFinishedReadings(ctx, member.HouseholdID, member.UserID, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]goals.Entry, len(readings))
+	for i, reading := range readings {
+		entries[i] = reading.Entry()
+	}
+	return entries, nil
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+func applyGoalRequest(goal *entities.Goal, payload dtos.GoalRequest) {
+	goal.Name = strings.TrimSpace(payload.Name)
+	goal.Year = payload.Year
+	goal.Metric = payload.Metric
+	goal.Target = payload.Target
+	goal.Language = payload.Language
+	goal.Author = strings.TrimSpace(payload.Author)
+	goal.Tag = strings.TrimSpace(payload.Tag)
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
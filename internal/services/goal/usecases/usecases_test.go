@@ -0,0 +1,281 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/goal/dtos"
+	"home-library/internal/services/goal/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/goals"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateGoal(ctx context.Context, goal *entities.Goal) (uuid.UUID, error) {
+	args := m.Called(ctx, goal)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) (*entities.Goal, error) {
+	args := m.Called(ctx, userID, goalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Goal), args.Error(1)
+}
+
+func (m *MockRepository) GetGoals(ctx context.Context, userID uuid.UUID, year int) ([]entities.Goal, error) {
+	args := m.Called(ctx, userID, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Goal), args.Error(1)
+}
+
+func (m *MockRepository) UpdateGoal(ctx context.Context, goal *entities.Goal) error {
+	return m.Called(ctx, goal).Error(0)
+}
+
+func (m *MockRepository) DeleteGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) error {
+	return m.Called(ctx, userID, goalID).Error(0)
+}
+
+func (m *MockRepository) GetFinishedReadings(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, from time.Time, to time.Time) ([]entities.FinishedReading, error) {
+	args := m.Called(ctx, householdID, userID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.FinishedReading), args.Error(1)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+func newUseCase(r *MockRepository, households *MockHouseholdRepository, now time.Time) *useCase {
+	u := NewUseCase(r, households, "Europe/Moscow").(*useCase)
+	u.now = func() time.Time { return now }
+	return u
+}
+
+func member(households *MockHouseholdRepository, userID uuid.UUID, householdID uuid.UUID) {
+	households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleViewer}, nil)
+}
+
+func TestGetGoals(t *testing.T) {
+	// It is already 2025 in Moscow.
+	now := time.Date(2024, time.December, 31, 22, 30, 0, 0, time.UTC)
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, moscow)
+
+	t.Run("current year in the library's time zone", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, now)
+		userID, householdID := uuid.New(), uuid.New()
+		member(households, userID, householdID)
+
+		repo.On("GetGoals", mock.Anything, userID, 2025).Return([]entities.Goal{
+			{GoalID: uuid.New(), Year: 2025, Metric: goals.MetricBooks, Target: 12},
+		}, nil)
+		repo.On("GetFinishedReadings", mock.Anything, householdID, userID, from, from.AddDate(1, 0, 0)).
+			Return([]entities.FinishedReading{{FinishedAt: time.Date(2024, time.December, 31, 21, 30, 0, 0, time.UTC)}}, nil)
+
+		result, err := u.GetGoals(context.Background(), userID, dtos.ListGoalsRequest{})
+
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, 1, result[0].Progress.Done)
+		assert.Equal(t, goals.StatusOnTrack, result[0].Progress.Status)
+	})
+
+	t.Run("past year is settled", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, now)
+		userID, householdID := uuid.New(), uuid.New()
+		member(households, userID, householdID)
+		pages := 300
+		start := time.Date(2024, time.January, 1, 0, 0, 0, 0, moscow)
+
+		repo.On("GetGoals", mock.Anything, userID, 2024).Return([]entities.Goal{
+			{GoalID: uuid.New(), Year: 2024, Metric: goals.MetricPages, Target: 500},
+			{GoalID: uuid.New(), Year: 2024, Metric: goals.MetricBooks, Target: 1, Language: "en"},
+		}, nil)
+		repo.On("GetFinishedReadings", mock.Anything, householdID, userID, start, start.AddDate(1, 0, 0)).
+			Return([]entities.FinishedReading{
+				{FinishedAt: time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC), Pages: &pages, Language: "en"},
+				{FinishedAt: time.Date(2024, time.July, 1, 12, 0, 0, 0, time.UTC), Language: "ru"},
+			}, nil)
+
+		result, err := u.GetGoals(context.Background(), userID, dtos.ListGoalsRequest{Year: 2024})
+
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, 300, result[0].Progress.Done)
+		assert.Equal(t, goals.StatusMissed, result[0].Progress.Status)
+		assert.Equal(t, 1, result[1].Progress.Done)
+		assert.Equal(t, goals.StatusAchieved, result[1].Progress.Status)
+	})
+
+	t.Run("no goals skip the reading log", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, now)
+		userID := uuid.New()
+		member(households, userID, uuid.New())
+
+		repo.On("GetGoals", mock.Anything, userID, 2025).Return([]entities.Goal{}, nil)
+
+		result, err := u.GetGoals(context.Background(), userID, dtos.ListGoalsRequest{})
+
+		require.NoError(t, err)
+		assert.Empty(t, result)
+		repo.AssertNotCalled(t, "GetFinishedReadings", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCreateGoal(t *testing.T) {
+	t.Run("challenge filters are trimmed", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, time.Now())
+		userID, goalID := uuid.New(), uuid.New()
+		member(households, userID, uuid.New())
+
+		repo.On("CreateGoal", mock.Anything, mock.MatchedBy(func(g *entities.Goal) bool {
+			return g.UserID == userID && g.Name == "Лем целиком" && g.Author == "Станислав Лем" &&
+				g.Metric == goals.MetricBooks && g.Target == 5 && g.Year == 2025
+		})).Return(goalID, nil)
+
+		id, err := u.CreateGoal(context.Background(), userID, dtos.GoalRequest{
+			Name:   " Лем целиком",
+			Year:   2025,
+			Metric: goals.MetricBooks,
+			Target: 5,
+			Author: "Станислав Лем ",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, goalID, id)
+	})
+
+	t.Run("user outside a household", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, time.Now())
+		userID := uuid.New()
+		households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.CreateGoal(context.Background(), userID, dtos.GoalRequest{Year: 2025, Metric: goals.MetricBooks, Target: 5})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdNotFound)
+	})
+}
+
+func TestGetGoal(t *testing.T) {
+	t.Run("goal of another user", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, time.Now())
+		userID, goalID := uuid.New(), uuid.New()
+		member(households, userID, uuid.New())
+
+		repo.On("GetGoal", mock.Anything, userID, goalID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.GetGoal(context.Background(), userID, goalID)
+
+		assert.ErrorIs(t, err, errors.ErrGoalNotFound)
+	})
+}
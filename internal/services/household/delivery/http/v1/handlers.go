@@ -0,0 +1,272 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/household/dtos"
+	"home-library/internal/services/household/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) CreateHousehold(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.CreateHouseholdRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	householdID, err := h.u.CreateHousehold(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to create household")
+	}
+
+	return c.JSON(http.StatusCreated, dtos.CreateHouseholdResponse{HouseholdID: householdID})
+}
+
+func (h *handler) GetHousehold(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	household, err := h.u.GetHousehold(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get household")
+	}
+
+	return c.JSON(http.StatusOK, household)
+}
+
+func (h *handler) RenameHousehold(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.RenameHouseholdRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.RenameHousehold(c.Request().Context(), userID, payload); err != nil {
+		return h.handleError(c, err, "failed to rename household")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) LeaveHousehold(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	if err := h.u.LeaveHousehold(c.Request().Context(), userID); err != nil {
+		return h.handleError(c, err, "failed to leave household")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) RemoveMember(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.RemoveMember(c.Request().Context(), userID, memberID); err != nil {
+		return h.handleError(c, err, "failed to remove household member")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) ChangeMemberRole(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.ChangeMemberRoleRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.ChangeMemberRole(c.Request().Context(), userID, memberID, payload); err != nil {
+		return h.handleError(c, err, "failed to change household member role")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) TransferOwnership(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.TransferOwnershipRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	if err := h.u.TransferOwnership(c.Request().Context(), userID, payload); err != nil {
+		return h.handleError(c, err, "failed to transfer household ownership")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) CreateInvitation(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.CreateInvitationRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	invitation, err := h.u.CreateInvitation(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to create household invitation")
+	}
+
+	return c.JSON(http.StatusCreated, invitation)
+}
+
+func (h *handler) GetInvitations(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	invitations, err := h.u.GetInvitations(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get household invitations")
+	}
+
+	return c.JSON(http.StatusOK, invitations)
+}
+
+func (h *handler) RevokeInvitation(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	invitationID, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.RevokeInvitation(c.Request().Context(), userID, invitationID); err != nil {
+		return h.handleError(c, err, "failed to revoke household invitation")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) JoinHousehold(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.JoinHouseholdRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	householdID, err := h.u.JoinHousehold(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to join household")
+	}
+
+	return c.JSON(http.StatusOK, dtos.JoinHouseholdResponse{HouseholdID: householdID})
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrHouseholdMemberNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Участник не найден", nil))
+	case errors.Is(err, customErrors.ErrInvitationNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Приглашение не найдено", nil))
+	case errors.Is(err, customErrors.ErrInvitationExpired):
+		return c.JSON(http.StatusGone, dtos.NewErrorResponse(http.StatusGone, "Приглашение истекло или уже использовано", nil))
+	case errors.Is(err, customErrors.ErrAlreadyInHousehold):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Вы уже состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrOwnerMustTransfer):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Сначала передайте права владельца другому участнику", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
@@ -0,0 +1,316 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"home-library/internal/services/household/dtos"
+	"home-library/internal/services/household/entities"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) CreateHousehold(ctx context.Context, userID uuid.UUID, payload dtos.CreateHouseholdRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) GetHousehold(ctx context.Context, userID uuid.UUID) (*dtos.HouseholdResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.HouseholdResponse), args.Error(1)
+}
+
+func (m *MockUseCase) RenameHousehold(ctx context.Context, userID uuid.UUID, payload dtos.RenameHouseholdRequest) error {
+	args := m.Called(ctx, userID, payload)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) LeaveHousehold(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) RemoveMember(ctx context.Context, userID uuid.UUID, memberID uuid.UUID) error {
+	args := m.Called(ctx, userID, memberID)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) ChangeMemberRole(ctx context.Context, userID uuid.UUID, memberID uuid.UUID, payload dtos.ChangeMemberRoleRequest) error {
+	args := m.Called(ctx, userID, memberID, payload)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) TransferOwnership(ctx context.Context, userID uuid.UUID, payload dtos.TransferOwnershipRequest) error {
+	args := m.Called(ctx, userID, payload)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) CreateInvitation(ctx context.Context, userID uuid.UUID, payload dtos.CreateInvitationRequest) (*dtos.InvitationResponse, error) {
+	args := m.Called(ctx, userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.InvitationResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetInvitations(ctx context.Context, userID uuid.UUID) ([]dtos.InvitationResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.InvitationResponse), args.Error(1)
+}
+
+func (m *MockUseCase) RevokeInvitation(ctx context.Context, userID uuid.UUID, invitationID uuid.UUID) error {
+	args := m.Called(ctx, userID, invitationID)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) JoinHousehold(ctx context.Context, userID uuid.UUID, payload dtos.JoinHouseholdRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func newContext(e *echo.Echo, method string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestCreateHousehold(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully create household", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, householdID := uuid.New(), uuid.New()
+		payload := dtos.CreateHouseholdRequest{Name: "Koveshnikovs"}
+
+		jsonPayload, _ := json.Marshal(payload)
+		c, rec := newContext(e, http.MethodPost, string(jsonPayload), userID)
+
+		mockUseCase.On("CreateHousehold", context.Background(), userID, payload).Return(householdID, nil)
+
+		err := handler.CreateHousehold(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response dtos.CreateHouseholdResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, householdID, response.HouseholdID)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("already in household", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+		payload := dtos.CreateHouseholdRequest{Name: "Koveshnikovs"}
+
+		jsonPayload, _ := json.Marshal(payload)
+		c, rec := newContext(e, http.MethodPost, string(jsonPayload), userID)
+
+		mockUseCase.On("CreateHousehold", context.Background(), userID, payload).Return(uuid.Nil, customErrors.ErrAlreadyInHousehold)
+
+		err := handler.CreateHousehold(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		c, rec := newContext(e, http.MethodPost, `{"name":""}`, uuid.New())
+
+		err := handler.CreateHousehold(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "CreateHousehold")
+	})
+}
+
+func TestGetHousehold(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully get household", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+		household := &dtos.HouseholdResponse{
+			HouseholdID: uuid.New(),
+			Name:        "Koveshnikovs",
+			Role:        entities.RoleOwner,
+			Members:     []dtos.MemberResponse{{UserID: userID, Role: entities.RoleOwner}},
+		}
+
+		c, rec := newContext(e, http.MethodGet, "", userID)
+
+		mockUseCase.On("GetHousehold", context.Background(), userID).Return(household, nil)
+
+		err := handler.GetHousehold(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response dtos.HouseholdResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, household.HouseholdID, response.HouseholdID)
+		assert.Len(t, response.Members, 1)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("not in household", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		c, rec := newContext(e, http.MethodGet, "", userID)
+
+		mockUseCase.On("GetHousehold", context.Background(), userID).Return(nil, customErrors.ErrHouseholdNotFound)
+
+		err := handler.GetHousehold(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+}
+
+func TestCreateInvitation(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully create invitation", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+		payload := dtos.CreateInvitationRequest{Role: entities.RoleViewer, ExpiresInHours: 24}
+		invitation := &dtos.InvitationResponse{
+			InvitationID: uuid.New(),
+			Code:         "ABCD2345",
+			Role:         entities.RoleViewer,
+			ExpiresAt:    time.Now().Add(24 * time.Hour),
+		}
+
+		jsonPayload, _ := json.Marshal(payload)
+		c, rec := newContext(e, http.MethodPost, string(jsonPayload), userID)
+
+		mockUseCase.On("CreateInvitation", context.Background(), userID, payload).Return(invitation, nil)
+
+		err := handler.CreateInvitation(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), "ABCD2345")
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("owner role cannot be granted by invitation", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		c, rec := newContext(e, http.MethodPost, `{"role":"owner"}`, uuid.New())
+
+		err := handler.CreateInvitation(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "CreateInvitation")
+	})
+}
+
+func TestJoinHousehold(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "successfully join", err: nil, expectedCode: http.StatusOK},
+		{name: "unknown code", err: customErrors.ErrInvitationNotFound, expectedCode: http.StatusNotFound},
+		{name: "expired invitation", err: customErrors.ErrInvitationExpired, expectedCode: http.StatusGone},
+		{name: "already in household", err: customErrors.ErrAlreadyInHousehold, expectedCode: http.StatusConflict},
+		{name: "internal server error", err: errors.New("database error"), expectedCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			handler := NewHandler(mockUseCase)
+			userID := uuid.New()
+			payload := dtos.JoinHouseholdRequest{Code: "ABCD2345"}
+
+			jsonPayload, _ := json.Marshal(payload)
+			c, rec := newContext(e, http.MethodPost, string(jsonPayload), userID)
+
+			mockUseCase.On("JoinHousehold", context.Background(), userID, payload).Return(uuid.New(), tt.err)
+
+			err := handler.JoinHousehold(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveMember(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "successfully remove", err: nil, expectedCode: http.StatusNoContent},
+		{name: "not an owner", err: customErrors.ErrHouseholdForbidden, expectedCode: http.StatusForbidden},
+		{name: "member not found", err: customErrors.ErrHouseholdMemberNotFound, expectedCode: http.StatusNotFound},
+		{name: "removing self", err: customErrors.ErrOwnerMustTransfer, expectedCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			handler := NewHandler(mockUseCase)
+			userID, memberID := uuid.New(), uuid.New()
+
+			c, rec := newContext(e, http.MethodDelete, "", userID)
+			c.SetParamNames("user_id")
+			c.SetParamValues(memberID.String())
+
+			mockUseCase.On("RemoveMember", context.Background(), userID, memberID).Return(tt.err)
+
+			err := handler.RemoveMember(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
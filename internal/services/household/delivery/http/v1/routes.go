@@ -0,0 +1,17 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) HouseholdRoutes(domain *echo.Group) {
+	domain.POST("/household", h.CreateHousehold)
+	domain.GET("/household", h.GetHousehold)
+	domain.PUT("/household", h.RenameHousehold)
+	domain.POST("/household/leave", h.LeaveHousehold)
+	domain.POST("/household/join", h.JoinHousehold)
+	domain.POST("/household/transfer", h.TransferOwnership)
+	domain.DELETE("/household/members/:user_id", h.RemoveMember)
+	domain.PUT("/household/members/:user_id/role", h.ChangeMemberRole)
+	domain.POST("/household/invitations", h.CreateInvitation)
+	domain.GET("/household/invitations", h.GetInvitations)
+	domain.DELETE("/household/invitations/:invitation_id", h.RevokeInvitation)
+}
@@ -0,0 +1,74 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/household/entities"
+	"time"
+)
+
+type CreateHouseholdRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=255"`
+}
+
+type CreateHouseholdResponse struct {
+	HouseholdID uuid.UUID `json:"household_id"`
+}
+
+func (r *CreateHouseholdRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type RenameHouseholdRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=255"`
+}
+
+func (r *RenameHouseholdRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type HouseholdResponse struct {
+	HouseholdID uuid.UUID        `json:"household_id"`
+	Name        string           `json:"name"`
+	Role        entities.Role    `json:"role"`
+	Members     []MemberResponse `json:"members"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+type MemberResponse struct {
+	UserID    uuid.UUID     `json:"user_id"`
+	FirstName string        `json:"first_name"`
+	LastName  string        `json:"last_name"`
+	Role      entities.Role `json:"role"`
+	JoinedAt  time.Time     `json:"joined_at"`
+}
+
+func NewMemberResponse(member entities.Member) MemberResponse {
+	return MemberResponse{
+		UserID:    member.UserID,
+		FirstName: member.FirstName,
+		LastName:  member.LastName,
+		Role:      member.Role,
+		JoinedAt:  member.JoinedAt,
+	}
+}
+
+type ChangeMemberRoleRequest struct {
+	Role entities.Role `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+func (r *ChangeMemberRoleRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type TransferOwnershipRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+func (r *TransferOwnershipRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
@@ -0,0 +1,48 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/household/entities"
+	"time"
+)
+
+type CreateInvitationRequest struct {
+	Role entities.Role `json:"role" validate:"required,oneof=editor viewer"`
+	// ExpiresInHours defaults to three days when omitted.
+	ExpiresInHours int `json:"expires_in_hours" validate:"omitempty,min=1,max=720"`
+}
+
+func (r *CreateInvitationRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type InvitationResponse struct {
+	InvitationID uuid.UUID     `json:"invitation_id"`
+	Code         string        `json:"code"`
+	Role         entities.Role `json:"role"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+}
+
+func NewInvitationResponse(invitation entities.Invitation) InvitationResponse {
+	return InvitationResponse{
+		InvitationID: invitation.InvitationID,
+		Code:         invitation.Code,
+		Role:         invitation.Role,
+		ExpiresAt:    invitation.ExpiresAt,
+	}
+}
+
+type JoinHouseholdRequest struct {
+	Code string `json:"code" validate:"required,alphanum,max=16"`
+}
+
+type JoinHouseholdResponse struct {
+	HouseholdID uuid.UUID `json:"household_id"`
+}
+
+func (r *JoinHouseholdRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
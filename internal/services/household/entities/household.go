@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+// CanManageMembers reports whether the role may invite, remove and re-role
+// other members.
+func (r Role) CanManageMembers() bool {
+	return r == RoleOwner
+}
+
+// CanManageIntegrations reports whether the role may connect the household
+// to outside services, such as webhooks.
+func (r Role) CanManageIntegrations() bool {
+	return r == RoleOwner
+}
+
+// CanManageFriends reports whether the role may befriend other households,
+// which lets them browse and borrow from the library.
+func (r Role) CanManageFriends() bool {
+	return r == RoleOwner
+}
+
+// CanEditLibrary reports whether the role may add, change and remove the
+// household's books, copies and locations.
+func (r Role) CanEditLibrary() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+type Household struct {
+	HouseholdID uuid.UUID  `db:"household_id"`
+	Name        string     `db:"name"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	DeletedAt   *time.Time `db:"deleted_at,omitempty"`
+}
+
+func NewHousehold(name string) *Household {
+	now := time.Now()
+	return &Household{
+		HouseholdID: uuid.New(),
+		Name:        name,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+type Member struct {
+	HouseholdID uuid.UUID `db:"household_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	Role        Role      `db:"role"`
+	JoinedAt    time.Time `db:"joined_at"`
+	FirstName   string    `db:"first_name"`
+	LastName    string    `db:"last_name"`
+	Email       string    `db:"email"`
+}
+
+func NewMember(householdID uuid.UUID, userID uuid.UUID, role Role) *Member {
+	return &Member{
+		HouseholdID: householdID,
+		UserID:      userID,
+		Role:        role,
+		JoinedAt:    time.Now(),
+	}
+}
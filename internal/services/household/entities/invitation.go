@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"crypto/rand"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// codeAlphabet leaves out characters that are easy to confuse when a code is
+// read aloud or typed from a phone screen.
+const (
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeLength   = 8
+)
+
+type Invitation struct {
+	InvitationID uuid.UUID  `db:"invitation_id"`
+	HouseholdID  uuid.UUID  `db:"household_id"`
+	Code         string     `db:"code"`
+	Role         Role       `db:"role"`
+	CreatedBy    uuid.UUID  `db:"created_by"`
+	ExpiresAt    time.Time  `db:"expires_at"`
+	AcceptedBy   *uuid.UUID `db:"accepted_by"`
+	AcceptedAt   *time.Time `db:"accepted_at"`
+	RevokedAt    *time.Time `db:"revoked_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+func NewInvitation(householdID uuid.UUID, createdBy uuid.UUID, role Role, ttl time.Duration) (*Invitation, error) {
+	code, err := generateCode()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Invitation{
+		InvitationID: uuid.New(),
+		HouseholdID:  householdID,
+		Code:         code,
+		Role:         role,
+		CreatedBy:    createdBy,
+		ExpiresAt:    now.Add(ttl),
+		CreatedAt:    now,
+	}, nil
+}
+
+// IsActive reports whether the invitation can still be accepted.
+func (i *Invitation) IsActive(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+func generateCode() (string, error) {
+	code := make([]byte, codeLength)
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
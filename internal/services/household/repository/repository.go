@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/household/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/storage"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository methods other than GetMembership and GetInvitationByCode take the
+// caller's household ID, so a member can never read or change another
+// household's data even if handed a foreign member or invitation ID.
+type Repository interface {
+	CreateHousehold(ctx context.Context, household *entities.Household, owner *entities.Member) (uuid.UUID, error)
+	GetHousehold(ctx context.Context, householdID uuid.UUID) (*entities.Household, error)
+	RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error
+	DeleteHousehold(ctx context.Context, householdID uuid.UUID) error
+
+	GetMembership(ctx context.Context, userID uuid.UUID) (*entities.Member, error)
+	GetMembers(ctx context.Context, householdID uuid.UUID) ([]entities.Member, error)
+	GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*entities.Member, error)
+	RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error
+	UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role entities.Role) error
+	TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error
+
+	CreateInvitation(ctx context.Context, invitation *entities.Invitation) (uuid.UUID, error)
+	GetInvitationByCode(ctx context.Context, code string) (*entities.Invitation, error)
+	GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]entities.Invitation, error)
+	RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error
+	AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *entities.Member) error
+}
+
+// constraints translates violations that concurrent requests can cause: a
+// user joining two households at once, or one that was just deleted.
+var constraints = storage.Constraints{
+	"household_members_user_id_key":       errors.ErrAlreadyInHousehold,
+	"household_members_household_id_fkey": errors.ErrHouseholdNotFound,
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateHousehold(ctx context.Context, household *entities.Household, owner *entities.Member) (uuid.UUID, error) {
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO households (household_id, name, created_at, updated_at)
+			VALUES (:household_id, :name, :created_at, :updated_at)
+		`
+		if _, err := tx.NamedExecContext(ctx, query, household); err != nil {
+			return err
+		}
+
+		return insertMember(ctx, tx, owner)
+	})
+	if err != nil {
+		return uuid.Nil, constraints.Map(err)
+	}
+
+	return household.HouseholdID, nil
+}
+
+func (r *repository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*entities.Household, error) {
+	var household entities.Household
+	query := `
+		SELECT * FROM households
+		WHERE household_id = $1 AND deleted_at IS NULL
+	`
+
+	err := r.db.GetContext(ctx, &household, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &household, nil
+}
+
+func (r *repository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	query := `
+		UPDATE households
+		SET name = $2, updated_at = NOW()
+		WHERE household_id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, name)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+// DeleteHousehold is used when the last member leaves. Pending invitations are
+// revoked so the household cannot be re-entered afterwards.
+func (r *repository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		queries := []string{
+			`UPDATE household_invitations SET revoked_at = NOW() WHERE household_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`,
+			`DELETE FROM household_members WHERE household_id = $1`,
+			`UPDATE households SET deleted_at = NOW() WHERE household_id = $1 AND deleted_at IS NULL`,
+		}
+		for _, query := range queries {
+			if _, err := tx.ExecContext(ctx, query, householdID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *repository) GetMembership(ctx context.Context, userID uuid.UUID) (*entities.Member, error) {
+	var member entities.Member
+	query := `
+		SELECT m.household_id, m.user_id, m.role, m.joined_at, u.first_name, u.last_name, u.email
+		FROM household_members m
+		JOIN users u ON u.user_id = m.user_id
+		WHERE m.user_id = $1
+	`
+
+	err := r.db.GetContext(ctx, &member, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (r *repository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]entities.Member, error) {
+	members := make([]entities.Member, 0)
+	query := `
+		SELECT m.household_id, m.user_id, m.role, m.joined_at, u.first_name, u.last_name, u.email
+		FROM household_members m
+		JOIN users u ON u.user_id = m.user_id
+		WHERE m.household_id = $1
+		ORDER BY m.joined_at
+	`
+
+	err := r.db.SelectContext(ctx, &members, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *repository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*entities.Member, error) {
+	var member entities.Member
+	query := `
+		SELECT m.household_id, m.user_id, m.role, m.joined_at, u.first_name, u.last_name, u.email
+		FROM household_members m
+		JOIN users u ON u.user_id = m.user_id
+		WHERE m.household_id = $1 AND m.user_id = $2
+	`
+
+	err := r.db.GetContext(ctx, &member, query, householdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+func (r *repository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	query := `
+		DELETE FROM household_members
+		WHERE household_id = $1 AND user_id = $2 AND role <> 'owner'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, userID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role entities.Role) error {
+	query := `
+		UPDATE household_members
+		SET role = $3
+		WHERE household_id = $1 AND user_id = $2 AND role <> 'owner'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+// TransferOwnership demotes the current owner to editor before promoting the
+// new one, keeping the one-owner-per-household index satisfied.
+func (r *repository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		demote := `
+			UPDATE household_members
+			SET role = 'editor'
+			WHERE household_id = $1 AND user_id = $2 AND role = 'owner'
+		`
+		result, err := tx.ExecContext(ctx, demote, householdID, fromUserID)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+
+		promote := `
+			UPDATE household_members
+			SET role = 'owner'
+			WHERE household_id = $1 AND user_id = $2
+		`
+		result, err = tx.ExecContext(ctx, promote, householdID, toUserID)
+		if err != nil {
+			return err
+		}
+		return requireAffected(result)
+	})
+}
+
+func (r *repository) CreateInvitation(ctx context.Context, invitation *entities.Invitation) (uuid.UUID, error) {
+	query := `
+		INSERT INTO household_invitations (
+			invitation_id, household_id, code, role,
+			created_by, expires_at, created_at
+		) VALUES (
+			:invitation_id, :household_id, :code, :role,
+			:created_by, :expires_at, :created_at
+		)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, invitation)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return invitation.InvitationID, nil
+}
+
+func (r *repository) GetInvitationByCode(ctx context.Context, code string) (*entities.Invitation, error) {
+	var invitation entities.Invitation
+	query := `
+		SELECT * FROM household_invitations
+		WHERE code = $1
+	`
+
+	err := r.db.GetContext(ctx, &invitation, query, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (r *repository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]entities.Invitation, error) {
+	invitations := make([]entities.Invitation, 0)
+	query := `
+		SELECT * FROM household_invitations
+		WHERE household_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY created_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &invitations, query, householdID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func (r *repository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE household_invitations
+		SET revoked_at = $3
+		WHERE household_id = $1 AND invitation_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, invitationID, now)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+// AcceptInvitation marks the invitation used and adds the member atomically.
+// It returns sql.ErrNoRows if the invitation was used, revoked or expired in
+// the meantime, and ErrAlreadyInHousehold if the user joined another one.
+func (r *repository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *entities.Member) error {
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE household_invitations
+			SET accepted_by = $2, accepted_at = $3
+			WHERE invitation_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $3
+		`
+		result, err := tx.ExecContext(ctx, query, invitationID, member.UserID, member.JoinedAt)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+
+		return insertMember(ctx, tx, member)
+	})
+	return constraints.Map(err)
+}
+
+func (r *repository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.db.InTx(ctx, nil, fn)
+}
+
+func insertMember(ctx context.Context, tx *sqlx.Tx, member *entities.Member) error {
+	query := `
+		INSERT INTO household_members (household_id, user_id, role, joined_at)
+		VALUES (:household_id, :user_id, :role, :joined_at)
+	`
+
+	_, err := tx.NamedExecContext(ctx, query, member)
+	return err
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
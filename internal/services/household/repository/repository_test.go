@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"home-library/internal/services/household/entities"
+	customErrors "home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestCreateHousehold(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("household and owner are inserted in one transaction", func(t *testing.T) {
+		household := entities.NewHousehold("Koveshnikovs")
+		owner := entities.NewMember(household.HouseholdID, uuid.New(), entities.RoleOwner)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO households").
+			WithArgs(household.HouseholdID, household.Name, household.CreatedAt, household.UpdatedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO household_members").
+			WithArgs(household.HouseholdID, owner.UserID, entities.RoleOwner, owner.JoinedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		id, err := repo.CreateHousehold(context.Background(), household, owner)
+
+		assert.NoError(t, err)
+		assert.Equal(t, household.HouseholdID, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("owner already in a household", func(t *testing.T) {
+		household := entities.NewHousehold("Koveshnikovs")
+		owner := entities.NewMember(household.HouseholdID, uuid.New(), entities.RoleOwner)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO households").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO household_members").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "household_members_user_id_key"})
+		mock.ExpectRollback()
+
+		id, err := repo.CreateHousehold(context.Background(), household, owner)
+
+		assert.ErrorIs(t, err, customErrors.ErrAlreadyInHousehold)
+		assert.Equal(t, uuid.Nil, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("member insert failure rolls back", func(t *testing.T) {
+		household := entities.NewHousehold("Koveshnikovs")
+		owner := entities.NewMember(household.HouseholdID, uuid.New(), entities.RoleOwner)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO households").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO household_members").
+			WillReturnError(errors.New("duplicate key value"))
+		mock.ExpectRollback()
+
+		id, err := repo.CreateHousehold(context.Background(), household, owner)
+
+		assert.Error(t, err)
+		assert.Equal(t, uuid.Nil, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("member of another household is not removed", func(t *testing.T) {
+		householdID, userID := uuid.New(), uuid.New()
+
+		mock.ExpectExec("DELETE FROM household_members").
+			WithArgs(householdID, userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.RemoveMember(context.Background(), householdID, userID)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTransferOwnership(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("owner is demoted before new owner is promoted", func(t *testing.T) {
+		householdID, fromID, toID := uuid.New(), uuid.New(), uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SET role = 'editor'").
+			WithArgs(householdID, fromID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("SET role = 'owner'").
+			WithArgs(householdID, toID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.TransferOwnership(context.Background(), householdID, fromID, toID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("new owner is not a member", func(t *testing.T) {
+		householdID, fromID, toID := uuid.New(), uuid.New(), uuid.New()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("SET role = 'editor'").
+			WithArgs(householdID, fromID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("SET role = 'owner'").
+			WithArgs(householdID, toID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.TransferOwnership(context.Background(), householdID, fromID, toID)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("invitation accepted and member added", func(t *testing.T) {
+		invitationID := uuid.New()
+		member := entities.NewMember(uuid.New(), uuid.New(), entities.RoleViewer)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE household_invitations").
+			WithArgs(invitationID, member.UserID, member.JoinedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO household_members").
+			WithArgs(member.HouseholdID, member.UserID, entities.RoleViewer, member.JoinedAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := repo.AcceptInvitation(context.Background(), invitationID, member)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("invitation used concurrently", func(t *testing.T) {
+		invitationID := uuid.New()
+		member := entities.NewMember(uuid.New(), uuid.New(), entities.RoleViewer)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE household_invitations").
+			WithArgs(invitationID, member.UserID, member.JoinedAt).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.AcceptInvitation(context.Background(), invitationID, member)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user joined another household concurrently", func(t *testing.T) {
+		invitationID := uuid.New()
+		member := entities.NewMember(uuid.New(), uuid.New(), entities.RoleViewer)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE household_invitations").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO household_members").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "household_members_user_id_key"})
+		mock.ExpectRollback()
+
+		err := repo.AcceptInvitation(context.Background(), invitationID, member)
+
+		assert.ErrorIs(t, err, customErrors.ErrAlreadyInHousehold)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("household deleted concurrently", func(t *testing.T) {
+		invitationID := uuid.New()
+		member := entities.NewMember(uuid.New(), uuid.New(), entities.RoleViewer)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE household_invitations").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO household_members").
+			WillReturnError(&pq.Error{Code: "23503", Constraint: "household_members_household_id_fkey"})
+		mock.ExpectRollback()
+
+		err := repo.AcceptInvitation(context.Background(), invitationID, member)
+
+		assert.ErrorIs(t, err, customErrors.ErrHouseholdNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetActiveInvitations(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("returns invitations of the household", func(t *testing.T) {
+		householdID, now := uuid.New(), time.Now()
+		rows := sqlmock.NewRows([]string{"invitation_id", "household_id", "code", "role", "created_by", "expires_at", "accepted_by", "accepted_at", "revoked_at", "created_at"}).
+			AddRow(uuid.New(), householdID, "ABCD2345", "editor", uuid.New(), now.Add(time.Hour), nil, nil, nil, now)
+
+		mock.ExpectQuery("SELECT \\* FROM household_invitations").
+			WithArgs(householdID, now).
+			WillReturnRows(rows)
+
+		invitations, err := repo.GetActiveInvitations(context.Background(), householdID, now)
+
+		assert.NoError(t, err)
+		assert.Len(t, invitations, 1)
+		assert.True(t, invitations[0].IsActive(now))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,291 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/household/dtos"
+	"home-library/internal/services/household/entities"
+	"home-library/internal/services/household/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/transaction"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultInvitationTTL = 72 * time.Hour
+
+type UseCase interface {
+	CreateHousehold(ctx context.Context, userID uuid.UUID, payload dtos.CreateHouseholdRequest) (householdID uuid.UUID, err error)
+	GetHousehold(ctx context.Context, userID uuid.UUID) (*dtos.HouseholdResponse, error)
+	RenameHousehold(ctx context.Context, userID uuid.UUID, payload dtos.RenameHouseholdRequest) error
+	LeaveHousehold(ctx context.Context, userID uuid.UUID) error
+
+	RemoveMember(ctx context.Context, userID uuid.UUID, memberID uuid.UUID) error
+	ChangeMemberRole(ctx context.Context, userID uuid.UUID, memberID uuid.UUID, payload dtos.ChangeMemberRoleRequest) error
+	TransferOwnership(ctx context.Context, userID uuid.UUID, payload dtos.TransferOwnershipRequest) error
+
+	CreateInvitation(ctx context.Context, userID uuid.UUID, payload dtos.CreateInvitationRequest) (*dtos.InvitationResponse, error)
+	GetInvitations(ctx context.Context, userID uuid.UUID) ([]dtos.InvitationResponse, error)
+	RevokeInvitation(ctx context.Context, userID uuid.UUID, invitationID uuid.UUID) error
+	JoinHousehold(ctx context.Context, userID uuid.UUID, payload dtos.JoinHouseholdRequest) (householdID uuid.UUID, err error)
+}
+
+type useCase struct {
+	r  repository.Repository
+	tx transaction.Transactor
+}
+
+func NewUseCase(r repository.Repository, tx transaction.Transactor) UseCase {
+	return &useCase{r: r, tx: tx}
+}
+
+func (u *useCase) CreateHousehold(ctx context.Context, userID uuid.UUID, payload dtos.CreateHouseholdRequest) (householdID uuid.UUID, err error) {
+	household := entities.NewHousehold(strings.TrimSpace(payload.Name))
+	owner := entities.NewMember(household.HouseholdID, userID, entities.RoleOwner)
+
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		if err := u.requireNoMembership(ctx, userID); err != nil {
+			return err
+		}
+
+		householdID, err = u.r.CreateHousehold(ctx, household, owner)
+		return err
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return householdID, nil
+}
+
+func (u *useCase) GetHousehold(ctx context.Context, userID uuid.UUID) (*dtos.HouseholdResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	household, err := u.r.GetHousehold(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+
+	members, err := u.r.GetMembers(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.HouseholdResponse{
+		HouseholdID: household.HouseholdID,
+		Name:        household.Name,
+		Role:        member.Role,
+		Members:     make([]dtos.MemberResponse, len(members)),
+		CreatedAt:   household.CreatedAt,
+	}
+	for i, m := range members {
+		response.Members[i] = dtos.NewMemberResponse(m)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) RenameHousehold(ctx context.Context, userID uuid.UUID, payload dtos.RenameHouseholdRequest) error {
+	member, err := u.manager(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.RenameHousehold(ctx, member.HouseholdID, strings.TrimSpace(payload.Name)), errors.ErrHouseholdNotFound)
+}
+
+// LeaveHousehold lets a member leave. The owner may only leave once they are
+// the last member, in which case the household is deleted.
+func (u *useCase) LeaveHousehold(ctx context.Context, userID uuid.UUID) error {
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		member, err := u.membership(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if member.Role != entities.RoleOwner {
+			return mapNoRows(u.r.RemoveMember(ctx, member.HouseholdID, userID), errors.ErrHouseholdNotFound)
+		}
+
+		// Counting the members and deleting share a transaction, so nobody
+		// joins a household that is about to disappear.
+		members, err := u.r.GetMembers(ctx, member.HouseholdID)
+		if err != nil {
+			return err
+		}
+		if len(members) > 1 {
+			return errors.ErrOwnerMustTransfer
+		}
+
+		return u.r.DeleteHousehold(ctx, member.HouseholdID)
+	})
+}
+
+func (u *useCase) RemoveMember(ctx context.Context, userID uuid.UUID, memberID uuid.UUID) error {
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		member, err := u.manager(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if memberID == userID {
+			return errors.ErrOwnerMustTransfer
+		}
+
+		return mapNoRows(u.r.RemoveMember(ctx, member.HouseholdID, memberID), errors.ErrHouseholdMemberNotFound)
+	})
+}
+
+func (u *useCase) ChangeMemberRole(ctx context.Context, userID uuid.UUID, memberID uuid.UUID, payload dtos.ChangeMemberRoleRequest) error {
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		member, err := u.manager(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if memberID == userID {
+			return errors.ErrOwnerMustTransfer
+		}
+
+		return mapNoRows(u.r.UpdateMemberRole(ctx, member.HouseholdID, memberID, payload.Role), errors.ErrHouseholdMemberNotFound)
+	})
+}
+
+func (u *useCase) TransferOwnership(ctx context.Context, userID uuid.UUID, payload dtos.TransferOwnershipRequest) error {
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		member, err := u.manager(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if payload.UserID == userID {
+			return nil
+		}
+
+		if _, err := u.r.GetMember(ctx, member.HouseholdID, payload.UserID); err != nil {
+			return mapNoRows(err, errors.ErrHouseholdMemberNotFound)
+		}
+
+		return mapNoRows(u.r.TransferOwnership(ctx, member.HouseholdID, userID, payload.UserID), errors.ErrHouseholdMemberNotFound)
+	})
+}
+
+func (u *useCase) CreateInvitation(ctx context.Context, userID uuid.UUID, payload dtos.CreateInvitationRequest) (*dtos.InvitationResponse, error) {
+	member, err := u.manager(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := defaultInvitationTTL
+	if payload.ExpiresInHours > 0 {
+		ttl = time.Duration(payload.ExpiresInHours) * time.Hour
+	}
+
+	invitation, err := entities.NewInvitation(member.HouseholdID, userID, payload.Role, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.r.CreateInvitation(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	response := dtos.NewInvitationResponse(*invitation)
+	return &response, nil
+}
+
+func (u *useCase) GetInvitations(ctx context.Context, userID uuid.UUID) ([]dtos.InvitationResponse, error) {
+	member, err := u.manager(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := u.r.GetActiveInvitations(ctx, member.HouseholdID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		response[i] = dtos.NewInvitationResponse(invitation)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) RevokeInvitation(ctx context.Context, userID uuid.UUID, invitationID uuid.UUID) error {
+	member, err := u.manager(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.RevokeInvitation(ctx, member.HouseholdID, invitationID, time.Now()), errors.ErrInvitationNotFound)
+}
+
+func (u *useCase) JoinHousehold(ctx context.Context, userID uuid.UUID, payload dtos.JoinHouseholdRequest) (householdID uuid.UUID, err error) {
+	invitation, err := u.r.GetInvitationByCode(ctx, strings.ToUpper(payload.Code))
+	if err != nil {
+		return uuid.Nil, mapNoRows(err, errors.ErrInvitationNotFound)
+	}
+	if !invitation.IsActive(time.Now()) {
+		return uuid.Nil, errors.ErrInvitationExpired
+	}
+
+	member := entities.NewMember(invitation.HouseholdID, userID, invitation.Role)
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		if err := u.requireNoMembership(ctx, userID); err != nil {
+			return err
+		}
+
+		return mapNoRows(u.r.AcceptInvitation(ctx, invitation.InvitationID, member), errors.ErrInvitationExpired)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return invitation.HouseholdID, nil
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*entities.Member, error) {
+	member, err := u.r.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+// requireNoMembership fails with ErrAlreadyInHousehold if the user already
+// belongs to a household.
+func (u *useCase) requireNoMembership(ctx context.Context, userID uuid.UUID) error {
+	_, err := u.membership(ctx, userID)
+	if err == nil {
+		return errors.ErrAlreadyInHousehold
+	}
+	if stdErrors.Is(err, errors.ErrHouseholdNotFound) {
+		return nil
+	}
+	return err
+}
+
+// manager returns the caller's membership if their role may manage the
+// household.
+func (u *useCase) manager(ctx context.Context, userID uuid.UUID) (*entities.Member, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanManageMembers() {
+		return nil, errors.ErrHouseholdForbidden
+	}
+	return member, nil
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
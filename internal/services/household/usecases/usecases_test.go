@@ -0,0 +1,334 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/household/dtos"
+	"home-library/internal/services/household/entities"
+	customErrors "home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateHousehold(ctx context.Context, household *entities.Household, owner *entities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*entities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Household), args.Error(1)
+}
+
+func (m *MockRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	args := m.Called(ctx, householdID, name)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	args := m.Called(ctx, householdID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*entities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Member), args.Error(1)
+}
+
+func (m *MockRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]entities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Member), args.Error(1)
+}
+
+func (m *MockRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*entities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Member), args.Error(1)
+}
+
+func (m *MockRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, householdID, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role entities.Role) error {
+	args := m.Called(ctx, householdID, userID, role)
+	return args.Error(0)
+}
+
+func (m *MockRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	args := m.Called(ctx, householdID, fromUserID, toUserID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateInvitation(ctx context.Context, invitation *entities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetInvitationByCode(ctx context.Context, code string) (*entities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Invitation), args.Error(1)
+}
+
+func (m *MockRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]entities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Invitation), args.Error(1)
+}
+
+func (m *MockRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	args := m.Called(ctx, householdID, invitationID, now)
+	return args.Error(0)
+}
+
+func (m *MockRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *entities.Member) error {
+	args := m.Called(ctx, invitationID, member)
+	return args.Error(0)
+}
+
+// passthroughTx runs the unit of work directly.
+type passthroughTx struct{}
+
+func (passthroughTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestCreateHousehold(t *testing.T) {
+	t.Run("creator becomes owner", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID, householdID := uuid.New(), uuid.New()
+
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+		mockRepo.On("CreateHousehold", mock.Anything,
+			mock.MatchedBy(func(h *entities.Household) bool { return h.Name == "Koveshnikovs" }),
+			mock.MatchedBy(func(m *entities.Member) bool { return m.UserID == userID && m.Role == entities.RoleOwner }),
+		).Return(householdID, nil)
+
+		id, err := useCase.CreateHousehold(context.Background(), userID, dtos.CreateHouseholdRequest{Name: " Koveshnikovs "})
+
+		assert.NoError(t, err)
+		assert.Equal(t, householdID, id)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("already in household", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID := uuid.New()
+
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(&entities.Member{UserID: userID}, nil)
+
+		id, err := useCase.CreateHousehold(context.Background(), userID, dtos.CreateHouseholdRequest{Name: "Koveshnikovs"})
+
+		assert.Equal(t, customErrors.ErrAlreadyInHousehold, err)
+		assert.Equal(t, uuid.Nil, id)
+		mockRepo.AssertNotCalled(t, "CreateHousehold")
+	})
+}
+
+func TestLeaveHousehold(t *testing.T) {
+	t.Run("member leaves", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID, householdID := uuid.New(), uuid.New()
+
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(&entities.Member{HouseholdID: householdID, UserID: userID, Role: entities.RoleEditor}, nil)
+		mockRepo.On("RemoveMember", mock.Anything, householdID, userID).Return(nil)
+
+		err := useCase.LeaveHousehold(context.Background(), userID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("owner with other members must transfer first", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID, householdID := uuid.New(), uuid.New()
+
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(&entities.Member{HouseholdID: householdID, UserID: userID, Role: entities.RoleOwner}, nil)
+		mockRepo.On("GetMembers", mock.Anything, householdID).Return([]entities.Member{{UserID: userID}, {UserID: uuid.New()}}, nil)
+
+		err := useCase.LeaveHousehold(context.Background(), userID)
+
+		assert.Equal(t, customErrors.ErrOwnerMustTransfer, err)
+		mockRepo.AssertNotCalled(t, "DeleteHousehold")
+	})
+
+	t.Run("last owner deletes household", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID, householdID := uuid.New(), uuid.New()
+
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(&entities.Member{HouseholdID: householdID, UserID: userID, Role: entities.RoleOwner}, nil)
+		mockRepo.On("GetMembers", mock.Anything, householdID).Return([]entities.Member{{UserID: userID}}, nil)
+		mockRepo.On("DeleteHousehold", mock.Anything, householdID).Return(nil)
+
+		err := useCase.LeaveHousehold(context.Background(), userID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	t.Run("editor cannot remove members", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID := uuid.New()
+
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(&entities.Member{UserID: userID, Role: entities.RoleEditor}, nil)
+
+		err := useCase.RemoveMember(context.Background(), userID, uuid.New())
+
+		assert.Equal(t, customErrors.ErrHouseholdForbidden, err)
+		mockRepo.AssertNotCalled(t, "RemoveMember")
+	})
+
+	t.Run("member of another household", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID, householdID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(&entities.Member{HouseholdID: householdID, UserID: userID, Role: entities.RoleOwner}, nil)
+		mockRepo.On("RemoveMember", mock.Anything, householdID, memberID).Return(sql.ErrNoRows)
+
+		err := useCase.RemoveMember(context.Background(), userID, memberID)
+
+		assert.Equal(t, customErrors.ErrHouseholdMemberNotFound, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestTransferOwnership(t *testing.T) {
+	t.Run("successfully transfer ownership", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID, householdID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(&entities.Member{HouseholdID: householdID, UserID: userID, Role: entities.RoleOwner}, nil)
+		mockRepo.On("GetMember", mock.Anything, householdID, memberID).Return(&entities.Member{HouseholdID: householdID, UserID: memberID, Role: entities.RoleViewer}, nil)
+		mockRepo.On("TransferOwnership", mock.Anything, householdID, userID, memberID).Return(nil)
+
+		err := useCase.TransferOwnership(context.Background(), userID, dtos.TransferOwnershipRequest{UserID: memberID})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCreateInvitation(t *testing.T) {
+	t.Run("default expiry", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID, householdID := uuid.New(), uuid.New()
+
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(&entities.Member{HouseholdID: householdID, UserID: userID, Role: entities.RoleOwner}, nil)
+		mockRepo.On("CreateInvitation", mock.Anything, mock.MatchedBy(func(i *entities.Invitation) bool {
+			return i.HouseholdID == householdID &&
+				i.Role == entities.RoleViewer &&
+				len(i.Code) == 8 &&
+				i.ExpiresAt.Sub(i.CreatedAt) == defaultInvitationTTL
+		})).Return(uuid.New(), nil)
+
+		invitation, err := useCase.CreateInvitation(context.Background(), userID, dtos.CreateInvitationRequest{Role: entities.RoleViewer})
+
+		assert.NoError(t, err)
+		assert.Len(t, invitation.Code, 8)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestJoinHousehold(t *testing.T) {
+	activeInvitation := func() *entities.Invitation {
+		invitation, _ := entities.NewInvitation(uuid.New(), uuid.New(), entities.RoleEditor, time.Hour)
+		return invitation
+	}
+
+	t.Run("successfully join", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID := uuid.New()
+		invitation := activeInvitation()
+
+		mockRepo.On("GetInvitationByCode", mock.Anything, invitation.Code).Return(invitation, nil)
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+		mockRepo.On("AcceptInvitation", mock.Anything, invitation.InvitationID, mock.MatchedBy(func(m *entities.Member) bool {
+			return m.UserID == userID && m.HouseholdID == invitation.HouseholdID && m.Role == entities.RoleEditor
+		})).Return(nil)
+
+		householdID, err := useCase.JoinHousehold(context.Background(), userID, dtos.JoinHouseholdRequest{Code: invitation.Code})
+
+		assert.NoError(t, err)
+		assert.Equal(t, invitation.HouseholdID, householdID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("expired invitation", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		invitation := activeInvitation()
+		invitation.ExpiresAt = time.Now().Add(-time.Minute)
+
+		mockRepo.On("GetInvitationByCode", mock.Anything, invitation.Code).Return(invitation, nil)
+
+		_, err := useCase.JoinHousehold(context.Background(), uuid.New(), dtos.JoinHouseholdRequest{Code: invitation.Code})
+
+		assert.Equal(t, customErrors.ErrInvitationExpired, err)
+		mockRepo.AssertNotCalled(t, "AcceptInvitation")
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+
+		mockRepo.On("GetInvitationByCode", mock.Anything, "NOPE2345").Return(nil, sql.ErrNoRows)
+
+		_, err := useCase.JoinHousehold(context.Background(), uuid.New(), dtos.JoinHouseholdRequest{Code: "nope2345"})
+
+		assert.Equal(t, customErrors.ErrInvitationNotFound, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invitation used concurrently", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, passthroughTx{})
+		userID := uuid.New()
+		invitation := activeInvitation()
+
+		mockRepo.On("GetInvitationByCode", mock.Anything, invitation.Code).Return(invitation, nil)
+		mockRepo.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+		mockRepo.On("AcceptInvitation", mock.Anything, invitation.InvitationID, mock.Anything).Return(sql.ErrNoRows)
+
+		_, err := useCase.JoinHousehold(context.Background(), userID, dtos.JoinHouseholdRequest{Code: invitation.Code})
+
+		assert.Equal(t, customErrors.ErrInvitationExpired, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
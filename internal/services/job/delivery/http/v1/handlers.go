@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/job/dtos"
+	"home-library/internal/services/job/usecases"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) ListJobs(c echo.Context) error {
+	var payload dtos.ListJobsRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind query parameters")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	list, err := h.u.ListJobs(c.Request().Context(), payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to list jobs")
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+func (h *handler) GetJob(c echo.Context) error {
+	jobID, err := uuid.Parse(c.Param("job_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	job, err := h.u.GetJob(c.Request().Context(), jobID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get job")
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+func (h *handler) RetryJob(c echo.Context) error {
+	jobID, err := uuid.Parse(c.Param("job_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	job, err := h.u.RetryJob(c.Request().Context(), jobID)
+	if err != nil {
+		return h.handleError(c, err, "failed to retry job")
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrJobNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Задача не найдена", nil))
+	case errors.Is(err, customErrors.ErrJobNotRetryable):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Повторить можно только задачу, исчерпавшую попытки", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
@@ -0,0 +1,161 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"home-library/internal/services/job/dtos"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jobs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) ListJobs(ctx context.Context, request dtos.ListJobsRequest) ([]dtos.JobResponse, error) {
+	args := m.Called(ctx, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.JobResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetJob(ctx context.Context, jobID uuid.UUID) (*dtos.JobResponse, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.JobResponse), args.Error(1)
+}
+
+func (m *MockUseCase) RetryJob(ctx context.Context, jobID uuid.UUID) (*dtos.JobResponse, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.JobResponse), args.Error(1)
+}
+
+func newContext(e *echo.Echo, method string, target string, jobID string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if jobID != "" {
+		c.SetParamNames("job_id")
+		c.SetParamValues(jobID)
+	}
+	return c, rec
+}
+
+func TestListJobs(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully list jobs", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+
+		mockUseCase.On("ListJobs", mock.Anything, dtos.ListJobsRequest{Status: "dead", Limit: 10}).
+			Return([]dtos.JobResponse{{JobID: uuid.New(), Kind: "email", Status: jobs.StatusDead}}, nil)
+
+		c, rec := newContext(e, http.MethodGet, "/jobs?status=dead&limit=10", "")
+		err := h.ListJobs(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"kind":"email"`)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, http.MethodGet, "/jobs?status=lost", "")
+		err := h.ListJobs(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Ошибка валидации")
+	})
+}
+
+func TestGetJob(t *testing.T) {
+	e := echo.New()
+
+	t.Run("invalid id", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, http.MethodGet, "/jobs/x", "x")
+		err := h.GetJob(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("job not found", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		jobID := uuid.New()
+
+		mockUseCase.On("GetJob", mock.Anything, jobID).Return(nil, customErrors.ErrJobNotFound)
+
+		c, rec := newContext(e, http.MethodGet, "/jobs/"+jobID.String(), jobID.String())
+		err := h.GetJob(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestRetryJob(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully retry", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		jobID := uuid.New()
+
+		mockUseCase.On("RetryJob", mock.Anything, jobID).Return(&dtos.JobResponse{JobID: jobID, Status: jobs.StatusPending}, nil)
+
+		c, rec := newContext(e, http.MethodPost, "/jobs/"+jobID.String()+"/retry", jobID.String())
+		err := h.RetryJob(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+	})
+
+	t.Run("job is not dead", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		jobID := uuid.New()
+
+		mockUseCase.On("RetryJob", mock.Anything, jobID).Return(nil, customErrors.ErrJobNotRetryable)
+
+		c, rec := newContext(e, http.MethodPost, "/jobs/"+jobID.String()+"/retry", jobID.String())
+		err := h.RetryJob(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		jobID := uuid.New()
+
+		mockUseCase.On("RetryJob", mock.Anything, jobID).Return(nil, errors.New("db down"))
+
+		c, rec := newContext(e, http.MethodPost, "/jobs/"+jobID.String()+"/retry", jobID.String())
+		err := h.RetryJob(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
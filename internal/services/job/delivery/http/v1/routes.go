@@ -0,0 +1,10 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+// JobRoutes expects a group that only lets administrators through.
+func (h *handler) JobRoutes(admin *echo.Group) {
+	admin.GET("/jobs", h.ListJobs)
+	admin.GET("/jobs/:job_id", h.GetJob)
+	admin.POST("/jobs/:job_id/retry", h.RetryJob)
+}
@@ -0,0 +1,52 @@
+package dtos
+
+import (
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/pkg/jobs"
+	"time"
+)
+
+type ListJobsRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending running done dead"`
+	Limit  int    `query:"limit" validate:"gte=0,lte=100"`
+	Offset int    `query:"offset" validate:"gte=0"`
+}
+
+func (r *ListJobsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type JobResponse struct {
+	JobID       uuid.UUID       `json:"job_id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      jobs.Status     `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	Timeout     int             `json:"timeout_seconds"`
+	RunAt       time.Time       `json:"run_at"`
+	LockedUntil *time.Time      `json:"locked_until"`
+	LastError   string          `json:"last_error"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func NewJobResponse(job jobs.Job) JobResponse {
+	return JobResponse{
+		JobID:       job.JobID,
+		Kind:        job.Kind,
+		Payload:     job.Payload,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		Timeout:     job.TimeoutSeconds,
+		RunAt:       job.RunAt,
+		LockedUntil: job.LockedUntil,
+		LastError:   job.LastError,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+}
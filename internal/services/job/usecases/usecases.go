@@ -0,0 +1,89 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/job/dtos"
+	"home-library/pkg/errors"
+	"home-library/pkg/jobs"
+
+	"github.com/google/uuid"
+)
+
+// defaultLimit is the page size when the request does not set one.
+const defaultLimit = 50
+
+// Queue is the part of jobs.Queue the administration needs.
+type Queue interface {
+	List(ctx context.Context, status jobs.Status, limit int, offset int) ([]jobs.Job, error)
+	Get(ctx context.Context, jobID uuid.UUID) (*jobs.Job, error)
+	Retry(ctx context.Context, jobID uuid.UUID) error
+}
+
+type UseCase interface {
+	ListJobs(ctx context.Context, request dtos.ListJobsRequest) ([]dtos.JobResponse, error)
+	GetJob(ctx context.Context, jobID uuid.UUID) (*dtos.JobResponse, error)
+	RetryJob(ctx context.Context, jobID uuid.UUID) (*dtos.JobResponse, error)
+}
+
+type useCase struct {
+	q Queue
+}
+
+func NewUseCase(q Queue) UseCase {
+	return &useCase{q: q}
+}
+
+func (u *useCase) ListJobs(ctx context.Context, request dtos.ListJobsRequest) ([]dtos.JobResponse, error) {
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	list, err := u.q.List(ctx, jobs.Status(request.Status), limit, request.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.JobResponse, len(list))
+	for i, job := range list {
+		result[i] = dtos.NewJobResponse(job)
+	}
+
+	return result, nil
+}
+
+func (u *useCase) GetJob(ctx context.Context, jobID uuid.UUID) (*dtos.JobResponse, error) {
+	job, err := u.q.Get(ctx, jobID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrJobNotFound)
+	}
+
+	response := dtos.NewJobResponse(*job)
+	return &response, nil
+}
+
+// RetryJob puts a dead job back in the queue and returns it as it is now.
+func (u *useCase) RetryJob(ctx context.Context, jobID uuid.UUID) (*dtos.JobResponse, error) {
+	if err := u.q.Retry(ctx, jobID); err != nil {
+		if !stdErrors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		// Tell a missing job from one that is not dead.
+		if _, err := u.GetJob(ctx, jobID); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrJobNotRetryable
+	}
+
+	return u.GetJob(ctx, jobID)
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
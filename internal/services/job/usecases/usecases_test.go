@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/job/dtos"
+	"home-library/pkg/errors"
+	"home-library/pkg/jobs"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockQueue struct {
+	mock.Mock
+}
+
+func (m *MockQueue) List(ctx context.Context, status jobs.Status, limit int, offset int) ([]jobs.Job, error) {
+	args := m.Called(ctx, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]jobs.Job), args.Error(1)
+}
+
+func (m *MockQueue) Get(ctx context.Context, jobID uuid.UUID) (*jobs.Job, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jobs.Job), args.Error(1)
+}
+
+func (m *MockQueue) Retry(ctx context.Context, jobID uuid.UUID) error {
+	return m.Called(ctx, jobID).Error(0)
+}
+
+func TestListJobs(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		mockQueue := new(MockQueue)
+		u := NewUseCase(mockQueue)
+
+		mockQueue.On("List", mock.Anything, jobs.StatusDead, defaultLimit, 0).
+			Return([]jobs.Job{{JobID: uuid.New(), Kind: "email", Status: jobs.StatusDead}}, nil)
+
+		list, err := u.ListJobs(context.Background(), dtos.ListJobsRequest{Status: "dead"})
+
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, "email", list[0].Kind)
+		mockQueue.AssertExpectations(t)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockQueue := new(MockQueue)
+		u := NewUseCase(mockQueue)
+
+		mockQueue.On("List", mock.Anything, jobs.Status(""), 10, 20).Return(nil, stdErrors.New("db down"))
+
+		_, err := u.ListJobs(context.Background(), dtos.ListJobsRequest{Limit: 10, Offset: 20})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestGetJob(t *testing.T) {
+	mockQueue := new(MockQueue)
+	u := NewUseCase(mockQueue)
+	jobID := uuid.New()
+
+	mockQueue.On("Get", mock.Anything, jobID).Return(nil, sql.ErrNoRows)
+
+	_, err := u.GetJob(context.Background(), jobID)
+
+	assert.ErrorIs(t, err, errors.ErrJobNotFound)
+}
+
+func TestRetryJob(t *testing.T) {
+	t.Run("successfully retry", func(t *testing.T) {
+		mockQueue := new(MockQueue)
+		u := NewUseCase(mockQueue)
+		jobID := uuid.New()
+
+		mockQueue.On("Retry", mock.Anything, jobID).Return(nil)
+		mockQueue.On("Get", mock.Anything, jobID).Return(&jobs.Job{JobID: jobID, Status: jobs.StatusPending}, nil)
+
+		job, err := u.RetryJob(context.Background(), jobID)
+
+		require.NoError(t, err)
+		assert.Equal(t, jobs.StatusPending, job.Status)
+	})
+
+	t.Run("job not found", func(t *testing.T) {
+		mockQueue := new(MockQueue)
+		u := NewUseCase(mockQueue)
+		jobID := uuid.New()
+
+		mockQueue.On("Retry", mock.Anything, jobID).Return(sql.ErrNoRows)
+		mockQueue.On("Get", mock.Anything, jobID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.RetryJob(context.Background(), jobID)
+
+		assert.ErrorIs(t, err, errors.ErrJobNotFound)
+	})
+
+	t.Run("job is not dead", func(t *testing.T) {
+		mockQueue := new(MockQueue)
+		u := NewUseCase(mockQueue)
+		jobID := uuid.New()
+
+		mockQueue.On("Retry", mock.Anything, jobID).Return(sql.ErrNoRows)
+		mockQueue.On("Get", mock.Anything, jobID).Return(&jobs.Job{JobID: jobID, Status: jobs.StatusRunning}, nil)
+
+		_, err := u.RetryJob(context.Background(), jobID)
+
+		assert.ErrorIs(t, err, errors.ErrJobNotRetryable)
+	})
+}
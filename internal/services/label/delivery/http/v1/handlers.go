@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/label/dtos"
+	"home-library/internal/services/label/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) GetSheets(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.u.GetSheets())
+}
+
+func (h *handler) RenderLabels(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var request dtos.LabelsRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := request.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	// The document is built in memory, so a failure can still be reported
+	// with a proper status.
+	var document bytes.Buffer
+	if err := h.u.RenderLabels(c.Request().Context(), userID, &request, &document); err != nil {
+		return h.handleError(c, err, "failed to render labels")
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="labels.pdf"`)
+	return c.Blob(http.StatusOK, "application/pdf", document.Bytes())
+}
+
+func (h *handler) RenderShelfLabels(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var request dtos.ShelfLabelsRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := request.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	var document bytes.Buffer
+	if err := h.u.RenderShelfLabels(c.Request().Context(), userID, &request, &document); err != nil {
+		return h.handleError(c, err, "failed to render shelf labels")
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="shelf-labels.pdf"`)
+	return c.Blob(http.StatusOK, "application/pdf", document.Bytes())
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrCopyNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Экземпляр не найден", nil))
+	case errors.Is(err, customErrors.ErrLocationNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Место хранения не найдено", nil))
+	case errors.Is(err, customErrors.ErrLabelSheetUnknown):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неизвестный формат листа с наклейками", nil))
+	case errors.Is(err, customErrors.ErrLabelSheetInvalid):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Наклейки не помещаются на лист", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
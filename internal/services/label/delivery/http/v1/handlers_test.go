@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"home-library/internal/services/label/dtos"
+	customErrors "home-library/pkg/errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) GetSheets() []dtos.SheetResponse {
+	return m.Called().Get(0).([]dtos.SheetResponse)
+}
+
+func (m *MockUseCase) RenderLabels(ctx context.Context, userID uuid.UUID, request *dtos.LabelsRequest, w io.Writer) error {
+	args := m.Called(ctx, userID, request)
+	if args.Error(0) == nil {
+		_, _ = io.WriteString(w, "%PDF-1.3")
+	}
+	return args.Error(0)
+}
+
+func (m *MockUseCase) RenderShelfLabels(ctx context.Context, userID uuid.UUID, request *dtos.ShelfLabelsRequest, w io.Writer) error {
+	args := m.Called(ctx, userID, request)
+	if args.Error(0) == nil {
+		_, _ = io.WriteString(w, "%PDF-1.3")
+	}
+	return args.Error(0)
+}
+
+func newContext(e *echo.Echo, req *http.Request, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func labelsRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/labels", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	return req
+}
+
+func TestRenderLabels(t *testing.T) {
+	e := echo.New()
+	userID, copyID := uuid.New(), uuid.New()
+
+	t.Run("pdf of the labels", func(t *testing.T) {
+		u := new(MockUseCase)
+		h := NewHandler(u)
+		u.On("RenderLabels", mock.Anything, userID, &dtos.LabelsRequest{CopyIDs: []uuid.UUID{copyID}, Layout: dtos.Layout{Sheet: "avery-l7160"}}).Return(nil)
+
+		c, rec := newContext(e, labelsRequest(`{"copy_ids":["`+copyID.String()+`"],"sheet":"avery-l7160"}`), userID)
+		require.NoError(t, h.RenderLabels(c))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/pdf", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "%PDF-1.3", rec.Body.String())
+	})
+
+	t.Run("copies and location together", func(t *testing.T) {
+		u := new(MockUseCase)
+		h := NewHandler(u)
+
+		body := `{"copy_ids":["` + copyID.String() + `"],"location_id":"` + uuid.NewString() + `","sheet":"avery-l7160"}`
+		c, rec := newContext(e, labelsRequest(body), userID)
+		require.NoError(t, h.RenderLabels(c))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		u.AssertNotCalled(t, "RenderLabels", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("no sheet", func(t *testing.T) {
+		u := new(MockUseCase)
+		h := NewHandler(u)
+
+		c, rec := newContext(e, labelsRequest(`{"copy_ids":["`+copyID.String()+`"]}`), userID)
+		require.NoError(t, h.RenderLabels(c))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("copy not found", func(t *testing.T) {
+		u := new(MockUseCase)
+		h := NewHandler(u)
+		u.On("RenderLabels", mock.Anything, userID, mock.Anything).Return(customErrors.ErrCopyNotFound)
+
+		c, rec := newContext(e, labelsRequest(`{"copy_ids":["`+copyID.String()+`"],"sheet":"avery-l7160"}`), userID)
+		require.NoError(t, h.RenderLabels(c))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, echo.MIMEApplicationJSON, strings.Split(rec.Header().Get(echo.HeaderContentType), ";")[0])
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, labelsRequest(`{}`), uuid.Nil)
+		require.NoError(t, h.RenderLabels(c))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestGetSheets(t *testing.T) {
+	e := echo.New()
+	u := new(MockUseCase)
+	h := NewHandler(u)
+	u.On("GetSheets").Return([]dtos.SheetResponse{{Code: "avery-l7160", Columns: 3, Rows: 7}})
+
+	c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/labels/sheets", nil), uuid.New())
+	require.NoError(t, h.GetSheets(c))
+
+	var sheets []dtos.SheetResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sheets))
+	assert.Equal(t, "avery-l7160", sheets[0].Code)
+}
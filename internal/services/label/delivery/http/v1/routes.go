@@ -0,0 +1,9 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) LabelRoutes(domain *echo.Group) {
+	domain.GET("/labels/sheets", h.GetSheets)
+	domain.POST("/labels", h.RenderLabels)
+	domain.POST("/labels/shelves", h.RenderShelfLabels)
+}
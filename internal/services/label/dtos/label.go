@@ -0,0 +1,108 @@
+package dtos
+
+import (
+	"home-library/pkg/labels"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// Layout is the sheet to print labels on: a predefined one by its code or a
+// custom layout.
+type Layout struct {
+	Sheet  string        `json:"sheet" validate:"required_without=Custom,excluded_with=Custom"`
+	Custom *SheetRequest `json:"custom"`
+	// Skip leaves the first labels of the sheet blank, for partly used
+	// sheets.
+	Skip    int  `json:"skip" validate:"gte=0,lte=1000"`
+	Outline bool `json:"outline"`
+}
+
+// LabelsRequest selects the copies to label, either by ID or as everything
+// kept in a location. At most 1000 copies fit in one request, a few dozen
+// pages.
+type LabelsRequest struct {
+	CopyIDs    []uuid.UUID `json:"copy_ids" validate:"required_without=LocationID,excluded_with=LocationID,max=1000"`
+	LocationID *uuid.UUID  `json:"location_id"`
+	Layout
+}
+
+func (r *LabelsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// ShelfLabelsRequest selects the locations to print shelf labels for.
+type ShelfLabelsRequest struct {
+	LocationIDs []uuid.UUID `json:"location_ids" validate:"required,min=1,max=100"`
+	Layout
+}
+
+func (r *ShelfLabelsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// SheetRequest is a custom sheet layout in millimetres.
+type SheetRequest struct {
+	PageWidth   float64 `json:"page_width" validate:"gt=0,lte=1000"`
+	PageHeight  float64 `json:"page_height" validate:"gt=0,lte=1000"`
+	Columns     int     `json:"columns" validate:"gt=0,lte=20"`
+	Rows        int     `json:"rows" validate:"gt=0,lte=50"`
+	LabelWidth  float64 `json:"label_width" validate:"gt=0"`
+	LabelHeight float64 `json:"label_height" validate:"gt=0"`
+	MarginTop   float64 `json:"margin_top" validate:"gte=0"`
+	MarginLeft  float64 `json:"margin_left" validate:"gte=0"`
+	GapX        float64 `json:"gap_x" validate:"gte=0"`
+	GapY        float64 `json:"gap_y" validate:"gte=0"`
+}
+
+func (r *SheetRequest) Sheet() labels.Sheet {
+	return labels.Sheet{
+		Name:        "custom",
+		PageWidth:   r.PageWidth,
+		PageHeight:  r.PageHeight,
+		Columns:     r.Columns,
+		Rows:        r.Rows,
+		LabelWidth:  r.LabelWidth,
+		LabelHeight: r.LabelHeight,
+		MarginTop:   r.MarginTop,
+		MarginLeft:  r.MarginLeft,
+		GapX:        r.GapX,
+		GapY:        r.GapY,
+	}
+}
+
+type SheetResponse struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	PageWidth   float64 `json:"page_width"`
+	PageHeight  float64 `json:"page_height"`
+	Columns     int     `json:"columns"`
+	Rows        int     `json:"rows"`
+	LabelWidth  float64 `json:"label_width"`
+	LabelHeight float64 `json:"label_height"`
+}
+
+// NewSheetResponses lists the predefined sheets ordered by code.
+func NewSheetResponses(sheets map[string]labels.Sheet) []SheetResponse {
+	response := make([]SheetResponse, 0, len(sheets))
+	for code, sheet := range sheets {
+		response = append(response, SheetResponse{
+			Code:        code,
+			Name:        sheet.Name,
+			PageWidth:   sheet.PageWidth,
+			PageHeight:  sheet.PageHeight,
+			Columns:     sheet.Columns,
+			Rows:        sheet.Rows,
+			LabelWidth:  sheet.LabelWidth,
+			LabelHeight: sheet.LabelHeight,
+		})
+	}
+
+	sort.Slice(response, func(i, j int) bool {
+		return response[i].Code < response[j].Code
+	})
+	return response
+}
@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Copy is what a label shows of a copy of a book.
+type Copy struct {
+	CopyID  uuid.UUID      `db:"copy_id"`
+	ShortID string         `db:"short_id"`
+	Title   string         `db:"title"`
+	Authors pq.StringArray `db:"authors"`
+}
+
+// Location is what a shelf label shows of a location.
+type Location struct {
+	LocationID uuid.UUID `db:"location_id"`
+	ShortID    string    `db:"short_id"`
+	Name       string    `db:"name"`
+}
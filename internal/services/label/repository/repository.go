@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/label/entities"
+	"home-library/pkg/transaction"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	// GetCopies returns those of the copies that belong to the household,
+	// in shelf order.
+	GetCopies(ctx context.Context, householdID uuid.UUID, copyIDs []uuid.UUID) ([]entities.Copy, error)
+	// GetLocationCopies returns the copies kept in the location, in shelf
+	// order.
+	GetLocationCopies(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) ([]entities.Copy, error)
+	// GetLocations returns those of the locations that belong to the
+	// household, by name.
+	GetLocations(ctx context.Context, householdID uuid.UUID, locationIDs []uuid.UUID) ([]entities.Location, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+const selectCopies = `
+	SELECT c.copy_id, c.short_id, b.title, b.authors
+	FROM copies c
+	JOIN books b ON b.book_id = c.book_id
+	LEFT JOIN locations l ON l.location_id = c.location_id
+`
+
+func (r *repository) GetCopies(ctx context.Context, householdID uuid.UUID, copyIDs []uuid.UUID) ([]entities.Copy, error) {
+	copies := make([]entities.Copy, 0)
+	query := selectCopies + `
+		WHERE c.household_id = $1 AND c.copy_id = ANY($2::uuid[])
+		ORDER BY l.name NULLS LAST, b.title, c.short_id
+	`
+
+	err := r.db.SelectContext(ctx, &copies, query, householdID, idArray(copyIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	return copies, nil
+}
+
+func (r *repository) GetLocationCopies(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) ([]entities.Copy, error) {
+	copies := make([]entities.Copy, 0)
+	query := selectCopies + `
+		WHERE c.household_id = $1 AND c.location_id = $2
+		ORDER BY b.title, c.short_id
+	`
+
+	err := r.db.SelectContext(ctx, &copies, query, householdID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return copies, nil
+}
+
+func (r *repository) GetLocations(ctx context.Context, householdID uuid.UUID, locationIDs []uuid.UUID) ([]entities.Location, error) {
+	locations := make([]entities.Location, 0)
+	query := `
+		SELECT location_id, short_id, name FROM locations
+		WHERE household_id = $1 AND location_id = ANY($2::uuid[])
+		ORDER BY name
+	`
+
+	err := r.db.SelectContext(ctx, &locations, query, householdID, idArray(locationIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+func idArray(ids []uuid.UUID) pq.StringArray {
+	array := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		array[i] = id.String()
+	}
+	return array
+}
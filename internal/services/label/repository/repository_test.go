@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestGetCopies(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("copies of the household in shelf order", func(t *testing.T) {
+		householdID, copyID := uuid.New(), uuid.New()
+		rows := sqlmock.NewRows([]string{"copy_id", "short_id", "title", "authors"}).
+			AddRow(copyID, "K7M2Q9X0", "Солярис", "{\"Станислав Лем\"}")
+
+		mock.ExpectQuery(`FROM copies c JOIN books b .+ WHERE c.household_id = \$1 AND c.copy_id = ANY\(\$2::uuid\[\]\) ORDER BY l.name NULLS LAST`).
+			WithArgs(householdID, pq.StringArray{copyID.String()}).
+			WillReturnRows(rows)
+
+		copies, err := repo.GetCopies(context.Background(), householdID, []uuid.UUID{copyID})
+
+		assert.NoError(t, err)
+		assert.Len(t, copies, 1)
+		assert.Equal(t, "K7M2Q9X0", copies[0].ShortID)
+		assert.Equal(t, pq.StringArray{"Станислав Лем"}, copies[0].Authors)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	catalogRepository "home-library/internal/services/catalog/repository"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/internal/services/label/dtos"
+	"home-library/internal/services/label/entities"
+	"home-library/internal/services/label/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/labels"
+	"home-library/pkg/shortid"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	GetSheets() []dtos.SheetResponse
+	// RenderLabels writes a PDF of labels for the requested copies. Nothing
+	// is written to w if the request is refused.
+	RenderLabels(ctx context.Context, userID uuid.UUID, request *dtos.LabelsRequest, w io.Writer) error
+	// RenderShelfLabels writes a PDF of labels for the requested locations,
+	// which scanning apps read to know which shelf they are at.
+	RenderShelfLabels(ctx context.Context, userID uuid.UUID, request *dtos.ShelfLabelsRequest, w io.Writer) error
+}
+
+type useCase struct {
+	r          repository.Repository
+	catalog    catalogRepository.Repository
+	households householdRepository.Repository
+	publicURL  string
+}
+
+// NewUseCase takes the address the app is reached at, which the QR codes on
+// the labels link to. Without one the labels carry no QR code.
+func NewUseCase(r repository.Repository, catalog catalogRepository.Repository, households householdRepository.Repository, publicURL string) UseCase {
+	return &useCase{r: r, catalog: catalog, households: households, publicURL: strings.TrimRight(publicURL, "/")}
+}
+
+func (u *useCase) GetSheets() []dtos.SheetResponse {
+	return dtos.NewSheetResponses(labels.Sheets)
+}
+
+func (u *useCase) RenderLabels(ctx context.Context, userID uuid.UUID, request *dtos.LabelsRequest, w io.Writer) error {
+	sheet, err := sheetOf(request.Layout)
+	if err != nil {
+		return err
+	}
+
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	copies, err := u.copies(ctx, member.HouseholdID, request)
+	if err != nil {
+		return err
+	}
+
+	items := make([]labels.Label, len(copies))
+	for i, copy := range copies {
+		items[i] = u.label(copy)
+	}
+
+	return labels.Render(w, sheet, items, labels.Options{Skip: request.Skip, Outline: request.Outline})
+}
+
+func (u *useCase) RenderShelfLabels(ctx context.Context, userID uuid.UUID, request *dtos.ShelfLabelsRequest, w io.Writer) error {
+	sheet, err := sheetOf(request.Layout)
+	if err != nil {
+		return err
+	}
+
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	locations, err := u.r.GetLocations(ctx, member.HouseholdID, request.LocationIDs)
+	if err != nil {
+		return err
+	}
+	if len(locations) != len(unique(request.LocationIDs)) {
+		return errors.ErrLocationNotFound
+	}
+
+	items := make([]labels.Label, len(locations))
+	for i, location := range locations {
+		items[i] = labels.Label{Code: shortid.Format(location.ShortID), Title: location.Name}
+		if u.publicURL != "" {
+			items[i].URL = u.publicURL + "/shelves/" + location.ShortID
+		}
+	}
+
+	return labels.Render(w, sheet, items, labels.Options{Skip: request.Skip, Outline: request.Outline})
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+func (u *useCase) copies(ctx context.Context, householdID uuid.UUID, request *dtos.LabelsRequest) ([]entities.Copy, error) {
+	if request.LocationID != nil {
+		if _, err := u.catalog.GetLocation(ctx, householdID, *request.LocationID); err != nil {
+			return nil, mapNoRows(err, errors.ErrLocationNotFound)
+		}
+		return u.r.GetLocationCopies(ctx, householdID, *request.LocationID)
+	}
+
+	copies, err := u.r.GetCopies(ctx, householdID, request.CopyIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(copies) != len(unique(request.CopyIDs)) {
+		return nil, errors.ErrCopyNotFound
+	}
+	return copies, nil
+}
+
+func (u *useCase) label(copy entities.Copy) labels.Label {
+	label := labels.Label{
+		Code:     shortid.Format(copy.ShortID),
+		Title:    copy.Title,
+		Subtitle: strings.Join(copy.Authors, ", "),
+	}
+	if u.publicURL != "" {
+		label.URL = u.publicURL + "/copies/" + copy.ShortID
+	}
+	return label
+}
+
+func sheetOf(layout dtos.Layout) (labels.Sheet, error) {
+	if layout.Custom != nil {
+		sheet := layout.Custom.Sheet()
+		if err := sheet.Validate(); err != nil {
+			return labels.Sheet{}, errors.ErrLabelSheetInvalid
+		}
+		return sheet, nil
+	}
+
+	sheet, err := labels.Lookup(layout.Sheet)
+	if err != nil {
+		return labels.Sheet{}, errors.ErrLabelSheetUnknown
+	}
+	return sheet, nil
+}
+
+func unique(ids []uuid.UUID) map[uuid.UUID]struct{} {
+	set := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
@@ -0,0 +1,369 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	catalogEntities "home-library/internal/services/catalog/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/internal/services/label/dtos"
+	"home-library/internal/services/label/entities"
+	"home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetCopies(ctx context.Context, householdID uuid.UUID, copyIDs []uuid.UUID) ([]entities.Copy, error) {
+	args := m.Called(ctx, householdID, copyIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Copy), args.Error(1)
+}
+
+func (m *MockRepository) GetLocations(ctx context.Context, householdID uuid.UUID, locationIDs []uuid.UUID) ([]entities.Location, error) {
+	args := m.Called(ctx, householdID, locationIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Location), args.Error(1)
+}
+
+func (m *MockRepository) GetLocationCopies(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) ([]entities.Copy, error) {
+	args := m.Called(ctx, householdID, locationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Copy), args.Error(1)
+}
+
+type MockCatalogRepository struct {
+	mock.Mock
+}
+
+func (m *MockCatalogRepository) CreateLocation(ctx context.Context, location *catalogEntities.Location) (uuid.UUID, error) {
+	args := m.Called(ctx, location)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetLocations(ctx context.Context, householdID uuid.UUID) ([]catalogEntities.Location, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]catalogEntities.Location), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*catalogEntities.Location, error) {
+	args := m.Called(ctx, householdID, locationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*catalogEntities.Location), args.Error(1)
+}
+
+func (m *MockCatalogRepository) RenameLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, locationID, name).Error(0)
+}
+
+func (m *MockCatalogRepository) DeleteLocation(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) error {
+	return m.Called(ctx, householdID, locationID).Error(0)
+}
+
+func (m *MockCatalogRepository) CreateBook(ctx context.Context, userID uuid.UUID, book *catalogEntities.Book) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, book)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) (*catalogEntities.Book, error) {
+	args := m.Called(ctx, householdID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*catalogEntities.Book), args.Error(1)
+}
+
+func (m *MockCatalogRepository) FindBooks(ctx context.Context, householdID uuid.UUID, filter catalogEntities.BookFilter) ([]catalogEntities.Book, error) {
+	args := m.Called(ctx, householdID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]catalogEntities.Book), args.Error(1)
+}
+
+func (m *MockCatalogRepository) UpdateBook(ctx context.Context, book *catalogEntities.Book) error {
+	return m.Called(ctx, book).Error(0)
+}
+
+func (m *MockCatalogRepository) HasCover(ctx context.Context, householdID uuid.UUID, coverID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, householdID, coverID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCatalogRepository) DeleteBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) error {
+	return m.Called(ctx, householdID, bookID).Error(0)
+}
+
+func (m *MockCatalogRepository) CreateCopy(ctx context.Context, copy *catalogEntities.Copy) (uuid.UUID, error) {
+	args := m.Called(ctx, copy)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) (*catalogEntities.Copy, error) {
+	args := m.Called(ctx, householdID, copyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*catalogEntities.Copy), args.Error(1)
+}
+
+func (m *MockCatalogRepository) GetCopiesByBook(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) ([]catalogEntities.Copy, error) {
+	args := m.Called(ctx, householdID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]catalogEntities.Copy), args.Error(1)
+}
+
+func (m *MockCatalogRepository) UpdateCopy(ctx context.Context, copy *catalogEntities.Copy) error {
+	return m.Called(ctx, copy).Error(0)
+}
+
+func (m *MockCatalogRepository) MoveCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID, locationID *uuid.UUID) error {
+	return m.Called(ctx, householdID, copyID, locationID).Error(0)
+}
+
+func (m *MockCatalogRepository) DeleteCopy(ctx context.Context, householdID uuid.UUID, copyID uuid.UUID) error {
+	return m.Called(ctx, householdID, copyID).Error(0)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+func newUseCase(publicURL string) (UseCase, *MockRepository, *MockCatalogRepository, *MockHouseholdRepository) {
+	repo, catalog, households := new(MockRepository), new(MockCatalogRepository), new(MockHouseholdRepository)
+	return NewUseCase(repo, catalog, households, publicURL), repo, catalog, households
+}
+
+func TestRenderLabels(t *testing.T) {
+	ctx := context.Background()
+	userID, householdID := uuid.New(), uuid.New()
+	member := &householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleViewer}
+	copies := []entities.Copy{
+		{CopyID: uuid.New(), ShortID: "K7M2Q9X0", Title: "Пикник на обочине", Authors: pq.StringArray{"Аркадий Стругацкий", "Борис Стругацкий"}},
+		{CopyID: uuid.New(), ShortID: "3F8HZ1WN", Title: "Солярис", Authors: pq.StringArray{"Станислав Лем"}},
+	}
+
+	t.Run("selected copies are rendered", func(t *testing.T) {
+		u, repo, _, households := newUseCase("https://library.example.org/")
+		ids := []uuid.UUID{copies[0].CopyID, copies[1].CopyID}
+		households.On("GetMembership", ctx, userID).Return(member, nil)
+		repo.On("GetCopies", ctx, householdID, ids).Return(copies, nil)
+
+		var document bytes.Buffer
+		err := u.RenderLabels(ctx, userID, &dtos.LabelsRequest{CopyIDs: ids, Layout: dtos.Layout{Sheet: "avery-l7160"}}, &document)
+
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(document.Bytes(), []byte("%PDF-")))
+	})
+
+	t.Run("copy of another household", func(t *testing.T) {
+		u, repo, _, households := newUseCase("")
+		ids := []uuid.UUID{copies[0].CopyID, uuid.New()}
+		households.On("GetMembership", ctx, userID).Return(member, nil)
+		repo.On("GetCopies", ctx, householdID, ids).Return(copies[:1], nil)
+
+		var document bytes.Buffer
+		err := u.RenderLabels(ctx, userID, &dtos.LabelsRequest{CopyIDs: ids, Layout: dtos.Layout{Sheet: "avery-l7160"}}, &document)
+
+		assert.ErrorIs(t, err, errors.ErrCopyNotFound)
+		assert.Zero(t, document.Len())
+	})
+
+	t.Run("whole location", func(t *testing.T) {
+		u, repo, catalog, households := newUseCase("")
+		locationID := uuid.New()
+		households.On("GetMembership", ctx, userID).Return(member, nil)
+		catalog.On("GetLocation", ctx, householdID, locationID).Return(&catalogEntities.Location{LocationID: locationID}, nil)
+		repo.On("GetLocationCopies", ctx, householdID, locationID).Return(copies, nil)
+
+		var document bytes.Buffer
+		err := u.RenderLabels(ctx, userID, &dtos.LabelsRequest{LocationID: &locationID, Layout: dtos.Layout{Sheet: "avery-5160", Skip: 4}}, &document)
+
+		assert.NoError(t, err)
+		assert.NotZero(t, document.Len())
+	})
+
+	t.Run("unknown location", func(t *testing.T) {
+		u, _, catalog, households := newUseCase("")
+		locationID := uuid.New()
+		households.On("GetMembership", ctx, userID).Return(member, nil)
+		catalog.On("GetLocation", ctx, householdID, locationID).Return(nil, sql.ErrNoRows)
+
+		err := u.RenderLabels(ctx, userID, &dtos.LabelsRequest{LocationID: &locationID, Layout: dtos.Layout{Sheet: "avery-5160"}}, &bytes.Buffer{})
+
+		assert.ErrorIs(t, err, errors.ErrLocationNotFound)
+	})
+
+	t.Run("unknown sheet", func(t *testing.T) {
+		u, _, _, _ := newUseCase("")
+
+		err := u.RenderLabels(ctx, userID, &dtos.LabelsRequest{CopyIDs: []uuid.UUID{uuid.New()}, Layout: dtos.Layout{Sheet: "avery-0000"}}, &bytes.Buffer{})
+
+		assert.ErrorIs(t, err, errors.ErrLabelSheetUnknown)
+	})
+
+	t.Run("custom sheet wider than the page", func(t *testing.T) {
+		u, _, _, _ := newUseCase("")
+		custom := &dtos.SheetRequest{PageWidth: 210, PageHeight: 297, Columns: 4, Rows: 8, LabelWidth: 63.5, LabelHeight: 33.9}
+
+		err := u.RenderLabels(ctx, userID, &dtos.LabelsRequest{CopyIDs: []uuid.UUID{uuid.New()}, Layout: dtos.Layout{Custom: custom}}, &bytes.Buffer{})
+
+		assert.ErrorIs(t, err, errors.ErrLabelSheetInvalid)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		u, _, _, households := newUseCase("")
+		households.On("GetMembership", ctx, userID).Return(nil, sql.ErrNoRows)
+
+		err := u.RenderLabels(ctx, userID, &dtos.LabelsRequest{CopyIDs: []uuid.UUID{uuid.New()}, Layout: dtos.Layout{Sheet: "avery-l7160"}}, &bytes.Buffer{})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdNotFound)
+	})
+}
+
+func TestRenderShelfLabels(t *testing.T) {
+	ctx := context.Background()
+	userID, householdID := uuid.New(), uuid.New()
+	member := &householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleViewer}
+
+	t.Run("locations are rendered", func(t *testing.T) {
+		u, repo, _, households := newUseCase("https://library.example.org")
+		ids := []uuid.UUID{uuid.New()}
+		households.On("GetMembership", ctx, userID).Return(member, nil)
+		repo.On("GetLocations", ctx, householdID, ids).Return([]entities.Location{{LocationID: ids[0], ShortID: "3F8HZ1WN", Name: "Гостиная"}}, nil)
+
+		var document bytes.Buffer
+		err := u.RenderShelfLabels(ctx, userID, &dtos.ShelfLabelsRequest{LocationIDs: ids, Layout: dtos.Layout{Sheet: "avery-l7163"}}, &document)
+
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(document.Bytes(), []byte("%PDF-")))
+	})
+
+	t.Run("location of another household", func(t *testing.T) {
+		u, repo, _, households := newUseCase("")
+		ids := []uuid.UUID{uuid.New()}
+		households.On("GetMembership", ctx, userID).Return(member, nil)
+		repo.On("GetLocations", ctx, householdID, ids).Return([]entities.Location{}, nil)
+
+		err := u.RenderShelfLabels(ctx, userID, &dtos.ShelfLabelsRequest{LocationIDs: ids, Layout: dtos.Layout{Sheet: "avery-l7163"}}, &bytes.Buffer{})
+
+		assert.ErrorIs(t, err, errors.ErrLocationNotFound)
+	})
+}
+
+func TestLabel(t *testing.T) {
+	copy := entities.Copy{ShortID: "K7M2Q9X0", Title: "Солярис", Authors: pq.StringArray{"Станислав Лем"}}
+
+	label := NewUseCase(nil, nil, nil, "https://library.example.org/").(*useCase).label(copy)
+
+	assert.Equal(t, "K7M2-Q9X0", label.Code)
+	assert.Equal(t, "Станислав Лем", label.Subtitle)
+	assert.Equal(t, "https://library.example.org/copies/K7M2Q9X0", label.URL)
+}
@@ -0,0 +1,245 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/loan/dtos"
+	"home-library/internal/services/loan/entities"
+	"home-library/internal/services/loan/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) LendCopy(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	copyID, err := uuid.Parse(c.Param("copy_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var payload dtos.LendCopyRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	loanID, err := h.u.LendCopy(c.Request().Context(), userID, copyID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to lend copy")
+	}
+
+	return c.JSON(http.StatusCreated, dtos.CreateLoanResponse{LoanID: loanID})
+}
+
+func (h *handler) GetLoans(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var request dtos.ListLoansRequest
+	if err := c.Bind(&request); err != nil {
+		log.Error().Err(err).Msg("failed to bind query parameters")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	loans, err := h.u.GetLoans(c.Request().Context(), userID, request)
+	if err != nil {
+		return h.handleError(c, err, "failed to get loans")
+	}
+
+	return c.JSON(http.StatusOK, loans)
+}
+
+func (h *handler) GetBorrowedLoans(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	loans, err := h.u.GetBorrowedLoans(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get borrowed loans")
+	}
+
+	return c.JSON(http.StatusOK, loans)
+}
+
+func (h *handler) ReturnLoan(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	loanID, err := uuid.Parse(c.Param("loan_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.ReturnLoan(c.Request().Context(), userID, loanID); err != nil {
+		return h.handleError(c, err, "failed to return loan")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) GetFriendBooks(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	householdID, err := uuid.Parse(c.Param("household_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	var request dtos.ListSharedBooksRequest
+	if err := c.Bind(&request); err != nil {
+		log.Error().Err(err).Msg("failed to bind query parameters")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := request.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	books, err := h.u.GetFriendBooks(c.Request().Context(), userID, householdID, request)
+	if err != nil {
+		return h.handleError(c, err, "failed to get friend books")
+	}
+
+	return c.JSON(http.StatusOK, books)
+}
+
+func (h *handler) RequestBorrow(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.BorrowRequestRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	requestID, err := h.u.RequestBorrow(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to request borrow")
+	}
+
+	return c.JSON(http.StatusCreated, dtos.CreateBorrowRequestResponse{RequestID: requestID})
+}
+
+func (h *handler) GetBorrowRequests(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var request dtos.ListBorrowRequestsRequest
+	if err := c.Bind(&request); err != nil {
+		log.Error().Err(err).Msg("failed to bind query parameters")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	requests, err := h.u.GetBorrowRequests(c.Request().Context(), userID, request)
+	if err != nil {
+		return h.handleError(c, err, "failed to get borrow requests")
+	}
+
+	return c.JSON(http.StatusOK, requests)
+}
+
+func (h *handler) ApproveBorrowRequest(c echo.Context) error {
+	return h.answerBorrowRequest(c, entities.StatusApproved)
+}
+
+func (h *handler) DeclineBorrowRequest(c echo.Context) error {
+	return h.answerBorrowRequest(c, entities.StatusDeclined)
+}
+
+func (h *handler) HandOverBorrowRequest(c echo.Context) error {
+	return h.answerBorrowRequest(c, entities.StatusHandedOver)
+}
+
+func (h *handler) ReturnBorrowRequest(c echo.Context) error {
+	return h.answerBorrowRequest(c, entities.StatusReturned)
+}
+
+func (h *handler) CancelBorrowRequest(c echo.Context) error {
+	return h.answerBorrowRequest(c, entities.StatusCancelled)
+}
+
+func (h *handler) answerBorrowRequest(c echo.Context, status entities.Status) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	requestID, err := uuid.Parse(c.Param("request_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.AnswerBorrowRequest(c.Request().Context(), userID, requestID, status); err != nil {
+		return h.handleError(c, err, "failed to answer borrow request")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrCopyNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Экземпляр не найден", nil))
+	case errors.Is(err, customErrors.ErrLoanNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Выдача не найдена или книга уже возвращена", nil))
+	case errors.Is(err, customErrors.ErrFriendshipNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Эта домашняя библиотека не в друзьях", nil))
+	case errors.Is(err, customErrors.ErrBorrowRequestNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Запрос на книгу не найден", nil))
+	case errors.Is(err, customErrors.ErrCopyOnLoan):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Экземпляр уже выдан", nil))
+	case errors.Is(err, customErrors.ErrBorrowRequestExists):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Запрос на этот экземпляр уже отправлен", nil))
+	case errors.Is(err, customErrors.ErrBorrowTransition):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Запрос на книгу уже в другом статусе", nil))
+	case errors.Is(err, customErrors.ErrBorrowOwnCopy):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Нельзя попросить свою книгу", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
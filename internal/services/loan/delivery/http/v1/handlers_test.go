@@ -0,0 +1,194 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"home-library/internal/services/loan/dtos"
+	"home-library/internal/services/loan/entities"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) LendCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload dtos.LendCopyRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, copyID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) GetLoans(ctx context.Context, userID uuid.UUID, request dtos.ListLoansRequest) ([]dtos.LoanResponse, error) {
+	args := m.Called(ctx, userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.LoanResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetBorrowedLoans(ctx context.Context, userID uuid.UUID) ([]dtos.LoanResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.LoanResponse), args.Error(1)
+}
+
+func (m *MockUseCase) ReturnLoan(ctx context.Context, userID uuid.UUID, loanID uuid.UUID) error {
+	return m.Called(ctx, userID, loanID).Error(0)
+}
+
+func (m *MockUseCase) GetFriendBooks(ctx context.Context, userID uuid.UUID, householdID uuid.UUID, request dtos.ListSharedBooksRequest) ([]dtos.SharedBookResponse, error) {
+	args := m.Called(ctx, userID, householdID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.SharedBookResponse), args.Error(1)
+}
+
+func (m *MockUseCase) RequestBorrow(ctx context.Context, userID uuid.UUID, payload dtos.BorrowRequestRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) GetBorrowRequests(ctx context.Context, userID uuid.UUID, request dtos.ListBorrowRequestsRequest) ([]dtos.BorrowRequestResponse, error) {
+	args := m.Called(ctx, userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.BorrowRequestResponse), args.Error(1)
+}
+
+func (m *MockUseCase) AnswerBorrowRequest(ctx context.Context, userID uuid.UUID, requestID uuid.UUID, status entities.Status) error {
+	return m.Called(ctx, userID, requestID, status).Error(0)
+}
+
+func newContext(e *echo.Echo, method string, target string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestLendCopy(t *testing.T) {
+	e := echo.New()
+
+	t.Run("borrower name is required", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		c, rec := newContext(e, http.MethodPost, "/copies/x/loans", `{"borrower_name":""}`, uuid.New())
+		c.SetParamNames("copy_id")
+		c.SetParamValues(uuid.New().String())
+
+		err := handler.LendCopy(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "LendCopy", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("copy already on loan", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, copyID := uuid.New(), uuid.New()
+		c, rec := newContext(e, http.MethodPost, "/copies/x/loans", `{"borrower_name":"Оля"}`, userID)
+		c.SetParamNames("copy_id")
+		c.SetParamValues(copyID.String())
+
+		mockUseCase.On("LendCopy", context.Background(), userID, copyID, dtos.LendCopyRequest{BorrowerName: "Оля"}).
+			Return(uuid.Nil, customErrors.ErrCopyOnLoan)
+
+		err := handler.LendCopy(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestGetBorrowRequests(t *testing.T) {
+	e := echo.New()
+
+	t.Run("incoming requests", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+		c, rec := newContext(e, http.MethodGet, "/borrow-requests?incoming=true", "", userID)
+
+		mockUseCase.On("GetBorrowRequests", context.Background(), userID, dtos.ListBorrowRequestsRequest{Incoming: true}).
+			Return([]dtos.BorrowRequestResponse{{RequestID: uuid.New(), Status: entities.StatusRequested}}, nil)
+
+		err := handler.GetBorrowRequests(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+}
+
+func TestAnswerBorrowRequest(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name   string
+		action func(h *handler, c echo.Context) error
+		status entities.Status
+	}{
+		{"approve", (*handler).ApproveBorrowRequest, entities.StatusApproved},
+		{"decline", (*handler).DeclineBorrowRequest, entities.StatusDeclined},
+		{"hand over", (*handler).HandOverBorrowRequest, entities.StatusHandedOver},
+		{"return", (*handler).ReturnBorrowRequest, entities.StatusReturned},
+		{"cancel", (*handler).CancelBorrowRequest, entities.StatusCancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			handler := NewHandler(mockUseCase)
+			userID, requestID := uuid.New(), uuid.New()
+			c, rec := newContext(e, http.MethodPost, "/borrow-requests/x", "", userID)
+			c.SetParamNames("request_id")
+			c.SetParamValues(requestID.String())
+
+			mockUseCase.On("AnswerBorrowRequest", context.Background(), userID, requestID, tt.status).Return(nil)
+
+			err := tt.action(handler, c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+
+	t.Run("request in another status", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, requestID := uuid.New(), uuid.New()
+		c, rec := newContext(e, http.MethodPost, "/borrow-requests/x/approve", "", userID)
+		c.SetParamNames("request_id")
+		c.SetParamValues(requestID.String())
+
+		mockUseCase.On("AnswerBorrowRequest", context.Background(), userID, requestID, entities.StatusApproved).
+			Return(customErrors.ErrBorrowTransition)
+
+		err := handler.ApproveBorrowRequest(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		var response dtos.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusConflict, response.Code)
+	})
+}
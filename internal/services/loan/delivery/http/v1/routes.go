@@ -0,0 +1,18 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) LoanRoutes(domain *echo.Group) {
+	domain.POST("/copies/:copy_id/loans", h.LendCopy)
+	domain.GET("/loans", h.GetLoans)
+	domain.GET("/loans/borrowed", h.GetBorrowedLoans)
+	domain.POST("/loans/:loan_id/return", h.ReturnLoan)
+	domain.GET("/friends/:household_id/books", h.GetFriendBooks)
+	domain.POST("/borrow-requests", h.RequestBorrow)
+	domain.GET("/borrow-requests", h.GetBorrowRequests)
+	domain.POST("/borrow-requests/:request_id/approve", h.ApproveBorrowRequest)
+	domain.POST("/borrow-requests/:request_id/decline", h.DeclineBorrowRequest)
+	domain.POST("/borrow-requests/:request_id/hand-over", h.HandOverBorrowRequest)
+	domain.POST("/borrow-requests/:request_id/return", h.ReturnBorrowRequest)
+	domain.POST("/borrow-requests/:request_id/cancel", h.CancelBorrowRequest)
+}
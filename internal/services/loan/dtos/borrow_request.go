@@ -0,0 +1,62 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/loan/entities"
+	"time"
+)
+
+// BorrowRequestRequest asks a friend household for one of its copies.
+type BorrowRequestRequest struct {
+	CopyID  uuid.UUID  `json:"copy_id" validate:"required"`
+	Message string     `json:"message" validate:"max=1000"`
+	DueAt   *time.Time `json:"due_at"`
+}
+
+func (r *BorrowRequestRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type CreateBorrowRequestResponse struct {
+	RequestID uuid.UUID `json:"request_id"`
+}
+
+type ListBorrowRequestsRequest struct {
+	// Incoming lists the requests made to the household instead of the
+	// ones it made.
+	Incoming bool `query:"incoming"`
+}
+
+type BorrowRequestResponse struct {
+	RequestID           uuid.UUID       `json:"request_id"`
+	CopyID              uuid.UUID       `json:"copy_id"`
+	Title               string          `json:"title"`
+	LenderHouseholdID   uuid.UUID       `json:"lender_household_id"`
+	BorrowerHouseholdID uuid.UUID       `json:"borrower_household_id"`
+	RequestedBy         uuid.UUID       `json:"requested_by"`
+	Status              entities.Status `json:"status"`
+	Message             string          `json:"message,omitempty"`
+	DueAt               *time.Time      `json:"due_at,omitempty"`
+	LoanID              *uuid.UUID      `json:"loan_id,omitempty"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+}
+
+func NewBorrowRequestResponse(request entities.BorrowRequest) BorrowRequestResponse {
+	return BorrowRequestResponse{
+		RequestID:           request.RequestID,
+		CopyID:              request.CopyID,
+		Title:               request.Title,
+		LenderHouseholdID:   request.LenderHouseholdID,
+		BorrowerHouseholdID: request.BorrowerHouseholdID,
+		RequestedBy:         request.RequestedBy,
+		Status:              request.Status,
+		Message:             request.Message,
+		DueAt:               request.DueAt,
+		LoanID:              request.LoanID,
+		CreatedAt:           request.CreatedAt,
+		UpdatedAt:           request.UpdatedAt,
+	}
+}
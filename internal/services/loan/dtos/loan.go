@@ -0,0 +1,107 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/loan/entities"
+	"time"
+)
+
+// LendCopyRequest records a copy lent to someone outside the app, so only
+// their name is known.
+type LendCopyRequest struct {
+	BorrowerName string     `json:"borrower_name" validate:"required,max=255"`
+	DueAt        *time.Time `json:"due_at"`
+}
+
+func (r *LendCopyRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type CreateLoanResponse struct {
+	LoanID uuid.UUID `json:"loan_id"`
+}
+
+type ListLoansRequest struct {
+	// All includes the returned loans.
+	All bool `query:"all"`
+}
+
+type LoanResponse struct {
+	LoanID              uuid.UUID  `json:"loan_id"`
+	HouseholdID         uuid.UUID  `json:"household_id"`
+	CopyID              uuid.UUID  `json:"copy_id"`
+	Title               string     `json:"title"`
+	BorrowerHouseholdID *uuid.UUID `json:"borrower_household_id,omitempty"`
+	BorrowerUserID      *uuid.UUID `json:"borrower_user_id,omitempty"`
+	BorrowerName        string     `json:"borrower_name"`
+	LentBy              uuid.UUID  `json:"lent_by"`
+	LentAt              time.Time  `json:"lent_at"`
+	DueAt               *time.Time `json:"due_at,omitempty"`
+	ReturnedAt          *time.Time `json:"returned_at,omitempty"`
+}
+
+func NewLoanResponse(loan entities.Loan) LoanResponse {
+	return LoanResponse{
+		LoanID:              loan.LoanID,
+		HouseholdID:         loan.HouseholdID,
+		CopyID:              loan.CopyID,
+		Title:               loan.Title,
+		BorrowerHouseholdID: loan.BorrowerHouseholdID,
+		BorrowerUserID:      loan.BorrowerUserID,
+		BorrowerName:        loan.BorrowerName,
+		LentBy:              loan.LentBy,
+		LentAt:              loan.LentAt,
+		DueAt:               loan.DueAt,
+		ReturnedAt:          loan.ReturnedAt,
+	}
+}
+
+type ListSharedBooksRequest struct {
+	Query  string `query:"q" validate:"max=255"`
+	Limit  int    `query:"limit" validate:"gte=0,lte=100"`
+	Offset int    `query:"offset" validate:"gte=0"`
+}
+
+func (r *ListSharedBooksRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type SharedBookResponse struct {
+	BookID      uuid.UUID  `json:"book_id"`
+	Title       string     `json:"title"`
+	Authors     []string   `json:"authors"`
+	ISBN        string     `json:"isbn"`
+	Language    string     `json:"language"`
+	Series      string     `json:"series"`
+	SeriesIndex *float64   `json:"series_index"`
+	CoverID     *uuid.UUID `json:"cover_id"`
+	Copies      int        `json:"copies"`
+	// AvailableCopies are the copies a borrow request can name.
+	AvailableCopies []string `json:"available_copies"`
+}
+
+func NewSharedBookResponse(book entities.SharedBook) SharedBookResponse {
+	return SharedBookResponse{
+		BookID:          book.BookID,
+		Title:           book.Title,
+		Authors:         nonNil(book.Authors),
+		ISBN:            book.ISBN,
+		Language:        book.Language,
+		Series:          book.Series,
+		SeriesIndex:     book.SeriesIndex,
+		CoverID:         book.CoverID,
+		Copies:          book.Copies,
+		AvailableCopies: nonNil(book.AvailableCopies),
+	}
+}
+
+// nonNil keeps empty lists as [] rather than null in responses.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,75 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusRequested  Status = "requested"
+	StatusApproved   Status = "approved"
+	StatusDeclined   Status = "declined"
+	StatusCancelled  Status = "cancelled"
+	StatusHandedOver Status = "handed_over"
+	StatusReturned   Status = "returned"
+)
+
+// transitions lists the statuses a request may move to from each status.
+// Declined, cancelled and returned requests are settled for good.
+var transitions = map[Status][]Status{
+	StatusRequested:  {StatusApproved, StatusDeclined, StatusCancelled},
+	StatusApproved:   {StatusHandedOver, StatusDeclined, StatusCancelled},
+	StatusHandedOver: {StatusReturned},
+}
+
+// ByLender reports whether the status is set by the household lending the
+// copy rather than by the one borrowing it.
+func (s Status) ByLender() bool {
+	return s != StatusRequested && s != StatusCancelled
+}
+
+// BorrowRequest is a friend household asking to borrow a copy.
+type BorrowRequest struct {
+	RequestID           uuid.UUID  `db:"request_id"`
+	CopyID              uuid.UUID  `db:"copy_id"`
+	LenderHouseholdID   uuid.UUID  `db:"lender_household_id"`
+	BorrowerHouseholdID uuid.UUID  `db:"borrower_household_id"`
+	RequestedBy         uuid.UUID  `db:"requested_by"`
+	Status              Status     `db:"status"`
+	Message             string     `db:"message"`
+	DueAt               *time.Time `db:"due_at"`
+	LoanID              *uuid.UUID `db:"loan_id"`
+	CreatedAt           time.Time  `db:"created_at"`
+	UpdatedAt           time.Time  `db:"updated_at"`
+	// Title is the title of the requested book, filled in by reads.
+	Title string `db:"title"`
+}
+
+func NewBorrowRequest(copy *Copy, borrowerHouseholdID uuid.UUID, requestedBy uuid.UUID) *BorrowRequest {
+	now := time.Now()
+	return &BorrowRequest{
+		RequestID:           uuid.New(),
+		CopyID:              copy.CopyID,
+		LenderHouseholdID:   copy.HouseholdID,
+		BorrowerHouseholdID: borrowerHouseholdID,
+		RequestedBy:         requestedBy,
+		Status:              StatusRequested,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		Title:               copy.Title,
+	}
+}
+
+// CanMoveTo reports whether the request may go from its status to the given
+// one.
+func (r *BorrowRequest) CanMoveTo(status Status) bool {
+	for _, next := range transitions[r.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
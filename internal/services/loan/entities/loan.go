@@ -0,0 +1,73 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Loan is a copy lent out by the household owning it. The borrower is a
+// member of a friend household when the loan comes from a borrow request,
+// otherwise only a name.
+type Loan struct {
+	LoanID              uuid.UUID  `db:"loan_id"`
+	HouseholdID         uuid.UUID  `db:"household_id"`
+	CopyID              uuid.UUID  `db:"copy_id"`
+	BorrowerHouseholdID *uuid.UUID `db:"borrower_household_id"`
+	BorrowerUserID      *uuid.UUID `db:"borrower_user_id"`
+	BorrowerName        string     `db:"borrower_name"`
+	LentBy              uuid.UUID  `db:"lent_by"`
+	LentAt              time.Time  `db:"lent_at"`
+	DueAt               *time.Time `db:"due_at"`
+	ReturnedAt          *time.Time `db:"returned_at"`
+	CreatedAt           time.Time  `db:"created_at"`
+	// Title is the title of the lent book, filled in by listings.
+	Title string `db:"title"`
+}
+
+func NewLoan(householdID uuid.UUID, copyID uuid.UUID, lentBy uuid.UUID, borrowerName string) *Loan {
+	now := time.Now()
+	return &Loan{
+		LoanID:       uuid.New(),
+		HouseholdID:  householdID,
+		CopyID:       copyID,
+		BorrowerName: borrowerName,
+		LentBy:       lentBy,
+		LentAt:       now,
+		CreatedAt:    now,
+	}
+}
+
+// Copy is what lending needs to know about a copy of any household.
+type Copy struct {
+	CopyID      uuid.UUID `db:"copy_id"`
+	BookID      uuid.UUID `db:"book_id"`
+	HouseholdID uuid.UUID `db:"household_id"`
+	Title       string    `db:"title"`
+}
+
+// SharedBook is a book of a friend household along with the copies that are
+// not on loan.
+type SharedBook struct {
+	BookID      uuid.UUID      `db:"book_id"`
+	Title       string         `db:"title"`
+	Authors     pq.StringArray `db:"authors"`
+	ISBN        string         `db:"isbn"`
+	Language    string         `db:"language"`
+	Series      string         `db:"series"`
+	SeriesIndex *float64       `db:"series_index"`
+	CoverID     *uuid.UUID     `db:"cover_id"`
+	Copies      int            `db:"copies"`
+	// AvailableCopies holds the IDs of the copies that can be requested.
+	AvailableCopies pq.StringArray `db:"available_copies"`
+}
+
+// SharedBookFilter narrows down a friend's catalog. Zero fields do not
+// filter.
+type SharedBookFilter struct {
+	// Query matches a substring of the title or of an author's name.
+	Query  string
+	Limit  int
+	Offset int
+}
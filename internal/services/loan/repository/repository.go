@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/loan/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/events"
+	"home-library/pkg/storage"
+	"home-library/pkg/transaction"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository methods that take a household ID only return loans of that
+// household and borrow requests it is a side of. GetCopy is the exception:
+// borrowing starts from a copy of another household, and the use case checks
+// the two are friends.
+type Repository interface {
+	GetCopy(ctx context.Context, copyID uuid.UUID) (*entities.Copy, error)
+	// FindSharedBooks lists the catalog of a household as its friends see it.
+	FindSharedBooks(ctx context.Context, householdID uuid.UUID, filter entities.SharedBookFilter) ([]entities.SharedBook, error)
+
+	CreateLoan(ctx context.Context, loan *entities.Loan) (uuid.UUID, error)
+	GetLoan(ctx context.Context, householdID uuid.UUID, loanID uuid.UUID) (*entities.Loan, error)
+	// GetLoans lists the household's loans, only the open ones unless all
+	// is set, latest first.
+	GetLoans(ctx context.Context, householdID uuid.UUID, all bool) ([]entities.Loan, error)
+	// GetBorrowedLoans lists the open loans of other households to members
+	// of this one.
+	GetBorrowedLoans(ctx context.Context, householdID uuid.UUID) ([]entities.Loan, error)
+	ReturnLoan(ctx context.Context, householdID uuid.UUID, loanID uuid.UUID, now time.Time) error
+
+	CreateBorrowRequest(ctx context.Context, request *entities.BorrowRequest) error
+	GetBorrowRequest(ctx context.Context, householdID uuid.UUID, requestID uuid.UUID) (*entities.BorrowRequest, error)
+	GetBorrowRequestByLoan(ctx context.Context, householdID uuid.UUID, loanID uuid.UUID) (*entities.BorrowRequest, error)
+	// GetBorrowRequests lists the requests to the household when incoming
+	// is set, and the requests it made otherwise.
+	GetBorrowRequests(ctx context.Context, householdID uuid.UUID, incoming bool) ([]entities.BorrowRequest, error)
+	// UpdateBorrowRequest saves the status and loan of the request if its
+	// status is still from, so concurrent answers cannot both apply.
+	UpdateBorrowRequest(ctx context.Context, request *entities.BorrowRequest, from entities.Status) error
+}
+
+// constraints translates a second loan of the same copy, a second open
+// request for it, and a copy deleted meanwhile.
+var constraints = storage.Constraints{
+	"idx_loans_open_copy":          errors.ErrCopyOnLoan,
+	"loans_copy_fkey":              errors.ErrCopyNotFound,
+	"idx_borrow_requests_open":     errors.ErrBorrowRequestExists,
+	"borrow_requests_copy_id_fkey": errors.ErrCopyNotFound,
+	"borrow_requests_loan_id_fkey": errors.ErrLoanNotFound,
+}
+
+// loanColumns selects a loan aliased as l with the title of its book.
+const loanColumns = `
+	l.loan_id, l.household_id, l.copy_id, l.borrower_household_id, l.borrower_user_id,
+	l.borrower_name, l.lent_by, l.lent_at, l.due_at, l.returned_at, l.created_at, b.title
+	FROM loans l
+	JOIN copies c ON c.copy_id = l.copy_id
+	JOIN books b ON b.book_id = c.book_id
+`
+
+// requestColumns selects a borrow request aliased as r with the title of
+// its book.
+const requestColumns = `
+	r.request_id, r.copy_id, r.lender_household_id, r.borrower_household_id, r.requested_by,
+	r.status, r.message, r.due_at, r.loan_id, r.created_at, r.updated_at, b.title
+	FROM borrow_requests r
+	JOIN copies c ON c.copy_id = r.copy_id
+	JOIN books b ON b.book_id = c.book_id
+`
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) GetCopy(ctx context.Context, copyID uuid.UUID) (*entities.Copy, error) {
+	var copy entities.Copy
+	query := `
+		SELECT c.copy_id, c.book_id, c.household_id, b.title
+		FROM copies c
+		JOIN books b ON b.book_id = c.book_id
+		WHERE c.copy_id = $1
+	`
+
+	err := r.db.GetContext(ctx, &copy, query, copyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &copy, nil
+}
+
+func (r *repository) FindSharedBooks(ctx context.Context, householdID uuid.UUID, filter entities.SharedBookFilter) ([]entities.SharedBook, error) {
+	books := make([]entities.SharedBook, 0)
+	conditions := []string{"b.household_id = $1"}
+	args := []interface{}{householdID}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.Query != "" {
+		pattern := arg("%" + likeEscaper.Replace(filter.Query) + "%")
+		conditions = append(conditions, "(b.title ILIKE "+pattern+" OR array_to_string(b.authors, ' ') ILIKE "+pattern+")")
+	}
+
+	// Books without copies are wishes or sold ones, there is nothing to
+	// borrow.
+	query := `
+		SELECT b.book_id, b.title, b.authors, b.isbn, b.language, b.series, b.series_index, b.cover_id,
+			count(c.copy_id) AS copies,
+			COALESCE(array_agg(c.copy_id::text ORDER BY c.created_at) FILTER (WHERE l.loan_id IS NULL), '{}') AS available_copies
+		FROM books b
+		JOIN copies c ON c.book_id = b.book_id
+		LEFT JOIN loans l ON l.copy_id = c.copy_id AND l.returned_at IS NULL
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY b.book_id
+		ORDER BY b.title, b.created_at`
+	if filter.Limit > 0 {
+		query += " LIMIT " + arg(filter.Limit) + " OFFSET " + arg(filter.Offset)
+	}
+
+	err := r.db.SelectContext(ctx, &books, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *repository) CreateLoan(ctx context.Context, loan *entities.Loan) (uuid.UUID, error) {
+	query := `
+		INSERT INTO loans (
+			loan_id, household_id, copy_id, borrower_household_id, borrower_user_id,
+			borrower_name, lent_by, lent_at, due_at, created_at
+		) VALUES (
+			:loan_id, :household_id, :copy_id, :borrower_household_id, :borrower_user_id,
+			:borrower_name, :lent_by, :lent_at, :due_at, :created_at
+		)
+	`
+
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, query, loan); err != nil {
+			return err
+		}
+
+		return events.Record(ctx, tx, events.TypeLoanCreated, loan.LentBy, events.LoanCreated{
+			LoanID:       loan.LoanID,
+			CopyID:       loan.CopyID,
+			BorrowerName: loan.BorrowerName,
+			DueAt:        loan.DueAt,
+		})
+	})
+	if err != nil {
+		return uuid.Nil, constraints.Map(err)
+	}
+
+	return loan.LoanID, nil
+}
+
+func (r *repository) GetLoan(ctx context.Context, householdID uuid.UUID, loanID uuid.UUID) (*entities.Loan, error) {
+	var loan entities.Loan
+	query := `SELECT ` + loanColumns + ` WHERE l.household_id = $1 AND l.loan_id = $2`
+
+	err := r.db.GetContext(ctx, &loan, query, householdID, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &loan, nil
+}
+
+func (r *repository) GetLoans(ctx context.Context, householdID uuid.UUID, all bool) ([]entities.Loan, error) {
+	loans := make([]entities.Loan, 0)
+	query := `SELECT ` + loanColumns + `
+		WHERE l.household_id = $1 AND ($2 OR l.returned_at IS NULL)
+		ORDER BY l.lent_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &loans, query, householdID, all)
+	if err != nil {
+		return nil, err
+	}
+
+	return loans, nil
+}
+
+func (r *repository) GetBorrowedLoans(ctx context.Context, householdID uuid.UUID) ([]entities.Loan, error) {
+	loans := make([]entities.Loan, 0)
+	query := `SELECT ` + loanColumns + `
+		WHERE l.borrower_household_id = $1 AND l.returned_at IS NULL
+		ORDER BY l.due_at NULLS LAST, l.lent_at
+	`
+
+	err := r.db.SelectContext(ctx, &loans, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return loans, nil
+}
+
+func (r *repository) ReturnLoan(ctx context.Context, householdID uuid.UUID, loanID uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE loans
+		SET returned_at = $3
+		WHERE household_id = $1 AND loan_id = $2 AND returned_at IS NULL
+		RETURNING copy_id, lent_by
+	`
+
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		var returned struct {
+			CopyID uuid.UUID `db:"copy_id"`
+			LentBy uuid.UUID `db:"lent_by"`
+		}
+		if err := tx.GetContext(ctx, &returned, query, householdID, loanID, now); err != nil {
+			return err
+		}
+
+		// The event goes to the lender, whose household the loan is of.
+		return events.Record(ctx, tx, events.TypeLoanReturned, returned.LentBy, events.LoanReturned{
+			LoanID: loanID,
+			CopyID: returned.CopyID,
+		})
+	})
+}
+
+func (r *repository) CreateBorrowRequest(ctx context.Context, request *entities.BorrowRequest) error {
+	query := `
+		INSERT INTO borrow_requests (
+			request_id, copy_id, lender_household_id, borrower_household_id, requested_by,
+			status, message, due_at, created_at, updated_at
+		) VALUES (
+			:request_id, :copy_id, :lender_household_id, :borrower_household_id, :requested_by,
+			:status, :message, :due_at, :created_at, :updated_at
+		)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, request)
+	return constraints.Map(err)
+}
+
+func (r *repository) GetBorrowRequest(ctx context.Context, householdID uuid.UUID, requestID uuid.UUID) (*entities.BorrowRequest, error) {
+	var request entities.BorrowRequest
+	query := `SELECT ` + requestColumns + `
+		WHERE r.request_id = $2 AND (r.lender_household_id = $1 OR r.borrower_household_id = $1)
+	`
+
+	err := r.db.GetContext(ctx, &request, query, householdID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (r *repository) GetBorrowRequestByLoan(ctx context.Context, householdID uuid.UUID, loanID uuid.UUID) (*entities.BorrowRequest, error) {
+	var request entities.BorrowRequest
+	query := `SELECT ` + requestColumns + `
+		WHERE r.loan_id = $2 AND r.lender_household_id = $1
+	`
+
+	err := r.db.GetContext(ctx, &request, query, householdID, loanID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+func (r *repository) GetBorrowRequests(ctx context.Context, householdID uuid.UUID, incoming bool) ([]entities.BorrowRequest, error) {
+	requests := make([]entities.BorrowRequest, 0)
+	side := "r.borrower_household_id"
+	if incoming {
+		side = "r.lender_household_id"
+	}
+	query := `SELECT ` + requestColumns + `
+		WHERE ` + side + ` = $1
+		ORDER BY r.created_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &requests, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func (r *repository) UpdateBorrowRequest(ctx context.Context, request *entities.BorrowRequest, from entities.Status) error {
+	query := `
+		UPDATE borrow_requests
+		SET status = $3, loan_id = $4, updated_at = $5
+		WHERE request_id = $1 AND status = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, request.RequestID, from, request.Status, request.LoanID, request.UpdatedAt)
+	if err != nil {
+		return constraints.Map(err)
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.db.InTx(ctx, nil, fn)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
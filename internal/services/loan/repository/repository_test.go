@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/loan/entities"
+	customErrors "home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestFindSharedBooks(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	columns := []string{"book_id", "title", "authors", "isbn", "language", "series", "series_index", "cover_id", "copies", "available_copies"}
+
+	t.Run("books with copies and the ones not on loan", func(t *testing.T) {
+		householdID, copyID := uuid.New(), uuid.New()
+		rows := sqlmock.NewRows(columns).
+			AddRow(uuid.New(), "Солярис", "{\"Станислав Лем\"}", "", "ru", "", nil, nil, 2, "{"+copyID.String()+"}")
+
+		mock.ExpectQuery(`WHERE b.household_id = \$1 AND \(b.title ILIKE \$2 .+\) GROUP BY b.book_id ORDER BY b.title, b.created_at LIMIT \$3 OFFSET \$4`).
+			WithArgs(householdID, `%лем%`, 50, 0).
+			WillReturnRows(rows)
+
+		books, err := repo.FindSharedBooks(context.Background(), householdID, entities.SharedBookFilter{Query: "лем", Limit: 50})
+
+		assert.NoError(t, err)
+		assert.Len(t, books, 1)
+		assert.Equal(t, 2, books[0].Copies)
+		assert.Equal(t, []string{copyID.String()}, []string(books[0].AvailableCopies))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateLoan(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	tests := []struct {
+		name       string
+		code       pq.ErrorCode
+		constraint string
+		err        error
+	}{
+		{"copy already on loan", "23505", "idx_loans_open_copy", customErrors.ErrCopyOnLoan},
+		{"copy of another household", "23503", "loans_copy_fkey", customErrors.ErrCopyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := entities.NewLoan(uuid.New(), uuid.New(), uuid.New(), "Оля")
+
+			mock.ExpectBegin()
+			mock.ExpectExec("INSERT INTO loans").
+				WillReturnError(&pq.Error{Code: tt.code, Constraint: tt.constraint})
+			mock.ExpectRollback()
+
+			id, err := repo.CreateLoan(context.Background(), loan)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, uuid.Nil, id)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestReturnLoan(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("return is recorded as an event for the lender", func(t *testing.T) {
+		householdID, loanID, copyID, lentBy, now := uuid.New(), uuid.New(), uuid.New(), uuid.New(), time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE loans SET returned_at = \$3 WHERE household_id = \$1 AND loan_id = \$2 AND returned_at IS NULL RETURNING copy_id, lent_by`).
+			WithArgs(householdID, loanID, now).
+			WillReturnRows(sqlmock.NewRows([]string{"copy_id", "lent_by"}).AddRow(copyID, lentBy))
+		mock.ExpectExec("INSERT INTO outbox_events").
+			WithArgs(sqlmock.AnyArg(), "loan.returned", lentBy, `{"loan_id":"`+loanID.String()+`","copy_id":"`+copyID.String()+`"}`, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err := repo.ReturnLoan(context.Background(), householdID, loanID, now)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("returned loan is not returned again", func(t *testing.T) {
+		householdID, loanID, now := uuid.New(), uuid.New(), time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE loans SET returned_at = \$3 WHERE household_id = \$1 AND loan_id = \$2 AND returned_at IS NULL`).
+			WithArgs(householdID, loanID, now).
+			WillReturnRows(sqlmock.NewRows([]string{"copy_id", "lent_by"}))
+		mock.ExpectRollback()
+
+		err := repo.ReturnLoan(context.Background(), householdID, loanID, now)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateBorrowRequest(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("copy already requested by the household", func(t *testing.T) {
+		request := entities.NewBorrowRequest(&entities.Copy{CopyID: uuid.New(), HouseholdID: uuid.New()}, uuid.New(), uuid.New())
+
+		mock.ExpectExec("INSERT INTO borrow_requests").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_borrow_requests_open"})
+
+		err := repo.CreateBorrowRequest(context.Background(), request)
+
+		assert.ErrorIs(t, err, customErrors.ErrBorrowRequestExists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateBorrowRequest(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("request answered meanwhile is left alone", func(t *testing.T) {
+		request := &entities.BorrowRequest{RequestID: uuid.New(), Status: entities.StatusApproved, UpdatedAt: time.Now()}
+
+		mock.ExpectExec(`UPDATE borrow_requests .+ WHERE request_id = \$1 AND status = \$2`).
+			WithArgs(request.RequestID, entities.StatusRequested, entities.StatusApproved, nil, request.UpdatedAt).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdateBorrowRequest(context.Background(), request, entities.StatusRequested)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,409 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	friendRepository "home-library/internal/services/friend/repository"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/internal/services/loan/dtos"
+	"home-library/internal/services/loan/entities"
+	"home-library/internal/services/loan/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/transaction"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultLimit is the page size of a friend's catalog when the request does
+// not set one.
+const defaultLimit = 50
+
+// NotificationPrefix is followed by the new status of a borrow request in
+// the type of the notification about it, e.g. "borrow_request.approved".
+const NotificationPrefix = "borrow_request."
+
+// Notifier tells users about changes that concern them; the notification
+// use case is the implementation.
+type Notifier interface {
+	Notify(ctx context.Context, userIDs []uuid.UUID, typ string, payload any) error
+}
+
+// Notice is the payload of the borrow request notifications. The household
+// is the one that made the change.
+type Notice struct {
+	RequestID   uuid.UUID       `json:"request_id"`
+	Title       string          `json:"title"`
+	Status      entities.Status `json:"status"`
+	HouseholdID uuid.UUID       `json:"household_id"`
+	Name        string          `json:"name"`
+}
+
+type UseCase interface {
+	// LendCopy records a copy of the household lent to someone by name.
+	LendCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload dtos.LendCopyRequest) (loanID uuid.UUID, err error)
+	GetLoans(ctx context.Context, userID uuid.UUID, request dtos.ListLoansRequest) ([]dtos.LoanResponse, error)
+	// GetBorrowedLoans lists the copies friends have lent to the household.
+	GetBorrowedLoans(ctx context.Context, userID uuid.UUID) ([]dtos.LoanResponse, error)
+	// ReturnLoan marks a loan returned, along with the borrow request it
+	// was handed over for.
+	ReturnLoan(ctx context.Context, userID uuid.UUID, loanID uuid.UUID) error
+
+	// GetFriendBooks lists the catalog of a friend household.
+	GetFriendBooks(ctx context.Context, userID uuid.UUID, householdID uuid.UUID, request dtos.ListSharedBooksRequest) ([]dtos.SharedBookResponse, error)
+
+	RequestBorrow(ctx context.Context, userID uuid.UUID, payload dtos.BorrowRequestRequest) (requestID uuid.UUID, err error)
+	GetBorrowRequests(ctx context.Context, userID uuid.UUID, request dtos.ListBorrowRequestsRequest) ([]dtos.BorrowRequestResponse, error)
+	// AnswerBorrowRequest moves a request to the status. The lending
+	// household approves, declines, hands over and takes back; the
+	// borrowing one cancels. Handing over creates the loan.
+	AnswerBorrowRequest(ctx context.Context, userID uuid.UUID, requestID uuid.UUID, status entities.Status) error
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	friends    friendRepository.Repository
+	notifier   Notifier
+	tx         transaction.Transactor
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository, friends friendRepository.Repository, notifier Notifier, tx transaction.Transactor) UseCase {
+	return &useCase{r: r, households: households, friends: friends, notifier: notifier, tx: tx}
+}
+
+func (u *useCase) LendCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload dtos.LendCopyRequest) (loanID uuid.UUID, err error) {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	copy, err := u.r.GetCopy(ctx, copyID)
+	if err != nil {
+		return uuid.Nil, mapNoRows(err, errors.ErrCopyNotFound)
+	}
+	if copy.HouseholdID != member.HouseholdID {
+		return uuid.Nil, errors.ErrCopyNotFound
+	}
+
+	loan := entities.NewLoan(member.HouseholdID, copy.CopyID, userID, strings.TrimSpace(payload.BorrowerName))
+	loan.DueAt = payload.DueAt
+
+	return u.r.CreateLoan(ctx, loan)
+}
+
+func (u *useCase) GetLoans(ctx context.Context, userID uuid.UUID, request dtos.ListLoansRequest) ([]dtos.LoanResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loans, err := u.r.GetLoans(ctx, member.HouseholdID, request.All)
+	if err != nil {
+		return nil, err
+	}
+
+	return loanResponses(loans), nil
+}
+
+func (u *useCase) GetBorrowedLoans(ctx context.Context, userID uuid.UUID) ([]dtos.LoanResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loans, err := u.r.GetBorrowedLoans(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return loanResponses(loans), nil
+}
+
+func (u *useCase) ReturnLoan(ctx context.Context, userID uuid.UUID, loanID uuid.UUID) error {
+	member, err := u.editor(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		if err := u.r.ReturnLoan(ctx, member.HouseholdID, loanID, time.Now()); err != nil {
+			return mapNoRows(err, errors.ErrLoanNotFound)
+		}
+
+		request, err := u.r.GetBorrowRequestByLoan(ctx, member.HouseholdID, loanID)
+		switch {
+		case stdErrors.Is(err, sql.ErrNoRows):
+			return nil
+		case err != nil:
+			return err
+		case !request.CanMoveTo(entities.StatusReturned):
+			return nil
+		}
+
+		return u.move(ctx, request, entities.StatusReturned, member.HouseholdID)
+	})
+}
+
+func (u *useCase) GetFriendBooks(ctx context.Context, userID uuid.UUID, householdID uuid.UUID, request dtos.ListSharedBooksRequest) ([]dtos.SharedBookResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	friends, err := u.friends.AreFriends(ctx, member.HouseholdID, householdID)
+	if err != nil {
+		return nil, err
+	}
+	if !friends {
+		return nil, errors.ErrFriendshipNotFound
+	}
+
+	filter := entities.SharedBookFilter{
+		Query:  strings.TrimSpace(request.Query),
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultLimit
+	}
+
+	books, err := u.r.FindSharedBooks(ctx, householdID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.SharedBookResponse, len(books))
+	for i, book := range books {
+		response[i] = dtos.NewSharedBookResponse(book)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) RequestBorrow(ctx context.Context, userID uuid.UUID, payload dtos.BorrowRequestRequest) (requestID uuid.UUID, err error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	copy, err := u.r.GetCopy(ctx, payload.CopyID)
+	if err != nil {
+		return uuid.Nil, mapNoRows(err, errors.ErrCopyNotFound)
+	}
+	if copy.HouseholdID == member.HouseholdID {
+		return uuid.Nil, errors.ErrBorrowOwnCopy
+	}
+
+	// Copies of households that are not friends are not shown, so they
+	// are reported as missing rather than forbidden.
+	friends, err := u.friends.AreFriends(ctx, member.HouseholdID, copy.HouseholdID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !friends {
+		return uuid.Nil, errors.ErrCopyNotFound
+	}
+
+	request := entities.NewBorrowRequest(copy, member.HouseholdID, userID)
+	request.Message = strings.TrimSpace(payload.Message)
+	request.DueAt = payload.DueAt
+
+	err = u.tx.Do(ctx, func(ctx context.Context) error {
+		if err := u.r.CreateBorrowRequest(ctx, request); err != nil {
+			return err
+		}
+		return u.notify(ctx, request, member.HouseholdID)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return request.RequestID, nil
+}
+
+func (u *useCase) GetBorrowRequests(ctx context.Context, userID uuid.UUID, request dtos.ListBorrowRequestsRequest) ([]dtos.BorrowRequestResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := u.r.GetBorrowRequests(ctx, member.HouseholdID, request.Incoming)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.BorrowRequestResponse, len(requests))
+	for i, request := range requests {
+		response[i] = dtos.NewBorrowRequestResponse(request)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) AnswerBorrowRequest(ctx context.Context, userID uuid.UUID, requestID uuid.UUID, status entities.Status) error {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	request, err := u.r.GetBorrowRequest(ctx, member.HouseholdID, requestID)
+	if err != nil {
+		return mapNoRows(err, errors.ErrBorrowRequestNotFound)
+	}
+
+	// Each side may only make its own moves. The borrowing household's
+	// editors may cancel for the member who asked.
+	if status.ByLender() {
+		if request.LenderHouseholdID != member.HouseholdID || !member.Role.CanEditLibrary() {
+			return errors.ErrHouseholdForbidden
+		}
+	} else if request.BorrowerHouseholdID != member.HouseholdID || (request.RequestedBy != userID && !member.Role.CanEditLibrary()) {
+		return errors.ErrHouseholdForbidden
+	}
+
+	if !request.CanMoveTo(status) {
+		return errors.ErrBorrowTransition
+	}
+
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		switch status {
+		case entities.StatusHandedOver:
+			loanID, err := u.handOver(ctx, request, userID)
+			if err != nil {
+				return err
+			}
+			request.LoanID = &loanID
+		case entities.StatusReturned:
+			if request.LoanID != nil {
+				err := u.r.ReturnLoan(ctx, request.LenderHouseholdID, *request.LoanID, time.Now())
+				if err != nil && !stdErrors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+			}
+		}
+
+		return u.move(ctx, request, status, member.HouseholdID)
+	})
+}
+
+// handOver lends the requested copy to the member who asked for it.
+func (u *useCase) handOver(ctx context.Context, request *entities.BorrowRequest, userID uuid.UUID) (uuid.UUID, error) {
+	name, err := u.borrowerName(ctx, request)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	loan := entities.NewLoan(request.LenderHouseholdID, request.CopyID, userID, name)
+	loan.BorrowerHouseholdID = &request.BorrowerHouseholdID
+	loan.BorrowerUserID = &request.RequestedBy
+	loan.DueAt = request.DueAt
+
+	return u.r.CreateLoan(ctx, loan)
+}
+
+// borrowerName is the name of the member who asked, or of their household
+// if they have left it since.
+func (u *useCase) borrowerName(ctx context.Context, request *entities.BorrowRequest) (string, error) {
+	borrower, err := u.households.GetMember(ctx, request.BorrowerHouseholdID, request.RequestedBy)
+	if err == nil {
+		if name := strings.TrimSpace(borrower.FirstName + " " + borrower.LastName); name != "" {
+			return name, nil
+		}
+	} else if !stdErrors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+
+	household, err := u.households.GetHousehold(ctx, request.BorrowerHouseholdID)
+	if err != nil {
+		return "", err
+	}
+	return household.Name, nil
+}
+
+// move saves the request in its new status, unless another answer got there
+// first, and tells the other side.
+func (u *useCase) move(ctx context.Context, request *entities.BorrowRequest, status entities.Status, byID uuid.UUID) error {
+	from := request.Status
+	request.Status = status
+	request.UpdatedAt = time.Now()
+
+	if err := u.r.UpdateBorrowRequest(ctx, request, from); err != nil {
+		return mapNoRows(err, errors.ErrBorrowTransition)
+	}
+
+	return u.notify(ctx, request, byID)
+}
+
+// notify tells the member who asked about the lender's answers, and the
+// lending household's editors about new and cancelled requests.
+func (u *useCase) notify(ctx context.Context, request *entities.BorrowRequest, byID uuid.UUID) error {
+	by, err := u.households.GetHousehold(ctx, byID)
+	if err != nil {
+		return err
+	}
+
+	recipients := []uuid.UUID{request.RequestedBy}
+	if !request.Status.ByLender() {
+		members, err := u.households.GetMembers(ctx, request.LenderHouseholdID)
+		if err != nil {
+			return err
+		}
+
+		recipients = recipients[:0]
+		for _, m := range members {
+			if m.Role.CanEditLibrary() {
+				recipients = append(recipients, m.UserID)
+			}
+		}
+	}
+
+	notice := Notice{
+		RequestID:   request.RequestID,
+		Title:       request.Title,
+		Status:      request.Status,
+		HouseholdID: by.HouseholdID,
+		Name:        by.Name,
+	}
+
+	return u.notifier.Notify(ctx, recipients, NotificationPrefix+string(request.Status), notice)
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+// editor returns the caller's membership if their role may change the
+// household's catalog, loans included.
+func (u *useCase) editor(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.CanEditLibrary() {
+		return nil, errors.ErrHouseholdForbidden
+	}
+	return member, nil
+}
+
+func loanResponses(loans []entities.Loan) []dtos.LoanResponse {
+	response := make([]dtos.LoanResponse, len(loans))
+	for i, loan := range loans {
+		response[i] = dtos.NewLoanResponse(loan)
+	}
+	return response
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
@@ -0,0 +1,631 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	friendEntities "home-library/internal/services/friend/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/internal/services/loan/dtos"
+	"home-library/internal/services/loan/entities"
+	"home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetCopy(ctx context.Context, copyID uuid.UUID) (*entities.Copy, error) {
+	args := m.Called(ctx, copyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Copy), args.Error(1)
+}
+
+func (m *MockRepository) FindSharedBooks(ctx context.Context, householdID uuid.UUID, filter entities.SharedBookFilter) ([]entities.SharedBook, error) {
+	args := m.Called(ctx, householdID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.SharedBook), args.Error(1)
+}
+
+func (m *MockRepository) CreateLoan(ctx context.Context, loan *entities.Loan) (uuid.UUID, error) {
+	args := m.Called(ctx, loan)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetLoan(ctx context.Context, householdID uuid.UUID, loanID uuid.UUID) (*entities.Loan, error) {
+	args := m.Called(ctx, householdID, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Loan), args.Error(1)
+}
+
+func (m *MockRepository) GetLoans(ctx context.Context, householdID uuid.UUID, all bool) ([]entities.Loan, error) {
+	args := m.Called(ctx, householdID, all)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Loan), args.Error(1)
+}
+
+func (m *MockRepository) GetBorrowedLoans(ctx context.Context, householdID uuid.UUID) ([]entities.Loan, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Loan), args.Error(1)
+}
+
+func (m *MockRepository) ReturnLoan(ctx context.Context, householdID uuid.UUID, loanID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, loanID, now).Error(0)
+}
+
+func (m *MockRepository) CreateBorrowRequest(ctx context.Context, request *entities.BorrowRequest) error {
+	return m.Called(ctx, request).Error(0)
+}
+
+func (m *MockRepository) GetBorrowRequest(ctx context.Context, householdID uuid.UUID, requestID uuid.UUID) (*entities.BorrowRequest, error) {
+	args := m.Called(ctx, householdID, requestID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.BorrowRequest), args.Error(1)
+}
+
+func (m *MockRepository) GetBorrowRequestByLoan(ctx context.Context, householdID uuid.UUID, loanID uuid.UUID) (*entities.BorrowRequest, error) {
+	args := m.Called(ctx, householdID, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.BorrowRequest), args.Error(1)
+}
+
+func (m *MockRepository) GetBorrowRequests(ctx context.Context, householdID uuid.UUID, incoming bool) ([]entities.BorrowRequest, error) {
+	args := m.Called(ctx, householdID, incoming)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.BorrowRequest), args.Error(1)
+}
+
+func (m *MockRepository) UpdateBorrowRequest(ctx context.Context, request *entities.BorrowRequest, from entities.Status) error {
+	return m.Called(ctx, request, from).Error(0)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+type MockFriendRepository struct {
+	mock.Mock
+}
+
+func (m *MockFriendRepository) FindHouseholdByEmail(ctx context.Context, email string) (uuid.UUID, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockFriendRepository) CreateFriendship(ctx context.Context, friendship *friendEntities.Friendship) error {
+	return m.Called(ctx, friendship).Error(0)
+}
+
+func (m *MockFriendRepository) GetFriends(ctx context.Context, householdID uuid.UUID) ([]friendEntities.Friend, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]friendEntities.Friend), args.Error(1)
+}
+
+func (m *MockFriendRepository) AcceptFriendship(ctx context.Context, householdID uuid.UUID, requesterID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, requesterID, now).Error(0)
+}
+
+func (m *MockFriendRepository) DeleteFriendship(ctx context.Context, householdID uuid.UUID, otherID uuid.UUID) error {
+	return m.Called(ctx, householdID, otherID).Error(0)
+}
+
+func (m *MockFriendRepository) AreFriends(ctx context.Context, householdID uuid.UUID, otherID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, householdID, otherID)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, userIDs []uuid.UUID, typ string, payload any) error {
+	return m.Called(ctx, userIDs, typ, payload).Error(0)
+}
+
+// passthroughTx runs the unit of work directly.
+type passthroughTx struct{}
+
+func (passthroughTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	households *MockHouseholdRepository
+	friends    *MockFriendRepository
+	notifier   *MockNotifier
+}
+
+func newUseCase() (UseCase, mocks) {
+	m := mocks{new(MockRepository), new(MockHouseholdRepository), new(MockFriendRepository), new(MockNotifier)}
+	return NewUseCase(m.repo, m.households, m.friends, m.notifier, passthroughTx{}), m
+}
+
+func (m mocks) member(userID uuid.UUID, householdID uuid.UUID, role householdEntities.Role) {
+	m.households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: role}, nil)
+}
+
+func (m mocks) household(householdID uuid.UUID, name string) {
+	m.households.On("GetHousehold", mock.Anything, householdID).
+		Return(&householdEntities.Household{HouseholdID: householdID, Name: name}, nil)
+}
+
+func TestLendCopy(t *testing.T) {
+	t.Run("copy of the household is lent by name", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, copyID, loanID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		due := time.Now().Add(14 * 24 * time.Hour)
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetCopy", mock.Anything, copyID).Return(&entities.Copy{CopyID: copyID, HouseholdID: householdID}, nil)
+		m.repo.On("CreateLoan", mock.Anything, mock.MatchedBy(func(l *entities.Loan) bool {
+			return l.HouseholdID == householdID && l.CopyID == copyID && l.LentBy == userID &&
+				l.BorrowerName == "Соседка Оля" && l.DueAt == &due && l.BorrowerUserID == nil
+		})).Return(loanID, nil)
+
+		id, err := u.LendCopy(context.Background(), userID, copyID, dtos.LendCopyRequest{BorrowerName: " Соседка Оля ", DueAt: &due})
+
+		assert.NoError(t, err)
+		assert.Equal(t, loanID, id)
+	})
+
+	t.Run("copy of another household is not found", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, copyID := uuid.New(), uuid.New()
+		m.member(userID, uuid.New(), householdEntities.RoleOwner)
+
+		m.repo.On("GetCopy", mock.Anything, copyID).Return(&entities.Copy{CopyID: copyID, HouseholdID: uuid.New()}, nil)
+
+		_, err := u.LendCopy(context.Background(), userID, copyID, dtos.LendCopyRequest{BorrowerName: "Оля"})
+
+		assert.ErrorIs(t, err, errors.ErrCopyNotFound)
+		m.repo.AssertNotCalled(t, "CreateLoan", mock.Anything, mock.Anything)
+	})
+
+	t.Run("viewers cannot lend", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+		m.member(userID, uuid.New(), householdEntities.RoleViewer)
+
+		_, err := u.LendCopy(context.Background(), userID, uuid.New(), dtos.LendCopyRequest{BorrowerName: "Оля"})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+	})
+}
+
+func TestReturnLoan(t *testing.T) {
+	t.Run("loan without a request is returned", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, loanID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("ReturnLoan", mock.Anything, householdID, loanID, mock.Anything).Return(nil)
+		m.repo.On("GetBorrowRequestByLoan", mock.Anything, householdID, loanID).Return(nil, sql.ErrNoRows)
+
+		err := u.ReturnLoan(context.Background(), userID, loanID)
+
+		assert.NoError(t, err)
+		m.notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("request of the loan is returned and the borrower told", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, loanID, borrowerID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+		m.household(householdID, "Ивановы")
+		request := &entities.BorrowRequest{
+			RequestID:         uuid.New(),
+			LenderHouseholdID: householdID,
+			RequestedBy:       borrowerID,
+			Status:            entities.StatusHandedOver,
+			LoanID:            &loanID,
+			Title:             "Солярис",
+		}
+
+		m.repo.On("ReturnLoan", mock.Anything, householdID, loanID, mock.Anything).Return(nil)
+		m.repo.On("GetBorrowRequestByLoan", mock.Anything, householdID, loanID).Return(request, nil)
+		m.repo.On("UpdateBorrowRequest", mock.Anything, request, entities.StatusHandedOver).Return(nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{borrowerID}, "borrow_request.returned", Notice{
+			RequestID:   request.RequestID,
+			Title:       "Солярис",
+			Status:      entities.StatusReturned,
+			HouseholdID: householdID,
+			Name:        "Ивановы",
+		}).Return(nil)
+
+		err := u.ReturnLoan(context.Background(), userID, loanID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.StatusReturned, request.Status)
+		m.notifier.AssertExpectations(t)
+	})
+
+	t.Run("loan already returned", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, loanID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("ReturnLoan", mock.Anything, householdID, loanID, mock.Anything).Return(sql.ErrNoRows)
+
+		err := u.ReturnLoan(context.Background(), userID, loanID)
+
+		assert.ErrorIs(t, err, errors.ErrLoanNotFound)
+	})
+}
+
+func TestGetFriendBooks(t *testing.T) {
+	t.Run("catalog of a friend gets the default page size", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, friendID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleViewer)
+
+		m.friends.On("AreFriends", mock.Anything, householdID, friendID).Return(true, nil)
+		m.repo.On("FindSharedBooks", mock.Anything, friendID, entities.SharedBookFilter{Query: "лем", Limit: defaultLimit}).
+			Return([]entities.SharedBook{{BookID: uuid.New(), Title: "Солярис", Copies: 1}}, nil)
+
+		books, err := u.GetFriendBooks(context.Background(), userID, friendID, dtos.ListSharedBooksRequest{Query: " лем "})
+
+		assert.NoError(t, err)
+		assert.Len(t, books, 1)
+		assert.Equal(t, []string{}, books[0].AvailableCopies)
+	})
+
+	t.Run("catalog of a stranger is hidden", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, otherID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		m.friends.On("AreFriends", mock.Anything, householdID, otherID).Return(false, nil)
+
+		_, err := u.GetFriendBooks(context.Background(), userID, otherID, dtos.ListSharedBooksRequest{})
+
+		assert.ErrorIs(t, err, errors.ErrFriendshipNotFound)
+		m.repo.AssertNotCalled(t, "FindSharedBooks", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRequestBorrow(t *testing.T) {
+	t.Run("request notifies the editors of the lender", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, lenderID, copyID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		ownerID, editorID, viewerID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleViewer)
+		m.household(householdID, "Петровы")
+
+		m.repo.On("GetCopy", mock.Anything, copyID).Return(&entities.Copy{CopyID: copyID, HouseholdID: lenderID, Title: "Солярис"}, nil)
+		m.friends.On("AreFriends", mock.Anything, householdID, lenderID).Return(true, nil)
+		m.repo.On("CreateBorrowRequest", mock.Anything, mock.MatchedBy(func(r *entities.BorrowRequest) bool {
+			return r.CopyID == copyID && r.LenderHouseholdID == lenderID && r.BorrowerHouseholdID == householdID &&
+				r.RequestedBy == userID && r.Status == entities.StatusRequested && r.Message == "На выходные"
+		})).Return(nil)
+		m.households.On("GetMembers", mock.Anything, lenderID).Return([]householdEntities.Member{
+			{UserID: ownerID, Role: householdEntities.RoleOwner},
+			{UserID: editorID, Role: householdEntities.RoleEditor},
+			{UserID: viewerID, Role: householdEntities.RoleViewer},
+		}, nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{ownerID, editorID}, "borrow_request.requested", mock.MatchedBy(func(n Notice) bool {
+			return n.Title == "Солярис" && n.HouseholdID == householdID && n.Name == "Петровы"
+		})).Return(nil)
+
+		id, err := u.RequestBorrow(context.Background(), userID, dtos.BorrowRequestRequest{CopyID: copyID, Message: " На выходные "})
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, id)
+		m.notifier.AssertExpectations(t)
+	})
+
+	t.Run("own copy cannot be requested", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, copyID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetCopy", mock.Anything, copyID).Return(&entities.Copy{CopyID: copyID, HouseholdID: householdID}, nil)
+
+		_, err := u.RequestBorrow(context.Background(), userID, dtos.BorrowRequestRequest{CopyID: copyID})
+
+		assert.ErrorIs(t, err, errors.ErrBorrowOwnCopy)
+	})
+
+	t.Run("copy of a stranger is not found", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, otherID, copyID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleEditor)
+
+		m.repo.On("GetCopy", mock.Anything, copyID).Return(&entities.Copy{CopyID: copyID, HouseholdID: otherID}, nil)
+		m.friends.On("AreFriends", mock.Anything, householdID, otherID).Return(false, nil)
+
+		_, err := u.RequestBorrow(context.Background(), userID, dtos.BorrowRequestRequest{CopyID: copyID})
+
+		assert.ErrorIs(t, err, errors.ErrCopyNotFound)
+		m.repo.AssertNotCalled(t, "CreateBorrowRequest", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAnswerBorrowRequest(t *testing.T) {
+	newRequest := func(lenderID, borrowerID, requestedBy uuid.UUID, status entities.Status) *entities.BorrowRequest {
+		return &entities.BorrowRequest{
+			RequestID:           uuid.New(),
+			CopyID:              uuid.New(),
+			LenderHouseholdID:   lenderID,
+			BorrowerHouseholdID: borrowerID,
+			RequestedBy:         requestedBy,
+			Status:              status,
+			Title:               "Солярис",
+		}
+	}
+
+	t.Run("lender approves and the borrower is told", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, lenderID, requesterID := uuid.New(), uuid.New(), uuid.New()
+		request := newRequest(lenderID, uuid.New(), requesterID, entities.StatusRequested)
+		m.member(userID, lenderID, householdEntities.RoleEditor)
+		m.household(lenderID, "Ивановы")
+
+		m.repo.On("GetBorrowRequest", mock.Anything, lenderID, request.RequestID).Return(request, nil)
+		m.repo.On("UpdateBorrowRequest", mock.Anything, request, entities.StatusRequested).Return(nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{requesterID}, "borrow_request.approved", mock.Anything).Return(nil)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, request.RequestID, entities.StatusApproved)
+
+		assert.NoError(t, err)
+		assert.Equal(t, entities.StatusApproved, request.Status)
+		m.notifier.AssertExpectations(t)
+	})
+
+	t.Run("hand-over lends the copy to the member who asked", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, lenderID, borrowerID, requesterID, loanID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		request := newRequest(lenderID, borrowerID, requesterID, entities.StatusApproved)
+		m.member(userID, lenderID, householdEntities.RoleOwner)
+		m.household(lenderID, "Ивановы")
+
+		m.repo.On("GetBorrowRequest", mock.Anything, lenderID, request.RequestID).Return(request, nil)
+		m.households.On("GetMember", mock.Anything, borrowerID, requesterID).
+			Return(&householdEntities.Member{FirstName: "Пётр", LastName: "Петров"}, nil)
+		m.repo.On("CreateLoan", mock.Anything, mock.MatchedBy(func(l *entities.Loan) bool {
+			return l.HouseholdID == lenderID && l.CopyID == request.CopyID && l.BorrowerName == "Пётр Петров" &&
+				*l.BorrowerHouseholdID == borrowerID && *l.BorrowerUserID == requesterID
+		})).Return(loanID, nil)
+		m.repo.On("UpdateBorrowRequest", mock.Anything, request, entities.StatusApproved).Return(nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{requesterID}, "borrow_request.handed_over", mock.Anything).Return(nil)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, request.RequestID, entities.StatusHandedOver)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &loanID, request.LoanID)
+		assert.Equal(t, entities.StatusHandedOver, request.Status)
+	})
+
+	t.Run("copy still on loan cannot be handed over", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, lenderID, borrowerID, requesterID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		request := newRequest(lenderID, borrowerID, requesterID, entities.StatusApproved)
+		m.member(userID, lenderID, householdEntities.RoleOwner)
+
+		m.repo.On("GetBorrowRequest", mock.Anything, lenderID, request.RequestID).Return(request, nil)
+		m.households.On("GetMember", mock.Anything, borrowerID, requesterID).
+			Return(&householdEntities.Member{FirstName: "Пётр"}, nil)
+		m.repo.On("CreateLoan", mock.Anything, mock.Anything).Return(uuid.Nil, errors.ErrCopyOnLoan)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, request.RequestID, entities.StatusHandedOver)
+
+		assert.ErrorIs(t, err, errors.ErrCopyOnLoan)
+		m.repo.AssertNotCalled(t, "UpdateBorrowRequest", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("returning the request returns its loan", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, lenderID, requesterID, loanID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		request := newRequest(lenderID, uuid.New(), requesterID, entities.StatusHandedOver)
+		request.LoanID = &loanID
+		m.member(userID, lenderID, householdEntities.RoleEditor)
+		m.household(lenderID, "Ивановы")
+
+		m.repo.On("GetBorrowRequest", mock.Anything, lenderID, request.RequestID).Return(request, nil)
+		m.repo.On("ReturnLoan", mock.Anything, lenderID, loanID, mock.Anything).Return(nil)
+		m.repo.On("UpdateBorrowRequest", mock.Anything, request, entities.StatusHandedOver).Return(nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{requesterID}, "borrow_request.returned", mock.Anything).Return(nil)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, request.RequestID, entities.StatusReturned)
+
+		assert.NoError(t, err)
+		m.repo.AssertExpectations(t)
+	})
+
+	t.Run("borrower cannot approve their own request", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, borrowerID := uuid.New(), uuid.New()
+		request := newRequest(uuid.New(), borrowerID, userID, entities.StatusRequested)
+		m.member(userID, borrowerID, householdEntities.RoleOwner)
+
+		m.repo.On("GetBorrowRequest", mock.Anything, borrowerID, request.RequestID).Return(request, nil)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, request.RequestID, entities.StatusApproved)
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+	})
+
+	t.Run("requester cancels and the lender editors are told", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, lenderID, borrowerID, editorID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		request := newRequest(lenderID, borrowerID, userID, entities.StatusApproved)
+		m.member(userID, borrowerID, householdEntities.RoleViewer)
+		m.household(borrowerID, "Петровы")
+
+		m.repo.On("GetBorrowRequest", mock.Anything, borrowerID, request.RequestID).Return(request, nil)
+		m.repo.On("UpdateBorrowRequest", mock.Anything, request, entities.StatusApproved).Return(nil)
+		m.households.On("GetMembers", mock.Anything, lenderID).
+			Return([]householdEntities.Member{{UserID: editorID, Role: householdEntities.RoleEditor}}, nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{editorID}, "borrow_request.cancelled", mock.Anything).Return(nil)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, request.RequestID, entities.StatusCancelled)
+
+		assert.NoError(t, err)
+		m.notifier.AssertExpectations(t)
+	})
+
+	t.Run("other viewers of the borrower cannot cancel", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, borrowerID := uuid.New(), uuid.New()
+		request := newRequest(uuid.New(), borrowerID, uuid.New(), entities.StatusRequested)
+		m.member(userID, borrowerID, householdEntities.RoleViewer)
+
+		m.repo.On("GetBorrowRequest", mock.Anything, borrowerID, request.RequestID).Return(request, nil)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, request.RequestID, entities.StatusCancelled)
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+	})
+
+	t.Run("declined request cannot be handed over", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, lenderID := uuid.New(), uuid.New()
+		request := newRequest(lenderID, uuid.New(), uuid.New(), entities.StatusDeclined)
+		m.member(userID, lenderID, householdEntities.RoleEditor)
+
+		m.repo.On("GetBorrowRequest", mock.Anything, lenderID, request.RequestID).Return(request, nil)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, request.RequestID, entities.StatusHandedOver)
+
+		assert.ErrorIs(t, err, errors.ErrBorrowTransition)
+		m.repo.AssertNotCalled(t, "CreateLoan", mock.Anything, mock.Anything)
+	})
+
+	t.Run("concurrent answer wins", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, lenderID := uuid.New(), uuid.New()
+		request := newRequest(lenderID, uuid.New(), uuid.New(), entities.StatusRequested)
+		m.member(userID, lenderID, householdEntities.RoleEditor)
+
+		m.repo.On("GetBorrowRequest", mock.Anything, lenderID, request.RequestID).Return(request, nil)
+		m.repo.On("UpdateBorrowRequest", mock.Anything, request, entities.StatusRequested).Return(sql.ErrNoRows)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, request.RequestID, entities.StatusDeclined)
+
+		assert.ErrorIs(t, err, errors.ErrBorrowTransition)
+	})
+
+	t.Run("request of other households is not found", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, requestID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleOwner)
+
+		m.repo.On("GetBorrowRequest", mock.Anything, householdID, requestID).Return(nil, sql.ErrNoRows)
+
+		err := u.AnswerBorrowRequest(context.Background(), userID, requestID, entities.StatusApproved)
+
+		assert.ErrorIs(t, err, errors.ErrBorrowRequestNotFound)
+	})
+}
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"context"
+	"home-library/internal/services/notification/dtos"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) Notify(ctx context.Context, userIDs []uuid.UUID, typ string, payload any) error {
+	return m.Called(ctx, userIDs, typ, payload).Error(0)
+}
+
+func (m *MockUseCase) GetNotifications(ctx context.Context, userID uuid.UUID, request dtos.ListNotificationsRequest) ([]dtos.NotificationResponse, error) {
+	args := m.Called(ctx, userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.NotificationResponse), args.Error(1)
+}
+
+func (m *MockUseCase) MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error {
+	return m.Called(ctx, userID, notificationID).Error(0)
+}
+
+func (m *MockUseCase) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
+	return m.Called(ctx, userID).Error(0)
+}
+
+func newContext(e *echo.Echo, method string, target string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestGetNotifications(t *testing.T) {
+	e := echo.New()
+
+	t.Run("unread notifications", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+		c, rec := newContext(e, http.MethodGet, "/notifications?unread=true&limit=10", "", userID)
+
+		mockUseCase.On("GetNotifications", context.Background(), userID, dtos.ListNotificationsRequest{Unread: true, Limit: 10}).
+			Return([]dtos.NotificationResponse{}, nil)
+
+		err := handler.GetNotifications(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("limit too large", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		c, rec := newContext(e, http.MethodGet, "/notifications?limit=1000", "", uuid.New())
+
+		err := handler.GetNotifications(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		handler := NewHandler(new(MockUseCase))
+		c, rec := newContext(e, http.MethodGet, "/notifications", "", uuid.Nil)
+
+		err := handler.GetNotifications(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestMarkRead(t *testing.T) {
+	e := echo.New()
+
+	t.Run("notification of another user", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, notificationID := uuid.New(), uuid.New()
+		c, rec := newContext(e, http.MethodPost, "/notifications/x/read", "", userID)
+		c.SetParamNames("notification_id")
+		c.SetParamValues(notificationID.String())
+
+		mockUseCase.On("MarkRead", context.Background(), userID, notificationID).Return(customErrors.ErrNotificationNotFound)
+
+		err := handler.MarkRead(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
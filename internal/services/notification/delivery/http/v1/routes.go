@@ -0,0 +1,9 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) NotificationRoutes(domain *echo.Group) {
+	domain.GET("/notifications", h.GetNotifications)
+	domain.POST("/notifications/read", h.MarkAllRead)
+	domain.POST("/notifications/:notification_id/read", h.MarkRead)
+}
@@ -0,0 +1,37 @@
+package dtos
+
+import (
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/notification/entities"
+	"time"
+)
+
+type ListNotificationsRequest struct {
+	Unread bool `query:"unread"`
+	Limit  int  `query:"limit" validate:"gte=0,lte=100"`
+}
+
+func (r *ListNotificationsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type NotificationResponse struct {
+	NotificationID uuid.UUID       `json:"notification_id"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"payload"`
+	Read           bool            `json:"read"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func NewNotificationResponse(notification entities.Notification) NotificationResponse {
+	return NotificationResponse{
+		NotificationID: notification.NotificationID,
+		Type:           notification.Type,
+		Payload:        notification.Payload,
+		Read:           notification.ReadAt != nil,
+		CreatedAt:      notification.CreatedAt,
+	}
+}
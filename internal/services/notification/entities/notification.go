@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification tells a user about something that happened to them, such as
+// an answer to their borrow request. Type names the event and Payload holds
+// what a client needs to show it.
+type Notification struct {
+	NotificationID uuid.UUID       `db:"notification_id"`
+	UserID         uuid.UUID       `db:"user_id"`
+	Type           string          `db:"type"`
+	Payload        json.RawMessage `db:"payload"`
+	ReadAt         *time.Time      `db:"read_at"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+func NewNotification(userID uuid.UUID, typ string, payload any) (*Notification, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notification{
+		NotificationID: uuid.New(),
+		UserID:         userID,
+		Type:           typ,
+		Payload:        data,
+		CreatedAt:      time.Now(),
+	}, nil
+}
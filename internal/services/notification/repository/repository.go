@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/notification/entities"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	// CreateNotifications stores the notifications at once, joining the
+	// transaction of the change they are about if there is one.
+	CreateNotifications(ctx context.Context, notifications []entities.Notification) error
+	GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]entities.Notification, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID, now time.Time) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID, now time.Time) error
+	DeleteReadBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateNotifications(ctx context.Context, notifications []entities.Notification) error {
+	return r.withTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO notifications (notification_id, user_id, type, payload, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		for _, n := range notifications {
+			if _, err := tx.ExecContext(ctx, query, n.NotificationID, n.UserID, n.Type, string(n.Payload), n.CreatedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *repository) GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]entities.Notification, error) {
+	notifications := make([]entities.Notification, 0)
+	query := `
+		SELECT * FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	err := r.db.SelectContext(ctx, &notifications, query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *repository) MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, $3)
+		WHERE notification_id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, notificationID, userID, now)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) MarkAllRead(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE notifications
+		SET read_at = $2
+		WHERE user_id = $1 AND read_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID, now)
+	return err
+}
+
+func (r *repository) DeleteReadBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM notifications WHERE read_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *repository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.db.InTx(ctx, nil, fn)
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/notification/entities"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestCreateNotifications(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("notifications are saved together", func(t *testing.T) {
+		first, _ := entities.NewNotification(uuid.New(), "friendship.accepted", map[string]string{"name": "Ивановы"})
+		second, _ := entities.NewNotification(uuid.New(), "friendship.accepted", map[string]string{"name": "Ивановы"})
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(first.NotificationID, first.UserID, first.Type, `{"name":"Ивановы"}`, first.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO notifications").
+			WithArgs(second.NotificationID, second.UserID, second.Type, `{"name":"Ивановы"}`, second.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.CreateNotifications(context.Background(), []entities.Notification{*first, *second})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,93 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/notification/dtos"
+	"home-library/internal/services/notification/entities"
+	"home-library/internal/services/notification/repository"
+	"home-library/pkg/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultLimit is how many notifications are listed when the request does
+// not say.
+const defaultLimit = 50
+
+type UseCase interface {
+	// Notify tells each of the users about an event. Called inside a
+	// transaction, the notifications are only kept if it commits.
+	Notify(ctx context.Context, userIDs []uuid.UUID, typ string, payload any) error
+
+	GetNotifications(ctx context.Context, userID uuid.UUID, request dtos.ListNotificationsRequest) ([]dtos.NotificationResponse, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error
+	MarkAllRead(ctx context.Context, userID uuid.UUID) error
+}
+
+type useCase struct {
+	r repository.Repository
+}
+
+func NewUseCase(r repository.Repository) UseCase {
+	return &useCase{r: r}
+}
+
+func (u *useCase) Notify(ctx context.Context, userIDs []uuid.UUID, typ string, payload any) error {
+	notifications := make([]entities.Notification, 0, len(userIDs))
+	seen := make(map[uuid.UUID]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		notification, err := entities.NewNotification(userID, typ, payload)
+		if err != nil {
+			return err
+		}
+		notifications = append(notifications, *notification)
+	}
+
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	return u.r.CreateNotifications(ctx, notifications)
+}
+
+func (u *useCase) GetNotifications(ctx context.Context, userID uuid.UUID, request dtos.ListNotificationsRequest) ([]dtos.NotificationResponse, error) {
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	notifications, err := u.r.GetNotifications(ctx, userID, request.Unread, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		response[i] = dtos.NewNotificationResponse(notification)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID) error {
+	return mapNoRows(u.r.MarkRead(ctx, userID, notificationID, time.Now()), errors.ErrNotificationNotFound)
+}
+
+func (u *useCase) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
+	return u.r.MarkAllRead(ctx, userID, time.Now())
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/notification/dtos"
+	"home-library/internal/services/notification/entities"
+	"home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateNotifications(ctx context.Context, notifications []entities.Notification) error {
+	return m.Called(ctx, notifications).Error(0)
+}
+
+func (m *MockRepository) GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]entities.Notification, error) {
+	args := m.Called(ctx, userID, unreadOnly, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Notification), args.Error(1)
+}
+
+func (m *MockRepository) MarkRead(ctx context.Context, userID uuid.UUID, notificationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, userID, notificationID, now).Error(0)
+}
+
+func (m *MockRepository) MarkAllRead(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, userID, now).Error(0)
+}
+
+func (m *MockRepository) DeleteReadBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestNotify(t *testing.T) {
+	t.Run("each user is told once", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		u := NewUseCase(mockRepo)
+		first, second := uuid.New(), uuid.New()
+
+		mockRepo.On("CreateNotifications", mock.Anything, mock.MatchedBy(func(n []entities.Notification) bool {
+			return len(n) == 2 && n[0].UserID == first && n[1].UserID == second &&
+				n[0].Type == "friendship.requested" && string(n[0].Payload) == `{"name":"Ивановы"}`
+		})).Return(nil)
+
+		err := u.Notify(context.Background(), []uuid.UUID{first, second, first}, "friendship.requested", map[string]string{"name": "Ивановы"})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("nobody to tell", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		u := NewUseCase(mockRepo)
+
+		err := u.Notify(context.Background(), nil, "friendship.requested", nil)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "CreateNotifications", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetNotifications(t *testing.T) {
+	t.Run("default page size", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		u := NewUseCase(mockRepo)
+		userID, now := uuid.New(), time.Now()
+
+		mockRepo.On("GetNotifications", mock.Anything, userID, true, defaultLimit).Return([]entities.Notification{
+			{NotificationID: uuid.New(), Type: "borrow_request.approved", Payload: []byte(`{}`), ReadAt: &now},
+		}, nil)
+
+		notifications, err := u.GetNotifications(context.Background(), userID, dtos.ListNotificationsRequest{Unread: true})
+
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 1)
+		assert.True(t, notifications[0].Read)
+	})
+}
+
+func TestMarkRead(t *testing.T) {
+	t.Run("notification of another user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		u := NewUseCase(mockRepo)
+		userID, notificationID := uuid.New(), uuid.New()
+
+		mockRepo.On("MarkRead", mock.Anything, userID, notificationID, mock.Anything).Return(sql.ErrNoRows)
+
+		err := u.MarkRead(context.Background(), userID, notificationID)
+
+		assert.ErrorIs(t, err, errors.ErrNotificationNotFound)
+	})
+}
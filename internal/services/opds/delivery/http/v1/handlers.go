@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/ebook/entities"
+	"home-library/internal/services/opds/dtos"
+	"home-library/internal/services/opds/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"home-library/pkg/opds"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) Root(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	feed, err := h.u.Root(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to build opds root feed")
+	}
+
+	return h.render(c, opds.NavigationType, feed)
+}
+
+func (h *handler) Recent(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	feed, err := h.u.Recent(c.Request().Context(), userID, page(c))
+	if err != nil {
+		return h.handleError(c, err, "failed to build opds recent feed")
+	}
+
+	return h.render(c, opds.AcquisitionType, feed)
+}
+
+// Facet serves the list of values of the facet, or the books of one value
+// when it is given as the name query parameter.
+func (h *handler) Facet(facet entities.Facet) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, ok := jwt.UserIDFromContext(c)
+		if !ok {
+			return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+		}
+
+		name := c.QueryParam("name")
+		if name == "" {
+			feed, err := h.u.Facet(c.Request().Context(), userID, facet)
+			if err != nil {
+				return h.handleError(c, err, "failed to build opds facet feed")
+			}
+			return h.render(c, opds.NavigationType, feed)
+		}
+
+		feed, err := h.u.FacetFiles(c.Request().Context(), userID, facet, name, page(c))
+		if err != nil {
+			return h.handleError(c, err, "failed to build opds facet books feed")
+		}
+		return h.render(c, opds.AcquisitionType, feed)
+	}
+}
+
+func (h *handler) Search(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	query := c.QueryParam("q")
+	if query == "" {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Не задан поисковый запрос", nil))
+	}
+
+	feed, err := h.u.Search(c.Request().Context(), userID, query, page(c))
+	if err != nil {
+		return h.handleError(c, err, "failed to build opds search feed")
+	}
+
+	return h.render(c, opds.AcquisitionType, feed)
+}
+
+func (h *handler) SearchDescription(c echo.Context) error {
+	return h.render(c, opds.OpenSearchType, h.u.SearchDescription())
+}
+
+func (h *handler) DownloadFile(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	file, object, err := h.u.OpenFile(c.Request().Context(), userID, fileID)
+	if err != nil {
+		return h.handleError(c, err, "failed to open ebook")
+	}
+	defer object.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": file.DownloadName()}))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(object.Size, 10))
+
+	return c.Stream(http.StatusOK, file.Format.ContentType(), object)
+}
+
+func (h *handler) render(c echo.Context, contentType string, v interface{}) error {
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().WriteHeader(http.StatusOK)
+	return opds.Write(c.Response(), v)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrEbookNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Файл книги не найден", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
+
+func page(c echo.Context) int {
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
@@ -0,0 +1,192 @@
+package v1
+
+import (
+	"context"
+	"home-library/internal/services/ebook/entities"
+	"home-library/pkg/blobstore"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/opds"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) Root(ctx context.Context, userID uuid.UUID) (*opds.Feed, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*opds.Feed), args.Error(1)
+}
+
+func (m *MockUseCase) Recent(ctx context.Context, userID uuid.UUID, page int) (*opds.Feed, error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*opds.Feed), args.Error(1)
+}
+
+func (m *MockUseCase) Facet(ctx context.Context, userID uuid.UUID, facet entities.Facet) (*opds.Feed, error) {
+	args := m.Called(ctx, userID, facet)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*opds.Feed), args.Error(1)
+}
+
+func (m *MockUseCase) FacetFiles(ctx context.Context, userID uuid.UUID, facet entities.Facet, name string, page int) (*opds.Feed, error) {
+	args := m.Called(ctx, userID, facet, name, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*opds.Feed), args.Error(1)
+}
+
+func (m *MockUseCase) Search(ctx context.Context, userID uuid.UUID, query string, page int) (*opds.Feed, error) {
+	args := m.Called(ctx, userID, query, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*opds.Feed), args.Error(1)
+}
+
+func (m *MockUseCase) SearchDescription() *opds.OpenSearchDescription {
+	return m.Called().Get(0).(*opds.OpenSearchDescription)
+}
+
+func (m *MockUseCase) OpenFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) (*entities.EbookFile, *blobstore.Object, error) {
+	args := m.Called(ctx, userID, fileID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entities.EbookFile), args.Get(1).(*blobstore.Object), args.Error(2)
+}
+
+func newContext(e *echo.Echo, target string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestRoot(t *testing.T) {
+	e := echo.New()
+
+	t.Run("renders navigation feed", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Root", mock.Anything, userID).Return(opds.NewFeed("urn:home-library:opds", "Домашняя библиотека", time.Now()), nil)
+
+		c, rec := newContext(e, "/opds", userID)
+		err := h.Root(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, opds.NavigationType, rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Body.String(), "<title>Домашняя библиотека</title>")
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, "/opds", uuid.Nil)
+		err := h.Root(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestFacet(t *testing.T) {
+	e := echo.New()
+
+	t.Run("list of values", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Facet", mock.Anything, userID, entities.FacetTag).Return(opds.NewFeed("tags", "Жанры и теги", time.Now()), nil)
+
+		c, rec := newContext(e, "/opds/tags", userID)
+		err := h.Facet(entities.FacetTag)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, opds.NavigationType, rec.Header().Get(echo.HeaderContentType))
+	})
+
+	t.Run("books of a value", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("FacetFiles", mock.Anything, userID, entities.FacetAuthor, "Кир Булычёв", 2).Return(opds.NewFeed("author", "Кир Булычёв", time.Now()), nil)
+
+		c, rec := newContext(e, "/opds/authors?name=%D0%9A%D0%B8%D1%80+%D0%91%D1%83%D0%BB%D1%8B%D1%87%D1%91%D0%B2&page=2", userID)
+		err := h.Facet(entities.FacetAuthor)(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, opds.AcquisitionType, rec.Header().Get(echo.HeaderContentType))
+		mockUseCase.AssertExpectations(t)
+	})
+}
+
+func TestSearch(t *testing.T) {
+	e := echo.New()
+
+	t.Run("missing query", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, "/opds/search", uuid.New())
+		err := h.Search(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid page falls back to the first", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Search", mock.Anything, userID, "dune", 1).Return(opds.NewFeed("search", "Поиск: dune", time.Now()), nil)
+
+		c, rec := newContext(e, "/opds/search?q=dune&page=-3", userID)
+		err := h.Search(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestDownloadFile(t *testing.T) {
+	e := echo.New()
+	mockUseCase := new(MockUseCase)
+	h := NewHandler(mockUseCase)
+	userID, fileID := uuid.New(), uuid.New()
+
+	mockUseCase.On("OpenFile", mock.Anything, userID, fileID).Return(nil, nil, customErrors.ErrEbookNotFound)
+
+	c, rec := newContext(e, "/", userID)
+	c.SetParamNames("file_id")
+	c.SetParamValues(fileID.String())
+	err := h.DownloadFile(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
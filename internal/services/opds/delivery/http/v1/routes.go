@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"home-library/internal/services/ebook/entities"
+)
+
+// OpdsRoutes expects a group that already authenticates the user, e-readers
+// cannot go through the bearer token flow.
+func (h *handler) OpdsRoutes(opds *echo.Group) {
+	opds.GET("", h.Root)
+	opds.GET("/recent", h.Recent)
+	opds.GET("/authors", h.Facet(entities.FacetAuthor))
+	opds.GET("/series", h.Facet(entities.FacetSeries))
+	opds.GET("/tags", h.Facet(entities.FacetTag))
+	opds.GET("/search", h.Search)
+	opds.GET("/opensearch.xml", h.SearchDescription)
+	opds.GET("/books/:file_id/file", h.DownloadFile)
+}
@@ -0,0 +1,258 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	coverEntities "home-library/internal/services/cover/entities"
+	ebookEntities "home-library/internal/services/ebook/entities"
+	ebookRepository "home-library/internal/services/ebook/repository"
+	ebookUseCases "home-library/internal/services/ebook/usecases"
+	"home-library/pkg/blobstore"
+	"home-library/pkg/opds"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	basePath = "/api/v1/opds"
+	pageSize = 50
+)
+
+// facets describes the browsable groupings: where they live and how they
+// are titled.
+var facets = map[ebookEntities.Facet]struct {
+	path  string
+	title string
+}{
+	ebookEntities.FacetAuthor: {path: "/authors", title: "Авторы"},
+	ebookEntities.FacetSeries: {path: "/series", title: "Серии"},
+	ebookEntities.FacetTag:    {path: "/tags", title: "Жанры и теги"},
+}
+
+type UseCase interface {
+	Root(ctx context.Context, userID uuid.UUID) (*opds.Feed, error)
+	Recent(ctx context.Context, userID uuid.UUID, page int) (*opds.Feed, error)
+	Facet(ctx context.Context, userID uuid.UUID, facet ebookEntities.Facet) (*opds.Feed, error)
+	FacetFiles(ctx context.Context, userID uuid.UUID, facet ebookEntities.Facet, name string, page int) (*opds.Feed, error)
+	Search(ctx context.Context, userID uuid.UUID, query string, page int) (*opds.Feed, error)
+	SearchDescription() *opds.OpenSearchDescription
+	OpenFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) (*ebookEntities.EbookFile, *blobstore.Object, error)
+}
+
+type useCase struct {
+	r      ebookRepository.Repository
+	ebooks ebookUseCases.UseCase
+}
+
+func NewUseCase(r ebookRepository.Repository, ebooks ebookUseCases.UseCase) UseCase {
+	return &useCase{r: r, ebooks: ebooks}
+}
+
+func (u *useCase) Root(_ context.Context, _ uuid.UUID) (*opds.Feed, error) {
+	now := time.Now()
+	feed := newFeed("urn:home-library:opds", "Домашняя библиотека", basePath, opds.NavigationType, now)
+
+	feed.Entries = append(feed.Entries, opds.Entry{
+		ID:      "urn:home-library:opds:recent",
+		Title:   "Новые поступления",
+		Updated: now,
+		Content: &opds.Content{Type: "text", Text: "Недавно добавленные книги"},
+		Links:   []opds.Link{{Rel: opds.RelNew, Href: basePath + "/recent", Type: opds.AcquisitionType}},
+	})
+	for _, facet := range []ebookEntities.Facet{ebookEntities.FacetAuthor, ebookEntities.FacetSeries, ebookEntities.FacetTag} {
+		feed.Entries = append(feed.Entries, opds.Entry{
+			ID:      "urn:home-library:opds:" + string(facet),
+			Title:   facets[facet].title,
+			Updated: now,
+			Links:   []opds.Link{{Rel: opds.RelSubsection, Href: basePath + facets[facet].path, Type: opds.NavigationType}},
+		})
+	}
+
+	return feed, nil
+}
+
+func (u *useCase) Recent(ctx context.Context, userID uuid.UUID, page int) (*opds.Feed, error) {
+	href := basePath + "/recent"
+	return u.acquisition(ctx, userID, ebookEntities.Filter{Recent: true}, page,
+		"urn:home-library:opds:recent", "Новые поступления", href, basePath)
+}
+
+func (u *useCase) Facet(ctx context.Context, userID uuid.UUID, facet ebookEntities.Facet) (*opds.Feed, error) {
+	values, err := u.r.GetSharedFacet(ctx, userID, facet)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	href := basePath + facets[facet].path
+	feed := newFeed("urn:home-library:opds:"+string(facet), facets[facet].title, href, opds.NavigationType, now)
+	feed.Links = append(feed.Links, opds.Link{Rel: opds.RelUp, Href: basePath, Type: opds.NavigationType})
+
+	for _, value := range values {
+		feed.Entries = append(feed.Entries, opds.Entry{
+			ID:      "urn:home-library:opds:" + string(facet) + ":" + url.QueryEscape(value.Name),
+			Title:   value.Name,
+			Updated: now,
+			Content: &opds.Content{Type: "text", Text: booksCount(value.Count)},
+			Links:   []opds.Link{{Rel: opds.RelSubsection, Href: withQuery(href, "name", value.Name), Type: opds.AcquisitionType}},
+		})
+	}
+
+	return feed, nil
+}
+
+func (u *useCase) FacetFiles(ctx context.Context, userID uuid.UUID, facet ebookEntities.Facet, name string, page int) (*opds.Feed, error) {
+	var filter ebookEntities.Filter
+	switch facet {
+	case ebookEntities.FacetAuthor:
+		filter.Author = name
+	case ebookEntities.FacetSeries:
+		filter.Series = name
+	case ebookEntities.FacetTag:
+		filter.Tag = name
+	}
+
+	up := basePath + facets[facet].path
+	return u.acquisition(ctx, userID, filter, page,
+		"urn:home-library:opds:"+string(facet)+":"+url.QueryEscape(name), name, withQuery(up, "name", name), up)
+}
+
+func (u *useCase) Search(ctx context.Context, userID uuid.UUID, query string, page int) (*opds.Feed, error) {
+	return u.acquisition(ctx, userID, ebookEntities.Filter{Query: query}, page,
+		"urn:home-library:opds:search:"+url.QueryEscape(query), "Поиск: "+query, withQuery(basePath+"/search", "q", query), basePath)
+}
+
+func (u *useCase) SearchDescription() *opds.OpenSearchDescription {
+	return &opds.OpenSearchDescription{
+		ShortName:      "Библиотека",
+		Description:    "Поиск по названию и автору",
+		InputEncoding:  "UTF-8",
+		OutputEncoding: "UTF-8",
+		URLs: []opds.OpenSearchURL{
+			{Type: opds.AcquisitionType, Template: basePath + "/search?q={searchTerms}"},
+		},
+	}
+}
+
+func (u *useCase) OpenFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) (*ebookEntities.EbookFile, *blobstore.Object, error) {
+	return u.ebooks.OpenFile(ctx, userID, fileID)
+}
+
+// acquisition builds a paginated feed of the files matching filter. href is
+// the feed's own URL without the page and up the URL of its parent.
+func (u *useCase) acquisition(ctx context.Context, userID uuid.UUID, filter ebookEntities.Filter, page int, id string, title string, href string, up string) (*opds.Feed, error) {
+	if page < 1 {
+		page = 1
+	}
+	filter.Limit = pageSize + 1
+	filter.Offset = (page - 1) * pageSize
+
+	files, err := u.r.FindSharedFiles(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	hasNext := len(files) > pageSize
+	if hasNext {
+		files = files[:pageSize]
+	}
+
+	feed := newFeed(id, title, pageHref(href, page), opds.AcquisitionType, time.Now())
+	feed.Links = append(feed.Links, opds.Link{Rel: opds.RelUp, Href: up, Type: opds.NavigationType})
+	if page > 1 {
+		feed.Links = append(feed.Links, opds.Link{Rel: opds.RelPrevious, Href: pageHref(href, page-1), Type: opds.AcquisitionType})
+	}
+	if hasNext {
+		feed.Links = append(feed.Links, opds.Link{Rel: opds.RelNext, Href: pageHref(href, page+1), Type: opds.AcquisitionType})
+	}
+
+	for _, file := range files {
+		feed.Entries = append(feed.Entries, newEntry(file))
+	}
+
+	return feed, nil
+}
+
+func newFeed(id string, title string, self string, kind string, updated time.Time) *opds.Feed {
+	feed := opds.NewFeed(id, title, updated)
+	feed.Links = []opds.Link{
+		{Rel: opds.RelSelf, Href: self, Type: kind},
+		{Rel: opds.RelStart, Href: basePath, Type: opds.NavigationType},
+		{Rel: opds.RelSearch, Href: basePath + "/opensearch.xml", Type: opds.OpenSearchType},
+	}
+	return feed
+}
+
+func newEntry(file ebookEntities.EbookFile) opds.Entry {
+	entry := opds.Entry{
+		ID:       "urn:uuid:" + file.FileID.String(),
+		Title:    file.Title,
+		Updated:  file.UpdatedAt.UTC().Truncate(time.Second),
+		Language: file.Language,
+		Links: []opds.Link{{
+			Rel:  opds.RelAcquisition,
+			Href: fmt.Sprintf("%s/books/%s/file", basePath, file.FileID),
+			Type: file.Format.ContentType(),
+		}},
+	}
+
+	for _, author := range file.Authors {
+		entry.Authors = append(entry.Authors, opds.Person{
+			Name: author,
+			URI:  withQuery(basePath+facets[ebookEntities.FacetAuthor].path, "name", author),
+		})
+	}
+	for _, tag := range file.Tags {
+		entry.Categories = append(entry.Categories, opds.Category{Term: tag, Label: tag})
+	}
+	if file.ISBN != "" {
+		entry.Identifier = "urn:isbn:" + file.ISBN
+	}
+	if file.Description != "" {
+		entry.Content = &opds.Content{Type: "text", Text: file.Description}
+	}
+	if file.CoverID != nil {
+		entry.Links = append(entry.Links,
+			opds.Link{Rel: opds.RelImage, Href: coverHref(*file.CoverID, coverEntities.VariantLarge), Type: "image/jpeg"},
+			opds.Link{Rel: opds.RelThumbnail, Href: coverHref(*file.CoverID, coverEntities.VariantThumbnail), Type: "image/jpeg"},
+		)
+	}
+
+	return entry
+}
+
+func coverHref(coverID uuid.UUID, variant coverEntities.Variant) string {
+	return fmt.Sprintf("/api/v1/covers/%s/%s", coverID, variant)
+}
+
+func withQuery(href string, key string, value string) string {
+	return href + "?" + url.Values{key: {value}}.Encode()
+}
+
+func pageHref(href string, page int) string {
+	if page <= 1 {
+		return href
+	}
+
+	parsed, _ := url.Parse(href)
+	query := parsed.Query()
+	query.Set("page", strconv.Itoa(page))
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// booksCount renders a count of books with the Russian plural form.
+func booksCount(n int) string {
+	form := "книг"
+	switch {
+	case n%100 >= 11 && n%100 <= 14:
+	case n%10 == 1:
+		form = "книга"
+	case n%10 >= 2 && n%10 <= 4:
+		form = "книги"
+	}
+	return fmt.Sprintf("%d %s", n, form)
+}
@@ -0,0 +1,310 @@
+package usecases
+
+import (
+	"context"
+	"home-library/internal/services/ebook/dtos"
+	"home-library/internal/services/ebook/entities"
+	ebookUseCases "home-library/internal/services/ebook/usecases"
+	"home-library/pkg/blobstore"
+	"home-library/pkg/opds"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateFile(ctx context.Context, file *entities.EbookFile) (uuid.UUID, error) {
+	args := m.Called(ctx, file)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetFileByHash(ctx context.Context, ownerID uuid.UUID, sha256 string) (*entities.EbookFile, error) {
+	args := m.Called(ctx, ownerID, sha256)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) GetSharedFile(ctx context.Context, fileID uuid.UUID, viewerID uuid.UUID) (*entities.EbookFile, error) {
+	args := m.Called(ctx, fileID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) GetSharedFiles(ctx context.Context, viewerID uuid.UUID) ([]entities.EbookFile, error) {
+	args := m.Called(ctx, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) FindSharedFiles(ctx context.Context, viewerID uuid.UUID, filter entities.Filter) ([]entities.EbookFile, error) {
+	args := m.Called(ctx, viewerID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) GetSharedFacet(ctx context.Context, viewerID uuid.UUID, facet entities.Facet) ([]entities.FacetValue, error) {
+	args := m.Called(ctx, viewerID, facet)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.FacetValue), args.Error(1)
+}
+
+func (m *MockRepository) UpdateFile(ctx context.Context, file *entities.EbookFile) error {
+	return m.Called(ctx, file).Error(0)
+}
+
+func (m *MockRepository) HasBook(ctx context.Context, ownerID uuid.UUID, bookID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, ownerID, bookID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) SetBook(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID, bookID *uuid.UUID) error {
+	return m.Called(ctx, fileID, ownerID, bookID).Error(0)
+}
+
+func (m *MockRepository) DeleteFile(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID) (*entities.EbookFile, error) {
+	args := m.Called(ctx, fileID, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EbookFile), args.Error(1)
+}
+
+func (m *MockRepository) HashInUse(ctx context.Context, sha256 string) (bool, error) {
+	args := m.Called(ctx, sha256)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockEbookUseCase struct {
+	mock.Mock
+}
+
+func (m *MockEbookUseCase) UploadFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (*dtos.EbookResponse, bool, error) {
+	args := m.Called(ctx, userID, name, r, size)
+	return nil, false, args.Error(2)
+}
+
+func (m *MockEbookUseCase) StageFile(ctx context.Context, userID uuid.UUID, name string, r io.ReaderAt, size int64) (*ebookUseCases.StagedFile, error) {
+	data, _ := io.ReadAll(io.NewSectionReader(r, 0, size))
+	args := m.Called(ctx, userID, name, string(data))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ebookUseCases.StagedFile), args.Error(1)
+}
+
+func (m *MockEbookUseCase) SaveFile(ctx context.Context, staged *ebookUseCases.StagedFile) (*dtos.EbookResponse, bool, error) {
+	args := m.Called(ctx, staged)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*dtos.EbookResponse), args.Bool(1), args.Error(2)
+}
+
+func (m *MockEbookUseCase) DiscardFile(ctx context.Context, staged *ebookUseCases.StagedFile) {
+	m.Called(ctx, staged)
+}
+
+func (m *MockEbookUseCase) GetFiles(ctx context.Context, viewerID uuid.UUID) ([]dtos.EbookResponse, error) {
+	args := m.Called(ctx, viewerID)
+	return nil, args.Error(1)
+}
+
+func (m *MockEbookUseCase) GetFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*dtos.EbookResponse, error) {
+	args := m.Called(ctx, viewerID, fileID)
+	return nil, args.Error(1)
+}
+
+func (m *MockEbookUseCase) UpdateFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload dtos.UpdateEbookRequest) error {
+	return m.Called(ctx, userID, fileID, payload).Error(0)
+}
+
+func (m *MockEbookUseCase) AttachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID, payload dtos.AttachEbookRequest) error {
+	return m.Called(ctx, userID, fileID, payload).Error(0)
+}
+
+func (m *MockEbookUseCase) DetachFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	return m.Called(ctx, userID, fileID).Error(0)
+}
+
+func (m *MockEbookUseCase) DeleteFile(ctx context.Context, userID uuid.UUID, fileID uuid.UUID) error {
+	return m.Called(ctx, userID, fileID).Error(0)
+}
+
+func (m *MockEbookUseCase) OpenFile(ctx context.Context, viewerID uuid.UUID, fileID uuid.UUID) (*entities.EbookFile, *blobstore.Object, error) {
+	args := m.Called(ctx, viewerID, fileID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*entities.EbookFile), args.Get(1).(*blobstore.Object), args.Error(2)
+}
+
+func files(n int) []entities.EbookFile {
+	result := make([]entities.EbookFile, n)
+	for i := range result {
+		result[i] = *entities.NewEbookFile(uuid.New(), strings.Repeat("a", 64), entities.FormatEPUB, 1)
+	}
+	return result
+}
+
+func findLink(links []opds.Link, rel string) *opds.Link {
+	for i := range links {
+		if links[i].Rel == rel {
+			return &links[i]
+		}
+	}
+	return nil
+}
+
+func TestRoot(t *testing.T) {
+	useCase := NewUseCase(new(MockRepository), new(MockEbookUseCase))
+
+	feed, err := useCase.Root(context.Background(), uuid.New())
+	require.NoError(t, err)
+
+	assert.Equal(t, "/api/v1/opds", findLink(feed.Links, opds.RelSelf).Href)
+	assert.Equal(t, opds.OpenSearchType, findLink(feed.Links, opds.RelSearch).Type)
+	require.Len(t, feed.Entries, 4)
+	assert.Equal(t, opds.RelNew, feed.Entries[0].Links[0].Rel)
+	assert.Equal(t, "/api/v1/opds/authors", feed.Entries[1].Links[0].Href)
+	assert.Equal(t, opds.NavigationType, feed.Entries[1].Links[0].Type)
+}
+
+func TestRecent(t *testing.T) {
+	t.Run("first page with more to come", func(t *testing.T) {
+		repo := new(MockRepository)
+		useCase := NewUseCase(repo, new(MockEbookUseCase))
+		userID := uuid.New()
+
+		repo.On("FindSharedFiles", mock.Anything, userID, entities.Filter{Recent: true, Limit: 51}).Return(files(51), nil)
+
+		feed, err := useCase.Recent(context.Background(), userID, 1)
+		require.NoError(t, err)
+
+		assert.Len(t, feed.Entries, 50)
+		assert.Equal(t, "/api/v1/opds/recent", findLink(feed.Links, opds.RelSelf).Href)
+		assert.Equal(t, "/api/v1/opds/recent?page=2", findLink(feed.Links, opds.RelNext).Href)
+		assert.Nil(t, findLink(feed.Links, opds.RelPrevious))
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		repo := new(MockRepository)
+		useCase := NewUseCase(repo, new(MockEbookUseCase))
+		userID := uuid.New()
+
+		repo.On("FindSharedFiles", mock.Anything, userID, entities.Filter{Recent: true, Limit: 51, Offset: 100}).Return(files(3), nil)
+
+		feed, err := useCase.Recent(context.Background(), userID, 3)
+		require.NoError(t, err)
+
+		assert.Len(t, feed.Entries, 3)
+		assert.Nil(t, findLink(feed.Links, opds.RelNext))
+		assert.Equal(t, "/api/v1/opds/recent?page=2", findLink(feed.Links, opds.RelPrevious).Href)
+	})
+}
+
+func TestFacet(t *testing.T) {
+	repo := new(MockRepository)
+	useCase := NewUseCase(repo, new(MockEbookUseCase))
+	userID := uuid.New()
+
+	repo.On("GetSharedFacet", mock.Anything, userID, entities.FacetAuthor).Return([]entities.FacetValue{
+		{Name: "Аркадий Стругацкий", Count: 21},
+		{Name: "Кир Булычёв", Count: 3},
+	}, nil)
+
+	feed, err := useCase.Facet(context.Background(), userID, entities.FacetAuthor)
+	require.NoError(t, err)
+
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, "Аркадий Стругацкий", feed.Entries[0].Title)
+	assert.Equal(t, "21 книга", feed.Entries[0].Content.Text)
+	assert.Equal(t, "/api/v1/opds/authors?name=%D0%9A%D0%B8%D1%80+%D0%91%D1%83%D0%BB%D1%8B%D1%87%D1%91%D0%B2", feed.Entries[1].Links[0].Href)
+	assert.Equal(t, opds.AcquisitionType, feed.Entries[1].Links[0].Type)
+}
+
+func TestFacetFiles(t *testing.T) {
+	repo := new(MockRepository)
+	useCase := NewUseCase(repo, new(MockEbookUseCase))
+	userID, coverID := uuid.New(), uuid.New()
+
+	file := entities.NewEbookFile(userID, strings.Repeat("b", 64), entities.FormatFB2, 1)
+	file.Title = "Трудно быть богом"
+	file.Authors = pq.StringArray{"Аркадий Стругацкий"}
+	file.Tags = pq.StringArray{"sf_social"}
+	file.ISBN = "9785170987658"
+	file.Description = "Румата Эсторский"
+	file.CoverID = &coverID
+
+	repo.On("FindSharedFiles", mock.Anything, userID, entities.Filter{Series: "Мир Полудня", Limit: 51}).Return([]entities.EbookFile{*file}, nil)
+
+	feed, err := useCase.FacetFiles(context.Background(), userID, entities.FacetSeries, "Мир Полудня", 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Мир Полудня", feed.Title)
+	assert.Equal(t, "/api/v1/opds/series", findLink(feed.Links, opds.RelUp).Href)
+
+	entry := feed.Entries[0]
+	assert.Equal(t, "urn:uuid:"+file.FileID.String(), entry.ID)
+	assert.Equal(t, "urn:isbn:9785170987658", entry.Identifier)
+	assert.Equal(t, "Аркадий Стругацкий", entry.Authors[0].Name)
+	assert.Equal(t, []opds.Category{{Term: "sf_social", Label: "sf_social"}}, entry.Categories)
+	assert.Equal(t, "Румата Эсторский", entry.Content.Text)
+
+	acquisition := findLink(entry.Links, opds.RelAcquisition)
+	assert.Equal(t, "/api/v1/opds/books/"+file.FileID.String()+"/file", acquisition.Href)
+	assert.Equal(t, "application/x-fictionbook+xml", acquisition.Type)
+	assert.Equal(t, "/api/v1/covers/"+coverID.String()+"/thumbnail", findLink(entry.Links, opds.RelThumbnail).Href)
+	assert.Equal(t, "/api/v1/covers/"+coverID.String()+"/large", findLink(entry.Links, opds.RelImage).Href)
+}
+
+func TestSearch(t *testing.T) {
+	repo := new(MockRepository)
+	useCase := NewUseCase(repo, new(MockEbookUseCase))
+	userID := uuid.New()
+
+	repo.On("FindSharedFiles", mock.Anything, userID, entities.Filter{Query: "пикник", Limit: 51, Offset: 50}).Return(files(1), nil)
+
+	feed, err := useCase.Search(context.Background(), userID, "пикник", 2)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/api/v1/opds/search?page=2&q=%D0%BF%D0%B8%D0%BA%D0%BD%D0%B8%D0%BA", findLink(feed.Links, opds.RelSelf).Href)
+	assert.Equal(t, "/api/v1/opds/search?q=%D0%BF%D0%B8%D0%BA%D0%BD%D0%B8%D0%BA", findLink(feed.Links, opds.RelPrevious).Href)
+}
+
+func TestBooksCount(t *testing.T) {
+	tests := map[int]string{
+		0:   "0 книг",
+		1:   "1 книга",
+		2:   "2 книги",
+		5:   "5 книг",
+		11:  "11 книг",
+		12:  "12 книг",
+		21:  "21 книга",
+		22:  "22 книги",
+		111: "111 книг",
+		101: "101 книга",
+	}
+
+	for n, expected := range tests {
+		assert.Equal(t, expected, booksCount(n))
+	}
+}
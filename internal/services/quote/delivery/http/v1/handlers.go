@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/quote/dtos"
+	"home-library/internal/services/quote/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) ImportQuotes(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Файл с цитатами не передан", nil))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to open uploaded highlights")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+	defer src.Close()
+
+	result, err := h.u.ImportQuotes(c.Request().Context(), userID, src)
+	if err != nil {
+		return h.handleError(c, err, "failed to import quotes")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *handler) GetQuotes(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var fileID *uuid.UUID
+	if value := c.QueryParam("file_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+		}
+		fileID = &id
+	}
+
+	quotes, err := h.u.GetQuotes(c.Request().Context(), userID, fileID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get quotes")
+	}
+
+	return c.JSON(http.StatusOK, quotes)
+}
+
+func (h *handler) DeleteQuote(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	quoteID, err := uuid.Parse(c.Param("quote_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.DeleteQuote(c.Request().Context(), userID, quoteID); err != nil {
+		return h.handleError(c, err, "failed to delete quote")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrQuoteNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Цитата не найдена", nil))
+	case errors.Is(err, customErrors.ErrQuotesTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, dtos.NewErrorResponse(http.StatusRequestEntityTooLarge, "Файл слишком большой", nil))
+	case errors.Is(err, customErrors.ErrQuotesMalformed):
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Не удалось разобрать файл с цитатами", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"home-library/internal/services/quote/dtos"
+	"home-library/internal/services/quote/entities"
+	customErrors "home-library/pkg/errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) ImportQuotes(ctx context.Context, userID uuid.UUID, r io.Reader) (*dtos.ImportQuotesResponse, error) {
+	data, _ := io.ReadAll(r)
+	args := m.Called(ctx, userID, string(data))
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.ImportQuotesResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetQuotes(ctx context.Context, userID uuid.UUID, fileID *uuid.UUID) ([]dtos.QuoteResponse, error) {
+	args := m.Called(ctx, userID, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.QuoteResponse), args.Error(1)
+}
+
+func (m *MockUseCase) DeleteQuote(ctx context.Context, userID uuid.UUID, quoteID uuid.UUID) error {
+	return m.Called(ctx, userID, quoteID).Error(0)
+}
+
+func newContext(e *echo.Echo, req *http.Request, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func uploadRequest(t *testing.T, field string, content string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(field, "My Clippings.txt")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/quotes/import", &body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestImportQuotes(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully import", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		expected := &dtos.ImportQuotesResponse{Source: entities.SourceKindle, Total: 3, Imported: 2, Duplicates: 1, Matched: 2}
+		mockUseCase.On("ImportQuotes", mock.Anything, userID, "clippings").Return(expected, nil)
+
+		c, rec := newContext(e, uploadRequest(t, "file", "clippings"), userID)
+		err := h.ImportQuotes(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response dtos.ImportQuotesResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, *expected, response)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, uploadRequest(t, "archive", "clippings"), uuid.New())
+		err := h.ImportQuotes(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{"too large", customErrors.ErrQuotesTooLarge, http.StatusRequestEntityTooLarge},
+		{"malformed", customErrors.ErrQuotesMalformed, http.StatusBadRequest},
+		{"internal error", errors.New("database is down"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			h := NewHandler(mockUseCase)
+			userID := uuid.New()
+
+			mockUseCase.On("ImportQuotes", mock.Anything, userID, "clippings").Return(nil, test.err)
+
+			c, rec := newContext(e, uploadRequest(t, "file", "clippings"), userID)
+			err := h.ImportQuotes(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestGetQuotes(t *testing.T) {
+	e := echo.New()
+
+	t.Run("filter by file", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, fileID := uuid.New(), uuid.New()
+
+		mockUseCase.On("GetQuotes", mock.Anything, userID, &fileID).Return([]dtos.QuoteResponse{{QuoteID: uuid.New(), Text: "Человеку нужен человек"}}, nil)
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/quotes?file_id="+fileID.String(), nil), userID)
+		err := h.GetQuotes(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Человеку нужен человек")
+	})
+
+	t.Run("all quotes", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("GetQuotes", mock.Anything, userID, (*uuid.UUID)(nil)).Return([]dtos.QuoteResponse{}, nil)
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/quotes", nil), userID)
+		err := h.GetQuotes(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, "[]", rec.Body.String())
+	})
+
+	t.Run("invalid file id", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/quotes?file_id=42", nil), uuid.New())
+		err := h.GetQuotes(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, httptest.NewRequest(http.MethodGet, "/quotes", nil), uuid.Nil)
+		err := h.GetQuotes(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestDeleteQuote(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{"deleted", nil, http.StatusNoContent},
+		{"not found", customErrors.ErrQuoteNotFound, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			h := NewHandler(mockUseCase)
+			userID, quoteID := uuid.New(), uuid.New()
+
+			mockUseCase.On("DeleteQuote", mock.Anything, userID, quoteID).Return(test.err)
+
+			c, rec := newContext(e, httptest.NewRequest(http.MethodDelete, "/", nil), userID)
+			c.SetParamNames("quote_id")
+			c.SetParamValues(quoteID.String())
+			err := h.DeleteQuote(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func (h *handler) QuoteRoutes(domain *echo.Group) {
+	// The limit leaves room for the multipart envelope around the file.
+	domain.POST("/quotes/import", h.ImportQuotes, middleware.BodyLimit("21M"))
+	domain.GET("/quotes", h.GetQuotes)
+	domain.DELETE("/quotes/:quote_id", h.DeleteQuote)
+}
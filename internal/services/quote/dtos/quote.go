@@ -0,0 +1,54 @@
+package dtos
+
+import (
+	"github.com/google/uuid"
+	"home-library/internal/services/quote/entities"
+	"time"
+)
+
+type QuoteResponse struct {
+	QuoteID       uuid.UUID       `json:"quote_id"`
+	BookID        *uuid.UUID      `json:"book_id"`
+	FileID        *uuid.UUID      `json:"file_id"`
+	Source        entities.Source `json:"source"`
+	Kind          entities.Kind   `json:"kind"`
+	BookTitle     string          `json:"book_title"`
+	BookAuthor    string          `json:"book_author"`
+	Text          string          `json:"text"`
+	Note          string          `json:"note"`
+	Chapter       string          `json:"chapter"`
+	Location      string          `json:"location"`
+	Page          int             `json:"page,omitempty"`
+	HighlightedAt *time.Time      `json:"highlighted_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func NewQuoteResponse(quote entities.Quote) QuoteResponse {
+	return QuoteResponse{
+		QuoteID:       quote.QuoteID,
+		BookID:        quote.BookID,
+		FileID:        quote.FileID,
+		Source:        quote.Source,
+		Kind:          quote.Kind,
+		BookTitle:     quote.BookTitle,
+		BookAuthor:    quote.BookAuthor,
+		Text:          quote.Text,
+		Note:          quote.Note,
+		Chapter:       quote.Chapter,
+		Location:      quote.Location,
+		Page:          quote.Page,
+		HighlightedAt: quote.HighlightedAt,
+		CreatedAt:     quote.CreatedAt,
+	}
+}
+
+// ImportQuotesResponse summarizes an import. Duplicates are quotes the user
+// already had from an earlier import of the same device file; Matched counts
+// quotes that were linked to a book of the catalog or an ebook file.
+type ImportQuotesResponse struct {
+	Source     entities.Source `json:"source"`
+	Total      int             `json:"total"`
+	Imported   int             `json:"imported"`
+	Duplicates int             `json:"duplicates"`
+	Matched    int             `json:"matched"`
+}
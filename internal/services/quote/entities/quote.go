@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type Source string
+
+const (
+	SourceKindle   Source = "kindle"
+	SourceKOReader Source = "koreader"
+)
+
+type Kind string
+
+const (
+	KindHighlight Kind = "highlight"
+	KindNote      Kind = "note"
+)
+
+// Quote is a highlight or note a user made on an e-reader. BookID and FileID
+// link it to the book of the catalog and the ebook file it was matched to on
+// import, if any.
+type Quote struct {
+	QuoteID       uuid.UUID  `db:"quote_id"`
+	UserID        uuid.UUID  `db:"user_id"`
+	BookID        *uuid.UUID `db:"book_id"`
+	FileID        *uuid.UUID `db:"file_id"`
+	Source        Source     `db:"source"`
+	Kind          Kind       `db:"kind"`
+	BookTitle     string     `db:"book_title"`
+	BookAuthor    string     `db:"book_author"`
+	Text          string     `db:"text"`
+	Note          string     `db:"note"`
+	Chapter       string     `db:"chapter"`
+	Location      string     `db:"location"`
+	Page          int        `db:"page"`
+	HighlightedAt *time.Time `db:"highlighted_at"`
+	Fingerprint   string     `db:"fingerprint"`
+	CreatedAt     time.Time  `db:"created_at"`
+}
+
+// Book is a book of the user's household catalog, as far as matching
+// highlights to it goes.
+type Book struct {
+	BookID  uuid.UUID      `db:"book_id"`
+	Title   string         `db:"title"`
+	Authors pq.StringArray `db:"authors"`
+}
+
+func NewQuote(userID uuid.UUID, source Source, kind Kind) *Quote {
+	return &Quote{
+		QuoteID:   uuid.New(),
+		UserID:    userID,
+		Source:    source,
+		Kind:      kind,
+		CreatedAt: time.Now(),
+	}
+}
+
+// ComputeFingerprint identifies the quote across imports of the same file.
+// It deliberately leaves out the note and the timestamp, which e-readers
+// change when a note is edited, and ignores differences in whitespace.
+func (q *Quote) ComputeFingerprint() string {
+	hash := sha256.New()
+	for _, part := range []string{
+		string(q.Source),
+		string(q.Kind),
+		q.BookTitle,
+		q.BookAuthor,
+		q.Location,
+		strconv.Itoa(q.Page),
+		q.Text,
+	} {
+		hash.Write([]byte(strings.Join(strings.Fields(part), " ")))
+		hash.Write([]byte{0})
+	}
+	if q.Kind == KindNote {
+		hash.Write([]byte(strings.Join(strings.Fields(q.Note), " ")))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/quote/entities"
+	"home-library/pkg/transaction"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	// CreateQuotes stores the quotes in one transaction, skipping those the
+	// user already has, and returns how many were actually inserted.
+	CreateQuotes(ctx context.Context, quotes []entities.Quote) (int, error)
+	GetQuotes(ctx context.Context, userID uuid.UUID, fileID *uuid.UUID) ([]entities.Quote, error)
+	DeleteQuote(ctx context.Context, quoteID uuid.UUID, userID uuid.UUID) error
+	// GetBooks returns the books of the user's household catalog.
+	GetBooks(ctx context.Context, userID uuid.UUID) ([]entities.Book, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateQuotes(ctx context.Context, quotes []entities.Quote) (int, error) {
+	query := `
+		INSERT INTO quotes (
+			quote_id, user_id, book_id, file_id, source, kind, book_title, book_author,
+			text, note, chapter, location, page, highlighted_at, fingerprint, created_at
+		) VALUES (
+			:quote_id, :user_id, :book_id, :file_id, :source, :kind, :book_title, :book_author,
+			:text, :note, :chapter, :location, :page, :highlighted_at, :fingerprint, :created_at
+		)
+		ON CONFLICT (user_id, fingerprint) DO NOTHING
+	`
+
+	inserted := 0
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		for i := range quotes {
+			result, err := tx.NamedExecContext(ctx, query, &quotes[i])
+			if err != nil {
+				return err
+			}
+
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			inserted += int(affected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return inserted, nil
+}
+
+func (r *repository) GetQuotes(ctx context.Context, userID uuid.UUID, fileID *uuid.UUID) ([]entities.Quote, error) {
+	quotes := make([]entities.Quote, 0)
+	query := `
+		SELECT * FROM quotes
+		WHERE user_id = $1 AND ($2::uuid IS NULL OR file_id = $2)
+		ORDER BY book_title, page, highlighted_at NULLS LAST, created_at
+	`
+
+	err := r.db.SelectContext(ctx, &quotes, query, userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return quotes, nil
+}
+
+func (r *repository) DeleteQuote(ctx context.Context, quoteID uuid.UUID, userID uuid.UUID) error {
+	query := `DELETE FROM quotes WHERE quote_id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, quoteID, userID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) GetBooks(ctx context.Context, userID uuid.UUID) ([]entities.Book, error) {
+	books := make([]entities.Book, 0)
+	query := `
+		SELECT b.book_id, b.title, b.authors FROM books b
+		JOIN household_members m ON m.household_id = b.household_id
+		WHERE m.user_id = $1
+	`
+
+	err := r.db.SelectContext(ctx, &books, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *repository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.db.InTx(ctx, nil, fn)
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"home-library/internal/services/quote/entities"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func newQuote(userID uuid.UUID, text string) entities.Quote {
+	quote := entities.NewQuote(userID, entities.SourceKindle, entities.KindHighlight)
+	quote.BookTitle = "Солярис"
+	quote.Text = text
+	quote.Fingerprint = quote.ComputeFingerprint()
+	return *quote
+}
+
+func TestCreateQuotes(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("counts only inserted quotes", func(t *testing.T) {
+		userID := uuid.New()
+		quotes := []entities.Quote{newQuote(userID, "first"), newQuote(userID, "second")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT (user_id, fingerprint) DO NOTHING")).
+			WithArgs(
+				quotes[0].QuoteID,
+				quotes[0].UserID,
+				quotes[0].BookID,
+				quotes[0].FileID,
+				quotes[0].Source,
+				quotes[0].Kind,
+				quotes[0].BookTitle,
+				quotes[0].BookAuthor,
+				quotes[0].Text,
+				quotes[0].Note,
+				quotes[0].Chapter,
+				quotes[0].Location,
+				quotes[0].Page,
+				quotes[0].HighlightedAt,
+				quotes[0].Fingerprint,
+				quotes[0].CreatedAt,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO quotes").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		inserted, err := repo.CreateQuotes(context.Background(), quotes)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, inserted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failure rolls back", func(t *testing.T) {
+		userID := uuid.New()
+		quotes := []entities.Quote{newQuote(userID, "first"), newQuote(userID, "second")}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO quotes").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO quotes").
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		inserted, err := repo.CreateQuotes(context.Background(), quotes)
+
+		assert.Error(t, err)
+		assert.Zero(t, inserted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetQuotes(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	userID, fileID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE user_id = $1 AND ($2::uuid IS NULL OR file_id = $2)")).
+		WithArgs(userID, &fileID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"quote_id", "user_id", "file_id", "source", "kind", "book_title", "book_author",
+			"text", "note", "chapter", "location", "page", "highlighted_at", "fingerprint", "created_at",
+		}).AddRow(
+			uuid.New(), userID, fileID, "koreader", "highlight", "Солярис", "Станислав Лем",
+			"Человеку нужен человек", "", "", "", 42, now, "f", now,
+		))
+
+	quotes, err := repo.GetQuotes(context.Background(), userID, &fileID)
+
+	assert.NoError(t, err)
+	assert.Len(t, quotes, 1)
+	assert.Equal(t, &fileID, quotes[0].FileID)
+	assert.Equal(t, 42, quotes[0].Page)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBooks(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	userID, bookID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`SELECT b.book_id, b.title, b.authors FROM books b\s+JOIN household_members m ON m.household_id = b.household_id\s+WHERE m.user_id = \$1`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "title", "authors"}).AddRow(bookID, "Солярис", "{\"Станислав Лем\"}"))
+
+	books, err := repo.GetBooks(context.Background(), userID)
+
+	assert.NoError(t, err)
+	if assert.Len(t, books, 1) {
+		assert.Equal(t, bookID, books[0].BookID)
+		assert.Equal(t, []string{"Станислав Лем"}, []string(books[0].Authors))
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteQuote(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("delete own quote", func(t *testing.T) {
+		quoteID, userID := uuid.New(), uuid.New()
+
+		mock.ExpectExec("DELETE FROM quotes").
+			WithArgs(quoteID, userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.DeleteQuote(context.Background(), quoteID, userID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM quotes").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteQuote(context.Background(), uuid.New(), uuid.New())
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
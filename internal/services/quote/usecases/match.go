@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	ebookEntities "home-library/internal/services/ebook/entities"
+	"home-library/internal/services/quote/entities"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// candidate is something a highlight can be matched to: a book of the
+// catalog, or an ebook file, which may be attached to a book itself.
+type candidate struct {
+	bookID  *uuid.UUID
+	fileID  *uuid.UUID
+	title   string
+	authors []string
+}
+
+func bookCandidates(books []entities.Book) []candidate {
+	candidates := make([]candidate, len(books))
+	for i := range books {
+		candidates[i] = candidate{bookID: &books[i].BookID, title: books[i].Title, authors: books[i].Authors}
+	}
+	return candidates
+}
+
+func fileCandidates(files []ebookEntities.EbookFile) []candidate {
+	candidates := make([]candidate, len(files))
+	for i := range files {
+		candidates[i] = candidate{bookID: files[i].BookID, fileID: &files[i].FileID, title: files[i].Title, authors: files[i].Authors}
+	}
+	return candidates
+}
+
+// bookMatcher links highlights to books. E-readers keep the title from the
+// file metadata, so titles are compared loosely and the author only breaks
+// ties and rules out namesakes.
+type bookMatcher struct {
+	byTitle map[string][]candidate
+}
+
+func newBookMatcher(candidates []candidate) *bookMatcher {
+	m := &bookMatcher{byTitle: make(map[string][]candidate)}
+	for _, c := range candidates {
+		full, short := titleKeys(c.title)
+		if full == "" {
+			continue
+		}
+		m.byTitle[full] = append(m.byTitle[full], c)
+		if short != full {
+			m.byTitle[short] = append(m.byTitle[short], c)
+		}
+	}
+	return m
+}
+
+func (m *bookMatcher) match(title, author string) *candidate {
+	full, short := titleKeys(title)
+	if full == "" {
+		return nil
+	}
+
+	candidates := m.byTitle[full]
+	if len(candidates) == 0 {
+		candidates = m.byTitle[short]
+	}
+
+	wanted := nameTokens(author)
+	for i, c := range candidates {
+		if len(wanted) == 0 || len(c.authors) == 0 {
+			return &candidates[i]
+		}
+		for _, name := range c.authors {
+			for token := range nameTokens(name) {
+				if wanted[token] {
+					return &candidates[i]
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// titleKeys returns the normalized title and the same without a subtitle.
+func titleKeys(title string) (string, string) {
+	main, _, _ := strings.Cut(title, ":")
+	return normalize(title), normalize(main)
+}
+
+// nameTokens returns the words of a name long enough to tell people apart, so
+// that "Стругацкий, Аркадий" and "Аркадий Стругацкий" match regardless of
+// word order and punctuation.
+func nameTokens(name string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, word := range strings.Fields(normalize(name)) {
+		if len([]rune(word)) >= 3 {
+			tokens[word] = true
+		}
+	}
+	return tokens
+}
+
+func normalize(s string) string {
+	s = strings.ToLower(s)
+	s = strings.ReplaceAll(s, "ё", "е")
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
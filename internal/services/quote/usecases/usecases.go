@@ -0,0 +1,142 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	ebookRepository "home-library/internal/services/ebook/repository"
+	"home-library/internal/services/quote/dtos"
+	"home-library/internal/services/quote/entities"
+	"home-library/internal/services/quote/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/highlights"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// MaxImportSize is the largest accepted highlights file in bytes. Years of
+// Kindle clippings stay well below it.
+const MaxImportSize = 20 << 20
+
+// maxLocationLength matches the quotes.location column.
+const maxLocationLength = 32
+
+type UseCase interface {
+	ImportQuotes(ctx context.Context, userID uuid.UUID, r io.Reader) (*dtos.ImportQuotesResponse, error)
+	GetQuotes(ctx context.Context, userID uuid.UUID, fileID *uuid.UUID) ([]dtos.QuoteResponse, error)
+	DeleteQuote(ctx context.Context, userID uuid.UUID, quoteID uuid.UUID) error
+}
+
+type useCase struct {
+	r      repository.Repository
+	ebooks ebookRepository.Repository
+}
+
+func NewUseCase(r repository.Repository, ebooks ebookRepository.Repository) UseCase {
+	return &useCase{r: r, ebooks: ebooks}
+}
+
+func (u *useCase) ImportQuotes(ctx context.Context, userID uuid.UUID, r io.Reader) (*dtos.ImportQuotesResponse, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxImportSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImportSize {
+		return nil, errors.ErrQuotesTooLarge
+	}
+
+	format := highlights.Detect(data)
+	parsed, err := highlights.Parse(format, bytes.NewReader(data))
+	if err != nil {
+		if stdErrors.Is(err, highlights.ErrMalformed) {
+			return nil, errors.ErrQuotesMalformed
+		}
+		return nil, err
+	}
+
+	response := &dtos.ImportQuotesResponse{Source: entities.Source(format), Total: len(parsed)}
+	if len(parsed) == 0 {
+		return response, nil
+	}
+
+	books, err := u.r.GetBooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := u.ebooks.GetSharedFiles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	catalogMatcher, fileMatcher := newBookMatcher(bookCandidates(books)), newBookMatcher(fileCandidates(files))
+
+	quotes := make([]entities.Quote, len(parsed))
+	for i, highlight := range parsed {
+		quote := entities.NewQuote(userID, entities.Source(format), entities.Kind(highlight.Kind))
+		quote.BookTitle = highlight.Title
+		quote.BookAuthor = highlight.Author
+		quote.Text = highlight.Text
+		quote.Note = highlight.Note
+		quote.Chapter = highlight.Chapter
+		quote.Location = truncate(highlight.Location, maxLocationLength)
+		quote.Page = highlight.Page
+		quote.HighlightedAt = highlight.CreatedAt
+		quote.Fingerprint = quote.ComputeFingerprint()
+
+		// A highlight matched only to an ebook file still gets the book the
+		// file is attached to.
+		if file := fileMatcher.match(highlight.Title, highlight.Author); file != nil {
+			quote.FileID, quote.BookID = file.fileID, file.bookID
+		}
+		if book := catalogMatcher.match(highlight.Title, highlight.Author); book != nil {
+			quote.BookID = book.bookID
+		}
+		if quote.BookID != nil || quote.FileID != nil {
+			response.Matched++
+		}
+
+		quotes[i] = *quote
+	}
+
+	response.Imported, err = u.r.CreateQuotes(ctx, quotes)
+	if err != nil {
+		return nil, err
+	}
+	response.Duplicates = response.Total - response.Imported
+
+	return response, nil
+}
+
+func (u *useCase) GetQuotes(ctx context.Context, userID uuid.UUID, fileID *uuid.UUID) ([]dtos.QuoteResponse, error) {
+	quotes, err := u.r.GetQuotes(ctx, userID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.QuoteResponse, len(quotes))
+	for i, quote := range quotes {
+		response[i] = dtos.NewQuoteResponse(quote)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) DeleteQuote(ctx context.Context, userID uuid.UUID, quoteID uuid.UUID) error {
+	return mapNoRows(u.r.DeleteQuote(ctx, quoteID, userID), errors.ErrQuoteNotFound)
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
@@ -0,0 +1,269 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	ebookEntities "home-library/internal/services/ebook/entities"
+	"home-library/internal/services/quote/entities"
+	"home-library/pkg/errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateQuotes(ctx context.Context, quotes []entities.Quote) (int, error) {
+	args := m.Called(ctx, quotes)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRepository) GetBooks(ctx context.Context, userID uuid.UUID) ([]entities.Book, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Book), args.Error(1)
+}
+
+func (m *MockRepository) GetQuotes(ctx context.Context, userID uuid.UUID, fileID *uuid.UUID) ([]entities.Quote, error) {
+	args := m.Called(ctx, userID, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Quote), args.Error(1)
+}
+
+func (m *MockRepository) DeleteQuote(ctx context.Context, quoteID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, quoteID, userID).Error(0)
+}
+
+type MockEbookRepository struct {
+	mock.Mock
+}
+
+func (m *MockEbookRepository) CreateFile(ctx context.Context, file *ebookEntities.EbookFile) (uuid.UUID, error) {
+	args := m.Called(ctx, file)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockEbookRepository) GetFileByHash(ctx context.Context, ownerID uuid.UUID, sha256 string) (*ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, ownerID, sha256)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) GetSharedFile(ctx context.Context, fileID uuid.UUID, viewerID uuid.UUID) (*ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, fileID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) GetSharedFiles(ctx context.Context, viewerID uuid.UUID) ([]ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) FindSharedFiles(ctx context.Context, viewerID uuid.UUID, filter ebookEntities.Filter) ([]ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, viewerID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) GetSharedFacet(ctx context.Context, viewerID uuid.UUID, facet ebookEntities.Facet) ([]ebookEntities.FacetValue, error) {
+	args := m.Called(ctx, viewerID, facet)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ebookEntities.FacetValue), args.Error(1)
+}
+
+func (m *MockEbookRepository) UpdateFile(ctx context.Context, file *ebookEntities.EbookFile) error {
+	return m.Called(ctx, file).Error(0)
+}
+
+func (m *MockEbookRepository) HasBook(ctx context.Context, ownerID uuid.UUID, bookID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, ownerID, bookID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockEbookRepository) SetBook(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID, bookID *uuid.UUID) error {
+	return m.Called(ctx, fileID, ownerID, bookID).Error(0)
+}
+
+func (m *MockEbookRepository) DeleteFile(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID) (*ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, fileID, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) HashInUse(ctx context.Context, sha256 string) (bool, error) {
+	args := m.Called(ctx, sha256)
+	return args.Bool(0), args.Error(1)
+}
+
+const clippings = "Пикник на обочине (Стругацкий, Аркадий)\n" +
+	"- Ваш выделенный отрывок на странице 57 | место 858-860 | Добавлено: воскресенье, 3 марта 2019 г. в 10:24:31\n" +
+	"\n" +
+	"Счастья для всех, даром!\n" +
+	"==========\n" +
+	"Пикник на обочине (Стругацкий, Аркадий)\n" +
+	"- Ваш выделенный отрывок на странице 57 | место 858-860 | Добавлено: воскресенье, 3 марта 2019 г. в 10:24:31\n" +
+	"\n" +
+	"Счастья для всех, даром!\n" +
+	"==========\n" +
+	"Незнакомая книга (Кто-то)\n" +
+	"- Ваша заметка в месте 10 | Добавлено: воскресенье, 3 марта 2019 г. в 11:00:00\n" +
+	"\n" +
+	"Сама по себе\n" +
+	"==========\n"
+
+func file(title string, authors ...string) ebookEntities.EbookFile {
+	f := ebookEntities.NewEbookFile(uuid.New(), strings.Repeat("a", 64), ebookEntities.FormatEPUB, 1)
+	f.Title = title
+	f.Authors = pq.StringArray(authors)
+	return *f
+}
+
+func TestImportQuotes(t *testing.T) {
+	t.Run("matches, deduplicates and stores", func(t *testing.T) {
+		repo, ebooks := new(MockRepository), new(MockEbookRepository)
+		useCase := NewUseCase(repo, ebooks)
+		userID := uuid.New()
+		picnic := file("Пикник на обочине", "Аркадий Стругацкий", "Борис Стругацкий")
+
+		repo.On("GetBooks", mock.Anything, userID).Return([]entities.Book{}, nil)
+		ebooks.On("GetSharedFiles", mock.Anything, userID).Return([]ebookEntities.EbookFile{file("Солярис", "Станислав Лем"), picnic}, nil)
+		repo.On("CreateQuotes", mock.Anything, mock.MatchedBy(func(quotes []entities.Quote) bool {
+			return len(quotes) == 3 &&
+				quotes[0].Fingerprint == quotes[1].Fingerprint &&
+				quotes[0].FileID != nil && *quotes[0].FileID == picnic.FileID && quotes[0].BookID == nil &&
+				quotes[0].Location == "858-860" && quotes[0].Page == 57 &&
+				quotes[2].Kind == entities.KindNote && quotes[2].FileID == nil &&
+				quotes[2].UserID == userID && quotes[2].Source == entities.SourceKindle
+		})).Return(1, nil)
+
+		result, err := useCase.ImportQuotes(context.Background(), userID, strings.NewReader(clippings))
+
+		require.NoError(t, err)
+		assert.Equal(t, entities.SourceKindle, result.Source)
+		assert.Equal(t, 3, result.Total)
+		assert.Equal(t, 1, result.Imported)
+		assert.Equal(t, 2, result.Duplicates)
+		assert.Equal(t, 2, result.Matched)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("matches the catalog before ebook files", func(t *testing.T) {
+		repo, ebooks := new(MockRepository), new(MockEbookRepository)
+		useCase := NewUseCase(repo, ebooks)
+		userID, attachedTo := uuid.New(), uuid.New()
+		picnic := entities.Book{BookID: uuid.New(), Title: "Пикник на обочине", Authors: pq.StringArray{"Аркадий Стругацкий"}}
+		attached := file("Незнакомая книга")
+		attached.BookID = &attachedTo
+
+		repo.On("GetBooks", mock.Anything, userID).Return([]entities.Book{picnic}, nil)
+		ebooks.On("GetSharedFiles", mock.Anything, userID).Return([]ebookEntities.EbookFile{attached}, nil)
+		repo.On("CreateQuotes", mock.Anything, mock.MatchedBy(func(quotes []entities.Quote) bool {
+			return len(quotes) == 3 &&
+				*quotes[0].BookID == picnic.BookID && quotes[0].FileID == nil &&
+				*quotes[2].BookID == attachedTo && *quotes[2].FileID == attached.FileID
+		})).Return(2, nil)
+
+		result, err := useCase.ImportQuotes(context.Background(), userID, strings.NewReader(clippings))
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Matched)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("nothing to import", func(t *testing.T) {
+		useCase := NewUseCase(new(MockRepository), new(MockEbookRepository))
+
+		result, err := useCase.ImportQuotes(context.Background(), uuid.New(), strings.NewReader(`{"title": "Пусто", "entries": []}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, entities.SourceKOReader, result.Source)
+		assert.Zero(t, result.Total)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		useCase := NewUseCase(new(MockRepository), new(MockEbookRepository))
+
+		_, err := useCase.ImportQuotes(context.Background(), uuid.New(), strings.NewReader("{not json"))
+
+		assert.ErrorIs(t, err, errors.ErrQuotesMalformed)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		useCase := NewUseCase(new(MockRepository), new(MockEbookRepository))
+
+		_, err := useCase.ImportQuotes(context.Background(), uuid.New(), bytes.NewReader(make([]byte, MaxImportSize+1)))
+
+		assert.ErrorIs(t, err, errors.ErrQuotesTooLarge)
+	})
+}
+
+func TestBookMatcher(t *testing.T) {
+	solaris := file("Солярис", "Станислав Лем")
+	hobbit := file("The Hobbit: or There and Back Again", "J. R. R. Tolkien")
+	anonymous := file("Слово о полку Игореве")
+	namesake := file("Солярис", "Kim Stanley Robinson")
+
+	matcher := newBookMatcher(fileCandidates([]ebookEntities.EbookFile{namesake, solaris, hobbit, anonymous}))
+
+	tests := []struct {
+		name     string
+		title    string
+		author   string
+		expected *uuid.UUID
+	}{
+		{"author breaks ties", "СОЛЯРИС", "Лем, Станислав", &solaris.FileID},
+		{"subtitle is optional", "The Hobbit", "Tolkien, J.R.R.", &hobbit.FileID},
+		{"punctuation is ignored", "The Hobbit, or There and Back Again", "", &hobbit.FileID},
+		{"file without authors", "Слово о полку Игореве", "Неизвестен", &anonymous.FileID},
+		{"other author", "Солярис", "Андрей Тарковский", nil},
+		{"unknown title", "Сталкер", "", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var matched *uuid.UUID
+			if c := matcher.match(test.title, test.author); c != nil {
+				matched = c.fileID
+			}
+			assert.Equal(t, test.expected, matched)
+		})
+	}
+}
+
+func TestDeleteQuote(t *testing.T) {
+	repo := new(MockRepository)
+	useCase := NewUseCase(repo, new(MockEbookRepository))
+	userID, quoteID := uuid.New(), uuid.New()
+
+	repo.On("DeleteQuote", mock.Anything, quoteID, userID).Return(sql.ErrNoRows)
+
+	err := useCase.DeleteQuote(context.Background(), userID, quoteID)
+
+	assert.ErrorIs(t, err, errors.ErrQuoteNotFound)
+}
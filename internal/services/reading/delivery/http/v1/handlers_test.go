@@ -0,0 +1,148 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"home-library/internal/services/reading/dtos"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) CreateReading(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.ReadingRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, bookID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) GetReadings(ctx context.Context, userID uuid.UUID) ([]dtos.ReadingResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.ReadingResponse), args.Error(1)
+}
+
+func (m *MockUseCase) UpdateReading(ctx context.Context, userID uuid.UUID, readingID uuid.UUID, payload dtos.ReadingRequest) error {
+	return m.Called(ctx, userID, readingID, payload).Error(0)
+}
+
+func (m *MockUseCase) DeleteReading(ctx context.Context, userID uuid.UUID, readingID uuid.UUID) error {
+	return m.Called(ctx, userID, readingID).Error(0)
+}
+
+func (m *MockUseCase) SaveReview(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.ReviewRequest) error {
+	return m.Called(ctx, userID, bookID, payload).Error(0)
+}
+
+func (m *MockUseCase) GetReviews(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) ([]dtos.ReviewResponse, error) {
+	args := m.Called(ctx, userID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.ReviewResponse), args.Error(1)
+}
+
+func (m *MockUseCase) DeleteReview(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) error {
+	return m.Called(ctx, userID, bookID).Error(0)
+}
+
+func newContext(e *echo.Echo, method string, target string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestCreateReading(t *testing.T) {
+	e := echo.New()
+
+	t.Run("finished reading is logged", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, bookID, readingID := uuid.New(), uuid.New(), uuid.New()
+		finished := time.Date(2026, time.May, 3, 21, 0, 0, 0, time.UTC)
+		c, rec := newContext(e, http.MethodPost, "/books/x/readings", `{"finished_at":"2026-05-03T21:00:00Z"}`, userID)
+		c.SetParamNames("book_id")
+		c.SetParamValues(bookID.String())
+
+		mockUseCase.On("CreateReading", context.Background(), userID, bookID, mock.MatchedBy(func(p dtos.ReadingRequest) bool {
+			return p.StartedAt == nil && p.FinishedAt.Equal(finished)
+		})).Return(readingID, nil)
+
+		err := handler.CreateReading(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response dtos.CreateReadingResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, readingID, response.ReadingID)
+	})
+
+	t.Run("dates out of order", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, bookID := uuid.New(), uuid.New()
+		c, rec := newContext(e, http.MethodPost, "/books/x/readings", `{}`, userID)
+		c.SetParamNames("book_id")
+		c.SetParamValues(bookID.String())
+
+		mockUseCase.On("CreateReading", context.Background(), userID, bookID, dtos.ReadingRequest{}).
+			Return(uuid.Nil, customErrors.ErrReadingDates)
+
+		err := handler.CreateReading(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestSaveReview(t *testing.T) {
+	e := echo.New()
+
+	t.Run("rating out of range", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		c, rec := newContext(e, http.MethodPut, "/books/x/review", `{"rating":6}`, uuid.New())
+		c.SetParamNames("book_id")
+		c.SetParamValues(uuid.New().String())
+
+		err := handler.SaveReview(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "SaveReview", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("book of another household", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, bookID := uuid.New(), uuid.New()
+		c, rec := newContext(e, http.MethodPut, "/books/x/review", `{"rating":4}`, userID)
+		c.SetParamNames("book_id")
+		c.SetParamValues(bookID.String())
+
+		mockUseCase.On("SaveReview", context.Background(), userID, bookID, dtos.ReviewRequest{Rating: 4}).Return(customErrors.ErrBookNotFound)
+
+		err := handler.SaveReview(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,13 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) ReadingRoutes(domain *echo.Group) {
+	domain.POST("/books/:book_id/readings", h.CreateReading)
+	domain.GET("/readings", h.GetReadings)
+	domain.PUT("/readings/:reading_id", h.UpdateReading)
+	domain.DELETE("/readings/:reading_id", h.DeleteReading)
+	domain.PUT("/books/:book_id/review", h.SaveReview)
+	domain.DELETE("/books/:book_id/review", h.DeleteReview)
+	domain.GET("/books/:book_id/reviews", h.GetReviews)
+}
@@ -0,0 +1,66 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/reading/entities"
+	"time"
+)
+
+// ReadingRequest leaves FinishedAt empty for a book still being read.
+type ReadingRequest struct {
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+type CreateReadingResponse struct {
+	ReadingID uuid.UUID `json:"reading_id"`
+}
+
+type ReadingResponse struct {
+	ReadingID  uuid.UUID  `json:"reading_id"`
+	BookID     uuid.UUID  `json:"book_id"`
+	Title      string     `json:"title"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+func NewReadingResponse(reading entities.Reading) ReadingResponse {
+	return ReadingResponse{
+		ReadingID:  reading.ReadingID,
+		BookID:     reading.BookID,
+		Title:      reading.Title,
+		StartedAt:  reading.StartedAt,
+		FinishedAt: reading.FinishedAt,
+	}
+}
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,gte=1,lte=5"`
+	Text   string `json:"text" validate:"max=10000"`
+}
+
+func (r *ReviewRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type ReviewResponse struct {
+	UserID    uuid.UUID `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewReviewResponse(review entities.Review) ReviewResponse {
+	return ReviewResponse{
+		UserID:    review.UserID,
+		FirstName: review.FirstName,
+		LastName:  review.LastName,
+		Rating:    review.Rating,
+		Text:      review.Text,
+		UpdatedAt: review.UpdatedAt,
+	}
+}
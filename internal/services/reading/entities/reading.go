@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reading is one pass of a member through a book of the household. It is in
+// progress until FinishedAt is set; rereading a book adds another reading.
+type Reading struct {
+	ReadingID   uuid.UUID  `db:"reading_id"`
+	HouseholdID uuid.UUID  `db:"household_id"`
+	BookID      uuid.UUID  `db:"book_id"`
+	UserID      uuid.UUID  `db:"user_id"`
+	StartedAt   *time.Time `db:"started_at"`
+	FinishedAt  *time.Time `db:"finished_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	// Title is the title of the book, filled in by listings.
+	Title string `db:"title"`
+}
+
+func NewReading(householdID uuid.UUID, bookID uuid.UUID, userID uuid.UUID) *Reading {
+	now := time.Now()
+	return &Reading{
+		ReadingID:   uuid.New(),
+		HouseholdID: householdID,
+		BookID:      bookID,
+		UserID:      userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
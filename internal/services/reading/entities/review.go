@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review is a member's rating of a book from 1 to 5, with optional text.
+// Each member has at most one review per book.
+type Review struct {
+	HouseholdID uuid.UUID `db:"household_id"`
+	BookID      uuid.UUID `db:"book_id"`
+	UserID      uuid.UUID `db:"user_id"`
+	Rating      int       `db:"rating"`
+	Text        string    `db:"text"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+	// FirstName and LastName are the reviewer's, filled in by listings.
+	FirstName string `db:"first_name"`
+	LastName  string `db:"last_name"`
+}
+
+func NewReview(householdID uuid.UUID, bookID uuid.UUID, userID uuid.UUID, rating int, text string) *Review {
+	now := time.Now()
+	return &Review{
+		HouseholdID: householdID,
+		BookID:      bookID,
+		UserID:      userID,
+		Rating:      rating,
+		Text:        text,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
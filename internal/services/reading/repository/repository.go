@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/reading/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/storage"
+	"home-library/pkg/transaction"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Repository methods take the household ID so that readings and reviews only
+// ever point at books of the caller's household, and the user ID so that
+// members only change their own.
+type Repository interface {
+	CreateReading(ctx context.Context, reading *entities.Reading) (uuid.UUID, error)
+	// GetReadings lists the user's readings in the household, the ones in
+	// progress first and then the latest finished.
+	GetReadings(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) ([]entities.Reading, error)
+	UpdateReading(ctx context.Context, reading *entities.Reading) error
+	DeleteReading(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, readingID uuid.UUID) error
+
+	// SaveReview creates the user's review of the book or replaces it.
+	SaveReview(ctx context.Context, review *entities.Review) error
+	// GetReviews lists the reviews of a book by the members, latest first.
+	GetReviews(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) ([]entities.Review, error)
+	DeleteReview(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID, userID uuid.UUID) error
+}
+
+// constraints translates a book of another household, or one deleted
+// meanwhile, and a reading that finishes before it starts.
+var constraints = storage.Constraints{
+	"readings_book_fkey": errors.ErrBookNotFound,
+	"reviews_book_fkey":  errors.ErrBookNotFound,
+	"readings_check":     errors.ErrReadingDates,
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateReading(ctx context.Context, reading *entities.Reading) (uuid.UUID, error) {
+	query := `
+		INSERT INTO readings (reading_id, household_id, book_id, user_id, started_at, finished_at, created_at, updated_at)
+		VALUES (:reading_id, :household_id, :book_id, :user_id, :started_at, :finished_at, :created_at, :updated_at)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, reading)
+	if err != nil {
+		return uuid.Nil, constraints.Map(err)
+	}
+
+	return reading.ReadingID, nil
+}
+
+func (r *repository) GetReadings(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) ([]entities.Reading, error) {
+	readings := make([]entities.Reading, 0)
+	query := `
+		SELECT r.*, b.title
+		FROM readings r
+		JOIN books b ON b.book_id = r.book_id
+		WHERE r.household_id = $1 AND r.user_id = $2
+		ORDER BY r.finished_at DESC NULLS FIRST, r.created_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &readings, query, householdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return readings, nil
+}
+
+func (r *repository) UpdateReading(ctx context.Context, reading *entities.Reading) error {
+	query := `
+		UPDATE readings
+		SET started_at = :started_at, finished_at = :finished_at, updated_at = :updated_at
+		WHERE reading_id = :reading_id AND household_id = :household_id AND user_id = :user_id
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, reading)
+	if err != nil {
+		return constraints.Map(err)
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) DeleteReading(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, readingID uuid.UUID) error {
+	query := `DELETE FROM readings WHERE household_id = $1 AND user_id = $2 AND reading_id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, userID, readingID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) SaveReview(ctx context.Context, review *entities.Review) error {
+	query := `
+		INSERT INTO reviews (household_id, book_id, user_id, rating, text, created_at, updated_at)
+		VALUES (:household_id, :book_id, :user_id, :rating, :text, :created_at, :updated_at)
+		ON CONFLICT (book_id, user_id) DO UPDATE
+		SET rating = EXCLUDED.rating, text = EXCLUDED.text, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, review)
+	return constraints.Map(err)
+}
+
+func (r *repository) GetReviews(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) ([]entities.Review, error) {
+	reviews := make([]entities.Review, 0)
+	query := `
+		SELECT r.*, u.first_name, u.last_name
+		FROM reviews r
+		JOIN users u ON u.user_id = r.user_id
+		WHERE r.household_id = $1 AND r.book_id = $2
+		ORDER BY r.updated_at DESC
+	`
+
+	err := r.db.SelectContext(ctx, &reviews, query, householdID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *repository) DeleteReview(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID, userID uuid.UUID) error {
+	query := `DELETE FROM reviews WHERE household_id = $1 AND book_id = $2 AND user_id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, householdID, bookID, userID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
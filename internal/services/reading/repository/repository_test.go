@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/reading/entities"
+	customErrors "home-library/pkg/errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestCreateReading(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	tests := []struct {
+		name       string
+		code       pq.ErrorCode
+		constraint string
+		err        error
+	}{
+		{"book of another household", "23503", "readings_book_fkey", customErrors.ErrBookNotFound},
+		{"finished before started", "23514", "readings_check", customErrors.ErrReadingDates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reading := entities.NewReading(uuid.New(), uuid.New(), uuid.New())
+
+			mock.ExpectExec("INSERT INTO readings").
+				WillReturnError(&pq.Error{Code: tt.code, Constraint: tt.constraint})
+
+			id, err := repo.CreateReading(context.Background(), reading)
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, uuid.Nil, id)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdateReading(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("reading of another member is not updated", func(t *testing.T) {
+		reading := entities.NewReading(uuid.New(), uuid.New(), uuid.New())
+
+		mock.ExpectExec(`UPDATE readings .+ WHERE reading_id = \? AND household_id = \? AND user_id = \?`).
+			WithArgs(reading.StartedAt, reading.FinishedAt, reading.UpdatedAt, reading.ReadingID, reading.HouseholdID, reading.UserID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.UpdateReading(context.Background(), reading)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveReview(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("second review of a book replaces the first", func(t *testing.T) {
+		review := entities.NewReview(uuid.New(), uuid.New(), uuid.New(), 4, "Хорошо")
+
+		mock.ExpectExec(`INSERT INTO reviews .+ ON CONFLICT \(book_id, user_id\) DO UPDATE`).
+			WithArgs(review.HouseholdID, review.BookID, review.UserID, 4, "Хорошо", review.CreatedAt, review.UpdatedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SaveReview(context.Background(), review)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,163 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/internal/services/reading/dtos"
+	"home-library/internal/services/reading/entities"
+	"home-library/internal/services/reading/repository"
+	"home-library/pkg/errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Every member keeps their own reading log and reviews, viewers included:
+// reading a book does not change the catalog.
+type UseCase interface {
+	CreateReading(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.ReadingRequest) (readingID uuid.UUID, err error)
+	GetReadings(ctx context.Context, userID uuid.UUID) ([]dtos.ReadingResponse, error)
+	UpdateReading(ctx context.Context, userID uuid.UUID, readingID uuid.UUID, payload dtos.ReadingRequest) error
+	DeleteReading(ctx context.Context, userID uuid.UUID, readingID uuid.UUID) error
+
+	SaveReview(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.ReviewRequest) error
+	GetReviews(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) ([]dtos.ReviewResponse, error)
+	DeleteReview(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) error
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository) UseCase {
+	return &useCase{r: r, households: households}
+}
+
+func (u *useCase) CreateReading(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.ReadingRequest) (readingID uuid.UUID, err error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	reading := entities.NewReading(member.HouseholdID, bookID, userID)
+	if err := applyReadingRequest(reading, payload); err != nil {
+		return uuid.Nil, err
+	}
+
+	return u.r.CreateReading(ctx, reading)
+}
+
+func (u *useCase) GetReadings(ctx context.Context, userID uuid.UUID) ([]dtos.ReadingResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	readings, err := u.r.GetReadings(ctx, member.HouseholdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.ReadingResponse, len(readings))
+	for i, reading := range readings {
+		response[i] = dtos.NewReadingResponse(reading)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) UpdateReading(ctx context.Context, userID uuid.UUID, readingID uuid.UUID, payload dtos.ReadingRequest) error {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	reading := &entities.Reading{
+		ReadingID:   readingID,
+		HouseholdID: member.HouseholdID,
+		UserID:      userID,
+		UpdatedAt:   time.Now(),
+	}
+	if err := applyReadingRequest(reading, payload); err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.UpdateReading(ctx, reading), errors.ErrReadingNotFound)
+}
+
+func (u *useCase) DeleteReading(ctx context.Context, userID uuid.UUID, readingID uuid.UUID) error {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.DeleteReading(ctx, member.HouseholdID, userID, readingID), errors.ErrReadingNotFound)
+}
+
+func (u *useCase) SaveReview(ctx context.Context, userID uuid.UUID, bookID uuid.UUID, payload dtos.ReviewRequest) error {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	review := entities.NewReview(member.HouseholdID, bookID, userID, payload.Rating, strings.TrimSpace(payload.Text))
+	return u.r.SaveReview(ctx, review)
+}
+
+func (u *useCase) GetReviews(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) ([]dtos.ReviewResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := u.r.GetReviews(ctx, member.HouseholdID, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.ReviewResponse, len(reviews))
+	for i, review := range reviews {
+		response[i] = dtos.NewReviewResponse(review)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) DeleteReview(ctx context.Context, userID uuid.UUID, bookID uuid.UUID) error {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.DeleteReview(ctx, member.HouseholdID, bookID, userID), errors.ErrReviewNotFound)
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+func applyReadingRequest(reading *entities.Reading, payload dtos.ReadingRequest) error {
+	if payload.StartedAt != nil && payload.FinishedAt != nil && payload.FinishedAt.Before(*payload.StartedAt) {
+		return errors.ErrReadingDates
+	}
+
+	reading.StartedAt = payload.StartedAt
+	reading.FinishedAt = payload.FinishedAt
+	return nil
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
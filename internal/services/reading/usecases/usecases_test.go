@@ -0,0 +1,251 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/internal/services/reading/dtos"
+	"home-library/internal/services/reading/entities"
+	"home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateReading(ctx context.Context, reading *entities.Reading) (uuid.UUID, error) {
+	args := m.Called(ctx, reading)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetReadings(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) ([]entities.Reading, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Reading), args.Error(1)
+}
+
+func (m *MockRepository) UpdateReading(ctx context.Context, reading *entities.Reading) error {
+	return m.Called(ctx, reading).Error(0)
+}
+
+func (m *MockRepository) DeleteReading(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, readingID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID, readingID).Error(0)
+}
+
+func (m *MockRepository) SaveReview(ctx context.Context, review *entities.Review) error {
+	return m.Called(ctx, review).Error(0)
+}
+
+func (m *MockRepository) GetReviews(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID) ([]entities.Review, error) {
+	args := m.Called(ctx, householdID, bookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Review), args.Error(1)
+}
+
+func (m *MockRepository) DeleteReview(ctx context.Context, householdID uuid.UUID, bookID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, bookID, userID).Error(0)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	households *MockHouseholdRepository
+}
+
+func newUseCase() (UseCase, mocks) {
+	m := mocks{new(MockRepository), new(MockHouseholdRepository)}
+	return NewUseCase(m.repo, m.households), m
+}
+
+func (m mocks) member(userID uuid.UUID, householdID uuid.UUID, role householdEntities.Role) {
+	m.households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: role}, nil)
+}
+
+func TestCreateReading(t *testing.T) {
+	t.Run("viewers log their reading", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID, readingID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+		started := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+		m.member(userID, householdID, householdEntities.RoleViewer)
+
+		m.repo.On("CreateReading", mock.Anything, mock.MatchedBy(func(r *entities.Reading) bool {
+			return r.HouseholdID == householdID && r.BookID == bookID && r.UserID == userID &&
+				r.StartedAt.Equal(started) && r.FinishedAt == nil
+		})).Return(readingID, nil)
+
+		id, err := u.CreateReading(context.Background(), userID, bookID, dtos.ReadingRequest{StartedAt: &started})
+
+		assert.NoError(t, err)
+		assert.Equal(t, readingID, id)
+	})
+
+	t.Run("finished before started", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+		started := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+		finished := started.AddDate(0, 0, -1)
+		m.member(userID, uuid.New(), householdEntities.RoleEditor)
+
+		_, err := u.CreateReading(context.Background(), userID, uuid.New(), dtos.ReadingRequest{StartedAt: &started, FinishedAt: &finished})
+
+		assert.ErrorIs(t, err, errors.ErrReadingDates)
+		m.repo.AssertNotCalled(t, "CreateReading", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUpdateReading(t *testing.T) {
+	t.Run("reading of another member", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, readingID := uuid.New(), uuid.New()
+		m.member(userID, uuid.New(), householdEntities.RoleOwner)
+
+		m.repo.On("UpdateReading", mock.Anything, mock.MatchedBy(func(r *entities.Reading) bool {
+			return r.ReadingID == readingID && r.UserID == userID
+		})).Return(sql.ErrNoRows)
+
+		err := u.UpdateReading(context.Background(), userID, readingID, dtos.ReadingRequest{})
+
+		assert.ErrorIs(t, err, errors.ErrReadingNotFound)
+	})
+}
+
+func TestSaveReview(t *testing.T) {
+	t.Run("review text is trimmed", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleViewer)
+
+		m.repo.On("SaveReview", mock.Anything, mock.MatchedBy(func(r *entities.Review) bool {
+			return r.HouseholdID == householdID && r.BookID == bookID && r.UserID == userID && r.Rating == 5 && r.Text == "Шедевр"
+		})).Return(nil)
+
+		err := u.SaveReview(context.Background(), userID, bookID, dtos.ReviewRequest{Rating: 5, Text: " Шедевр "})
+
+		assert.NoError(t, err)
+		m.repo.AssertExpectations(t)
+	})
+}
+
+func TestDeleteReview(t *testing.T) {
+	t.Run("no review to delete", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID, bookID := uuid.New(), uuid.New(), uuid.New()
+		m.member(userID, householdID, householdEntities.RoleViewer)
+
+		m.repo.On("DeleteReview", mock.Anything, householdID, bookID, userID).Return(sql.ErrNoRows)
+
+		err := u.DeleteReview(context.Background(), userID, bookID)
+
+		assert.ErrorIs(t, err, errors.ErrReviewNotFound)
+	})
+
+	t.Run("user outside a household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+		m.households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+
+		err := u.DeleteReview(context.Background(), userID, uuid.New())
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdNotFound)
+	})
+}
@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"context"
+	"home-library/internal/services/recommendation/dtos"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) GetRecommendations(ctx context.Context, userID uuid.UUID, request dtos.ListRecommendationsRequest) ([]dtos.RecommendationResponse, error) {
+	args := m.Called(ctx, userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.RecommendationResponse), args.Error(1)
+}
+
+func newContext(e *echo.Echo, target string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestGetRecommendations(t *testing.T) {
+	e := echo.New()
+
+	t.Run("suggestions with reasons", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+		c, rec := newContext(e, "/recommendations?limit=5", userID)
+
+		mockUseCase.On("GetRecommendations", context.Background(), userID, dtos.ListRecommendationsRequest{Limit: 5}).
+			Return([]dtos.RecommendationResponse{{BookID: uuid.New(), Title: "Эдем", Authors: []string{"Станислав Лем"}, Score: 2, Reason: "Вам понравились книги автора Станислав Лем"}}, nil)
+
+		err := h.GetRecommendations(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Вам понравились книги автора")
+	})
+
+	t.Run("limit too large", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		c, rec := newContext(e, "/recommendations?limit=500", uuid.New())
+
+		err := h.GetRecommendations(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "GetRecommendations", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user outside a household", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+		c, rec := newContext(e, "/recommendations", userID)
+
+		mockUseCase.On("GetRecommendations", context.Background(), userID, dtos.ListRecommendationsRequest{}).
+			Return(nil, customErrors.ErrHouseholdNotFound)
+
+		err := h.GetRecommendations(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
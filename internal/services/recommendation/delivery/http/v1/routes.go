@@ -0,0 +1,7 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) RecommendationRoutes(domain *echo.Group) {
+	domain.GET("/recommendations", h.GetRecommendations)
+}
@@ -0,0 +1,26 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+type ListRecommendationsRequest struct {
+	// Limit defaults to 10.
+	Limit int `query:"limit" validate:"gte=0,lte=50"`
+}
+
+func (r *ListRecommendationsRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type RecommendationResponse struct {
+	BookID  uuid.UUID  `json:"book_id"`
+	Title   string     `json:"title"`
+	Authors []string   `json:"authors"`
+	CoverID *uuid.UUID `json:"cover_id"`
+	Score   float64    `json:"score"`
+	// Reason explains the suggestion to the reader.
+	Reason string `json:"reason"`
+}
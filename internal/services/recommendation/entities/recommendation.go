@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"home-library/pkg/recommend"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Book is a household book as the ranking sees it. Owned is false for books
+// without copies, which only tell about the reader's taste.
+type Book struct {
+	BookID      uuid.UUID      `db:"book_id"`
+	Title       string         `db:"title"`
+	Authors     pq.StringArray `db:"authors"`
+	Tags        pq.StringArray `db:"tags"`
+	Series      string         `db:"series"`
+	SeriesIndex *float64       `db:"series_index"`
+	CoverID     *uuid.UUID     `db:"cover_id"`
+	Owned       bool           `db:"owned"`
+}
+
+func (b Book) Candidate() recommend.Book {
+	book := recommend.Book{
+		ID:      b.BookID.String(),
+		Title:   b.Title,
+		Authors: b.Authors,
+		Tags:    b.Tags,
+		Series:  b.Series,
+	}
+	if b.SeriesIndex != nil {
+		book.SeriesIndex = *b.SeriesIndex
+	}
+	return book
+}
+
+// Rating is the score of a household member's review.
+type Rating struct {
+	BookID uuid.UUID `db:"book_id"`
+	UserID uuid.UUID `db:"user_id"`
+	Score  int       `db:"rating"`
+}
+
+func (r Rating) Signal() recommend.Rating {
+	return recommend.Rating{BookID: r.BookID.String(), UserID: r.UserID.String(), Score: r.Score}
+}
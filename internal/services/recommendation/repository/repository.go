@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/recommendation/entities"
+	"home-library/pkg/transaction"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	// GetBooks lists every book of the household.
+	GetBooks(ctx context.Context, householdID uuid.UUID) ([]entities.Book, error)
+	// GetRatings lists the review scores the household's members gave its
+	// books.
+	GetRatings(ctx context.Context, householdID uuid.UUID) ([]entities.Rating, error)
+	// GetStartedBooks lists the household books the user has a reading of,
+	// finished or not.
+	GetStartedBooks(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) GetBooks(ctx context.Context, householdID uuid.UUID) ([]entities.Book, error) {
+	books := make([]entities.Book, 0)
+	query := `
+		SELECT b.book_id, b.title, b.authors, b.tags, b.series, b.series_index, b.cover_id,
+			EXISTS (SELECT 1 FROM copies c WHERE c.book_id = b.book_id) AS owned
+		FROM books b
+		WHERE b.household_id = $1
+		ORDER BY b.title, b.created_at
+	`
+
+	err := r.db.SelectContext(ctx, &books, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (r *repository) GetRatings(ctx context.Context, householdID uuid.UUID) ([]entities.Rating, error) {
+	ratings := make([]entities.Rating, 0)
+	query := `SELECT book_id, user_id, rating FROM reviews WHERE household_id = $1`
+
+	err := r.db.SelectContext(ctx, &ratings, query, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+func (r *repository) GetStartedBooks(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error) {
+	bookIDs := make([]uuid.UUID, 0)
+	query := `SELECT DISTINCT book_id FROM readings WHERE household_id = $1 AND user_id = $2`
+
+	err := r.db.SelectContext(ctx, &bookIDs, query, householdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookIDs, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestGetBooks(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	householdID, bookID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("AS owned FROM books b WHERE b.household_id = $1")).
+		WithArgs(householdID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id", "title", "authors", "tags", "series", "series_index", "cover_id", "owned"}).
+			AddRow(bookID, "Мессия Дюны", "{\"Фрэнк Герберт\"}", "{фантастика}", "Дюна", 2.0, nil, true))
+
+	books, err := repo.GetBooks(context.Background(), householdID)
+
+	require.NoError(t, err)
+	require.Len(t, books, 1)
+	assert.Equal(t, pq.StringArray{"Фрэнк Герберт"}, books[0].Authors)
+	assert.True(t, books[0].Owned)
+	assert.Equal(t, 2.0, books[0].Candidate().SeriesIndex)
+	assert.Equal(t, bookID.String(), books[0].Candidate().ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetStartedBooks(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	householdID, userID, bookID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT DISTINCT book_id FROM readings")).
+		WithArgs(householdID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"book_id"}).AddRow(bookID))
+
+	bookIDs, err := repo.GetStartedBooks(context.Background(), householdID, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{bookID}, bookIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
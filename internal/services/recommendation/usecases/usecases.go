@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/internal/services/recommendation/dtos"
+	"home-library/internal/services/recommendation/entities"
+	"home-library/internal/services/recommendation/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/recommend"
+
+	"github.com/google/uuid"
+)
+
+// defaultLimit is the number of suggestions when the request sets none.
+const defaultLimit = 10
+
+type UseCase interface {
+	// GetRecommendations suggests what the user could read next among the
+	// household's copies, computed from the household's own catalog and
+	// reviews only.
+	GetRecommendations(ctx context.Context, userID uuid.UUID, request dtos.ListRecommendationsRequest) ([]dtos.RecommendationResponse, error)
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository) UseCase {
+	return &useCase{r: r, households: households}
+}
+
+func (u *useCase) GetRecommendations(ctx context.Context, userID uuid.UUID, request dtos.ListRecommendationsRequest) ([]dtos.RecommendationResponse, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+
+	books, err := u.r.GetBooks(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := u.r.GetRatings(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A book the user is in the middle of is no news to them either.
+	started, err := u.r.GetStartedBooks(ctx, member.HouseholdID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]entities.Book, len(books))
+	candidates := make([]recommend.Book, len(books))
+	for i, book := range books {
+		byID[book.BookID.String()] = book
+		candidates[i] = book.Candidate()
+	}
+
+	signals := make([]recommend.Rating, len(ratings))
+	for i, rating := range ratings {
+		signals[i] = rating.Signal()
+	}
+
+	read := make(map[string]bool, len(started))
+	for _, bookID := range started {
+		read[bookID.String()] = true
+	}
+
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultLimit
+	}
+
+	// Books without copies shape the taste but cannot be taken off the
+	// shelf, so they are dropped before the limit applies.
+	result := make([]dtos.RecommendationResponse, 0, limit)
+	for _, suggestion := range recommend.Recommend(userID.String(), candidates, signals, read, 0) {
+		book := byID[suggestion.BookID]
+		if !book.Owned {
+			continue
+		}
+
+		result = append(result, dtos.RecommendationResponse{
+			BookID:  book.BookID,
+			Title:   book.Title,
+			Authors: nonNil(book.Authors),
+			CoverID: book.CoverID,
+			Score:   suggestion.Score,
+			Reason:  suggestion.Reason,
+		})
+		if len(result) == limit {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// nonNil keeps empty lists as [] rather than null in responses.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
@@ -0,0 +1,204 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/internal/services/recommendation/dtos"
+	"home-library/internal/services/recommendation/entities"
+	"home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetBooks(ctx context.Context, householdID uuid.UUID) ([]entities.Book, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Book), args.Error(1)
+}
+
+func (m *MockRepository) GetRatings(ctx context.Context, householdID uuid.UUID) ([]entities.Rating, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Rating), args.Error(1)
+}
+
+func (m *MockRepository) GetStartedBooks(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+func member(households *MockHouseholdRepository, userID uuid.UUID, householdID uuid.UUID) {
+	households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleViewer}, nil)
+}
+
+func TestGetRecommendations(t *testing.T) {
+	index := func(value float64) *float64 { return &value }
+	userID, partnerID, householdID := uuid.New(), uuid.New(), uuid.New()
+	dune1 := entities.Book{BookID: uuid.New(), Title: "Дюна", Authors: pq.StringArray{"Фрэнк Герберт"}, Series: "Дюна", SeriesIndex: index(1), Owned: true}
+	dune2 := entities.Book{BookID: uuid.New(), Title: "Мессия Дюны", Authors: pq.StringArray{"Фрэнк Герберт"}, Series: "Дюна", SeriesIndex: index(2), Owned: true}
+	eden := entities.Book{BookID: uuid.New(), Title: "Эдем", Authors: pq.StringArray{"Станислав Лем"}, Owned: true}
+	solaris := entities.Book{BookID: uuid.New(), Title: "Солярис", Authors: pq.StringArray{"Станислав Лем"}}
+	poems := entities.Book{BookID: uuid.New(), Title: "Стихотворения", Owned: true}
+	books := []entities.Book{dune1, dune2, eden, solaris, poems}
+	ratings := []entities.Rating{
+		{BookID: dune1.BookID, UserID: userID, Score: 5},
+		// Borrowed from a friend: it tells about the taste only.
+		{BookID: solaris.BookID, UserID: userID, Score: 5},
+		{BookID: poems.BookID, UserID: partnerID, Score: 5},
+	}
+
+	t.Run("unread owned books with reasons", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := NewUseCase(repo, households)
+		member(households, userID, householdID)
+
+		repo.On("GetBooks", mock.Anything, householdID).Return(books, nil)
+		repo.On("GetRatings", mock.Anything, householdID).Return(ratings, nil)
+		repo.On("GetStartedBooks", mock.Anything, householdID, userID).Return([]uuid.UUID{}, nil)
+
+		result, err := u.GetRecommendations(context.Background(), userID, dtos.ListRecommendationsRequest{})
+
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		assert.Equal(t, dune2.BookID, result[0].BookID)
+		assert.Contains(t, result[0].Reason, "Следующая книга серии")
+		assert.Equal(t, eden.BookID, result[1].BookID)
+		assert.Equal(t, poems.BookID, result[2].BookID)
+		assert.Equal(t, []string{}, result[2].Authors)
+	})
+
+	t.Run("books being read and the limit", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := NewUseCase(repo, households)
+		member(households, userID, householdID)
+
+		repo.On("GetBooks", mock.Anything, householdID).Return(books, nil)
+		repo.On("GetRatings", mock.Anything, householdID).Return(ratings, nil)
+		repo.On("GetStartedBooks", mock.Anything, householdID, userID).Return([]uuid.UUID{dune2.BookID}, nil)
+
+		result, err := u.GetRecommendations(context.Background(), userID, dtos.ListRecommendationsRequest{Limit: 1})
+
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, eden.BookID, result[0].BookID)
+	})
+
+	t.Run("user outside a household", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := NewUseCase(repo, households)
+		households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.GetRecommendations(context.Background(), userID, dtos.ListRecommendationsRequest{})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdNotFound)
+	})
+}
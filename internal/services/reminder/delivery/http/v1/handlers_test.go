@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"context"
+	"home-library/internal/services/reminder/dtos"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) GetPreferences(ctx context.Context, userID uuid.UUID) (*dtos.PreferencesResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.PreferencesResponse), args.Error(1)
+}
+
+func (m *MockUseCase) UpdatePreferences(ctx context.Context, userID uuid.UUID, payload dtos.PreferencesRequest) (*dtos.PreferencesResponse, error) {
+	args := m.Called(ctx, userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.PreferencesResponse), args.Error(1)
+}
+
+func (m *MockUseCase) Remind(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func newContext(e *echo.Echo, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPut, "/reminders/preferences", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestUpdatePreferences(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully update", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+		payload := dtos.PreferencesRequest{DaysBefore: 3, QuietFrom: "23:00", QuietTo: "08:00"}
+
+		mockUseCase.On("UpdatePreferences", mock.Anything, userID, payload).
+			Return(&dtos.PreferencesResponse{DaysBefore: 3, QuietFrom: "23:00", QuietTo: "08:00"}, nil)
+
+		c, rec := newContext(e, `{"days_before": 3, "quiet_from": "23:00", "quiet_to": "08:00"}`, userID)
+		err := h.UpdatePreferences(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("invalid time of day", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{"days_before": 3, "quiet_from": "25:00", "quiet_to": "08:00"}`, uuid.New())
+		err := h.UpdatePreferences(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{}`, uuid.Nil)
+		err := h.UpdatePreferences(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
@@ -0,0 +1,8 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) ReminderRoutes(domain *echo.Group) {
+	domain.GET("/reminders/preferences", h.GetPreferences)
+	domain.PUT("/reminders/preferences", h.UpdatePreferences)
+}
@@ -0,0 +1,50 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/pkg/reminders"
+	"time"
+)
+
+// PreferencesRequest sets how a user is reminded of their loans. Quiet hours
+// are times of day such as "22:00", in the library's time zone; equal times
+// mean no quiet hours.
+type PreferencesRequest struct {
+	Disabled   bool   `json:"disabled"`
+	DaysBefore int    `json:"days_before" validate:"gte=0,lte=30"`
+	QuietFrom  string `json:"quiet_from" validate:"required,datetime=15:04"`
+	QuietTo    string `json:"quiet_to" validate:"required,datetime=15:04"`
+}
+
+func (r *PreferencesRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type PreferencesResponse struct {
+	Disabled   bool   `json:"disabled"`
+	DaysBefore int    `json:"days_before"`
+	QuietFrom  string `json:"quiet_from"`
+	QuietTo    string `json:"quiet_to"`
+}
+
+func NewPreferencesResponse(p reminders.Preferences) PreferencesResponse {
+	return PreferencesResponse{
+		Disabled:   p.Disabled,
+		DaysBefore: p.DaysBefore,
+		QuietFrom:  p.QuietFrom.String(),
+		QuietTo:    p.QuietTo.String(),
+	}
+}
+
+// Notice is the payload of the reminder notifications. Borrower tells
+// whether the user reminded is the borrower, or the member who lent the
+// copy to someone without an account.
+type Notice struct {
+	LoanID       uuid.UUID `json:"loan_id"`
+	Title        string    `json:"title"`
+	BorrowerName string    `json:"borrower_name"`
+	Borrower     bool      `json:"borrower"`
+	DueAt        time.Time `json:"due_at"`
+}
@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Loan is an open loan with a due date, as reminders see it. The borrower is
+// reminded when they have an account; otherwise the member who lent the copy
+// is, so they can chase it.
+type Loan struct {
+	LoanID       uuid.UUID `db:"loan_id"`
+	RecipientID  uuid.UUID `db:"recipient_id"`
+	ToBorrower   bool      `db:"to_borrower"`
+	Title        string    `db:"title"`
+	BorrowerName string    `db:"borrower_name"`
+	DueAt        time.Time `db:"due_at"`
+}
+
+type Preferences struct {
+	UserID     uuid.UUID `db:"user_id"`
+	Disabled   bool      `db:"disabled"`
+	DaysBefore int       `db:"days_before"`
+	// QuietFrom and QuietTo are minutes after midnight.
+	QuietFrom int       `db:"quiet_from"`
+	QuietTo   int       `db:"quiet_to"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Sent records a reminder delivered to a user.
+type Sent struct {
+	Key    string    `db:"key"`
+	LoanID uuid.UUID `db:"loan_id"`
+	UserID uuid.UUID `db:"user_id"`
+	SentAt time.Time `db:"sent_at"`
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/reminder/entities"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	// GetLoans lists the open loans due before the time.
+	GetLoans(ctx context.Context, before time.Time) ([]entities.Loan, error)
+	GetPreferences(ctx context.Context, userIDs []uuid.UUID) ([]entities.Preferences, error)
+	SavePreferences(ctx context.Context, preferences *entities.Preferences) error
+	// GetSent lists the keys of the reminders sent for the loans.
+	GetSent(ctx context.Context, loanIDs []uuid.UUID) ([]string, error)
+	// RecordSent records a reminder and reports false if it was already
+	// recorded, by a run that got there first.
+	RecordSent(ctx context.Context, sent *entities.Sent) (bool, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) GetLoans(ctx context.Context, before time.Time) ([]entities.Loan, error) {
+	loans := make([]entities.Loan, 0)
+	query := `
+		SELECT l.loan_id, COALESCE(l.borrower_user_id, l.lent_by) AS recipient_id,
+			l.borrower_user_id IS NOT NULL AS to_borrower, b.title, l.borrower_name, l.due_at
+		FROM loans l
+		JOIN copies c ON c.copy_id = l.copy_id
+		JOIN books b ON b.book_id = c.book_id
+		WHERE l.returned_at IS NULL AND l.due_at < $1
+	`
+
+	err := r.db.SelectContext(ctx, &loans, query, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return loans, nil
+}
+
+func (r *repository) GetPreferences(ctx context.Context, userIDs []uuid.UUID) ([]entities.Preferences, error) {
+	preferences := make([]entities.Preferences, 0)
+	query := `SELECT * FROM reminder_preferences WHERE user_id = ANY($1::uuid[])`
+
+	err := r.db.SelectContext(ctx, &preferences, query, idArray(userIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func (r *repository) SavePreferences(ctx context.Context, preferences *entities.Preferences) error {
+	query := `
+		INSERT INTO reminder_preferences (user_id, disabled, days_before, quiet_from, quiet_to, updated_at)
+		VALUES (:user_id, :disabled, :days_before, :quiet_from, :quiet_to, :updated_at)
+		ON CONFLICT (user_id) DO UPDATE SET
+			disabled = EXCLUDED.disabled, days_before = EXCLUDED.days_before,
+			quiet_from = EXCLUDED.quiet_from, quiet_to = EXCLUDED.quiet_to, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, preferences)
+	return err
+}
+
+func (r *repository) GetSent(ctx context.Context, loanIDs []uuid.UUID) ([]string, error) {
+	keys := make([]string, 0)
+	query := `SELECT key FROM sent_reminders WHERE loan_id = ANY($1::uuid[])`
+
+	err := r.db.SelectContext(ctx, &keys, query, idArray(loanIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *repository) RecordSent(ctx context.Context, sent *entities.Sent) (bool, error) {
+	query := `
+		INSERT INTO sent_reminders (key, loan_id, user_id, sent_at)
+		VALUES (:key, :loan_id, :user_id, :sent_at)
+		ON CONFLICT (key) DO NOTHING
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, sent)
+	if err != nil {
+		return false, err
+	}
+
+	recorded, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return recorded > 0, nil
+}
+
+func idArray(ids []uuid.UUID) pq.StringArray {
+	array := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		array[i] = id.String()
+	}
+	return array
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"home-library/internal/services/reminder/entities"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestRecordSent(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	sent := &entities.Sent{Key: "loan:overdue:0", LoanID: uuid.New(), UserID: uuid.New(), SentAt: time.Now()}
+
+	t.Run("first time", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO sent_reminders .+ ON CONFLICT \(key\) DO NOTHING`).
+			WithArgs(sent.Key, sent.LoanID, sent.UserID, sent.SentAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		recorded, err := repo.RecordSent(context.Background(), sent)
+
+		assert.NoError(t, err)
+		assert.True(t, recorded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already recorded", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO sent_reminders`).
+			WithArgs(sent.Key, sent.LoanID, sent.UserID, sent.SentAt).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		recorded, err := repo.RecordSent(context.Background(), sent)
+
+		assert.NoError(t, err)
+		assert.False(t, recorded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetLoans(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	before := time.Now()
+	loanID, userID := uuid.New(), uuid.New()
+
+	rows := sqlmock.NewRows([]string{"loan_id", "recipient_id", "to_borrower", "title", "borrower_name", "due_at"}).
+		AddRow(loanID, userID, false, "Dune", "Оля", before.Add(-time.Hour))
+	mock.ExpectQuery(`COALESCE\(l.borrower_user_id, l.lent_by\) AS recipient_id.+WHERE l.returned_at IS NULL AND l.due_at < \$1`).
+		WithArgs(before).
+		WillReturnRows(rows)
+
+	loans, err := repo.GetLoans(context.Background(), before)
+
+	assert.NoError(t, err)
+	assert.Len(t, loans, 1)
+	assert.Equal(t, userID, loans[0].RecipientID)
+	assert.False(t, loans[0].ToBorrower)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
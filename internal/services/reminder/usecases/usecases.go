@@ -0,0 +1,196 @@
+package usecases
+
+import (
+	"context"
+	"home-library/internal/services/reminder/dtos"
+	"home-library/internal/services/reminder/entities"
+	"home-library/internal/services/reminder/repository"
+	"home-library/pkg/reminders"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// NotificationPrefix is followed by the kind of a loan reminder in the type
+// of its notification.
+const NotificationPrefix = "loan_reminder."
+
+// maxDaysBefore is the furthest ahead a user may ask to be reminded; loans
+// due later are not looked at.
+const maxDaysBefore = 30
+
+// Notifier tells users about changes that concern them; the notification
+// use case is the implementation.
+type Notifier interface {
+	Notify(ctx context.Context, userIDs []uuid.UUID, typ string, payload any) error
+}
+
+type UseCase interface {
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*dtos.PreferencesResponse, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, payload dtos.PreferencesRequest) (*dtos.PreferencesResponse, error)
+	// Remind sends the loan reminders that are due and not sent yet; the
+	// scheduler calls it.
+	Remind(ctx context.Context) error
+}
+
+type useCase struct {
+	r        repository.Repository
+	notifier Notifier
+	tx       transaction.Transactor
+	timeZone string
+	now      func() time.Time
+}
+
+func NewUseCase(r repository.Repository, notifier Notifier, tx transaction.Transactor, timeZone string) UseCase {
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	return &useCase{r: r, notifier: notifier, tx: tx, timeZone: timeZone, now: time.Now}
+}
+
+func (u *useCase) GetPreferences(ctx context.Context, userID uuid.UUID) (*dtos.PreferencesResponse, error) {
+	preferences, err := u.preferences(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	response := dtos.NewPreferencesResponse(preferences[userID.String()])
+	return &response, nil
+}
+
+func (u *useCase) UpdatePreferences(ctx context.Context, userID uuid.UUID, payload dtos.PreferencesRequest) (*dtos.PreferencesResponse, error) {
+	quietFrom, err := reminders.ParseClock(payload.QuietFrom)
+	if err != nil {
+		return nil, err
+	}
+	quietTo, err := reminders.ParseClock(payload.QuietTo)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := &entities.Preferences{
+		UserID:     userID,
+		Disabled:   payload.Disabled,
+		DaysBefore: payload.DaysBefore,
+		QuietFrom:  int(quietFrom),
+		QuietTo:    int(quietTo),
+		UpdatedAt:  u.now(),
+	}
+	if err := u.r.SavePreferences(ctx, preferences); err != nil {
+		return nil, err
+	}
+
+	response := dtos.NewPreferencesResponse(toPreferences(*preferences))
+	return &response, nil
+}
+
+func (u *useCase) Remind(ctx context.Context) error {
+	location, err := time.LoadLocation(u.timeZone)
+	if err != nil {
+		return err
+	}
+	now := u.now()
+
+	loans, err := u.r.GetLoans(ctx, now.AddDate(0, 0, maxDaysBefore+1))
+	if err != nil {
+		return err
+	}
+	if len(loans) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]entities.Loan, len(loans))
+	loanIDs := make([]uuid.UUID, len(loans))
+	recipients := make([]uuid.UUID, len(loans))
+	planned := make([]reminders.Loan, len(loans))
+	for i, loan := range loans {
+		byID[loan.LoanID.String()] = loan
+		loanIDs[i] = loan.LoanID
+		recipients[i] = loan.RecipientID
+		planned[i] = reminders.Loan{
+			ID:         loan.LoanID.String(),
+			BorrowerID: loan.RecipientID.String(),
+			Title:      loan.Title,
+			DueAt:      loan.DueAt,
+		}
+	}
+
+	preferences, err := u.preferences(ctx, recipients)
+	if err != nil {
+		return err
+	}
+
+	keys, err := u.r.GetSent(ctx, loanIDs)
+	if err != nil {
+		return err
+	}
+	sent := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		sent[key] = true
+	}
+
+	for _, reminder := range reminders.Plan(planned, preferences, func(key string) bool { return sent[key] }, now, location) {
+		// One failed reminder should not hold back the others; it is tried
+		// again on the next run.
+		if err := u.send(ctx, byID[reminder.LoanID], reminder, now); err != nil {
+			log.Error().Err(err).Str("loan_id", reminder.LoanID).Msg("failed to send loan reminder")
+		}
+	}
+
+	return nil
+}
+
+// send records the reminder and notifies its recipient in one transaction,
+// so that it is neither lost nor sent twice.
+func (u *useCase) send(ctx context.Context, loan entities.Loan, reminder reminders.Reminder, now time.Time) error {
+	return u.tx.Do(ctx, func(ctx context.Context) error {
+		recorded, err := u.r.RecordSent(ctx, &entities.Sent{
+			Key:    reminder.Key,
+			LoanID: loan.LoanID,
+			UserID: loan.RecipientID,
+			SentAt: now,
+		})
+		if err != nil || !recorded {
+			return err
+		}
+
+		notice := dtos.Notice{
+			LoanID:       loan.LoanID,
+			Title:        loan.Title,
+			BorrowerName: loan.BorrowerName,
+			Borrower:     loan.ToBorrower,
+			DueAt:        loan.DueAt,
+		}
+		return u.notifier.Notify(ctx, []uuid.UUID{loan.RecipientID}, NotificationPrefix+string(reminder.Kind), notice)
+	})
+}
+
+// preferences returns the preferences of each user by ID, the defaults for
+// those who set none.
+func (u *useCase) preferences(ctx context.Context, userIDs []uuid.UUID) (map[string]reminders.Preferences, error) {
+	stored, err := u.r.GetPreferences(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]reminders.Preferences, len(userIDs))
+	for _, userID := range userIDs {
+		result[userID.String()] = reminders.DefaultPreferences
+	}
+	for _, preferences := range stored {
+		result[preferences.UserID.String()] = toPreferences(preferences)
+	}
+
+	return result, nil
+}
+
+func toPreferences(p entities.Preferences) reminders.Preferences {
+	return reminders.Preferences{
+		Disabled:   p.Disabled,
+		DaysBefore: p.DaysBefore,
+		QuietFrom:  reminders.Clock(p.QuietFrom),
+		QuietTo:    reminders.Clock(p.QuietTo),
+	}
+}
@@ -0,0 +1,179 @@
+package usecases
+
+import (
+	"context"
+	"home-library/internal/services/reminder/dtos"
+	"home-library/internal/services/reminder/entities"
+	"home-library/pkg/reminders"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetLoans(ctx context.Context, before time.Time) ([]entities.Loan, error) {
+	args := m.Called(ctx, before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Loan), args.Error(1)
+}
+
+func (m *MockRepository) GetPreferences(ctx context.Context, userIDs []uuid.UUID) ([]entities.Preferences, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Preferences), args.Error(1)
+}
+
+func (m *MockRepository) SavePreferences(ctx context.Context, preferences *entities.Preferences) error {
+	return m.Called(ctx, preferences).Error(0)
+}
+
+func (m *MockRepository) GetSent(ctx context.Context, loanIDs []uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, loanIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockRepository) RecordSent(ctx context.Context, sent *entities.Sent) (bool, error) {
+	args := m.Called(ctx, sent)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, userIDs []uuid.UUID, typ string, payload any) error {
+	return m.Called(ctx, userIDs, typ, payload).Error(0)
+}
+
+// passthroughTx runs the unit of work directly.
+type passthroughTx struct{}
+
+func (passthroughTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type mocks struct {
+	repo     *MockRepository
+	notifier *MockNotifier
+}
+
+// newUseCase returns a use case whose clock reads now, in Moscow time.
+func newUseCase(now time.Time) (UseCase, mocks) {
+	m := mocks{new(MockRepository), new(MockNotifier)}
+	u := NewUseCase(m.repo, m.notifier, passthroughTx{}, "Europe/Moscow").(*useCase)
+	u.now = func() time.Time { return now }
+	return u, m
+}
+
+func TestRemind(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, moscow)
+
+	t.Run("due soon and overdue loans are reminded once", func(t *testing.T) {
+		u, m := newUseCase(now)
+		borrower, lender := uuid.New(), uuid.New()
+		soon := entities.Loan{LoanID: uuid.New(), RecipientID: borrower, ToBorrower: true, Title: "Dune", DueAt: now.Add(24 * time.Hour)}
+		overdue := entities.Loan{LoanID: uuid.New(), RecipientID: lender, Title: "Solaris", BorrowerName: "Оля", DueAt: now.AddDate(0, 0, -3)}
+		sentAlready := entities.Loan{LoanID: uuid.New(), RecipientID: borrower, ToBorrower: true, Title: "Пикник на обочине", DueAt: now.AddDate(0, 0, -10)}
+
+		m.repo.On("GetLoans", mock.Anything, now.AddDate(0, 0, maxDaysBefore+1)).Return([]entities.Loan{soon, overdue, sentAlready}, nil)
+		m.repo.On("GetPreferences", mock.Anything, mock.Anything).Return([]entities.Preferences{}, nil)
+		m.repo.On("GetSent", mock.Anything, []uuid.UUID{soon.LoanID, overdue.LoanID, sentAlready.LoanID}).
+			Return([]string{sentAlready.LoanID.String() + ":overdue:1"}, nil)
+		m.repo.On("RecordSent", mock.Anything, mock.Anything).Return(true, nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{borrower}, "loan_reminder.due_soon", dtos.Notice{
+			LoanID: soon.LoanID, Title: "Dune", Borrower: true, DueAt: soon.DueAt,
+		}).Return(nil)
+		m.notifier.On("Notify", mock.Anything, []uuid.UUID{lender}, "loan_reminder.overdue", dtos.Notice{
+			LoanID: overdue.LoanID, Title: "Solaris", BorrowerName: "Оля", DueAt: overdue.DueAt,
+		}).Return(nil)
+
+		err := u.Remind(context.Background())
+
+		require.NoError(t, err)
+		m.notifier.AssertNumberOfCalls(t, "Notify", 2)
+	})
+
+	t.Run("reminder recorded by another run is not sent", func(t *testing.T) {
+		u, m := newUseCase(now)
+		loan := entities.Loan{LoanID: uuid.New(), RecipientID: uuid.New(), ToBorrower: true, Title: "Dune", DueAt: now.Add(-time.Hour)}
+
+		m.repo.On("GetLoans", mock.Anything, mock.Anything).Return([]entities.Loan{loan}, nil)
+		m.repo.On("GetPreferences", mock.Anything, mock.Anything).Return([]entities.Preferences{}, nil)
+		m.repo.On("GetSent", mock.Anything, mock.Anything).Return([]string{}, nil)
+		m.repo.On("RecordSent", mock.Anything, mock.MatchedBy(func(s *entities.Sent) bool {
+			return s.Key == loan.LoanID.String()+":overdue:0" && s.UserID == loan.RecipientID
+		})).Return(false, nil)
+
+		err := u.Remind(context.Background())
+
+		require.NoError(t, err)
+		m.notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("quiet hours and opt-outs are respected", func(t *testing.T) {
+		late := time.Date(2026, 10, 19, 23, 0, 0, 0, moscow)
+		u, m := newUseCase(late)
+		quiet, optedOut := uuid.New(), uuid.New()
+
+		m.repo.On("GetLoans", mock.Anything, mock.Anything).Return([]entities.Loan{
+			{LoanID: uuid.New(), RecipientID: quiet, DueAt: late.Add(-time.Hour)},
+			{LoanID: uuid.New(), RecipientID: optedOut, DueAt: late.Add(-time.Hour)},
+		}, nil)
+		m.repo.On("GetPreferences", mock.Anything, mock.Anything).Return([]entities.Preferences{
+			{UserID: optedOut, Disabled: true},
+		}, nil)
+		m.repo.On("GetSent", mock.Anything, mock.Anything).Return([]string{}, nil)
+
+		err := u.Remind(context.Background())
+
+		require.NoError(t, err)
+		m.repo.AssertNotCalled(t, "RecordSent", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPreferences(t *testing.T) {
+	t.Run("defaults until set", func(t *testing.T) {
+		u, m := newUseCase(time.Now())
+		userID := uuid.New()
+
+		m.repo.On("GetPreferences", mock.Anything, []uuid.UUID{userID}).Return([]entities.Preferences{}, nil)
+
+		preferences, err := u.GetPreferences(context.Background(), userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, dtos.NewPreferencesResponse(reminders.DefaultPreferences), *preferences)
+		assert.Equal(t, "22:00", preferences.QuietFrom)
+	})
+
+	t.Run("quiet hours are stored in minutes", func(t *testing.T) {
+		u, m := newUseCase(time.Now())
+		userID := uuid.New()
+
+		m.repo.On("SavePreferences", mock.Anything, mock.MatchedBy(func(p *entities.Preferences) bool {
+			return p.UserID == userID && p.DaysBefore == 3 && p.QuietFrom == 23*60+30 && p.QuietTo == 7*60
+		})).Return(nil)
+
+		preferences, err := u.UpdatePreferences(context.Background(), userID, dtos.PreferencesRequest{
+			DaysBefore: 3, QuietFrom: "23:30", QuietTo: "07:00",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "23:30", preferences.QuietFrom)
+	})
+}
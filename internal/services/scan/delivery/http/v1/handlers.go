@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/scan/dtos"
+	"home-library/internal/services/scan/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) Scan(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.ScanRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	result, err := h.u.Scan(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to resolve scanned code")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *handler) CreateSession(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	payload, err := bindSession(c)
+	if err != nil || payload == nil {
+		return err
+	}
+
+	session, err := h.u.CreateSession(c.Request().Context(), userID, *payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to create scan session")
+	}
+
+	return c.JSON(http.StatusCreated, session)
+}
+
+func (h *handler) GetSession(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	session, err := h.u.GetSession(c.Request().Context(), userID, sessionID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get scan session")
+	}
+
+	return c.JSON(http.StatusOK, session)
+}
+
+func (h *handler) UpdateSession(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	payload, err := bindSession(c)
+	if err != nil || payload == nil {
+		return err
+	}
+
+	session, err := h.u.UpdateSession(c.Request().Context(), userID, sessionID, *payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to update scan session")
+	}
+
+	return c.JSON(http.StatusOK, session)
+}
+
+func (h *handler) DeleteSession(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.DeleteSession(c.Request().Context(), userID, sessionID); err != nil {
+		return h.handleError(c, err, "failed to delete scan session")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// bindSession reads a session request. A nil request means the response has
+// already been written.
+func bindSession(c echo.Context) (*dtos.SessionRequest, error) {
+	var payload dtos.SessionRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return nil, c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return nil, c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	return &payload, nil
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrScanUnrecognized):
+		return c.JSON(http.StatusUnprocessableEntity, dtos.NewErrorResponse(http.StatusUnprocessableEntity, "Код не распознан", nil))
+	case errors.Is(err, customErrors.ErrScanNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "По этому коду ничего не найдено", nil))
+	case errors.Is(err, customErrors.ErrScanSessionNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Сессия сканирования не найдена или истекла", nil))
+	case errors.Is(err, customErrors.ErrScanNoShelf):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Сначала отсканируйте полку", nil))
+	case errors.Is(err, customErrors.ErrScanNotOnLoan):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Экземпляр не выдан", nil))
+	case errors.Is(err, customErrors.ErrCopyOnLoan):
+		return c.JSON(http.StatusConflict, dtos.NewErrorResponse(http.StatusConflict, "Экземпляр уже выдан", nil))
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	case errors.Is(err, customErrors.ErrLocationNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Место хранения не найдено", nil))
+	case errors.Is(err, customErrors.ErrCopyNotFound), errors.Is(err, customErrors.ErrLoanNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Экземпляр не найден", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
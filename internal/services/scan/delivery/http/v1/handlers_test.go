@@ -0,0 +1,185 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"home-library/internal/services/scan/dtos"
+	"home-library/internal/services/scan/entities"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/scancode"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) Scan(ctx context.Context, userID uuid.UUID, payload dtos.ScanRequest) (*dtos.ScanResponse, error) {
+	args := m.Called(ctx, userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.ScanResponse), args.Error(1)
+}
+
+func (m *MockUseCase) CreateSession(ctx context.Context, userID uuid.UUID, payload dtos.SessionRequest) (*dtos.SessionResponse, error) {
+	args := m.Called(ctx, userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.SessionResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*dtos.SessionResponse, error) {
+	args := m.Called(ctx, userID, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.SessionResponse), args.Error(1)
+}
+
+func (m *MockUseCase) UpdateSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, payload dtos.SessionRequest) (*dtos.SessionResponse, error) {
+	args := m.Called(ctx, userID, sessionID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.SessionResponse), args.Error(1)
+}
+
+func (m *MockUseCase) DeleteSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	return m.Called(ctx, userID, sessionID).Error(0)
+}
+
+func newContext(e *echo.Echo, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestScan(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully scan", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Scan", mock.Anything, userID, dtos.ScanRequest{Code: "9785170987658"}).Return(&dtos.ScanResponse{
+			Kind:    scancode.KindISBN,
+			Value:   "9785170987658",
+			Actions: []dtos.Action{{Name: "add_to_wishlist", Method: http.MethodPost, Href: "/api/v1/wishlist"}},
+		}, nil)
+
+		c, rec := newContext(e, `{"code": "9785170987658"}`, userID)
+		err := h.Scan(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"add_to_wishlist"`)
+	})
+
+	t.Run("empty code", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{"code": ""}`, uuid.New())
+		err := h.Scan(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Ошибка валидации")
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{"code": "9785170987658"}`, uuid.Nil)
+		err := h.Scan(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{"unrecognized", customErrors.ErrScanUnrecognized, http.StatusUnprocessableEntity},
+		{"not found", customErrors.ErrScanNotFound, http.StatusNotFound},
+		{"session expired", customErrors.ErrScanSessionNotFound, http.StatusNotFound},
+		{"no shelf scanned", customErrors.ErrScanNoShelf, http.StatusConflict},
+		{"copy already lent", customErrors.ErrCopyOnLoan, http.StatusConflict},
+		{"viewer", customErrors.ErrHouseholdForbidden, http.StatusForbidden},
+		{"internal error", errors.New("database is down"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			h := NewHandler(mockUseCase)
+			userID := uuid.New()
+
+			mockUseCase.On("Scan", mock.Anything, userID, dtos.ScanRequest{Code: "code"}).Return(nil, test.err)
+
+			c, rec := newContext(e, `{"code": "code"}`, userID)
+			err := h.Scan(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestCreateSession(t *testing.T) {
+	e := echo.New()
+
+	t.Run("lend session", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, sessionID := uuid.New(), uuid.New()
+
+		mockUseCase.On("CreateSession", mock.Anything, userID, dtos.SessionRequest{Mode: entities.ModeLend, BorrowerName: "Аня"}).
+			Return(&dtos.SessionResponse{SessionID: sessionID, Mode: entities.ModeLend, BorrowerName: "Аня"}, nil)
+
+		c, rec := newContext(e, `{"mode": "lend", "borrower_name": "Аня"}`, userID)
+		err := h.CreateSession(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), sessionID.String())
+	})
+
+	t.Run("lend session without a borrower", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{"mode": "lend"}`, uuid.New())
+		err := h.CreateSession(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "required_if")
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, `{"mode": "burn"}`, uuid.New())
+		err := h.CreateSession(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
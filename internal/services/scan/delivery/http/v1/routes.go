@@ -0,0 +1,11 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) ScanRoutes(domain *echo.Group) {
+	domain.POST("/scan", h.Scan)
+	domain.POST("/scan/sessions", h.CreateSession)
+	domain.GET("/scan/sessions/:session_id", h.GetSession)
+	domain.PUT("/scan/sessions/:session_id", h.UpdateSession)
+	domain.DELETE("/scan/sessions/:session_id", h.DeleteSession)
+}
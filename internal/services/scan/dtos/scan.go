@@ -0,0 +1,132 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/scan/entities"
+	"home-library/pkg/scancode"
+	"time"
+)
+
+type ScanRequest struct {
+	Code string `json:"code" validate:"required,max=512"`
+	// SessionID carries the shelf scanned last and the mode over to this
+	// scan.
+	SessionID *uuid.UUID `json:"session_id"`
+}
+
+func (r *ScanRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// Action is something the client can do next with the scanned item. It is a
+// ready-made request against this API, so scanning apps need no knowledge of
+// the individual endpoints.
+type Action struct {
+	Name   string            `json:"name"`
+	Method string            `json:"method"`
+	Href   string            `json:"href"`
+	Body   map[string]string `json:"body,omitempty"`
+}
+
+type EbookMatch struct {
+	FileID  uuid.UUID `json:"file_id"`
+	Title   string    `json:"title"`
+	Authors []string  `json:"authors"`
+	Format  string    `json:"format"`
+}
+
+type WishlistMatch struct {
+	ItemID       uuid.UUID `json:"item_id"`
+	OwnerID      uuid.UUID `json:"owner_id"`
+	Title        string    `json:"title"`
+	IsOwn        bool      `json:"is_own"`
+	IsReserved   bool      `json:"is_reserved"`
+	ReservedByMe bool      `json:"reserved_by_me"`
+}
+
+type CopyMatch struct {
+	CopyID       uuid.UUID  `json:"copy_id"`
+	ShortID      string     `json:"short_id"`
+	BookID       uuid.UUID  `json:"book_id"`
+	Title        string     `json:"title"`
+	Authors      []string   `json:"authors"`
+	LocationID   *uuid.UUID `json:"location_id"`
+	LocationName *string    `json:"location_name"`
+	LoanID       *uuid.UUID `json:"loan_id"`
+	BorrowerName *string    `json:"borrower_name"`
+	DueAt        *time.Time `json:"due_at"`
+}
+
+func NewCopyMatch(copy entities.Copy) *CopyMatch {
+	return &CopyMatch{
+		CopyID:       copy.CopyID,
+		ShortID:      copy.ShortID,
+		BookID:       copy.BookID,
+		Title:        copy.Title,
+		Authors:      append([]string{}, copy.Authors...),
+		LocationID:   copy.LocationID,
+		LocationName: copy.LocationName,
+		LoanID:       copy.LoanID,
+		BorrowerName: copy.BorrowerName,
+		DueAt:        copy.DueAt,
+	}
+}
+
+type LocationMatch struct {
+	LocationID uuid.UUID `json:"location_id"`
+	ShortID    string    `json:"short_id"`
+	Name       string    `json:"name"`
+}
+
+type ScanResponse struct {
+	Kind          scancode.Kind   `json:"kind"`
+	Value         string          `json:"value"`
+	Ebooks        []EbookMatch    `json:"ebooks"`
+	WishlistItems []WishlistMatch `json:"wishlist_items"`
+	Copy          *CopyMatch      `json:"copy,omitempty"`
+	Location      *LocationMatch  `json:"location,omitempty"`
+	// Performed is the mode of the session when the scan already moved,
+	// lent or returned the copy.
+	Performed entities.Mode    `json:"performed,omitempty"`
+	Session   *SessionResponse `json:"session,omitempty"`
+	Actions   []Action         `json:"actions"`
+}
+
+// SessionRequest sets what a scan session does with the copies scanned in
+// it. Lending needs the borrower; moving needs a shelf, which may also be
+// given by scanning its label.
+type SessionRequest struct {
+	Mode         entities.Mode `json:"mode" validate:"omitempty,oneof=lookup move lend return"`
+	LocationID   *uuid.UUID    `json:"location_id"`
+	BorrowerName string        `json:"borrower_name" validate:"required_if=Mode lend,max=255"`
+	DueAt        *time.Time    `json:"due_at"`
+}
+
+func (r *SessionRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type SessionResponse struct {
+	SessionID    uuid.UUID     `json:"session_id"`
+	Mode         entities.Mode `json:"mode"`
+	LocationID   *uuid.UUID    `json:"location_id"`
+	LocationName *string       `json:"location_name"`
+	BorrowerName string        `json:"borrower_name"`
+	DueAt        *time.Time    `json:"due_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+func NewSessionResponse(session *entities.Session) *SessionResponse {
+	return &SessionResponse{
+		SessionID:    session.SessionID,
+		Mode:         session.Mode,
+		LocationID:   session.LocationID,
+		LocationName: session.LocationName,
+		BorrowerName: session.BorrowerName,
+		DueAt:        session.DueAt,
+		UpdatedAt:    session.UpdatedAt,
+	}
+}
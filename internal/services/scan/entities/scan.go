@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Mode is what a scan session does with each copy scanned in it.
+type Mode string
+
+const (
+	// ModeLookup only shows the copy and what can be done with it.
+	ModeLookup Mode = "lookup"
+	// ModeMove puts the copy in the shelf scanned last.
+	ModeMove Mode = "move"
+	// ModeLend lends the copy to the borrower of the session.
+	ModeLend Mode = "lend"
+	// ModeReturn takes the copy back from its borrower.
+	ModeReturn Mode = "return"
+)
+
+// Session is the context kept between the scans of one device, so that a
+// shelf can be scanned once and then the copies put on it one after another.
+type Session struct {
+	SessionID    uuid.UUID  `db:"session_id"`
+	UserID       uuid.UUID  `db:"user_id"`
+	HouseholdID  uuid.UUID  `db:"household_id"`
+	Mode         Mode       `db:"mode"`
+	LocationID   *uuid.UUID `db:"location_id"`
+	BorrowerName string     `db:"borrower_name"`
+	DueAt        *time.Time `db:"due_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+	// LocationName is read along with the session and not stored.
+	LocationName *string `db:"location_name"`
+}
+
+func NewSession(userID uuid.UUID, householdID uuid.UUID) *Session {
+	now := time.Now()
+	return &Session{
+		SessionID:   uuid.New(),
+		UserID:      userID,
+		HouseholdID: householdID,
+		Mode:        ModeLookup,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Copy is a copy found by the short ID on its label, with where it is kept
+// and whom it is lent to.
+type Copy struct {
+	CopyID       uuid.UUID      `db:"copy_id"`
+	ShortID      string         `db:"short_id"`
+	BookID       uuid.UUID      `db:"book_id"`
+	Title        string         `db:"title"`
+	Authors      pq.StringArray `db:"authors"`
+	LocationID   *uuid.UUID     `db:"location_id"`
+	LocationName *string        `db:"location_name"`
+	LoanID       *uuid.UUID     `db:"loan_id"`
+	BorrowerName *string        `db:"borrower_name"`
+	DueAt        *time.Time     `db:"due_at"`
+}
+
+func (c *Copy) OnLoan() bool {
+	return c.LoanID != nil
+}
+
+// Location is a shelf found by the short ID on its label.
+type Location struct {
+	LocationID uuid.UUID `db:"location_id"`
+	ShortID    string    `db:"short_id"`
+	Name       string    `db:"name"`
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/scan/entities"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	GetCopy(ctx context.Context, householdID uuid.UUID, shortID string) (*entities.Copy, error)
+	GetLocation(ctx context.Context, householdID uuid.UUID, shortID string) (*entities.Location, error)
+	GetLocationByID(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*entities.Location, error)
+
+	CreateSession(ctx context.Context, session *entities.Session) error
+	// GetSession returns a session of the user that was used after since.
+	GetSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, since time.Time) (*entities.Session, error)
+	UpdateSession(ctx context.Context, session *entities.Session) error
+	DeleteSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+	DeleteSessionsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) GetCopy(ctx context.Context, householdID uuid.UUID, shortID string) (*entities.Copy, error) {
+	var copy entities.Copy
+	query := `
+		SELECT c.copy_id, c.short_id, c.book_id, b.title, b.authors, c.location_id, l.name AS location_name,
+			o.loan_id, o.borrower_name, o.due_at
+		FROM copies c
+		JOIN books b ON b.book_id = c.book_id
+		LEFT JOIN locations l ON l.location_id = c.location_id
+		LEFT JOIN loans o ON o.copy_id = c.copy_id AND o.returned_at IS NULL
+		WHERE c.household_id = $1 AND c.short_id = $2
+	`
+
+	err := r.db.GetContext(ctx, &copy, query, householdID, shortID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &copy, nil
+}
+
+func (r *repository) GetLocation(ctx context.Context, householdID uuid.UUID, shortID string) (*entities.Location, error) {
+	var location entities.Location
+	query := `SELECT location_id, short_id, name FROM locations WHERE household_id = $1 AND short_id = $2`
+
+	err := r.db.GetContext(ctx, &location, query, householdID, shortID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &location, nil
+}
+
+func (r *repository) GetLocationByID(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*entities.Location, error) {
+	var location entities.Location
+	query := `SELECT location_id, short_id, name FROM locations WHERE household_id = $1 AND location_id = $2`
+
+	err := r.db.GetContext(ctx, &location, query, householdID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &location, nil
+}
+
+func (r *repository) CreateSession(ctx context.Context, session *entities.Session) error {
+	query := `
+		INSERT INTO scan_sessions (
+			session_id, user_id, household_id, mode, location_id, borrower_name, due_at, created_at, updated_at
+		) VALUES (
+			:session_id, :user_id, :household_id, :mode, :location_id, :borrower_name, :due_at, :created_at, :updated_at
+		)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, session)
+	return err
+}
+
+func (r *repository) GetSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, since time.Time) (*entities.Session, error) {
+	var session entities.Session
+	// The household is matched too, so a session left over from a household
+	// the user has since left reads as gone.
+	query := `
+		SELECT s.*, l.name AS location_name
+		FROM scan_sessions s
+		JOIN household_members m ON m.household_id = s.household_id AND m.user_id = s.user_id
+		LEFT JOIN locations l ON l.household_id = s.household_id AND l.location_id = s.location_id
+		WHERE s.user_id = $1 AND s.session_id = $2 AND s.updated_at > $3
+	`
+
+	err := r.db.GetContext(ctx, &session, query, userID, sessionID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *repository) UpdateSession(ctx context.Context, session *entities.Session) error {
+	query := `
+		UPDATE scan_sessions
+		SET mode = :mode, location_id = :location_id, borrower_name = :borrower_name, due_at = :due_at,
+			updated_at = :updated_at
+		WHERE session_id = :session_id AND user_id = :user_id
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, session)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) DeleteSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM scan_sessions WHERE user_id = $1 AND session_id = $2`, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) DeleteSessionsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM scan_sessions WHERE updated_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestGetCopy(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	columns := []string{"copy_id", "short_id", "book_id", "title", "authors", "location_id", "location_name", "loan_id", "borrower_name", "due_at"}
+
+	t.Run("copy with its open loan", func(t *testing.T) {
+		householdID, copyID, loanID := uuid.New(), uuid.New(), uuid.New()
+		rows := sqlmock.NewRows(columns).
+			AddRow(copyID, "K7M2Q9XD", uuid.New(), "Dune", "{\"Frank Herbert\"}", nil, nil, loanID, "Аня", nil)
+
+		mock.ExpectQuery(`LEFT JOIN loans o ON o.copy_id = c.copy_id AND o.returned_at IS NULL WHERE c.household_id = \$1 AND c.short_id = \$2`).
+			WithArgs(householdID, "K7M2Q9XD").
+			WillReturnRows(rows)
+
+		copy, err := repo.GetCopy(context.Background(), householdID, "K7M2Q9XD")
+
+		assert.NoError(t, err)
+		assert.True(t, copy.OnLoan())
+		assert.Equal(t, "Аня", *copy.BorrowerName)
+		assert.Nil(t, copy.LocationID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetSession(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("expired session is not found", func(t *testing.T) {
+		userID, sessionID := uuid.New(), uuid.New()
+		since := time.Now().Add(-time.Hour)
+
+		mock.ExpectQuery(`FROM scan_sessions s JOIN household_members m .+ WHERE s.user_id = \$1 AND s.session_id = \$2 AND s.updated_at > \$3`).
+			WithArgs(userID, sessionID, since).
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetSession(context.Background(), userID, sessionID, since)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
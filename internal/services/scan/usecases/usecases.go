@@ -0,0 +1,409 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	catalogDtos "home-library/internal/services/catalog/dtos"
+	ebookEntities "home-library/internal/services/ebook/entities"
+	ebookRepository "home-library/internal/services/ebook/repository"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	loanDtos "home-library/internal/services/loan/dtos"
+	"home-library/internal/services/scan/dtos"
+	"home-library/internal/services/scan/entities"
+	"home-library/internal/services/scan/repository"
+	wishlistRepository "home-library/internal/services/wishlist/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/isbn"
+	"home-library/pkg/scancode"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const basePath = "/api/v1"
+
+// SessionTTL is how long a scan session lasts without being used.
+const SessionTTL = 12 * time.Hour
+
+type UseCase interface {
+	Scan(ctx context.Context, userID uuid.UUID, payload dtos.ScanRequest) (*dtos.ScanResponse, error)
+
+	CreateSession(ctx context.Context, userID uuid.UUID, payload dtos.SessionRequest) (*dtos.SessionResponse, error)
+	GetSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*dtos.SessionResponse, error)
+	UpdateSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, payload dtos.SessionRequest) (*dtos.SessionResponse, error)
+	DeleteSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error
+}
+
+// Shelver moves copies between locations; the catalog use case is one.
+type Shelver interface {
+	MoveCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload catalogDtos.MoveCopyRequest) error
+}
+
+// Lender lends copies and takes them back; the loan use case is one.
+type Lender interface {
+	LendCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload loanDtos.LendCopyRequest) (uuid.UUID, error)
+	ReturnLoan(ctx context.Context, userID uuid.UUID, loanID uuid.UUID) error
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	ebooks     ebookRepository.Repository
+	wishlist   wishlistRepository.Repository
+	shelver    Shelver
+	lender     Lender
+}
+
+func NewUseCase(
+	r repository.Repository,
+	households householdRepository.Repository,
+	ebooks ebookRepository.Repository,
+	wishlist wishlistRepository.Repository,
+	shelver Shelver,
+	lender Lender,
+) UseCase {
+	return &useCase{r: r, households: households, ebooks: ebooks, wishlist: wishlist, shelver: shelver, lender: lender}
+}
+
+func (u *useCase) Scan(ctx context.Context, userID uuid.UUID, payload dtos.ScanRequest) (*dtos.ScanResponse, error) {
+	code := scancode.Parse(payload.Code)
+
+	switch code.Kind {
+	case scancode.KindISBN:
+		return u.scanISBN(ctx, userID, code)
+	case scancode.KindCopy, scancode.KindShelf:
+		return u.scanLabel(ctx, userID, code, payload.SessionID)
+	default:
+		return nil, errors.ErrScanUnrecognized
+	}
+}
+
+// scanLabel resolves one of our own labels in the household of the user.
+// Within a session a shelf becomes the current one and a copy is handled as
+// the session's mode says.
+func (u *useCase) scanLabel(ctx context.Context, userID uuid.UUID, code scancode.Code, sessionID *uuid.UUID) (*dtos.ScanResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var session *entities.Session
+	if sessionID != nil {
+		session, err = u.session(ctx, userID, *sessionID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response := &dtos.ScanResponse{
+		Kind:          code.Kind,
+		Value:         code.Value,
+		Ebooks:        make([]dtos.EbookMatch, 0),
+		WishlistItems: make([]dtos.WishlistMatch, 0),
+		Actions:       make([]dtos.Action, 0),
+	}
+
+	if code.Kind == scancode.KindShelf {
+		err = u.scanShelf(ctx, member, code, session, response)
+	} else {
+		err = u.scanCopy(ctx, userID, member, code, session, response)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if session != nil {
+		session.UpdatedAt = time.Now()
+		if err := u.r.UpdateSession(ctx, session); err != nil {
+			return nil, mapNoRows(err, errors.ErrScanSessionNotFound)
+		}
+		response.Session = dtos.NewSessionResponse(session)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) scanShelf(ctx context.Context, member *householdEntities.Member, code scancode.Code, session *entities.Session, response *dtos.ScanResponse) error {
+	location, err := u.r.GetLocation(ctx, member.HouseholdID, code.Value)
+	if err != nil {
+		return mapNoRows(err, errors.ErrScanNotFound)
+	}
+
+	response.Location = &dtos.LocationMatch{LocationID: location.LocationID, ShortID: location.ShortID, Name: location.Name}
+	response.Actions = append(response.Actions, dtos.Action{
+		Name:   "list_books",
+		Method: http.MethodGet,
+		Href:   basePath + "/books?location_id=" + location.LocationID.String(),
+	})
+
+	if session != nil {
+		session.LocationID, session.LocationName = &location.LocationID, &location.Name
+	}
+	return nil
+}
+
+func (u *useCase) scanCopy(ctx context.Context, userID uuid.UUID, member *householdEntities.Member, code scancode.Code, session *entities.Session, response *dtos.ScanResponse) error {
+	copy, err := u.r.GetCopy(ctx, member.HouseholdID, code.Value)
+	if err != nil {
+		return mapNoRows(err, errors.ErrScanNotFound)
+	}
+
+	if session != nil && session.Mode != entities.ModeLookup {
+		if err := u.apply(ctx, userID, session, copy); err != nil {
+			return err
+		}
+		response.Performed = session.Mode
+	}
+
+	response.Copy = dtos.NewCopyMatch(*copy)
+	response.Actions = append(response.Actions, copyActions(copy, session)...)
+	return nil
+}
+
+// apply does to the copy what the session is for and updates the copy to
+// match.
+func (u *useCase) apply(ctx context.Context, userID uuid.UUID, session *entities.Session, copy *entities.Copy) error {
+	switch session.Mode {
+	case entities.ModeMove:
+		if session.LocationID == nil {
+			return errors.ErrScanNoShelf
+		}
+		if err := u.shelver.MoveCopy(ctx, userID, copy.CopyID, catalogDtos.MoveCopyRequest{LocationID: session.LocationID}); err != nil {
+			return err
+		}
+		copy.LocationID, copy.LocationName = session.LocationID, session.LocationName
+
+	case entities.ModeLend:
+		if copy.OnLoan() {
+			return errors.ErrCopyOnLoan
+		}
+		request := loanDtos.LendCopyRequest{BorrowerName: session.BorrowerName, DueAt: session.DueAt}
+		loanID, err := u.lender.LendCopy(ctx, userID, copy.CopyID, request)
+		if err != nil {
+			return err
+		}
+		copy.LoanID, copy.BorrowerName, copy.DueAt = &loanID, &session.BorrowerName, session.DueAt
+
+	case entities.ModeReturn:
+		if !copy.OnLoan() {
+			return errors.ErrScanNotOnLoan
+		}
+		if err := u.lender.ReturnLoan(ctx, userID, *copy.LoanID); err != nil {
+			return err
+		}
+		copy.LoanID, copy.BorrowerName, copy.DueAt = nil, nil, nil
+	}
+
+	return nil
+}
+
+// copyActions offers what can be done with the copy as it is now.
+func copyActions(copy *entities.Copy, session *entities.Session) []dtos.Action {
+	href := basePath + "/copies/" + copy.CopyID.String()
+	actions := []dtos.Action{{
+		Name:   "view_book",
+		Method: http.MethodGet,
+		Href:   basePath + "/books/" + copy.BookID.String(),
+	}}
+
+	if copy.OnLoan() {
+		actions = append(actions, dtos.Action{
+			Name:   "return_copy",
+			Method: http.MethodPost,
+			Href:   basePath + "/loans/" + copy.LoanID.String() + "/return",
+		})
+	} else {
+		action := dtos.Action{Name: "lend_copy", Method: http.MethodPost, Href: href + "/loans"}
+		if session != nil && session.BorrowerName != "" {
+			action.Body = map[string]string{"borrower_name": session.BorrowerName}
+		}
+		actions = append(actions, action)
+	}
+
+	if session != nil && session.LocationID != nil && !sameLocation(copy.LocationID, session.LocationID) {
+		actions = append(actions, dtos.Action{
+			Name:   "move_copy",
+			Method: http.MethodPut,
+			Href:   href + "/location",
+			Body:   map[string]string{"location_id": session.LocationID.String()},
+		})
+	}
+
+	return actions
+}
+
+func sameLocation(a *uuid.UUID, b *uuid.UUID) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+func (u *useCase) CreateSession(ctx context.Context, userID uuid.UUID, payload dtos.SessionRequest) (*dtos.SessionResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	session := entities.NewSession(userID, member.HouseholdID)
+	if err := u.applyRequest(ctx, session, payload); err != nil {
+		return nil, err
+	}
+
+	if err := u.r.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return dtos.NewSessionResponse(session), nil
+}
+
+func (u *useCase) GetSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*dtos.SessionResponse, error) {
+	session, err := u.session(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dtos.NewSessionResponse(session), nil
+}
+
+func (u *useCase) UpdateSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, payload dtos.SessionRequest) (*dtos.SessionResponse, error) {
+	session, err := u.session(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.applyRequest(ctx, session, payload); err != nil {
+		return nil, err
+	}
+	session.UpdatedAt = time.Now()
+
+	if err := u.r.UpdateSession(ctx, session); err != nil {
+		return nil, mapNoRows(err, errors.ErrScanSessionNotFound)
+	}
+
+	return dtos.NewSessionResponse(session), nil
+}
+
+func (u *useCase) DeleteSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	return mapNoRows(u.r.DeleteSession(ctx, userID, sessionID), errors.ErrScanSessionNotFound)
+}
+
+func (u *useCase) applyRequest(ctx context.Context, session *entities.Session, payload dtos.SessionRequest) error {
+	session.Mode = payload.Mode
+	if session.Mode == "" {
+		session.Mode = entities.ModeLookup
+	}
+	session.BorrowerName = strings.TrimSpace(payload.BorrowerName)
+	session.DueAt = payload.DueAt
+
+	session.LocationID, session.LocationName = nil, nil
+	if payload.LocationID != nil {
+		location, err := u.r.GetLocationByID(ctx, session.HouseholdID, *payload.LocationID)
+		if err != nil {
+			return mapNoRows(err, errors.ErrLocationNotFound)
+		}
+		session.LocationID, session.LocationName = &location.LocationID, &location.Name
+	}
+
+	return nil
+}
+
+func (u *useCase) session(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (*entities.Session, error) {
+	session, err := u.r.GetSession(ctx, userID, sessionID, time.Now().Add(-SessionTTL))
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrScanSessionNotFound)
+	}
+	return session, nil
+}
+
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+	}
+	return member, nil
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
+
+func (u *useCase) scanISBN(ctx context.Context, userID uuid.UUID, code scancode.Code) (*dtos.ScanResponse, error) {
+	// Both forms are looked up, as either may have been stored.
+	isbns := []string{code.Value}
+	if isbn10 := isbn.To10(code.Value); isbn10 != "" {
+		isbns = append(isbns, isbn10)
+	}
+
+	files, err := u.ebooks.FindSharedFiles(ctx, userID, ebookEntities.Filter{ISBNs: isbns})
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := u.wishlist.FindItemsByISBN(ctx, userID, isbns)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.ScanResponse{
+		Kind:          code.Kind,
+		Value:         code.Value,
+		Ebooks:        make([]dtos.EbookMatch, len(files)),
+		WishlistItems: make([]dtos.WishlistMatch, len(items)),
+		Actions:       make([]dtos.Action, 0),
+	}
+
+	for i, file := range files {
+		response.Ebooks[i] = dtos.EbookMatch{
+			FileID:  file.FileID,
+			Title:   file.Title,
+			Authors: append([]string{}, file.Authors...),
+			Format:  string(file.Format),
+		}
+		response.Actions = append(response.Actions, dtos.Action{
+			Name:   "download_ebook",
+			Method: http.MethodGet,
+			Href:   basePath + "/ebooks/" + file.FileID.String() + "/download",
+		})
+	}
+
+	wished := false
+	for i, item := range items {
+		match := dtos.WishlistMatch{
+			ItemID:       item.ItemID,
+			OwnerID:      item.UserID,
+			Title:        item.Title,
+			IsOwn:        item.UserID == userID,
+			IsReserved:   item.IsReserved(),
+			ReservedByMe: item.IsReserved() && *item.ReservedBy == userID,
+		}
+		response.WishlistItems[i] = match
+
+		href := basePath + "/wishlist/" + item.ItemID.String()
+		switch {
+		case match.IsOwn:
+			// Scanning a wished-for book usually means it has arrived.
+			wished = true
+			response.Actions = append(response.Actions, dtos.Action{Name: "remove_from_wishlist", Method: http.MethodDelete, Href: href})
+		case match.ReservedByMe:
+			response.Actions = append(response.Actions, dtos.Action{Name: "cancel_reservation", Method: http.MethodDelete, Href: href + "/reservation"})
+		case !match.IsReserved:
+			response.Actions = append(response.Actions, dtos.Action{Name: "reserve_wishlist_item", Method: http.MethodPost, Href: href + "/reservation"})
+		}
+	}
+
+	if !wished {
+		response.Actions = append(response.Actions, dtos.Action{
+			Name:   "add_to_wishlist",
+			Method: http.MethodPost,
+			Href:   basePath + "/wishlist",
+			Body:   map[string]string{"isbn": code.Value},
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,610 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	catalogDtos "home-library/internal/services/catalog/dtos"
+	ebookEntities "home-library/internal/services/ebook/entities"
+	householdEntities "home-library/internal/services/household/entities"
+	loanDtos "home-library/internal/services/loan/dtos"
+	"home-library/internal/services/scan/dtos"
+	"home-library/internal/services/scan/entities"
+	wishlistEntities "home-library/internal/services/wishlist/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/scancode"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockEbookRepository struct {
+	mock.Mock
+}
+
+func (m *MockEbookRepository) CreateFile(ctx context.Context, file *ebookEntities.EbookFile) (uuid.UUID, error) {
+	args := m.Called(ctx, file)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockEbookRepository) GetFileByHash(ctx context.Context, ownerID uuid.UUID, sha256 string) (*ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, ownerID, sha256)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) GetSharedFile(ctx context.Context, fileID uuid.UUID, viewerID uuid.UUID) (*ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, fileID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) GetSharedFiles(ctx context.Context, viewerID uuid.UUID) ([]ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) FindSharedFiles(ctx context.Context, viewerID uuid.UUID, filter ebookEntities.Filter) ([]ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, viewerID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) GetSharedFacet(ctx context.Context, viewerID uuid.UUID, facet ebookEntities.Facet) ([]ebookEntities.FacetValue, error) {
+	args := m.Called(ctx, viewerID, facet)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ebookEntities.FacetValue), args.Error(1)
+}
+
+func (m *MockEbookRepository) UpdateFile(ctx context.Context, file *ebookEntities.EbookFile) error {
+	return m.Called(ctx, file).Error(0)
+}
+
+func (m *MockEbookRepository) HasBook(ctx context.Context, ownerID uuid.UUID, bookID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, ownerID, bookID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockEbookRepository) SetBook(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID, bookID *uuid.UUID) error {
+	return m.Called(ctx, fileID, ownerID, bookID).Error(0)
+}
+
+func (m *MockEbookRepository) DeleteFile(ctx context.Context, fileID uuid.UUID, ownerID uuid.UUID) (*ebookEntities.EbookFile, error) {
+	args := m.Called(ctx, fileID, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ebookEntities.EbookFile), args.Error(1)
+}
+
+func (m *MockEbookRepository) HashInUse(ctx context.Context, sha256 string) (bool, error) {
+	args := m.Called(ctx, sha256)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockWishlistRepository struct {
+	mock.Mock
+}
+
+func (m *MockWishlistRepository) CreateItem(ctx context.Context, item *wishlistEntities.WishlistItem) (uuid.UUID, error) {
+	args := m.Called(ctx, item)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetItemsByUser(ctx context.Context, userID uuid.UUID) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetSharedItem(ctx context.Context, itemID uuid.UUID, viewerID uuid.UUID) (*wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, itemID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) GetSharedItemsByUser(ctx context.Context, ownerID uuid.UUID, viewerID uuid.UUID) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, ownerID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) FindItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbns []string) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) TakeItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbn string) ([]wishlistEntities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]wishlistEntities.WishlistItem), args.Error(1)
+}
+
+func (m *MockWishlistRepository) UpdateItem(ctx context.Context, item *wishlistEntities.WishlistItem) error {
+	return m.Called(ctx, item).Error(0)
+}
+
+func (m *MockWishlistRepository) DeleteItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, itemID, userID).Error(0)
+}
+
+func (m *MockWishlistRepository) ReserveItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID, reservedAt time.Time) error {
+	return m.Called(ctx, itemID, userID, reservedAt).Error(0)
+}
+
+func (m *MockWishlistRepository) CancelReservation(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, itemID, userID).Error(0)
+}
+
+func TestScanISBN(t *testing.T) {
+	ebooks, wishlist := new(MockEbookRepository), new(MockWishlistRepository)
+	useCase := NewUseCase(new(MockRepository), new(MockHouseholdRepository), ebooks, wishlist, new(MockShelver), new(MockLender))
+	userID, friendID := uuid.New(), uuid.New()
+	isbns := []string{"9780441013593", "0441013597"}
+
+	file := ebookEntities.NewEbookFile(friendID, strings.Repeat("a", 64), ebookEntities.FormatEPUB, 1)
+	file.Title = "Dune"
+	file.Authors = pq.StringArray{"Frank Herbert"}
+
+	reservedAt := time.Now()
+	free := wishlistEntities.NewWishlistItem(friendID)
+	mine := wishlistEntities.NewWishlistItem(friendID)
+	mine.ReservedBy, mine.ReservedAt = &userID, &reservedAt
+	taken := wishlistEntities.NewWishlistItem(friendID)
+	other := uuid.New()
+	taken.ReservedBy, taken.ReservedAt = &other, &reservedAt
+
+	ebooks.On("FindSharedFiles", mock.Anything, userID, ebookEntities.Filter{ISBNs: isbns}).Return([]ebookEntities.EbookFile{*file}, nil)
+	wishlist.On("FindItemsByISBN", mock.Anything, userID, isbns).Return([]wishlistEntities.WishlistItem{*free, *mine, *taken}, nil)
+
+	result, err := useCase.Scan(context.Background(), userID, dtos.ScanRequest{Code: "0-441-01359-7"})
+	require.NoError(t, err)
+
+	assert.Equal(t, scancode.KindISBN, result.Kind)
+	assert.Equal(t, "9780441013593", result.Value)
+	require.Len(t, result.Ebooks, 1)
+	assert.Equal(t, []string{"Frank Herbert"}, result.Ebooks[0].Authors)
+	require.Len(t, result.WishlistItems, 3)
+	assert.True(t, result.WishlistItems[1].ReservedByMe)
+	assert.True(t, result.WishlistItems[2].IsReserved)
+	assert.False(t, result.WishlistItems[2].ReservedByMe)
+
+	names := make([]string, len(result.Actions))
+	for i, action := range result.Actions {
+		names[i] = action.Name
+	}
+	assert.Equal(t, []string{"download_ebook", "reserve_wishlist_item", "cancel_reservation", "add_to_wishlist"}, names)
+	assert.Equal(t, "/api/v1/ebooks/"+file.FileID.String()+"/download", result.Actions[0].Href)
+	assert.Equal(t, http.MethodPost, result.Actions[1].Method)
+	assert.Equal(t, "/api/v1/wishlist/"+free.ItemID.String()+"/reservation", result.Actions[1].Href)
+	assert.Equal(t, map[string]string{"isbn": "9780441013593"}, result.Actions[3].Body)
+}
+
+func TestScanOwnWishlistItem(t *testing.T) {
+	ebooks, wishlist := new(MockEbookRepository), new(MockWishlistRepository)
+	useCase := NewUseCase(new(MockRepository), new(MockHouseholdRepository), ebooks, wishlist, new(MockShelver), new(MockLender))
+	userID := uuid.New()
+	item := wishlistEntities.NewWishlistItem(userID)
+	isbns := []string{"9791032305690"}
+
+	ebooks.On("FindSharedFiles", mock.Anything, userID, ebookEntities.Filter{ISBNs: isbns}).Return([]ebookEntities.EbookFile{}, nil)
+	wishlist.On("FindItemsByISBN", mock.Anything, userID, isbns).Return([]wishlistEntities.WishlistItem{*item}, nil)
+
+	result, err := useCase.Scan(context.Background(), userID, dtos.ScanRequest{Code: "9791032305690"})
+	require.NoError(t, err)
+
+	assert.Empty(t, result.Ebooks)
+	assert.True(t, result.WishlistItems[0].IsOwn)
+	require.Len(t, result.Actions, 1)
+	assert.Equal(t, "remove_from_wishlist", result.Actions[0].Name)
+	assert.Equal(t, http.MethodDelete, result.Actions[0].Method)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetCopy(ctx context.Context, householdID uuid.UUID, shortID string) (*entities.Copy, error) {
+	args := m.Called(ctx, householdID, shortID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Copy), args.Error(1)
+}
+
+func (m *MockRepository) GetLocation(ctx context.Context, householdID uuid.UUID, shortID string) (*entities.Location, error) {
+	args := m.Called(ctx, householdID, shortID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Location), args.Error(1)
+}
+
+func (m *MockRepository) GetLocationByID(ctx context.Context, householdID uuid.UUID, locationID uuid.UUID) (*entities.Location, error) {
+	args := m.Called(ctx, householdID, locationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Location), args.Error(1)
+}
+
+func (m *MockRepository) CreateSession(ctx context.Context, session *entities.Session) error {
+	return m.Called(ctx, session).Error(0)
+}
+
+func (m *MockRepository) GetSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID, since time.Time) (*entities.Session, error) {
+	args := m.Called(ctx, userID, sessionID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Session), args.Error(1)
+}
+
+func (m *MockRepository) UpdateSession(ctx context.Context, session *entities.Session) error {
+	return m.Called(ctx, session).Error(0)
+}
+
+func (m *MockRepository) DeleteSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) error {
+	return m.Called(ctx, userID, sessionID).Error(0)
+}
+
+func (m *MockRepository) DeleteSessionsBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockShelver struct {
+	mock.Mock
+}
+
+func (m *MockShelver) MoveCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload catalogDtos.MoveCopyRequest) error {
+	return m.Called(ctx, userID, copyID, payload).Error(0)
+}
+
+type MockLender struct {
+	mock.Mock
+}
+
+func (m *MockLender) LendCopy(ctx context.Context, userID uuid.UUID, copyID uuid.UUID, payload loanDtos.LendCopyRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, copyID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockLender) ReturnLoan(ctx context.Context, userID uuid.UUID, loanID uuid.UUID) error {
+	return m.Called(ctx, userID, loanID).Error(0)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	households *MockHouseholdRepository
+	shelver    *MockShelver
+	lender     *MockLender
+}
+
+func newLabelUseCase(userID uuid.UUID, householdID uuid.UUID) (UseCase, mocks) {
+	m := mocks{new(MockRepository), new(MockHouseholdRepository), new(MockShelver), new(MockLender)}
+	m.households.On("GetMembership", mock.Anything, userID).
+		Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleEditor}, nil)
+	return NewUseCase(m.repo, m.households, new(MockEbookRepository), new(MockWishlistRepository), m.shelver, m.lender), m
+}
+
+// session makes the session with the given mode the one the scans run in.
+func (m mocks) session(userID uuid.UUID, householdID uuid.UUID, mode entities.Mode) *entities.Session {
+	session := entities.NewSession(userID, householdID)
+	session.Mode = mode
+	m.repo.On("GetSession", mock.Anything, userID, session.SessionID, mock.Anything).Return(session, nil)
+	m.repo.On("UpdateSession", mock.Anything, session).Return(nil)
+	return session
+}
+
+func actionNames(actions []dtos.Action) []string {
+	names := make([]string, len(actions))
+	for i, action := range actions {
+		names[i] = action.Name
+	}
+	return names
+}
+
+func TestScanCopy(t *testing.T) {
+	userID, householdID := uuid.New(), uuid.New()
+	shelf := uuid.New()
+
+	t.Run("copy label without a session", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		copy := &entities.Copy{CopyID: uuid.New(), ShortID: "K7M2Q9XD", BookID: uuid.New(), Title: "Dune", Authors: pq.StringArray{"Frank Herbert"}}
+		m.repo.On("GetCopy", mock.Anything, householdID, "K7M2Q9XD").Return(copy, nil)
+
+		result, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "https://library.example/copies/K7M2Q9XD"})
+		require.NoError(t, err)
+
+		assert.Equal(t, scancode.KindCopy, result.Kind)
+		assert.Equal(t, copy.CopyID, result.Copy.CopyID)
+		assert.Empty(t, result.Performed)
+		assert.Nil(t, result.Session)
+		assert.Equal(t, []string{"view_book", "lend_copy"}, actionNames(result.Actions))
+		assert.Equal(t, "/api/v1/copies/"+copy.CopyID.String()+"/loans", result.Actions[1].Href)
+	})
+
+	t.Run("copy on loan offers its return", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		loanID := uuid.New()
+		copy := &entities.Copy{CopyID: uuid.New(), ShortID: "K7M2Q9XD", LoanID: &loanID}
+		m.repo.On("GetCopy", mock.Anything, householdID, "K7M2Q9XD").Return(copy, nil)
+
+		result, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "k7m2-q9xd"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"view_book", "return_copy"}, actionNames(result.Actions))
+		assert.Equal(t, "/api/v1/loans/"+loanID.String()+"/return", result.Actions[1].Href)
+	})
+
+	t.Run("move session puts the copy on the current shelf", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		session := m.session(userID, householdID, entities.ModeMove)
+		name := "Гостиная"
+		session.LocationID, session.LocationName = &shelf, &name
+		copy := &entities.Copy{CopyID: uuid.New(), ShortID: "K7M2Q9XD"}
+		m.repo.On("GetCopy", mock.Anything, householdID, "K7M2Q9XD").Return(copy, nil)
+		m.shelver.On("MoveCopy", mock.Anything, userID, copy.CopyID, catalogDtos.MoveCopyRequest{LocationID: &shelf}).Return(nil)
+
+		result, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "K7M2Q9XD", SessionID: &session.SessionID})
+		require.NoError(t, err)
+
+		assert.Equal(t, entities.ModeMove, result.Performed)
+		assert.Equal(t, &shelf, result.Copy.LocationID)
+		assert.Equal(t, &name, result.Copy.LocationName)
+		assert.NotContains(t, actionNames(result.Actions), "move_copy")
+		m.shelver.AssertExpectations(t)
+		m.repo.AssertCalled(t, "UpdateSession", mock.Anything, session)
+	})
+
+	t.Run("move session without a shelf", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		session := m.session(userID, householdID, entities.ModeMove)
+		m.repo.On("GetCopy", mock.Anything, householdID, "K7M2Q9XD").Return(&entities.Copy{CopyID: uuid.New()}, nil)
+
+		_, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "K7M2Q9XD", SessionID: &session.SessionID})
+
+		assert.ErrorIs(t, err, errors.ErrScanNoShelf)
+		m.shelver.AssertNotCalled(t, "MoveCopy", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("lend session lends to its borrower", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		session := m.session(userID, householdID, entities.ModeLend)
+		session.BorrowerName = "Аня"
+		copy := &entities.Copy{CopyID: uuid.New(), ShortID: "K7M2Q9XD"}
+		loanID := uuid.New()
+		m.repo.On("GetCopy", mock.Anything, householdID, "K7M2Q9XD").Return(copy, nil)
+		m.lender.On("LendCopy", mock.Anything, userID, copy.CopyID, loanDtos.LendCopyRequest{BorrowerName: "Аня"}).Return(loanID, nil)
+
+		result, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "K7M2Q9XD", SessionID: &session.SessionID})
+		require.NoError(t, err)
+
+		assert.Equal(t, entities.ModeLend, result.Performed)
+		assert.Equal(t, &loanID, result.Copy.LoanID)
+		assert.Equal(t, []string{"view_book", "return_copy"}, actionNames(result.Actions))
+	})
+
+	t.Run("return session takes the copy back", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		session := m.session(userID, householdID, entities.ModeReturn)
+		loanID := uuid.New()
+		copy := &entities.Copy{CopyID: uuid.New(), ShortID: "K7M2Q9XD", LoanID: &loanID}
+		m.repo.On("GetCopy", mock.Anything, householdID, "K7M2Q9XD").Return(copy, nil)
+		m.lender.On("ReturnLoan", mock.Anything, userID, loanID).Return(nil)
+
+		result, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "K7M2Q9XD", SessionID: &session.SessionID})
+		require.NoError(t, err)
+
+		assert.Equal(t, entities.ModeReturn, result.Performed)
+		assert.Nil(t, result.Copy.LoanID)
+		m.lender.AssertExpectations(t)
+	})
+
+	t.Run("return session with a copy that is not lent", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		session := m.session(userID, householdID, entities.ModeReturn)
+		m.repo.On("GetCopy", mock.Anything, householdID, "K7M2Q9XD").Return(&entities.Copy{CopyID: uuid.New()}, nil)
+
+		_, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "K7M2Q9XD", SessionID: &session.SessionID})
+
+		assert.ErrorIs(t, err, errors.ErrScanNotOnLoan)
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		sessionID := uuid.New()
+		m.repo.On("GetSession", mock.Anything, userID, sessionID, mock.Anything).Return(nil, sql.ErrNoRows)
+
+		_, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "K7M2Q9XD", SessionID: &sessionID})
+
+		assert.ErrorIs(t, err, errors.ErrScanSessionNotFound)
+	})
+}
+
+func TestScanShelf(t *testing.T) {
+	userID, householdID := uuid.New(), uuid.New()
+
+	t.Run("shelf becomes the current one of the session", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		session := m.session(userID, householdID, entities.ModeMove)
+		location := &entities.Location{LocationID: uuid.New(), ShortID: "3F8HZ1WN", Name: "Гостиная"}
+		m.repo.On("GetLocation", mock.Anything, householdID, "3F8HZ1WN").Return(location, nil)
+
+		result, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "https://library.example/shelves/3F8HZ1WN", SessionID: &session.SessionID})
+		require.NoError(t, err)
+
+		assert.Equal(t, scancode.KindShelf, result.Kind)
+		assert.Equal(t, "Гостиная", result.Location.Name)
+		assert.Equal(t, &location.LocationID, result.Session.LocationID)
+		assert.Equal(t, []string{"list_books"}, actionNames(result.Actions))
+	})
+
+	t.Run("shelf of another household", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		m.repo.On("GetLocation", mock.Anything, householdID, "3F8HZ1WN").Return(nil, sql.ErrNoRows)
+
+		_, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "https://library.example/shelves/3F8HZ1WN"})
+
+		assert.ErrorIs(t, err, errors.ErrScanNotFound)
+	})
+}
+
+func TestScanErrors(t *testing.T) {
+	userID, householdID := uuid.New(), uuid.New()
+	u, m := newLabelUseCase(userID, householdID)
+	m.repo.On("GetCopy", mock.Anything, householdID, "K7M2Q9XD").Return(nil, sql.ErrNoRows)
+
+	_, err := u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "https://library.example/copies/K7M2Q9XD"})
+	assert.ErrorIs(t, err, errors.ErrScanNotFound)
+
+	_, err = u.Scan(context.Background(), userID, dtos.ScanRequest{Code: "4006381333931"})
+	assert.ErrorIs(t, err, errors.ErrScanUnrecognized)
+}
+
+func TestUpdateSession(t *testing.T) {
+	userID, householdID := uuid.New(), uuid.New()
+
+	t.Run("shelf of another household", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		session := m.session(userID, householdID, entities.ModeLookup)
+		locationID := uuid.New()
+		m.repo.On("GetLocationByID", mock.Anything, householdID, locationID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.UpdateSession(context.Background(), userID, session.SessionID, dtos.SessionRequest{Mode: entities.ModeMove, LocationID: &locationID})
+
+		assert.ErrorIs(t, err, errors.ErrLocationNotFound)
+		m.repo.AssertNotCalled(t, "UpdateSession", mock.Anything, mock.Anything)
+	})
+
+	t.Run("mode and borrower are saved", func(t *testing.T) {
+		u, m := newLabelUseCase(userID, householdID)
+		session := m.session(userID, householdID, entities.ModeLookup)
+
+		result, err := u.UpdateSession(context.Background(), userID, session.SessionID, dtos.SessionRequest{Mode: entities.ModeLend, BorrowerName: " Аня "})
+		require.NoError(t, err)
+
+		assert.Equal(t, entities.ModeLend, result.Mode)
+		assert.Equal(t, "Аня", result.BorrowerName)
+		m.repo.AssertCalled(t, "UpdateSession", mock.Anything, session)
+	})
+}
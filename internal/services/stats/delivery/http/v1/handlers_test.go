@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"home-library/internal/services/stats/dtos"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) GetStats(ctx context.Context, userID uuid.UUID) (*dtos.StatsResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.StatsResponse), args.Error(1)
+}
+
+func newContext(e *echo.Echo, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestGetStats(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully get stats", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("GetStats", mock.Anything, userID).Return(&dtos.StatsResponse{
+			User: &dtos.Stats{Ebooks: 3, Languages: []dtos.CountResponse{{Name: "ru", Count: 3}}},
+		}, nil)
+
+		c, rec := newContext(e, userID)
+		err := h.GetStats(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"ebooks":3`)
+		assert.NotContains(t, rec.Body.String(), `"household"`)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, uuid.Nil)
+		err := h.GetStats(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("GetStats", mock.Anything, userID).Return(nil, errors.New("db down"))
+
+		c, rec := newContext(e, userID)
+		err := h.GetStats(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,7 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) StatsRoutes(domain *echo.Group) {
+	domain.GET("/stats", h.GetStats)
+}
@@ -0,0 +1,111 @@
+package dtos
+
+import (
+	"home-library/internal/services/stats/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CountResponse struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type Stats struct {
+	Ebooks         int             `json:"ebooks"`
+	TotalSize      int64           `json:"total_size"`
+	Series         int             `json:"series"`
+	Quotes         int             `json:"quotes"`
+	WishlistItems  int             `json:"wishlist_items"`
+	ReservedItems  int             `json:"reserved_items"`
+	BooksRead      int             `json:"books_read"`
+	PagesRead      int             `json:"pages_read"`
+	Reviews        int             `json:"reviews"`
+	AverageRating  *float64        `json:"average_rating"`
+	Languages      []CountResponse `json:"languages"`
+	Formats        []CountResponse `json:"formats"`
+	TopTags        []CountResponse `json:"top_tags"`
+	TopAuthors     []CountResponse `json:"top_authors"`
+	QuotesPerMonth []CountResponse `json:"quotes_per_month"`
+	PagesPerMonth  []CountResponse `json:"pages_per_month"`
+}
+
+type ShelfResponse struct {
+	LocationID uuid.UUID  `json:"location_id"`
+	Name       string     `json:"name"`
+	Copies     int        `json:"copies"`
+	LastReadAt *time.Time `json:"last_read_at"`
+}
+
+// Library describes the household catalog.
+type Library struct {
+	Books         int             `json:"books"`
+	Copies        int             `json:"copies"`
+	LentOut       int             `json:"lent_out"`
+	Genres        []CountResponse `json:"genres"`
+	Languages     []CountResponse `json:"languages"`
+	Decades       []CountResponse `json:"decades"`
+	TopAuthors    []CountResponse `json:"top_authors"`
+	UnreadShelves []ShelfResponse `json:"unread_shelves"`
+}
+
+// StatsResponse holds the caller's own numbers and, for a household member,
+// those of the whole household and of its catalog.
+type StatsResponse struct {
+	User      *Stats   `json:"user"`
+	Household *Stats   `json:"household,omitempty"`
+	Library   *Library `json:"library,omitempty"`
+}
+
+func NewStats(stats *entities.Stats) *Stats {
+	return &Stats{
+		Ebooks:         stats.Ebooks,
+		TotalSize:      stats.TotalSize,
+		Series:         stats.Series,
+		Quotes:         stats.Quotes,
+		WishlistItems:  stats.WishlistItems,
+		ReservedItems:  stats.ReservedItems,
+		BooksRead:      stats.BooksRead,
+		PagesRead:      stats.PagesRead,
+		Reviews:        stats.Reviews,
+		AverageRating:  stats.AverageRating,
+		Languages:      newCounts(stats.Languages),
+		Formats:        newCounts(stats.Formats),
+		TopTags:        newCounts(stats.TopTags),
+		TopAuthors:     newCounts(stats.TopAuthors),
+		QuotesPerMonth: newCounts(stats.QuotesPerMonth),
+		PagesPerMonth:  newCounts(stats.PagesPerMonth),
+	}
+}
+
+func NewLibrary(library *entities.Library) *Library {
+	shelves := make([]ShelfResponse, len(library.UnreadShelves))
+	for i, shelf := range library.UnreadShelves {
+		shelves[i] = ShelfResponse{
+			LocationID: shelf.LocationID,
+			Name:       shelf.Name,
+			Copies:     shelf.Copies,
+			LastReadAt: shelf.LastReadAt,
+		}
+	}
+
+	return &Library{
+		Books:         library.Books,
+		Copies:        library.Copies,
+		LentOut:       library.LentOut,
+		Genres:        newCounts(library.Genres),
+		Languages:     newCounts(library.Languages),
+		Decades:       newCounts(library.Decades),
+		TopAuthors:    newCounts(library.TopAuthors),
+		UnreadShelves: shelves,
+	}
+}
+
+func newCounts(counts []entities.Count) []CountResponse {
+	result := make([]CountResponse, len(counts))
+	for i, count := range counts {
+		result[i] = CountResponse{Name: count.Name, Count: count.Count}
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Count is one group of an aggregate, such as a language and the number of
+// ebooks in it.
+type Count struct {
+	Name  string `db:"name"`
+	Count int    `db:"count"`
+}
+
+type Totals struct {
+	Ebooks        int   `db:"ebooks"`
+	TotalSize     int64 `db:"total_size"`
+	Series        int   `db:"series"`
+	Quotes        int   `db:"quotes"`
+	WishlistItems int   `db:"wishlist_items"`
+	ReservedItems int   `db:"reserved_items"`
+	BooksRead     int   `db:"books_read"`
+	PagesRead     int   `db:"pages_read"`
+	Reviews       int   `db:"reviews"`
+	// AverageRating is nil until the first review.
+	AverageRating *float64 `db:"average_rating"`
+}
+
+// Stats aggregates the library of a set of users: one user alone or all the
+// members of a household.
+type Stats struct {
+	Totals
+	Languages      []Count
+	Formats        []Count
+	TopTags        []Count
+	TopAuthors     []Count
+	QuotesPerMonth []Count
+	PagesPerMonth  []Count
+}
+
+type LibraryTotals struct {
+	Books   int `db:"books"`
+	Copies  int `db:"copies"`
+	LentOut int `db:"lent_out"`
+}
+
+// Shelf is a location with the last time one of the books on it was
+// finished, nil when none ever was.
+type Shelf struct {
+	LocationID uuid.UUID  `db:"location_id"`
+	Name       string     `db:"name"`
+	Copies     int        `db:"copies"`
+	LastReadAt *time.Time `db:"last_read_at"`
+}
+
+// Library aggregates the catalog of a household. Genres are the tags of its
+// books and decades are named by their first year.
+type Library struct {
+	LibraryTotals
+	Genres        []Count
+	Languages     []Count
+	Decades       []Count
+	TopAuthors    []Count
+	UnreadShelves []Shelf
+}
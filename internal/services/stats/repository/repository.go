@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/stats/entities"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// topLimit is the length of the top authors and tags lists and of the
+// longest-unread shelves.
+const topLimit = 10
+
+// Repository methods take the set of users whose library is aggregated, so
+// the same queries serve one user and a whole household.
+type Repository interface {
+	// GetStats computes the statistics from a single snapshot. Quotes and
+	// pages read are counted per month in the given time zone from since
+	// onwards, a book's pages going to the month it was finished in.
+	GetStats(ctx context.Context, userIDs []uuid.UUID, since time.Time, timeZone string) (*entities.Stats, error)
+	// GetVersion returns a value that changes whenever rows GetStats reads
+	// are added, changed or removed, so computed statistics can be cached.
+	GetVersion(ctx context.Context, userIDs []uuid.UUID) (string, error)
+	// GetLibrary computes the catalog statistics of a household from a
+	// single snapshot.
+	GetLibrary(ctx context.Context, householdID uuid.UUID) (*entities.Library, error)
+	// GetLibraryVersion is GetVersion for GetLibrary.
+	GetLibraryVersion(ctx context.Context, householdID uuid.UUID) (string, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) GetStats(ctx context.Context, userIDs []uuid.UUID, since time.Time, timeZone string) (*entities.Stats, error) {
+	ids := userIDArray(userIDs)
+	stats := &entities.Stats{}
+
+	err := r.withReadTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+			SELECT
+				(SELECT count(*) FROM ebook_files WHERE owner_id = ANY($1::uuid[])) AS ebooks,
+				(SELECT COALESCE(sum(size), 0) FROM ebook_files WHERE owner_id = ANY($1::uuid[])) AS total_size,
+				(SELECT count(DISTINCT series) FROM ebook_files WHERE owner_id = ANY($1::uuid[]) AND series <> '') AS series,
+				(SELECT count(*) FROM quotes WHERE user_id = ANY($1::uuid[])) AS quotes,
+				(SELECT count(*) FROM wishlist_items WHERE user_id = ANY($1::uuid[]) AND deleted_at IS NULL) AS wishlist_items,
+				(SELECT count(*) FROM wishlist_items WHERE user_id = ANY($1::uuid[]) AND deleted_at IS NULL AND reserved_by IS NOT NULL) AS reserved_items,
+				(SELECT count(DISTINCT book_id) FROM readings WHERE user_id = ANY($1::uuid[]) AND finished_at IS NOT NULL) AS books_read,
+				(SELECT COALESCE(sum(b.pages), 0) FROM readings r JOIN books b ON b.book_id = r.book_id
+					WHERE r.user_id = ANY($1::uuid[]) AND r.finished_at IS NOT NULL) AS pages_read,
+				(SELECT count(*) FROM reviews WHERE user_id = ANY($1::uuid[])) AS reviews,
+				(SELECT round(avg(rating), 2)::double precision FROM reviews WHERE user_id = ANY($1::uuid[])) AS average_rating
+		`
+		if err := tx.GetContext(ctx, &stats.Totals, query, ids); err != nil {
+			return err
+		}
+
+		groups := []struct {
+			target *[]entities.Count
+			query  string
+			args   []any
+		}{
+			{&stats.Languages, countEbooksBy("e.language"), []any{ids}},
+			{&stats.Formats, countEbooksBy("e.format"), []any{ids}},
+			{&stats.TopTags, countEbooksBy("unnest(e.tags)") + " LIMIT $2", []any{ids, topLimit}},
+			{&stats.TopAuthors, countEbooksBy("unnest(e.authors)") + " LIMIT $2", []any{ids, topLimit}},
+			{&stats.QuotesPerMonth, `
+				SELECT to_char(date_trunc('month', COALESCE(q.highlighted_at, q.created_at) AT TIME ZONE $3), 'YYYY-MM') AS name,
+					count(*) AS count
+				FROM quotes q
+				WHERE q.user_id = ANY($1::uuid[]) AND COALESCE(q.highlighted_at, q.created_at) >= $2
+				GROUP BY name
+				ORDER BY name
+			`, []any{ids, since, timeZone}},
+			{&stats.PagesPerMonth, `
+				SELECT to_char(date_trunc('month', r.finished_at AT TIME ZONE $3), 'YYYY-MM') AS name,
+					sum(b.pages) AS count
+				FROM readings r
+				JOIN books b ON b.book_id = r.book_id
+				WHERE r.user_id = ANY($1::uuid[]) AND r.finished_at >= $2 AND b.pages IS NOT NULL
+				GROUP BY name
+				ORDER BY name
+			`, []any{ids, since, timeZone}},
+		}
+
+		for _, group := range groups {
+			*group.target = make([]entities.Count, 0)
+			if err := tx.SelectContext(ctx, group.target, group.query, group.args...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *repository) GetVersion(ctx context.Context, userIDs []uuid.UUID) (string, error) {
+	var version string
+	query := `
+		SELECT concat_ws('/',
+			(SELECT count(*) || ':' || COALESCE(max(updated_at)::text, '') FROM ebook_files WHERE owner_id = ANY($1::uuid[])),
+			(SELECT count(*) || ':' || COALESCE(max(created_at)::text, '') FROM quotes WHERE user_id = ANY($1::uuid[])),
+			(SELECT count(*) || ':' || count(reserved_by) || ':' || COALESCE(max(updated_at)::text, '')
+				FROM wishlist_items WHERE user_id = ANY($1::uuid[]) AND deleted_at IS NULL),
+			(SELECT count(*) || ':' || COALESCE(max(r.updated_at)::text, '') || ':' || COALESCE(max(b.updated_at)::text, '')
+				FROM readings r JOIN books b ON b.book_id = r.book_id WHERE r.user_id = ANY($1::uuid[])),
+			(SELECT count(*) || ':' || COALESCE(max(updated_at)::text, '') FROM reviews WHERE user_id = ANY($1::uuid[]))
+		)
+	`
+
+	err := r.db.GetContext(ctx, &version, query, userIDArray(userIDs))
+	if err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
+func (r *repository) GetLibrary(ctx context.Context, householdID uuid.UUID) (*entities.Library, error) {
+	library := &entities.Library{}
+
+	err := r.withReadTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+			SELECT
+				(SELECT count(*) FROM books WHERE household_id = $1) AS books,
+				(SELECT count(*) FROM copies WHERE household_id = $1) AS copies,
+				(SELECT count(*) FROM loans WHERE household_id = $1 AND returned_at IS NULL) AS lent_out
+		`
+		if err := tx.GetContext(ctx, &library.LibraryTotals, query, householdID); err != nil {
+			return err
+		}
+
+		groups := []struct {
+			target *[]entities.Count
+			query  string
+			args   []any
+		}{
+			{&library.Genres, countBooksBy("unnest(b.tags)") + " LIMIT $2", []any{householdID, topLimit}},
+			{&library.Languages, countBooksBy("b.language"), []any{householdID}},
+			{&library.TopAuthors, countBooksBy("unnest(b.authors)") + " LIMIT $2", []any{householdID, topLimit}},
+			{&library.Decades, `
+				SELECT (published_year / 10 * 10)::text AS name, count(*) AS count
+				FROM books
+				WHERE household_id = $1 AND published_year IS NOT NULL
+				GROUP BY published_year / 10
+				ORDER BY published_year / 10
+			`, []any{householdID}},
+		}
+		for _, group := range groups {
+			*group.target = make([]entities.Count, 0)
+			if err := tx.SelectContext(ctx, group.target, group.query, group.args...); err != nil {
+				return err
+			}
+		}
+
+		// Shelves nothing was ever read from come first, then those read
+		// from the longest ago. Empty shelves have nothing to read.
+		library.UnreadShelves = make([]entities.Shelf, 0)
+		query = `
+			SELECT l.location_id, l.name, count(DISTINCT c.copy_id) AS copies, max(r.finished_at) AS last_read_at
+			FROM locations l
+			JOIN copies c ON c.location_id = l.location_id
+			LEFT JOIN readings r ON r.book_id = c.book_id AND r.finished_at IS NOT NULL
+			WHERE l.household_id = $1
+			GROUP BY l.location_id
+			ORDER BY last_read_at NULLS FIRST, l.name
+			LIMIT $2
+		`
+		return tx.SelectContext(ctx, &library.UnreadShelves, query, householdID, topLimit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return library, nil
+}
+
+func (r *repository) GetLibraryVersion(ctx context.Context, householdID uuid.UUID) (string, error) {
+	var version string
+	query := `
+		SELECT concat_ws('/',
+			(SELECT count(*) || ':' || COALESCE(max(updated_at)::text, '') FROM books WHERE household_id = $1),
+			(SELECT count(*) || ':' || COALESCE(max(updated_at)::text, '') FROM copies WHERE household_id = $1),
+			(SELECT count(*) || ':' || count(returned_at) FROM loans WHERE household_id = $1),
+			(SELECT count(*) || ':' || COALESCE(max(updated_at)::text, '') FROM readings WHERE household_id = $1),
+			(SELECT count(*) || ':' || COALESCE(max(updated_at)::text, '') FROM locations WHERE household_id = $1)
+		)
+	`
+
+	err := r.db.GetContext(ctx, &version, query, householdID)
+	if err != nil {
+		return "", err
+	}
+
+	return version, nil
+}
+
+// countEbooksBy groups the ebook files of the users passed as $1 by the
+// expression, largest groups first. Empty values are left out.
+func countEbooksBy(expression string) string {
+	return `
+		SELECT name, count(*) AS count
+		FROM (SELECT ` + expression + ` AS name FROM ebook_files e WHERE e.owner_id = ANY($1::uuid[])) grouped
+		WHERE name <> ''
+		GROUP BY name
+		ORDER BY count DESC, name
+	`
+}
+
+// countBooksBy groups the books of the household passed as $1 by the
+// expression, largest groups first. Empty values are left out.
+func countBooksBy(expression string) string {
+	return `
+		SELECT name, count(*) AS count
+		FROM (SELECT ` + expression + ` AS name FROM books b WHERE b.household_id = $1) grouped
+		WHERE name <> ''
+		GROUP BY name
+		ORDER BY count DESC, name
+	`
+}
+
+// withReadTx runs fn in a read-only repeatable read transaction, so that all
+// the statistics describe the same state of the library.
+func (r *repository) withReadTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.db.InTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, fn)
+}
+
+func userIDArray(userIDs []uuid.UUID) pq.StringArray {
+	ids := make(pq.StringArray, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	return ids
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"home-library/internal/services/stats/entities"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func counts(rows ...any) *sqlmock.Rows {
+	result := sqlmock.NewRows([]string{"name", "count"})
+	for i := 0; i+1 < len(rows); i += 2 {
+		result.AddRow(rows[i], rows[i+1])
+	}
+	return result
+}
+
+func TestGetStats(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	userIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	ids := pq.StringArray{userIDs[0].String(), userIDs[1].String()}
+	since := time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("successfully get stats", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("AS average_rating")).
+			WithArgs(ids).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"ebooks", "total_size", "series", "quotes", "wishlist_items", "reserved_items",
+				"books_read", "pages_read", "reviews", "average_rating",
+			}).AddRow(3, 4096, 1, 10, 2, 1, 5, 1520, 2, 4.5))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT e.language AS name")).
+			WithArgs(ids).
+			WillReturnRows(counts("ru", 2, "en", 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT e.format AS name")).
+			WithArgs(ids).
+			WillReturnRows(counts("epub", 3))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT unnest(e.tags) AS name")).
+			WithArgs(ids, topLimit).
+			WillReturnRows(counts())
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT unnest(e.authors) AS name")).
+			WithArgs(ids, topLimit).
+			WillReturnRows(counts("Станислав Лем", 2))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM quotes q")).
+			WithArgs(ids, since, "Europe/Moscow").
+			WillReturnRows(counts("2024-01", 4, "2024-03", 6))
+		mock.ExpectQuery(regexp.QuoteMeta("sum(b.pages) AS count")).
+			WithArgs(ids, since, "Europe/Moscow").
+			WillReturnRows(counts("2024-02", 380))
+		mock.ExpectCommit()
+
+		stats, err := repo.GetStats(context.Background(), userIDs, since, "Europe/Moscow")
+
+		require.NoError(t, err)
+		rating := 4.5
+		assert.Equal(t, entities.Totals{
+			Ebooks: 3, TotalSize: 4096, Series: 1, Quotes: 10, WishlistItems: 2, ReservedItems: 1,
+			BooksRead: 5, PagesRead: 1520, Reviews: 2, AverageRating: &rating,
+		}, stats.Totals)
+		assert.Equal(t, []entities.Count{{Name: "ru", Count: 2}, {Name: "en", Count: 1}}, stats.Languages)
+		assert.Equal(t, []entities.Count{}, stats.TopTags)
+		assert.Len(t, stats.QuotesPerMonth, 2)
+		assert.Equal(t, []entities.Count{{Name: "2024-02", Count: 380}}, stats.PagesPerMonth)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failure rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("AS average_rating")).
+			WillReturnError(errors.New("db error"))
+		mock.ExpectRollback()
+
+		_, err := repo.GetStats(context.Background(), userIDs, since, "UTC")
+
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetVersion(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	userID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT concat_ws")).
+		WithArgs(pq.StringArray{userID.String()}).
+		WillReturnRows(sqlmock.NewRows([]string{"concat_ws"}).AddRow("3:2024-03-01/0:/1:0:2024-02-01"))
+
+	version, err := repo.GetVersion(context.Background(), []uuid.UUID{userID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "3:2024-03-01/0:/1:0:2024-02-01", version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLibrary(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	householdID := uuid.New()
+	locationID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("AS lent_out")).
+		WithArgs(householdID).
+		WillReturnRows(sqlmock.NewRows([]string{"books", "copies", "lent_out"}).AddRow(40, 42, 2))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT unnest(b.tags) AS name")).
+		WithArgs(householdID, topLimit).
+		WillReturnRows(counts("фантастика", 12))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT b.language AS name")).
+		WithArgs(householdID).
+		WillReturnRows(counts("ru", 30, "en", 10))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT unnest(b.authors) AS name")).
+		WithArgs(householdID, topLimit).
+		WillReturnRows(counts("Станислав Лем", 6))
+	mock.ExpectQuery(regexp.QuoteMeta("GROUP BY published_year / 10")).
+		WithArgs(householdID).
+		WillReturnRows(counts("1960", 7, "1970", 3))
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY last_read_at NULLS FIRST")).
+		WithArgs(householdID, topLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"location_id", "name", "copies", "last_read_at"}).
+			AddRow(locationID, "Антресоль", 12, nil))
+	mock.ExpectCommit()
+
+	library, err := repo.GetLibrary(context.Background(), householdID)
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.LibraryTotals{Books: 40, Copies: 42, LentOut: 2}, library.LibraryTotals)
+	assert.Equal(t, []entities.Count{{Name: "1960", Count: 7}, {Name: "1970", Count: 3}}, library.Decades)
+	assert.Equal(t, []entities.Shelf{{LocationID: locationID, Name: "Антресоль", Copies: 12}}, library.UnreadShelves)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,184 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/internal/services/stats/dtos"
+	"home-library/internal/services/stats/repository"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// months is how far back quotes and pages read are counted per month, the
+// current month included.
+const months = 12
+
+// maxCacheEntries bounds the cache; it is emptied when full, which is cheap
+// as every entry can be recomputed.
+const maxCacheEntries = 1024
+
+type UseCase interface {
+	GetStats(ctx context.Context, userID uuid.UUID) (*dtos.StatsResponse, error)
+}
+
+type cacheEntry struct {
+	version string
+	month   string
+	value   any
+}
+
+// useCase caches computed statistics per set of users and per household
+// catalog. Before serving an entry it compares the cheap version of the
+// underlying rows, so any write to them invalidates the entry without the
+// writers knowing about the cache.
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	timeZone   string
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository, timeZone string) UseCase {
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+
+	return &useCase{
+		r:          r,
+		households: households,
+		timeZone:   timeZone,
+		now:        time.Now,
+		cache:      make(map[string]cacheEntry),
+	}
+}
+
+func (u *useCase) GetStats(ctx context.Context, userID uuid.UUID) (*dtos.StatsResponse, error) {
+	userStats, err := u.stats(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, err
+	}
+	response := &dtos.StatsResponse{User: userStats}
+
+	member, err := u.households.GetMembership(ctx, userID)
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return response, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := u.households.GetMembers(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uuid.UUID, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+
+	response.Household, err = u.stats(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Library, err = u.library(ctx, member.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (u *useCase) stats(ctx context.Context, userIDs []uuid.UUID) (*dtos.Stats, error) {
+	location, err := time.LoadLocation(u.timeZone)
+	if err != nil {
+		return nil, err
+	}
+	now := u.now().In(location)
+
+	version, err := u.r.GetVersion(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries also expire with the month, as the counts per month move on.
+	value, err := u.cached(cacheKey(userIDs), version, now.Format("2006-01"), func() (any, error) {
+		since := time.Date(now.Year(), now.Month()-months+1, 1, 0, 0, 0, 0, location)
+		stats, err := u.r.GetStats(ctx, userIDs, since, u.timeZone)
+		if err != nil {
+			return nil, err
+		}
+		return dtos.NewStats(stats), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*dtos.Stats), nil
+}
+
+func (u *useCase) library(ctx context.Context, householdID uuid.UUID) (*dtos.Library, error) {
+	version, err := u.r.GetLibraryVersion(ctx, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := u.cached("library:"+householdID.String(), version, "", func() (any, error) {
+		library, err := u.r.GetLibrary(ctx, householdID)
+		if err != nil {
+			return nil, err
+		}
+		return dtos.NewLibrary(library), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.(*dtos.Library), nil
+}
+
+// cached returns the entry under key if it was computed at the same version
+// and month, and computes and stores it otherwise.
+func (u *useCase) cached(key string, version string, month string, compute func() (any, error)) (any, error) {
+	u.mu.Lock()
+	entry, ok := u.cache[key]
+	u.mu.Unlock()
+
+	if ok && entry.version == version && entry.month == month {
+		return entry.value, nil
+	}
+
+	value, err := compute()
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	if len(u.cache) >= maxCacheEntries {
+		clear(u.cache)
+	}
+	u.cache[key] = cacheEntry{version: version, month: month, value: value}
+	u.mu.Unlock()
+
+	return value, nil
+}
+
+// cacheKey identifies a set of users regardless of their order.
+func cacheKey(userIDs []uuid.UUID) string {
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
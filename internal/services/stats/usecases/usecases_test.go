@@ -0,0 +1,252 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/internal/services/stats/entities"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) GetStats(ctx context.Context, userIDs []uuid.UUID, since time.Time, timeZone string) (*entities.Stats, error) {
+	args := m.Called(ctx, userIDs, since, timeZone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Stats), args.Error(1)
+}
+
+func (m *MockRepository) GetVersion(ctx context.Context, userIDs []uuid.UUID) (string, error) {
+	args := m.Called(ctx, userIDs)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRepository) GetLibrary(ctx context.Context, householdID uuid.UUID) (*entities.Library, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Library), args.Error(1)
+}
+
+func (m *MockRepository) GetLibraryVersion(ctx context.Context, householdID uuid.UUID) (string, error) {
+	args := m.Called(ctx, householdID)
+	return args.String(0), args.Error(1)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+func newUseCase(r *MockRepository, households *MockHouseholdRepository, now time.Time) *useCase {
+	u := NewUseCase(r, households, "Europe/Moscow").(*useCase)
+	u.now = func() time.Time { return now }
+	return u
+}
+
+func TestGetStats(t *testing.T) {
+	now := time.Date(2024, time.March, 31, 22, 30, 0, 0, time.UTC)
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	// It is already April in Moscow, so the last twelve months start in May.
+	since := time.Date(2023, time.May, 1, 0, 0, 0, 0, moscow)
+
+	t.Run("user without household", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, now)
+		userID := uuid.New()
+
+		repo.On("GetVersion", mock.Anything, []uuid.UUID{userID}).Return("v1", nil)
+		repo.On("GetStats", mock.Anything, []uuid.UUID{userID}, since, "Europe/Moscow").Return(&entities.Stats{
+			Totals:    entities.Totals{Ebooks: 3, TotalSize: 1024},
+			Languages: []entities.Count{{Name: "ru", Count: 2}, {Name: "en", Count: 1}},
+		}, nil)
+		households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+
+		result, err := u.GetStats(context.Background(), userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.User.Ebooks)
+		assert.Equal(t, "ru", result.User.Languages[0].Name)
+		assert.Empty(t, result.User.Formats)
+		assert.Nil(t, result.Household)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("household member", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, now)
+		userID, otherID, householdID := uuid.New(), uuid.New(), uuid.New()
+		members := []uuid.UUID{otherID, userID}
+
+		repo.On("GetVersion", mock.Anything, []uuid.UUID{userID}).Return("v1", nil)
+		repo.On("GetStats", mock.Anything, []uuid.UUID{userID}, since, "Europe/Moscow").
+			Return(&entities.Stats{Totals: entities.Totals{Ebooks: 1}}, nil)
+		repo.On("GetVersion", mock.Anything, members).Return("v2", nil)
+		repo.On("GetStats", mock.Anything, members, since, "Europe/Moscow").
+			Return(&entities.Stats{Totals: entities.Totals{Ebooks: 5}}, nil)
+		households.On("GetMembership", mock.Anything, userID).
+			Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID}, nil)
+		households.On("GetMembers", mock.Anything, householdID).Return([]householdEntities.Member{
+			{HouseholdID: householdID, UserID: otherID},
+			{HouseholdID: householdID, UserID: userID},
+		}, nil)
+		repo.On("GetLibraryVersion", mock.Anything, householdID).Return("v3", nil)
+		repo.On("GetLibrary", mock.Anything, householdID).Return(&entities.Library{
+			LibraryTotals: entities.LibraryTotals{Books: 40, Copies: 42, LentOut: 2},
+			Decades:       []entities.Count{{Name: "1960", Count: 7}},
+			UnreadShelves: []entities.Shelf{{LocationID: uuid.New(), Name: "Антресоль", Copies: 12}},
+		}, nil)
+
+		result, err := u.GetStats(context.Background(), userID)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.User.Ebooks)
+		require.NotNil(t, result.Household)
+		assert.Equal(t, 5, result.Household.Ebooks)
+		require.NotNil(t, result.Library)
+		assert.Equal(t, 2, result.Library.LentOut)
+		assert.Equal(t, "Антресоль", result.Library.UnreadShelves[0].Name)
+		assert.Empty(t, result.Library.Genres)
+	})
+
+	t.Run("cached until the data changes", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, now)
+		userID := uuid.New()
+		households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+
+		repo.On("GetVersion", mock.Anything, []uuid.UUID{userID}).Return("v1", nil).Twice()
+		repo.On("GetStats", mock.Anything, []uuid.UUID{userID}, since, "Europe/Moscow").
+			Return(&entities.Stats{Totals: entities.Totals{Ebooks: 1}}, nil).Once()
+
+		first, err := u.GetStats(context.Background(), userID)
+		require.NoError(t, err)
+		second, err := u.GetStats(context.Background(), userID)
+		require.NoError(t, err)
+		assert.Same(t, first.User, second.User)
+
+		repo.On("GetVersion", mock.Anything, []uuid.UUID{userID}).Return("v2", nil).Once()
+		repo.On("GetStats", mock.Anything, []uuid.UUID{userID}, since, "Europe/Moscow").
+			Return(&entities.Stats{Totals: entities.Totals{Ebooks: 2}}, nil).Once()
+
+		third, err := u.GetStats(context.Background(), userID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, third.User.Ebooks)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo, households := new(MockRepository), new(MockHouseholdRepository)
+		u := newUseCase(repo, households, now)
+		userID := uuid.New()
+
+		repo.On("GetVersion", mock.Anything, []uuid.UUID{userID}).Return("", errors.New("db down"))
+
+		_, err := u.GetStats(context.Background(), userID)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCacheKey(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	assert.Equal(t, cacheKey([]uuid.UUID{a, b}), cacheKey([]uuid.UUID{b, a}))
+	assert.NotEqual(t, cacheKey([]uuid.UUID{a}), cacheKey([]uuid.UUID{a, b}))
+}
@@ -32,8 +32,13 @@ func (h *handler) CreateUser(c echo.Context) error {
 
 	userID, err := h.u.CreateUser(context.Background(), payload)
 	if err != nil {
-		if errors.Is(err, customErrors.ErrUserAlreadyExist) {
+		switch {
+		case errors.Is(err, customErrors.ErrUserAlreadyExist):
 			return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "User with this email or phone number already exists", nil))
+		case errors.Is(err, customErrors.ErrInvalidEmail):
+			return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Invalid email address", nil))
+		case errors.Is(err, customErrors.ErrInvalidPhoneNumber):
+			return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Invalid phone number", nil))
 		}
 		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Failed to create user", nil))
 	}
@@ -31,6 +31,16 @@ func (m *MockUseCase) SignInUser(ctx context.Context, payload dtos.SignInUserReq
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockUseCase) Authenticate(ctx context.Context, email string, password string) (uuid.UUID, error) {
+	args := m.Called(ctx, email, password)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
 func TestCreateUser(t *testing.T) {
 	e := echo.New()
 
@@ -133,6 +143,39 @@ func TestCreateUser(t *testing.T) {
 		mockUseCase.AssertExpectations(t)
 	})
 
+	t.Run("invalid phone number", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		payload := dtos.CreateUserRequest{
+			FirstName:   "Evgeny",
+			LastName:    "Koveshnikov",
+			Email:       "evgeny@example.com",
+			PhoneNumber: "8 (900) 12",
+			Password:    "password123",
+		}
+
+		jsonPayload, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/sign-up", strings.NewReader(string(jsonPayload)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		mockUseCase.On("CreateUser", context.Background(), payload).Return(uuid.Nil, customErrors.ErrInvalidPhoneNumber)
+
+		err := handler.CreateUser(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var response dtos.ErrorResponse
+		err = json.Unmarshal(rec.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "Invalid phone number", response.Message)
+
+		mockUseCase.AssertExpectations(t)
+	})
+
 	t.Run("internal server error", func(t *testing.T) {
 		mockUseCase := new(MockUseCase)
 		handler := NewHandler(mockUseCase)
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/user/dtos"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+// BasicAuth authenticates requests with HTTP Basic credentials checked
+// against the user accounts, for clients such as e-readers that cannot go
+// through sign-in for a bearer token. The user ID ends up where
+// jwt.UserIDFromContext finds it.
+func (h *handler) BasicAuth(realm string) echo.MiddlewareFunc {
+	return middleware.BasicAuthWithConfig(middleware.BasicAuthConfig{
+		Realm: realm,
+		Validator: func(email string, password string, c echo.Context) (bool, error) {
+			userID, err := h.u.Authenticate(c.Request().Context(), email, password)
+			switch {
+			case errors.Is(err, customErrors.ErrInvalidCredentials), errors.Is(err, customErrors.ErrUserInactive):
+				return false, nil
+			case err != nil:
+				return false, err
+			}
+
+			jwt.SetUserID(c, userID)
+			return true, nil
+		},
+	})
+}
+
+// AdminOnly lets through authenticated administrators only. It goes after
+// the middleware that authenticates the user.
+func (h *handler) AdminOnly(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, ok := jwt.UserIDFromContext(c)
+		if !ok {
+			return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+		}
+
+		admin, err := h.u.IsAdmin(c.Request().Context(), userID)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to check administrator")
+			return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+		}
+		if !admin {
+			return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+		}
+
+		return next(c)
+	}
+}
@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"encoding/base64"
+	"errors"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBasicAuth(t *testing.T) {
+	e := echo.New()
+
+	serve := func(mockUseCase *MockUseCase, authorization string) (*httptest.ResponseRecorder, uuid.UUID) {
+		var seen uuid.UUID
+		next := func(c echo.Context) error {
+			seen, _ = jwt.UserIDFromContext(c)
+			return c.NoContent(http.StatusOK)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/opds", nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		err := NewHandler(mockUseCase).BasicAuth("Home Library")(next)(c)
+		if err != nil {
+			e.HTTPErrorHandler(err, c)
+		}
+		return rec, seen
+	}
+
+	basic := func(email, password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(email+":"+password))
+	}
+
+	t.Run("valid credentials", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("Authenticate", mock.Anything, "evgeny@example.com", "pass:word").Return(userID, nil)
+
+		rec, seen := serve(mockUseCase, basic("evgeny@example.com", "pass:word"))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, userID, seen)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+
+		mockUseCase.On("Authenticate", mock.Anything, "evgeny@example.com", "wrong").Return(uuid.Nil, customErrors.ErrInvalidCredentials)
+
+		rec, seen := serve(mockUseCase, basic("evgeny@example.com", "wrong"))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, `basic realm="Home Library"`, rec.Header().Get(echo.HeaderWWWAuthenticate))
+		assert.Equal(t, uuid.Nil, seen)
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		rec, _ := serve(new(MockUseCase), "")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get(echo.HeaderWWWAuthenticate))
+	})
+}
+
+func TestAdminOnly(t *testing.T) {
+	e := echo.New()
+
+	serve := func(mockUseCase *MockUseCase, userID uuid.UUID) *httptest.ResponseRecorder {
+		next := func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/admin/jobs", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if userID != uuid.Nil {
+			jwt.SetUserID(c, userID)
+		}
+
+		_ = NewHandler(mockUseCase).AdminOnly(next)(c)
+		return rec
+	}
+
+	t.Run("administrator", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("IsAdmin", mock.Anything, userID).Return(true, nil)
+
+		assert.Equal(t, http.StatusOK, serve(mockUseCase, userID).Code)
+	})
+
+	t.Run("regular user", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("IsAdmin", mock.Anything, userID).Return(false, nil)
+
+		assert.Equal(t, http.StatusForbidden, serve(mockUseCase, userID).Code)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(new(MockUseCase), uuid.Nil).Code)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("IsAdmin", mock.Anything, userID).Return(false, errors.New("db down"))
+
+		assert.Equal(t, http.StatusInternalServerError, serve(mockUseCase, userID).Code)
+	})
+}
@@ -8,7 +8,7 @@ import (
 type CreateUserRequest struct {
 	FirstName   string `json:"first_name" validate:"required,min=2,max=50"`
 	LastName    string `json:"last_name" validate:"required,min=2,max=50"`
-	PhoneNumber string `json:"phone_number" validate:"required,e164"`
+	PhoneNumber string `json:"phone_number" validate:"required,max=32"`
 	Email       string `json:"email" validate:"required,email"`
 	Password    string `json:"password" validate:"required,min=8"`
 }
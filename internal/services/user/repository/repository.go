@@ -3,6 +3,10 @@ package repository
 import (
 	"context"
 	"home-library/internal/services/user/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/events"
+	"home-library/pkg/storage"
+	"home-library/pkg/transaction"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
@@ -11,17 +15,26 @@ import (
 type Repository interface {
 	CreateUser(ctx context.Context, user *entities.User) (uuid.UUID, error)
 	GetUserByEmail(ctx context.Context, email string) (*entities.User, error)
-	IsUserExist(ctx context.Context, email string, phoneNumber string) (bool, error)
+	GetUserByID(ctx context.Context, userID uuid.UUID) (*entities.User, error)
+}
+
+// constraints translates violations of the users table constraints. Both
+// indexes only cover users that are not deleted.
+var constraints = storage.Constraints{
+	"idx_users_email_lower":  errors.ErrUserAlreadyExist,
+	"idx_users_phone_number": errors.ErrUserAlreadyExist,
 }
 
 type repository struct {
-	db *sqlx.DB
+	db *transaction.DB
 }
 
 func NewRepository(db *sqlx.DB) Repository {
-	return &repository{db: db}
+	return &repository{db: transaction.Wrap(db)}
 }
 
+// CreateUser returns ErrUserAlreadyExist if the email or the phone number is
+// taken, which also settles concurrent sign-ups with the same details.
 func (r *repository) CreateUser(ctx context.Context, user *entities.User) (uuid.UUID, error) {
 	query := `
 		INSERT INTO users (
@@ -33,9 +46,19 @@ func (r *repository) CreateUser(ctx context.Context, user *entities.User) (uuid.
 		)
 	`
 
-	_, err := r.db.NamedExecContext(ctx, query, user)
+	err := r.withTx(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExecContext(ctx, query, user); err != nil {
+			return err
+		}
+
+		return events.Record(ctx, tx, events.TypeUserRegistered, user.UserID, events.UserRegistered{
+			UserID:    user.UserID,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+		})
+	})
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, constraints.Map(err)
 	}
 
 	return user.UserID, nil
@@ -44,8 +67,8 @@ func (r *repository) CreateUser(ctx context.Context, user *entities.User) (uuid.
 func (r *repository) GetUserByEmail(ctx context.Context, email string) (*entities.User, error) {
 	var user entities.User
 	query := `
-		SELECT * FROM users 
-		WHERE email = $1 AND deleted_at IS NULL
+		SELECT * FROM users
+		WHERE lower(email) = lower($1) AND deleted_at IS NULL
 	`
 
 	err := r.db.GetContext(ctx, &user, query, email)
@@ -56,17 +79,21 @@ func (r *repository) GetUserByEmail(ctx context.Context, email string) (*entitie
 	return &user, nil
 }
 
-func (r *repository) IsUserExist(ctx context.Context, email string, phoneNumber string) (bool, error) {
-	var count int
+func (r *repository) GetUserByID(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	var user entities.User
 	query := `
-		SELECT COUNT(*) FROM users 
-		WHERE email = $1 OR phone_number = $2 AND deleted_at IS NULL
+		SELECT * FROM users
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
 
-	err := r.db.GetContext(ctx, &count, query, email, phoneNumber)
+	err := r.db.GetContext(ctx, &user, query, userID)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return count > 0, nil
+	return &user, nil
+}
+
+func (r *repository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	return r.db.InTx(ctx, nil, fn)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"home-library/internal/services/user/entities"
+	customErrors "home-library/pkg/errors"
 	"testing"
 	"time"
 
@@ -40,6 +41,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -54,6 +56,10 @@ func TestCreateUser(t *testing.T) {
 				user.UpdatedAt,
 			).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox_events").
+			WithArgs(sqlmock.AnyArg(), "user.registered", userID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
@@ -78,6 +84,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -92,6 +99,10 @@ func TestCreateUser(t *testing.T) {
 				user.UpdatedAt,
 			).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO outbox_events").
+			WithArgs(sqlmock.AnyArg(), "user.registered", userID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
@@ -116,6 +127,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -130,13 +142,15 @@ func TestCreateUser(t *testing.T) {
 				user.UpdatedAt,
 			).
 			WillReturnError(&pq.Error{
-				Code:    "23505",
-				Message: "duplicate key value violates unique constraint \"users_email_key\"",
+				Code:       "23505",
+				Message:    "duplicate key value violates unique constraint \"idx_users_email_lower\"",
+				Constraint: "idx_users_email_lower",
 			})
+		mock.ExpectRollback()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, customErrors.ErrUserAlreadyExist)
 		assert.Equal(t, uuid.Nil, id)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -157,6 +171,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -171,13 +186,15 @@ func TestCreateUser(t *testing.T) {
 				user.UpdatedAt,
 			).
 			WillReturnError(&pq.Error{
-				Code:    "23505",
-				Message: "duplicate key value violates unique constraint \"users_phone_number_key\"",
+				Code:       "23505",
+				Message:    "duplicate key value violates unique constraint \"idx_users_phone_number\"",
+				Constraint: "idx_users_phone_number",
 			})
+		mock.ExpectRollback()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, customErrors.ErrUserAlreadyExist)
 		assert.Equal(t, uuid.Nil, id)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -198,6 +215,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -215,6 +233,7 @@ func TestCreateUser(t *testing.T) {
 				Code:    "23514",
 				Message: "new row for relation \"users\" violates check constraint \"users_email_check\"",
 			})
+		mock.ExpectRollback()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
@@ -239,6 +258,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -256,6 +276,7 @@ func TestCreateUser(t *testing.T) {
 				Code:    "23514",
 				Message: "new row for relation \"users\" violates check constraint \"users_password_check\"",
 			})
+		mock.ExpectRollback()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
@@ -280,6 +301,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -297,6 +319,7 @@ func TestCreateUser(t *testing.T) {
 				Code:    "23514",
 				Message: "new row for relation \"users\" violates check constraint \"users_user_type_check\"",
 			})
+		mock.ExpectRollback()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
@@ -321,6 +344,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -338,6 +362,7 @@ func TestCreateUser(t *testing.T) {
 				Code:    "23514",
 				Message: "new row for relation \"users\" violates check constraint \"users_first_name_check\"",
 			})
+		mock.ExpectRollback()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
@@ -362,6 +387,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -379,6 +405,7 @@ func TestCreateUser(t *testing.T) {
 				Code:    "23514",
 				Message: "new row for relation \"users\" violates check constraint \"users_last_name_check\"",
 			})
+		mock.ExpectRollback()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
@@ -403,6 +430,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -420,6 +448,7 @@ func TestCreateUser(t *testing.T) {
 				Code:    "23514",
 				Message: "new row for relation \"users\" violates check constraint \"users_phone_number_check\"",
 			})
+		mock.ExpectRollback()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
@@ -444,6 +473,7 @@ func TestCreateUser(t *testing.T) {
 			UpdatedAt:   now,
 		}
 
+		mock.ExpectBegin()
 		mock.ExpectExec("INSERT INTO users").
 			WithArgs(
 				userID,
@@ -461,6 +491,7 @@ func TestCreateUser(t *testing.T) {
 				Code:    "08006",
 				Message: "connection to database failed",
 			})
+		mock.ExpectRollback()
 
 		id, err := repo.CreateUser(context.Background(), user)
 
@@ -512,7 +543,7 @@ func TestGetUserByEmail(t *testing.T) {
 			expectedUser.UpdatedAt,
 		)
 
-		mock.ExpectQuery("SELECT \\* FROM users WHERE email = \\$1 AND deleted_at IS NULL").
+		mock.ExpectQuery("SELECT \\* FROM users WHERE lower\\(email\\) = lower\\(\\$1\\) AND deleted_at IS NULL").
 			WithArgs(expectedUser.Email).
 			WillReturnRows(rows)
 
@@ -526,7 +557,7 @@ func TestGetUserByEmail(t *testing.T) {
 	t.Run("user not found", func(t *testing.T) {
 		email := "nonexistent@example.com"
 
-		mock.ExpectQuery("SELECT \\* FROM users WHERE email = \\$1 AND deleted_at IS NULL").
+		mock.ExpectQuery("SELECT \\* FROM users WHERE lower\\(email\\) = lower\\(\\$1\\) AND deleted_at IS NULL").
 			WithArgs(email).
 			WillReturnError(sql.ErrNoRows)
 
@@ -540,7 +571,7 @@ func TestGetUserByEmail(t *testing.T) {
 	t.Run("user is deleted", func(t *testing.T) {
 		email := "deleted@example.com"
 
-		mock.ExpectQuery("SELECT \\* FROM users WHERE email = \\$1 AND deleted_at IS NULL").
+		mock.ExpectQuery("SELECT \\* FROM users WHERE lower\\(email\\) = lower\\(\\$1\\) AND deleted_at IS NULL").
 			WithArgs(email).
 			WillReturnError(sql.ErrNoRows)
 
@@ -554,7 +585,7 @@ func TestGetUserByEmail(t *testing.T) {
 	t.Run("database connection error", func(t *testing.T) {
 		email := "test@example.com"
 
-		mock.ExpectQuery("SELECT \\* FROM users WHERE email = \\$1 AND deleted_at IS NULL").
+		mock.ExpectQuery("SELECT \\* FROM users WHERE lower\\(email\\) = lower\\(\\$1\\) AND deleted_at IS NULL").
 			WithArgs(email).
 			WillReturnError(&pq.Error{
 				Code:    "08006",
@@ -571,7 +602,7 @@ func TestGetUserByEmail(t *testing.T) {
 	t.Run("empty email", func(t *testing.T) {
 		email := ""
 
-		mock.ExpectQuery("SELECT \\* FROM users WHERE email = \\$1 AND deleted_at IS NULL").
+		mock.ExpectQuery("SELECT \\* FROM users WHERE lower\\(email\\) = lower\\(\\$1\\) AND deleted_at IS NULL").
 			WithArgs(email).
 			WillReturnError(&pq.Error{
 				Code:    "23514",
@@ -586,7 +617,7 @@ func TestGetUserByEmail(t *testing.T) {
 	})
 }
 
-func TestIsUserExist(t *testing.T) {
+func TestGetUserByID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("failed to create sqlmock: %v", err)
@@ -596,123 +627,35 @@ func TestIsUserExist(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	repo := NewRepository(sqlxDB)
 
-	t.Run("user exists by email", func(t *testing.T) {
-		email := "evgeny@example.com"
-		phoneNumber := "+79001234567"
-
-		rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-
-		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE email = \\$1 OR phone_number = \\$2 AND deleted_at IS NULL").
-			WithArgs(email, phoneNumber).
-			WillReturnRows(rows)
-
-		exists, err := repo.IsUserExist(context.Background(), email, phoneNumber)
-
-		assert.NoError(t, err)
-		assert.True(t, exists)
-		assert.NoError(t, mock.ExpectationsWereMet())
-	})
-
-	t.Run("user exists by phone number", func(t *testing.T) {
-		email := "nonexistent@example.com"
-		phoneNumber := "+79001234567"
-
-		rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-
-		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE email = \\$1 OR phone_number = \\$2 AND deleted_at IS NULL").
-			WithArgs(email, phoneNumber).
-			WillReturnRows(rows)
-
-		exists, err := repo.IsUserExist(context.Background(), email, phoneNumber)
-
-		assert.NoError(t, err)
-		assert.True(t, exists)
-		assert.NoError(t, mock.ExpectationsWereMet())
-	})
-
-	t.Run("user exists by both email and phone number", func(t *testing.T) {
-		email := "evgeny@example.com"
-		phoneNumber := "+79001234567"
-
-		rows := sqlmock.NewRows([]string{"count"}).AddRow(1)
-
-		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE email = \\$1 OR phone_number = \\$2 AND deleted_at IS NULL").
-			WithArgs(email, phoneNumber).
-			WillReturnRows(rows)
-
-		exists, err := repo.IsUserExist(context.Background(), email, phoneNumber)
-
-		assert.NoError(t, err)
-		assert.True(t, exists)
-		assert.NoError(t, mock.ExpectationsWereMet())
-	})
-
-	t.Run("user does not exist", func(t *testing.T) {
-		email := "nonexistent@example.com"
-		phoneNumber := "+79001234568"
-
-		rows := sqlmock.NewRows([]string{"count"}).AddRow(0)
-
-		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE email = \\$1 OR phone_number = \\$2 AND deleted_at IS NULL").
-			WithArgs(email, phoneNumber).
-			WillReturnRows(rows)
-
-		exists, err := repo.IsUserExist(context.Background(), email, phoneNumber)
-
-		assert.NoError(t, err)
-		assert.False(t, exists)
-		assert.NoError(t, mock.ExpectationsWereMet())
-	})
-
-	t.Run("user is deleted", func(t *testing.T) {
-		email := "deleted@example.com"
-		phoneNumber := "+79001234569"
+	t.Run("successfully get user by id", func(t *testing.T) {
+		userID := uuid.New()
 
-		rows := sqlmock.NewRows([]string{"count"}).AddRow(0)
+		rows := sqlmock.NewRows([]string{"user_id", "user_type", "is_active"}).
+			AddRow(userID, "admin", true)
 
-		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE email = \\$1 OR phone_number = \\$2 AND deleted_at IS NULL").
-			WithArgs(email, phoneNumber).
+		mock.ExpectQuery("SELECT \\* FROM users WHERE user_id = \\$1 AND deleted_at IS NULL").
+			WithArgs(userID).
 			WillReturnRows(rows)
 
-		exists, err := repo.IsUserExist(context.Background(), email, phoneNumber)
+		user, err := repo.GetUserByID(context.Background(), userID)
 
 		assert.NoError(t, err)
-		assert.False(t, exists)
+		assert.Equal(t, userID, user.UserID)
+		assert.Equal(t, entities.UserTypeAdmin, user.UserType)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("database connection error", func(t *testing.T) {
-		email := "test@example.com"
-		phoneNumber := "+79001234570"
-
-		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE email = \\$1 OR phone_number = \\$2 AND deleted_at IS NULL").
-			WithArgs(email, phoneNumber).
-			WillReturnError(&pq.Error{
-				Code:    "08006",
-				Message: "connection to database failed",
-			})
-
-		exists, err := repo.IsUserExist(context.Background(), email, phoneNumber)
-
-		assert.Error(t, err)
-		assert.False(t, exists)
-		assert.NoError(t, mock.ExpectationsWereMet())
-	})
-
-	t.Run("empty parameters", func(t *testing.T) {
-		email := ""
-		phoneNumber := ""
-
-		rows := sqlmock.NewRows([]string{"count"}).AddRow(0)
+	t.Run("user not found", func(t *testing.T) {
+		userID := uuid.New()
 
-		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE email = \\$1 OR phone_number = \\$2 AND deleted_at IS NULL").
-			WithArgs(email, phoneNumber).
-			WillReturnRows(rows)
+		mock.ExpectQuery("SELECT \\* FROM users WHERE user_id = \\$1").
+			WithArgs(userID).
+			WillReturnError(sql.ErrNoRows)
 
-		exists, err := repo.IsUserExist(context.Background(), email, phoneNumber)
+		user, err := repo.GetUserByID(context.Background(), userID)
 
-		assert.NoError(t, err)
-		assert.False(t, exists)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Nil(t, user)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
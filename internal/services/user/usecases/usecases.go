@@ -2,11 +2,14 @@ package usecases
 
 import (
 	"context"
+	"database/sql"
+	stdErrors "errors"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"home-library/internal/services/user/dtos"
 	"home-library/internal/services/user/entities"
 	"home-library/internal/services/user/repository"
+	"home-library/pkg/contact"
 	"home-library/pkg/errors"
 	"home-library/pkg/jwt"
 )
@@ -14,24 +17,36 @@ import (
 type UseCase interface {
 	CreateUser(ctx context.Context, payload dtos.CreateUserRequest) (userID uuid.UUID, err error)
 	SignInUser(ctx context.Context, payload dtos.SignInUserRequest) (token string, err error)
+	Authenticate(ctx context.Context, email string, password string) (userID uuid.UUID, err error)
+	IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error)
 }
 
+// defaultPhoneRegion applies when the configuration names no region.
+const defaultPhoneRegion = "RU"
+
 type useCase struct {
-	r   repository.Repository
-	jwt jwt.JWTService
+	r           repository.Repository
+	jwt         jwt.JWTService
+	phoneRegion string
 }
 
-func NewUseCase(r repository.Repository, jwt jwt.JWTService) UseCase {
-	return &useCase{r: r, jwt: jwt}
+func NewUseCase(r repository.Repository, jwt jwt.JWTService, phoneRegion string) UseCase {
+	if phoneRegion == "" {
+		phoneRegion = defaultPhoneRegion
+	}
+	return &useCase{r: r, jwt: jwt, phoneRegion: phoneRegion}
 }
 
+// CreateUser stores the email and the phone number normalized, so the same
+// person cannot sign up twice by writing them differently.
 func (u *useCase) CreateUser(ctx context.Context, payload dtos.CreateUserRequest) (userID uuid.UUID, err error) {
-	exist, err := u.r.IsUserExist(ctx, payload.Email, payload.PhoneNumber)
+	email, err := contact.NormalizeEmail(payload.Email)
 	if err != nil {
 		return uuid.Nil, err
 	}
-	if exist {
-		return uuid.Nil, errors.ErrUserAlreadyExist
+	phoneNumber, err := contact.NormalizePhone(payload.PhoneNumber, u.phoneRegion)
+	if err != nil {
+		return uuid.Nil, err
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(payload.Password), bcrypt.DefaultCost)
@@ -42,36 +57,68 @@ func (u *useCase) CreateUser(ctx context.Context, payload dtos.CreateUserRequest
 	user := entities.NewUser()
 	user.FirstName = payload.FirstName
 	user.LastName = payload.LastName
-	user.Email = payload.Email
-	user.PhoneNumber = payload.PhoneNumber
+	user.Email = email
+	user.PhoneNumber = phoneNumber
 	user.Password = string(hashedPassword)
 	user.UserType = entities.UserTypeUser
 	user.IsActive = true
 
+	// A taken email or phone number is reported by the unique constraints;
+	// checking first would still let concurrent sign-ups through.
 	return u.r.CreateUser(ctx, user)
 }
 
 func (u *useCase) SignInUser(ctx context.Context, payload dtos.SignInUserRequest) (token string, err error) {
-	user, err := u.r.GetUserByEmail(ctx, payload.Email)
+	userID, err := u.Authenticate(ctx, payload.Email, payload.Password)
+	if err != nil {
+		return "", err
+	}
+
+	token, err = u.jwt.GenerateToken(jwt.PayloadToken{
+		UserID: userID,
+	})
 	if err != nil {
-		return "", errors.ErrInvalidCredentials
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Authenticate checks the credentials without issuing a token. It backs
+// sign-in as well as clients that can only send HTTP Basic auth.
+func (u *useCase) Authenticate(ctx context.Context, email string, password string) (userID uuid.UUID, err error) {
+	email, err = contact.NormalizeEmail(email)
+	if err != nil {
+		return uuid.Nil, errors.ErrInvalidCredentials
+	}
+
+	user, err := u.r.GetUserByEmail(ctx, email)
+	if err != nil {
+		return uuid.Nil, errors.ErrInvalidCredentials
 	}
 
 	if !user.IsActive {
-		return "", errors.ErrUserInactive
+		return uuid.Nil, errors.ErrUserInactive
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password))
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", errors.ErrInvalidCredentials
+		return uuid.Nil, errors.ErrInvalidCredentials
 	}
 
-	token, err = u.jwt.GenerateToken(jwt.PayloadToken{
-		UserID: user.UserID,
-	})
+	return user.UserID, nil
+}
+
+// IsAdmin reports whether the user is an active administrator. Unknown and
+// deleted users are not.
+func (u *useCase) IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := u.r.GetUserByID(ctx, userID)
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
 	if err != nil {
-		return "", err
+		return false, err
 	}
 
-	return token, nil
+	return user.IsActive && user.UserType == entities.UserTypeAdmin, nil
 }
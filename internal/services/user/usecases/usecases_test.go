@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"home-library/internal/services/user/dtos"
 	"home-library/internal/services/user/entities"
@@ -33,9 +34,12 @@ func (m *MockRepository) GetUserByEmail(ctx context.Context, email string) (*ent
 	return args.Get(0).(*entities.User), args.Error(1)
 }
 
-func (m *MockRepository) IsUserExist(ctx context.Context, email string, phoneNumber string) (bool, error) {
-	args := m.Called(ctx, email, phoneNumber)
-	return args.Bool(0), args.Error(1)
+func (m *MockRepository) GetUserByID(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), args.Error(1)
 }
 
 type MockJWT struct {
@@ -56,7 +60,7 @@ func TestCreateUser(t *testing.T) {
 	t.Run("successfully create user", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockJWT := new(MockJWT)
-		useCase := NewUseCase(mockRepo, mockJWT)
+		useCase := NewUseCase(mockRepo, mockJWT, "RU")
 		userID := uuid.New()
 		payload := dtos.CreateUserRequest{
 			FirstName:   "Evgeny",
@@ -66,9 +70,6 @@ func TestCreateUser(t *testing.T) {
 			Password:    "password123",
 		}
 
-		mockRepo.On("IsUserExist", mock.Anything, payload.Email, payload.PhoneNumber).
-			Return(false, nil)
-
 		mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *entities.User) bool {
 			return user.FirstName == payload.FirstName &&
 				user.LastName == payload.LastName &&
@@ -88,7 +89,7 @@ func TestCreateUser(t *testing.T) {
 	t.Run("user already exists", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockJWT := new(MockJWT)
-		useCase := NewUseCase(mockRepo, mockJWT)
+		useCase := NewUseCase(mockRepo, mockJWT, "RU")
 		payload := dtos.CreateUserRequest{
 			FirstName:   "Evgeny",
 			LastName:    "Koveshnikov",
@@ -97,8 +98,8 @@ func TestCreateUser(t *testing.T) {
 			Password:    "password123",
 		}
 
-		mockRepo.On("IsUserExist", mock.Anything, payload.Email, payload.PhoneNumber).
-			Return(true, nil).Once()
+		mockRepo.On("CreateUser", mock.Anything, mock.Anything).
+			Return(uuid.Nil, customErrors.ErrUserAlreadyExist).Once()
 
 		id, err := useCase.CreateUser(context.Background(), payload)
 
@@ -111,7 +112,7 @@ func TestCreateUser(t *testing.T) {
 	t.Run("repository error", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockJWT := new(MockJWT)
-		useCase := NewUseCase(mockRepo, mockJWT)
+		useCase := NewUseCase(mockRepo, mockJWT, "RU")
 		payload := dtos.CreateUserRequest{
 			FirstName:   "Evgeny",
 			LastName:    "Koveshnikov",
@@ -121,8 +122,8 @@ func TestCreateUser(t *testing.T) {
 		}
 
 		expectedErr := errors.New("database error")
-		mockRepo.On("IsUserExist", mock.Anything, payload.Email, payload.PhoneNumber).
-			Return(false, expectedErr).Once()
+		mockRepo.On("CreateUser", mock.Anything, mock.Anything).
+			Return(uuid.Nil, expectedErr).Once()
 
 		id, err := useCase.CreateUser(context.Background(), payload)
 
@@ -135,7 +136,7 @@ func TestCreateUser(t *testing.T) {
 	t.Run("password is properly hashed", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockJWT := new(MockJWT)
-		useCase := NewUseCase(mockRepo, mockJWT)
+		useCase := NewUseCase(mockRepo, mockJWT, "RU")
 		userID := uuid.New()
 		payload := dtos.CreateUserRequest{
 			FirstName:   "Evgeny",
@@ -145,9 +146,6 @@ func TestCreateUser(t *testing.T) {
 			Password:    "password123",
 		}
 
-		mockRepo.On("IsUserExist", mock.Anything, payload.Email, payload.PhoneNumber).
-			Return(false, nil)
-
 		mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *entities.User) bool {
 			err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(payload.Password))
 			return err == nil
@@ -159,13 +157,73 @@ func TestCreateUser(t *testing.T) {
 		assert.Equal(t, userID, id)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("email and phone number are normalized", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, new(MockJWT), "RU")
+		payload := dtos.CreateUserRequest{
+			FirstName:   "Evgeny",
+			LastName:    "Koveshnikov",
+			Email:       " Evgeny@Пример.РФ ",
+			PhoneNumber: "8 (900) 123-45-67",
+			Password:    "password123",
+		}
+
+		mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *entities.User) bool {
+			return user.Email == "evgeny@xn--e1afmkfd.xn--p1ai" && user.PhoneNumber == "+79001234567"
+		})).Return(uuid.New(), nil)
+
+		_, err := useCase.CreateUser(context.Background(), payload)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid phone number", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, new(MockJWT), "RU")
+		payload := dtos.CreateUserRequest{
+			FirstName:   "Evgeny",
+			LastName:    "Koveshnikov",
+			Email:       "evgeny@example.com",
+			PhoneNumber: "+7 900 12",
+			Password:    "password123",
+		}
+
+		id, err := useCase.CreateUser(context.Background(), payload)
+
+		assert.Equal(t, customErrors.ErrInvalidPhoneNumber, err)
+		assert.Equal(t, uuid.Nil, id)
+		mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("region applies to national numbers", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, new(MockJWT), "GB")
+		payload := dtos.CreateUserRequest{
+			FirstName:   "Evgeny",
+			LastName:    "Koveshnikov",
+			Email:       "evgeny@example.com",
+			PhoneNumber: "020 7946 0958",
+			Password:    "password123",
+		}
+
+		mockRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *entities.User) bool {
+			return user.PhoneNumber == "+442079460958"
+		})).Return(uuid.New(), nil)
+
+		_, err := useCase.CreateUser(context.Background(), payload)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 func TestSignInUser(t *testing.T) {
 	t.Run("successful sign in", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockJWT := new(MockJWT)
-		useCase := NewUseCase(mockRepo, mockJWT)
+		useCase := NewUseCase(mockRepo, mockJWT, "RU")
 
 		userID := uuid.New()
 		email := "test@example.com"
@@ -198,7 +256,7 @@ func TestSignInUser(t *testing.T) {
 	t.Run("user not found", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockJWT := new(MockJWT)
-		useCase := NewUseCase(mockRepo, mockJWT)
+		useCase := NewUseCase(mockRepo, mockJWT, "RU")
 
 		email := "nonexistent@example.com"
 		payload := dtos.SignInUserRequest{
@@ -220,7 +278,7 @@ func TestSignInUser(t *testing.T) {
 	t.Run("inactive account", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockJWT := new(MockJWT)
-		useCase := NewUseCase(mockRepo, mockJWT)
+		useCase := NewUseCase(mockRepo, mockJWT, "RU")
 
 		userID := uuid.New()
 		email := "test@example.com"
@@ -253,7 +311,7 @@ func TestSignInUser(t *testing.T) {
 	t.Run("invalid password", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockJWT := new(MockJWT)
-		useCase := NewUseCase(mockRepo, mockJWT)
+		useCase := NewUseCase(mockRepo, mockJWT, "RU")
 
 		userID := uuid.New()
 		email := "test@example.com"
@@ -286,7 +344,7 @@ func TestSignInUser(t *testing.T) {
 	t.Run("jwt generation error", func(t *testing.T) {
 		mockRepo := new(MockRepository)
 		mockJWT := new(MockJWT)
-		useCase := NewUseCase(mockRepo, mockJWT)
+		useCase := NewUseCase(mockRepo, mockJWT, "RU")
 
 		userID := uuid.New()
 		email := "test@example.com"
@@ -316,3 +374,87 @@ func TestSignInUser(t *testing.T) {
 		mockJWT.AssertExpectations(t)
 	})
 }
+
+func TestAuthenticate(t *testing.T) {
+	password := "password123"
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+
+	tests := []struct {
+		name     string
+		user     *entities.User
+		password string
+		err      error
+	}{
+		{"valid credentials", &entities.User{Password: string(hashedPassword), IsActive: true}, password, nil},
+		{"invalid password", &entities.User{Password: string(hashedPassword), IsActive: true}, "wrong", customErrors.ErrInvalidCredentials},
+		{"inactive account", &entities.User{Password: string(hashedPassword)}, password, customErrors.ErrUserInactive},
+		{"user not found", nil, password, customErrors.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			useCase := NewUseCase(mockRepo, new(MockJWT), "RU")
+
+			if tt.user != nil {
+				tt.user.UserID = uuid.New()
+				mockRepo.On("GetUserByEmail", context.Background(), "test@example.com").Return(tt.user, nil)
+			} else {
+				mockRepo.On("GetUserByEmail", context.Background(), "test@example.com").Return(nil, errors.New("not found"))
+			}
+
+			userID, err := useCase.Authenticate(context.Background(), "test@example.com", tt.password)
+
+			assert.Equal(t, tt.err, err)
+			if tt.err == nil {
+				assert.Equal(t, tt.user.UserID, userID)
+			} else {
+				assert.Equal(t, uuid.Nil, userID)
+			}
+		})
+	}
+
+	t.Run("email is looked up normalized", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo, new(MockJWT), "RU")
+		user := &entities.User{UserID: uuid.New(), Password: string(hashedPassword), IsActive: true}
+
+		mockRepo.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil)
+
+		userID, err := useCase.Authenticate(context.Background(), "  Test@Example.com", password)
+
+		assert.NoError(t, err)
+		assert.Equal(t, user.UserID, userID)
+	})
+}
+
+func TestIsAdmin(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *entities.User
+		err      error
+		expected bool
+		fails    bool
+	}{
+		{"active admin", &entities.User{UserType: entities.UserTypeAdmin, IsActive: true}, nil, true, false},
+		{"inactive admin", &entities.User{UserType: entities.UserTypeAdmin}, nil, false, false},
+		{"regular user", &entities.User{UserType: entities.UserTypeUser, IsActive: true}, nil, false, false},
+		{"unknown user", nil, sql.ErrNoRows, false, false},
+		{"database error", nil, errors.New("db down"), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			useCase := NewUseCase(mockRepo, new(MockJWT), "RU")
+			userID := uuid.New()
+
+			mockRepo.On("GetUserByID", context.Background(), userID).Return(tt.user, tt.err)
+
+			admin, err := useCase.IsAdmin(context.Background(), userID)
+
+			assert.Equal(t, tt.expected, admin)
+			assert.Equal(t, tt.fails, err != nil)
+		})
+	}
+}
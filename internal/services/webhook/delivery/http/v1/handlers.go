@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"home-library/internal/services/webhook/dtos"
+	"home-library/internal/services/webhook/usecases"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/jwt"
+	"net/http"
+)
+
+type handler struct {
+	u usecases.UseCase
+}
+
+func NewHandler(u usecases.UseCase) *handler {
+	return &handler{u: u}
+}
+
+func (h *handler) CreateWebhook(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	var payload dtos.CreateWebhookRequest
+	if err := c.Bind(&payload); err != nil {
+		log.Error().Err(err).Msg("failed to bind request body")
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный формат запроса", nil))
+	}
+
+	if err := payload.Validate(); err != nil {
+		validatorErrors := dtos.FromValidatorErrors(err)
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Ошибка валидации", validatorErrors))
+	}
+
+	webhook, err := h.u.CreateWebhook(c.Request().Context(), userID, payload)
+	if err != nil {
+		return h.handleError(c, err, "failed to create webhook")
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *handler) GetWebhooks(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	list, err := h.u.GetWebhooks(c.Request().Context(), userID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get webhooks")
+	}
+
+	return c.JSON(http.StatusOK, list)
+}
+
+func (h *handler) DeleteWebhook(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	webhookID, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	if err := h.u.DeleteWebhook(c.Request().Context(), userID, webhookID); err != nil {
+		return h.handleError(c, err, "failed to delete webhook")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) GetDeliveries(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	webhookID, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	deliveries, err := h.u.GetDeliveries(c.Request().Context(), userID, webhookID)
+	if err != nil {
+		return h.handleError(c, err, "failed to get webhook deliveries")
+	}
+
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// SendTestEvent answers 200 with the delivery log entry whether or not the
+// receiver accepted the event; the entry says which.
+func (h *handler) SendTestEvent(c echo.Context) error {
+	userID, ok := jwt.UserIDFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dtos.NewErrorResponse(http.StatusUnauthorized, "Требуется авторизация", nil))
+	}
+
+	webhookID, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dtos.NewErrorResponse(http.StatusBadRequest, "Неверный идентификатор", nil))
+	}
+
+	delivery, err := h.u.SendTestEvent(c.Request().Context(), userID, webhookID)
+	if err != nil {
+		return h.handleError(c, err, "failed to send test webhook event")
+	}
+
+	return c.JSON(http.StatusOK, delivery)
+}
+
+func (h *handler) handleError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, customErrors.ErrWebhookNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вебхук не найден", nil))
+	case errors.Is(err, customErrors.ErrHouseholdNotFound):
+		return c.JSON(http.StatusNotFound, dtos.NewErrorResponse(http.StatusNotFound, "Вы не состоите в домашней библиотеке", nil))
+	case errors.Is(err, customErrors.ErrHouseholdForbidden):
+		return c.JSON(http.StatusForbidden, dtos.NewErrorResponse(http.StatusForbidden, "Недостаточно прав", nil))
+	default:
+		log.Error().Err(err).Msg(message)
+		return c.JSON(http.StatusInternalServerError, dtos.NewErrorResponse(http.StatusInternalServerError, "Внутренняя ошибка сервера", nil))
+	}
+}
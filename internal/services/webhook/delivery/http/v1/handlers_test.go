@@ -0,0 +1,211 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"home-library/internal/services/webhook/dtos"
+	customErrors "home-library/pkg/errors"
+	"home-library/pkg/events"
+	"home-library/pkg/jobs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) CreateWebhook(ctx context.Context, userID uuid.UUID, payload dtos.CreateWebhookRequest) (*dtos.CreateWebhookResponse, error) {
+	args := m.Called(ctx, userID, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.CreateWebhookResponse), args.Error(1)
+}
+
+func (m *MockUseCase) GetWebhooks(ctx context.Context, userID uuid.UUID) ([]dtos.WebhookResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.WebhookResponse), args.Error(1)
+}
+
+func (m *MockUseCase) DeleteWebhook(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) error {
+	return m.Called(ctx, userID, webhookID).Error(0)
+}
+
+func (m *MockUseCase) GetDeliveries(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) ([]dtos.DeliveryResponse, error) {
+	args := m.Called(ctx, userID, webhookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.DeliveryResponse), args.Error(1)
+}
+
+func (m *MockUseCase) SendTestEvent(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) (*dtos.DeliveryResponse, error) {
+	args := m.Called(ctx, userID, webhookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.DeliveryResponse), args.Error(1)
+}
+
+func (m *MockUseCase) Fanout(ctx context.Context, event *events.Event) error {
+	return m.Called(ctx, event).Error(0)
+}
+
+func (m *MockUseCase) Deliver(ctx context.Context, job *jobs.Job) error {
+	return m.Called(ctx, job).Error(0)
+}
+
+func newContext(e *echo.Echo, method string, target string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestCreateWebhook(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully create webhook", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+		payload := dtos.CreateWebhookRequest{URL: "http://192.168.1.10:8123/api/webhook/library", EventTypes: []string{"ebook.added"}, Household: true}
+
+		mockUseCase.On("CreateWebhook", mock.Anything, userID, payload).Return(&dtos.CreateWebhookResponse{
+			WebhookResponse: dtos.WebhookResponse{WebhookID: uuid.New(), URL: payload.URL, EventTypes: payload.EventTypes},
+			Secret:          "0123456789abcdef",
+		}, nil)
+
+		c, rec := newContext(e, http.MethodPost, "/webhooks", `{"url":"http://192.168.1.10:8123/api/webhook/library","event_types":["ebook.added"],"household":true}`, userID)
+		err := h.CreateWebhook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"secret":"0123456789abcdef"`)
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, http.MethodPost, "/webhooks", `{"url":"ftp://example.com","event_types":["book.burned"]}`, uuid.New())
+		err := h.CreateWebhook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"field":"URL"`)
+		assert.Contains(t, rec.Body.String(), `"field":"EventTypes[0]"`)
+	})
+
+	t.Run("not the household owner", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID := uuid.New()
+
+		mockUseCase.On("CreateWebhook", mock.Anything, userID, mock.Anything).Return(nil, customErrors.ErrHouseholdForbidden)
+
+		c, rec := newContext(e, http.MethodPost, "/webhooks", `{"url":"https://example.com/hook","household":true}`, userID)
+		err := h.CreateWebhook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, http.MethodPost, "/webhooks", `{}`, uuid.Nil)
+		err := h.CreateWebhook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	e := echo.New()
+
+	t.Run("webhook not found", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, webhookID := uuid.New(), uuid.New()
+
+		mockUseCase.On("DeleteWebhook", mock.Anything, userID, webhookID).Return(customErrors.ErrWebhookNotFound)
+
+		c, rec := newContext(e, http.MethodDelete, "/", "", userID)
+		c.SetParamNames("webhook_id")
+		c.SetParamValues(webhookID.String())
+		err := h.DeleteWebhook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		h := NewHandler(new(MockUseCase))
+
+		c, rec := newContext(e, http.MethodDelete, "/", "", uuid.New())
+		c.SetParamNames("webhook_id")
+		c.SetParamValues("hook")
+		err := h.DeleteWebhook(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestSendTestEvent(t *testing.T) {
+	e := echo.New()
+
+	t.Run("failed delivery is reported", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, webhookID := uuid.New(), uuid.New()
+
+		mockUseCase.On("SendTestEvent", mock.Anything, userID, webhookID).Return(&dtos.DeliveryResponse{
+			EventType: "webhook.test",
+			Attempt:   1,
+			Error:     "connection refused",
+		}, nil)
+
+		c, rec := newContext(e, http.MethodPost, "/", "", userID)
+		c.SetParamNames("webhook_id")
+		c.SetParamValues(webhookID.String())
+		err := h.SendTestEvent(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"succeeded":false`)
+		assert.Contains(t, rec.Body.String(), `"error":"connection refused"`)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		h := NewHandler(mockUseCase)
+		userID, webhookID := uuid.New(), uuid.New()
+
+		mockUseCase.On("SendTestEvent", mock.Anything, userID, webhookID).Return(nil, errors.New("db down"))
+
+		c, rec := newContext(e, http.MethodPost, "/", "", userID)
+		c.SetParamNames("webhook_id")
+		c.SetParamValues(webhookID.String())
+		err := h.SendTestEvent(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -0,0 +1,11 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) WebhookRoutes(domain *echo.Group) {
+	domain.POST("/webhooks", h.CreateWebhook)
+	domain.GET("/webhooks", h.GetWebhooks)
+	domain.DELETE("/webhooks/:webhook_id", h.DeleteWebhook)
+	domain.GET("/webhooks/:webhook_id/deliveries", h.GetDeliveries)
+	domain.POST("/webhooks/:webhook_id/test", h.SendTestEvent)
+}
@@ -0,0 +1,77 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/webhook/entities"
+	"time"
+)
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" validate:"required,http_url,max=2048"`
+	// EventTypes limits the webhook to some events; empty means all of them.
+	EventTypes []string `json:"event_types" validate:"max=16,dive,oneof=user.registered ebook.added ebook.deleted book.added loan.created loan.returned"`
+	// Household makes the webhook receive the events of the whole household.
+	Household bool `json:"household"`
+}
+
+func (r *CreateWebhookRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type WebhookResponse struct {
+	WebhookID   uuid.UUID  `json:"webhook_id"`
+	HouseholdID *uuid.UUID `json:"household_id,omitempty"`
+	URL         string     `json:"url"`
+	EventTypes  []string   `json:"event_types"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateWebhookResponse is the only response that reveals the signing
+// secret.
+type CreateWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type DeliveryResponse struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+	EventID    uuid.UUID `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	Succeeded  bool      `json:"succeeded"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int       `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func NewWebhookResponse(webhook entities.Webhook) WebhookResponse {
+	eventTypes := []string(webhook.EventTypes)
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	return WebhookResponse{
+		WebhookID:   webhook.WebhookID,
+		HouseholdID: webhook.HouseholdID,
+		URL:         webhook.URL,
+		EventTypes:  eventTypes,
+		CreatedAt:   webhook.CreatedAt,
+	}
+}
+
+func NewDeliveryResponse(delivery entities.Delivery) DeliveryResponse {
+	return DeliveryResponse{
+		DeliveryID: delivery.DeliveryID,
+		EventID:    delivery.EventID,
+		EventType:  delivery.EventType,
+		Attempt:    delivery.Attempt,
+		Succeeded:  delivery.Succeeded(),
+		StatusCode: delivery.StatusCode,
+		Error:      delivery.Error,
+		DurationMs: delivery.DurationMs,
+		CreatedAt:  delivery.CreatedAt,
+	}
+}
@@ -0,0 +1,69 @@
+package entities
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// secretSize is the number of random bytes in a signing secret.
+const secretSize = 32
+
+// Webhook is a subscription to library events. A personal webhook gets the
+// events of its owner; one with a household gets the events of every member.
+type Webhook struct {
+	WebhookID   uuid.UUID      `db:"webhook_id"`
+	OwnerID     uuid.UUID      `db:"owner_id"`
+	HouseholdID *uuid.UUID     `db:"household_id"`
+	URL         string         `db:"url"`
+	Secret      string         `db:"secret"`
+	EventTypes  pq.StringArray `db:"event_types"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+func NewWebhook(ownerID uuid.UUID, url string, eventTypes []string) (*Webhook, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Webhook{
+		WebhookID:  uuid.New(),
+		OwnerID:    ownerID,
+		URL:        url,
+		Secret:     secret,
+		EventTypes: pq.StringArray(eventTypes),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// Delivery is the log entry of one attempt to deliver an event.
+type Delivery struct {
+	DeliveryID uuid.UUID `db:"delivery_id"`
+	WebhookID  uuid.UUID `db:"webhook_id"`
+	EventID    uuid.UUID `db:"event_id"`
+	EventType  string    `db:"event_type"`
+	Attempt    int       `db:"attempt"`
+	StatusCode int       `db:"status_code"`
+	Error      string    `db:"error"`
+	DurationMs int       `db:"duration_ms"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (d *Delivery) Succeeded() bool {
+	return d.Error == ""
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, secretSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
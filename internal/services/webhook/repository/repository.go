@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/webhook/entities"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type Repository interface {
+	CreateWebhook(ctx context.Context, webhook *entities.Webhook) (uuid.UUID, error)
+	GetWebhook(ctx context.Context, webhookID uuid.UUID) (*entities.Webhook, error)
+	GetWebhooks(ctx context.Context, ownerID uuid.UUID, householdID *uuid.UUID) ([]entities.Webhook, error)
+	GetSubscribedWebhooks(ctx context.Context, userID uuid.UUID, eventType string) ([]entities.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error
+
+	CreateDelivery(ctx context.Context, delivery *entities.Delivery) error
+	GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]entities.Delivery, error)
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateWebhook(ctx context.Context, webhook *entities.Webhook) (uuid.UUID, error) {
+	query := `
+		INSERT INTO webhooks (
+			webhook_id, owner_id, household_id, url, secret,
+			event_types, created_at, updated_at
+		) VALUES (
+			:webhook_id, :owner_id, :household_id, :url, :secret,
+			:event_types, :created_at, :updated_at
+		)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, webhook)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return webhook.WebhookID, nil
+}
+
+func (r *repository) GetWebhook(ctx context.Context, webhookID uuid.UUID) (*entities.Webhook, error) {
+	var webhook entities.Webhook
+	query := `SELECT * FROM webhooks WHERE webhook_id = $1`
+
+	err := r.db.GetContext(ctx, &webhook, query, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// GetWebhooks returns the personal webhooks of the owner and, when a
+// household is given, the webhooks of the household.
+func (r *repository) GetWebhooks(ctx context.Context, ownerID uuid.UUID, householdID *uuid.UUID) ([]entities.Webhook, error) {
+	webhooks := make([]entities.Webhook, 0)
+	query := `
+		SELECT * FROM webhooks
+		WHERE (household_id IS NULL AND owner_id = $1) OR household_id = $2
+		ORDER BY created_at
+	`
+
+	err := r.db.SelectContext(ctx, &webhooks, query, ownerID, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// GetSubscribedWebhooks returns the webhooks that receive an event of the
+// given type about the user: the user's personal ones and those of the
+// user's household. An empty list of event types subscribes to all of them.
+func (r *repository) GetSubscribedWebhooks(ctx context.Context, userID uuid.UUID, eventType string) ([]entities.Webhook, error) {
+	webhooks := make([]entities.Webhook, 0)
+	query := `
+		SELECT w.* FROM webhooks w
+		WHERE (cardinality(w.event_types) = 0 OR $2 = ANY(w.event_types)) AND (
+			(w.household_id IS NULL AND w.owner_id = $1) OR
+			w.household_id = (SELECT household_id FROM household_members WHERE user_id = $1)
+		)
+	`
+
+	err := r.db.SelectContext(ctx, &webhooks, query, userID, eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (r *repository) DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error {
+	query := `DELETE FROM webhooks WHERE webhook_id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, webhookID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) CreateDelivery(ctx context.Context, delivery *entities.Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (
+			delivery_id, webhook_id, event_id, event_type, attempt,
+			status_code, error, duration_ms, created_at
+		) VALUES (
+			:delivery_id, :webhook_id, :event_id, :event_type, :attempt,
+			:status_code, :error, :duration_ms, :created_at
+		)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, delivery)
+	return err
+}
+
+// GetDeliveries returns the latest delivery attempts of the webhook, newest
+// first.
+func (r *repository) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]entities.Delivery, error) {
+	deliveries := make([]entities.Delivery, 0)
+	query := `
+		SELECT * FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	err := r.db.SelectContext(ctx, &deliveries, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *repository) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM webhook_deliveries WHERE created_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
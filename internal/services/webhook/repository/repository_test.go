@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/webhook/entities"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+var webhookColumns = []string{"webhook_id", "owner_id", "household_id", "url", "secret", "event_types", "created_at", "updated_at"}
+
+func TestCreateWebhook(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	householdID := uuid.New()
+
+	webhook, err := entities.NewWebhook(uuid.New(), "https://hass.local/api/webhook/library", []string{"ebook.added"})
+	require.NoError(t, err)
+	webhook.HouseholdID = &householdID
+
+	mock.ExpectExec("INSERT INTO webhooks").
+		WithArgs(webhook.WebhookID, webhook.OwnerID, webhook.HouseholdID, webhook.URL, webhook.Secret, webhook.EventTypes, webhook.CreatedAt, webhook.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	id, err := repo.CreateWebhook(context.Background(), webhook)
+
+	assert.NoError(t, err)
+	assert.Equal(t, webhook.WebhookID, id)
+	assert.Len(t, webhook.Secret, 64)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSubscribedWebhooks(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	userID, webhookID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("(cardinality(w.event_types) = 0 OR $2 = ANY(w.event_types))")).
+		WithArgs(userID, "ebook.added").
+		WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow(webhookID, userID, nil, "https://example.com/hook", "secret", pq.StringArray{}, time.Now(), time.Now()))
+
+	webhooks, err := repo.GetSubscribedWebhooks(context.Background(), userID, "ebook.added")
+
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, webhookID, webhooks[0].WebhookID)
+	assert.Nil(t, webhooks[0].HouseholdID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	webhookID := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM webhooks WHERE webhook_id = $1")).
+		WithArgs(webhookID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.DeleteWebhook(context.Background(), webhookID)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliveries(t *testing.T) {
+	repo, mock := newMockRepository(t)
+	delivery := &entities.Delivery{
+		DeliveryID: uuid.New(),
+		WebhookID:  uuid.New(),
+		EventID:    uuid.New(),
+		EventType:  "ebook.added",
+		Attempt:    2,
+		StatusCode: 502,
+		Error:      "unexpected response status 502 Bad Gateway",
+		DurationMs: 120,
+		CreatedAt:  time.Now(),
+	}
+
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs(delivery.DeliveryID, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Attempt,
+			delivery.StatusCode, delivery.Error, delivery.DurationMs, delivery.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.CreateDelivery(context.Background(), delivery))
+
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY created_at DESC")).
+		WithArgs(delivery.WebhookID, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"delivery_id", "webhook_id", "event_id", "event_type", "attempt", "status_code", "error", "duration_ms", "created_at"}).
+			AddRow(delivery.DeliveryID, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Attempt,
+				delivery.StatusCode, delivery.Error, delivery.DurationMs, delivery.CreatedAt))
+
+	deliveries, err := repo.GetDeliveries(context.Background(), delivery.WebhookID, 50)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.False(t, deliveries[0].Succeeded())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
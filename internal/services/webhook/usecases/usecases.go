@@ -0,0 +1,310 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	stdErrors "errors"
+	householdEntities "home-library/internal/services/household/entities"
+	householdRepository "home-library/internal/services/household/repository"
+	"home-library/internal/services/webhook/dtos"
+	"home-library/internal/services/webhook/entities"
+	"home-library/internal/services/webhook/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/events"
+	"home-library/pkg/jobs"
+	"home-library/pkg/webhooks"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// DeliveryJob is the job kind of delivering one event to one webhook.
+const DeliveryJob = "webhook.deliver"
+
+// TestEventType marks the events sent on request to check a webhook.
+const TestEventType events.Type = "webhook.test"
+
+const (
+	// deliveryAttempts with the queue's backoff keep retrying for about
+	// an hour and a half before giving up.
+	deliveryAttempts = 10
+	deliveryTimeout  = time.Minute
+
+	deliveriesShown = 50
+)
+
+// Sender delivers a signed message; webhooks.Sender is the implementation.
+type Sender interface {
+	Send(ctx context.Context, url string, secret string, message webhooks.Message) webhooks.Result
+}
+
+// Queue is the part of jobs.Queue deliveries are scheduled with.
+type Queue interface {
+	Enqueue(ctx context.Context, kind string, payload any, opts jobs.Options) (uuid.UUID, error)
+}
+
+type UseCase interface {
+	CreateWebhook(ctx context.Context, userID uuid.UUID, payload dtos.CreateWebhookRequest) (*dtos.CreateWebhookResponse, error)
+	GetWebhooks(ctx context.Context, userID uuid.UUID) ([]dtos.WebhookResponse, error)
+	DeleteWebhook(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) error
+	GetDeliveries(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) ([]dtos.DeliveryResponse, error)
+	SendTestEvent(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) (*dtos.DeliveryResponse, error)
+
+	// Fanout schedules the delivery of an event to every webhook subscribed
+	// to it. It is an events.Handler.
+	Fanout(ctx context.Context, event *events.Event) error
+	// Deliver makes one attempt of a scheduled delivery. It is a
+	// jobs.Handler, so a failed attempt is retried with backoff.
+	Deliver(ctx context.Context, job *jobs.Job) error
+}
+
+type useCase struct {
+	r          repository.Repository
+	households householdRepository.Repository
+	queue      Queue
+	sender     Sender
+}
+
+func NewUseCase(r repository.Repository, households householdRepository.Repository, queue Queue, sender Sender) UseCase {
+	return &useCase{r: r, households: households, queue: queue, sender: sender}
+}
+
+type deliveryPayload struct {
+	WebhookID uuid.UUID    `json:"webhook_id"`
+	Event     events.Event `json:"event"`
+}
+
+type testPayload struct {
+	WebhookID uuid.UUID `json:"webhook_id"`
+	Message   string    `json:"message"`
+}
+
+func (u *useCase) CreateWebhook(ctx context.Context, userID uuid.UUID, payload dtos.CreateWebhookRequest) (*dtos.CreateWebhookResponse, error) {
+	webhook, err := entities.NewWebhook(userID, payload.URL, payload.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.Household {
+		member, err := u.households.GetMembership(ctx, userID)
+		if err != nil {
+			return nil, mapNoRows(err, errors.ErrHouseholdNotFound)
+		}
+		if !member.Role.CanManageIntegrations() {
+			return nil, errors.ErrHouseholdForbidden
+		}
+		webhook.HouseholdID = &member.HouseholdID
+	}
+
+	if _, err := u.r.CreateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return &dtos.CreateWebhookResponse{
+		WebhookResponse: dtos.NewWebhookResponse(*webhook),
+		Secret:          webhook.Secret,
+	}, nil
+}
+
+// GetWebhooks lists the webhooks the user manages: their own and, for a
+// household owner, those of the household.
+func (u *useCase) GetWebhooks(ctx context.Context, userID uuid.UUID) ([]dtos.WebhookResponse, error) {
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var householdID *uuid.UUID
+	if member != nil && member.Role.CanManageIntegrations() {
+		householdID = &member.HouseholdID
+	}
+
+	list, err := u.r.GetWebhooks(ctx, userID, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.WebhookResponse, len(list))
+	for i, webhook := range list {
+		result[i] = dtos.NewWebhookResponse(webhook)
+	}
+
+	return result, nil
+}
+
+func (u *useCase) DeleteWebhook(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) error {
+	if _, err := u.managed(ctx, userID, webhookID); err != nil {
+		return err
+	}
+
+	return mapNoRows(u.r.DeleteWebhook(ctx, webhookID), errors.ErrWebhookNotFound)
+}
+
+func (u *useCase) GetDeliveries(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) ([]dtos.DeliveryResponse, error) {
+	if _, err := u.managed(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := u.r.GetDeliveries(ctx, webhookID, deliveriesShown)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.DeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		result[i] = dtos.NewDeliveryResponse(delivery)
+	}
+
+	return result, nil
+}
+
+// SendTestEvent delivers a test event right away, without retries, and
+// reports how it went.
+func (u *useCase) SendTestEvent(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) (*dtos.DeliveryResponse, error) {
+	webhook, err := u.managed(ctx, userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := events.New(TestEventType, userID, testPayload{WebhookID: webhookID, Message: "Тестовое событие домашней библиотеки"})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := u.send(ctx, webhook, event, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dtos.NewDeliveryResponse(*delivery)
+	return &response, nil
+}
+
+func (u *useCase) Fanout(ctx context.Context, event *events.Event) error {
+	subscribed, err := u.r.GetSubscribedWebhooks(ctx, event.UserID, string(event.Type))
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range subscribed {
+		payload := deliveryPayload{WebhookID: webhook.WebhookID, Event: *event}
+		_, err := u.queue.Enqueue(ctx, DeliveryJob, payload, jobs.Options{MaxAttempts: deliveryAttempts, Timeout: deliveryTimeout})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *useCase) Deliver(ctx context.Context, job *jobs.Job) error {
+	var payload deliveryPayload
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+
+	webhook, err := u.r.GetWebhook(ctx, payload.WebhookID)
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		// Deleted since the event was scheduled.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	delivery, err := u.send(ctx, webhook, &payload.Event, job.Attempts)
+	if err != nil {
+		return err
+	}
+	if !delivery.Succeeded() {
+		return stdErrors.New(delivery.Error)
+	}
+
+	return nil
+}
+
+// send delivers the event and logs the attempt. A failed delivery is not an
+// error; the returned log entry tells.
+func (u *useCase) send(ctx context.Context, webhook *entities.Webhook, event *events.Event, attempt int) (*entities.Delivery, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	result := u.sender.Send(ctx, webhook.URL, webhook.Secret, webhooks.Message{
+		EventID: event.EventID.String(),
+		Type:    string(event.Type),
+		Body:    body,
+	})
+
+	delivery := &entities.Delivery{
+		DeliveryID: uuid.New(),
+		WebhookID:  webhook.WebhookID,
+		EventID:    event.EventID,
+		EventType:  string(event.Type),
+		Attempt:    attempt,
+		StatusCode: result.StatusCode,
+		DurationMs: int(result.Duration.Milliseconds()),
+		CreatedAt:  time.Now(),
+	}
+	if result.Err != nil {
+		delivery.Error = result.Err.Error()
+	}
+
+	if err := u.r.CreateDelivery(ctx, delivery); err != nil {
+		// Losing a log entry is better than sending the event again.
+		log.Error().Err(err).Str("webhook_id", webhook.WebhookID.String()).Msg("failed to log webhook delivery")
+	}
+
+	return delivery, nil
+}
+
+// membership returns the user's household membership, or nil for a user
+// outside any household.
+func (u *useCase) membership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	member, err := u.households.GetMembership(ctx, userID)
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// managed returns the webhook if the user may manage it. Webhooks of
+// others are reported as missing, so their existence does not leak.
+func (u *useCase) managed(ctx context.Context, userID uuid.UUID, webhookID uuid.UUID) (*entities.Webhook, error) {
+	webhook, err := u.r.GetWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, mapNoRows(err, errors.ErrWebhookNotFound)
+	}
+
+	if webhook.HouseholdID == nil {
+		if webhook.OwnerID != userID {
+			return nil, errors.ErrWebhookNotFound
+		}
+		return webhook, nil
+	}
+
+	member, err := u.membership(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.HouseholdID != *webhook.HouseholdID {
+		return nil, errors.ErrWebhookNotFound
+	}
+	if !member.Role.CanManageIntegrations() {
+		return nil, errors.ErrHouseholdForbidden
+	}
+	return webhook, nil
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
@@ -0,0 +1,409 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	stdErrors "errors"
+	householdEntities "home-library/internal/services/household/entities"
+	"home-library/internal/services/webhook/dtos"
+	"home-library/internal/services/webhook/entities"
+	"home-library/pkg/errors"
+	"home-library/pkg/events"
+	"home-library/pkg/jobs"
+	"home-library/pkg/webhooks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateWebhook(ctx context.Context, webhook *entities.Webhook) (uuid.UUID, error) {
+	args := m.Called(ctx, webhook)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetWebhook(ctx context.Context, webhookID uuid.UUID) (*entities.Webhook, error) {
+	args := m.Called(ctx, webhookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Webhook), args.Error(1)
+}
+
+func (m *MockRepository) GetWebhooks(ctx context.Context, ownerID uuid.UUID, householdID *uuid.UUID) ([]entities.Webhook, error) {
+	args := m.Called(ctx, ownerID, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Webhook), args.Error(1)
+}
+
+func (m *MockRepository) GetSubscribedWebhooks(ctx context.Context, userID uuid.UUID, eventType string) ([]entities.Webhook, error) {
+	args := m.Called(ctx, userID, eventType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Webhook), args.Error(1)
+}
+
+func (m *MockRepository) DeleteWebhook(ctx context.Context, webhookID uuid.UUID) error {
+	return m.Called(ctx, webhookID).Error(0)
+}
+
+func (m *MockRepository) CreateDelivery(ctx context.Context, delivery *entities.Delivery) error {
+	return m.Called(ctx, delivery).Error(0)
+}
+
+func (m *MockRepository) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]entities.Delivery, error) {
+	args := m.Called(ctx, webhookID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.Delivery), args.Error(1)
+}
+
+func (m *MockRepository) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockHouseholdRepository struct {
+	mock.Mock
+}
+
+func (m *MockHouseholdRepository) CreateHousehold(ctx context.Context, household *householdEntities.Household, owner *householdEntities.Member) (uuid.UUID, error) {
+	args := m.Called(ctx, household, owner)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetHousehold(ctx context.Context, householdID uuid.UUID) (*householdEntities.Household, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Household), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RenameHousehold(ctx context.Context, householdID uuid.UUID, name string) error {
+	return m.Called(ctx, householdID, name).Error(0)
+}
+
+func (m *MockHouseholdRepository) DeleteHousehold(ctx context.Context, householdID uuid.UUID) error {
+	return m.Called(ctx, householdID).Error(0)
+}
+
+func (m *MockHouseholdRepository) GetMembership(ctx context.Context, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMembers(ctx context.Context, householdID uuid.UUID) ([]householdEntities.Member, error) {
+	args := m.Called(ctx, householdID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) (*householdEntities.Member, error) {
+	args := m.Called(ctx, householdID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Member), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RemoveMember(ctx context.Context, householdID uuid.UUID, userID uuid.UUID) error {
+	return m.Called(ctx, householdID, userID).Error(0)
+}
+
+func (m *MockHouseholdRepository) UpdateMemberRole(ctx context.Context, householdID uuid.UUID, userID uuid.UUID, role householdEntities.Role) error {
+	return m.Called(ctx, householdID, userID, role).Error(0)
+}
+
+func (m *MockHouseholdRepository) TransferOwnership(ctx context.Context, householdID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) error {
+	return m.Called(ctx, householdID, fromUserID, toUserID).Error(0)
+}
+
+func (m *MockHouseholdRepository) CreateInvitation(ctx context.Context, invitation *householdEntities.Invitation) (uuid.UUID, error) {
+	args := m.Called(ctx, invitation)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetInvitationByCode(ctx context.Context, code string) (*householdEntities.Invitation, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) GetActiveInvitations(ctx context.Context, householdID uuid.UUID, now time.Time) ([]householdEntities.Invitation, error) {
+	args := m.Called(ctx, householdID, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]householdEntities.Invitation), args.Error(1)
+}
+
+func (m *MockHouseholdRepository) RevokeInvitation(ctx context.Context, householdID uuid.UUID, invitationID uuid.UUID, now time.Time) error {
+	return m.Called(ctx, householdID, invitationID, now).Error(0)
+}
+
+func (m *MockHouseholdRepository) AcceptInvitation(ctx context.Context, invitationID uuid.UUID, member *householdEntities.Member) error {
+	return m.Called(ctx, invitationID, member).Error(0)
+}
+
+type MockQueue struct {
+	mock.Mock
+}
+
+func (m *MockQueue) Enqueue(ctx context.Context, kind string, payload any, opts jobs.Options) (uuid.UUID, error) {
+	args := m.Called(ctx, kind, payload, opts)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+type MockSender struct {
+	mock.Mock
+}
+
+func (m *MockSender) Send(ctx context.Context, url string, secret string, message webhooks.Message) webhooks.Result {
+	return m.Called(ctx, url, secret, message).Get(0).(webhooks.Result)
+}
+
+type mocks struct {
+	repo       *MockRepository
+	households *MockHouseholdRepository
+	queue      *MockQueue
+	sender     *MockSender
+}
+
+func newUseCase() (UseCase, mocks) {
+	m := mocks{new(MockRepository), new(MockHouseholdRepository), new(MockQueue), new(MockSender)}
+	return NewUseCase(m.repo, m.households, m.queue, m.sender), m
+}
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("personal webhook", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+
+		m.repo.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(w *entities.Webhook) bool {
+			return w.OwnerID == userID && w.HouseholdID == nil && w.URL == "https://example.com/hook"
+		})).Return(uuid.New(), nil)
+
+		response, err := u.CreateWebhook(context.Background(), userID, dtos.CreateWebhookRequest{URL: "https://example.com/hook"})
+
+		require.NoError(t, err)
+		assert.Len(t, response.Secret, 64)
+		assert.Empty(t, response.EventTypes)
+		m.households.AssertNotCalled(t, "GetMembership")
+	})
+
+	t.Run("household webhook", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID := uuid.New(), uuid.New()
+
+		m.households.On("GetMembership", mock.Anything, userID).
+			Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleOwner}, nil)
+		m.repo.On("CreateWebhook", mock.Anything, mock.MatchedBy(func(w *entities.Webhook) bool {
+			return w.HouseholdID != nil && *w.HouseholdID == householdID
+		})).Return(uuid.New(), nil)
+
+		response, err := u.CreateWebhook(context.Background(), userID, dtos.CreateWebhookRequest{URL: "https://example.com/hook", Household: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, &householdID, response.HouseholdID)
+	})
+
+	t.Run("household webhook by a viewer", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+
+		m.households.On("GetMembership", mock.Anything, userID).
+			Return(&householdEntities.Member{HouseholdID: uuid.New(), UserID: userID, Role: householdEntities.RoleViewer}, nil)
+
+		_, err := u.CreateWebhook(context.Background(), userID, dtos.CreateWebhookRequest{URL: "https://example.com/hook", Household: true})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdForbidden)
+		m.repo.AssertNotCalled(t, "CreateWebhook")
+	})
+
+	t.Run("household webhook outside a household", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+
+		m.households.On("GetMembership", mock.Anything, userID).Return(nil, sql.ErrNoRows)
+
+		_, err := u.CreateWebhook(context.Background(), userID, dtos.CreateWebhookRequest{URL: "https://example.com/hook", Household: true})
+
+		assert.ErrorIs(t, err, errors.ErrHouseholdNotFound)
+	})
+}
+
+func TestGetWebhooks(t *testing.T) {
+	t.Run("household owner sees household webhooks", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, householdID := uuid.New(), uuid.New()
+
+		m.households.On("GetMembership", mock.Anything, userID).
+			Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleOwner}, nil)
+		m.repo.On("GetWebhooks", mock.Anything, userID, &householdID).
+			Return([]entities.Webhook{{WebhookID: uuid.New(), HouseholdID: &householdID}}, nil)
+
+		list, err := u.GetWebhooks(context.Background(), userID)
+
+		require.NoError(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("editor sees own webhooks only", func(t *testing.T) {
+		u, m := newUseCase()
+		userID := uuid.New()
+
+		m.households.On("GetMembership", mock.Anything, userID).
+			Return(&householdEntities.Member{HouseholdID: uuid.New(), UserID: userID, Role: householdEntities.RoleEditor}, nil)
+		m.repo.On("GetWebhooks", mock.Anything, userID, (*uuid.UUID)(nil)).Return([]entities.Webhook{}, nil)
+
+		list, err := u.GetWebhooks(context.Background(), userID)
+
+		require.NoError(t, err)
+		assert.Empty(t, list)
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	t.Run("someone else's webhook", func(t *testing.T) {
+		u, m := newUseCase()
+		webhookID := uuid.New()
+
+		m.repo.On("GetWebhook", mock.Anything, webhookID).Return(&entities.Webhook{WebhookID: webhookID, OwnerID: uuid.New()}, nil)
+
+		err := u.DeleteWebhook(context.Background(), uuid.New(), webhookID)
+
+		assert.ErrorIs(t, err, errors.ErrWebhookNotFound)
+		m.repo.AssertNotCalled(t, "DeleteWebhook")
+	})
+
+	t.Run("another household's webhook", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, webhookID, householdID := uuid.New(), uuid.New(), uuid.New()
+
+		m.repo.On("GetWebhook", mock.Anything, webhookID).Return(&entities.Webhook{WebhookID: webhookID, OwnerID: uuid.New(), HouseholdID: &householdID}, nil)
+		m.households.On("GetMembership", mock.Anything, userID).
+			Return(&householdEntities.Member{HouseholdID: uuid.New(), UserID: userID, Role: householdEntities.RoleOwner}, nil)
+
+		err := u.DeleteWebhook(context.Background(), userID, webhookID)
+
+		assert.ErrorIs(t, err, errors.ErrWebhookNotFound)
+	})
+
+	t.Run("household webhook by the new owner", func(t *testing.T) {
+		u, m := newUseCase()
+		userID, webhookID, householdID := uuid.New(), uuid.New(), uuid.New()
+
+		m.repo.On("GetWebhook", mock.Anything, webhookID).Return(&entities.Webhook{WebhookID: webhookID, OwnerID: uuid.New(), HouseholdID: &householdID}, nil)
+		m.households.On("GetMembership", mock.Anything, userID).
+			Return(&householdEntities.Member{HouseholdID: householdID, UserID: userID, Role: householdEntities.RoleOwner}, nil)
+		m.repo.On("DeleteWebhook", mock.Anything, webhookID).Return(nil)
+
+		assert.NoError(t, u.DeleteWebhook(context.Background(), userID, webhookID))
+	})
+}
+
+func TestSendTestEvent(t *testing.T) {
+	u, m := newUseCase()
+	userID := uuid.New()
+	webhook := &entities.Webhook{WebhookID: uuid.New(), OwnerID: userID, URL: "https://example.com/hook", Secret: "secret"}
+
+	m.repo.On("GetWebhook", mock.Anything, webhook.WebhookID).Return(webhook, nil)
+	m.sender.On("Send", mock.Anything, webhook.URL, webhook.Secret, mock.MatchedBy(func(message webhooks.Message) bool {
+		return message.Type == string(TestEventType)
+	})).Return(webhooks.Result{StatusCode: 500, Duration: 30 * time.Millisecond, Err: stdErrors.New("unexpected response status 500 Internal Server Error")})
+	m.repo.On("CreateDelivery", mock.Anything, mock.Anything).Return(nil)
+
+	delivery, err := u.SendTestEvent(context.Background(), userID, webhook.WebhookID)
+
+	require.NoError(t, err)
+	assert.False(t, delivery.Succeeded)
+	assert.Equal(t, 500, delivery.StatusCode)
+	assert.Equal(t, 30, delivery.DurationMs)
+}
+
+func TestFanout(t *testing.T) {
+	u, m := newUseCase()
+	userID := uuid.New()
+	event, err := events.New(events.TypeEbookAdded, userID, events.EbookAdded{FileID: uuid.New()})
+	require.NoError(t, err)
+	first, second := uuid.New(), uuid.New()
+
+	m.repo.On("GetSubscribedWebhooks", mock.Anything, userID, "ebook.added").
+		Return([]entities.Webhook{{WebhookID: first}, {WebhookID: second}}, nil)
+	for _, webhookID := range []uuid.UUID{first, second} {
+		m.queue.On("Enqueue", mock.Anything, DeliveryJob, deliveryPayload{WebhookID: webhookID, Event: *event}, mock.Anything).
+			Return(uuid.New(), nil).Once()
+	}
+
+	assert.NoError(t, u.Fanout(context.Background(), event))
+	m.queue.AssertExpectations(t)
+}
+
+func TestDeliver(t *testing.T) {
+	newJob := func(t *testing.T, webhookID uuid.UUID) *jobs.Job {
+		event, err := events.New(events.TypeEbookAdded, uuid.New(), events.EbookAdded{FileID: uuid.New()})
+		require.NoError(t, err)
+
+		job := &jobs.Job{JobID: uuid.New(), Kind: DeliveryJob, Attempts: 3}
+		job.Payload, err = json.Marshal(deliveryPayload{WebhookID: webhookID, Event: *event})
+		require.NoError(t, err)
+		return job
+	}
+
+	t.Run("failed attempt is retried", func(t *testing.T) {
+		u, m := newUseCase()
+		webhook := &entities.Webhook{WebhookID: uuid.New(), URL: "https://example.com/hook", Secret: "secret"}
+
+		m.repo.On("GetWebhook", mock.Anything, webhook.WebhookID).Return(webhook, nil)
+		m.sender.On("Send", mock.Anything, webhook.URL, webhook.Secret, mock.Anything).
+			Return(webhooks.Result{Err: stdErrors.New("connection refused")})
+		m.repo.On("CreateDelivery", mock.Anything, mock.MatchedBy(func(d *entities.Delivery) bool {
+			return d.Attempt == 3 && d.Error == "connection refused"
+		})).Return(nil)
+
+		err := u.Deliver(context.Background(), newJob(t, webhook.WebhookID))
+
+		assert.EqualError(t, err, "connection refused")
+	})
+
+	t.Run("delivered even if the log fails", func(t *testing.T) {
+		u, m := newUseCase()
+		webhook := &entities.Webhook{WebhookID: uuid.New(), URL: "https://example.com/hook", Secret: "secret"}
+
+		m.repo.On("GetWebhook", mock.Anything, webhook.WebhookID).Return(webhook, nil)
+		m.sender.On("Send", mock.Anything, webhook.URL, webhook.Secret, mock.Anything).Return(webhooks.Result{StatusCode: 200})
+		m.repo.On("CreateDelivery", mock.Anything, mock.Anything).Return(stdErrors.New("db down"))
+
+		assert.NoError(t, u.Deliver(context.Background(), newJob(t, webhook.WebhookID)))
+	})
+
+	t.Run("deleted webhook", func(t *testing.T) {
+		u, m := newUseCase()
+		webhookID := uuid.New()
+
+		m.repo.On("GetWebhook", mock.Anything, webhookID).Return(nil, sql.ErrNoRows)
+
+		assert.NoError(t, u.Deliver(context.Background(), newJob(t, webhookID)))
+		m.sender.AssertNotCalled(t, "Send")
+	})
+}
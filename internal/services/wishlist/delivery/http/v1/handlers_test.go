@@ -0,0 +1,237 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"home-library/internal/services/wishlist/dtos"
+	"home-library/internal/services/wishlist/entities"
+	customErrors "home-library/pkg/errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUseCase) CreateItem(ctx context.Context, userID uuid.UUID, payload dtos.CreateWishlistItemRequest) (uuid.UUID, error) {
+	args := m.Called(ctx, userID, payload)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockUseCase) GetOwnItems(ctx context.Context, userID uuid.UUID) ([]dtos.WishlistItemResponse, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.WishlistItemResponse), args.Error(1)
+}
+
+func (m *MockUseCase) UpdateItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, payload dtos.UpdateWishlistItemRequest) error {
+	args := m.Called(ctx, userID, itemID, payload)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) DeleteItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
+	args := m.Called(ctx, userID, itemID)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) GetSharedItems(ctx context.Context, viewerID uuid.UUID, ownerID uuid.UUID) ([]dtos.SharedWishlistItemResponse, error) {
+	args := m.Called(ctx, viewerID, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dtos.SharedWishlistItemResponse), args.Error(1)
+}
+
+func (m *MockUseCase) ReserveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
+	args := m.Called(ctx, userID, itemID)
+	return args.Error(0)
+}
+
+func (m *MockUseCase) CancelReservation(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
+	args := m.Called(ctx, userID, itemID)
+	return args.Error(0)
+}
+
+func newContext(e *echo.Echo, method string, target string, body string, userID uuid.UUID) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if userID != uuid.Nil {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestCreateItem(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully create item", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID, itemID := uuid.New(), uuid.New()
+		payload := dtos.CreateWishlistItemRequest{
+			ISBN:     "9780441013593",
+			Priority: entities.PriorityHigh,
+		}
+
+		jsonPayload, _ := json.Marshal(payload)
+		c, rec := newContext(e, http.MethodPost, "/wishlist", string(jsonPayload), userID)
+
+		mockUseCase.On("CreateItem", context.Background(), userID, payload).Return(itemID, nil)
+
+		err := handler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response dtos.CreateWishlistItemResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, itemID, response.ItemID)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("neither isbn nor title", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		c, rec := newContext(e, http.MethodPost, "/wishlist", `{"notes":"something nice"}`, uuid.New())
+
+		err := handler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var response dtos.ErrorResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.NotEmpty(t, response.ValidationErrors)
+		mockUseCase.AssertNotCalled(t, "CreateItem")
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		c, rec := newContext(e, http.MethodPost, "/wishlist", `{"title":"Dune"}`, uuid.Nil)
+
+		err := handler.CreateItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		mockUseCase.AssertNotCalled(t, "CreateItem")
+	})
+}
+
+func TestGetOwnItems(t *testing.T) {
+	e := echo.New()
+
+	t.Run("reservation fields are not exposed", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		userID := uuid.New()
+		items := []dtos.WishlistItemResponse{{ItemID: uuid.New(), Title: "Dune", Priority: entities.PriorityLow}}
+
+		c, rec := newContext(e, http.MethodGet, "/wishlist", "", userID)
+
+		mockUseCase.On("GetOwnItems", context.Background(), userID).Return(items, nil)
+
+		err := handler.GetOwnItems(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "reserved")
+		mockUseCase.AssertExpectations(t)
+	})
+}
+
+func TestReserveItem(t *testing.T) {
+	e := echo.New()
+
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "successfully reserve", err: nil, expectedCode: http.StatusNoContent},
+		{name: "item not found", err: customErrors.ErrWishlistItemNotFound, expectedCode: http.StatusNotFound},
+		{name: "already reserved", err: customErrors.ErrWishlistItemReserved, expectedCode: http.StatusConflict},
+		{name: "own item", err: customErrors.ErrWishlistOwnItem, expectedCode: http.StatusForbidden},
+		{name: "internal server error", err: errors.New("database error"), expectedCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUseCase)
+			handler := NewHandler(mockUseCase)
+			userID, itemID := uuid.New(), uuid.New()
+
+			c, rec := newContext(e, http.MethodPost, "/", "", userID)
+			c.SetParamNames("item_id")
+			c.SetParamValues(itemID.String())
+
+			mockUseCase.On("ReserveItem", context.Background(), userID, itemID).Return(tt.err)
+
+			err := handler.ReserveItem(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+
+	t.Run("invalid item id", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+
+		c, rec := newContext(e, http.MethodPost, "/", "", uuid.New())
+		c.SetParamNames("item_id")
+		c.SetParamValues("not-a-uuid")
+
+		err := handler.ReserveItem(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		mockUseCase.AssertNotCalled(t, "ReserveItem")
+	})
+}
+
+func TestGetSharedItems(t *testing.T) {
+	e := echo.New()
+
+	t.Run("successfully get shared wishlist", func(t *testing.T) {
+		mockUseCase := new(MockUseCase)
+		handler := NewHandler(mockUseCase)
+		viewerID, ownerID := uuid.New(), uuid.New()
+		items := []dtos.SharedWishlistItemResponse{{
+			WishlistItemResponse: dtos.WishlistItemResponse{ItemID: uuid.New(), Title: "Dune"},
+			IsReserved:           true,
+		}}
+
+		c, rec := newContext(e, http.MethodGet, "/", "", viewerID)
+		c.SetParamNames("user_id")
+		c.SetParamValues(ownerID.String())
+
+		mockUseCase.On("GetSharedItems", context.Background(), viewerID, ownerID).Return(items, nil)
+
+		err := handler.GetSharedItems(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response []dtos.SharedWishlistItemResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, items, response)
+		mockUseCase.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,13 @@
+package v1
+
+import "github.com/labstack/echo/v4"
+
+func (h *handler) WishlistRoutes(domain *echo.Group) {
+	domain.POST("/wishlist", h.CreateItem)
+	domain.GET("/wishlist", h.GetOwnItems)
+	domain.PUT("/wishlist/:item_id", h.UpdateItem)
+	domain.DELETE("/wishlist/:item_id", h.DeleteItem)
+	domain.POST("/wishlist/:item_id/reservation", h.ReserveItem)
+	domain.DELETE("/wishlist/:item_id/reservation", h.CancelReservation)
+	domain.GET("/users/:user_id/wishlist", h.GetSharedItems)
+}
@@ -0,0 +1,95 @@
+package dtos
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"home-library/internal/services/wishlist/entities"
+	"time"
+)
+
+type CreateWishlistItemRequest struct {
+	ISBN     string            `json:"isbn" validate:"omitempty,isbn"`
+	Title    string            `json:"title" validate:"required_without=ISBN,max=255"`
+	Author   string            `json:"author" validate:"max=255"`
+	Priority entities.Priority `json:"priority" validate:"omitempty,oneof=low medium high"`
+	Notes    string            `json:"notes" validate:"max=2000"`
+	Purchase
+}
+
+// Purchase is how a wished book was bought, for the copy it becomes in the
+// catalog.
+type Purchase struct {
+	PurchasePrice *float64 `json:"purchase_price" validate:"omitempty,gte=0,lt=100000000"`
+	PurchasedAt   string   `json:"purchased_at" validate:"omitempty,datetime=2006-01-02"`
+	PurchaseStore string   `json:"purchase_store" validate:"max=255"`
+}
+
+type CreateWishlistItemResponse struct {
+	ItemID uuid.UUID `json:"item_id"`
+}
+
+func (r *CreateWishlistItemRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+type UpdateWishlistItemRequest struct {
+	ISBN     string            `json:"isbn" validate:"omitempty,isbn"`
+	Title    string            `json:"title" validate:"required_without=ISBN,max=255"`
+	Author   string            `json:"author" validate:"max=255"`
+	Priority entities.Priority `json:"priority" validate:"required,oneof=low medium high"`
+	Notes    string            `json:"notes" validate:"max=2000"`
+	Purchase
+}
+
+func (r *UpdateWishlistItemRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(r)
+}
+
+// WishlistItemResponse is the owner's view of an item. It deliberately has no
+// reservation fields so that gifts stay a surprise.
+type WishlistItemResponse struct {
+	ItemID   uuid.UUID         `json:"item_id"`
+	ISBN     string            `json:"isbn,omitempty"`
+	Title    string            `json:"title,omitempty"`
+	Author   string            `json:"author,omitempty"`
+	Priority entities.Priority `json:"priority"`
+	Notes    string            `json:"notes,omitempty"`
+	// PurchasedAt is formatted as in the request.
+	PurchasePrice *float64 `json:"purchase_price,omitempty"`
+	PurchasedAt   string   `json:"purchased_at,omitempty"`
+	PurchaseStore string   `json:"purchase_store,omitempty"`
+}
+
+// SharedWishlistItemResponse is what other users see when picking a gift.
+type SharedWishlistItemResponse struct {
+	WishlistItemResponse
+	IsReserved   bool `json:"is_reserved"`
+	ReservedByMe bool `json:"reserved_by_me"`
+}
+
+func NewWishlistItemResponse(item entities.WishlistItem) WishlistItemResponse {
+	response := WishlistItemResponse{
+		ItemID:        item.ItemID,
+		ISBN:          item.ISBN,
+		Title:         item.Title,
+		Author:        item.Author,
+		Priority:      item.Priority,
+		Notes:         item.Notes,
+		PurchasePrice: item.PurchasePrice,
+		PurchaseStore: item.PurchaseStore,
+	}
+	if item.PurchasedAt != nil {
+		response.PurchasedAt = item.PurchasedAt.Format(time.DateOnly)
+	}
+	return response
+}
+
+func NewSharedWishlistItemResponse(item entities.WishlistItem, viewerID uuid.UUID) SharedWishlistItemResponse {
+	return SharedWishlistItemResponse{
+		WishlistItemResponse: NewWishlistItemResponse(item),
+		IsReserved:           item.IsReserved(),
+		ReservedByMe:         item.IsReserved() && *item.ReservedBy == viewerID,
+	}
+}
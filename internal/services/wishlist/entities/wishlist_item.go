@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
+)
+
+type WishlistItem struct {
+	ItemID     uuid.UUID  `db:"item_id"`
+	UserID     uuid.UUID  `db:"user_id"`
+	ISBN       string     `db:"isbn"`
+	Title      string     `db:"title"`
+	Author     string     `db:"author"`
+	Priority   Priority   `db:"priority"`
+	Notes      string     `db:"notes"`
+	ReservedBy *uuid.UUID `db:"reserved_by"`
+	ReservedAt *time.Time `db:"reserved_at"`
+	// The purchase goes over to the copy added for the book when the item
+	// leaves the wishlist.
+	PurchasePrice *float64   `db:"purchase_price"`
+	PurchasedAt   *time.Time `db:"purchased_at"`
+	PurchaseStore string     `db:"purchase_store"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+	DeletedAt     *time.Time `db:"deleted_at,omitempty"`
+}
+
+func NewWishlistItem(userID uuid.UUID) *WishlistItem {
+	now := time.Now()
+	return &WishlistItem{
+		ItemID:    uuid.New(),
+		UserID:    userID,
+		Priority:  PriorityMedium,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func (i *WishlistItem) IsReserved() bool {
+	return i.ReservedBy != nil
+}
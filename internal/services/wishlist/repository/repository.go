@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/wishlist/entities"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Repository interface {
+	CreateItem(ctx context.Context, item *entities.WishlistItem) (uuid.UUID, error)
+	GetItemsByUser(ctx context.Context, userID uuid.UUID) ([]entities.WishlistItem, error)
+	GetSharedItem(ctx context.Context, itemID uuid.UUID, viewerID uuid.UUID) (*entities.WishlistItem, error)
+	GetSharedItemsByUser(ctx context.Context, ownerID uuid.UUID, viewerID uuid.UUID) ([]entities.WishlistItem, error)
+	// FindItemsByISBN returns the viewer's own items and the items of the
+	// viewer's household that have one of the ISBNs.
+	FindItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbns []string) ([]entities.WishlistItem, error)
+	// TakeItemsByISBN deletes the items FindItemsByISBN would return for the
+	// ISBN and returns them, once the household has the book.
+	TakeItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbn string) ([]entities.WishlistItem, error)
+	UpdateItem(ctx context.Context, item *entities.WishlistItem) error
+	DeleteItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error
+	ReserveItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID, reservedAt time.Time) error
+	CancelReservation(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error
+}
+
+// sharedWithViewer limits wishlist_items aliased as w to owners who are in the
+// same household as the viewer passed as $2.
+const sharedWithViewer = `
+	EXISTS (
+		SELECT 1 FROM household_members owner_member
+		JOIN household_members viewer_member ON viewer_member.household_id = owner_member.household_id
+		WHERE owner_member.user_id = w.user_id AND viewer_member.user_id = $2
+	)
+`
+
+type repository struct {
+	db *transaction.DB
+}
+
+func NewRepository(db *sqlx.DB) Repository {
+	return &repository{db: transaction.Wrap(db)}
+}
+
+func (r *repository) CreateItem(ctx context.Context, item *entities.WishlistItem) (uuid.UUID, error) {
+	query := `
+		INSERT INTO wishlist_items (
+			item_id, user_id, isbn, title, author, priority, notes,
+			purchase_price, purchased_at, purchase_store, created_at, updated_at
+		) VALUES (
+			:item_id, :user_id, :isbn, :title, :author, :priority, :notes,
+			:purchase_price, :purchased_at, :purchase_store, :created_at, :updated_at
+		)
+	`
+
+	_, err := r.db.NamedExecContext(ctx, query, item)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return item.ItemID, nil
+}
+
+func (r *repository) GetItemsByUser(ctx context.Context, userID uuid.UUID) ([]entities.WishlistItem, error) {
+	items := make([]entities.WishlistItem, 0)
+	query := `
+		SELECT * FROM wishlist_items
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY CASE priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END, created_at
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *repository) GetSharedItem(ctx context.Context, itemID uuid.UUID, viewerID uuid.UUID) (*entities.WishlistItem, error) {
+	var item entities.WishlistItem
+	query := `
+		SELECT w.* FROM wishlist_items w
+		WHERE w.item_id = $1 AND w.deleted_at IS NULL AND ` + sharedWithViewer
+
+	err := r.db.GetContext(ctx, &item, query, itemID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *repository) GetSharedItemsByUser(ctx context.Context, ownerID uuid.UUID, viewerID uuid.UUID) ([]entities.WishlistItem, error) {
+	items := make([]entities.WishlistItem, 0)
+	query := `
+		SELECT w.* FROM wishlist_items w
+		WHERE w.user_id = $1 AND w.deleted_at IS NULL AND ` + sharedWithViewer + `
+		ORDER BY CASE w.priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END, w.created_at
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, ownerID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *repository) FindItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbns []string) ([]entities.WishlistItem, error) {
+	items := make([]entities.WishlistItem, 0)
+	query := `
+		SELECT w.* FROM wishlist_items w
+		WHERE w.isbn = ANY($1) AND w.deleted_at IS NULL AND (w.user_id = $2 OR ` + sharedWithViewer + `)
+		ORDER BY w.created_at
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, pq.StringArray(isbns), viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *repository) TakeItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbn string) ([]entities.WishlistItem, error) {
+	items := make([]entities.WishlistItem, 0)
+	query := `
+		UPDATE wishlist_items w
+		SET deleted_at = NOW()
+		WHERE w.isbn = $1 AND w.deleted_at IS NULL AND (w.user_id = $2 OR ` + sharedWithViewer + `)
+		RETURNING w.*
+	`
+
+	err := r.db.SelectContext(ctx, &items, query, isbn, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *repository) UpdateItem(ctx context.Context, item *entities.WishlistItem) error {
+	query := `
+		UPDATE wishlist_items
+		SET isbn = :isbn, title = :title, author = :author,
+			priority = :priority, notes = :notes, purchase_price = :purchase_price,
+			purchased_at = :purchased_at, purchase_store = :purchase_store, updated_at = :updated_at
+		WHERE item_id = :item_id AND user_id = :user_id AND deleted_at IS NULL
+	`
+
+	result, err := r.db.NamedExecContext(ctx, query, item)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) DeleteItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	query := `
+		UPDATE wishlist_items
+		SET deleted_at = NOW()
+		WHERE item_id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, itemID, userID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+// ReserveItem only succeeds when the item is not reserved yet and belongs to
+// another member of the user's household. Checking reserved_by in the same
+// statement means two concurrent reservations cannot both win.
+func (r *repository) ReserveItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID, reservedAt time.Time) error {
+	query := `
+		UPDATE wishlist_items w
+		SET reserved_by = $2, reserved_at = $3
+		WHERE w.item_id = $1 AND w.user_id <> $2 AND w.reserved_by IS NULL AND w.deleted_at IS NULL AND ` + sharedWithViewer
+
+	result, err := r.db.ExecContext(ctx, query, itemID, userID, reservedAt)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func (r *repository) CancelReservation(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	query := `
+		UPDATE wishlist_items
+		SET reserved_by = NULL, reserved_at = NULL
+		WHERE item_id = $1 AND reserved_by = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, itemID, userID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(result)
+}
+
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"home-library/internal/services/wishlist/entities"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockRepository(t *testing.T) (Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestCreateItem(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("create item with isbn", func(t *testing.T) {
+		item := entities.NewWishlistItem(uuid.New())
+		item.ISBN = "9780441013593"
+		item.Priority = entities.PriorityHigh
+
+		mock.ExpectExec("INSERT INTO wishlist_items").
+			WithArgs(
+				item.ItemID,
+				item.UserID,
+				item.ISBN,
+				item.Title,
+				item.Author,
+				item.Priority,
+				item.Notes,
+				item.PurchasePrice,
+				item.PurchasedAt,
+				item.PurchaseStore,
+				item.CreatedAt,
+				item.UpdatedAt,
+			).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		id, err := repo.CreateItem(context.Background(), item)
+
+		assert.NoError(t, err)
+		assert.Equal(t, item.ItemID, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		item := entities.NewWishlistItem(uuid.New())
+		item.Title = "Мастер и Маргарита"
+
+		mock.ExpectExec("INSERT INTO wishlist_items").
+			WillReturnError(errors.New("database error"))
+
+		id, err := repo.CreateItem(context.Background(), item)
+
+		assert.Error(t, err)
+		assert.Equal(t, uuid.Nil, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetItemsByUser(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("returns items ordered by priority", func(t *testing.T) {
+		userID := uuid.New()
+		now := time.Now()
+		rows := sqlmock.NewRows([]string{"item_id", "user_id", "isbn", "title", "author", "priority", "notes", "reserved_by", "reserved_at", "created_at", "updated_at", "deleted_at"}).
+			AddRow(uuid.New(), userID, "", "Пикник на обочине", "Стругацкие", "high", "", nil, nil, now, now, nil).
+			AddRow(uuid.New(), userID, "9780441013593", "", "", "low", "", uuid.New(), now, now, now, nil)
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM wishlist_items")).
+			WithArgs(userID).
+			WillReturnRows(rows)
+
+		items, err := repo.GetItemsByUser(context.Background(), userID)
+
+		assert.NoError(t, err)
+		assert.Len(t, items, 2)
+		assert.Equal(t, entities.PriorityHigh, items[0].Priority)
+		assert.False(t, items[0].IsReserved())
+		assert.True(t, items[1].IsReserved())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFindItemsByISBN(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	viewerID := uuid.New()
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"item_id", "user_id", "isbn", "title", "author", "priority", "notes", "reserved_by", "reserved_at", "created_at", "updated_at", "deleted_at"}).
+		AddRow(uuid.New(), viewerID, "0441013597", "Dune", "", "high", "", nil, nil, now, now, nil)
+
+	mock.ExpectQuery(`WHERE w.isbn = ANY\(\$1\) AND w.deleted_at IS NULL AND \(w.user_id = \$2 OR\s+EXISTS`).
+		WithArgs(pq.StringArray{"9780441013593", "0441013597"}, viewerID).
+		WillReturnRows(rows)
+
+	items, err := repo.FindItemsByISBN(context.Background(), viewerID, []string{"9780441013593", "0441013597"})
+
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTakeItemsByISBN(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	viewerID := uuid.New()
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"item_id", "user_id", "isbn", "title", "author", "priority", "notes", "reserved_by", "reserved_at", "purchase_price", "purchased_at", "purchase_store", "created_at", "updated_at", "deleted_at"}).
+		AddRow(uuid.New(), uuid.New(), "9780441013593", "Dune", "", "high", "", nil, nil, 450.0, now, "Буквоед", now, now, now)
+
+	mock.ExpectQuery(`UPDATE wishlist_items w\s+SET deleted_at = NOW\(\)\s+WHERE w.isbn = \$1 AND w.deleted_at IS NULL AND \(w.user_id = \$2 OR\s+EXISTS.+RETURNING w.\*`).
+		WithArgs("9780441013593", viewerID).
+		WillReturnRows(rows)
+
+	items, err := repo.TakeItemsByISBN(context.Background(), viewerID, "9780441013593")
+
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, 450.0, *items[0].PurchasePrice)
+		assert.Equal(t, "Буквоед", items[0].PurchaseStore)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSharedItemsByUser(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("query is scoped to viewer household", func(t *testing.T) {
+		ownerID, viewerID := uuid.New(), uuid.New()
+		now := time.Now()
+		rows := sqlmock.NewRows([]string{"item_id", "user_id", "isbn", "title", "author", "priority", "notes", "reserved_by", "reserved_at", "created_at", "updated_at", "deleted_at"}).
+			AddRow(uuid.New(), ownerID, "", "Солярис", "", "medium", "", nil, nil, now, now, nil)
+
+		mock.ExpectQuery(`FROM wishlist_items w\s+WHERE w.user_id = \$1 .* household_members`).
+			WithArgs(ownerID, viewerID).
+			WillReturnRows(rows)
+
+		items, err := repo.GetSharedItemsByUser(context.Background(), ownerID, viewerID)
+
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("viewer outside household sees nothing", func(t *testing.T) {
+		ownerID, viewerID := uuid.New(), uuid.New()
+
+		mock.ExpectQuery("FROM wishlist_items w").
+			WithArgs(ownerID, viewerID).
+			WillReturnRows(sqlmock.NewRows([]string{"item_id"}))
+
+		items, err := repo.GetSharedItemsByUser(context.Background(), ownerID, viewerID)
+
+		assert.NoError(t, err)
+		assert.Empty(t, items)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReserveItem(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("item reserved", func(t *testing.T) {
+		itemID, userID, now := uuid.New(), uuid.New(), time.Now()
+
+		mock.ExpectExec("UPDATE wishlist_items").
+			WithArgs(itemID, userID, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.ReserveItem(context.Background(), itemID, userID, now)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("item already reserved", func(t *testing.T) {
+		itemID, userID, now := uuid.New(), uuid.New(), time.Now()
+
+		mock.ExpectExec("UPDATE wishlist_items").
+			WithArgs(itemID, userID, now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.ReserveItem(context.Background(), itemID, userID, now)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteItem(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	t.Run("item deleted", func(t *testing.T) {
+		itemID, userID := uuid.New(), uuid.New()
+
+		mock.ExpectExec("UPDATE wishlist_items").
+			WithArgs(itemID, userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.DeleteItem(context.Background(), itemID, userID)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("item of another user", func(t *testing.T) {
+		itemID, userID := uuid.New(), uuid.New()
+
+		mock.ExpectExec("UPDATE wishlist_items").
+			WithArgs(itemID, userID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteItem(context.Background(), itemID, userID)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	stdErrors "errors"
+	"home-library/internal/services/wishlist/dtos"
+	"home-library/internal/services/wishlist/entities"
+	"home-library/internal/services/wishlist/repository"
+	"home-library/pkg/errors"
+	"home-library/pkg/isbn"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UseCase interface {
+	CreateItem(ctx context.Context, userID uuid.UUID, payload dtos.CreateWishlistItemRequest) (itemID uuid.UUID, err error)
+	GetOwnItems(ctx context.Context, userID uuid.UUID) ([]dtos.WishlistItemResponse, error)
+	UpdateItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, payload dtos.UpdateWishlistItemRequest) error
+	DeleteItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error
+	GetSharedItems(ctx context.Context, viewerID uuid.UUID, ownerID uuid.UUID) ([]dtos.SharedWishlistItemResponse, error)
+	ReserveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error
+	CancelReservation(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error
+}
+
+type useCase struct {
+	r repository.Repository
+}
+
+func NewUseCase(r repository.Repository) UseCase {
+	return &useCase{r: r}
+}
+
+func (u *useCase) CreateItem(ctx context.Context, userID uuid.UUID, payload dtos.CreateWishlistItemRequest) (itemID uuid.UUID, err error) {
+	item := entities.NewWishlistItem(userID)
+	// The request accepts hyphens and spaces; only the bare ISBN is stored.
+	item.ISBN = isbn.Normalize(payload.ISBN)
+	item.Title = strings.TrimSpace(payload.Title)
+	item.Author = strings.TrimSpace(payload.Author)
+	item.Notes = strings.TrimSpace(payload.Notes)
+	if payload.Priority != "" {
+		item.Priority = payload.Priority
+	}
+	applyPurchase(item, payload.Purchase)
+
+	return u.r.CreateItem(ctx, item)
+}
+
+func (u *useCase) GetOwnItems(ctx context.Context, userID uuid.UUID) ([]dtos.WishlistItemResponse, error) {
+	items, err := u.r.GetItemsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.WishlistItemResponse, len(items))
+	for i, item := range items {
+		response[i] = dtos.NewWishlistItemResponse(item)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) UpdateItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, payload dtos.UpdateWishlistItemRequest) error {
+	item := &entities.WishlistItem{
+		ItemID:    itemID,
+		UserID:    userID,
+		ISBN:      isbn.Normalize(payload.ISBN),
+		Title:     strings.TrimSpace(payload.Title),
+		Author:    strings.TrimSpace(payload.Author),
+		Priority:  payload.Priority,
+		Notes:     strings.TrimSpace(payload.Notes),
+		UpdatedAt: time.Now(),
+	}
+	applyPurchase(item, payload.Purchase)
+
+	return mapNoRows(u.r.UpdateItem(ctx, item), errors.ErrWishlistItemNotFound)
+}
+
+func (u *useCase) DeleteItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
+	return mapNoRows(u.r.DeleteItem(ctx, itemID, userID), errors.ErrWishlistItemNotFound)
+}
+
+func (u *useCase) GetSharedItems(ctx context.Context, viewerID uuid.UUID, ownerID uuid.UUID) ([]dtos.SharedWishlistItemResponse, error) {
+	if viewerID == ownerID {
+		return nil, errors.ErrWishlistOwnItem
+	}
+
+	items, err := u.r.GetSharedItemsByUser(ctx, ownerID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dtos.SharedWishlistItemResponse, len(items))
+	for i, item := range items {
+		response[i] = dtos.NewSharedWishlistItemResponse(item, viewerID)
+	}
+
+	return response, nil
+}
+
+func (u *useCase) ReserveItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
+	item, err := u.r.GetSharedItem(ctx, itemID, userID)
+	if err != nil {
+		return mapNoRows(err, errors.ErrWishlistItemNotFound)
+	}
+
+	if item.UserID == userID {
+		return errors.ErrWishlistOwnItem
+	}
+	if item.IsReserved() {
+		return errors.ErrWishlistItemReserved
+	}
+
+	// The item may have been reserved by someone else since it was read.
+	return mapNoRows(u.r.ReserveItem(ctx, itemID, userID, time.Now()), errors.ErrWishlistItemReserved)
+}
+
+func (u *useCase) CancelReservation(ctx context.Context, userID uuid.UUID, itemID uuid.UUID) error {
+	return mapNoRows(u.r.CancelReservation(ctx, itemID, userID), errors.ErrReservationNotFound)
+}
+
+func applyPurchase(item *entities.WishlistItem, purchase dtos.Purchase) {
+	item.PurchasePrice = purchase.PurchasePrice
+	item.PurchaseStore = strings.TrimSpace(purchase.PurchaseStore)
+	// The date has been validated along with the request.
+	if purchasedAt, err := time.Parse(time.DateOnly, purchase.PurchasedAt); err == nil {
+		item.PurchasedAt = &purchasedAt
+	}
+}
+
+func mapNoRows(err error, target error) error {
+	if stdErrors.Is(err, sql.ErrNoRows) {
+		return target
+	}
+	return err
+}
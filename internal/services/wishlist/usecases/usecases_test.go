@@ -0,0 +1,280 @@
+package usecases
+
+import (
+	"context"
+	"database/sql"
+	"home-library/internal/services/wishlist/dtos"
+	"home-library/internal/services/wishlist/entities"
+	customErrors "home-library/pkg/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) CreateItem(ctx context.Context, item *entities.WishlistItem) (uuid.UUID, error) {
+	args := m.Called(ctx, item)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) GetItemsByUser(ctx context.Context, userID uuid.UUID) ([]entities.WishlistItem, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.WishlistItem), args.Error(1)
+}
+
+func (m *MockRepository) GetSharedItem(ctx context.Context, itemID uuid.UUID, viewerID uuid.UUID) (*entities.WishlistItem, error) {
+	args := m.Called(ctx, itemID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.WishlistItem), args.Error(1)
+}
+
+func (m *MockRepository) GetSharedItemsByUser(ctx context.Context, ownerID uuid.UUID, viewerID uuid.UUID) ([]entities.WishlistItem, error) {
+	args := m.Called(ctx, ownerID, viewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.WishlistItem), args.Error(1)
+}
+
+func (m *MockRepository) FindItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbns []string) ([]entities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.WishlistItem), args.Error(1)
+}
+
+func (m *MockRepository) TakeItemsByISBN(ctx context.Context, viewerID uuid.UUID, isbn string) ([]entities.WishlistItem, error) {
+	args := m.Called(ctx, viewerID, isbn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.WishlistItem), args.Error(1)
+}
+
+func (m *MockRepository) UpdateItem(ctx context.Context, item *entities.WishlistItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, itemID, userID)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ReserveItem(ctx context.Context, itemID uuid.UUID, userID uuid.UUID, reservedAt time.Time) error {
+	args := m.Called(ctx, itemID, userID, reservedAt)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CancelReservation(ctx context.Context, itemID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, itemID, userID)
+	return args.Error(0)
+}
+
+func TestCreateItem(t *testing.T) {
+	t.Run("defaults priority to medium", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		userID, itemID := uuid.New(), uuid.New()
+		payload := dtos.CreateWishlistItemRequest{
+			ISBN:  "080442957x",
+			Title: "  Dune ",
+		}
+
+		mockRepo.On("CreateItem", mock.Anything, mock.MatchedBy(func(item *entities.WishlistItem) bool {
+			return item.UserID == userID &&
+				item.ISBN == "080442957X" &&
+				item.Title == "Dune" &&
+				item.Priority == entities.PriorityMedium
+		})).Return(itemID, nil)
+
+		id, err := useCase.CreateItem(context.Background(), userID, payload)
+
+		assert.NoError(t, err)
+		assert.Equal(t, itemID, id)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stores hyphenated ISBN without separators", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		payload := dtos.CreateWishlistItemRequest{ISBN: "978-3-16-148410-0"}
+
+		mockRepo.On("CreateItem", mock.Anything, mock.MatchedBy(func(item *entities.WishlistItem) bool {
+			return item.ISBN == "9783161484100"
+		})).Return(uuid.New(), nil)
+
+		_, err := useCase.CreateItem(context.Background(), uuid.New(), payload)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUpdateItem(t *testing.T) {
+	t.Run("item not found", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+
+		mockRepo.On("UpdateItem", mock.Anything, mock.Anything).Return(sql.ErrNoRows)
+
+		err := useCase.UpdateItem(context.Background(), uuid.New(), uuid.New(), dtos.UpdateWishlistItemRequest{
+			Title:    "Dune",
+			Priority: entities.PriorityLow,
+		})
+
+		assert.Equal(t, customErrors.ErrWishlistItemNotFound, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("stores hyphenated ISBN without separators", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+
+		mockRepo.On("UpdateItem", mock.Anything, mock.MatchedBy(func(item *entities.WishlistItem) bool {
+			return item.ISBN == "9783161484100"
+		})).Return(nil)
+
+		err := useCase.UpdateItem(context.Background(), uuid.New(), uuid.New(), dtos.UpdateWishlistItemRequest{
+			ISBN:     "978 3 16 148410 0",
+			Priority: entities.PriorityHigh,
+		})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetSharedItems(t *testing.T) {
+	t.Run("reservations are visible to household members", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		ownerID, viewerID, otherID := uuid.New(), uuid.New(), uuid.New()
+		items := []entities.WishlistItem{
+			{ItemID: uuid.New(), UserID: ownerID, Title: "Dune", ReservedBy: &viewerID},
+			{ItemID: uuid.New(), UserID: ownerID, Title: "Solaris", ReservedBy: &otherID},
+			{ItemID: uuid.New(), UserID: ownerID, Title: "Roadside Picnic"},
+		}
+
+		mockRepo.On("GetSharedItemsByUser", mock.Anything, ownerID, viewerID).Return(items, nil)
+
+		response, err := useCase.GetSharedItems(context.Background(), viewerID, ownerID)
+
+		assert.NoError(t, err)
+		assert.Len(t, response, 3)
+		assert.True(t, response[0].IsReserved)
+		assert.True(t, response[0].ReservedByMe)
+		assert.True(t, response[1].IsReserved)
+		assert.False(t, response[1].ReservedByMe)
+		assert.False(t, response[2].IsReserved)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("owner cannot see reservations", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		ownerID := uuid.New()
+
+		response, err := useCase.GetSharedItems(context.Background(), ownerID, ownerID)
+
+		assert.Equal(t, customErrors.ErrWishlistOwnItem, err)
+		assert.Nil(t, response)
+		mockRepo.AssertNotCalled(t, "GetSharedItemsByUser")
+	})
+}
+
+func TestReserveItem(t *testing.T) {
+	t.Run("successfully reserve item", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		userID, itemID := uuid.New(), uuid.New()
+
+		mockRepo.On("GetSharedItem", mock.Anything, itemID, mock.Anything).Return(&entities.WishlistItem{ItemID: itemID, UserID: uuid.New()}, nil)
+		mockRepo.On("ReserveItem", mock.Anything, itemID, userID, mock.Anything).Return(nil)
+
+		err := useCase.ReserveItem(context.Background(), userID, itemID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("item not found or outside household", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		itemID := uuid.New()
+
+		mockRepo.On("GetSharedItem", mock.Anything, itemID, mock.Anything).Return(nil, sql.ErrNoRows)
+
+		err := useCase.ReserveItem(context.Background(), uuid.New(), itemID)
+
+		assert.Equal(t, customErrors.ErrWishlistItemNotFound, err)
+		mockRepo.AssertNotCalled(t, "ReserveItem")
+	})
+
+	t.Run("own item", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		userID, itemID := uuid.New(), uuid.New()
+
+		mockRepo.On("GetSharedItem", mock.Anything, itemID, mock.Anything).Return(&entities.WishlistItem{ItemID: itemID, UserID: userID}, nil)
+
+		err := useCase.ReserveItem(context.Background(), userID, itemID)
+
+		assert.Equal(t, customErrors.ErrWishlistOwnItem, err)
+		mockRepo.AssertNotCalled(t, "ReserveItem")
+	})
+
+	t.Run("already reserved", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		itemID, otherID := uuid.New(), uuid.New()
+
+		mockRepo.On("GetSharedItem", mock.Anything, itemID, mock.Anything).Return(&entities.WishlistItem{ItemID: itemID, UserID: uuid.New(), ReservedBy: &otherID}, nil)
+
+		err := useCase.ReserveItem(context.Background(), uuid.New(), itemID)
+
+		assert.Equal(t, customErrors.ErrWishlistItemReserved, err)
+		mockRepo.AssertNotCalled(t, "ReserveItem")
+	})
+
+	t.Run("reserved concurrently", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		userID, itemID := uuid.New(), uuid.New()
+
+		mockRepo.On("GetSharedItem", mock.Anything, itemID, mock.Anything).Return(&entities.WishlistItem{ItemID: itemID, UserID: uuid.New()}, nil)
+		mockRepo.On("ReserveItem", mock.Anything, itemID, userID, mock.Anything).Return(sql.ErrNoRows)
+
+		err := useCase.ReserveItem(context.Background(), userID, itemID)
+
+		assert.Equal(t, customErrors.ErrWishlistItemReserved, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCancelReservation(t *testing.T) {
+	t.Run("reservation of another user", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		useCase := NewUseCase(mockRepo)
+		userID, itemID := uuid.New(), uuid.New()
+
+		mockRepo.On("CancelReservation", mock.Anything, itemID, userID).Return(sql.ErrNoRows)
+
+		err := useCase.CancelReservation(context.Background(), userID, itemID)
+
+		assert.Equal(t, customErrors.ErrReservationNotFound, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    item_id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (user_id),
+    isbn varchar(13) NOT NULL DEFAULT '',
+    title varchar(255) NOT NULL DEFAULT '',
+    author varchar(255) NOT NULL DEFAULT '',
+    priority varchar(10) CHECK (priority IN ('low', 'medium', 'high')) NOT NULL DEFAULT 'medium',
+    notes TEXT NOT NULL DEFAULT '',
+    reserved_by uuid REFERENCES users (user_id),
+    reserved_at timestamp WITH time zone,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    deleted_at timestamp WITH time zone,
+    CHECK (isbn <> '' OR title <> '')
+);
+
+CREATE INDEX idx_wishlist_items_user_id ON wishlist_items (user_id);
+CREATE INDEX idx_wishlist_items_isbn ON wishlist_items (isbn) WHERE isbn <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS wishlist_items;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS households (
+    household_id uuid PRIMARY KEY,
+    name varchar(255) NOT NULL,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    deleted_at timestamp WITH time zone
+);
+
+CREATE TABLE IF NOT EXISTS household_members (
+    household_id uuid NOT NULL REFERENCES households (household_id),
+    user_id uuid UNIQUE NOT NULL REFERENCES users (user_id),
+    role varchar(10) CHECK (role IN ('owner', 'editor', 'viewer')) NOT NULL,
+    joined_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE UNIQUE INDEX idx_household_members_owner ON household_members (household_id) WHERE role = 'owner';
+
+CREATE TABLE IF NOT EXISTS household_invitations (
+    invitation_id uuid PRIMARY KEY,
+    household_id uuid NOT NULL REFERENCES households (household_id),
+    code varchar(16) UNIQUE NOT NULL,
+    role varchar(10) CHECK (role IN ('editor', 'viewer')) NOT NULL,
+    created_by uuid NOT NULL REFERENCES users (user_id),
+    expires_at timestamp WITH time zone NOT NULL,
+    accepted_by uuid REFERENCES users (user_id),
+    accepted_at timestamp WITH time zone,
+    revoked_at timestamp WITH time zone,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_household_invitations_household_id ON household_invitations (household_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS household_invitations;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
+-- +goose StatementEnd
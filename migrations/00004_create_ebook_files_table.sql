@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS ebook_files (
+    file_id uuid PRIMARY KEY,
+    owner_id uuid NOT NULL REFERENCES users (user_id),
+    sha256 char(64) NOT NULL,
+    format varchar(10) CHECK (format IN ('epub', 'pdf', 'fb2', 'fb2.zip')) NOT NULL,
+    size bigint NOT NULL CHECK (size > 0),
+    original_name varchar(255) NOT NULL DEFAULT '',
+    title varchar(255) NOT NULL DEFAULT '',
+    authors text[] NOT NULL DEFAULT '{}',
+    language varchar(35) NOT NULL DEFAULT '',
+    isbn varchar(13) NOT NULL DEFAULT '',
+    cover_id uuid,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (owner_id, sha256)
+);
+
+CREATE INDEX idx_ebook_files_sha256 ON ebook_files (sha256);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ebook_files;
+-- +goose StatementEnd
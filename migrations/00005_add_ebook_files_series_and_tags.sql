@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE ebook_files
+    ADD COLUMN description text NOT NULL DEFAULT '',
+    ADD COLUMN series varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN series_index double precision,
+    ADD COLUMN tags text[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_ebook_files_authors ON ebook_files USING gin (authors);
+CREATE INDEX idx_ebook_files_tags ON ebook_files USING gin (tags);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_ebook_files_tags;
+DROP INDEX IF EXISTS idx_ebook_files_authors;
+
+ALTER TABLE ebook_files
+    DROP COLUMN tags,
+    DROP COLUMN series_index,
+    DROP COLUMN series,
+    DROP COLUMN description;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS quotes (
+    quote_id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (user_id),
+    file_id uuid REFERENCES ebook_files (file_id) ON DELETE SET NULL,
+    source varchar(10) CHECK (source IN ('kindle', 'koreader')) NOT NULL,
+    kind varchar(10) CHECK (kind IN ('highlight', 'note')) NOT NULL,
+    book_title text NOT NULL DEFAULT '',
+    book_author text NOT NULL DEFAULT '',
+    text text NOT NULL DEFAULT '',
+    note text NOT NULL DEFAULT '',
+    chapter text NOT NULL DEFAULT '',
+    location varchar(32) NOT NULL DEFAULT '',
+    page integer NOT NULL DEFAULT 0,
+    highlighted_at timestamp WITH time zone,
+    fingerprint char(64) NOT NULL,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, fingerprint),
+    CHECK (text <> '' OR note <> '')
+);
+
+CREATE INDEX idx_quotes_user_id_file_id ON quotes (user_id, file_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS quotes;
+-- +goose StatementEnd
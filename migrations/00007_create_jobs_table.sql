@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS jobs (
+    job_id uuid PRIMARY KEY,
+    kind varchar(64) NOT NULL,
+    payload jsonb NOT NULL DEFAULT '{}',
+    status varchar(10) CHECK (status IN ('pending', 'running', 'done', 'dead')) NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    max_attempts integer NOT NULL CHECK (max_attempts > 0),
+    timeout_seconds integer NOT NULL CHECK (timeout_seconds > 0),
+    run_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    locked_until timestamp WITH time zone,
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_jobs_pending ON jobs (kind, run_at) WHERE status = 'pending';
+CREATE INDEX idx_jobs_running ON jobs (kind, locked_until) WHERE status = 'running';
+CREATE INDEX idx_jobs_status_updated_at ON jobs (status, updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS jobs;
+-- +goose StatementEnd
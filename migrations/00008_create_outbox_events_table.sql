@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox_events (
+    seq bigserial PRIMARY KEY,
+    event_id uuid NOT NULL UNIQUE,
+    type varchar(64) NOT NULL,
+    user_id uuid NOT NULL,
+    payload jsonb NOT NULL DEFAULT '{}',
+    occurred_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    dispatched_at timestamp WITH time zone
+);
+
+CREATE INDEX idx_outbox_events_undispatched ON outbox_events (seq) WHERE dispatched_at IS NULL;
+CREATE INDEX idx_outbox_events_dispatched_at ON outbox_events (dispatched_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox_events;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhooks (
+    webhook_id uuid PRIMARY KEY,
+    owner_id uuid NOT NULL REFERENCES users (user_id),
+    household_id uuid REFERENCES households (household_id),
+    url varchar(2048) NOT NULL,
+    secret varchar(64) NOT NULL,
+    event_types text[] NOT NULL DEFAULT '{}',
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhooks_owner_id ON webhooks (owner_id) WHERE household_id IS NULL;
+CREATE INDEX idx_webhooks_household_id ON webhooks (household_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id uuid PRIMARY KEY,
+    webhook_id uuid NOT NULL REFERENCES webhooks (webhook_id) ON DELETE CASCADE,
+    event_id uuid NOT NULL,
+    event_type varchar(64) NOT NULL,
+    attempt integer NOT NULL,
+    status_code integer NOT NULL DEFAULT 0,
+    error text NOT NULL DEFAULT '',
+    duration_ms integer NOT NULL DEFAULT 0,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_created_at ON webhook_deliveries (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+-- +goose StatementEnd
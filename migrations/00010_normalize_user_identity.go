@@ -0,0 +1,163 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"home-library/pkg/contact"
+	"sort"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+	"github.com/rs/zerolog/log"
+)
+
+func init() {
+	goose.AddMigrationContext(upNormalizeUserIdentity, downNormalizeUserIdentity)
+}
+
+// identity is the part of a user that must be unique among live accounts.
+type identity struct {
+	UserID      string
+	Email       string
+	PhoneNumber string
+}
+
+// upNormalizeUserIdentity rewrites the stored emails and phone numbers the
+// way sign-up now normalizes them, then makes them unique among users that
+// are not deleted.
+func upNormalizeUserIdentity(ctx context.Context, tx *sql.Tx) error {
+	// Normalized numbers can be longer than the old column, and a live
+	// user's normalized value can equal a deleted user's stored one.
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE users
+			DROP CONSTRAINT users_email_key,
+			DROP CONSTRAINT users_phone_number_key,
+			-- E.164 numbers have up to fifteen digits after the plus sign.
+			ALTER COLUMN phone_number TYPE varchar(16);
+
+		DROP INDEX IF EXISTS idx_users_email;
+	`)
+	if err != nil {
+		return err
+	}
+
+	users, err := liveIdentities(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	changed, err := normalizeIdentities(users, phoneRegion)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range changed {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET email = $2, phone_number = $3 WHERE user_id = $1`,
+			user.UserID, user.Email, user.PhoneNumber)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Deleted users keep their email and phone number, but no longer hold them.
+	_, err = tx.ExecContext(ctx, `
+		CREATE UNIQUE INDEX idx_users_email_lower ON users (lower(email)) WHERE deleted_at IS NULL;
+		CREATE UNIQUE INDEX idx_users_phone_number ON users (phone_number) WHERE deleted_at IS NULL;
+	`)
+	return err
+}
+
+func downNormalizeUserIdentity(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP INDEX IF EXISTS idx_users_phone_number;
+		DROP INDEX IF EXISTS idx_users_email_lower;
+
+		CREATE INDEX idx_users_email ON users (email);
+
+		ALTER TABLE users
+			ALTER COLUMN phone_number TYPE varchar(15),
+			ADD CONSTRAINT users_email_key UNIQUE (email),
+			ADD CONSTRAINT users_phone_number_key UNIQUE (phone_number);
+	`)
+	return err
+}
+
+func liveIdentities(ctx context.Context, tx *sql.Tx) ([]identity, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, email, phone_number FROM users WHERE deleted_at IS NULL ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []identity
+	for rows.Next() {
+		var user identity
+		if err := rows.Scan(&user.UserID, &user.Email, &user.PhoneNumber); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// normalizeIdentities returns the users whose email or phone number changes
+// when normalized. One person may own several accounts whose contacts only
+// differ in how they were written. Merging them is not something a migration
+// can decide, so they are listed in the error and resolved by hand.
+func normalizeIdentities(users []identity, region string) ([]identity, error) {
+	var (
+		changed []identity
+		emails  = make(map[string][]string)
+		phones  = make(map[string][]string)
+	)
+
+	for _, user := range users {
+		normalized := user
+
+		email, err := contact.NormalizeEmail(user.Email)
+		if err != nil {
+			// Such an account cannot sign in until its email is fixed, but
+			// it still has to hold its address.
+			log.Warn().Str("user_id", user.UserID).Str("email", user.Email).Msg("email cannot be normalized, only lower-cased")
+			email = strings.ToLower(strings.TrimSpace(user.Email))
+		}
+		normalized.Email = email
+
+		phoneNumber, err := contact.NormalizePhone(user.PhoneNumber, region)
+		if err != nil {
+			log.Warn().Str("user_id", user.UserID).Str("phone_number", user.PhoneNumber).Msg("phone number cannot be normalized, left as stored")
+			phoneNumber = user.PhoneNumber
+		}
+		normalized.PhoneNumber = phoneNumber
+
+		emails[normalized.Email] = append(emails[normalized.Email], user.UserID)
+		phones[normalized.PhoneNumber] = append(phones[normalized.PhoneNumber], user.UserID)
+
+		if normalized != user {
+			changed = append(changed, normalized)
+		}
+	}
+
+	conflicts := append(duplicates("email", emails), duplicates("phone number", phones)...)
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("users with conflicting contacts: %s; delete all but one account of each, or change their contacts, and run the migration again",
+			strings.Join(conflicts, "; "))
+	}
+
+	return changed, nil
+}
+
+func duplicates(kind string, owners map[string][]string) []string {
+	var conflicts []string
+	for value, userIDs := range owners {
+		if len(userIDs) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%s %s (%s)", kind, value, strings.Join(userIDs, ", ")))
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeIdentities(t *testing.T) {
+	t.Run("returns only changed users", func(t *testing.T) {
+		users := []identity{
+			{UserID: "1", Email: "evgeny@example.com", PhoneNumber: "+79001234567"},
+			{UserID: "2", Email: " Anna@Example.COM", PhoneNumber: "8 (900) 765-43-21"},
+			{UserID: "3", Email: "книги@пример.рф", PhoneNumber: "+79001112233"},
+		}
+
+		changed, err := normalizeIdentities(users, "RU")
+
+		require.NoError(t, err)
+		assert.Equal(t, []identity{
+			{UserID: "2", Email: "anna@example.com", PhoneNumber: "+79007654321"},
+			{UserID: "3", Email: "книги@xn--e1afmkfd.xn--p1ai", PhoneNumber: "+79001112233"},
+		}, changed)
+	})
+
+	t.Run("keeps numbers it cannot parse", func(t *testing.T) {
+		users := []identity{{UserID: "1", Email: "evgeny@example.com", PhoneNumber: "12345"}}
+
+		changed, err := normalizeIdentities(users, "RU")
+
+		require.NoError(t, err)
+		assert.Empty(t, changed)
+	})
+
+	t.Run("lists conflicting emails and phone numbers", func(t *testing.T) {
+		users := []identity{
+			{UserID: "1", Email: "evgeny@example.com", PhoneNumber: "+79001234567"},
+			{UserID: "2", Email: "Evgeny@example.com", PhoneNumber: "+79007654321"},
+			{UserID: "3", Email: "anna@example.com", PhoneNumber: "8 900 123-45-67"},
+		}
+
+		_, err := normalizeIdentities(users, "RU")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "email evgeny@example.com (1, 2)")
+		assert.Contains(t, err.Error(), "phone number +79001234567 (1, 3)")
+	})
+}
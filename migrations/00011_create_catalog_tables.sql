@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS locations (
+    location_id uuid PRIMARY KEY,
+    household_id uuid NOT NULL REFERENCES households (household_id),
+    name varchar(255) NOT NULL,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (household_id, name),
+    -- Lets copies reference a location together with its household.
+    UNIQUE (household_id, location_id)
+);
+
+CREATE TABLE IF NOT EXISTS books (
+    book_id uuid PRIMARY KEY,
+    household_id uuid NOT NULL REFERENCES households (household_id),
+    title varchar(255) NOT NULL,
+    authors text[] NOT NULL DEFAULT '{}',
+    isbn varchar(13) NOT NULL DEFAULT '',
+    language varchar(35) NOT NULL DEFAULT '',
+    publisher varchar(255) NOT NULL DEFAULT '',
+    published_year integer,
+    pages integer CHECK (pages > 0),
+    series varchar(255) NOT NULL DEFAULT '',
+    series_index double precision,
+    tags text[] NOT NULL DEFAULT '{}',
+    description text NOT NULL DEFAULT '',
+    cover_id uuid,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (household_id, book_id)
+);
+
+CREATE INDEX idx_books_household_id ON books (household_id, title);
+CREATE INDEX idx_books_isbn ON books (household_id, isbn) WHERE isbn <> '';
+CREATE INDEX idx_books_authors ON books USING gin (authors);
+CREATE INDEX idx_books_tags ON books USING gin (tags);
+
+-- The composite foreign keys keep a copy in the household of its book and of
+-- its location, whatever IDs a request passes.
+CREATE TABLE IF NOT EXISTS copies (
+    copy_id uuid PRIMARY KEY,
+    book_id uuid NOT NULL,
+    household_id uuid NOT NULL,
+    location_id uuid,
+    notes text NOT NULL DEFAULT '',
+    purchase_price numeric(10, 2) CHECK (purchase_price >= 0),
+    purchased_at date,
+    purchase_store varchar(255) NOT NULL DEFAULT '',
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT copies_book_fkey FOREIGN KEY (household_id, book_id)
+        REFERENCES books (household_id, book_id) ON DELETE CASCADE,
+    CONSTRAINT copies_location_fkey FOREIGN KEY (household_id, location_id)
+        REFERENCES locations (household_id, location_id)
+);
+
+CREATE INDEX idx_copies_book_id ON copies (book_id);
+CREATE INDEX idx_copies_location_id ON copies (location_id);
+CREATE INDEX idx_copies_household_id ON copies (household_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS copies;
+DROP TABLE IF EXISTS books;
+DROP TABLE IF EXISTS locations;
+-- +goose StatementEnd
@@ -0,0 +1,81 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS household_friendships (
+    requester_id uuid NOT NULL REFERENCES households (household_id),
+    addressee_id uuid NOT NULL REFERENCES households (household_id),
+    status varchar(10) CHECK (status IN ('pending', 'accepted')) NOT NULL,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    accepted_at timestamp WITH time zone,
+    PRIMARY KEY (requester_id, addressee_id),
+    CHECK (requester_id <> addressee_id)
+);
+
+-- Two households are friends once, whoever asked first.
+CREATE UNIQUE INDEX idx_household_friendships_pair
+    ON household_friendships (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+CREATE INDEX idx_household_friendships_addressee_id ON household_friendships (addressee_id);
+
+ALTER TABLE copies ADD CONSTRAINT copies_household_id_copy_id_key UNIQUE (household_id, copy_id);
+
+-- A loan is made by the household owning the copy, to a friend household's
+-- member or to anyone else by name.
+CREATE TABLE IF NOT EXISTS loans (
+    loan_id uuid PRIMARY KEY,
+    household_id uuid NOT NULL,
+    copy_id uuid NOT NULL,
+    borrower_household_id uuid REFERENCES households (household_id),
+    borrower_user_id uuid REFERENCES users (user_id),
+    borrower_name varchar(255) NOT NULL,
+    lent_by uuid NOT NULL REFERENCES users (user_id),
+    lent_at timestamp WITH time zone NOT NULL,
+    due_at timestamp WITH time zone,
+    returned_at timestamp WITH time zone,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT loans_copy_fkey FOREIGN KEY (household_id, copy_id)
+        REFERENCES copies (household_id, copy_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_loans_open_copy ON loans (copy_id) WHERE returned_at IS NULL;
+CREATE INDEX idx_loans_household_id ON loans (household_id, lent_at DESC);
+CREATE INDEX idx_loans_borrower_household_id ON loans (borrower_household_id) WHERE borrower_household_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS borrow_requests (
+    request_id uuid PRIMARY KEY,
+    copy_id uuid NOT NULL REFERENCES copies (copy_id) ON DELETE CASCADE,
+    lender_household_id uuid NOT NULL REFERENCES households (household_id),
+    borrower_household_id uuid NOT NULL REFERENCES households (household_id),
+    requested_by uuid NOT NULL REFERENCES users (user_id),
+    status varchar(12) CHECK (status IN ('requested', 'approved', 'declined', 'cancelled', 'handed_over', 'returned')) NOT NULL,
+    message varchar(1000) NOT NULL DEFAULT '',
+    due_at timestamp WITH time zone,
+    loan_id uuid REFERENCES loans (loan_id) ON DELETE SET NULL,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+-- A household asks for a copy once until the request is settled.
+CREATE UNIQUE INDEX idx_borrow_requests_open
+    ON borrow_requests (copy_id, borrower_household_id) WHERE status IN ('requested', 'approved');
+CREATE INDEX idx_borrow_requests_lender ON borrow_requests (lender_household_id, created_at DESC);
+CREATE INDEX idx_borrow_requests_borrower ON borrow_requests (borrower_household_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    notification_id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (user_id),
+    type varchar(64) NOT NULL,
+    payload jsonb NOT NULL DEFAULT '{}',
+    read_at timestamp WITH time zone,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS borrow_requests;
+DROP TABLE IF EXISTS loans;
+ALTER TABLE copies DROP CONSTRAINT IF EXISTS copies_household_id_copy_id_key;
+DROP TABLE IF EXISTS household_friendships;
+-- +goose StatementEnd
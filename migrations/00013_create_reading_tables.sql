@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A reading is one pass through a book by a member; rereading adds another.
+-- It is in progress until finished_at is set.
+CREATE TABLE IF NOT EXISTS readings (
+    reading_id uuid PRIMARY KEY,
+    household_id uuid NOT NULL,
+    book_id uuid NOT NULL,
+    user_id uuid NOT NULL REFERENCES users (user_id),
+    started_at timestamp WITH time zone,
+    finished_at timestamp WITH time zone,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT readings_book_fkey FOREIGN KEY (household_id, book_id)
+        REFERENCES books (household_id, book_id) ON DELETE CASCADE,
+    CHECK (finished_at IS NULL OR started_at IS NULL OR finished_at >= started_at)
+);
+
+CREATE INDEX idx_readings_user_id ON readings (user_id, finished_at DESC);
+CREATE INDEX idx_readings_household_id ON readings (household_id, finished_at);
+CREATE INDEX idx_readings_book_id ON readings (book_id);
+
+-- Each member rates a book once; the review text is optional.
+CREATE TABLE IF NOT EXISTS reviews (
+    household_id uuid NOT NULL,
+    book_id uuid NOT NULL,
+    user_id uuid NOT NULL REFERENCES users (user_id),
+    rating smallint NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    text text NOT NULL DEFAULT '',
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (book_id, user_id),
+    CONSTRAINT reviews_book_fkey FOREIGN KEY (household_id, book_id)
+        REFERENCES books (household_id, book_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_reviews_household_id ON reviews (household_id);
+CREATE INDEX idx_reviews_user_id ON reviews (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reviews;
+DROP TABLE IF EXISTS readings;
+-- +goose StatementEnd
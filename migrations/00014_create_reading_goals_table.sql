@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A goal without filters is a plain yearly target such as "50 books"; the
+-- filters turn it into a challenge such as "5 books in English".
+CREATE TABLE IF NOT EXISTS reading_goals (
+    goal_id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (user_id),
+    name varchar(255) NOT NULL DEFAULT '',
+    year integer NOT NULL CHECK (year > 0),
+    metric varchar(16) NOT NULL CHECK (metric IN ('books', 'pages', 'series')),
+    target integer NOT NULL CHECK (target > 0),
+    language varchar(35) NOT NULL DEFAULT '',
+    author varchar(255) NOT NULL DEFAULT '',
+    tag varchar(100) NOT NULL DEFAULT '',
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_reading_goals_user_id ON reading_goals (user_id, year);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reading_goals;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- An import keeps its uploaded file in the blob store until the job has run.
+-- Rows matching a book by ISBN update it; rows only resembling one by title
+-- and authors are duplicates, resolved as the import says.
+CREATE TABLE IF NOT EXISTS book_imports (
+    import_id uuid PRIMARY KEY,
+    household_id uuid NOT NULL REFERENCES households (household_id),
+    created_by uuid NOT NULL REFERENCES users (user_id),
+    format varchar(16) NOT NULL CHECK (format IN ('goodreads', 'librarything')),
+    duplicates varchar(8) NOT NULL CHECK (duplicates IN ('skip', 'update', 'create')),
+    status varchar(8) NOT NULL CHECK (status IN ('pending', 'done', 'failed')),
+    created integer NOT NULL DEFAULT 0,
+    updated integer NOT NULL DEFAULT 0,
+    skipped integer NOT NULL DEFAULT 0,
+    failed integer NOT NULL DEFAULT 0,
+    error text NOT NULL DEFAULT '',
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    finished_at timestamp WITH time zone
+);
+
+CREATE INDEX idx_book_imports_household_id ON book_imports (household_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS book_import_errors (
+    import_id uuid NOT NULL REFERENCES book_imports (import_id) ON DELETE CASCADE,
+    line integer NOT NULL,
+    message text NOT NULL,
+    PRIMARY KEY (import_id, line)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS book_import_errors;
+DROP TABLE IF EXISTS book_imports;
+-- +goose StatementEnd
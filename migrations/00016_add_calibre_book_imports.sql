@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE book_imports DROP CONSTRAINT book_imports_format_check;
+ALTER TABLE book_imports ADD CONSTRAINT book_imports_format_check
+    CHECK (format IN ('goodreads', 'librarything', 'calibre'));
+
+-- Remembers which book a row of an earlier import became, so that importing
+-- the same source again updates the book rather than adding another.
+CREATE TABLE IF NOT EXISTS book_import_sources (
+    household_id uuid NOT NULL,
+    format varchar(16) NOT NULL,
+    source_id varchar(64) NOT NULL,
+    book_id uuid NOT NULL,
+    PRIMARY KEY (household_id, format, source_id),
+    CONSTRAINT book_import_sources_book_fkey FOREIGN KEY (household_id, book_id)
+        REFERENCES books (household_id, book_id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_book_import_sources_book_id ON book_import_sources (book_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS book_import_sources;
+
+DELETE FROM book_imports WHERE format = 'calibre';
+ALTER TABLE book_imports DROP CONSTRAINT book_imports_format_check;
+ALTER TABLE book_imports ADD CONSTRAINT book_imports_format_check
+    CHECK (format IN ('goodreads', 'librarything'));
+-- +goose StatementEnd
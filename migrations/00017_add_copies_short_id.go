@@ -0,0 +1,105 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"home-library/pkg/shortid"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddCopiesShortID, downAddCopiesShortID)
+}
+
+// upAddCopiesShortID gives every copy the short ID printed on its label. The
+// IDs are random, so they are generated here rather than in SQL.
+func upAddCopiesShortID(ctx context.Context, tx *sql.Tx) error {
+	return addShortIDs(ctx, tx, "copies", "copy_id")
+}
+
+func downAddCopiesShortID(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE copies DROP COLUMN short_id`)
+	return err
+}
+
+// shortIDRow is a row waiting for its short ID.
+type shortIDRow struct {
+	ID          string
+	HouseholdID string
+	ShortID     string
+}
+
+// addShortIDs adds a short_id column to a table of household rows keyed by
+// the given column and fills it in.
+func addShortIDs(ctx context.Context, tx *sql.Tx, table string, key string) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ADD COLUMN short_id varchar(8)`, table))
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT %s, household_id FROM %s`, key, table))
+	if err != nil {
+		return err
+	}
+	var pending []shortIDRow
+	for rows.Next() {
+		var row shortIDRow
+		if err := rows.Scan(&row.ID, &row.HouseholdID); err != nil {
+			rows.Close()
+			return err
+		}
+		pending = append(pending, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := assignShortIDs(pending, shortid.New); err != nil {
+		return err
+	}
+	update := fmt.Sprintf(`UPDATE %s SET short_id = $2 WHERE %s = $1`, table, key)
+	for _, row := range pending {
+		if _, err := tx.ExecContext(ctx, update, row.ID, row.ShortID); err != nil {
+			return err
+		}
+	}
+
+	// Codes are typed and scanned within a household, so they only need to
+	// be unique there.
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		ALTER TABLE %[1]s
+			ALTER COLUMN short_id SET NOT NULL,
+			ADD CONSTRAINT %[1]s_household_id_short_id_key UNIQUE (household_id, short_id);
+	`, table))
+	return err
+}
+
+// shortIDAttempts bounds the draws for one row; with 40 random bits even a
+// second one is rare.
+const shortIDAttempts = 5
+
+// assignShortIDs gives each row an ID no other row of its household has.
+func assignShortIDs(rows []shortIDRow, generate func() (string, error)) error {
+	taken := make(map[string]bool, len(rows))
+	for i := range rows {
+		for attempt := 0; ; attempt++ {
+			if attempt == shortIDAttempts {
+				return fmt.Errorf("no free short id for %s", rows[i].ID)
+			}
+
+			id, err := generate()
+			if err != nil {
+				return err
+			}
+			if key := rows[i].HouseholdID + "/" + id; !taken[key] {
+				taken[key] = true
+				rows[i].ShortID = id
+				break
+			}
+		}
+	}
+	return nil
+}
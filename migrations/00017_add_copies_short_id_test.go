@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequence returns the IDs in order, one per call.
+func sequence(ids ...string) func() (string, error) {
+	return func() (string, error) {
+		id := ids[0]
+		ids = ids[1:]
+		return id, nil
+	}
+}
+
+func TestAssignShortIDs(t *testing.T) {
+	t.Run("draws again for an ID taken in the household", func(t *testing.T) {
+		copies := []shortIDRow{
+			{ID: "1", HouseholdID: "a"},
+			{ID: "2", HouseholdID: "a"},
+			{ID: "3", HouseholdID: "b"},
+		}
+
+		err := assignShortIDs(copies, sequence("K7M2Q9X0", "K7M2Q9X0", "7TGZ3B1D", "K7M2Q9X0"))
+
+		require.NoError(t, err)
+		assert.Equal(t, []shortIDRow{
+			{ID: "1", HouseholdID: "a", ShortID: "K7M2Q9X0"},
+			{ID: "2", HouseholdID: "a", ShortID: "7TGZ3B1D"},
+			{ID: "3", HouseholdID: "b", ShortID: "K7M2Q9X0"},
+		}, copies)
+	})
+
+	t.Run("gives up on a generator that keeps repeating", func(t *testing.T) {
+		copies := []shortIDRow{{ID: "1", HouseholdID: "a"}, {ID: "2", HouseholdID: "a"}}
+		generate := func() (string, error) { return "K7M2Q9X0", nil }
+
+		err := assignShortIDs(copies, generate)
+
+		assert.Error(t, err)
+	})
+}
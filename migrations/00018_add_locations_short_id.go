@@ -0,0 +1,23 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upAddLocationsShortID, downAddLocationsShortID)
+}
+
+// upAddLocationsShortID gives every location the short ID printed on its
+// shelf label.
+func upAddLocationsShortID(ctx context.Context, tx *sql.Tx) error {
+	return addShortIDs(ctx, tx, "locations", "location_id")
+}
+
+func downAddLocationsShortID(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `ALTER TABLE locations DROP COLUMN short_id`)
+	return err
+}
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A scan session remembers, between the scans of one device, the shelf last
+-- scanned and what to do with each copy scanned next. The location is not a
+-- foreign key: a shelf deleted meanwhile simply reads as no shelf.
+CREATE TABLE IF NOT EXISTS scan_sessions (
+    session_id uuid PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    household_id uuid NOT NULL REFERENCES households (household_id) ON DELETE CASCADE,
+    mode varchar(8) NOT NULL DEFAULT 'lookup' CHECK (mode IN ('lookup', 'move', 'lend', 'return')),
+    location_id uuid,
+    borrower_name varchar(255) NOT NULL DEFAULT '',
+    due_at timestamp WITH time zone,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scan_sessions_updated_at ON scan_sessions (updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS scan_sessions;
+-- +goose StatementEnd
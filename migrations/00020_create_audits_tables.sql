@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- An audit checks one location against the catalog by scanning the labels of
+-- the copies found there. It stays open, and can be resumed, until it is
+-- closed; the counts are filled in then and kept as the location's history.
+CREATE TABLE IF NOT EXISTS audits (
+    audit_id uuid PRIMARY KEY,
+    household_id uuid NOT NULL,
+    location_id uuid NOT NULL,
+    started_by uuid NOT NULL REFERENCES users (user_id),
+    status varchar(8) NOT NULL CHECK (status IN ('open', 'closed')),
+    found integer NOT NULL DEFAULT 0,
+    misplaced integer NOT NULL DEFAULT 0,
+    missing integer NOT NULL DEFAULT 0,
+    unknown integer NOT NULL DEFAULT 0,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    closed_at timestamp WITH time zone,
+    CONSTRAINT audits_location_fkey FOREIGN KEY (household_id, location_id)
+        REFERENCES locations (household_id, location_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_audits_open_location ON audits (location_id) WHERE status = 'open';
+CREATE INDEX idx_audits_household_id ON audits (household_id, created_at DESC);
+
+-- Scans hold the short IDs read, resolved against the catalog when the report
+-- is made, so a copy added during the audit is no longer unknown.
+CREATE TABLE IF NOT EXISTS audit_scans (
+    audit_id uuid NOT NULL REFERENCES audits (audit_id) ON DELETE CASCADE,
+    code varchar(8) NOT NULL,
+    scanned_at timestamp WITH time zone NOT NULL,
+    PRIMARY KEY (audit_id, code)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_scans;
+DROP TABLE IF EXISTS audits;
+-- +goose StatementEnd
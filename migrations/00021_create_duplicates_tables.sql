@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Candidate duplicates found by the detector. Each run replaces the pending
+-- pairs of the household; dismissed ones stay so they are not offered again.
+-- The pair is stored with book_a < book_b, whichever came first.
+CREATE TABLE IF NOT EXISTS duplicate_books (
+    household_id uuid NOT NULL,
+    book_a uuid NOT NULL,
+    book_b uuid NOT NULL,
+    score double precision NOT NULL,
+    reasons text[] NOT NULL DEFAULT '{}',
+    dismissed boolean NOT NULL DEFAULT FALSE,
+    detected_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (book_a, book_b),
+    CONSTRAINT duplicate_books_a_fkey FOREIGN KEY (household_id, book_a)
+        REFERENCES books (household_id, book_id) ON DELETE CASCADE,
+    CONSTRAINT duplicate_books_b_fkey FOREIGN KEY (household_id, book_b)
+        REFERENCES books (household_id, book_id) ON DELETE CASCADE,
+    CHECK (book_a < book_b)
+);
+
+CREATE INDEX idx_duplicate_books_household_id ON duplicate_books (household_id, score DESC);
+CREATE INDEX idx_duplicate_books_book_b ON duplicate_books (book_b);
+
+-- Authors are the names on books rather than records of their own, so the
+-- pairs hold names, ordered like the book pairs.
+CREATE TABLE IF NOT EXISTS duplicate_authors (
+    household_id uuid NOT NULL REFERENCES households (household_id),
+    author_a text NOT NULL,
+    author_b text NOT NULL,
+    score double precision NOT NULL,
+    dismissed boolean NOT NULL DEFAULT FALSE,
+    detected_at timestamp WITH time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (household_id, author_a, author_b)
+);
+
+CREATE INDEX idx_duplicate_authors_household_id ON duplicate_authors (household_id, score DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS duplicate_authors;
+DROP TABLE IF EXISTS duplicate_books;
+-- +goose StatementEnd
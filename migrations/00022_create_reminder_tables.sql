@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Loan reminder settings of a user. Users without a row get the defaults of
+-- pkg/reminders. Quiet hours are minutes after midnight in the library's
+-- time zone.
+CREATE TABLE IF NOT EXISTS reminder_preferences (
+    user_id uuid PRIMARY KEY REFERENCES users (user_id),
+    disabled boolean NOT NULL DEFAULT FALSE,
+    days_before smallint NOT NULL CHECK (days_before BETWEEN 0 AND 30),
+    quiet_from smallint NOT NULL CHECK (quiet_from BETWEEN 0 AND 1439),
+    quiet_to smallint NOT NULL CHECK (quiet_to BETWEEN 0 AND 1439),
+    updated_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+-- The reminders already sent, by the key pkg/reminders gives them, so that
+-- none is sent twice however often the reminder task runs.
+CREATE TABLE IF NOT EXISTS sent_reminders (
+    key varchar(128) PRIMARY KEY,
+    loan_id uuid NOT NULL REFERENCES loans (loan_id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users (user_id),
+    sent_at timestamp WITH time zone NOT NULL
+);
+
+CREATE INDEX idx_sent_reminders_loan_id ON sent_reminders (loan_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS sent_reminders;
+DROP TABLE IF EXISTS reminder_preferences;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Where and for how much a wished book was bought. The copy added for the
+-- book to the catalog takes these over when the item leaves the wishlist.
+ALTER TABLE wishlist_items
+    ADD COLUMN purchase_price numeric(10, 2) CHECK (purchase_price >= 0),
+    ADD COLUMN purchased_at date,
+    ADD COLUMN purchase_store varchar(255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE wishlist_items
+    DROP COLUMN purchase_price,
+    DROP COLUMN purchased_at,
+    DROP COLUMN purchase_store;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Records the uploaded covers, so a book can only take a cover of its own
+-- household and a cover shared by several books and ebook files is deleted
+-- with the last of them. Covers of the old rows have no known uploader.
+CREATE TABLE IF NOT EXISTS covers (
+    cover_id uuid PRIMARY KEY,
+    uploaded_by uuid REFERENCES users (user_id) ON DELETE SET NULL,
+    created_at timestamp WITH time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_covers_uploaded_by ON covers (uploaded_by);
+
+INSERT INTO covers (cover_id, uploaded_by)
+SELECT DISTINCT ON (cover_id) cover_id, owner_id FROM ebook_files
+WHERE cover_id IS NOT NULL
+ORDER BY cover_id, created_at;
+
+INSERT INTO covers (cover_id)
+SELECT DISTINCT cover_id FROM books WHERE cover_id IS NOT NULL
+ON CONFLICT (cover_id) DO NOTHING;
+
+-- A cover still in use cannot be deleted, even by a check racing with a
+-- book taking it.
+ALTER TABLE books ADD CONSTRAINT books_cover_fkey
+    FOREIGN KEY (cover_id) REFERENCES covers (cover_id);
+ALTER TABLE ebook_files ADD CONSTRAINT ebook_files_cover_fkey
+    FOREIGN KEY (cover_id) REFERENCES covers (cover_id);
+
+CREATE INDEX idx_books_cover_id ON books (cover_id) WHERE cover_id IS NOT NULL;
+CREATE INDEX idx_ebook_files_cover_id ON ebook_files (cover_id) WHERE cover_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE ebook_files DROP CONSTRAINT ebook_files_cover_fkey;
+ALTER TABLE books DROP CONSTRAINT books_cover_fkey;
+DROP INDEX IF EXISTS idx_ebook_files_cover_id;
+DROP INDEX IF EXISTS idx_books_cover_id;
+DROP TABLE IF EXISTS covers;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The catalog entry an ebook file is of. Deleting the book keeps the file.
+ALTER TABLE ebook_files
+    ADD COLUMN book_id uuid REFERENCES books (book_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_ebook_files_book_id ON ebook_files (book_id) WHERE book_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE ebook_files DROP COLUMN book_id;
+-- +goose StatementEnd
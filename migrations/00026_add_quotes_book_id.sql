@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The catalog book a quote was matched to on import. Deleting the book keeps
+-- the quote.
+ALTER TABLE quotes
+    ADD COLUMN book_id uuid REFERENCES books (book_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_quotes_user_id_book_id ON quotes (user_id, book_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE quotes DROP COLUMN book_id;
+-- +goose StatementEnd
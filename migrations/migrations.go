@@ -0,0 +1,32 @@
+// Package migrations holds the database schema changes. Most of them are SQL
+// files; the ones that need application code, such as normalizing stored
+// contacts, are Go functions registered with goose.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+
+	"github.com/pressly/goose/v3"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// phoneRegion is the region of stored phone numbers written without the
+// international prefix. Run sets it before any migration executes.
+var phoneRegion string
+
+// Run executes a goose command such as "up", "down" or "status" against the
+// embedded migrations.
+func Run(ctx context.Context, db *sql.DB, region string, command string, args ...string) error {
+	phoneRegion = region
+
+	goose.SetBaseFS(files)
+	if err := goose.SetDialect("postgres"); err != nil {
+		return err
+	}
+
+	return goose.RunContext(ctx, command, db, ".", args...)
+}
@@ -0,0 +1,111 @@
+// Package audit reconciles a shelf inventory: what was scanned on each
+// location against where the catalog says every copy should be.
+package audit
+
+import (
+	"sort"
+	"time"
+)
+
+// Scan records that a copy was found on a location during the audit.
+type Scan struct {
+	Location string
+	Copy     string
+	At       time.Time
+}
+
+// Misplaced is a copy found somewhere other than where it is recorded.
+// Expected is empty for a copy that had no location.
+type Misplaced struct {
+	Copy     string
+	Expected string
+	Found    string
+}
+
+// Missing is a copy that was not found on any audited location.
+type Missing struct {
+	Copy     string
+	Expected string
+}
+
+type Report struct {
+	Found     []string
+	Misplaced []Misplaced
+	Missing   []Missing
+	// Unknown are scanned codes that are not copies of the catalog.
+	Unknown []string
+}
+
+// Move is a correction that records a copy at the location it was found on.
+type Move struct {
+	Copy string
+	From string
+	To   string
+}
+
+// Reconcile compares the scans of the audited locations with the expected
+// location of each copy. Only copies expected on one of the audited
+// locations can be missing, so an audit may cover part of the library.
+// Scans can arrive over several sittings and in any order; when a copy was
+// scanned twice, the latest scan counts, as the copy may have been moved in
+// between.
+func Reconcile(expected map[string]string, audited []string, scans []Scan) Report {
+	inScope := make(map[string]bool, len(audited))
+	for _, location := range audited {
+		inScope[location] = true
+	}
+
+	latest := make(map[string]Scan, len(scans))
+	for _, scan := range scans {
+		if previous, ok := latest[scan.Copy]; !ok || !scan.At.Before(previous.At) {
+			latest[scan.Copy] = scan
+		}
+	}
+
+	report := Report{
+		Found:     make([]string, 0),
+		Misplaced: make([]Misplaced, 0),
+		Missing:   make([]Missing, 0),
+		Unknown:   make([]string, 0),
+	}
+
+	for copyID, scan := range latest {
+		location, known := expected[copyID]
+		switch {
+		case !known:
+			report.Unknown = append(report.Unknown, copyID)
+		case location == scan.Location:
+			report.Found = append(report.Found, copyID)
+		default:
+			report.Misplaced = append(report.Misplaced, Misplaced{Copy: copyID, Expected: location, Found: scan.Location})
+		}
+	}
+
+	for copyID, location := range expected {
+		if _, scanned := latest[copyID]; !scanned && inScope[location] {
+			report.Missing = append(report.Missing, Missing{Copy: copyID, Expected: location})
+		}
+	}
+
+	sort.Strings(report.Found)
+	sort.Strings(report.Unknown)
+	sort.Slice(report.Misplaced, func(i, j int) bool { return report.Misplaced[i].Copy < report.Misplaced[j].Copy })
+	sort.Slice(report.Missing, func(i, j int) bool {
+		if report.Missing[i].Expected != report.Missing[j].Expected {
+			return report.Missing[i].Expected < report.Missing[j].Expected
+		}
+		return report.Missing[i].Copy < report.Missing[j].Copy
+	})
+
+	return report
+}
+
+// Corrections returns the moves that make the catalog match the shelves for
+// every misplaced copy of the report.
+func (r Report) Corrections() []Move {
+	moves := make([]Move, len(r.Misplaced))
+	for i, misplaced := range r.Misplaced {
+		moves[i] = Move{Copy: misplaced.Copy, From: misplaced.Expected, To: misplaced.Found}
+	}
+	return moves
+}
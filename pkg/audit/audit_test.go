@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcile(t *testing.T) {
+	start := time.Date(2026, time.January, 3, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	expected := map[string]string{
+		"A1": "shelf-1",
+		"A2": "shelf-1",
+		"A3": "shelf-1",
+		"B1": "shelf-2",
+		"B2": "shelf-2",
+		"C1": "shelf-3",
+		"N1": "",
+	}
+
+	scans := []Scan{
+		{Location: "shelf-1", Copy: "A1", At: at(1)},
+		{Location: "shelf-1", Copy: "B1", At: at(2)},
+		{Location: "shelf-1", Copy: "X9", At: at(3)},
+		{Location: "shelf-2", Copy: "B2", At: at(10)},
+		// A3 was found on shelf 2 first and then put back where it belongs.
+		{Location: "shelf-1", Copy: "A3", At: at(12)},
+		{Location: "shelf-2", Copy: "A3", At: at(11)},
+		{Location: "shelf-2", Copy: "C1", At: at(13)},
+		{Location: "shelf-2", Copy: "N1", At: at(14)},
+		{Location: "shelf-2", Copy: "B2", At: at(15)},
+	}
+
+	report := Reconcile(expected, []string{"shelf-1", "shelf-2"}, scans)
+
+	assert.Equal(t, []string{"A1", "A3", "B2"}, report.Found)
+	assert.Equal(t, []Misplaced{
+		{Copy: "B1", Expected: "shelf-2", Found: "shelf-1"},
+		{Copy: "C1", Expected: "shelf-3", Found: "shelf-2"},
+		{Copy: "N1", Expected: "", Found: "shelf-2"},
+	}, report.Misplaced)
+	// C1's shelf was not audited, so only A2 counts as missing.
+	assert.Equal(t, []Missing{{Copy: "A2", Expected: "shelf-1"}}, report.Missing)
+	assert.Equal(t, []string{"X9"}, report.Unknown)
+
+	assert.Equal(t, []Move{
+		{Copy: "B1", From: "shelf-2", To: "shelf-1"},
+		{Copy: "C1", From: "shelf-3", To: "shelf-2"},
+		{Copy: "N1", From: "", To: "shelf-2"},
+	}, report.Corrections())
+}
+
+func TestReconcileNothingScanned(t *testing.T) {
+	report := Reconcile(map[string]string{"B1": "shelf-2", "A1": "shelf-1"}, []string{"shelf-2", "shelf-1"}, nil)
+
+	assert.Empty(t, report.Found)
+	assert.Empty(t, report.Misplaced)
+	assert.Empty(t, report.Unknown)
+	assert.Equal(t, []Missing{{Copy: "A1", Expected: "shelf-1"}, {Copy: "B1", Expected: "shelf-2"}}, report.Missing)
+	assert.Empty(t, report.Corrections())
+}
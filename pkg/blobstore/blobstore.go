@@ -0,0 +1,69 @@
+// Package blobstore stores opaque binary objects (cover images, ebook files)
+// behind a small interface with a local filesystem and an S3-compatible
+// implementation.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"home-library/pkg/config"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Info describes a stored object.
+type Info struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Object is an open stored object. The caller must close it.
+type Object struct {
+	io.ReadCloser
+	Info
+}
+
+type Store interface {
+	// Put stores the object under key, replacing any previous one. Size may
+	// be -1 when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Stat(ctx context.Context, key string) (*Info, error)
+	// Delete removes the object. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the store selected by cfg.Driver ("local" or "s3").
+func New(cfg *config.BlobStoreConfig) (Store, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocal(cfg.Local.Root)
+	case "s3":
+		return NewS3(&cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown blob store driver %q", cfg.Driver)
+	}
+}
+
+// validKey accepts slash separated relative keys without empty, "." or ".."
+// segments so that they map safely onto both a directory tree and S3.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package blobstore
+
+import (
+	"context"
+	"home-library/pkg/config"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore runs the behaviour every Store implementation must share.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	t.Run("put and get", func(t *testing.T) {
+		err := store.Put(ctx, "covers/a/large.jpg", strings.NewReader("jpeg data"), 9, "image/jpeg")
+		require.NoError(t, err)
+
+		object, err := store.Get(ctx, "covers/a/large.jpg")
+		require.NoError(t, err)
+		defer object.Close()
+
+		data, err := io.ReadAll(object)
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg data", string(data))
+		assert.Equal(t, int64(9), object.Size)
+		assert.Equal(t, "image/jpeg", object.ContentType)
+		assert.False(t, object.LastModified.IsZero())
+	})
+
+	t.Run("put replaces object", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "replace.txt", strings.NewReader("old"), -1, "text/plain"))
+		require.NoError(t, store.Put(ctx, "replace.txt", strings.NewReader("newer"), -1, "text/plain"))
+
+		info, err := store.Stat(ctx, "replace.txt")
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), info.Size)
+	})
+
+	t.Run("missing object", func(t *testing.T) {
+		_, err := store.Get(ctx, "covers/missing.jpg")
+		assert.Equal(t, ErrNotFound, err)
+
+		_, err = store.Stat(ctx, "covers/missing.jpg")
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "delete.txt", strings.NewReader("x"), 1, "text/plain"))
+		require.NoError(t, store.Delete(ctx, "delete.txt"))
+
+		_, err := store.Stat(ctx, "delete.txt")
+		assert.Equal(t, ErrNotFound, err)
+		assert.NoError(t, store.Delete(ctx, "delete.txt"))
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		for _, key := range []string{"", "/etc/passwd", "../escape", "a//b", "a/./b", `a\b`} {
+			err := store.Put(ctx, key, strings.NewReader("x"), 1, "")
+			assert.Equal(t, ErrInvalidKey, err, key)
+		}
+	})
+}
+
+func TestLocal(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	testStore(t, store)
+}
+
+func TestLocalGuessesContentType(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocal(root)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(root+"/book.epub", []byte("PK"), 0o644))
+
+	info, err := store.Stat(context.Background(), "book.epub")
+	require.NoError(t, err)
+	assert.Equal(t, "application/epub+zip", info.ContentType)
+}
+
+// TestS3 runs against an S3-compatible service, e.g. a local MinIO:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	BLOBSTORE_S3_ENDPOINT=localhost:9000 BLOBSTORE_S3_BUCKET=test \
+//	BLOBSTORE_S3_ACCESS_KEY=minioadmin BLOBSTORE_S3_SECRET_KEY=minioadmin go test ./pkg/blobstore
+func TestS3(t *testing.T) {
+	endpoint := os.Getenv("BLOBSTORE_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("BLOBSTORE_S3_ENDPOINT is not set")
+	}
+
+	store, err := NewS3(&config.S3BlobConfig{
+		Endpoint:  endpoint,
+		Bucket:    os.Getenv("BLOBSTORE_S3_BUCKET"),
+		AccessKey: os.Getenv("BLOBSTORE_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("BLOBSTORE_S3_SECRET_KEY"),
+	})
+	require.NoError(t, err)
+
+	s3 := store.(*s3Store)
+	exists, err := s3.client.BucketExists(context.Background(), s3.bucket)
+	require.NoError(t, err)
+	if !exists {
+		require.NoError(t, s3.client.MakeBucket(context.Background(), s3.bucket, minio.MakeBucketOptions{}))
+	}
+
+	testStore(t, store)
+}
@@ -0,0 +1,150 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+// contentTypeSuffix holds the content type next to the object, the local
+// filesystem has nowhere else to keep it.
+const contentTypeSuffix = ".content-type"
+
+type localStore struct {
+	root string
+}
+
+// NewLocal returns a store keeping objects as files under root.
+func NewLocal(root string) (Store, error) {
+	if root == "" {
+		return nil, errors.New("blob store root is not set")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &localStore{root: root}, nil
+}
+
+func (s *localStore) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func (s *localStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial object.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, &contextReader{ctx: ctx, r: r}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if contentType != "" {
+		if err := os.WriteFile(path+contentTypeSuffix, []byte(contentType), 0o644); err != nil {
+			return err
+		}
+	} else if err := os.Remove(path + contentTypeSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *localStore) Get(ctx context.Context, key string) (*Object, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	path, _ := s.path(key)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, mapNotExist(err)
+	}
+
+	return &Object{ReadCloser: file, Info: *info}, nil
+}
+
+func (s *localStore) Stat(_ context.Context, key string) (*Info, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, mapNotExist(err)
+	}
+	if stat.IsDir() {
+		return nil, ErrNotFound
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if data, err := os.ReadFile(path + contentTypeSuffix); err == nil {
+		contentType = string(data)
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &Info{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  contentType,
+		LastModified: stat.ModTime(),
+	}, nil
+}
+
+func (s *localStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{path, path + contentTypeSuffix} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func mapNotExist(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
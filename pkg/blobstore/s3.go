@@ -0,0 +1,102 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"home-library/pkg/config"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3 returns a store backed by an S3-compatible service (AWS S3, MinIO,
+// Yandex Object Storage...). The bucket must already exist.
+func NewS3(cfg *config.S3BlobConfig) (Store, error) {
+	if cfg.Bucket == "" {
+		return nil, errors.New("blob store bucket is not set")
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (*Object, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, mapNoSuchKey(err)
+	}
+
+	// GetObject is lazy, Stat performs the request and reports a missing key.
+	stat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, mapNoSuchKey(err)
+	}
+
+	return &Object{ReadCloser: object, Info: toInfo(stat)}, nil
+}
+
+func (s *s3Store) Stat(ctx context.Context, key string) (*Info, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	stat, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, mapNoSuchKey(err)
+	}
+
+	info := toInfo(stat)
+	return &info, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+func toInfo(stat minio.ObjectInfo) Info {
+	return Info{
+		Key:          stat.Key,
+		Size:         stat.Size,
+		ContentType:  stat.ContentType,
+		LastModified: stat.LastModified,
+	}
+}
+
+func mapNoSuchKey(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,160 @@
+// Package bookimport reads catalog exports from other book tracking services
+// row by row, so large files never have to be held in memory.
+package bookimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatGoodreads    Format = "goodreads"
+	FormatLibraryThing Format = "librarything"
+	FormatCalibre      Format = "calibre"
+)
+
+var ErrUnknownFormat = errors.New("unknown import format")
+
+// Record is a single book from an export, normalized across formats.
+type Record struct {
+	Line      int
+	SourceID  string
+	Title     string
+	Authors   []string
+	ISBN10    string
+	ISBN13    string
+	Publisher string
+	Language  string
+	Pages     int
+	Year      int
+	Rating    float64
+	Tags      []string
+	Review    string
+	DateRead  *time.Time
+	DateAdded *time.Time
+
+	Series      string
+	SeriesIndex *float64
+	Description string
+	// Files are the paths of the book's ebook files within a Calibre
+	// library.
+	Files []string
+}
+
+// RowError describes a row that could not be parsed. Reading may continue
+// after it.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+type Reader interface {
+	// Next returns the next record, a *RowError for a malformed row, or io.EOF
+	// when the input is exhausted.
+	Next() (*Record, error)
+}
+
+// NewReader reads the exports that come as a single table. A Calibre
+// library is a tree of files, read by NewCalibreReader.
+func NewReader(format Format, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatGoodreads:
+		return NewGoodreadsReader(r)
+	case FormatLibraryThing:
+		return NewLibraryThingReader(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// table wraps csv.Reader and gives access to cells by header name.
+type table struct {
+	csv     *csv.Reader
+	columns map[string]int
+	row     []string
+}
+
+func newTable(r io.Reader, comma rune) (*table, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	return &table{csv: reader, columns: columns}, nil
+}
+
+func (t *table) next() (int, error) {
+	row, err := t.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return 0, err
+	}
+	t.row = row
+
+	line, _ := t.csv.FieldPos(0)
+	return line, nil
+}
+
+func (t *table) has(column string) bool {
+	_, ok := t.columns[column]
+	return ok
+}
+
+func (t *table) get(column string) string {
+	i, ok := t.columns[column]
+	if !ok || i >= len(t.row) {
+		return ""
+	}
+	return strings.TrimSpace(t.row[i])
+}
+
+func splitList(s string, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseDate(s string, layouts ...string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,158 @@
+package bookimport
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const goodreadsExport = "\ufeff" + `Book Id,Title,Author,Author l-f,Additional Authors,ISBN,ISBN13,My Rating,Average Rating,Publisher,Binding,Number of Pages,Year Published,Original Publication Year,Date Read,Date Added,Bookshelves,Bookshelves with positions,Exclusive Shelf,My Review,Spoiler,Private Notes,Read Count,Owned Copies
+234225,Dune (Dune #1),Frank Herbert,"Herbert, Frank",,"=""0441013597""","=""9780441013593""",5,4.25,Ace,Paperback,658,2005,1965,2021/03/15,2020/12/01,"sci-fi, favorites, read","sci-fi (#3), favorites (#1), read (#120)",read,"Still great.",,,1,1
+5,"Пикник на обочине",Аркадий Стругацкий,"Стругацкий, Аркадий",Борис Стругацкий,"=""""","=""""",0,4.21,АСТ,Hardcover,not-a-number,2015,1972,,2022/01/10,to-read,to-read (#4),to-read,,,,0,0
+6,,Nobody,,,,,0,0,,,,,,,2022/01/10,,,,,,,0,0
+`
+
+func TestGoodreadsReader(t *testing.T) {
+	reader, err := NewReader(FormatGoodreads, strings.NewReader(goodreadsExport))
+	require.NoError(t, err)
+
+	t.Run("full row", func(t *testing.T) {
+		record, err := reader.Next()
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, record.Line)
+		assert.Equal(t, "234225", record.SourceID)
+		assert.Equal(t, "Dune (Dune #1)", record.Title)
+		assert.Equal(t, []string{"Frank Herbert"}, record.Authors)
+		assert.Equal(t, "0441013597", record.ISBN10)
+		assert.Equal(t, "9780441013593", record.ISBN13)
+		assert.Equal(t, 658, record.Pages)
+		assert.Equal(t, 1965, record.Year)
+		assert.Equal(t, 5.0, record.Rating)
+		assert.Equal(t, []string{"sci-fi", "favorites"}, record.Tags)
+		assert.Equal(t, "Still great.", record.Review)
+		require.NotNil(t, record.DateRead)
+		assert.Equal(t, "2021-03-15", record.DateRead.Format("2006-01-02"))
+	})
+
+	t.Run("malformed row is reported and skipped", func(t *testing.T) {
+		record, err := reader.Next()
+
+		var rowErr *RowError
+		require.True(t, errors.As(err, &rowErr))
+		assert.Equal(t, 3, rowErr.Line)
+		assert.Nil(t, record)
+	})
+
+	t.Run("row without title", func(t *testing.T) {
+		_, err := reader.Next()
+
+		var rowErr *RowError
+		require.True(t, errors.As(err, &rowErr))
+		assert.Equal(t, 4, rowErr.Line)
+	})
+
+	t.Run("end of input", func(t *testing.T) {
+		_, err := reader.Next()
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+const libraryThingExport = "Book Id\tTitle\tSort Character\tPrimary Author\tPrimary Author Role\tSecondary Author\tSecondary Author Roles\tPublication\tDate\tReview\tRating\tComment\tPrivate Comment\tSummary\tMedia\tPhysical Description\tPage Count\tDate Started\tDate Read\tTags\tCollections\tLanguages\tISBN\tISBNs\tEntry Date\n" +
+	"123\tSolaris\t1\tLem, Stanisław\tAuthor\tKilmartin, Joanna|Cox, Steve\tTranslator\tFaber & Faber (2003), Paperback, 224 pages\t1961\tA classic.\t4.5\t\t\tSolaris\tPaperback\t224 p.\t224\t\t[2023-05-02]\tsci-fi, polish\tYour library\tEnglish\t[0571219950]\t9780571219957, 0571219950\t[2019-05-04]\n"
+
+func TestLibraryThingReader(t *testing.T) {
+	reader, err := NewReader(FormatLibraryThing, strings.NewReader(libraryThingExport))
+	require.NoError(t, err)
+
+	record, err := reader.Next()
+	require.NoError(t, err)
+
+	assert.Equal(t, "123", record.SourceID)
+	assert.Equal(t, "Solaris", record.Title)
+	assert.Equal(t, []string{"Lem, Stanisław", "Kilmartin, Joanna", "Cox, Steve"}, record.Authors)
+	assert.Equal(t, "Faber & Faber", record.Publisher)
+	assert.Equal(t, "0571219950", record.ISBN10)
+	assert.Equal(t, "9780571219957", record.ISBN13)
+	assert.Equal(t, 224, record.Pages)
+	assert.Equal(t, 1961, record.Year)
+	assert.Equal(t, 4.5, record.Rating)
+	assert.Equal(t, "English", record.Language)
+	assert.Equal(t, []string{"sci-fi", "polish"}, record.Tags)
+	require.NotNil(t, record.DateAdded)
+	assert.Equal(t, "2019-05-04", record.DateAdded.Format("2006-01-02"))
+
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestNewReader(t *testing.T) {
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := NewReader("calibre", strings.NewReader(""))
+		assert.Equal(t, ErrUnknownFormat, err)
+	})
+
+	t.Run("wrong file for format", func(t *testing.T) {
+		_, err := NewReader(FormatLibraryThing, strings.NewReader(goodreadsExport))
+		assert.Error(t, err)
+	})
+}
+
+const calibreOPF = `<?xml version='1.0' encoding='utf-8'?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+    <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+        <dc:identifier opf:scheme="uuid">6f1b1c34-93a4-4bd0-a3ef-6f0f5d3c1a77</dc:identifier>
+        <dc:identifier opf:scheme="ISBN">0-15-602760-7</dc:identifier>
+        <dc:title>Solaris</dc:title>
+        <dc:creator opf:role="aut">Stanisław Lem</dc:creator>
+        <dc:subject>Science Fiction</dc:subject>
+        <dc:language>pol</dc:language>
+        <meta name="calibre:series" content="Lem"/>
+        <meta name="calibre:series_index" content="2.0"/>
+    </metadata>
+</package>`
+
+func TestCalibreReader(t *testing.T) {
+	t.Run("book directories", func(t *testing.T) {
+		reader, err := NewCalibreReader(fstest.MapFS{
+			"Stanislaw Lem/Solaris (12)/metadata.opf": {Data: []byte(calibreOPF)},
+			"Stanislaw Lem/Solaris (12)/Solaris.epub": {Data: []byte("epub")},
+			"Unknown/Broken (13)/metadata.opf":        {Data: []byte("<package>")},
+		})
+		require.NoError(t, err)
+
+		record, err := reader.Next()
+		require.NoError(t, err)
+		assert.Equal(t, 1, record.Line)
+		assert.Equal(t, "6f1b1c34-93a4-4bd0-a3ef-6f0f5d3c1a77", record.SourceID)
+		assert.Equal(t, "Solaris", record.Title)
+		assert.Equal(t, []string{"Stanisław Lem"}, record.Authors)
+		assert.Equal(t, "0156027607", record.ISBN10)
+		assert.Equal(t, "9780156027601", record.ISBN13)
+		assert.Equal(t, "pol", record.Language)
+		assert.Equal(t, []string{"Science Fiction"}, record.Tags)
+		assert.Equal(t, "Lem", record.Series)
+		assert.Equal(t, 2.0, *record.SeriesIndex)
+		assert.Equal(t, []string{"Stanislaw Lem/Solaris (12)/Solaris.epub"}, record.Files)
+
+		_, err = reader.Next()
+		var rowErr *RowError
+		require.ErrorAs(t, err, &rowErr)
+		assert.Equal(t, 2, rowErr.Line)
+		assert.Contains(t, rowErr.Error(), "Unknown/Broken (13)")
+
+		_, err = reader.Next()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("no metadata", func(t *testing.T) {
+		_, err := NewCalibreReader(fstest.MapFS{"Solaris.epub": {Data: []byte("epub")}})
+
+		assert.ErrorIs(t, err, ErrEmptyLibrary)
+	})
+}
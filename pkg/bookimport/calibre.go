@@ -0,0 +1,85 @@
+package bookimport
+
+import (
+	"errors"
+	"fmt"
+	"home-library/pkg/calibre"
+	"home-library/pkg/isbn"
+	"io"
+	"io/fs"
+)
+
+// ErrEmptyLibrary is returned for a tree without a single metadata.opf.
+var ErrEmptyLibrary = errors.New("not a Calibre library: no metadata.opf found")
+
+type calibreReader struct {
+	books []calibreBook
+	next  int
+}
+
+type calibreBook struct {
+	book *calibre.Book
+	dir  string
+	err  error
+}
+
+// NewCalibreReader reads a Calibre library, whether a directory on disk or
+// an uploaded zip archive. Every book directory is a row, numbered in the
+// order of the walk; its Calibre UUID is the source ID. Only the metadata is
+// held in memory, the ebook files are left for the caller to open.
+func NewCalibreReader(fsys fs.FS) (Reader, error) {
+	reader := &calibreReader{}
+	err := calibre.Scan(fsys, func(book *calibre.Book, dir string, err error) error {
+		reader.books = append(reader.books, calibreBook{book: book, dir: dir, err: err})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(reader.books) == 0 {
+		return nil, ErrEmptyLibrary
+	}
+	return reader, nil
+}
+
+func (c *calibreReader) Next() (*Record, error) {
+	if c.next == len(c.books) {
+		return nil, io.EOF
+	}
+	entry := c.books[c.next]
+	c.next++
+	line := c.next
+
+	if entry.err != nil {
+		return nil, &RowError{Line: line, Err: fmt.Errorf("%s: %w", entry.dir, entry.err)}
+	}
+
+	metadata := entry.book.Metadata
+	record := &Record{
+		Line:        line,
+		SourceID:    metadata.UUID,
+		Title:       metadata.Title,
+		Authors:     metadata.Authors,
+		Publisher:   metadata.Publisher,
+		Language:    metadata.Language,
+		Year:        metadata.Year,
+		Tags:        metadata.Subjects,
+		Series:      metadata.Series,
+		Description: metadata.Description,
+	}
+	if len(metadata.ISBN) == 10 {
+		record.ISBN10, record.ISBN13 = metadata.ISBN, isbn.To13(metadata.ISBN)
+	} else {
+		record.ISBN13 = metadata.ISBN
+	}
+	// The index means nothing without a series.
+	if metadata.Series != "" {
+		index := metadata.SeriesIndex
+		record.SeriesIndex = &index
+	}
+	for _, file := range entry.book.Files {
+		record.Files = append(record.Files, file.Path)
+	}
+
+	return record, nil
+}
@@ -0,0 +1,106 @@
+package bookimport
+
+import (
+	"errors"
+	"fmt"
+	"home-library/pkg/isbn"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// exclusiveShelves are Goodreads reading states rather than user tags.
+var exclusiveShelves = map[string]bool{
+	"read":              true,
+	"currently-reading": true,
+	"to-read":           true,
+}
+
+type goodreadsReader struct {
+	t *table
+}
+
+// NewGoodreadsReader reads the CSV produced by Goodreads' "Export Library".
+func NewGoodreadsReader(r io.Reader) (Reader, error) {
+	t, err := newTable(r, ',')
+	if err != nil {
+		return nil, err
+	}
+	if !t.has("title") || !t.has("author") {
+		return nil, errors.New("not a Goodreads export: missing Title or Author column")
+	}
+	return &goodreadsReader{t: t}, nil
+}
+
+func (g *goodreadsReader) Next() (*Record, error) {
+	line, err := g.t.next()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{
+		Line:      line,
+		SourceID:  g.t.get("book id"),
+		Title:     g.t.get("title"),
+		Publisher: g.t.get("publisher"),
+		Review:    g.t.get("my review"),
+		ISBN10:    isbn.Normalize(unquoteISBN(g.t.get("isbn"))),
+		ISBN13:    isbn.Normalize(unquoteISBN(g.t.get("isbn13"))),
+	}
+	if record.Title == "" {
+		return nil, &RowError{Line: line, Err: errors.New("title is empty")}
+	}
+
+	if author := g.t.get("author"); author != "" {
+		record.Authors = append(record.Authors, author)
+	}
+	record.Authors = append(record.Authors, splitList(g.t.get("additional authors"), ",")...)
+
+	for _, shelf := range splitList(g.t.get("bookshelves"), ",") {
+		if !exclusiveShelves[shelf] {
+			record.Tags = append(record.Tags, shelf)
+		}
+	}
+
+	if record.Pages, err = parseInt(g.t.get("number of pages")); err != nil {
+		return nil, &RowError{Line: line, Err: fmt.Errorf("number of pages: %w", err)}
+	}
+
+	year := g.t.get("original publication year")
+	if year == "" {
+		year = g.t.get("year published")
+	}
+	if record.Year, err = parseInt(year); err != nil {
+		return nil, &RowError{Line: line, Err: fmt.Errorf("publication year: %w", err)}
+	}
+
+	// Goodreads writes 0 for books the user has not rated.
+	if rating, err := parseInt(g.t.get("my rating")); err != nil {
+		return nil, &RowError{Line: line, Err: fmt.Errorf("rating: %w", err)}
+	} else {
+		record.Rating = float64(rating)
+	}
+
+	if record.DateRead, err = parseDate(g.t.get("date read"), "2006/01/02", "2006-01-02"); err != nil {
+		return nil, &RowError{Line: line, Err: err}
+	}
+	if record.DateAdded, err = parseDate(g.t.get("date added"), "2006/01/02", "2006-01-02"); err != nil {
+		return nil, &RowError{Line: line, Err: err}
+	}
+
+	return record, nil
+}
+
+// unquoteISBN strips the ="..." wrapper Goodreads uses to stop spreadsheets
+// from turning ISBNs into numbers.
+func unquoteISBN(s string) string {
+	s = strings.TrimPrefix(s, "=")
+	return strings.Trim(s, `"`)
+}
+
+func parseInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
@@ -0,0 +1,100 @@
+package bookimport
+
+import (
+	"errors"
+	"fmt"
+	"home-library/pkg/isbn"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var yearPattern = regexp.MustCompile(`\b(\d{4})\b`)
+
+type libraryThingReader struct {
+	t *table
+}
+
+// NewLibraryThingReader reads the tab-separated export from LibraryThing's
+// "Export your library" page.
+func NewLibraryThingReader(r io.Reader) (Reader, error) {
+	t, err := newTable(r, '\t')
+	if err != nil {
+		return nil, err
+	}
+	if !t.has("title") || !t.has("primary author") {
+		return nil, errors.New("not a LibraryThing export: missing Title or Primary Author column")
+	}
+	return &libraryThingReader{t: t}, nil
+}
+
+func (l *libraryThingReader) Next() (*Record, error) {
+	line, err := l.t.next()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{
+		Line:     line,
+		SourceID: l.t.get("book id"),
+		Title:    l.t.get("title"),
+		Review:   l.t.get("review"),
+		Tags:     splitList(l.t.get("tags"), ","),
+	}
+	if record.Title == "" {
+		return nil, &RowError{Line: line, Err: errors.New("title is empty")}
+	}
+
+	if author := l.t.get("primary author"); author != "" {
+		record.Authors = append(record.Authors, author)
+	}
+	record.Authors = append(record.Authors, splitList(l.t.get("secondary author"), "|")...)
+
+	if languages := splitList(l.t.get("languages"), ","); len(languages) > 0 {
+		record.Language = languages[0]
+	}
+
+	// Publication looks like "Ace (1990), Paperback, 535 pages".
+	if publication := l.t.get("publication"); publication != "" {
+		record.Publisher = strings.TrimSpace(strings.SplitN(publication, "(", 2)[0])
+	}
+
+	for _, value := range splitList(l.t.get("isbns")+","+strings.Trim(l.t.get("isbn"), "[]"), ",") {
+		switch normalized := isbn.Normalize(value); len(normalized) {
+		case 10:
+			if record.ISBN10 == "" {
+				record.ISBN10 = normalized
+			}
+		case 13:
+			if record.ISBN13 == "" {
+				record.ISBN13 = normalized
+			}
+		}
+	}
+
+	if pages := l.t.get("page count"); pages != "" {
+		if record.Pages, err = strconv.Atoi(pages); err != nil {
+			return nil, &RowError{Line: line, Err: fmt.Errorf("page count: %w", err)}
+		}
+	}
+
+	if match := yearPattern.FindString(l.t.get("date")); match != "" {
+		record.Year, _ = strconv.Atoi(match)
+	}
+
+	if rating := l.t.get("rating"); rating != "" {
+		if record.Rating, err = strconv.ParseFloat(rating, 64); err != nil {
+			return nil, &RowError{Line: line, Err: fmt.Errorf("rating: %w", err)}
+		}
+	}
+
+	if record.DateRead, err = parseDate(strings.Trim(l.t.get("date read"), "[]"), "2006-01-02"); err != nil {
+		return nil, &RowError{Line: line, Err: err}
+	}
+	if record.DateAdded, err = parseDate(strings.Trim(l.t.get("entry date"), "[]"), "2006-01-02"); err != nil {
+		return nil, &RowError{Line: line, Err: err}
+	}
+
+	return record, nil
+}
@@ -0,0 +1,110 @@
+// Package calibre walks a Calibre library, where every book lives in its own
+// "Author/Title (id)" directory next to a metadata.opf file.
+//
+// Scan works on any fs.FS, so a library on disk (os.DirFS) and an uploaded
+// zip archive (zip.NewReader) are handled the same way.
+package calibre
+
+import (
+	"home-library/pkg/opf"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const metadataFile = "metadata.opf"
+
+// formats are the book file extensions Calibre stores next to metadata.opf.
+var formats = map[string]bool{
+	"epub": true,
+	"pdf":  true,
+	"fb2":  true,
+	"mobi": true,
+	"azw3": true,
+	"djvu": true,
+	"txt":  true,
+	"rtf":  true,
+	"cbz":  true,
+}
+
+type Book struct {
+	// Dir is the book directory relative to the library root.
+	Dir      string
+	Metadata *opf.Metadata
+	// CoverPath is empty when the book has no cover image.
+	CoverPath string
+	Files     []File
+}
+
+type File struct {
+	Path   string
+	Format string
+	Size   int64
+}
+
+// ScanFunc is called for every book directory. err is non-nil when the book's
+// metadata.opf could not be read or parsed; returning a non-nil error stops
+// the scan.
+type ScanFunc func(book *Book, dir string, err error) error
+
+func Scan(fsys fs.FS, fn ScanFunc) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != metadataFile {
+			return nil
+		}
+
+		dir := path.Dir(p)
+		book, err := readBook(fsys, dir)
+		if err != nil {
+			return fn(nil, dir, err)
+		}
+		return fn(book, dir, nil)
+	})
+}
+
+func readBook(fsys fs.FS, dir string) (*Book, error) {
+	f, err := fsys.Open(path.Join(dir, metadataFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	metadata, err := opf.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+
+	book := &Book{Dir: dir, Metadata: metadata}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		format := strings.TrimPrefix(strings.ToLower(path.Ext(name)), ".")
+		switch {
+		case name == "cover.jpg" || (metadata.CoverHref != "" && name == path.Base(metadata.CoverHref)):
+			book.CoverPath = path.Join(dir, name)
+		case formats[format]:
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			book.Files = append(book.Files, File{
+				Path:   path.Join(dir, name),
+				Format: format,
+				Size:   info.Size(),
+			})
+		}
+	}
+
+	return book, nil
+}
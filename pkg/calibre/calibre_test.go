@@ -0,0 +1,92 @@
+package calibre
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const metadataOPF = `<?xml version='1.0' encoding='utf-8'?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0">
+    <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+        <dc:identifier opf:scheme="uuid">6f1b1c34-93a4-4bd0-a3ef-6f0f5d3c1a77</dc:identifier>
+        <dc:title>Solaris</dc:title>
+        <dc:creator opf:role="aut">Stanisław Lem</dc:creator>
+    </metadata>
+</package>`
+
+func TestScan(t *testing.T) {
+	t.Run("directory library", func(t *testing.T) {
+		library := fstest.MapFS{
+			"metadata.db": {Data: []byte("sqlite")},
+			"Stanislaw Lem/Solaris (12)/metadata.opf": {Data: []byte(metadataOPF)},
+			"Stanislaw Lem/Solaris (12)/cover.jpg":    {Data: []byte("jpeg")},
+			"Stanislaw Lem/Solaris (12)/Solaris.epub": {Data: []byte("epub-bytes")},
+			"Stanislaw Lem/Solaris (12)/Solaris.pdf":  {Data: []byte("pdf")},
+			"Stanislaw Lem/Solaris (12)/notes.xml":    {Data: []byte("<x/>")},
+			"Unknown/Broken (13)/metadata.opf":        {Data: []byte("<package>")},
+			"Unknown/Broken (13)/Broken.fb2":          {Data: []byte("fb2")},
+			"Unknown/Without metadata (14)/Lost.epub": {Data: []byte("epub")},
+		}
+
+		var books []*Book
+		var failed []string
+		err := Scan(library, func(book *Book, dir string, err error) error {
+			if err != nil {
+				failed = append(failed, dir)
+				return nil
+			}
+			books = append(books, book)
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"Unknown/Broken (13)"}, failed)
+		require.Len(t, books, 1)
+
+		book := books[0]
+		assert.Equal(t, "Stanislaw Lem/Solaris (12)", book.Dir)
+		assert.Equal(t, "Solaris", book.Metadata.Title)
+		assert.Equal(t, "6f1b1c34-93a4-4bd0-a3ef-6f0f5d3c1a77", book.Metadata.UUID)
+		assert.Equal(t, "Stanislaw Lem/Solaris (12)/cover.jpg", book.CoverPath)
+		assert.Equal(t, []File{
+			{Path: "Stanislaw Lem/Solaris (12)/Solaris.epub", Format: "epub", Size: 10},
+			{Path: "Stanislaw Lem/Solaris (12)/Solaris.pdf", Format: "pdf", Size: 3},
+		}, book.Files)
+	})
+
+	t.Run("zip archive", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		for name, data := range map[string]string{
+			"Calibre Library/Stanislaw Lem/Solaris (12)/metadata.opf": metadataOPF,
+			"Calibre Library/Stanislaw Lem/Solaris (12)/Solaris.fb2":  "fb2",
+		} {
+			f, err := w.Create(name)
+			require.NoError(t, err)
+			_, err = f.Write([]byte(data))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+
+		var books []*Book
+		err = Scan(archive, func(book *Book, dir string, err error) error {
+			require.NoError(t, err)
+			books = append(books, book)
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Len(t, books, 1)
+		assert.Equal(t, "Solaris", books[0].Metadata.Title)
+		assert.Equal(t, "fb2", books[0].Files[0].Format)
+		assert.Empty(t, books[0].CoverPath)
+	})
+}
@@ -15,12 +15,21 @@ type (
 		Database    DatabaseConfig    `yaml:"database"`
 		SSL         SSLConfig         `yaml:"ssl"`
 		JWT         JWTConfig         `yaml:"jwt"`
+		BlobStore   BlobStoreConfig   `yaml:"blob_store"`
+		Jobs        JobsConfig        `yaml:"jobs"`
 	}
 
 	ApplicationConfig struct {
 		Name            string `yaml:"name"`
 		TimeZone        string `yaml:"time_zone"`
 		ShutdownTimeout uint   `yaml:"shutdown_timeout"`
+		// PhoneRegion is the country, such as "RU", of phone numbers given
+		// without the international prefix.
+		PhoneRegion string `yaml:"phone_region" env-default:"RU"`
+		// PublicURL is the address the app is reached at, such as
+		// "https://library.example.org". Printed labels link to copies
+		// under it; without it they carry no QR code.
+		PublicURL string `yaml:"public_url"`
 	}
 
 	HTTPServerConfig struct {
@@ -46,6 +55,35 @@ type (
 	JWTConfig struct {
 		Secret string `yaml:"secret"`
 	}
+
+	BlobStoreConfig struct {
+		// Driver is "local" or "s3". Without a blob_store section objects are
+		// kept on the local disk.
+		Driver string          `yaml:"driver" env-default:"local"`
+		Local  LocalBlobConfig `yaml:"local"`
+		S3     S3BlobConfig    `yaml:"s3"`
+	}
+
+	LocalBlobConfig struct {
+		Root string `yaml:"root" env-default:"data/blobs"`
+	}
+
+	S3BlobConfig struct {
+		Endpoint  string `yaml:"endpoint"`
+		Region    string `yaml:"region"`
+		Bucket    string `yaml:"bucket"`
+		AccessKey string `yaml:"access_key"`
+		SecretKey string `yaml:"secret_key"`
+		UseSSL    bool   `yaml:"use_ssl"`
+	}
+
+	JobsConfig struct {
+		// Concurrency is the number of workers running background jobs.
+		Concurrency int `yaml:"concurrency"`
+		// PollInterval is how many seconds an idle worker waits before
+		// looking for new jobs.
+		PollInterval uint `yaml:"poll_interval"`
+	}
 )
 
 var once sync.Once
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobStoreDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("application:\n  name: home-library\n"), 0o600))
+
+	var cfg Config
+	require.NoError(t, cleanenv.ReadConfig(path, &cfg))
+
+	assert.Equal(t, "local", cfg.BlobStore.Driver)
+	assert.Equal(t, "data/blobs", cfg.BlobStore.Local.Root)
+}
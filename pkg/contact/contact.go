@@ -0,0 +1,43 @@
+// Package contact normalizes the email addresses and phone numbers that
+// identify users, so that one address or number written in different ways
+// always leads to the same account.
+package contact
+
+import (
+	"home-library/pkg/errors"
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+	"golang.org/x/net/idna"
+)
+
+// NormalizeEmail trims and lower-cases the address and converts an
+// internationalized domain to its ASCII (punycode) form, so "Foo@Пример.рф"
+// becomes "foo@xn--e1afmkfd.xn--p1ai".
+func NormalizeEmail(s string) (string, error) {
+	s = strings.TrimSpace(s)
+
+	at := strings.LastIndexByte(s, '@')
+	if at <= 0 || at == len(s)-1 {
+		return "", errors.ErrInvalidEmail
+	}
+
+	domain, err := idna.Lookup.ToASCII(s[at+1:])
+	if err != nil {
+		return "", errors.ErrInvalidEmail
+	}
+
+	return strings.ToLower(s[:at]) + "@" + strings.ToLower(domain), nil
+}
+
+// NormalizePhone parses a phone number and formats it as E.164. Numbers
+// written without the international prefix are read as numbers of region, an
+// ISO 3166 code such as "RU".
+func NormalizePhone(s string, region string) (string, error) {
+	number, err := phonenumbers.Parse(s, strings.ToUpper(region))
+	if err != nil || !phonenumbers.IsValidNumber(number) {
+		return "", errors.ErrInvalidPhoneNumber
+	}
+
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
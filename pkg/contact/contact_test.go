@@ -0,0 +1,63 @@
+package contact
+
+import (
+	"home-library/pkg/errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		err   error
+	}{
+		{"already normalized", "evgeny@example.com", "evgeny@example.com", nil},
+		{"case and spaces", "  Evgeny@Example.COM ", "evgeny@example.com", nil},
+		{"internationalized domain", "Книги@Пример.рф", "книги@xn--e1afmkfd.xn--p1ai", nil},
+		{"quoted local part with at sign", `"a@b"@example.com`, `"a@b"@example.com`, nil},
+		{"no at sign", "evgeny.example.com", "", errors.ErrInvalidEmail},
+		{"no local part", "@example.com", "", errors.ErrInvalidEmail},
+		{"no domain", "evgeny@", "", errors.ErrInvalidEmail},
+		{"invalid domain", "evgeny@exa_mple.com", "", errors.ErrInvalidEmail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeEmail(tt.input)
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		region string
+		want   string
+		err    error
+	}{
+		{"already E.164", "+79001234567", "RU", "+79001234567", nil},
+		{"formatted", "+7 (900) 123-45-67", "RU", "+79001234567", nil},
+		{"national prefix", "8 900 123-45-67", "RU", "+79001234567", nil},
+		{"foreign number ignores region", "+44 20 7946 0958", "RU", "+442079460958", nil},
+		{"region in lower case", "020 7946 0958", "gb", "+442079460958", nil},
+		{"too short", "+7 900 12", "RU", "", errors.ErrInvalidPhoneNumber},
+		{"not a number", "call me", "RU", "", errors.ErrInvalidPhoneNumber},
+		{"empty", "", "RU", "", errors.ErrInvalidPhoneNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePhone(tt.input, tt.region)
+
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
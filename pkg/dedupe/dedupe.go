@@ -0,0 +1,415 @@
+// Package dedupe finds likely duplicates among catalog records, such as the
+// same book imported twice from different sources or an author spelled in
+// Cyrillic in one place and in Latin in another. It only scores candidate
+// pairs; deciding whether to merge them is left to a person.
+package dedupe
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"home-library/pkg/isbn"
+)
+
+// DefaultThreshold is the score from which a pair is worth showing.
+const DefaultThreshold = 0.85
+
+// maxBlock skips blocking keys shared by so many records that they say
+// nothing about them, like "the" in titles.
+const maxBlock = 500
+
+type Reason string
+
+const (
+	ReasonISBN    Reason = "isbn"
+	ReasonTitle   Reason = "title"
+	ReasonAuthors Reason = "authors"
+	ReasonName    Reason = "name"
+)
+
+type Book struct {
+	ID      string
+	Title   string
+	Authors []string
+	// ISBNs may be in either form and with hyphens; invalid ones are ignored.
+	ISBNs []string
+}
+
+type Author struct {
+	ID   string
+	Name string
+}
+
+// Pair is a candidate duplicate. A is the record that came first in the
+// input. Score is between 0 and 1.
+type Pair struct {
+	A       string
+	B       string
+	Score   float64
+	Reasons []Reason
+}
+
+// Books returns the pairs of books scoring at least threshold, best first.
+// Books sharing an ISBN, once converted to ISBN-13, score 1. Otherwise the
+// score combines the similarity of the titles and of the authors; books
+// whose titles carry different numbers, like two volumes of a series, are
+// never paired on title alone.
+func Books(books []Book, threshold float64) []Pair {
+	prepared := make([]preparedBook, len(books))
+	blocks := make(map[string][]int)
+	for i, book := range books {
+		p := prepareBook(book)
+		prepared[i] = p
+		for _, key := range blockingKeys(p) {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+
+	result := make([]Pair, 0)
+	for _, candidate := range candidates(blocks) {
+		a, b := prepared[candidate[0]], prepared[candidate[1]]
+		score, reasons := scoreBooks(a, b)
+		if score >= threshold {
+			result = append(result, Pair{A: books[candidate[0]].ID, B: books[candidate[1]].ID, Score: score, Reasons: reasons})
+		}
+	}
+
+	sortPairs(result)
+	return result
+}
+
+// Index holds books to match single records against, such as the rows of an
+// import against a catalog, without scoring the books among themselves.
+type Index struct {
+	books    []Book
+	prepared []preparedBook
+	blocks   map[string][]int
+}
+
+func NewIndex(books []Book) *Index {
+	index := &Index{blocks: make(map[string][]int)}
+	for _, book := range books {
+		index.Add(book)
+	}
+	return index
+}
+
+// Add indexes one more book, so that later records can match it.
+func (x *Index) Add(book Book) {
+	i := len(x.books)
+	p := prepareBook(book)
+	x.books = append(x.books, book)
+	x.prepared = append(x.prepared, p)
+	for _, key := range blockingKeys(p) {
+		x.blocks[key] = append(x.blocks[key], i)
+	}
+}
+
+// Match returns the indexed book most similar to book, as the A side of a
+// pair with book as B, if it scores at least threshold.
+func (x *Index) Match(book Book, threshold float64) (Pair, bool) {
+	p := prepareBook(book)
+	best, found := Pair{}, false
+	seen := make(map[int]bool)
+	for _, key := range blockingKeys(p) {
+		block := x.blocks[key]
+		if len(block) > maxBlock {
+			continue
+		}
+		for _, i := range block {
+			if seen[i] {
+				continue
+			}
+			seen[i] = true
+
+			score, reasons := scoreBooks(x.prepared[i], p)
+			if score >= threshold && (!found || score > best.Score) {
+				best, found = Pair{A: x.books[i].ID, B: book.ID, Score: score, Reasons: reasons}, true
+			}
+		}
+	}
+	return best, found
+}
+
+// Authors returns the pairs of authors scoring at least threshold, best
+// first. Names match regardless of word order, script and transliteration
+// scheme, and an initial matches any name starting with it.
+func Authors(authors []Author, threshold float64) []Pair {
+	names := make([][]string, len(authors))
+	blocks := make(map[string][]int)
+	for i, author := range authors {
+		names[i] = strings.Fields(skeleton(author.Name))
+		for _, word := range names[i] {
+			if !isInitial(word) {
+				blocks[word] = append(blocks[word], i)
+			}
+		}
+	}
+
+	result := make([]Pair, 0)
+	for _, candidate := range candidates(blocks) {
+		score := nameSimilarity(names[candidate[0]], names[candidate[1]])
+		if score >= threshold {
+			result = append(result, Pair{A: authors[candidate[0]].ID, B: authors[candidate[1]].ID, Score: score, Reasons: []Reason{ReasonName}})
+		}
+	}
+
+	sortPairs(result)
+	return result
+}
+
+type preparedBook struct {
+	isbns   []string
+	title   string
+	main    string
+	words   []string
+	numbers string
+	authors [][]string
+}
+
+func prepareBook(book Book) preparedBook {
+	p := preparedBook{
+		title: skeleton(book.Title),
+		main:  skeleton(mainTitle(book.Title)),
+	}
+
+	for _, code := range book.ISBNs {
+		if code = isbn.To13(code); code != "" {
+			p.isbns = append(p.isbns, code)
+		}
+	}
+
+	var numbers []string
+	for _, word := range strings.Fields(p.title) {
+		if strings.Trim(word, "0123456789") == "" {
+			numbers = append(numbers, word)
+		} else if len(word) >= 3 {
+			p.words = append(p.words, word)
+		}
+	}
+	sort.Strings(numbers)
+	p.numbers = strings.Join(numbers, " ")
+	// Very short titles have no long words, so they block on the whole title.
+	if len(p.words) == 0 && p.title != "" {
+		p.words = []string{p.title}
+	}
+
+	for _, author := range book.Authors {
+		if name := strings.Fields(skeleton(author)); len(name) > 0 {
+			p.authors = append(p.authors, name)
+		}
+	}
+
+	return p
+}
+
+// blockingKeys are the ISBNs and title words of a book: only books sharing
+// one of them are compared.
+func blockingKeys(p preparedBook) []string {
+	keys := make([]string, 0, len(p.isbns)+len(p.words))
+	for _, code := range p.isbns {
+		keys = append(keys, "isbn:"+code)
+	}
+	for _, word := range p.words {
+		keys = append(keys, "title:"+word)
+	}
+	return keys
+}
+
+// mainTitle drops a subtitle after a colon or in parentheses.
+func mainTitle(title string) string {
+	if i := strings.IndexAny(title, ":("); i > 0 {
+		return title[:i]
+	}
+	return title
+}
+
+func scoreBooks(a, b preparedBook) (float64, []Reason) {
+	for _, x := range a.isbns {
+		for _, y := range b.isbns {
+			if x == y {
+				return 1, []Reason{ReasonISBN}
+			}
+		}
+	}
+
+	if a.numbers != b.numbers || a.title == "" || b.title == "" {
+		return 0, nil
+	}
+
+	title := max(dice(a.title, b.title), dice(a.main, b.main))
+	var reasons []Reason
+	if title >= DefaultThreshold {
+		reasons = append(reasons, ReasonTitle)
+	}
+
+	// Without authors on both sides the title alone decides, but a title
+	// is weaker evidence than a title and an author together.
+	if len(a.authors) == 0 || len(b.authors) == 0 {
+		return title * 0.9, reasons
+	}
+
+	authors := authorsSimilarity(a.authors, b.authors)
+	if authors >= DefaultThreshold {
+		reasons = append(reasons, ReasonAuthors)
+	}
+
+	return 0.7*title + 0.3*authors, reasons
+}
+
+// authorsSimilarity matches every author of the shorter list with the most
+// similar one of the other list and averages the results, so a book listing
+// one of two co-authors still matches.
+func authorsSimilarity(a, b [][]string) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	total := 0.0
+	for _, x := range a {
+		best := 0.0
+		for _, y := range b {
+			best = max(best, nameSimilarity(x, y))
+		}
+		total += best
+	}
+
+	return total / float64(len(a))
+}
+
+// nameSimilarity pairs each word of the shorter name with the most similar
+// unused word of the other one. A name with fewer words, like a surname
+// alone, scores a little lower even when all its words match.
+func nameSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	used := make([]bool, len(b))
+	total, initialsOnly := 0.0, true
+	for _, x := range a {
+		best, bestIndex := 0.0, -1
+		for j, y := range b {
+			if used[j] {
+				continue
+			}
+			if score := wordSimilarity(x, y); score > best {
+				best, bestIndex = score, j
+			}
+		}
+		if bestIndex >= 0 {
+			used[bestIndex] = true
+			if !isInitial(x) && !isInitial(b[bestIndex]) {
+				initialsOnly = false
+			}
+		}
+		total += best
+	}
+
+	// Initials alone match too easily to mean anything.
+	if initialsOnly {
+		return 0
+	}
+
+	score := total / float64(len(a))
+	if len(a) != len(b) {
+		score *= 0.9
+	}
+	return score
+}
+
+func wordSimilarity(a, b string) float64 {
+	switch {
+	case a == b:
+		return 1
+	case isInitial(a) || isInitial(b):
+		first, _ := utf8.DecodeRuneInString(a)
+		if strings.HasPrefix(b, string(first)) {
+			return 0.9
+		}
+		return 0
+	default:
+		return dice(a, b)
+	}
+}
+
+func isInitial(word string) bool {
+	return utf8.RuneCountInString(word) == 1
+}
+
+// dice is the Sørensen–Dice coefficient of the trigrams of two skeletons.
+func dice(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	x, y := trigrams(a), trigrams(b)
+	if len(x) == 0 || len(y) == 0 {
+		return 0
+	}
+
+	common := 0
+	for gram, n := range x {
+		common += min(n, y[gram])
+	}
+
+	return 2 * float64(common) / float64(count(x)+count(y))
+}
+
+func trigrams(s string) map[string]int {
+	grams := make(map[string]int)
+	for _, word := range strings.Fields(s) {
+		padded := "  " + word + " "
+		for i := 0; i+3 <= len(padded); i++ {
+			grams[padded[i:i+3]]++
+		}
+	}
+	return grams
+}
+
+func count(grams map[string]int) int {
+	n := 0
+	for _, c := range grams {
+		n += c
+	}
+	return n
+}
+
+// candidates returns every pair of records sharing a blocking key, once,
+// with the earlier record first.
+func candidates(blocks map[string][]int) [][2]int {
+	seen := make(map[[2]int]bool)
+	result := make([][2]int, 0)
+	for _, block := range blocks {
+		if len(block) > maxBlock {
+			continue
+		}
+		for i := 0; i < len(block); i++ {
+			for j := i + 1; j < len(block); j++ {
+				pair := [2]int{block[i], block[j]}
+				if pair[0] == pair[1] || seen[pair] {
+					continue
+				}
+				seen[pair] = true
+				result = append(result, pair)
+			}
+		}
+	}
+	return result
+}
+
+func sortPairs(pairs []Pair) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Score != pairs[j].Score {
+			return pairs[i].Score > pairs[j].Score
+		}
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+}
@@ -0,0 +1,160 @@
+package dedupe
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSkeleton(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"Стругацкий Аркадий", "Arkady Strugatsky"},
+		{"Strugatskiy", "Strugackij"},
+		{"Толстой", "Tolstoi"},
+		{"Станислав Лем", "Stanisław Lem"},
+		{"Пикник на обочине!", "  piknik   na obochine "},
+		{"Ёжик в тумане", "Ezhik v tumane"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a, func(t *testing.T) {
+			a := strings.Fields(skeleton(test.a))
+			b := strings.Fields(skeleton(test.b))
+			sort.Strings(a)
+			sort.Strings(b)
+			assert.Equal(t, a, b)
+		})
+	}
+}
+
+func TestBooks(t *testing.T) {
+	books := []Book{
+		{ID: "1", Title: "Пикник на обочине", Authors: []string{"Аркадий Стругацкий", "Борис Стругацкий"}, ISBNs: []string{"5-17-098765-X"}},
+		{ID: "2", Title: "Roadside Picnic", Authors: []string{"Arkady Strugatsky"}, ISBNs: []string{"978-5-17-098765-8"}},
+		{ID: "3", Title: "Piknik na obochine", Authors: []string{"Strugatsky, Boris"}},
+		{ID: "4", Title: "Dune", Authors: []string{"Frank Herbert"}},
+		{ID: "5", Title: "Dune Messiah", Authors: []string{"Frank Herbert"}},
+		{ID: "6", Title: "Harry Potter 1", Authors: []string{"J. K. Rowling"}},
+		{ID: "7", Title: "Harry Potter 2", Authors: []string{"J. K. Rowling"}},
+		{ID: "8", Title: "Solaris: A Novel", Authors: []string{"Stanisław Lem"}},
+		{ID: "9", Title: "Solaris", Authors: []string{"Lem Stanislaw"}},
+		{ID: "10", Title: "Solaris"},
+	}
+
+	result := Books(books, DefaultThreshold)
+
+	pairs := make(map[[2]string]Pair)
+	for _, pair := range result {
+		pairs[[2]string{pair.A, pair.B}] = pair
+	}
+
+	t.Run("same isbn in both forms", func(t *testing.T) {
+		pair, ok := pairs[[2]string{"1", "2"}]
+		require.True(t, ok)
+		assert.Equal(t, 1.0, pair.Score)
+		assert.Equal(t, []Reason{ReasonISBN}, pair.Reasons)
+	})
+
+	t.Run("transliterated title and co-author", func(t *testing.T) {
+		pair, ok := pairs[[2]string{"1", "3"}]
+		require.True(t, ok)
+		assert.Equal(t, []Reason{ReasonTitle, ReasonAuthors}, pair.Reasons)
+	})
+
+	t.Run("subtitle", func(t *testing.T) {
+		_, ok := pairs[[2]string{"8", "9"}]
+		assert.True(t, ok)
+	})
+
+	t.Run("title alone", func(t *testing.T) {
+		pair, ok := pairs[[2]string{"9", "10"}]
+		require.True(t, ok)
+		assert.InDelta(t, 0.9, pair.Score, 0.001)
+	})
+
+	t.Run("different books", func(t *testing.T) {
+		for _, ids := range [][2]string{{"4", "5"}, {"6", "7"}, {"2", "3"}} {
+			_, ok := pairs[ids]
+			assert.False(t, ok, ids)
+		}
+	})
+
+	t.Run("best first", func(t *testing.T) {
+		for i := 1; i < len(result); i++ {
+			assert.GreaterOrEqual(t, result[i-1].Score, result[i].Score)
+		}
+	})
+}
+
+func TestIndex(t *testing.T) {
+	index := NewIndex([]Book{
+		{ID: "picnic", Title: "Пикник на обочине", Authors: []string{"Аркадий Стругацкий"}, ISBNs: []string{"9785170987658"}},
+		{ID: "dune-1", Title: "Dune", Authors: []string{"Frank Herbert"}},
+	})
+
+	t.Run("isbn first", func(t *testing.T) {
+		pair, ok := index.Match(Book{ID: "row", Title: "Roadside Picnic", ISBNs: []string{"5-17-098765-X"}}, DefaultThreshold)
+
+		require.True(t, ok)
+		assert.Equal(t, Pair{A: "picnic", B: "row", Score: 1, Reasons: []Reason{ReasonISBN}}, pair)
+	})
+
+	t.Run("then title and authors", func(t *testing.T) {
+		pair, ok := index.Match(Book{ID: "row", Title: "Piknik na obochine", Authors: []string{"Strugatsky, Arkady"}}, DefaultThreshold)
+
+		require.True(t, ok)
+		assert.Equal(t, "picnic", pair.A)
+		assert.Contains(t, pair.Reasons, ReasonTitle)
+	})
+
+	t.Run("added books match later records", func(t *testing.T) {
+		_, ok := index.Match(Book{ID: "row", Title: "Dune Messiah 2", Authors: []string{"Frank Herbert"}}, DefaultThreshold)
+		require.False(t, ok)
+
+		index.Add(Book{ID: "dune-2", Title: "Dune Messiah 2", Authors: []string{"Frank Herbert"}})
+		pair, ok := index.Match(Book{ID: "row", Title: "Dune Messiah 2", Authors: []string{"Herbert, Frank"}}, DefaultThreshold)
+
+		require.True(t, ok)
+		assert.Equal(t, "dune-2", pair.A)
+	})
+}
+
+func TestAuthors(t *testing.T) {
+	authors := []Author{
+		{ID: "1", Name: "Стругацкий Аркадий"},
+		{ID: "2", Name: "Arkady Strugatsky"},
+		{ID: "3", Name: "A. Strugatsky"},
+		{ID: "4", Name: "Борис Стругацкий"},
+		{ID: "5", Name: "Ursula K. Le Guin"},
+		{ID: "6", Name: "Le Guin, Ursula"},
+		{ID: "7", Name: "J. R. R. Tolkien"},
+		{ID: "8", Name: "J. K. Rowling"},
+	}
+
+	result := Authors(authors, DefaultThreshold)
+
+	found := make(map[[2]string]float64)
+	for _, pair := range result {
+		assert.Equal(t, []Reason{ReasonName}, pair.Reasons)
+		found[[2]string{pair.A, pair.B}] = pair.Score
+	}
+
+	assert.Equal(t, 1.0, found[[2]string{"1", "2"}])
+	assert.Contains(t, found, [2]string{"1", "3"})
+	assert.Contains(t, found, [2]string{"2", "3"})
+	assert.Contains(t, found, [2]string{"5", "6"})
+	assert.NotContains(t, found, [2]string{"1", "4"})
+	assert.NotContains(t, found, [2]string{"3", "4"})
+	assert.NotContains(t, found, [2]string{"7", "8"})
+}
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Zero(t, nameSimilarity([]string{"j", "k"}, []string{"j", "r", "r"}))
+	assert.Zero(t, nameSimilarity(nil, []string{"lem"}))
+	assert.InDelta(t, 0.9, nameSimilarity([]string{"lem"}, []string{"stanislav", "lem"}), 0.001)
+}
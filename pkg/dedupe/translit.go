@@ -0,0 +1,83 @@
+package dedupe
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// cyrillic maps Russian letters to a plain Latin transliteration. The exact
+// scheme matters little, because skeleton folds the differences between the
+// common ones afterwards.
+var cyrillic = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+// latinFolds bring the spellings of transliteration schemes together, so
+// that "Strugatskiy", "Strugatsky" and "Strugackij" meet. They are applied
+// in order.
+var latinFolds = strings.NewReplacer(
+	"shch", "sh", "sch", "sh",
+	"iy", "y", "ij", "y", "yj", "y", "ii", "y",
+	"ai", "ay", "ei", "ey", "oi", "oy",
+	"ya", "ia", "ja", "ia",
+	"yu", "iu", "ju", "iu",
+	"kh", "h",
+	"tz", "c", "ts", "c",
+	"ph", "f", "w", "v", "x", "ks", "q", "k",
+)
+
+// skeleton reduces a string to lowercase Latin letters and digits separated
+// by single spaces, transliterating Cyrillic and dropping diacritics, with
+// the usual transliteration variants folded together.
+func skeleton(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		// Cyrillic goes first, because decomposing would turn й into и.
+		if latin, ok := cyrillic[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+
+		for _, d := range norm.NFD.String(string(r)) {
+			switch {
+			case unicode.Is(unicode.Mn, d):
+			case d == 'ł':
+				b.WriteByte('l')
+			case d == 'ø':
+				b.WriteByte('o')
+			case d == 'ß':
+				b.WriteString("ss")
+			case unicode.IsLetter(d) || unicode.IsDigit(d):
+				b.WriteRune(d)
+			default:
+				b.WriteByte(' ')
+			}
+		}
+	}
+
+	words := strings.Fields(b.String())
+	for i, word := range words {
+		words[i] = collapse(latinFolds.Replace(word))
+	}
+	return strings.Join(words, " ")
+}
+
+// collapse removes doubled letters, as in "Tolstoy" and "Tolsstoy".
+func collapse(word string) string {
+	var b strings.Builder
+	var previous rune
+	for _, r := range word {
+		if r != previous {
+			b.WriteRune(r)
+		}
+		previous = r
+	}
+	return b.String()
+}
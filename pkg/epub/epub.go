@@ -0,0 +1,102 @@
+// Package epub reads the metadata and cover image of an EPUB file.
+package epub
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"home-library/pkg/opf"
+	"io"
+	"net/url"
+	"path"
+)
+
+// MaxCoverSize bounds the cover image read into memory.
+const MaxCoverSize = 10 << 20
+
+var ErrNotEPUB = errors.New("epub: not an EPUB file")
+
+type Book struct {
+	Metadata *opf.Metadata
+	// Cover holds the raw cover image, nil when the book has none.
+	Cover []byte
+}
+
+type container struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// Open reads the package document the container points at. Books with a
+// broken or missing cover are still returned, just without Cover.
+func Open(r io.ReaderAt, size int64) (*Book, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrNotEPUB
+	}
+
+	rootfile, err := packagePath(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := zr.Open(rootfile)
+	if err != nil {
+		return nil, fmt.Errorf("epub: %w", err)
+	}
+	defer f.Close()
+
+	metadata, err := opf.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+
+	book := &Book{Metadata: metadata}
+	if metadata.CoverHref != "" {
+		book.Cover = readCover(zr, path.Join(path.Dir(rootfile), metadata.CoverHref))
+	}
+
+	return book, nil
+}
+
+func packagePath(zr *zip.Reader) (string, error) {
+	f, err := zr.Open("META-INF/container.xml")
+	if err != nil {
+		return "", ErrNotEPUB
+	}
+	defer f.Close()
+
+	var c container
+	if err := xml.NewDecoder(f).Decode(&c); err != nil {
+		return "", ErrNotEPUB
+	}
+
+	for _, rootfile := range c.Rootfiles {
+		if rootfile.MediaType == "application/oebps-package+xml" && rootfile.FullPath != "" {
+			return rootfile.FullPath, nil
+		}
+	}
+	return "", ErrNotEPUB
+}
+
+func readCover(zr *zip.Reader, name string) []byte {
+	// Hrefs are URL encoded, "cover%20image.jpg" is stored with a space.
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, MaxCoverSize+1))
+	if err != nil || len(data) > MaxCoverSize {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,95 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const containerXML = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+const packageOPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="id">urn:isbn:9785170987658</dc:identifier>
+    <dc:title>Пикник на обочине</dc:title>
+    <dc:creator>Аркадий Стругацкий</dc:creator>
+    <dc:creator>Борис Стругацкий</dc:creator>
+    <dc:language>ru</dc:language>
+  </metadata>
+  <manifest>
+    <item id="cover" href="images/cover%20art.jpg" media-type="image/jpeg" properties="cover-image"/>
+  </manifest>
+</package>`
+
+func buildEPUB(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"mimetype", "META-INF/container.xml", "OEBPS/content.opf", "OEBPS/images/cover art.jpg"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		f, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("metadata and cover", func(t *testing.T) {
+		data := buildEPUB(t, map[string]string{
+			"mimetype":                   "application/epub+zip",
+			"META-INF/container.xml":     containerXML,
+			"OEBPS/content.opf":          packageOPF,
+			"OEBPS/images/cover art.jpg": "jpeg bytes",
+		})
+
+		book, err := Open(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Пикник на обочине", book.Metadata.Title)
+		assert.Equal(t, []string{"Аркадий Стругацкий", "Борис Стругацкий"}, book.Metadata.Authors)
+		assert.Equal(t, "ru", book.Metadata.Language)
+		assert.Equal(t, "9785170987658", book.Metadata.ISBN)
+		assert.Equal(t, []byte("jpeg bytes"), book.Cover)
+	})
+
+	t.Run("missing cover file", func(t *testing.T) {
+		data := buildEPUB(t, map[string]string{
+			"META-INF/container.xml": containerXML,
+			"OEBPS/content.opf":      packageOPF,
+		})
+
+		book, err := Open(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Пикник на обочине", book.Metadata.Title)
+		assert.Nil(t, book.Cover)
+	})
+
+	t.Run("zip without container", func(t *testing.T) {
+		data := buildEPUB(t, map[string]string{"OEBPS/content.opf": packageOPF})
+
+		_, err := Open(bytes.NewReader(data), int64(len(data)))
+		assert.Equal(t, ErrNotEPUB, err)
+	})
+
+	t.Run("not a zip", func(t *testing.T) {
+		data := []byte("%PDF-1.7")
+
+		_, err := Open(bytes.NewReader(data), int64(len(data)))
+		assert.Equal(t, ErrNotEPUB, err)
+	})
+}
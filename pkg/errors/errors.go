@@ -6,4 +6,88 @@ var (
 	ErrUserAlreadyExist   = errors.New("user already exists")
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrUserInactive       = errors.New("user account is inactive")
+	ErrInvalidEmail       = errors.New("invalid email address")
+	ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+	ErrWishlistItemNotFound = errors.New("wishlist item not found")
+	ErrWishlistItemReserved = errors.New("wishlist item is already reserved")
+	ErrWishlistOwnItem      = errors.New("action is not available for own wishlist")
+	ErrReservationNotFound  = errors.New("reservation not found")
+
+	ErrHouseholdNotFound       = errors.New("user is not a member of a household")
+	ErrAlreadyInHousehold      = errors.New("user is already a member of a household")
+	ErrHouseholdForbidden      = errors.New("household role does not allow this action")
+	ErrHouseholdMemberNotFound = errors.New("household member not found")
+	ErrOwnerMustTransfer       = errors.New("household owner must transfer ownership first")
+	ErrInvitationNotFound      = errors.New("invitation not found")
+	ErrInvitationExpired       = errors.New("invitation is expired or already used")
+
+	ErrBookNotFound     = errors.New("book not found")
+	ErrCopyNotFound     = errors.New("copy not found")
+	ErrLocationNotFound = errors.New("location not found")
+	ErrLocationExists   = errors.New("location with this name already exists")
+
+	ErrFriendshipNotFound = errors.New("friendship not found")
+	ErrFriendshipExists   = errors.New("households are already friends or asked to be")
+	ErrFriendOwnHousehold = errors.New("household cannot befriend itself")
+	ErrFriendNotFound     = errors.New("no household has a member with this email")
+
+	ErrLoanNotFound          = errors.New("loan not found")
+	ErrCopyOnLoan            = errors.New("copy is already on loan")
+	ErrBorrowRequestNotFound = errors.New("borrow request not found")
+	ErrBorrowRequestExists   = errors.New("copy has already been requested")
+	ErrBorrowOwnCopy         = errors.New("household cannot borrow its own copy")
+	ErrBorrowTransition      = errors.New("borrow request cannot change to this status")
+
+	ErrNotificationNotFound = errors.New("notification not found")
+
+	ErrReadingNotFound = errors.New("reading not found")
+	ErrReadingDates    = errors.New("reading cannot finish before it starts")
+	ErrReviewNotFound  = errors.New("review not found")
+
+	ErrGoalNotFound = errors.New("reading goal not found")
+
+	ErrImportNotFound  = errors.New("book import not found")
+	ErrImportMalformed = errors.New("import file is not an export of the given format")
+	ErrImportTooLarge  = errors.New("import file is too large")
+
+	ErrArchiveInvalid    = errors.New("archive is malformed")
+	ErrArchiveVersion    = errors.New("unsupported archive schema version")
+	ErrHouseholdNotEmpty = errors.New("household library is not empty")
+
+	ErrCoverNotFound    = errors.New("cover not found")
+	ErrCoverTooLarge    = errors.New("cover image is too large")
+	ErrCoverUnsupported = errors.New("cover image format is not supported")
+
+	ErrEbookNotFound    = errors.New("ebook file not found")
+	ErrEbookExists      = errors.New("ebook file already exists")
+	ErrEbookTooLarge    = errors.New("ebook file is too large")
+	ErrEbookUnsupported = errors.New("ebook file format is not supported")
+
+	ErrQuoteNotFound   = errors.New("quote not found")
+	ErrQuotesTooLarge  = errors.New("highlights file is too large")
+	ErrQuotesMalformed = errors.New("highlights file is malformed")
+
+	ErrLabelSheetUnknown = errors.New("unknown label sheet")
+	ErrLabelSheetInvalid = errors.New("labels do not fit on the sheet")
+
+	ErrScanUnrecognized    = errors.New("scanned code is not recognized")
+	ErrScanNotFound        = errors.New("scanned code does not match anything")
+	ErrScanSessionNotFound = errors.New("scan session not found or expired")
+	ErrScanNoShelf         = errors.New("scan a shelf before moving copies")
+	ErrScanNotOnLoan       = errors.New("scanned copy is not on loan")
+
+	ErrAuditNotFound   = errors.New("audit not found")
+	ErrAuditOpen       = errors.New("location already has an open audit")
+	ErrAuditClosed     = errors.New("audit is closed")
+	ErrAuditCorrection = errors.New("copy is not misplaced or missing in the audit")
+
+	ErrAuthorNotFound    = errors.New("no book has this author")
+	ErrDuplicateNotFound = errors.New("duplicate candidate not found")
+	ErrMergeSelf         = errors.New("a record cannot be merged into itself")
+
+	ErrJobNotFound     = errors.New("job not found")
+	ErrJobNotRetryable = errors.New("only dead jobs can be retried")
+
+	ErrWebhookNotFound = errors.New("webhook not found")
 )
@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"home-library/pkg/jobs"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// relayBatch is how many events one relay transaction moves.
+const relayBatch = 100
+
+// Handler reacts to an event. It may see the same event more than once and
+// must tolerate that.
+type Handler func(ctx context.Context, event *Event) error
+
+type subscriber struct {
+	name  string
+	types map[Type]bool
+}
+
+func (s subscriber) wants(typ Type) bool {
+	return len(s.types) == 0 || s.types[typ]
+}
+
+// Dispatcher delivers recorded events through the job queue: relaying an
+// event enqueues a job per interested subscriber, so every subscriber
+// retries on its own and a failing one does not hold the others back.
+type Dispatcher struct {
+	db          *transaction.DB
+	tx          transaction.Transactor
+	queue       *jobs.Queue
+	pool        *jobs.Pool
+	subscribers []subscriber
+}
+
+func NewDispatcher(db *sqlx.DB, queue *jobs.Queue, pool *jobs.Pool) *Dispatcher {
+	return &Dispatcher{db: transaction.Wrap(db), tx: transaction.NewManager(db), queue: queue, pool: pool}
+}
+
+// Subscribe registers a handler for the given event types, or for all of
+// them when none are given. The name identifies the subscriber in the job
+// queue and must be unique. Subscribers must be registered before the
+// worker pool starts; events relayed earlier do not reach them.
+func (d *Dispatcher) Subscribe(name string, handler Handler, types ...Type) {
+	s := subscriber{name: name, types: make(map[Type]bool, len(types))}
+	for _, typ := range types {
+		s.types[typ] = true
+	}
+	d.subscribers = append(d.subscribers, s)
+
+	d.pool.Handle(jobKind(name), func(ctx context.Context, job *jobs.Job) error {
+		var event Event
+		if err := job.Decode(&event); err != nil {
+			return err
+		}
+		return handler(ctx, &event)
+	})
+}
+
+// Relay hands the recorded events over to the subscribers, in batches until
+// the outbox is empty. Several processes may relay at once.
+func (d *Dispatcher) Relay(ctx context.Context) error {
+	for {
+		relayed, err := d.relayBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if relayed < relayBatch {
+			return nil
+		}
+	}
+}
+
+// relayBatch enqueues the jobs and marks the events relayed in one
+// transaction, which the queue joins through the context.
+func (d *Dispatcher) relayBatch(ctx context.Context) (int, error) {
+	var relayed int
+	err := d.tx.Do(ctx, func(ctx context.Context) error {
+		events := make([]Event, 0)
+		query := `
+			SELECT * FROM outbox_events
+			WHERE dispatched_at IS NULL
+			ORDER BY seq
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		`
+		if err := d.db.SelectContext(ctx, &events, query, relayBatch); err != nil {
+			return err
+		}
+		relayed = len(events)
+		if len(events) == 0 {
+			return nil
+		}
+
+		seqs := make(pq.Int64Array, len(events))
+		for i, event := range events {
+			seqs[i] = event.Seq
+			for _, s := range d.subscribers {
+				if !s.wants(event.Type) {
+					continue
+				}
+				if _, err := d.queue.Enqueue(ctx, jobKind(s.name), event, jobs.Options{}); err != nil {
+					return err
+				}
+			}
+		}
+
+		query = `UPDATE outbox_events SET dispatched_at = NOW() WHERE seq = ANY($1)`
+		_, err := d.db.ExecContext(ctx, query, seqs)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return relayed, nil
+}
+
+// Prune deletes events relayed before the cutoff and returns how many it
+// removed.
+func (d *Dispatcher) Prune(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM outbox_events WHERE dispatched_at < $1`
+
+	result, err := d.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func jobKind(subscriber string) string {
+	return "event:" + subscriber
+}
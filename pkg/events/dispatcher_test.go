@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"home-library/pkg/config"
+	"home-library/pkg/jobs"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var eventColumns = []string{"seq", "event_id", "type", "user_id", "payload", "occurred_at", "dispatched_at"}
+
+func TestRelay(t *testing.T) {
+	db, mock := newMockDB(t)
+	queue := jobs.NewQueue(db)
+	dispatcher := NewDispatcher(db, queue, jobs.NewPool(queue, config.JobsConfig{}))
+
+	noop := func(ctx context.Context, event *Event) error { return nil }
+	dispatcher.Subscribe("mailer", noop, TypeUserRegistered)
+	dispatcher.Subscribe("webhooks", noop)
+
+	t.Run("enqueues a job per interested subscriber", func(t *testing.T) {
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+			WithArgs(relayBatch).
+			WillReturnRows(sqlmock.NewRows(eventColumns).
+				AddRow(1, uuid.New(), TypeUserRegistered, uuid.New(), []byte(`{}`), now, nil).
+				AddRow(2, uuid.New(), TypeEbookAdded, uuid.New(), []byte(`{}`), now, nil))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), "event:mailer", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), "event:webhooks", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), "event:webhooks", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox_events SET dispatched_at = NOW() WHERE seq = ANY($1)")).
+			WithArgs(pq.Int64Array{1, 2}).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		assert.NoError(t, dispatcher.Relay(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty outbox", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+			WillReturnRows(sqlmock.NewRows(eventColumns))
+		mock.ExpectCommit()
+
+		assert.NoError(t, dispatcher.Relay(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("events stay in the outbox when enqueueing fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+			WillReturnRows(sqlmock.NewRows(eventColumns).
+				AddRow(3, uuid.New(), TypeEbookAdded, uuid.New(), []byte(`{}`), time.Now(), nil))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WillReturnError(errors.New("db down"))
+		mock.ExpectRollback()
+
+		assert.Error(t, dispatcher.Relay(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSubscriberWants(t *testing.T) {
+	all := subscriber{name: "all", types: map[Type]bool{}}
+	some := subscriber{name: "some", types: map[Type]bool{TypeEbookAdded: true}}
+
+	assert.True(t, all.wants(TypeUserRegistered))
+	assert.True(t, some.wants(TypeEbookAdded))
+	assert.False(t, some.wants(TypeEbookDeleted))
+}
@@ -0,0 +1,114 @@
+// Package events carries domain events from the code that changes the data
+// to the parts of the application that react to it. Events are recorded in
+// the outbox_events table in the same transaction as the change, so an
+// event exists exactly when the change was committed, and a Dispatcher
+// delivers them to subscribers at least once.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type Type string
+
+const (
+	TypeUserRegistered Type = "user.registered"
+	TypeEbookAdded     Type = "ebook.added"
+	TypeEbookDeleted   Type = "ebook.deleted"
+	TypeBookAdded      Type = "book.added"
+	TypeLoanCreated    Type = "loan.created"
+	TypeLoanReturned   Type = "loan.returned"
+)
+
+type Event struct {
+	Seq     int64     `db:"seq" json:"-"`
+	EventID uuid.UUID `db:"event_id" json:"event_id"`
+	Type    Type      `db:"type" json:"type"`
+	// UserID is the user whose data changed. Households see the events of
+	// their members.
+	UserID       uuid.UUID       `db:"user_id" json:"user_id"`
+	Payload      json.RawMessage `db:"payload" json:"payload"`
+	OccurredAt   time.Time       `db:"occurred_at" json:"occurred_at"`
+	DispatchedAt *time.Time      `db:"dispatched_at" json:"-"`
+}
+
+type UserRegistered struct {
+	UserID    uuid.UUID `json:"user_id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+}
+
+type EbookAdded struct {
+	FileID  uuid.UUID `json:"file_id"`
+	Title   string    `json:"title"`
+	Authors []string  `json:"authors"`
+	Format  string    `json:"format"`
+}
+
+type EbookDeleted struct {
+	FileID uuid.UUID `json:"file_id"`
+	Title  string    `json:"title"`
+}
+
+type BookAdded struct {
+	BookID  uuid.UUID `json:"book_id"`
+	Title   string    `json:"title"`
+	Authors []string  `json:"authors"`
+}
+
+type LoanCreated struct {
+	LoanID       uuid.UUID  `json:"loan_id"`
+	CopyID       uuid.UUID  `json:"copy_id"`
+	BorrowerName string     `json:"borrower_name"`
+	DueAt        *time.Time `json:"due_at"`
+}
+
+type LoanReturned struct {
+	LoanID uuid.UUID `json:"loan_id"`
+	CopyID uuid.UUID `json:"copy_id"`
+}
+
+// New makes an event with payload marshalled to JSON.
+func New(typ Type, userID uuid.UUID, payload any) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s event payload: %w", typ, err)
+	}
+
+	return &Event{
+		EventID:    uuid.New(),
+		Type:       typ,
+		UserID:     userID,
+		Payload:    data,
+		OccurredAt: time.Now(),
+	}, nil
+}
+
+// Decode unmarshals the payload into v.
+func (e *Event) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// Record writes the event to the outbox. Pass the transaction making the
+// change the event describes.
+func Record(ctx context.Context, tx *sqlx.Tx, typ Type, userID uuid.UUID, payload any) error {
+	event, err := New(typ, userID, payload)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO outbox_events (event_id, type, user_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4::jsonb, $5)
+	`
+
+	// The payload goes as text: lib/pq would send a byte slice as bytea.
+	_, err = tx.ExecContext(ctx, query, event.EventID, event.Type, event.UserID, string(event.Payload), event.OccurredAt)
+	return err
+}
@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return sqlx.NewDb(db, "sqlmock"), mock
+}
+
+func TestNew(t *testing.T) {
+	userID, fileID := uuid.New(), uuid.New()
+
+	event, err := New(TypeEbookAdded, userID, EbookAdded{FileID: fileID, Title: "Улитка на склоне"})
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, event.EventID)
+	assert.Equal(t, userID, event.UserID)
+
+	var payload EbookAdded
+	require.NoError(t, event.Decode(&payload))
+	assert.Equal(t, fileID, payload.FileID)
+	assert.Equal(t, "Улитка на склоне", payload.Title)
+
+	_, err = New(TypeEbookAdded, userID, make(chan int))
+	assert.Error(t, err)
+}
+
+func TestRecord(t *testing.T) {
+	db, mock := newMockDB(t)
+	userID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox_events")).
+		WithArgs(sqlmock.AnyArg(), TypeUserRegistered, userID, `{"user_id":"`+userID.String()+`","first_name":"Анна","last_name":""}`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Beginx()
+	require.NoError(t, err)
+	require.NoError(t, Record(context.Background(), tx, TypeUserRegistered, userID, UserRegistered{UserID: userID, FirstName: "Анна"}))
+	require.NoError(t, tx.Commit())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,297 @@
+// Package fb2 reads the description block of FictionBook 2 files, plain or
+// zipped, in any of the encodings found in the wild (mostly UTF-8 and
+// windows-1251).
+package fb2
+
+import (
+	"archive/zip"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"home-library/pkg/isbn"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// MaxCoverSize bounds the decoded cover image kept in memory.
+const MaxCoverSize = 10 << 20
+
+var ErrNotFB2 = errors.New("fb2: not a FictionBook file")
+
+type Author struct {
+	FirstName  string
+	MiddleName string
+	LastName   string
+	Nickname   string
+}
+
+// Name returns the author as "First Middle Last", falling back to the
+// nickname for pseudonymous authors.
+func (a Author) Name() string {
+	name := strings.Join(strings.Fields(strings.Join([]string{a.FirstName, a.MiddleName, a.LastName}, " ")), " ")
+	if name == "" {
+		return a.Nickname
+	}
+	return name
+}
+
+type Description struct {
+	Title       string
+	Authors     []Author
+	Genres      []string
+	Language    string
+	Annotation  string
+	Series      string
+	SeriesIndex int
+	Publisher   string
+	Year        int
+	ISBN        string
+	CoverType   string
+	Cover       []byte
+}
+
+type xmlDescription struct {
+	TitleInfo struct {
+		Genres     []string    `xml:"genre"`
+		Authors    []xmlAuthor `xml:"author"`
+		BookTitle  text        `xml:"book-title"`
+		Annotation struct {
+			Paragraphs []text `xml:"p"`
+		} `xml:"annotation"`
+		Coverpage struct {
+			Images []xmlImage `xml:"image"`
+		} `xml:"coverpage"`
+		Lang     string `xml:"lang"`
+		Sequence struct {
+			Name   string `xml:"name,attr"`
+			Number string `xml:"number,attr"`
+		} `xml:"sequence"`
+	} `xml:"title-info"`
+	PublishInfo struct {
+		Publisher string `xml:"publisher"`
+		Year      string `xml:"year"`
+		ISBN      string `xml:"isbn"`
+	} `xml:"publish-info"`
+}
+
+type xmlAuthor struct {
+	FirstName  string `xml:"first-name"`
+	MiddleName string `xml:"middle-name"`
+	LastName   string `xml:"last-name"`
+	Nickname   string `xml:"nickname"`
+}
+
+// xmlImage keeps the href whatever prefix the xlink namespace is bound to,
+// plenty of files use l:href without declaring it.
+type xmlImage struct {
+	Href string
+}
+
+func (i *xmlImage) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "href" {
+			i.Href = attr.Value
+		}
+	}
+	return d.Skip()
+}
+
+// text collects the character data of an element and all its children, so
+// <p>Some <emphasis>text</emphasis></p> reads as "Some text".
+type text string
+
+func (t *text) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	var b strings.Builder
+	for depth := 1; depth > 0; {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			b.Write(token)
+		}
+	}
+	*t = text(strings.Join(strings.Fields(b.String()), " "))
+	return nil
+}
+
+// Parse reads the description of an FB2 document and the cover binary it
+// references. The book body is skipped without being kept in memory.
+func Parse(r io.Reader) (*Description, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+
+	var (
+		description *Description
+		coverID     string
+		seenRoot    bool
+	)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if description != nil {
+				// The description is all that is needed, a broken body
+				// further down does not make it any less usable.
+				break
+			}
+			return nil, ErrNotFB2
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !seenRoot {
+			if start.Name.Local != "FictionBook" {
+				return nil, ErrNotFB2
+			}
+			seenRoot = true
+			continue
+		}
+
+		switch start.Name.Local {
+		case "description":
+			var raw xmlDescription
+			if err := decoder.DecodeElement(&raw, &start); err != nil {
+				return nil, ErrNotFB2
+			}
+			description, coverID = newDescription(raw)
+		case "binary":
+			if description == nil || coverID == "" || attr(start, "id") != coverID {
+				if err := decoder.Skip(); err != nil {
+					return description, nil
+				}
+				continue
+			}
+			var data string
+			if err := decoder.DecodeElement(&data, &start); err != nil {
+				return description, nil
+			}
+			if cover, ok := decodeCover(data); ok {
+				description.Cover = cover
+				description.CoverType = attr(start, "content-type")
+			}
+			return description, nil
+		default:
+			if err := decoder.Skip(); err != nil && description != nil {
+				return description, nil
+			}
+		}
+	}
+
+	if description == nil {
+		return nil, ErrNotFB2
+	}
+	return description, nil
+}
+
+// ParseZip parses the first .fb2 file inside a zip archive, the usual way
+// FB2 books are distributed.
+func ParseZip(r io.ReaderAt, size int64) (*Description, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrNotFB2
+	}
+
+	for _, file := range zr.File {
+		if !strings.EqualFold(path.Ext(file.Name), ".fb2") {
+			continue
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return nil, ErrNotFB2
+		}
+		defer f.Close()
+
+		return Parse(f)
+	}
+
+	return nil, ErrNotFB2
+}
+
+func newDescription(raw xmlDescription) (*Description, string) {
+	info := raw.TitleInfo
+	description := &Description{
+		Title:     string(info.BookTitle),
+		Language:  strings.TrimSpace(info.Lang),
+		Series:    strings.TrimSpace(info.Sequence.Name),
+		Publisher: strings.TrimSpace(raw.PublishInfo.Publisher),
+	}
+
+	for _, author := range info.Authors {
+		author := Author{
+			FirstName:  strings.TrimSpace(author.FirstName),
+			MiddleName: strings.TrimSpace(author.MiddleName),
+			LastName:   strings.TrimSpace(author.LastName),
+			Nickname:   strings.TrimSpace(author.Nickname),
+		}
+		if author.Name() != "" {
+			description.Authors = append(description.Authors, author)
+		}
+	}
+
+	for _, genre := range info.Genres {
+		if genre = strings.TrimSpace(genre); genre != "" {
+			description.Genres = append(description.Genres, genre)
+		}
+	}
+
+	paragraphs := make([]string, 0, len(info.Annotation.Paragraphs))
+	for _, paragraph := range info.Annotation.Paragraphs {
+		if paragraph != "" {
+			paragraphs = append(paragraphs, string(paragraph))
+		}
+	}
+	description.Annotation = strings.Join(paragraphs, "\n")
+
+	if number, err := strconv.Atoi(strings.TrimSpace(info.Sequence.Number)); err == nil {
+		description.SeriesIndex = number
+	}
+	// Years come as "2005" as well as "2005 г." or a full date.
+	if year := strings.TrimSpace(raw.PublishInfo.Year); len(year) >= 4 {
+		if value, err := strconv.Atoi(year[:4]); err == nil {
+			description.Year = value
+		}
+	}
+	description.ISBN = isbn.Normalize(strings.TrimSpace(raw.PublishInfo.ISBN))
+
+	var coverID string
+	if len(info.Coverpage.Images) > 0 {
+		coverID = strings.TrimPrefix(info.Coverpage.Images[0].Href, "#")
+	}
+
+	return description, coverID
+}
+
+func decodeCover(data string) ([]byte, bool) {
+	cover, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data), ""))
+	if err != nil || len(cover) == 0 || len(cover) > MaxCoverSize {
+		return nil, false
+	}
+	return cover, true
+}
+
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package fb2
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const book = `<?xml version="1.0" encoding="%s"?>
+<FictionBook xmlns="http://www.gribuser.ru/xml/fictionbook/2.0" xmlns:l="http://www.w3.org/1999/xlink">
+  <description>
+    <title-info>
+      <genre>sf_social</genre>
+      <genre>sf</genre>
+      <author>
+        <first-name>Аркадий</first-name>
+        <middle-name>Натанович</middle-name>
+        <last-name>Стругацкий</last-name>
+      </author>
+      <author>
+        <nickname>С. Ярославцев</nickname>
+      </author>
+      <book-title>Понедельник начинается в субботу</book-title>
+      <annotation>
+        <p>Сказка для научных работников <emphasis>младшего</emphasis> возраста.</p>
+        <p>НИИЧАВО.</p>
+      </annotation>
+      <coverpage><image l:href="#cover.jpg"/></coverpage>
+      <lang>ru</lang>
+      <sequence name="Понедельник" number="1"/>
+    </title-info>
+    <publish-info>
+      <publisher>АСТ</publisher>
+      <year>2005 г.</year>
+      <isbn>978-5-17-098765-8</isbn>
+    </publish-info>
+  </description>
+  <body><section><p>Я приближался к месту моего назначения.</p></section></body>
+  <binary id="other.png" content-type="image/png">bm90IHRoZSBjb3Zlcg==</binary>
+  <binary id="cover.jpg" content-type="image/jpeg">%s</binary>
+</FictionBook>`
+
+func document(encoding string) string {
+	cover := base64.StdEncoding.EncodeToString([]byte("jpeg cover bytes"))
+	// Real files wrap base64 at 76 or so columns.
+	cover = cover[:10] + "\n    " + cover[10:]
+	return strings.Replace(strings.Replace(book, "%s", encoding, 1), "%s", cover, 1)
+}
+
+func assertDescription(t *testing.T, description *Description) {
+	assert.Equal(t, "Понедельник начинается в субботу", description.Title)
+	require.Len(t, description.Authors, 2)
+	assert.Equal(t, "Аркадий Натанович Стругацкий", description.Authors[0].Name())
+	assert.Equal(t, "С. Ярославцев", description.Authors[1].Name())
+	assert.Equal(t, []string{"sf_social", "sf"}, description.Genres)
+	assert.Equal(t, "ru", description.Language)
+	assert.Equal(t, "Сказка для научных работников младшего возраста.\nНИИЧАВО.", description.Annotation)
+	assert.Equal(t, "Понедельник", description.Series)
+	assert.Equal(t, 1, description.SeriesIndex)
+	assert.Equal(t, "АСТ", description.Publisher)
+	assert.Equal(t, 2005, description.Year)
+	assert.Equal(t, "9785170987658", description.ISBN)
+	assert.Equal(t, "image/jpeg", description.CoverType)
+	assert.Equal(t, []byte("jpeg cover bytes"), description.Cover)
+}
+
+func TestParse(t *testing.T) {
+	t.Run("utf-8", func(t *testing.T) {
+		description, err := Parse(strings.NewReader(document("UTF-8")))
+		require.NoError(t, err)
+
+		assertDescription(t, description)
+	})
+
+	t.Run("windows-1251", func(t *testing.T) {
+		encoded, err := charmap.Windows1251.NewEncoder().String(document("windows-1251"))
+		require.NoError(t, err)
+
+		description, err := Parse(strings.NewReader(encoded))
+		require.NoError(t, err)
+
+		assertDescription(t, description)
+	})
+
+	t.Run("without cover", func(t *testing.T) {
+		doc := strings.Replace(document("UTF-8"), `<coverpage><image l:href="#cover.jpg"/></coverpage>`, "", 1)
+
+		description, err := Parse(strings.NewReader(doc))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Понедельник начинается в субботу", description.Title)
+		assert.Nil(t, description.Cover)
+	})
+
+	t.Run("broken body", func(t *testing.T) {
+		doc := document("UTF-8")
+		doc = doc[:strings.Index(doc, "<body>")] + "<body><section><p>oops</section>"
+
+		description, err := Parse(strings.NewReader(doc))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Понедельник начинается в субботу", description.Title)
+	})
+
+	t.Run("not fictionbook", func(t *testing.T) {
+		_, err := Parse(strings.NewReader(`<?xml version="1.0"?><html><body/></html>`))
+		assert.Equal(t, ErrNotFB2, err)
+
+		_, err = Parse(strings.NewReader("%PDF-1.7"))
+		assert.Equal(t, ErrNotFB2, err)
+	})
+}
+
+func TestParseZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create("Strugatsky_Ponedelnik.FB2")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(document("UTF-8")))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	description, err := ParseZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	assertDescription(t, description)
+}
@@ -0,0 +1,156 @@
+// Package goals evaluates yearly reading goals and challenges against a
+// reading log and projects whether the reader is on track to meet them by
+// the end of the year.
+package goals
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidGoal = errors.New("invalid reading goal")
+
+// Metric is what a goal counts.
+type Metric string
+
+const (
+	MetricBooks  Metric = "books"
+	MetricPages  Metric = "pages"
+	MetricSeries Metric = "series"
+)
+
+// Entry is a book the reader finished.
+type Entry struct {
+	FinishedAt time.Time
+	Pages      int
+	Language   string
+	Authors    []string
+	Tags       []string
+	// CompletesSeries marks the book that finished a series for the reader.
+	CompletesSeries bool
+}
+
+// Goal is a yearly target. A plain goal such as "50 books this year" has no
+// filters; a challenge such as "read 5 books in English" narrows the entries
+// that count. Filters compare case-insensitively and zero ones match all.
+type Goal struct {
+	Year     int
+	Metric   Metric
+	Target   int
+	Language string
+	Author   string
+	Tag      string
+}
+
+func (g Goal) Validate() error {
+	switch g.Metric {
+	case MetricBooks, MetricPages, MetricSeries:
+	default:
+		return ErrInvalidGoal
+	}
+	if g.Target <= 0 || g.Year < 1 {
+		return ErrInvalidGoal
+	}
+	return nil
+}
+
+// Status tells how a goal stands against the time left in its year.
+type Status string
+
+const (
+	StatusUpcoming Status = "upcoming"
+	StatusOnTrack  Status = "on_track"
+	StatusBehind   Status = "behind"
+	StatusAchieved Status = "achieved"
+	StatusMissed   Status = "missed"
+)
+
+type Progress struct {
+	Done   int
+	Target int
+	// Expected is how much should be done by now when reading at an even pace.
+	Expected float64
+	// Projected is what the current pace adds up to by the end of the year.
+	Projected int
+	Status    Status
+}
+
+// Evaluate counts the entries finished in the goal's year, in the given
+// location, and compares the result with the share of the year elapsed at
+// now. The caller passes the location of the library, so that a book
+// finished on New Year's Eve counts towards the right year.
+func Evaluate(goal Goal, entries []Entry, now time.Time, location *time.Location) Progress {
+	start := time.Date(goal.Year, time.January, 1, 0, 0, 0, 0, location)
+	end := start.AddDate(1, 0, 0)
+
+	progress := Progress{Target: goal.Target}
+	for _, entry := range entries {
+		finished := entry.FinishedAt.In(location)
+		if finished.Before(start) || !finished.Before(end) || !goal.matches(entry) {
+			continue
+		}
+
+		switch goal.Metric {
+		case MetricBooks:
+			progress.Done++
+		case MetricPages:
+			progress.Done += entry.Pages
+		case MetricSeries:
+			if entry.CompletesSeries {
+				progress.Done++
+			}
+		}
+	}
+
+	elapsed := float64(now.Sub(start)) / float64(end.Sub(start))
+	switch {
+	case elapsed <= 0:
+		progress.Status = StatusUpcoming
+		return progress
+	case elapsed >= 1:
+		progress.Expected = float64(goal.Target)
+		progress.Projected = progress.Done
+		progress.Status = StatusMissed
+		if progress.Done >= goal.Target {
+			progress.Status = StatusAchieved
+		}
+		return progress
+	}
+
+	progress.Expected = float64(goal.Target) * elapsed
+	progress.Projected = int(float64(progress.Done) / elapsed)
+	switch {
+	case progress.Done >= goal.Target:
+		progress.Status = StatusAchieved
+	// A reader is on track until a whole book or page behind the even pace.
+	case float64(progress.Done) > progress.Expected-1:
+		progress.Status = StatusOnTrack
+	default:
+		progress.Status = StatusBehind
+	}
+
+	return progress
+}
+
+func (g Goal) matches(entry Entry) bool {
+	if g.Language != "" && !strings.EqualFold(g.Language, entry.Language) {
+		return false
+	}
+	if g.Author != "" && !containsFold(entry.Authors, g.Author) {
+		return false
+	}
+	if g.Tag != "" && !containsFold(entry.Tags, g.Tag) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), strings.TrimSpace(target)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package goals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func finished(year int, month time.Month, day int, pages int) Entry {
+	return Entry{FinishedAt: time.Date(year, month, day, 12, 0, 0, 0, time.UTC), Pages: pages, Language: "ru"}
+}
+
+func TestEvaluate(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	assert.NoError(t, err)
+
+	entries := []Entry{
+		finished(2024, time.January, 10, 300),
+		finished(2024, time.February, 20, 250),
+		{FinishedAt: time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC), Pages: 400, Language: "EN", Tags: []string{"Fantasy"}, CompletesSeries: true},
+		// Still New Year's Eve in UTC, but 2024 in Moscow.
+		{FinishedAt: time.Date(2023, time.December, 31, 22, 0, 0, 0, time.UTC), Pages: 100, Language: "en"},
+		finished(2023, time.June, 1, 500),
+	}
+	// A quarter of 2024 has passed by early April.
+	april := time.Date(2024, time.April, 1, 0, 0, 0, 0, moscow)
+
+	t.Run("books on track", func(t *testing.T) {
+		progress := Evaluate(Goal{Year: 2024, Metric: MetricBooks, Target: 16}, entries, april, moscow)
+
+		assert.Equal(t, 4, progress.Done)
+		assert.InDelta(t, 4, progress.Expected, 0.1)
+		assert.Equal(t, 16, progress.Projected)
+		assert.Equal(t, StatusOnTrack, progress.Status)
+	})
+
+	t.Run("pages behind", func(t *testing.T) {
+		progress := Evaluate(Goal{Year: 2024, Metric: MetricPages, Target: 10000}, entries, april, moscow)
+
+		assert.Equal(t, 1050, progress.Done)
+		assert.Equal(t, StatusBehind, progress.Status)
+		assert.Less(t, progress.Projected, 10000)
+	})
+
+	t.Run("challenge with filters", func(t *testing.T) {
+		english := Evaluate(Goal{Year: 2024, Metric: MetricBooks, Target: 2, Language: "en"}, entries, april, moscow)
+		assert.Equal(t, 2, english.Done)
+		assert.Equal(t, StatusAchieved, english.Status)
+
+		fantasy := Evaluate(Goal{Year: 2024, Metric: MetricBooks, Target: 5, Tag: "fantasy"}, entries, april, moscow)
+		assert.Equal(t, 1, fantasy.Done)
+
+		series := Evaluate(Goal{Year: 2024, Metric: MetricSeries, Target: 3}, entries, april, moscow)
+		assert.Equal(t, 1, series.Done)
+	})
+
+	t.Run("past year", func(t *testing.T) {
+		progress := Evaluate(Goal{Year: 2023, Metric: MetricBooks, Target: 2}, entries, april, moscow)
+
+		assert.Equal(t, 1, progress.Done)
+		assert.Equal(t, StatusMissed, progress.Status)
+	})
+
+	t.Run("future year", func(t *testing.T) {
+		progress := Evaluate(Goal{Year: 2025, Metric: MetricBooks, Target: 2}, entries, april, moscow)
+
+		assert.Zero(t, progress.Done)
+		assert.Equal(t, StatusUpcoming, progress.Status)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Goal{Year: 2024, Metric: MetricPages, Target: 5000}.Validate())
+	assert.ErrorIs(t, Goal{Year: 2024, Metric: "minutes", Target: 5}.Validate(), ErrInvalidGoal)
+	assert.ErrorIs(t, Goal{Year: 2024, Metric: MetricBooks}.Validate(), ErrInvalidGoal)
+}
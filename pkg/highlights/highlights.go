@@ -0,0 +1,72 @@
+// Package highlights reads highlights and notes exported from e-readers:
+// Kindle "My Clippings.txt" and KOReader JSON exports or sidecar files.
+package highlights
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatKindle   Format = "kindle"
+	FormatKOReader Format = "koreader"
+)
+
+type Kind string
+
+const (
+	KindHighlight Kind = "highlight"
+	KindNote      Kind = "note"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown highlights format")
+	ErrMalformed     = errors.New("highlights file is malformed")
+)
+
+// Highlight is a single passage or note, normalized across formats. Location
+// is the Kindle location range and Page the printed page where the reader
+// knows it.
+type Highlight struct {
+	Title     string
+	Author    string
+	Kind      Kind
+	Text      string
+	Note      string
+	Chapter   string
+	Location  string
+	Page      int
+	CreatedAt *time.Time
+}
+
+// Detect guesses the format from the beginning of the file.
+func Detect(data []byte) Format {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.HasPrefix(data, []byte("{")):
+		return FormatKOReader
+	case bytes.HasPrefix(data, []byte("--")), bytes.HasPrefix(data, []byte("return")):
+		return FormatKOReader
+	default:
+		return FormatKindle
+	}
+}
+
+// Parse reads all highlights of the given format. Entries without text, such
+// as bookmarks, are skipped.
+func Parse(format Format, r io.Reader) ([]Highlight, error) {
+	switch format {
+	case FormatKindle:
+		return ParseKindle(r)
+	case FormatKOReader:
+		return ParseKOReader(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
@@ -0,0 +1,269 @@
+package highlights
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day, hour, minute, second int) *time.Time {
+	t := time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	return &t
+}
+
+const englishClippings = "\ufeffThe Left Hand of Darkness (Le Guin, Ursula K.)\r\n" +
+	"- Your Highlight on page 12 | Location 170-172 | Added on Sunday, March 3, 2019 10:24:31 PM\r\n" +
+	"\r\n" +
+	"Light is the left hand of darkness\r\n" +
+	"==========\r\n" +
+	"The Left Hand of Darkness (Le Guin, Ursula K.)\r\n" +
+	"- Your Note on page 12 | Location 172 | Added on Sunday, March 3, 2019 10:25:02 PM\r\n" +
+	"\r\n" +
+	"Shifgrethor!\r\n" +
+	"==========\r\n" +
+	"The Left Hand of Darkness (Le Guin, Ursula K.)\r\n" +
+	"- Your Bookmark on page 40 | Location 610 | Added on Monday, March 4, 2019 8:01:00 AM\r\n" +
+	"\r\n" +
+	"\r\n" +
+	"==========\r\n" +
+	"Notes (2019) (Journal)\r\n" +
+	"- Your Note at location 5 | Added on Tuesday, 5 March 2019 12:00:00\r\n" +
+	"\r\n" +
+	"A standalone note\r\n" +
+	"==========\r\n"
+
+const russianClippings = "Пикник на обочине (Стругацкий Аркадий;Стругацкий Борис)\n" +
+	"- Ваш выделенный отрывок на странице 57 | место 858–860 | Добавлено: воскресенье, 3 марта 2019 г. в 10:24:31\n" +
+	"\n" +
+	"Счастья для всех, даром,\n" +
+	"и пусть никто не уйдёт обиженный!\n" +
+	"==========\n" +
+	"Пикник на обочине (Стругацкий Аркадий;Стругацкий Борис)\n" +
+	"- Ваша заметка в месте 860 | Добавлено: воскресенье, 3 марта 2019 г. в 10:26:00\n" +
+	"\n" +
+	"Финал\n" +
+	"==========\n"
+
+func TestParseKindle(t *testing.T) {
+	t.Run("english", func(t *testing.T) {
+		result, err := ParseKindle(strings.NewReader(englishClippings))
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		assert.Equal(t, Highlight{
+			Title:     "The Left Hand of Darkness",
+			Author:    "Le Guin, Ursula K.",
+			Kind:      KindHighlight,
+			Text:      "Light is the left hand of darkness",
+			Note:      "Shifgrethor!",
+			Location:  "170-172",
+			Page:      12,
+			CreatedAt: date(2019, time.March, 3, 22, 24, 31),
+		}, result[0])
+
+		assert.Equal(t, Highlight{
+			Title:     "Notes (2019)",
+			Author:    "Journal",
+			Kind:      KindNote,
+			Note:      "A standalone note",
+			Location:  "5",
+			CreatedAt: date(2019, time.March, 5, 12, 0, 0),
+		}, result[1])
+	})
+
+	t.Run("russian", func(t *testing.T) {
+		result, err := ParseKindle(strings.NewReader(russianClippings))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+
+		assert.Equal(t, "Пикник на обочине", result[0].Title)
+		assert.Equal(t, "Стругацкий Аркадий;Стругацкий Борис", result[0].Author)
+		assert.Equal(t, "Счастья для всех, даром,\nи пусть никто не уйдёт обиженный!", result[0].Text)
+		assert.Equal(t, "Финал", result[0].Note)
+		assert.Equal(t, "858-860", result[0].Location)
+		assert.Equal(t, 57, result[0].Page)
+		assert.Equal(t, date(2019, time.March, 3, 10, 24, 31), result[0].CreatedAt)
+	})
+
+	t.Run("not clippings", func(t *testing.T) {
+		_, err := ParseKindle(strings.NewReader("just some text\nwithout structure\n"))
+		assert.True(t, errors.Is(err, ErrMalformed))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		result, err := ParseKindle(strings.NewReader(""))
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
+
+func TestParseKindleDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected *time.Time
+	}{
+		{"added on sunday, march 3, 2019 12:05:00 am", date(2019, time.March, 3, 0, 5, 0)},
+		{"added on sunday, 3 march 2019 10:24:31", date(2019, time.March, 3, 10, 24, 31)},
+		{"добавлено: суббота, 1 мая 2021 г. в 9:05:07", date(2021, time.May, 1, 9, 5, 7)},
+		{"hinzugefügt am sonntag, 3. märz 2019 10:24:31", date(2019, time.March, 3, 10, 24, 31)},
+		{"ajouté le dimanche 3 mars 2019 10:24:31", date(2019, time.March, 3, 10, 24, 31)},
+		{"añadido el martes, 5 de marzo de 2019 7:00:00", date(2019, time.March, 5, 7, 0, 0)},
+		{"added on someday", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseKindleDate(test.input))
+		})
+	}
+}
+
+func TestParseKOReaderJSON(t *testing.T) {
+	t.Run("single book", func(t *testing.T) {
+		input := `{
+			"title": "Solaris",
+			"author": "Stanisław Lem",
+			"number_of_pages": 220,
+			"entries": [
+				{"chapter": "The Arrival", "page": 12, "time": 1551608671, "text": "  Man has gone out to explore other worlds  ", "note": "why"},
+				{"page": 14, "time": 1551608700, "text": ""}
+			]
+		}`
+
+		result, err := ParseKOReader(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+
+		assert.Equal(t, Highlight{
+			Title:     "Solaris",
+			Author:    "Stanisław Lem",
+			Kind:      KindHighlight,
+			Text:      "Man has gone out to explore other worlds",
+			Note:      "why",
+			Chapter:   "The Arrival",
+			Page:      12,
+			CreatedAt: date(2019, time.March, 3, 10, 24, 31),
+		}, result[0])
+	})
+
+	t.Run("several books", func(t *testing.T) {
+		input := `{"created_on": 1700000000, "version": "koreader", "documents": [
+			{"title": "A", "author": "X", "entries": [{"text": "one", "page": "/body/DocFragment[3]"}]},
+			{"title": "B", "author": "Y", "entries": [{"text": "two"}, {"text": "three"}]}
+		]}`
+
+		result, err := ParseKOReader(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		assert.Equal(t, "A", result[0].Title)
+		assert.Equal(t, 0, result[0].Page)
+		assert.Equal(t, "B", result[2].Title)
+		assert.Equal(t, "three", result[2].Text)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := ParseKOReader(strings.NewReader(`{"entries": [`))
+		assert.True(t, errors.Is(err, ErrMalformed))
+	})
+}
+
+func TestParseKOReaderSidecar(t *testing.T) {
+	t.Run("annotations", func(t *testing.T) {
+		input := `-- we can read Lua syntax here!
+return {
+    ["annotations"] = {
+        [1] = {
+            ["chapter"] = "Глава 1",
+            ["datetime"] = "2024-01-02 10:11:12",
+            ["drawer"] = "lighten",
+            ["note"] = "Заметка\
+на двух строках",
+            ["page"] = "/body/DocFragment[2]/body/p[3]/text().0",
+            ["pageno"] = 7,
+            ["text"] = "Цитата с \"кавычками\" и \226\128\148 тире",
+        },
+        [2] = {
+            ["datetime"] = "2024-01-03 08:00:00",
+            ["page"] = "/body/DocFragment[4]",
+            ["pageno"] = 20,
+        },
+    },
+    ["doc_props"] = {
+        ["authors"] = "Аркадий Стругацкий\
+Борис Стругацкий",
+        ["title"] = "Понедельник начинается в субботу",
+    },
+    ["percent_finished"] = 0.25,
+    ["summary"] = { status = "reading", modified = "2024-01-03" },
+    ["flags"] = { true, false, nil, -1.5e2, 0x10 },
+}`
+
+		result, err := ParseKOReader(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+
+		assert.Equal(t, Highlight{
+			Title:     "Понедельник начинается в субботу",
+			Author:    "Аркадий Стругацкий, Борис Стругацкий",
+			Kind:      KindHighlight,
+			Text:      "Цитата с \"кавычками\" и — тире",
+			Note:      "Заметка\nна двух строках",
+			Chapter:   "Глава 1",
+			Page:      7,
+			CreatedAt: date(2024, time.January, 2, 10, 11, 12),
+		}, result[0])
+	})
+
+	t.Run("legacy highlight table", func(t *testing.T) {
+		input := `return {
+    ["highlight"] = {
+        [12] = {
+            [1] = { ["datetime"] = "2019-03-03 10:24:31", ["text"] = "second on the page" },
+        },
+        [3] = {
+            [1] = { ["chapter"] = "I", ["text"] = [[first
+highlight]] },
+        },
+    },
+    ["stats"] = { ["title"] = "Old Book", ["authors"] = "Someone" },
+}`
+
+		result, err := ParseKOReader(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		assert.Equal(t, "first\nhighlight", result[0].Text)
+		assert.Equal(t, 3, result[0].Page)
+		assert.Equal(t, "Old Book", result[0].Title)
+		assert.Equal(t, 12, result[1].Page)
+		assert.Equal(t, date(2019, time.March, 3, 10, 24, 31), result[1].CreatedAt)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, input := range []string{
+			`return { ["a"] = `,
+			`return { ["a"] = "unterminated }`,
+			`return { ["a"] = os.execute("rm") }`,
+			`return ` + strings.Repeat("{", luaMaxDepth+2) + strings.Repeat("}", luaMaxDepth+2),
+		} {
+			_, err := ParseKOReader(strings.NewReader(input))
+			assert.True(t, errors.Is(err, ErrMalformed), input)
+		}
+	})
+}
+
+func TestDetect(t *testing.T) {
+	assert.Equal(t, FormatKOReader, Detect([]byte("\ufeff  {\"entries\": []}")))
+	assert.Equal(t, FormatKOReader, Detect([]byte("-- ./book.sdr/metadata.epub.lua\nreturn {}")))
+	assert.Equal(t, FormatKOReader, Detect([]byte("return {}")))
+	assert.Equal(t, FormatKindle, Detect([]byte(englishClippings)))
+}
+
+func TestParse(t *testing.T) {
+	_, err := Parse("kobo", strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,293 @@
+package highlights
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const kindleSeparator = "=========="
+
+// Keywords of the metadata line of a clipping in the Kindle interface
+// languages seen in the wild. They are matched against the lowercased line,
+// so stems are enough where words are inflected.
+var (
+	kindleBookmarkWords  = []string{"bookmark", "закладк", "lesezeichen", "signet", "marcador"}
+	kindleHighlightWords = []string{"highlight", "выделен", "markierung", "surlignement", "subrayado"}
+	kindleNoteWords      = []string{"note", "заметк", "notiz", "nota"}
+	kindleLocationWords  = []string{"location", "loc.", "мест", "position", "emplacement", "posición"}
+	kindlePageWords      = []string{"page", "страниц", "seite", "página", "pagina"}
+	kindleAddedWords     = []string{"added on", "добавлено", "hinzugefügt am", "ajouté le", "añadido el", "agregado el"}
+)
+
+var kindleMonths = map[string]time.Month{
+	"january": time.January, "february": time.February, "march": time.March,
+	"april": time.April, "may": time.May, "june": time.June,
+	"july": time.July, "august": time.August, "september": time.September,
+	"october": time.October, "november": time.November, "december": time.December,
+
+	"января": time.January, "февраля": time.February, "марта": time.March,
+	"апреля": time.April, "мая": time.May, "июня": time.June,
+	"июля": time.July, "августа": time.August, "сентября": time.September,
+	"октября": time.October, "ноября": time.November, "декабря": time.December,
+	"январь": time.January, "февраль": time.February, "март": time.March,
+	"апрель": time.April, "май": time.May, "июнь": time.June,
+	"июль": time.July, "август": time.August, "сентябрь": time.September,
+	"октябрь": time.October, "ноябрь": time.November, "декабрь": time.December,
+
+	"januar": time.January, "februar": time.February, "märz": time.March,
+	"mai": time.May, "juni": time.June, "juli": time.July,
+	"oktober": time.October, "dezember": time.December,
+
+	"janvier": time.January, "février": time.February, "mars": time.March,
+	"avril": time.April, "juin": time.June, "juillet": time.July,
+	"août": time.August, "septembre": time.September, "octobre": time.October,
+	"novembre": time.November, "décembre": time.December,
+
+	"enero": time.January, "febrero": time.February, "marzo": time.March,
+	"abril": time.April, "mayo": time.May, "junio": time.June,
+	"julio": time.July, "agosto": time.August, "septiembre": time.September,
+	"octubre": time.October, "noviembre": time.November, "diciembre": time.December,
+}
+
+var (
+	kindleLocation  = regexp.MustCompile(`\d+(?:[-–]\d+)?`)
+	kindlePage      = regexp.MustCompile(`\d+`)
+	kindleDateToken = regexp.MustCompile(`\d{1,2}:\d{2}(?::\d{2})?|\d+|\p{L}+`)
+)
+
+// ParseKindle reads a Kindle "My Clippings.txt". Notes are attached to the
+// highlight they were made on when it is in the file; Kindle stores them as
+// separate clippings located at the end of the highlighted range.
+func ParseKindle(r io.Reader) ([]Highlight, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var (
+		result     = make([]Highlight, 0)
+		block      []string
+		recognized int
+		content    bool
+	)
+
+	flush := func() {
+		highlight, ok := parseClipping(block)
+		if ok {
+			recognized++
+			if highlight != nil {
+				result = append(result, *highlight)
+			}
+		}
+		block = block[:0]
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(strings.TrimPrefix(scanner.Text(), "\ufeff"), "\r")
+		if strings.TrimSpace(line) == kindleSeparator {
+			flush()
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			content = true
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	if content && recognized == 0 {
+		return nil, ErrMalformed
+	}
+
+	return attachNotes(result), nil
+}
+
+// parseClipping reports whether the block looked like a clipping at all and
+// returns nil for clippings that carry no text, such as bookmarks.
+func parseClipping(lines []string) (*Highlight, bool) {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) < 2 || !strings.HasPrefix(strings.TrimSpace(lines[1]), "-") {
+		return nil, false
+	}
+
+	highlight := &Highlight{}
+	highlight.Title, highlight.Author = splitKindleTitle(strings.TrimSpace(lines[0]))
+
+	meta := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[1]), "-"))
+	segments := strings.Split(meta, "|")
+
+	kind := strings.ToLower(segments[0])
+	switch {
+	case containsAny(kind, kindleBookmarkWords):
+		return nil, true
+	case containsAny(kind, kindleHighlightWords):
+		highlight.Kind = KindHighlight
+	case containsAny(kind, kindleNoteWords):
+		highlight.Kind = KindNote
+	default:
+		return nil, false
+	}
+
+	for _, segment := range segments {
+		lower := strings.ToLower(segment)
+		switch {
+		case containsAny(lower, kindleAddedWords):
+			highlight.CreatedAt = parseKindleDate(lower)
+		case containsAny(lower, kindleLocationWords):
+			highlight.Location = strings.ReplaceAll(kindleLocation.FindString(lower), "–", "-")
+		case containsAny(lower, kindlePageWords):
+			highlight.Page, _ = strconv.Atoi(kindlePage.FindString(lower))
+		}
+	}
+
+	text := strings.TrimSpace(strings.Join(lines[2:], "\n"))
+	if text == "" {
+		return nil, true
+	}
+	if highlight.Kind == KindNote {
+		highlight.Note = text
+	} else {
+		highlight.Text = text
+	}
+
+	return highlight, true
+}
+
+// splitKindleTitle separates the author Kindle appends in parentheses.
+func splitKindleTitle(line string) (string, string) {
+	if !strings.HasSuffix(line, ")") {
+		return line, ""
+	}
+
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				title := strings.TrimSpace(line[:i])
+				if title == "" {
+					return line, ""
+				}
+				return title, strings.TrimSpace(line[i+1 : len(line)-1])
+			}
+		}
+	}
+
+	return line, ""
+}
+
+// parseKindleDate picks the date apart token by token, because every
+// interface language orders day, month and year differently and decorates
+// them with weekdays and words like "г." or "в".
+func parseKindleDate(s string) *time.Time {
+	var (
+		year, day            int
+		month                time.Month
+		hour, minute, second int
+		pm, am               bool
+	)
+
+	for _, token := range kindleDateToken.FindAllString(s, -1) {
+		switch {
+		case strings.Contains(token, ":"):
+			parts := strings.Split(token, ":")
+			hour, _ = strconv.Atoi(parts[0])
+			minute, _ = strconv.Atoi(parts[1])
+			if len(parts) > 2 {
+				second, _ = strconv.Atoi(parts[2])
+			}
+		case token[0] >= '0' && token[0] <= '9':
+			n, _ := strconv.Atoi(token)
+			if len(token) == 4 {
+				year = n
+			} else if day == 0 {
+				day = n
+			}
+		case token == "pm":
+			pm = true
+		case token == "am":
+			am = true
+		default:
+			if m, ok := kindleMonths[token]; ok {
+				month = m
+			}
+		}
+	}
+
+	if year == 0 || month == 0 || day < 1 || day > 31 || hour > 23 || minute > 59 || second > 59 {
+		return nil
+	}
+	if pm && hour < 12 {
+		hour += 12
+	}
+	if am && hour == 12 {
+		hour = 0
+	}
+
+	date := time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	return &date
+}
+
+func attachNotes(clippings []Highlight) []Highlight {
+	result := make([]Highlight, 0, len(clippings))
+	attached := make(map[int]bool)
+
+	for i, note := range clippings {
+		if note.Kind != KindNote {
+			continue
+		}
+		for j := range clippings {
+			highlight := &clippings[j]
+			if highlight.Kind == KindHighlight && highlight.Note == "" && highlight.Title == note.Title && sameSpot(*highlight, note) {
+				highlight.Note = note.Note
+				attached[i] = true
+				break
+			}
+		}
+	}
+
+	for i, clipping := range clippings {
+		if !attached[i] {
+			result = append(result, clipping)
+		}
+	}
+
+	return result
+}
+
+// sameSpot reports whether the note was made on the highlight. Kindle places
+// a note at the last location of the range; PDFs only have pages.
+func sameSpot(highlight, note Highlight) bool {
+	if highlight.Location == "" || note.Location == "" {
+		return highlight.Location == note.Location && highlight.Page > 0 && highlight.Page == note.Page
+	}
+
+	first, last, found := strings.Cut(highlight.Location, "-")
+	if !found {
+		last = first
+	}
+	start, _ := strconv.Atoi(first)
+	end, _ := strconv.Atoi(last)
+	at, _ := strconv.Atoi(note.Location)
+
+	return at >= start && at <= end
+}
+
+func containsAny(s string, words []string) bool {
+	for _, word := range words {
+		if strings.Contains(s, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,217 @@
+package highlights
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// koreaderDateTime is how KOReader stamps annotations in sidecar files.
+const koreaderDateTime = "2006-01-02 15:04:05"
+
+type koreaderExport struct {
+	koreaderDocument
+	Documents []koreaderDocument `json:"documents"`
+}
+
+type koreaderDocument struct {
+	Title   string          `json:"title"`
+	Author  string          `json:"author"`
+	Entries []koreaderEntry `json:"entries"`
+}
+
+type koreaderEntry struct {
+	Text    string `json:"text"`
+	Note    string `json:"note"`
+	Chapter string `json:"chapter"`
+	Page    any    `json:"page"`
+	Time    int64  `json:"time"`
+}
+
+// ParseKOReader reads either the JSON written by the KOReader highlight
+// exporter, for one book or several, or a metadata.*.lua sidecar file.
+func ParseKOReader(r io.Reader) ([]Highlight, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	if bytes.HasPrefix(data, []byte("{")) {
+		return parseKOReaderJSON(data)
+	}
+
+	return parseKOReaderSidecar(data)
+}
+
+func parseKOReaderJSON(data []byte) ([]Highlight, error) {
+	var export koreaderExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	documents := export.Documents
+	if len(documents) == 0 {
+		documents = []koreaderDocument{export.koreaderDocument}
+	}
+
+	result := make([]Highlight, 0)
+	for _, document := range documents {
+		for _, entry := range document.Entries {
+			text := strings.TrimSpace(entry.Text)
+			if text == "" {
+				continue
+			}
+
+			highlight := Highlight{
+				Title:   strings.TrimSpace(document.Title),
+				Author:  strings.TrimSpace(document.Author),
+				Kind:    KindHighlight,
+				Text:    text,
+				Note:    strings.TrimSpace(entry.Note),
+				Chapter: strings.TrimSpace(entry.Chapter),
+				Page:    koreaderPage(entry.Page),
+			}
+			if entry.Time > 0 {
+				createdAt := time.Unix(entry.Time, 0).UTC()
+				highlight.CreatedAt = &createdAt
+			}
+			result = append(result, highlight)
+		}
+	}
+
+	return result, nil
+}
+
+// parseKOReaderSidecar understands both the annotations list of current
+// KOReader versions and the per-page highlight table of older ones.
+func parseKOReaderSidecar(data []byte) ([]Highlight, error) {
+	value, err := parseLua(data)
+	if err != nil {
+		return nil, err
+	}
+	sidecar, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: sidecar is not a table", ErrMalformed)
+	}
+
+	title, author := koreaderProps(sidecar)
+	result := make([]Highlight, 0)
+
+	add := func(annotation map[string]any) {
+		text := strings.TrimSpace(luaString(annotation["text"]))
+		if text == "" {
+			return
+		}
+
+		highlight := Highlight{
+			Title:   title,
+			Author:  author,
+			Kind:    KindHighlight,
+			Text:    text,
+			Note:    strings.TrimSpace(luaString(annotation["note"])),
+			Chapter: strings.TrimSpace(luaString(annotation["chapter"])),
+			Page:    koreaderPage(annotation["pageno"]),
+		}
+		if highlight.Page == 0 {
+			highlight.Page = koreaderPage(annotation["page"])
+		}
+		if createdAt, err := time.Parse(koreaderDateTime, luaString(annotation["datetime"])); err == nil {
+			highlight.CreatedAt = &createdAt
+		}
+		result = append(result, highlight)
+	}
+
+	if annotations, ok := sidecar["annotations"].(map[string]any); ok {
+		for _, key := range luaIndexes(annotations) {
+			if annotation, ok := annotations[key].(map[string]any); ok {
+				add(annotation)
+			}
+		}
+		return result, nil
+	}
+
+	if pages, ok := sidecar["highlight"].(map[string]any); ok {
+		for _, page := range luaIndexes(pages) {
+			items, ok := pages[page].(map[string]any)
+			if !ok {
+				continue
+			}
+			for _, key := range luaIndexes(items) {
+				if item, ok := items[key].(map[string]any); ok {
+					if _, ok := item["pageno"]; !ok {
+						item["pageno"], _ = strconv.ParseFloat(page, 64)
+					}
+					add(item)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func koreaderProps(sidecar map[string]any) (string, string) {
+	for _, name := range []string{"doc_props", "stats"} {
+		props, ok := sidecar[name].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		title := strings.TrimSpace(luaString(props["title"]))
+		// Several authors are stored one per line.
+		var authors []string
+		for _, author := range strings.Split(luaString(props["authors"]), "\n") {
+			if author = strings.TrimSpace(author); author != "" {
+				authors = append(authors, author)
+			}
+		}
+		if title != "" {
+			return title, strings.Join(authors, ", ")
+		}
+	}
+
+	return "", ""
+}
+
+// koreaderPage returns the page number, ignoring the XPointer strings that
+// reflowable documents use in place of one.
+func koreaderPage(value any) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	default:
+		return 0
+	}
+}
+
+func luaString(value any) string {
+	s, _ := value.(string)
+	return s
+}
+
+// luaIndexes returns the numeric keys of a Lua table in ascending order.
+func luaIndexes(table map[string]any) []string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		if _, err := strconv.Atoi(key); err == nil {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.Atoi(keys[i])
+		b, _ := strconv.Atoi(keys[j])
+		return a < b
+	})
+
+	return keys
+}
@@ -0,0 +1,350 @@
+package highlights
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// luaMaxDepth bounds table nesting so a hostile file cannot exhaust the stack.
+const luaMaxDepth = 64
+
+// parseLua reads the subset of Lua that KOReader writes its sidecar files
+// in: a single returned table literal of strings, numbers and booleans.
+// Tables become map[string]any with positional items keyed "1", "2" and so
+// on, numbers become float64.
+func parseLua(data []byte) (any, error) {
+	p := &luaParser{s: string(data)}
+
+	p.skip()
+	if strings.HasPrefix(p.s[p.pos:], "return") {
+		p.pos += len("return")
+	}
+
+	value, err := p.value(0)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skip()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected trailing input")
+	}
+
+	return value, nil
+}
+
+type luaParser struct {
+	s   string
+	pos int
+}
+
+func (p *luaParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: offset %d: %s", ErrMalformed, p.pos, fmt.Sprintf(format, args...))
+}
+
+// skip moves past whitespace and comments.
+func (p *luaParser) skip() {
+	for p.pos < len(p.s) {
+		switch {
+		case strings.ContainsRune(" \t\r\n\f\v", rune(p.s[p.pos])):
+			p.pos++
+		case strings.HasPrefix(p.s[p.pos:], "--"):
+			p.pos += 2
+			if level := p.longBracket(); level >= 0 {
+				p.longString(level)
+				continue
+			}
+			if end := strings.IndexByte(p.s[p.pos:], '\n'); end >= 0 {
+				p.pos += end + 1
+			} else {
+				p.pos = len(p.s)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *luaParser) value(depth int) (any, error) {
+	p.skip()
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	c := p.s[p.pos]
+	switch {
+	case c == '{':
+		if depth >= luaMaxDepth {
+			return nil, p.errorf("tables are nested too deep")
+		}
+		return p.table(depth + 1)
+	case c == '"' || c == '\'':
+		return p.quoted()
+	case c == '[':
+		level := p.longBracket()
+		if level < 0 {
+			return nil, p.errorf("unexpected '['")
+		}
+		return p.longString(level)
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	}
+
+	switch name := p.name(); name {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "nil":
+		return nil, nil
+	default:
+		return nil, p.errorf("unexpected %q", name)
+	}
+}
+
+func (p *luaParser) table(depth int) (map[string]any, error) {
+	p.pos++
+	table := make(map[string]any)
+	index := 0
+
+	for {
+		p.skip()
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("unterminated table")
+		}
+		if p.s[p.pos] == '}' {
+			p.pos++
+			return table, nil
+		}
+
+		var key string
+		switch start := p.pos; {
+		case p.s[p.pos] == '[' && p.longBracket() < 0:
+			p.pos++
+			k, err := p.value(depth)
+			if err != nil {
+				return nil, err
+			}
+			p.skip()
+			if !p.consume(']') {
+				return nil, p.errorf("expected ']'")
+			}
+			p.skip()
+			if !p.consume('=') {
+				return nil, p.errorf("expected '='")
+			}
+			key = luaKey(k)
+		default:
+			if name := p.name(); name != "" {
+				p.skip()
+				if p.consume('=') && !p.consume('=') {
+					key = name
+					break
+				}
+			}
+			p.pos = start
+			index++
+			key = strconv.Itoa(index)
+		}
+
+		v, err := p.value(depth)
+		if err != nil {
+			return nil, err
+		}
+		table[key] = v
+
+		p.skip()
+		if !p.consume(',') && !p.consume(';') {
+			p.skip()
+			if p.pos < len(p.s) && p.s[p.pos] != '}' {
+				return nil, p.errorf("expected ',' or '}'")
+			}
+		}
+	}
+}
+
+func (p *luaParser) consume(c byte) bool {
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *luaParser) name() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(p.pos > start && c >= '0' && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *luaParser) number() (float64, error) {
+	start := p.pos
+	if p.s[p.pos] == '-' {
+		p.pos++
+		p.skip()
+	}
+	digits := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("0123456789abcdefABCDEFxX.+-pP", p.s[p.pos]) >= 0 {
+		// A sign only belongs to the number right after an exponent.
+		if c := p.s[p.pos]; (c == '+' || c == '-') && !strings.ContainsRune("eEpP", rune(p.s[p.pos-1])) {
+			break
+		}
+		p.pos++
+	}
+
+	literal := p.s[digits:p.pos]
+	sign := 1.0
+	if p.s[start] == '-' {
+		sign = -1
+	}
+
+	if strings.HasPrefix(literal, "0x") || strings.HasPrefix(literal, "0X") {
+		if n, err := strconv.ParseInt(literal[2:], 16, 64); err == nil {
+			return sign * float64(n), nil
+		}
+	}
+	n, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %q", literal)
+	}
+
+	return sign * n, nil
+}
+
+func (p *luaParser) quoted() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+
+		switch c {
+		case quote:
+			return b.String(), nil
+		case '\n':
+			return "", p.errorf("unterminated string")
+		case '\\':
+		default:
+			b.WriteByte(c)
+			continue
+		}
+
+		if p.pos >= len(p.s) {
+			break
+		}
+		e := p.s[p.pos]
+		p.pos++
+
+		switch e {
+		case 'n', '\n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case 'z':
+			for p.pos < len(p.s) && strings.ContainsRune(" \t\r\n\f\v", rune(p.s[p.pos])) {
+				p.pos++
+			}
+		case 'x':
+			if p.pos+2 > len(p.s) {
+				return "", p.errorf("invalid escape")
+			}
+			n, err := strconv.ParseUint(p.s[p.pos:p.pos+2], 16, 8)
+			if err != nil {
+				return "", p.errorf("invalid escape")
+			}
+			b.WriteByte(byte(n))
+			p.pos += 2
+		case 'u':
+			end := strings.IndexByte(p.s[p.pos:], '}')
+			if !strings.HasPrefix(p.s[p.pos:], "{") || end < 0 {
+				return "", p.errorf("invalid escape")
+			}
+			n, err := strconv.ParseUint(p.s[p.pos+1:p.pos+end], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(n)) {
+				return "", p.errorf("invalid escape")
+			}
+			b.WriteRune(rune(n))
+			p.pos += end + 1
+		default:
+			if e >= '0' && e <= '9' {
+				end := p.pos - 1
+				for end < len(p.s) && end < p.pos+2 && p.s[end] >= '0' && p.s[end] <= '9' {
+					end++
+				}
+				n, err := strconv.Atoi(p.s[p.pos-1 : end])
+				if err != nil || n > 255 {
+					return "", p.errorf("invalid escape")
+				}
+				b.WriteByte(byte(n))
+				p.pos = end
+				continue
+			}
+			b.WriteByte(e)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+// longBracket returns the level of a [[ or [==[ opening at the current
+// position and moves past it, or returns -1 and stays put.
+func (p *luaParser) longBracket() int {
+	if p.pos >= len(p.s) || p.s[p.pos] != '[' {
+		return -1
+	}
+	level := 0
+	for p.pos+1+level < len(p.s) && p.s[p.pos+1+level] == '=' {
+		level++
+	}
+	if p.pos+1+level >= len(p.s) || p.s[p.pos+1+level] != '[' {
+		return -1
+	}
+	p.pos += level + 2
+	return level
+}
+
+func (p *luaParser) longString(level int) (string, error) {
+	closing := "]" + strings.Repeat("=", level) + "]"
+	end := strings.Index(p.s[p.pos:], closing)
+	if end < 0 {
+		p.pos = len(p.s)
+		return "", p.errorf("unterminated long string")
+	}
+
+	value := strings.TrimPrefix(p.s[p.pos:p.pos+end], "\n")
+	p.pos += end + len(closing)
+	return value, nil
+}
+
+func luaKey(key any) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case float64:
+		return strconv.FormatFloat(k, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(k)
+	default:
+		return ""
+	}
+}
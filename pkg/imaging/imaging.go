@@ -0,0 +1,101 @@
+// Package imaging decodes user uploaded images and produces resized JPEG
+// copies of them. Re-encoding drops every metadata block of the original
+// (EXIF, XMP, ICC...), the EXIF orientation is applied to the pixels first.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels bounds the decoded size so a small but hostile file cannot
+// allocate gigabytes of memory.
+const MaxPixels = 40_000_000
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image dimensions are too large")
+)
+
+var contentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Sniff detects the content type from the leading bytes of data and
+// returns ErrUnsupportedFormat for anything but JPEG, PNG, GIF and WebP.
+// The name the client sent and its declared content type are never trusted.
+func Sniff(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !contentTypes[contentType] {
+		return "", ErrUnsupportedFormat
+	}
+	return contentType, nil
+}
+
+// Decode decodes data and rotates the result according to its EXIF
+// orientation.
+func Decode(data []byte) (image.Image, error) {
+	if _, err := Sniff(data); err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	return orient(img, exifOrientation(data)), nil
+}
+
+// Fit scales img down to fit into width x height keeping the aspect ratio.
+// Images that already fit are returned as they are.
+func Fit(img image.Image, width, height int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= width && h <= height {
+		return img
+	}
+
+	if w*height > h*width {
+		h = max(1, h*width/w)
+		w = width
+	} else {
+		w = max(1, w*height/h)
+		h = height
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// EncodeJPEG writes img as a baseline JPEG. Transparent areas become white
+// instead of JPEG's default black.
+func EncodeJPEG(w io.Writer, img image.Image, quality int) error {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+
+	return jpeg.Encode(w, flat, &jpeg.Options{Quality: quality})
+}
@@ -0,0 +1,162 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testImage is a 40x20 image, red on the left half and blue on the right.
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			if x < 20 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// jpegWithOrientation encodes img as JPEG and inserts an EXIF segment with
+// the given orientation right after the SOI marker.
+func jpegWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	data := buf.Bytes()
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func isBlue(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return b > 0xC000 && r < 0x4000 && g < 0x4000
+}
+
+func TestSniff(t *testing.T) {
+	contentType, err := Sniff(encodePNG(t, testImage()))
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+
+	_, err = Sniff([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+	assert.Equal(t, ErrUnsupportedFormat, err)
+
+	_, err = Sniff([]byte("%PDF-1.7"))
+	assert.Equal(t, ErrUnsupportedFormat, err)
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("applies exif orientation", func(t *testing.T) {
+		img, err := Decode(jpegWithOrientation(t, testImage(), 6))
+		require.NoError(t, err)
+
+		assert.Equal(t, image.Rect(0, 0, 20, 40), img.Bounds())
+		// Rotated clockwise: the left (red) half ends up on top.
+		assert.True(t, isRed(img.At(10, 5)))
+		assert.True(t, isBlue(img.At(10, 35)))
+	})
+
+	t.Run("without exif", func(t *testing.T) {
+		img, err := Decode(encodePNG(t, testImage()))
+		require.NoError(t, err)
+
+		assert.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+		assert.True(t, isRed(img.At(5, 10)))
+	})
+
+	t.Run("truncated image", func(t *testing.T) {
+		data := encodePNG(t, testImage())
+
+		_, err := Decode(data[:len(data)/2])
+		assert.Equal(t, ErrUnsupportedFormat, err)
+	})
+
+	t.Run("too many pixels", func(t *testing.T) {
+		// A valid PNG header claiming 100000x100000 pixels is enough,
+		// Decode must give up before allocating anything.
+		data := encodePNG(t, image.NewGray(image.Rect(0, 0, 1, 1)))
+		binary.BigEndian.PutUint32(data[16:], 100000)
+		binary.BigEndian.PutUint32(data[20:], 100000)
+		binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+		_, err := Decode(data)
+		assert.Equal(t, ErrTooLarge, err)
+	})
+}
+
+func TestExifOrientation(t *testing.T) {
+	for orientation := uint16(1); orientation <= 8; orientation++ {
+		assert.Equal(t, int(orientation), exifOrientation(jpegWithOrientation(t, testImage(), orientation)))
+	}
+
+	assert.Equal(t, 1, exifOrientation(encodePNG(t, testImage())))
+	assert.Equal(t, 1, exifOrientation([]byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF}))
+}
+
+func TestFit(t *testing.T) {
+	img := testImage()
+
+	assert.Equal(t, image.Rect(0, 0, 20, 10), Fit(img, 20, 30).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 10, 5), Fit(img, 100, 5).Bounds())
+	assert.Same(t, img, Fit(img, 40, 20))
+}
+
+func TestEncodeJPEGStripsMetadata(t *testing.T) {
+	img, err := Decode(jpegWithOrientation(t, testImage(), 3))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, EncodeJPEG(&buf, img, 85))
+
+	assert.NotContains(t, buf.String(), "Exif")
+	assert.Equal(t, 1, exifOrientation(buf.Bytes()))
+
+	decoded, err := jpeg.Decode(&buf)
+	require.NoError(t, err)
+	assert.True(t, isBlue(decoded.At(5, 10)))
+}
+
+func TestEncodeJPEGFlattensTransparency(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeJPEG(&buf, image.NewNRGBA(image.Rect(0, 0, 4, 4)), 85))
+
+	decoded, err := jpeg.Decode(&buf)
+	require.NoError(t, err)
+	r, g, b, _ := decoded.At(1, 1).RGBA()
+	assert.True(t, r > 0xF000 && g > 0xF000 && b > 0xF000)
+}
@@ -0,0 +1,123 @@
+package isbn
+
+import "strings"
+
+// Normalize strips spaces and hyphens and upper-cases the ISBN-10 check
+// character. It returns an empty string if the result is not a valid ISBN-10
+// or ISBN-13.
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == 'x' || r == 'X':
+			b.WriteRune('X')
+		case r == '-' || r == ' ':
+		default:
+			return ""
+		}
+	}
+
+	normalized := b.String()
+	if !IsValid(normalized) {
+		return ""
+	}
+	return normalized
+}
+
+// IsValid reports whether s is a normalized ISBN-10 or ISBN-13 with a correct
+// check digit.
+func IsValid(s string) bool {
+	switch len(s) {
+	case 10:
+		return isValid10(s)
+	case 13:
+		return isValid13(s)
+	default:
+		return false
+	}
+}
+
+// To13 converts a valid ISBN-10 to its ISBN-13 form. ISBN-13 values are
+// returned unchanged and anything else yields an empty string.
+func To13(s string) string {
+	s = Normalize(s)
+	switch len(s) {
+	case 13:
+		return s
+	case 10:
+		body := "978" + s[:9]
+		return body + string(checkDigit13(body))
+	default:
+		return ""
+	}
+}
+
+// To10 converts a valid ISBN-13 with the 978 prefix to its ISBN-10 form.
+// ISBN-10 values are returned unchanged. 979 ISBNs have no ISBN-10 form, so
+// they and anything else yield an empty string.
+func To10(s string) string {
+	s = Normalize(s)
+	switch {
+	case len(s) == 10:
+		return s
+	case len(s) == 13 && strings.HasPrefix(s, "978"):
+		body := s[3:12]
+		return body + string(checkDigit10(body))
+	default:
+		return ""
+	}
+}
+
+func isValid10(s string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		c := s[i]
+		var v int
+		switch {
+		case c >= '0' && c <= '9':
+			v = int(c - '0')
+		case c == 'X' && i == 9:
+			v = 10
+		default:
+			return false
+		}
+		sum += v * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+func isValid13(s string) bool {
+	for i := 0; i < 13; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return checkDigit13(s[:12]) == s[12]
+}
+
+func checkDigit10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	switch check := (11 - sum%11) % 11; check {
+	case 10:
+		return 'X'
+	default:
+		return byte('0' + check)
+	}
+}
+
+func checkDigit13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		v := int(body[i] - '0')
+		if i%2 == 1 {
+			v *= 3
+		}
+		sum += v
+	}
+	return byte('0' + (10-sum%10)%10)
+}
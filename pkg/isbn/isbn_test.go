@@ -0,0 +1,67 @@
+package isbn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "isbn-13 with hyphens", input: "978-0-441-01359-3", expected: "9780441013593"},
+		{name: "isbn-10 with spaces", input: "0 441 01359 7", expected: "0441013597"},
+		{name: "lowercase check character", input: "080442957x", expected: "080442957X"},
+		{name: "wrong check digit", input: "9780441013594", expected: ""},
+		{name: "letters", input: "97804410135ab", expected: ""},
+		{name: "empty", input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Normalize(tt.input))
+		})
+	}
+}
+
+func TestTo13(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "isbn-10", input: "0441013597", expected: "9780441013593"},
+		{name: "isbn-10 with X check character", input: "080442957X", expected: "9780804429573"},
+		{name: "isbn-13 unchanged", input: "9785170987658", expected: "9785170987658"},
+		{name: "invalid", input: "123", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, To13(tt.input))
+		})
+	}
+}
+
+func TestTo10(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "isbn-13", input: "9780441013593", expected: "0441013597"},
+		{name: "isbn-13 with X check character", input: "978-0-8044-2957-3", expected: "080442957X"},
+		{name: "isbn-10 unchanged", input: "517098765X", expected: "517098765X"},
+		{name: "979 prefix has no isbn-10", input: "9791032305690", expected: ""},
+		{name: "invalid", input: "9780441013594", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, To10(tt.input))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"home-library/pkg/config"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultConcurrency  = 4
+	defaultPollInterval = time.Second
+
+	// recordTimeout bounds writing a job's result, which happens even when
+	// the pool is being stopped.
+	recordTimeout = 10 * time.Second
+)
+
+// Handler runs one attempt of a job. The context is cancelled when the job
+// times out or the pool is stopped for good.
+type Handler func(ctx context.Context, job *Job) error
+
+// store is the part of Queue the pool works with.
+type store interface {
+	claim(ctx context.Context, kinds []string) (*Job, error)
+	complete(ctx context.Context, job *Job) error
+	fail(ctx context.Context, job *Job, cause error) error
+}
+
+// Pool runs jobs of the registered kinds with a fixed number of workers.
+type Pool struct {
+	store        store
+	handlers     map[string]Handler
+	concurrency  int
+	pollInterval time.Duration
+
+	mu       sync.Mutex
+	started  bool
+	stopping chan struct{}
+	// abort cancels the contexts of running jobs when a drain runs out of
+	// time.
+	ctx     context.Context
+	abort   context.CancelFunc
+	running sync.WaitGroup
+}
+
+func NewPool(queue *Queue, cfg config.JobsConfig) *Pool {
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	pollInterval := time.Duration(cfg.PollInterval) * time.Second
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	return newPool(queue, concurrency, pollInterval)
+}
+
+func newPool(s store, concurrency int, pollInterval time.Duration) *Pool {
+	ctx, abort := context.WithCancel(context.Background())
+	return &Pool{
+		store:        s,
+		handlers:     make(map[string]Handler),
+		concurrency:  concurrency,
+		pollInterval: pollInterval,
+		stopping:     make(chan struct{}),
+		ctx:          ctx,
+		abort:        abort,
+	}
+}
+
+// Handle registers the handler of a job kind. Handlers must be registered
+// before Start; the pool only claims jobs it has a handler for.
+func (p *Pool) Handle(kind string, handler Handler) {
+	p.handlers[kind] = handler
+}
+
+func (p *Pool) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started || len(p.handlers) == 0 {
+		return
+	}
+	p.started = true
+
+	kinds := make([]string, 0, len(p.handlers))
+	for kind := range p.handlers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for i := 0; i < p.concurrency; i++ {
+		p.running.Add(1)
+		go p.work(kinds)
+	}
+}
+
+// Stop drains the pool: workers take no new jobs and the running ones may
+// finish until ctx is done. Jobs still running then are cancelled and
+// recorded as failed, so they are retried later.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	select {
+	case <-p.stopping:
+	default:
+		close(p.stopping)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.abort()
+		return nil
+	case <-ctx.Done():
+		p.abort()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) work(kinds []string) {
+	defer p.running.Done()
+
+	for {
+		select {
+		case <-p.stopping:
+			return
+		default:
+		}
+
+		job, err := p.store.claim(p.ctx, kinds)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to claim job")
+		}
+		if job == nil {
+			select {
+			case <-p.stopping:
+				return
+			case <-time.After(p.pollInterval):
+			}
+			continue
+		}
+
+		p.run(job)
+	}
+}
+
+func (p *Pool) run(job *Job) {
+	logger := log.With().Str("job_id", job.JobID.String()).Str("kind", job.Kind).Int("attempt", job.Attempts).Logger()
+
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		// A worker died holding the job on its last attempt.
+		err = errors.New("job lease expired")
+	} else {
+		ctx, cancel := context.WithTimeout(p.ctx, job.Timeout())
+		err = call(ctx, p.handlers[job.Kind], job)
+		cancel()
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(p.ctx), recordTimeout)
+	defer cancel()
+
+	if err == nil {
+		if err := p.store.complete(ctx, job); err != nil {
+			logger.Error().Err(err).Msg("failed to record completed job")
+		}
+		return
+	}
+
+	logger.Warn().Err(err).Msg("job failed")
+	if err := p.store.fail(ctx, job, err); err != nil {
+		logger.Error().Err(err).Msg("failed to record failed job")
+	}
+}
+
+// call turns a panic into an error, so a broken handler costs one attempt
+// and not the worker.
+func call(ctx context.Context, handler Handler, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return handler(ctx, job)
+}
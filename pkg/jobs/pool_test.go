@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore hands out the jobs it was given and records the outcomes.
+type memoryStore struct {
+	mu        sync.Mutex
+	pending   []*Job
+	completed []uuid.UUID
+	failed    map[uuid.UUID]string
+}
+
+func newMemoryStore(jobs ...*Job) *memoryStore {
+	return &memoryStore{pending: jobs, failed: make(map[uuid.UUID]string)}
+}
+
+func (s *memoryStore) claim(ctx context.Context, kinds []string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, job := range s.pending {
+		for _, kind := range kinds {
+			if job.Kind == kind {
+				s.pending = append(s.pending[:i], s.pending[i+1:]...)
+				job.Attempts++
+				return job, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) complete(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed = append(s.completed, job.JobID)
+	return nil
+}
+
+func (s *memoryStore) fail(ctx context.Context, job *Job, cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[job.JobID] = cause.Error()
+	return nil
+}
+
+func (s *memoryStore) outcomes() (int, map[uuid.UUID]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	failed := make(map[uuid.UUID]string, len(s.failed))
+	for id, cause := range s.failed {
+		failed[id] = cause
+	}
+	return len(s.completed), failed
+}
+
+func newJob(kind string) *Job {
+	return &Job{JobID: uuid.New(), Kind: kind, MaxAttempts: 3, TimeoutSeconds: 60}
+}
+
+func TestPool(t *testing.T) {
+	t.Run("runs jobs and records the results", func(t *testing.T) {
+		ok, broken, panicky, foreign := newJob("ok"), newJob("broken"), newJob("panicky"), newJob("foreign")
+		expired := newJob("ok")
+		expired.Attempts = expired.MaxAttempts
+		store := newMemoryStore(ok, broken, panicky, foreign, expired)
+
+		pool := newPool(store, 2, time.Millisecond)
+		pool.Handle("ok", func(ctx context.Context, job *Job) error { return nil })
+		pool.Handle("broken", func(ctx context.Context, job *Job) error { return errors.New("boom") })
+		pool.Handle("panicky", func(ctx context.Context, job *Job) error { panic("oops") })
+		pool.Start()
+
+		assert.Eventually(t, func() bool {
+			completed, failed := store.outcomes()
+			return completed == 1 && len(failed) == 3
+		}, time.Second, time.Millisecond)
+		require.NoError(t, pool.Stop(context.Background()))
+
+		_, failed := store.outcomes()
+		assert.Equal(t, "boom", failed[broken.JobID])
+		assert.Equal(t, "panic: oops", failed[panicky.JobID])
+		assert.Equal(t, "job lease expired", failed[expired.JobID])
+		assert.Len(t, store.pending, 1, "jobs without a handler stay in the queue")
+	})
+
+	t.Run("times out long jobs", func(t *testing.T) {
+		slow := newJob("slow")
+		slow.TimeoutSeconds = 0
+		store := newMemoryStore(slow)
+
+		pool := newPool(store, 1, time.Millisecond)
+		pool.Handle("slow", func(ctx context.Context, job *Job) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		pool.Start()
+
+		assert.Eventually(t, func() bool {
+			_, failed := store.outcomes()
+			return failed[slow.JobID] == context.DeadlineExceeded.Error()
+		}, time.Second, time.Millisecond)
+		require.NoError(t, pool.Stop(context.Background()))
+	})
+
+	t.Run("drains running jobs on stop", func(t *testing.T) {
+		job := newJob("slow")
+		store := newMemoryStore(job)
+		started := make(chan struct{})
+
+		pool := newPool(store, 1, time.Millisecond)
+		pool.Handle("slow", func(ctx context.Context, job *Job) error {
+			close(started)
+			time.Sleep(10 * time.Millisecond)
+			return ctx.Err()
+		})
+		pool.Start()
+		<-started
+
+		require.NoError(t, pool.Stop(context.Background()))
+		completed, _ := store.outcomes()
+		assert.Equal(t, 1, completed)
+	})
+
+	t.Run("cancels running jobs when the drain times out", func(t *testing.T) {
+		job := newJob("stuck")
+		store := newMemoryStore(job)
+		started := make(chan struct{})
+
+		pool := newPool(store, 1, time.Millisecond)
+		pool.Handle("stuck", func(ctx context.Context, job *Job) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		pool.Start()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, pool.Stop(ctx), context.DeadlineExceeded)
+
+		_, failed := store.outcomes()
+		assert.Equal(t, context.Canceled.Error(), failed[job.JobID])
+	})
+
+	t.Run("stop without handlers", func(t *testing.T) {
+		pool := newPool(newMemoryStore(), 1, time.Millisecond)
+		pool.Start()
+		assert.NoError(t, pool.Stop(context.Background()))
+	})
+}
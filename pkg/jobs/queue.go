@@ -0,0 +1,261 @@
+// Package jobs is a background job queue kept in the application database.
+// Workers claim jobs with SELECT ... FOR UPDATE SKIP LOCKED, so any number
+// of them, in one process or several, share the queue without handing the
+// same job out twice. Failed jobs are retried with exponential backoff and
+// end up dead once their attempts run out.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"home-library/pkg/transaction"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusDead    Status = "dead"
+)
+
+const (
+	DefaultMaxAttempts = 5
+	DefaultTimeout     = 5 * time.Minute
+
+	// leaseGrace is added to a job's timeout for its lease, so a worker that
+	// is slow to record the result does not see the job reclaimed under it.
+	leaseGrace = 30 * time.Second
+
+	backoffBase = 10 * time.Second
+	backoffMax  = time.Hour
+)
+
+type Job struct {
+	JobID          uuid.UUID       `db:"job_id"`
+	Kind           string          `db:"kind"`
+	Payload        json.RawMessage `db:"payload"`
+	Status         Status          `db:"status"`
+	Attempts       int             `db:"attempts"`
+	MaxAttempts    int             `db:"max_attempts"`
+	TimeoutSeconds int             `db:"timeout_seconds"`
+	RunAt          time.Time       `db:"run_at"`
+	LockedUntil    *time.Time      `db:"locked_until"`
+	LastError      string          `db:"last_error"`
+	CreatedAt      time.Time       `db:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at"`
+}
+
+// Timeout is how long one attempt of the job may run.
+func (j *Job) Timeout() time.Duration {
+	return time.Duration(j.TimeoutSeconds) * time.Second
+}
+
+// Decode unmarshals the payload into v.
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// Options tune a single job. Zero fields take the defaults: run now, five
+// attempts, five minutes per attempt.
+type Options struct {
+	RunAt       time.Time
+	MaxAttempts int
+	Timeout     time.Duration
+}
+
+// Backoff returns the delay before the retry that follows the given attempt:
+// ten seconds after the first, doubling up to an hour.
+func Backoff(attempt int) time.Duration {
+	delay := backoffBase
+	for i := 1; i < attempt && delay < backoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, backoffMax)
+}
+
+// Queue runs its queries in the transaction carried by the context, if any,
+// so a job enqueued within a unit of work only exists if it commits.
+type Queue struct {
+	db *transaction.DB
+}
+
+func NewQueue(db *sqlx.DB) *Queue {
+	return &Queue{db: transaction.Wrap(db)}
+}
+
+// Enqueue adds a job of the given kind with payload marshalled to JSON.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload any, opts Options) (uuid.UUID, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("marshal %s job payload: %w", kind, err)
+	}
+
+	if opts.RunAt.IsZero() {
+		opts.RunAt = time.Now()
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	// The timeout is stored in whole seconds, rounded up so that a timeout
+	// under a second does not become none.
+	timeoutSeconds := int((opts.Timeout + time.Second - 1) / time.Second)
+
+	jobID := uuid.New()
+	query := `
+		INSERT INTO jobs (job_id, kind, payload, max_attempts, timeout_seconds, run_at)
+		VALUES ($1, $2, $3::jsonb, $4, $5, $6)
+	`
+
+	// The payload goes as text: lib/pq would send a byte slice as bytea.
+	_, err = q.db.ExecContext(ctx, query, jobID, kind, string(data), opts.MaxAttempts, timeoutSeconds, opts.RunAt)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return jobID, nil
+}
+
+// claim takes the next due job of one of the kinds and leases it for its
+// timeout. Running jobs whose lease ran out, because their worker died, are
+// due again. It returns nil when there is nothing to do.
+func (q *Queue) claim(ctx context.Context, kinds []string) (*Job, error) {
+	var job Job
+	query := `
+		UPDATE jobs
+		SET status = 'running',
+			attempts = attempts + 1,
+			locked_until = NOW() + make_interval(secs => timeout_seconds + $2),
+			updated_at = NOW()
+		WHERE job_id = (
+			SELECT job_id FROM jobs
+			WHERE kind = ANY($1) AND (
+				(status = 'pending' AND run_at <= NOW()) OR
+				(status = 'running' AND locked_until < NOW())
+			)
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+
+	err := q.db.GetContext(ctx, &job, query, pq.StringArray(kinds), int(leaseGrace.Seconds()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (q *Queue) complete(ctx context.Context, job *Job) error {
+	query := `
+		UPDATE jobs
+		SET status = 'done', locked_until = NULL, last_error = '', updated_at = NOW()
+		WHERE job_id = $1 AND status = 'running'
+	`
+
+	_, err := q.db.ExecContext(ctx, query, job.JobID)
+	return err
+}
+
+// fail records the error and schedules a retry, or buries the job once it
+// has used up its attempts.
+func (q *Queue) fail(ctx context.Context, job *Job, cause error) error {
+	status, runAt := StatusPending, time.Now().Add(Backoff(job.Attempts))
+	if job.Attempts >= job.MaxAttempts {
+		status, runAt = StatusDead, job.RunAt
+	}
+
+	query := `
+		UPDATE jobs
+		SET status = $2, run_at = $3, locked_until = NULL, last_error = $4, updated_at = NOW()
+		WHERE job_id = $1 AND status = 'running'
+	`
+
+	_, err := q.db.ExecContext(ctx, query, job.JobID, status, runAt, cause.Error())
+	return err
+}
+
+// List returns jobs for inspection, the most recently updated first. An
+// empty status lists jobs of every status.
+func (q *Queue) List(ctx context.Context, status Status, limit int, offset int) ([]Job, error) {
+	jobs := make([]Job, 0)
+	query := `
+		SELECT * FROM jobs
+		WHERE $1 = '' OR status = $1
+		ORDER BY updated_at DESC, job_id
+		LIMIT $2 OFFSET $3
+	`
+
+	err := q.db.SelectContext(ctx, &jobs, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (q *Queue) Get(ctx context.Context, jobID uuid.UUID) (*Job, error) {
+	var job Job
+	query := `SELECT * FROM jobs WHERE job_id = $1`
+
+	err := q.db.GetContext(ctx, &job, query, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// Retry gives a dead job a fresh set of attempts, starting now. It returns
+// sql.ErrNoRows if there is no dead job with the ID.
+func (q *Queue) Retry(ctx context.Context, jobID uuid.UUID) error {
+	query := `
+		UPDATE jobs
+		SET status = 'pending', attempts = 0, run_at = NOW(), last_error = '', updated_at = NOW()
+		WHERE job_id = $1 AND status = 'dead'
+	`
+
+	result, err := q.db.ExecContext(ctx, query, jobID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Prune deletes finished jobs last updated before the cutoff and returns
+// how many it removed. Dead jobs stay until someone looks at them.
+func (q *Queue) Prune(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM jobs WHERE status = 'done' AND updated_at < $1`
+
+	result, err := q.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,212 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"home-library/pkg/transaction"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockQueue(t *testing.T) (*Queue, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewQueue(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+var jobColumns = []string{
+	"job_id", "kind", "payload", "status", "attempts", "max_attempts",
+	"timeout_seconds", "run_at", "locked_until", "last_error", "created_at", "updated_at",
+}
+
+func TestEnqueueJoinsTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	q := NewQueue(sqlxDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	err = transaction.NewManager(sqlxDB).Do(context.Background(), func(ctx context.Context) error {
+		if _, err := q.Enqueue(ctx, "thumbnail", nil, Options{}); err != nil {
+			return err
+		}
+		return errors.New("later step failed")
+	})
+
+	assert.EqualError(t, err, "later step failed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, Backoff(1))
+	assert.Equal(t, 20*time.Second, Backoff(2))
+	assert.Equal(t, 80*time.Second, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
+
+func TestEnqueue(t *testing.T) {
+	q, mock := newMockQueue(t)
+
+	t.Run("defaults", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), "thumbnail", `{"file_id":"42"}`, DefaultMaxAttempts, 300, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		jobID, err := q.Enqueue(context.Background(), "thumbnail", map[string]string{"file_id": "42"}, Options{})
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, uuid.Nil, jobID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("options", func(t *testing.T) {
+		runAt := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), "email", `null`, 2, 30, runAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := q.Enqueue(context.Background(), "email", nil, Options{RunAt: runAt, MaxAttempts: 2, Timeout: 30 * time.Second})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("timeout is rounded up to a second", func(t *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), "email", `null`, DefaultMaxAttempts, 1, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := q.Enqueue(context.Background(), "email", nil, Options{Timeout: 500 * time.Millisecond})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("payload that cannot be marshalled", func(t *testing.T) {
+		_, err := q.Enqueue(context.Background(), "email", make(chan int), Options{})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestClaim(t *testing.T) {
+	q, mock := newMockQueue(t)
+
+	t.Run("claims a job", func(t *testing.T) {
+		jobID := uuid.New()
+		now := time.Now()
+
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+			WithArgs(pq.StringArray{"email", "thumbnail"}, 30).
+			WillReturnRows(sqlmock.NewRows(jobColumns).
+				AddRow(jobID, "email", []byte(`{"to":"a@example.com"}`), "running", 1, 5, 60, now, now, "", now, now))
+
+		job, err := q.claim(context.Background(), []string{"email", "thumbnail"})
+
+		require.NoError(t, err)
+		assert.Equal(t, jobID, job.JobID)
+		assert.Equal(t, time.Minute, job.Timeout())
+
+		var payload struct{ To string }
+		assert.NoError(t, job.Decode(&payload))
+		assert.Equal(t, "a@example.com", payload.To)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing to do", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+			WillReturnRows(sqlmock.NewRows(jobColumns))
+
+		job, err := q.claim(context.Background(), []string{"email"})
+
+		assert.NoError(t, err)
+		assert.Nil(t, job)
+	})
+}
+
+func TestFail(t *testing.T) {
+	q, mock := newMockQueue(t)
+	runAt := time.Now().Add(-time.Minute)
+
+	t.Run("retries later", func(t *testing.T) {
+		job := &Job{JobID: uuid.New(), Attempts: 2, MaxAttempts: 5, RunAt: runAt}
+
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs")).
+			WithArgs(job.JobID, StatusPending, sqlmock.AnyArg(), "boom").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, q.fail(context.Background(), job, errors.New("boom")))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("buries after the last attempt", func(t *testing.T) {
+		job := &Job{JobID: uuid.New(), Attempts: 5, MaxAttempts: 5, RunAt: runAt}
+
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs")).
+			WithArgs(job.JobID, StatusDead, runAt, "boom").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, q.fail(context.Background(), job, errors.New("boom")))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRetry(t *testing.T) {
+	q, mock := newMockQueue(t)
+	jobID := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta("WHERE job_id = $1 AND status = 'dead'")).
+		WithArgs(jobID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, q.Retry(context.Background(), jobID))
+
+	mock.ExpectExec(regexp.QuoteMeta("WHERE job_id = $1 AND status = 'dead'")).
+		WithArgs(jobID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, q.Retry(context.Background(), jobID), sql.ErrNoRows)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListAndPrune(t *testing.T) {
+	q, mock := newMockQueue(t)
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM jobs")).
+		WithArgs(StatusDead, 10, 20).
+		WillReturnRows(sqlmock.NewRows(jobColumns).
+			AddRow(uuid.New(), "email", []byte(`{}`), "dead", 5, 5, 60, now, nil, "smtp down", now, now))
+
+	list, err := q.List(context.Background(), StatusDead, 10, 20)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "smtp down", list[0].LastError)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM jobs WHERE status = 'done'")).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	pruned, err := q.Prune(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), pruned)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const userIDKey = "user_id"
+
 type PayloadToken struct {
 	UserID uuid.UUID
 	jwt.StandardClaims
@@ -56,11 +58,13 @@ func (j *JWT) VerifyToken(c echo.Context, token string) error {
 		return fmt.Errorf("invalid token: %w", err)
 	}
 
-	if !newToken.Valid {
+	claims, ok := newToken.Claims.(*PayloadToken)
+	if !newToken.Valid || !ok {
 		return errors.New("invalid token")
 	}
 
 	c.Set("jwt", token)
+	c.Set(userIDKey, claims.UserID)
 
 	return nil
 }
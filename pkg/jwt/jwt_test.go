@@ -3,6 +3,8 @@ package jwt
 import (
 	"errors"
 	"home-library/pkg/config"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -105,6 +107,64 @@ func TestJWT_VerifyToken(t *testing.T) {
 			token, ok := c.Get("jwt").(string)
 			assert.True(t, ok)
 			assert.NotEmpty(t, token)
+
+			id, ok := UserIDFromContext(c)
+			assert.True(t, ok)
+			assert.Equal(t, userID, id)
 		})
 	}
 }
+
+func TestMiddleware(t *testing.T) {
+	cfg := config.JWTConfig{
+		Secret: "test-secret",
+	}
+	jwtService := NewJWT(cfg)
+	userID := uuid.New()
+
+	validToken, err := jwtService.GenerateToken(PayloadToken{UserID: userID})
+	require.NoError(t, err)
+
+	e := echo.New()
+	handler := Middleware(jwtService)(func(c echo.Context) error {
+		id, ok := UserIDFromContext(c)
+		require.True(t, ok)
+		return c.String(http.StatusOK, id.String())
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+validToken)
+		rec := httptest.NewRecorder()
+
+		err := handler(e.NewContext(req, rec))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, userID.String(), rec.Body.String())
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+
+		err := handler(e.NewContext(req, rec))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("token signed with another secret", func(t *testing.T) {
+		otherToken, err := NewJWT(config.JWTConfig{Secret: "other-secret"}).GenerateToken(PayloadToken{UserID: userID})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+otherToken)
+		rec := httptest.NewRecorder()
+
+		err = handler(e.NewContext(req, rec))
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
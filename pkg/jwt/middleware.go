@@ -0,0 +1,41 @@
+package jwt
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type unauthorizedResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Middleware rejects requests without a valid bearer token and stores the
+// authenticated user ID in the echo context.
+func Middleware(j JWTService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := j.VerifyToken(c, c.Request().Header.Get(echo.HeaderAuthorization)); err != nil {
+				return c.JSON(http.StatusUnauthorized, unauthorizedResponse{
+					Code:    http.StatusUnauthorized,
+					Message: "Требуется авторизация",
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// UserIDFromContext returns the user ID stored by Middleware.
+func UserIDFromContext(c echo.Context) (uuid.UUID, bool) {
+	userID, ok := c.Get(userIDKey).(uuid.UUID)
+	return userID, ok
+}
+
+// SetUserID stores a user authenticated by other means than a bearer token,
+// so handlers read it with UserIDFromContext either way.
+func SetUserID(c echo.Context, userID uuid.UUID) {
+	c.Set(userIDKey, userID)
+}
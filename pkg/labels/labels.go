@@ -0,0 +1,302 @@
+// Package labels lays out printable book labels, each with a QR code and a
+// short identifier, on sheets of self-adhesive labels and renders them to
+// PDF. Fonts are embedded, so the output does not depend on the viewer.
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+var (
+	ErrInvalidSheet = errors.New("label sheet does not fit on the page")
+	ErrUnknownSheet = errors.New("unknown label sheet")
+)
+
+// Sheet describes a sheet of labels. All sizes are in millimetres; margins
+// are measured to the first label and gaps between neighbouring labels.
+type Sheet struct {
+	Name        string
+	PageWidth   float64
+	PageHeight  float64
+	Columns     int
+	Rows        int
+	LabelWidth  float64
+	LabelHeight float64
+	MarginTop   float64
+	MarginLeft  float64
+	GapX        float64
+	GapY        float64
+}
+
+// Sheets are common label sheets by their manufacturer code.
+var Sheets = map[string]Sheet{
+	"avery-l7159": {
+		Name:      "Avery L7159, A4 3×8",
+		PageWidth: 210, PageHeight: 297,
+		Columns: 3, Rows: 8,
+		LabelWidth: 63.5, LabelHeight: 33.9,
+		MarginTop: 12.9, MarginLeft: 6.4,
+		GapX: 2.5,
+	},
+	"avery-l7160": {
+		Name:      "Avery L7160, A4 3×7",
+		PageWidth: 210, PageHeight: 297,
+		Columns: 3, Rows: 7,
+		LabelWidth: 63.5, LabelHeight: 38.1,
+		MarginTop: 15.15, MarginLeft: 7.2,
+		GapX: 2.5,
+	},
+	"avery-l7163": {
+		Name:      "Avery L7163, A4 2×7",
+		PageWidth: 210, PageHeight: 297,
+		Columns: 2, Rows: 7,
+		LabelWidth: 99.1, LabelHeight: 38.1,
+		MarginTop: 15.15, MarginLeft: 4.65,
+		GapX: 2.5,
+	},
+	"avery-5160": {
+		Name:      "Avery 5160, Letter 3×10",
+		PageWidth: 215.9, PageHeight: 279.4,
+		Columns: 3, Rows: 10,
+		LabelWidth: 66.675, LabelHeight: 25.4,
+		MarginTop: 12.7, MarginLeft: 4.7625,
+		GapX: 3.175,
+	},
+}
+
+// Lookup returns one of the predefined sheets.
+func Lookup(name string) (Sheet, error) {
+	sheet, ok := Sheets[name]
+	if !ok {
+		return Sheet{}, ErrUnknownSheet
+	}
+	return sheet, nil
+}
+
+// tolerance absorbs the rounding in manufacturers' published dimensions.
+const tolerance = 0.5
+
+// Validate checks that the labels fit on the page.
+func (s Sheet) Validate() error {
+	if s.Columns <= 0 || s.Rows <= 0 || s.LabelWidth <= 0 || s.LabelHeight <= 0 ||
+		s.MarginTop < 0 || s.MarginLeft < 0 || s.GapX < 0 || s.GapY < 0 {
+		return ErrInvalidSheet
+	}
+
+	width := s.MarginLeft + float64(s.Columns)*s.LabelWidth + float64(s.Columns-1)*s.GapX
+	height := s.MarginTop + float64(s.Rows)*s.LabelHeight + float64(s.Rows-1)*s.GapY
+	if width > s.PageWidth+tolerance || height > s.PageHeight+tolerance {
+		return ErrInvalidSheet
+	}
+
+	return nil
+}
+
+// PerPage returns the number of labels on one sheet.
+func (s Sheet) PerPage() int {
+	return s.Columns * s.Rows
+}
+
+// Label is the content of one label. URL is encoded in the QR code and may
+// be empty, in which case the text takes the whole label.
+type Label struct {
+	Code     string
+	Title    string
+	Subtitle string
+	URL      string
+}
+
+type Options struct {
+	// Skip leaves the first labels of the first sheet blank, so a partly
+	// used sheet can be fed through the printer again.
+	Skip int
+	// Outline draws the label borders, which helps to check the alignment
+	// on plain paper.
+	Outline bool
+}
+
+// padding is the blank space kept inside each label edge, in millimetres.
+const padding = 2.5
+
+// Render writes the labels as a PDF document to w.
+func Render(w io.Writer, sheet Sheet, labels []Label, opts Options) error {
+	if err := sheet.Validate(); err != nil {
+		return err
+	}
+	if opts.Skip < 0 {
+		opts.Skip = 0
+	}
+	opts.Skip %= sheet.PerPage()
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		UnitStr: "mm",
+		Size:    fpdf.SizeType{Wd: sheet.PageWidth, Ht: sheet.PageHeight},
+	})
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetCreator("Home Library", true)
+	pdf.AddUTF8FontFromBytes("go", "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes("go", "B", gobold.TTF)
+	pdf.AddUTF8FontFromBytes("gomono", "B", gomonobold.TTF)
+
+	if len(labels) == 0 {
+		pdf.AddPage()
+	}
+
+	for i, label := range labels {
+		position := opts.Skip + i
+		slot := position % sheet.PerPage()
+		if slot == 0 || i == 0 {
+			pdf.AddPage()
+		}
+
+		column, row := slot%sheet.Columns, slot/sheet.Columns
+		x := sheet.MarginLeft + float64(column)*(sheet.LabelWidth+sheet.GapX)
+		y := sheet.MarginTop + float64(row)*(sheet.LabelHeight+sheet.GapY)
+
+		if err := drawLabel(pdf, label, x, y, sheet.LabelWidth, sheet.LabelHeight, opts.Outline); err != nil {
+			return fmt.Errorf("label %d: %w", i+1, err)
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+func drawLabel(pdf *fpdf.Fpdf, label Label, x, y, width, height float64, outline bool) error {
+	if outline {
+		pdf.SetDrawColor(200, 200, 200)
+		pdf.SetLineWidth(0.1)
+		pdf.Rect(x, y, width, height, "D")
+	}
+
+	textX := x + padding
+	if label.URL != "" {
+		side := height - 2*padding
+		if err := drawQR(pdf, label.URL, x+padding, y+padding, side); err != nil {
+			return err
+		}
+		textX += side + padding
+	}
+	textWidth := x + width - padding - textX
+	if textWidth <= 0 {
+		return nil
+	}
+
+	// Font sizes follow the label height so that the same layout works from
+	// address labels to large shelf labels. 1pt is 0.3528mm.
+	codeSize := min(height*0.9, 16)
+	textSize := min(height*0.28, 9)
+	lineHeight := textSize * 0.3528 * 1.2
+
+	cursor := y + padding
+	if label.Code != "" {
+		pdf.SetFont("gomono", "B", codeSize)
+		for codeSize > 6 && pdf.GetStringWidth(label.Code) > textWidth {
+			codeSize--
+			pdf.SetFont("gomono", "B", codeSize)
+		}
+		cursor += codeSize * 0.3528
+		pdf.Text(textX, cursor, label.Code)
+		cursor += lineHeight * 0.5
+	}
+
+	bottom := y + height - padding
+	lines := int((bottom - cursor) / lineHeight)
+	if label.Subtitle != "" && lines > 1 {
+		lines--
+	}
+
+	pdf.SetFont("go", "B", textSize)
+	for _, line := range wrap(pdf, label.Title, textWidth, lines) {
+		cursor += lineHeight
+		pdf.Text(textX, cursor, line)
+	}
+
+	if label.Subtitle != "" && cursor+lineHeight <= bottom+0.01 {
+		pdf.SetFont("go", "", textSize)
+		for _, line := range wrap(pdf, label.Subtitle, textWidth, 1) {
+			cursor += lineHeight
+			pdf.Text(textX, cursor, line)
+		}
+	}
+
+	return pdf.Error()
+}
+
+// drawQR draws the code as filled rectangles, merging runs of dark modules
+// in a row to keep the page content small.
+func drawQR(pdf *fpdf.Fpdf, content string, x, y, side float64) error {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return err
+	}
+	code.DisableBorder = true
+
+	bitmap := code.Bitmap()
+	module := side / float64(len(bitmap))
+
+	pdf.SetFillColor(0, 0, 0)
+	for row, modules := range bitmap {
+		for start := 0; start < len(modules); start++ {
+			if !modules[start] {
+				continue
+			}
+			end := start
+			for end+1 < len(modules) && modules[end+1] {
+				end++
+			}
+			pdf.Rect(x+float64(start)*module, y+float64(row)*module, float64(end-start+1)*module, module, "F")
+			start = end
+		}
+	}
+
+	return nil
+}
+
+// wrap breaks text into at most limit lines of the given width, shortening
+// the last one with an ellipsis when the text does not fit.
+func wrap(pdf *fpdf.Fpdf, text string, width float64, limit int) []string {
+	words := strings.Fields(text)
+	if limit <= 0 || len(words) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, limit)
+	current := ""
+	for i, word := range words {
+		candidate := strings.TrimSpace(current + " " + word)
+		if pdf.GetStringWidth(candidate) <= width || current == "" {
+			current = candidate
+			continue
+		}
+
+		lines = append(lines, current)
+		current = word
+		if len(lines) == limit {
+			current = strings.Join(append([]string{lines[limit-1]}, words[i:]...), " ")
+			lines = lines[:limit-1]
+			break
+		}
+	}
+	lines = append(lines, current)
+
+	last := lines[len(lines)-1]
+	if pdf.GetStringWidth(last) > width {
+		runes := []rune(last)
+		for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+			runes = runes[:len(runes)-1]
+		}
+		lines[len(lines)-1] = strings.TrimSpace(string(runes)) + "…"
+	}
+
+	return lines
+}
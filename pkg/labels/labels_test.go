@@ -0,0 +1,111 @@
+package labels
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pageObject = regexp.MustCompile(`/Type /Page\b[^s]`)
+
+func pages(t *testing.T, pdf []byte) int {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+	return len(pageObject.FindAll(pdf, -1))
+}
+
+func TestSheets(t *testing.T) {
+	for name, sheet := range Sheets {
+		assert.NoError(t, sheet.Validate(), name)
+	}
+
+	sheet, err := Lookup("avery-l7159")
+	require.NoError(t, err)
+	assert.Equal(t, 24, sheet.PerPage())
+
+	_, err = Lookup("avery-0000")
+	assert.ErrorIs(t, err, ErrUnknownSheet)
+}
+
+func TestValidate(t *testing.T) {
+	sheet := Sheets["avery-l7160"]
+
+	tooWide := sheet
+	tooWide.Columns = 4
+	assert.ErrorIs(t, tooWide.Validate(), ErrInvalidSheet)
+
+	tooTall := sheet
+	tooTall.GapY = 5
+	assert.ErrorIs(t, tooTall.Validate(), ErrInvalidSheet)
+
+	empty := sheet
+	empty.Rows = 0
+	assert.ErrorIs(t, empty.Validate(), ErrInvalidSheet)
+}
+
+func TestRender(t *testing.T) {
+	label := Label{
+		Code:     "K7M2-Q9XD",
+		Title:    "Понедельник начинается в субботу. Сказка для научных работников младшего возраста",
+		Subtitle: "Аркадий и Борис Стругацкие",
+		URL:      "https://library.example/copies/K7M2Q9XD",
+	}
+
+	t.Run("fills sheets in order", func(t *testing.T) {
+		labels := make([]Label, 30)
+		for i := range labels {
+			labels[i] = label
+		}
+
+		var out bytes.Buffer
+		require.NoError(t, Render(&out, Sheets["avery-l7159"], labels, Options{}))
+		assert.Equal(t, 2, pages(t, out.Bytes()))
+	})
+
+	t.Run("skip continues a used sheet", func(t *testing.T) {
+		labels := []Label{label, label}
+
+		var out bytes.Buffer
+		require.NoError(t, Render(&out, Sheets["avery-l7163"], labels, Options{Skip: 13, Outline: true}))
+		assert.Equal(t, 2, pages(t, out.Bytes()))
+	})
+
+	t.Run("without qr code", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, Render(&out, Sheets["avery-5160"], []Label{{Code: "K7M2-Q9XD", Title: "Солярис"}}, Options{}))
+		assert.Equal(t, 1, pages(t, out.Bytes()))
+	})
+
+	t.Run("no labels", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, Render(&out, Sheets["avery-l7160"], nil, Options{}))
+		assert.Equal(t, 1, pages(t, out.Bytes()))
+	})
+
+	t.Run("invalid sheet", func(t *testing.T) {
+		var out bytes.Buffer
+		err := Render(&out, Sheet{}, []Label{label}, Options{})
+		assert.ErrorIs(t, err, ErrInvalidSheet)
+		assert.Zero(t, out.Len())
+	})
+}
+
+func TestWrap(t *testing.T) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Helvetica", "", 10)
+
+	assert.Nil(t, wrap(pdf, "", 50, 2))
+	assert.Equal(t, []string{"short title"}, wrap(pdf, "short title", 50, 2))
+
+	lines := wrap(pdf, strings.Repeat("word ", 40), 30, 2)
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasSuffix(lines[1], "…"))
+	for _, line := range lines {
+		assert.LessOrEqual(t, pdf.GetStringWidth(line), 30.0)
+	}
+}
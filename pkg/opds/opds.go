@@ -0,0 +1,120 @@
+// Package opds defines the Atom documents of an OPDS 1.2 catalog and the
+// OpenSearch description that goes with it.
+package opds
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+const (
+	NavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	AcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	OpenSearchType  = "application/opensearchdescription+xml"
+)
+
+const (
+	RelSelf        = "self"
+	RelStart       = "start"
+	RelUp          = "up"
+	RelNext        = "next"
+	RelPrevious    = "previous"
+	RelSearch      = "search"
+	RelSubsection  = "subsection"
+	RelNew         = "http://opds-spec.org/sort/new"
+	RelAcquisition = "http://opds-spec.org/acquisition"
+	RelImage       = "http://opds-spec.org/image"
+	RelThumbnail   = "http://opds-spec.org/image/thumbnail"
+)
+
+// Feed is an Atom feed. Navigation and acquisition feeds only differ in the
+// type of the links pointing at them and in what their entries link to.
+type Feed struct {
+	XMLName xml.Name  `xml:"http://www.w3.org/2005/Atom feed"`
+	DC      string    `xml:"xmlns:dc,attr"`
+	OPDS    string    `xml:"xmlns:opds,attr"`
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated time.Time `xml:"updated"`
+	Author  *Person   `xml:"author,omitempty"`
+	Links   []Link    `xml:"link"`
+	Entries []Entry   `xml:"entry"`
+}
+
+type Entry struct {
+	ID         string     `xml:"id"`
+	Title      string     `xml:"title"`
+	Updated    time.Time  `xml:"updated"`
+	Authors    []Person   `xml:"author"`
+	Identifier string     `xml:"dc:identifier,omitempty"`
+	Language   string     `xml:"dc:language,omitempty"`
+	Categories []Category `xml:"category"`
+	Summary    string     `xml:"summary,omitempty"`
+	Content    *Content   `xml:"content,omitempty"`
+	Links      []Link     `xml:"link"`
+}
+
+type Person struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type Link struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type Category struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+type Content struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// OpenSearchDescription tells clients how to build search URLs.
+type OpenSearchDescription struct {
+	XMLName        xml.Name        `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName      string          `xml:"ShortName"`
+	Description    string          `xml:"Description"`
+	InputEncoding  string          `xml:"InputEncoding"`
+	OutputEncoding string          `xml:"OutputEncoding"`
+	URLs           []OpenSearchURL `xml:"Url"`
+}
+
+// OpenSearchURL has {searchTerms} in its template where the query goes.
+type OpenSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+// NewFeed returns a feed with the namespaces OPDS entries use declared.
+func NewFeed(id string, title string, updated time.Time) *Feed {
+	return &Feed{
+		DC:      "http://purl.org/dc/terms/",
+		OPDS:    "http://opds-spec.org/2010/catalog",
+		ID:      id,
+		Title:   title,
+		Updated: updated.UTC().Truncate(time.Second),
+	}
+}
+
+// Write writes v as an indented XML document.
+func Write(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,62 @@
+package opds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFeed(t *testing.T) {
+	feed := NewFeed("urn:home-library:recent", "Новинки", time.Date(2025, 3, 1, 12, 0, 0, 500, time.FixedZone("MSK", 3*60*60)))
+	feed.Links = []Link{{Rel: RelSelf, Href: "/opds/recent", Type: AcquisitionType}}
+	feed.Entries = []Entry{{
+		ID:         "urn:uuid:2b5e0e9e-3c2f-4a4e-9d7e-6f2b4d7c1a10",
+		Title:      "Пикник на обочине",
+		Updated:    time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		Authors:    []Person{{Name: "Аркадий Стругацкий"}},
+		Identifier: "urn:isbn:9785170987658",
+		Language:   "ru",
+		Categories: []Category{{Term: "sf", Label: "sf"}},
+		Content:    &Content{Type: "text", Text: "Зона & сталкеры"},
+		Links:      []Link{{Rel: RelAcquisition, Href: "/opds/books/1/file", Type: "application/epub+zip"}},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, feed))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, out, `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/terms/" xmlns:opds="http://opds-spec.org/2010/catalog">`)
+	assert.Contains(t, out, `<updated>2025-03-01T09:00:00Z</updated>`)
+	assert.Contains(t, out, `<dc:identifier>urn:isbn:9785170987658</dc:identifier>`)
+	assert.Contains(t, out, `<dc:language>ru</dc:language>`)
+	assert.Contains(t, out, `<content type="text">Зона &amp; сталкеры</content>`)
+	assert.Contains(t, out, `<link rel="http://opds-spec.org/acquisition" href="/opds/books/1/file" type="application/epub+zip"></link>`)
+	assert.NotContains(t, out, "<summary>")
+
+	// The document must read back as plain Atom.
+	var parsed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Entries []struct {
+			Title string `xml:"title"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+	assert.Equal(t, "Пикник на обочине", parsed.Entries[0].Title)
+}
+
+func TestWriteOpenSearchDescription(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, OpenSearchDescription{
+		ShortName: "Библиотека",
+		URLs:      []OpenSearchURL{{Type: AcquisitionType, Template: "/opds/search?q={searchTerms}"}},
+	}))
+
+	assert.Contains(t, buf.String(), `<OpenSearchDescription xmlns="http://a9.com/-/spec/opensearch/1.1/">`)
+	assert.Contains(t, buf.String(), `<Url type="application/atom+xml;profile=opds-catalog;kind=acquisition" template="/opds/search?q={searchTerms}"></Url>`)
+}
@@ -0,0 +1,218 @@
+// Package opf parses OPF package documents as written by EPUB 2, EPUB 3 and
+// Calibre's metadata.opf.
+package opf
+
+import (
+	"encoding/xml"
+	"errors"
+	"home-library/pkg/isbn"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+type Metadata struct {
+	Title       string
+	Authors     []string
+	Language    string
+	Publisher   string
+	Description string
+	Date        string
+	Year        int
+	ISBN        string
+	UUID        string
+	Subjects    []string
+	Series      string
+	SeriesIndex float64
+	// CoverHref is the cover image path relative to the OPF document.
+	CoverHref string
+}
+
+var ErrNoTitle = errors.New("opf: package has no title")
+
+type xmlPackage struct {
+	Metadata struct {
+		Titles      []xmlText       `xml:"http://purl.org/dc/elements/1.1/ title"`
+		Creators    []xmlCreator    `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		Languages   []xmlText       `xml:"http://purl.org/dc/elements/1.1/ language"`
+		Publisher   xmlText         `xml:"http://purl.org/dc/elements/1.1/ publisher"`
+		Description xmlText         `xml:"http://purl.org/dc/elements/1.1/ description"`
+		Dates       []xmlText       `xml:"http://purl.org/dc/elements/1.1/ date"`
+		Identifiers []xmlIdentifier `xml:"http://purl.org/dc/elements/1.1/ identifier"`
+		Subjects    []xmlText       `xml:"http://purl.org/dc/elements/1.1/ subject"`
+		Metas       []xmlMeta       `xml:"meta"`
+	} `xml:"metadata"`
+	Manifest struct {
+		Items []xmlItem `xml:"item"`
+	} `xml:"manifest"`
+	Guide struct {
+		References []xmlReference `xml:"reference"`
+	} `xml:"guide"`
+}
+
+type xmlText struct {
+	Value string `xml:",chardata"`
+}
+
+type xmlCreator struct {
+	ID     string `xml:"id,attr"`
+	Role   string `xml:"http://www.idpf.org/2007/opf role,attr"`
+	FileAs string `xml:"http://www.idpf.org/2007/opf file-as,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type xmlIdentifier struct {
+	Scheme string `xml:"http://www.idpf.org/2007/opf scheme,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type xmlMeta struct {
+	Name     string `xml:"name,attr"`
+	Content  string `xml:"content,attr"`
+	Property string `xml:"property,attr"`
+	Refines  string `xml:"refines,attr"`
+	ID       string `xml:"id,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type xmlItem struct {
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr"`
+}
+
+type xmlReference struct {
+	Type string `xml:"type,attr"`
+	Href string `xml:"href,attr"`
+}
+
+func Parse(r io.Reader) (*Metadata, error) {
+	var pkg xmlPackage
+	if err := xml.NewDecoder(r).Decode(&pkg); err != nil {
+		return nil, err
+	}
+
+	m := pkg.Metadata
+	metadata := &Metadata{
+		Publisher:   strings.TrimSpace(m.Publisher.Value),
+		Description: strings.TrimSpace(m.Description.Value),
+	}
+
+	if len(m.Titles) > 0 {
+		metadata.Title = strings.TrimSpace(m.Titles[0].Value)
+	}
+	if metadata.Title == "" {
+		return nil, ErrNoTitle
+	}
+
+	if len(m.Languages) > 0 {
+		metadata.Language = strings.TrimSpace(m.Languages[0].Value)
+	}
+
+	if len(m.Dates) > 0 {
+		metadata.Date = strings.TrimSpace(m.Dates[0].Value)
+		if len(metadata.Date) >= 4 {
+			metadata.Year, _ = strconv.Atoi(metadata.Date[:4])
+		}
+	}
+
+	// EPUB 3 moves creator roles into <meta refines="#id" property="role">.
+	refinedRoles := make(map[string]string)
+	for _, meta := range m.Metas {
+		if meta.Property == "role" && strings.HasPrefix(meta.Refines, "#") {
+			refinedRoles[strings.TrimPrefix(meta.Refines, "#")] = strings.TrimSpace(meta.Value)
+		}
+	}
+	for _, creator := range m.Creators {
+		role := creator.Role
+		if role == "" {
+			role = refinedRoles[creator.ID]
+		}
+		name := strings.TrimSpace(creator.Value)
+		if name != "" && (role == "" || role == "aut") {
+			metadata.Authors = append(metadata.Authors, name)
+		}
+	}
+
+	for _, identifier := range m.Identifiers {
+		scheme := strings.ToLower(identifier.Scheme)
+		value := strings.TrimSpace(identifier.Value)
+		lower := strings.ToLower(value)
+		switch {
+		case scheme == "isbn" || strings.HasPrefix(lower, "urn:isbn:") || strings.HasPrefix(lower, "isbn:"):
+			if normalized := isbn.Normalize(value[strings.LastIndex(value, ":")+1:]); normalized != "" && metadata.ISBN == "" {
+				metadata.ISBN = normalized
+			}
+		case scheme == "uuid" || strings.HasPrefix(lower, "urn:uuid:"):
+			if metadata.UUID == "" {
+				metadata.UUID = strings.TrimPrefix(lower, "urn:uuid:")
+			}
+		}
+	}
+
+	for _, subject := range m.Subjects {
+		if s := strings.TrimSpace(subject.Value); s != "" {
+			metadata.Subjects = append(metadata.Subjects, s)
+		}
+	}
+
+	var coverID, collectionID string
+	for _, meta := range m.Metas {
+		switch {
+		case meta.Name == "calibre:series":
+			metadata.Series = strings.TrimSpace(meta.Content)
+		case meta.Name == "calibre:series_index":
+			metadata.SeriesIndex, _ = strconv.ParseFloat(meta.Content, 64)
+		case meta.Name == "cover":
+			coverID = meta.Content
+		case meta.Property == "belongs-to-collection" && metadata.Series == "":
+			metadata.Series = strings.TrimSpace(meta.Value)
+			collectionID = meta.ID
+		}
+	}
+	if collectionID != "" {
+		for _, meta := range m.Metas {
+			if meta.Property == "group-position" && meta.Refines == "#"+collectionID {
+				metadata.SeriesIndex, _ = strconv.ParseFloat(strings.TrimSpace(meta.Value), 64)
+			}
+		}
+	}
+
+	metadata.CoverHref = coverHref(pkg, coverID)
+
+	return metadata, nil
+}
+
+func coverHref(pkg xmlPackage, coverID string) string {
+	for _, item := range pkg.Manifest.Items {
+		if strings.Contains(" "+item.Properties+" ", " cover-image ") {
+			return item.Href
+		}
+	}
+	if coverID != "" {
+		for _, item := range pkg.Manifest.Items {
+			if item.ID == coverID {
+				return item.Href
+			}
+		}
+	}
+	// EPUB 2 guides often point "cover" at an XHTML page, which is no use as
+	// an image.
+	for _, reference := range pkg.Guide.References {
+		if reference.Type == "cover" && isImage(reference.Href) {
+			return reference.Href
+		}
+	}
+	return ""
+}
+
+func isImage(href string) bool {
+	switch strings.ToLower(path.Ext(href)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,99 @@
+package opf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const calibreOPF = `<?xml version='1.0' encoding='utf-8'?>
+<package xmlns="http://www.idpf.org/2007/opf" unique-identifier="uuid_id" version="2.0">
+    <metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+        <dc:identifier opf:scheme="calibre" id="calibre_id">42</dc:identifier>
+        <dc:identifier opf:scheme="uuid" id="uuid_id">0d3c7a4e-3b1f-4a44-9d0a-2c8c5b0f6e11</dc:identifier>
+        <dc:title>Трудно быть богом</dc:title>
+        <dc:creator opf:file-as="Стругацкий, Аркадий" opf:role="aut">Аркадий Стругацкий</dc:creator>
+        <dc:creator opf:file-as="Стругацкий, Борис" opf:role="aut">Борис Стругацкий</dc:creator>
+        <dc:contributor opf:file-as="calibre" opf:role="bkp">calibre (6.11.0) [https://calibre-ebook.com]</dc:contributor>
+        <dc:date>1964-01-01T00:00:00+00:00</dc:date>
+        <dc:description>&lt;p&gt;Повесть.&lt;/p&gt;</dc:description>
+        <dc:publisher>АСТ</dc:publisher>
+        <dc:identifier opf:scheme="ISBN">978-5-17-098765-8</dc:identifier>
+        <dc:language>rus</dc:language>
+        <dc:subject>Фантастика</dc:subject>
+        <dc:subject>Классика</dc:subject>
+        <meta name="calibre:series" content="Мир Полудня"/>
+        <meta name="calibre:series_index" content="3.0"/>
+        <meta name="calibre:rating" content="10"/>
+    </metadata>
+    <guide>
+        <reference type="cover" title="Обложка" href="cover.jpg"/>
+    </guide>
+</package>`
+
+const epub3OPF = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="pub-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="pub-id">urn:isbn:9780441013593</dc:identifier>
+    <dc:title>Dune</dc:title>
+    <dc:creator id="creator01">Frank Herbert</dc:creator>
+    <meta refines="#creator01" property="role" scheme="marc:relators">aut</meta>
+    <dc:creator id="creator02">John Schoenherr</dc:creator>
+    <meta refines="#creator02" property="role" scheme="marc:relators">ill</meta>
+    <dc:language>en</dc:language>
+    <meta property="belongs-to-collection" id="c01">Dune Chronicles</meta>
+    <meta refines="#c01" property="collection-type">series</meta>
+    <meta refines="#c01" property="group-position">1</meta>
+  </metadata>
+  <manifest>
+    <item id="cover" href="images/cover.jpeg" media-type="image/jpeg" properties="cover-image"/>
+    <item id="ch1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <guide>
+    <reference type="cover" href="text/cover.xhtml"/>
+  </guide>
+</package>`
+
+func TestParse(t *testing.T) {
+	t.Run("calibre metadata", func(t *testing.T) {
+		metadata, err := Parse(strings.NewReader(calibreOPF))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Трудно быть богом", metadata.Title)
+		assert.Equal(t, []string{"Аркадий Стругацкий", "Борис Стругацкий"}, metadata.Authors)
+		assert.Equal(t, "rus", metadata.Language)
+		assert.Equal(t, "АСТ", metadata.Publisher)
+		assert.Equal(t, 1964, metadata.Year)
+		assert.Equal(t, "9785170987658", metadata.ISBN)
+		assert.Equal(t, "0d3c7a4e-3b1f-4a44-9d0a-2c8c5b0f6e11", metadata.UUID)
+		assert.Equal(t, []string{"Фантастика", "Классика"}, metadata.Subjects)
+		assert.Equal(t, "Мир Полудня", metadata.Series)
+		assert.Equal(t, 3.0, metadata.SeriesIndex)
+		assert.Equal(t, "cover.jpg", metadata.CoverHref)
+		assert.Equal(t, "<p>Повесть.</p>", metadata.Description)
+	})
+
+	t.Run("epub 3 package", func(t *testing.T) {
+		metadata, err := Parse(strings.NewReader(epub3OPF))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Dune", metadata.Title)
+		assert.Equal(t, []string{"Frank Herbert"}, metadata.Authors)
+		assert.Equal(t, "9780441013593", metadata.ISBN)
+		assert.Equal(t, "Dune Chronicles", metadata.Series)
+		assert.Equal(t, 1.0, metadata.SeriesIndex)
+		assert.Equal(t, "images/cover.jpeg", metadata.CoverHref)
+	})
+
+	t.Run("missing title", func(t *testing.T) {
+		_, err := Parse(strings.NewReader(`<package xmlns="http://www.idpf.org/2007/opf"><metadata/></package>`))
+		assert.Equal(t, ErrNoTitle, err)
+	})
+
+	t.Run("malformed xml", func(t *testing.T) {
+		_, err := Parse(strings.NewReader(`<package><metadata>`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,215 @@
+// Package recommend ranks the unread books of a household for one reader.
+// It works on the household's own catalog and ratings only: the reader's
+// taste in authors and tags, the series they have started and what the
+// other members liked. Every suggestion carries a short explanation.
+package recommend
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Book struct {
+	ID          string
+	Title       string
+	Authors     []string
+	Tags        []string
+	Series      string
+	SeriesIndex float64
+}
+
+// Rating is a score from 1 to 5 a household member gave a book.
+type Rating struct {
+	BookID string
+	UserID string
+	Score  int
+}
+
+type Suggestion struct {
+	BookID string
+	Score  float64
+	Reason string
+}
+
+// Weights of the signals. A rating of 3 is neutral; better ones pull books
+// with the same authors and tags up, worse ones push them down.
+const (
+	authorWeight    = 1.0
+	tagWeight       = 0.5
+	householdWeight = 0.8
+	nextInSeries    = 3.0
+)
+
+// Recommend returns up to limit of the books the user has not read, best
+// first. read holds the books the user finished; rated books count as read
+// too. Books without any positive signal are left out, so a new reader gets
+// nothing rather than noise, and so are volumes of a started series beyond
+// the next one.
+func Recommend(userID string, books []Book, ratings []Rating, read map[string]bool, limit int) []Suggestion {
+	byID := make(map[string]Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+
+	done := make(map[string]bool, len(read))
+	for id, ok := range read {
+		if ok {
+			done[id] = true
+		}
+	}
+
+	authors := make(map[string]float64)
+	tags := make(map[string]float64)
+	others := make(map[string][]int)
+	for _, rating := range ratings {
+		book, ok := byID[rating.BookID]
+		if !ok {
+			continue
+		}
+		if rating.UserID != userID {
+			others[rating.BookID] = append(others[rating.BookID], rating.Score)
+			continue
+		}
+
+		done[book.ID] = true
+		weight := float64(rating.Score - 3)
+		for _, author := range book.Authors {
+			authors[key(author)] += weight
+		}
+		for _, tag := range book.Tags {
+			tags[key(tag)] += weight
+		}
+	}
+
+	next := nextVolumes(books, done)
+
+	result := make([]Suggestion, 0)
+	for _, book := range books {
+		if done[book.ID] {
+			continue
+		}
+
+		var reasons []reason
+		if book.Series != "" {
+			if previous, ok := next[key(book.Series)]; ok {
+				if book.SeriesIndex == previous.next {
+					reasons = append(reasons, reason{nextInSeries, fmt.Sprintf("Следующая книга серии «%s» после «%s»", book.Series, previous.title)})
+				} else if book.SeriesIndex > previous.next {
+					// Skipping a volume of a started series is never a good idea.
+					continue
+				}
+			}
+		}
+
+		var liked []string
+		authorScore := 0.0
+		for _, author := range book.Authors {
+			if weight := authors[key(author)]; weight != 0 {
+				authorScore += weight
+				if weight > 0 {
+					liked = append(liked, author)
+				}
+			}
+		}
+		if authorScore != 0 {
+			reasons = append(reasons, reason{authorWeight * authorScore, "Вам понравились книги автора " + strings.Join(liked, ", ")})
+		}
+
+		var matched []string
+		tagScore := 0.0
+		for _, tag := range book.Tags {
+			if weight := tags[key(tag)]; weight != 0 {
+				tagScore += weight
+				if weight > 0 {
+					matched = append(matched, tag)
+				}
+			}
+		}
+		if len(book.Tags) > 0 && tagScore != 0 {
+			// Averaged, so a book is not favoured for merely having many tags.
+			tagScore /= float64(len(book.Tags))
+			reasons = append(reasons, reason{tagWeight * tagScore, "Похоже на ваши любимые жанры: " + strings.Join(matched, ", ")})
+		}
+
+		if scores := others[book.ID]; len(scores) > 0 {
+			average := mean(scores)
+			reasons = append(reasons, reason{householdWeight * (average - 3), fmt.Sprintf("Средняя оценка в семье — %.1f", average)})
+		}
+
+		score, best := 0.0, reason{}
+		for _, r := range reasons {
+			score += r.weight
+			if r.weight > best.weight {
+				best = r
+			}
+		}
+		if score <= 0 || best.text == "" {
+			continue
+		}
+
+		result = append(result, Suggestion{BookID: book.ID, Score: score, Reason: best.text})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return byID[result[i].BookID].Title < byID[result[j].BookID].Title
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+type reason struct {
+	weight float64
+	text   string
+}
+
+type volume struct {
+	next  float64
+	title string
+}
+
+// nextVolumes finds, for every series the reader has started, the first
+// unread volume after the last one they read.
+func nextVolumes(books []Book, done map[string]bool) map[string]volume {
+	last := make(map[string]Book)
+	for _, book := range books {
+		if book.Series == "" || !done[book.ID] {
+			continue
+		}
+		if previous, ok := last[key(book.Series)]; !ok || book.SeriesIndex > previous.SeriesIndex {
+			last[key(book.Series)] = book
+		}
+	}
+
+	result := make(map[string]volume)
+	for _, book := range books {
+		series := key(book.Series)
+		previous, ok := last[series]
+		if !ok || done[book.ID] || book.SeriesIndex <= previous.SeriesIndex {
+			continue
+		}
+		if current, ok := result[series]; !ok || book.SeriesIndex < current.next {
+			result[series] = volume{next: book.SeriesIndex, title: previous.Title}
+		}
+	}
+
+	return result
+}
+
+func key(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+func mean(scores []int) float64 {
+	total := 0
+	for _, score := range scores {
+		total += score
+	}
+	return float64(total) / float64(len(scores))
+}
@@ -0,0 +1,80 @@
+package recommend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var catalog = []Book{
+	{ID: "dune-1", Title: "Дюна", Authors: []string{"Фрэнк Герберт"}, Tags: []string{"фантастика"}, Series: "Дюна", SeriesIndex: 1},
+	{ID: "dune-2", Title: "Мессия Дюны", Authors: []string{"Фрэнк Герберт"}, Tags: []string{"фантастика"}, Series: "Дюна", SeriesIndex: 2},
+	{ID: "dune-3", Title: "Дети Дюны", Authors: []string{"Фрэнк Герберт"}, Tags: []string{"фантастика"}, Series: "Дюна", SeriesIndex: 3},
+	{ID: "solaris", Title: "Солярис", Authors: []string{"Станислав Лем"}, Tags: []string{"фантастика", "классика"}},
+	{ID: "eden", Title: "Эдем", Authors: []string{"Станислав Лем"}, Tags: []string{"фантастика"}},
+	{ID: "romance", Title: "Гордость и предубеждение", Authors: []string{"Джейн Остин"}, Tags: []string{"роман"}},
+	{ID: "emma", Title: "Эмма", Authors: []string{"Джейн Остин"}, Tags: []string{"роман"}},
+	{ID: "cookbook", Title: "Книга о вкусной и здоровой пище", Tags: []string{"кулинария"}},
+	{ID: "poems", Title: "Стихотворения", Authors: []string{"Анна Ахматова"}, Tags: []string{"поэзия"}},
+}
+
+func TestRecommend(t *testing.T) {
+	ratings := []Rating{
+		{BookID: "dune-1", UserID: "me", Score: 5},
+		{BookID: "solaris", UserID: "me", Score: 5},
+		{BookID: "romance", UserID: "me", Score: 1},
+		{BookID: "poems", UserID: "partner", Score: 5},
+		{BookID: "poems", UserID: "kid", Score: 4},
+		{BookID: "cookbook", UserID: "partner", Score: 2},
+	}
+
+	result := Recommend("me", catalog, ratings, nil, 0)
+
+	ids := make([]string, len(result))
+	reasons := make(map[string]string)
+	for i, suggestion := range result {
+		ids[i] = suggestion.BookID
+		reasons[suggestion.BookID] = suggestion.Reason
+	}
+
+	t.Run("next volume first", func(t *testing.T) {
+		require.NotEmpty(t, ids)
+		assert.Equal(t, "dune-2", ids[0])
+		assert.Equal(t, "Следующая книга серии «Дюна» после «Дюна»", reasons["dune-2"])
+	})
+
+	t.Run("favourite author", func(t *testing.T) {
+		assert.Equal(t, "Вам понравились книги автора Станислав Лем", reasons["eden"])
+	})
+
+	t.Run("household favourite", func(t *testing.T) {
+		assert.Equal(t, "Средняя оценка в семье — 4.5", reasons["poems"])
+	})
+
+	t.Run("read, disliked and skipped volumes are left out", func(t *testing.T) {
+		for _, id := range []string{"dune-1", "solaris", "romance", "emma", "cookbook", "dune-3"} {
+			assert.NotContains(t, ids, id)
+		}
+	})
+
+	t.Run("best first", func(t *testing.T) {
+		for i := 1; i < len(result); i++ {
+			assert.GreaterOrEqual(t, result[i-1].Score, result[i].Score)
+		}
+	})
+}
+
+func TestRecommendReadLog(t *testing.T) {
+	read := map[string]bool{"dune-1": true, "dune-2": true}
+
+	result := Recommend("me", catalog, nil, read, 1)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, "dune-3", result[0].BookID)
+	assert.Contains(t, result[0].Reason, "после «Мессия Дюны»")
+}
+
+func TestRecommendNewReader(t *testing.T) {
+	assert.Empty(t, Recommend("me", catalog, nil, nil, 10))
+}
@@ -0,0 +1,125 @@
+// Package reminders decides which loan reminders are due: a notice shortly
+// before the due date and weekly notices once a loan is overdue, respecting
+// each borrower's preferences and quiet hours.
+package reminders
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidClock = errors.New("invalid time of day")
+
+type Kind string
+
+const (
+	KindDueSoon Kind = "due_soon"
+	KindOverdue Kind = "overdue"
+)
+
+// overdueEvery is how often an overdue loan is brought up again.
+const overdueEvery = 7 * 24 * time.Hour
+
+type Loan struct {
+	ID         string
+	BorrowerID string
+	Title      string
+	DueAt      time.Time
+	ReturnedAt *time.Time
+}
+
+// Clock is a time of day in minutes after midnight.
+type Clock int
+
+// ParseClock reads a time of day such as "22:30".
+func ParseClock(s string) (Clock, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, ErrInvalidClock
+	}
+	return Clock(t.Hour()*60 + t.Minute()), nil
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c/60, c%60)
+}
+
+// Preferences of a borrower. Quiet hours run from QuietFrom to QuietTo and
+// may span midnight; equal values mean no quiet hours.
+type Preferences struct {
+	Disabled   bool
+	DaysBefore int
+	QuietFrom  Clock
+	QuietTo    Clock
+}
+
+// DefaultPreferences apply to borrowers who have not set their own.
+var DefaultPreferences = Preferences{DaysBefore: 2, QuietFrom: 22 * 60, QuietTo: 9 * 60}
+
+func (p Preferences) quiet(at time.Time) bool {
+	now := Clock(at.Hour()*60 + at.Minute())
+	switch {
+	case p.QuietFrom == p.QuietTo:
+		return false
+	case p.QuietFrom < p.QuietTo:
+		return now >= p.QuietFrom && now < p.QuietTo
+	default:
+		return now >= p.QuietFrom || now < p.QuietTo
+	}
+}
+
+type Reminder struct {
+	LoanID     string
+	BorrowerID string
+	Title      string
+	Kind       Kind
+	DueAt      time.Time
+	// Key identifies the reminder, so a sender can record it and never send
+	// it twice, even when planning runs more often than reminders fall due.
+	Key string
+}
+
+// Plan returns the reminders to send at now, in the library's location.
+// Reminders falling in a borrower's quiet hours are left for a later run,
+// and so are those whose key sent reports as already delivered.
+func Plan(loans []Loan, preferences map[string]Preferences, sent func(key string) bool, now time.Time, location *time.Location) []Reminder {
+	now = now.In(location)
+	result := make([]Reminder, 0)
+
+	for _, loan := range loans {
+		if loan.ReturnedAt != nil {
+			continue
+		}
+
+		prefs, ok := preferences[loan.BorrowerID]
+		if !ok {
+			prefs = DefaultPreferences
+		}
+		if prefs.Disabled || prefs.quiet(now) {
+			continue
+		}
+
+		reminder := Reminder{LoanID: loan.ID, BorrowerID: loan.BorrowerID, Title: loan.Title, DueAt: loan.DueAt}
+		switch {
+		case !now.Before(loan.DueAt):
+			reminder.Kind = KindOverdue
+			week := int(now.Sub(loan.DueAt) / overdueEvery)
+			reminder.Key = fmt.Sprintf("%s:%s:%d", loan.ID, KindOverdue, week)
+		case now.AddDate(0, 0, prefs.DaysBefore).After(loan.DueAt):
+			reminder.Kind = KindDueSoon
+			// The due date is part of the key, so extending a loan earns a
+			// new reminder before the new date.
+			reminder.Key = fmt.Sprintf("%s:%s:%s", loan.ID, KindDueSoon, loan.DueAt.In(location).Format("2006-01-02"))
+		default:
+			continue
+		}
+
+		if sent != nil && sent(reminder.Key) {
+			continue
+		}
+		result = append(result, reminder)
+	}
+
+	return result
+}
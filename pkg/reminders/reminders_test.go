@@ -0,0 +1,67 @@
+package reminders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	// Noon in Moscow.
+	now := time.Date(2024, time.March, 10, 9, 0, 0, 0, time.UTC)
+	returned := now.Add(-time.Hour)
+	loans := []Loan{
+		{ID: "soon", BorrowerID: "anna", DueAt: now.Add(36 * time.Hour)},
+		{ID: "later", BorrowerID: "anna", DueAt: now.AddDate(0, 0, 10)},
+		{ID: "overdue", BorrowerID: "boris", DueAt: now.AddDate(0, 0, -8)},
+		{ID: "returned", BorrowerID: "boris", DueAt: now.AddDate(0, 0, -1), ReturnedAt: &returned},
+		{ID: "muted", BorrowerID: "vera", DueAt: now.AddDate(0, 0, -1)},
+	}
+	preferences := map[string]Preferences{
+		"vera": {Disabled: true},
+	}
+
+	t.Run("due soon and overdue", func(t *testing.T) {
+		result := Plan(loans, preferences, nil, now, moscow)
+
+		require.Len(t, result, 2)
+		assert.Equal(t, KindDueSoon, result[0].Kind)
+		assert.Equal(t, "soon:due_soon:2024-03-12", result[0].Key)
+		assert.Equal(t, KindOverdue, result[1].Kind)
+		assert.Equal(t, "overdue:overdue:1", result[1].Key)
+	})
+
+	t.Run("already sent", func(t *testing.T) {
+		sent := func(key string) bool { return key == "soon:due_soon:2024-03-12" }
+
+		result := Plan(loans, preferences, sent, now, moscow)
+
+		require.Len(t, result, 1)
+		assert.Equal(t, "overdue", result[0].LoanID)
+	})
+
+	t.Run("quiet hours across midnight", func(t *testing.T) {
+		// 23:30 in Moscow falls into the default quiet hours.
+		late := time.Date(2024, time.March, 10, 20, 30, 0, 0, time.UTC)
+		assert.Empty(t, Plan(loans, preferences, nil, late, moscow))
+
+		owl := map[string]Preferences{"anna": {DaysBefore: 2, QuietFrom: 2 * 60, QuietTo: 6 * 60}}
+		result := Plan(loans[:1], owl, nil, late, moscow)
+		assert.Len(t, result, 1)
+	})
+}
+
+func TestParseClock(t *testing.T) {
+	clock, err := ParseClock("22:30")
+	require.NoError(t, err)
+	assert.Equal(t, Clock(22*60+30), clock)
+	assert.Equal(t, "22:30", clock.String())
+
+	_, err = ParseClock("25:00")
+	assert.ErrorIs(t, err, ErrInvalidClock)
+}
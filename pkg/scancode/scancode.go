@@ -0,0 +1,83 @@
+// Package scancode recognizes what a barcode or QR scanner read: a book's
+// ISBN from its EAN-13 barcode, or one of our own labels.
+package scancode
+
+import (
+	"home-library/pkg/isbn"
+	"home-library/pkg/shortid"
+	"net/url"
+	"strings"
+)
+
+type Kind string
+
+const (
+	KindISBN    Kind = "isbn"
+	KindCopy    Kind = "copy"
+	KindShelf   Kind = "shelf"
+	KindUnknown Kind = "unknown"
+)
+
+// Code is a recognized scan. Value is the ISBN-13 for books and the
+// canonical short id for labels.
+type Code struct {
+	Kind  Kind
+	Value string
+}
+
+// Label QR codes point to these path segments followed by the short id,
+// for example https://library.example/copies/K7M2Q9XD.
+var labelPaths = map[string]Kind{
+	"copies":  KindCopy,
+	"shelves": KindShelf,
+}
+
+// Parse classifies raw scanner input. A bare short id, as typed from a label
+// by hand, is taken to be a copy.
+func Parse(raw string) Code {
+	raw = strings.TrimSpace(raw)
+
+	if u, err := url.Parse(raw); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return parseURL(u)
+	}
+
+	if value := parseEAN(raw); value != "" {
+		return Code{Kind: KindISBN, Value: value}
+	}
+
+	if id, err := shortid.Normalize(raw); err == nil {
+		return Code{Kind: KindCopy, Value: id}
+	}
+
+	return Code{Kind: KindUnknown}
+}
+
+func parseURL(u *url.URL) Code {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 2; i >= 0; i-- {
+		kind, ok := labelPaths[segments[i]]
+		if !ok {
+			continue
+		}
+		if id, err := shortid.Normalize(segments[i+1]); err == nil {
+			return Code{Kind: kind, Value: id}
+		}
+	}
+
+	return Code{Kind: KindUnknown}
+}
+
+// parseEAN returns the ISBN-13 of a Bookland EAN-13 or a typed ISBN-10. The
+// 2 or 5 digit add-on printed next to the barcode, which some scanners
+// append, is dropped.
+func parseEAN(raw string) string {
+	digits := strings.NewReplacer("-", "", " ", "").Replace(raw)
+	if (len(digits) == 15 || len(digits) == 18) && strings.Trim(digits, "0123456789") == "" {
+		digits = digits[:13]
+	}
+
+	if len(digits) == 13 && !strings.HasPrefix(digits, "978") && !strings.HasPrefix(digits, "979") {
+		return ""
+	}
+	return isbn.To13(digits)
+}
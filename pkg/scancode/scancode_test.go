@@ -0,0 +1,34 @@
+package scancode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Code
+	}{
+		{"ean-13", "9785170987658", Code{KindISBN, "9785170987658"}},
+		{"ean-13 with 5 digit add-on", "978517098765851299", Code{KindISBN, "9785170987658"}},
+		{"ean-13 with 2 digit add-on", "978517098765812", Code{KindISBN, "9785170987658"}},
+		{"typed isbn-10", " 0-441-01359-7 ", Code{KindISBN, "9780441013593"}},
+		{"ean-13 of something else", "4006381333931", Code{KindUnknown, ""}},
+		{"wrong check digit", "9785170987659", Code{KindUnknown, ""}},
+		{"copy label url", "https://library.example/copies/K7M2Q9XD", Code{KindCopy, "K7M2Q9XD"}},
+		{"shelf label url", "http://192.168.1.10:8080/app/shelves/k7m2-q9xd?ref=qr", Code{KindShelf, "K7M2Q9XD"}},
+		{"other url", "https://example.com/books/K7M2Q9XD", Code{KindUnknown, ""}},
+		{"typed copy id", "k7m2-q9xo", Code{KindCopy, "K7M2Q9X0"}},
+		{"empty", "", Code{KindUnknown, ""}},
+		{"text", "hello world", Code{KindUnknown, ""}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, Parse(test.input))
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Package scheduler runs periodic background jobs for the lifetime of the
+// application.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type job struct {
+	name  string
+	every time.Duration
+	run   func(ctx context.Context) error
+}
+
+// Scheduler runs every job on its own interval. Runs of one job never
+// overlap: a run that takes longer than the interval delays the next one.
+type Scheduler struct {
+	jobs []job
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers a job. Jobs must be added before Start.
+func (s *Scheduler) Add(name string, every time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, job{name: name, every: every, run: run})
+}
+
+// Start launches the jobs. Each first runs one interval after the start.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, j := range s.jobs {
+		s.running.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop cancels the context of the running jobs and waits for them to
+// return, or for ctx to be done, whichever comes first.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.running.Done()
+
+	ticker := time.NewTicker(j.every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := runJob(ctx, j); err != nil {
+				log.Error().Err(err).Str("job", j.name).Msg("scheduled job failed")
+			}
+		}
+	}
+}
+
+// runJob turns a panic into an error, so one broken job does not take the
+// server down.
+func runJob(ctx context.Context, j job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return j.run(ctx)
+}
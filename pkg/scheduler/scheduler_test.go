@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Run("runs jobs until stopped", func(t *testing.T) {
+		s := New()
+		var runs, failures atomic.Int32
+		s.Add("count", time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+		s.Add("fail", time.Millisecond, func(ctx context.Context) error {
+			failures.Add(1)
+			panic("broken job")
+		})
+
+		s.Start()
+		s.Start()
+		assert.Eventually(t, func() bool { return runs.Load() >= 3 && failures.Load() >= 3 }, time.Second, time.Millisecond)
+
+		assert.NoError(t, s.Stop(context.Background()))
+		stopped := runs.Load()
+		time.Sleep(5 * time.Millisecond)
+		assert.Equal(t, stopped, runs.Load())
+	})
+
+	t.Run("stop waits for running jobs", func(t *testing.T) {
+		s := New()
+		started := make(chan struct{})
+		var finished atomic.Bool
+		s.Add("slow", time.Millisecond, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(5 * time.Millisecond)
+			finished.Store(true)
+			return ctx.Err()
+		})
+
+		s.Start()
+		<-started
+
+		assert.NoError(t, s.Stop(context.Background()))
+		assert.True(t, finished.Load())
+	})
+
+	t.Run("stop gives up at the deadline", func(t *testing.T) {
+		s := New()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		s.Add("stuck", time.Millisecond, func(ctx context.Context) error {
+			close(started)
+			<-release
+			return nil
+		})
+
+		s.Start()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+		assert.True(t, errors.Is(s.Stop(ctx), context.DeadlineExceeded))
+		close(release)
+	})
+
+	t.Run("stop before start", func(t *testing.T) {
+		assert.NoError(t, New().Stop(context.Background()))
+	})
+}
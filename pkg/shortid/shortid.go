@@ -0,0 +1,64 @@
+// Package shortid generates short identifiers meant to be printed, read
+// aloud and typed back by hand. They use Crockford's base32 alphabet, which
+// has no I, L, O or U, so the usual misreadings can be corrected on input.
+package shortid
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+)
+
+// Length is the number of characters in an identifier. 40 bits are plenty
+// for the books of one library and still fit on a spine label.
+const Length = 8
+
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var ErrInvalid = errors.New("invalid short id")
+
+// New returns a random identifier in its canonical form, without a hyphen.
+func New() (string, error) {
+	buf := make([]byte, Length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	for i, b := range buf {
+		buf[i] = alphabet[b&31]
+	}
+	return string(buf), nil
+}
+
+// Normalize turns user input such as "k7m2-q9xo" into the canonical form
+// "K7M2Q9X0", reading O as zero and I and L as one.
+func Normalize(s string) (string, error) {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		switch r {
+		case '-', ' ':
+			continue
+		case 'O':
+			r = '0'
+		case 'I', 'L':
+			r = '1'
+		}
+		if !strings.ContainsRune(alphabet, r) {
+			return "", ErrInvalid
+		}
+		b.WriteRune(r)
+	}
+
+	if b.Len() != Length {
+		return "", ErrInvalid
+	}
+	return b.String(), nil
+}
+
+// Format splits a canonical identifier into two halves for printing.
+func Format(id string) string {
+	if len(id) != Length {
+		return id
+	}
+	return id[:Length/2] + "-" + id[Length/2:]
+}
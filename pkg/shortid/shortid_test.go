@@ -0,0 +1,53 @@
+package shortid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 1000 {
+		id, err := New()
+		require.NoError(t, err)
+		assert.Len(t, id, Length)
+
+		normalized, err := Normalize(id)
+		require.NoError(t, err)
+		assert.Equal(t, id, normalized)
+
+		assert.False(t, seen[id])
+		seen[id] = true
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      error
+	}{
+		{"K7M2-Q9XD", "K7M2Q9XD", nil},
+		{"k7m2 q9xd", "K7M2Q9XD", nil},
+		{"oIlo-0000", "01100000", nil},
+		{"K7M2-Q9X", "", ErrInvalid},
+		{"K7M2-Q9XDD", "", ErrInvalid},
+		{"K7M2-Q9XU", "", ErrInvalid},
+		{"К7М2-Q9XD", "", ErrInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			result, err := Normalize(test.input)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "K7M2-Q9XD", Format("K7M2Q9XD"))
+	assert.Equal(t, "short", Format("short"))
+}
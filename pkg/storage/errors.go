@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Kinds of constraint violations, matched with errors.Is.
+var (
+	ErrUniqueViolation     = errors.New("unique constraint violated")
+	ErrForeignKeyViolation = errors.New("foreign key constraint violated")
+	ErrCheckViolation      = errors.New("check constraint violated")
+)
+
+var violations = map[pq.ErrorCode]error{
+	"23505": ErrUniqueViolation,
+	"23503": ErrForeignKeyViolation,
+	"23514": ErrCheckViolation,
+}
+
+// ConstraintError is a statement rejected by a constraint. It matches its
+// kind with errors.Is and unwraps to the *pq.Error.
+type ConstraintError struct {
+	Kind       error
+	Table      string
+	Constraint string
+
+	err *pq.Error
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s: %s on %s", e.Kind, e.Constraint, e.Table)
+}
+
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.err
+}
+
+// MapError turns a constraint violation into a *ConstraintError and returns
+// any other error as it is.
+func MapError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	kind, ok := violations[pqErr.Code]
+	if !ok {
+		return err
+	}
+
+	return &ConstraintError{Kind: kind, Table: pqErr.Table, Constraint: pqErr.Constraint, err: pqErr}
+}
+
+// Constraints maps the names of a repository's constraints to the domain
+// errors their violations mean, such as a unique email to "user already
+// exists".
+type Constraints map[string]error
+
+// Map returns the domain error of the violated constraint. Violations of
+// other constraints come back as a *ConstraintError, anything else as it is.
+func (c Constraints) Map(err error) error {
+	err = MapError(err)
+
+	var constraintErr *ConstraintError
+	if errors.As(err, &constraintErr) {
+		if domainErr, ok := c[constraintErr.Constraint]; ok {
+			return domainErr
+		}
+	}
+
+	return err
+}
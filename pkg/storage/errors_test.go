@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		code pq.ErrorCode
+		kind error
+	}{
+		{"23505", ErrUniqueViolation},
+		{"23503", ErrForeignKeyViolation},
+		{"23514", ErrCheckViolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			pqErr := &pq.Error{Code: tt.code, Table: "users", Constraint: "users_email_key"}
+
+			err := MapError(fmt.Errorf("insert user: %w", pqErr))
+
+			var constraintErr *ConstraintError
+			require.ErrorAs(t, err, &constraintErr)
+			assert.ErrorIs(t, err, tt.kind)
+			assert.Equal(t, "users", constraintErr.Table)
+			assert.Equal(t, "users_email_key", constraintErr.Constraint)
+
+			var unwrapped *pq.Error
+			require.ErrorAs(t, err, &unwrapped)
+			assert.Same(t, pqErr, unwrapped)
+		})
+	}
+
+	t.Run("other errors are left alone", func(t *testing.T) {
+		serialization := &pq.Error{Code: "40001"}
+
+		assert.Nil(t, MapError(nil))
+		assert.Equal(t, sql.ErrNoRows, MapError(sql.ErrNoRows))
+		assert.Same(t, serialization, MapError(serialization))
+	})
+}
+
+func TestConstraintsMap(t *testing.T) {
+	errTaken := errors.New("already exists")
+	constraints := Constraints{"users_email_key": errTaken}
+
+	t.Run("known constraint", func(t *testing.T) {
+		err := constraints.Map(&pq.Error{Code: "23505", Constraint: "users_email_key"})
+
+		assert.Equal(t, errTaken, err)
+	})
+
+	t.Run("already mapped error", func(t *testing.T) {
+		err := constraints.Map(MapError(&pq.Error{Code: "23505", Constraint: "users_email_key"}))
+
+		assert.Equal(t, errTaken, err)
+	})
+
+	t.Run("unknown constraint", func(t *testing.T) {
+		err := constraints.Map(&pq.Error{Code: "23503", Constraint: "users_household_id_fkey"})
+
+		assert.ErrorIs(t, err, ErrForeignKeyViolation)
+		assert.NotErrorIs(t, err, errTaken)
+	})
+
+	t.Run("not a violation", func(t *testing.T) {
+		assert.Equal(t, sql.ErrNoRows, constraints.Map(sql.ErrNoRows))
+		assert.Nil(t, constraints.Map(nil))
+	})
+}
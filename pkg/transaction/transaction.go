@@ -0,0 +1,181 @@
+// Package transaction runs several repository calls as one unit of work. A
+// Manager begins the transaction and carries it in the context; repositories
+// hold a DB, which runs their queries in that transaction when there is one
+// and on the pool otherwise, so they need not know whether they are part of
+// a larger operation. A DB also reports constraint violations as
+// *storage.ConstraintError.
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"home-library/pkg/storage"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	// DefaultAttempts is how many times a unit of work runs before a
+	// serialization failure is handed to the caller.
+	DefaultAttempts = 5
+
+	retryBase = 10 * time.Millisecond
+)
+
+type txKey struct{}
+
+func withTx(ctx context.Context, tx *sqlx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+func fromContext(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sqlx.Tx)
+	return tx, ok
+}
+
+// Transactor runs fn atomically. The context given to fn carries the
+// transaction and must be passed to the repositories.
+type Transactor interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Manager runs units of work in serializable transactions. Postgres aborts
+// one of two serializable transactions that would not have given the same
+// result one after the other, and either may deadlock; in both cases the
+// whole unit of work is run again, up to a number of attempts.
+type Manager struct {
+	db       *sqlx.DB
+	attempts int
+}
+
+func NewManager(db *sqlx.DB) *Manager {
+	return &Manager{db: db, attempts: DefaultAttempts}
+}
+
+// Do runs fn in a transaction and commits it if fn succeeds. Called within a
+// unit of work, it joins the transaction already in ctx; retrying is then up
+// to the outermost call. fn may run more than once and should not have side
+// effects outside the database.
+func (m *Manager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := fromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; attempt <= m.attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(backoff(attempt)):
+			}
+		}
+
+		err = m.run(ctx, fn)
+		if !IsRetryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (m *Manager) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return err
+	}
+
+	if err := fn(withTx(ctx, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// IsRetryable reports whether err is a serialization failure or a deadlock,
+// after which the transaction may succeed if run again.
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
+
+// backoff returns a random delay before the given attempt, so that the
+// transactions that collided do not collide again.
+func backoff(attempt int) time.Duration {
+	return rand.N(retryBase << (attempt - 1))
+}
+
+// executor is what sqlx.DB and sqlx.Tx have in common that repositories use.
+type executor interface {
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error)
+}
+
+// DB runs queries in the transaction carried by the context, if any, and
+// maps their errors with storage.MapError.
+type DB struct {
+	db *sqlx.DB
+}
+
+func Wrap(db *sqlx.DB) *DB {
+	return &DB{db: db}
+}
+
+func (d *DB) executor(ctx context.Context) executor {
+	if tx, ok := fromContext(ctx); ok {
+		return tx
+	}
+	return d.db
+}
+
+func (d *DB) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return storage.MapError(d.executor(ctx).GetContext(ctx, dest, query, args...))
+}
+
+func (d *DB) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return storage.MapError(d.executor(ctx).SelectContext(ctx, dest, query, args...))
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	result, err := d.executor(ctx).ExecContext(ctx, query, args...)
+	return result, storage.MapError(err)
+}
+
+func (d *DB) NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error) {
+	result, err := d.executor(ctx).NamedExecContext(ctx, query, arg)
+	return result, storage.MapError(err)
+}
+
+// InTx runs fn in the transaction carried by the context, or else in a new
+// one with the given options that commits if fn succeeds. A repository
+// method that needs several statements uses it to stay atomic on its own
+// and still be part of a larger unit of work.
+func (d *DB) InTx(ctx context.Context, opts *sql.TxOptions, fn func(tx *sqlx.Tx) error) error {
+	if tx, ok := fromContext(ctx); ok {
+		return storage.MapError(fn(tx))
+	}
+
+	tx, err := d.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return storage.MapError(err)
+	}
+
+	// Deferred constraints are checked on commit.
+	return storage.MapError(tx.Commit())
+}
@@ -0,0 +1,239 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"home-library/pkg/storage"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMock(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return sqlx.NewDb(db, "sqlmock"), mock
+}
+
+func TestManagerDo(t *testing.T) {
+	t.Run("repository calls share the transaction", func(t *testing.T) {
+		db, mock := newMock(t)
+		manager, repo := NewManager(db), Wrap(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := manager.Do(context.Background(), func(ctx context.Context) error {
+			var count int
+			if err := repo.GetContext(ctx, &count, "SELECT COUNT(*) FROM users"); err != nil {
+				return err
+			}
+			_, err := repo.ExecContext(ctx, "INSERT INTO users DEFAULT VALUES")
+			return err
+		})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error rolls back", func(t *testing.T) {
+		db, mock := newMock(t)
+		manager, repo := NewManager(db), Wrap(db)
+		failure := errors.New("already exists")
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		err := manager.Do(context.Background(), func(ctx context.Context) error {
+			if _, err := repo.ExecContext(ctx, "INSERT INTO users DEFAULT VALUES"); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		assert.ErrorIs(t, err, failure)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("serialization failure runs the work again", func(t *testing.T) {
+		db, mock := newMock(t)
+		manager, repo := NewManager(db), Wrap(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO users").WillReturnError(&pq.Error{Code: "40001"})
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		runs := 0
+		err := manager.Do(context.Background(), func(ctx context.Context) error {
+			runs++
+			_, err := repo.ExecContext(ctx, "INSERT INTO users DEFAULT VALUES")
+			return err
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, runs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failing commit is retried too", func(t *testing.T) {
+		db, mock := newMock(t)
+		manager := NewManager(db)
+
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(&pq.Error{Code: "40001"})
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		err := manager.Do(context.Background(), func(ctx context.Context) error { return nil })
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("gives up after the attempts", func(t *testing.T) {
+		db, mock := newMock(t)
+		manager := NewManager(db)
+		manager.attempts = 2
+		deadlock := &pq.Error{Code: "40P01"}
+
+		for i := 0; i < 2; i++ {
+			mock.ExpectBegin()
+			mock.ExpectRollback()
+		}
+
+		err := manager.Do(context.Background(), func(ctx context.Context) error { return deadlock })
+
+		assert.ErrorIs(t, err, deadlock)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		db, mock := newMock(t)
+		manager := NewManager(db)
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		runs := 0
+		err := manager.Do(context.Background(), func(ctx context.Context) error {
+			runs++
+			return &pq.Error{Code: "23505"}
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, runs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nested call joins the outer transaction", func(t *testing.T) {
+		db, mock := newMock(t)
+		manager, repo := NewManager(db), Wrap(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO households").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO household_members").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := manager.Do(context.Background(), func(ctx context.Context) error {
+			if _, err := repo.ExecContext(ctx, "INSERT INTO households DEFAULT VALUES"); err != nil {
+				return err
+			}
+			return manager.Do(ctx, func(ctx context.Context) error {
+				_, err := repo.ExecContext(ctx, "INSERT INTO household_members DEFAULT VALUES")
+				return err
+			})
+		})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBInTx(t *testing.T) {
+	t.Run("begins its own transaction outside a unit of work", func(t *testing.T) {
+		db, mock := newMock(t)
+		repo := Wrap(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO outbox_events").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.InTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+			if _, err := tx.Exec("INSERT INTO users DEFAULT VALUES"); err != nil {
+				return err
+			}
+			_, err := tx.Exec("INSERT INTO outbox_events DEFAULT VALUES")
+			return err
+		})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("leaves commit to the unit of work", func(t *testing.T) {
+		db, mock := newMock(t)
+		manager, repo := NewManager(db), Wrap(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO users").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		failure := errors.New("later step failed")
+		err := manager.Do(context.Background(), func(ctx context.Context) error {
+			err := repo.InTx(ctx, nil, func(tx *sqlx.Tx) error {
+				_, err := tx.ExecContext(ctx, "INSERT INTO users DEFAULT VALUES")
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return failure
+		})
+
+		assert.ErrorIs(t, err, failure)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBMapsConstraintViolations(t *testing.T) {
+	db, mock := newMock(t)
+	repo := Wrap(db)
+
+	mock.ExpectExec("INSERT INTO users").WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_key"})
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users").WillReturnError(&pq.Error{Code: "23503", Constraint: "users_household_id_fkey"})
+	mock.ExpectRollback()
+
+	_, err := repo.ExecContext(context.Background(), "INSERT INTO users DEFAULT VALUES")
+	assert.ErrorIs(t, err, storage.ErrUniqueViolation)
+
+	err = repo.InTx(context.Background(), nil, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("INSERT INTO users DEFAULT VALUES")
+		return err
+	})
+	assert.ErrorIs(t, err, storage.ErrForeignKeyViolation)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(&pq.Error{Code: "40001"}))
+	assert.True(t, IsRetryable(&pq.Error{Code: "40P01"}))
+	assert.False(t, IsRetryable(&pq.Error{Code: "23505"}))
+	assert.False(t, IsRetryable(errors.New("boom")))
+	assert.False(t, IsRetryable(nil))
+}
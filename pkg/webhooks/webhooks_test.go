@@ -0,0 +1,85 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=b8569b78799ff9e3cbff0fc2d63a33a2b57f3282abd07c37ae5e8e7d79a5f163", Sign("secret", timestamp, []byte("{}")))
+	assert.NotEqual(t, Sign("secret", timestamp, []byte("{}")), Sign("secret", timestamp.Add(time.Second), []byte("{}")))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"type":"ebook.added"}`)
+
+	signed := func(secret string, timestamp time.Time) http.Header {
+		header := http.Header{}
+		header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+		header.Set(HeaderSignature, Sign(secret, timestamp, body))
+		return header
+	}
+
+	assert.NoError(t, Verify("secret", signed("secret", now.Add(-time.Minute)), body, 5*time.Minute, now))
+	assert.ErrorIs(t, Verify("secret", signed("other", now), body, 5*time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", signed("secret", now), []byte(`{}`), 5*time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", signed("secret", now.Add(-10*time.Minute)), body, 5*time.Minute, now), ErrExpired)
+	assert.ErrorIs(t, Verify("secret", http.Header{}, body, 5*time.Minute, now), ErrNoSignature)
+}
+
+func TestSend(t *testing.T) {
+	t.Run("signed delivery", func(t *testing.T) {
+		var received http.Header
+		var receivedBody []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Clone()
+			receivedBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		body := []byte(`{"type":"ebook.added"}`)
+		result := NewSender(time.Second).Send(context.Background(), server.URL, "secret", Message{EventID: "42", Type: "ebook.added", Body: body})
+
+		require.NoError(t, result.Err)
+		assert.Equal(t, http.StatusNoContent, result.StatusCode)
+		assert.Equal(t, body, receivedBody)
+		assert.Equal(t, "ebook.added", received.Get(HeaderEvent))
+		assert.Equal(t, "42", received.Get(HeaderEventID))
+		assert.NoError(t, Verify("secret", received, receivedBody, time.Minute, time.Now()))
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "nope", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		result := NewSender(time.Second).Send(context.Background(), server.URL, "secret", Message{Body: []byte(`{}`)})
+
+		assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+		assert.EqualError(t, result.Err, "unexpected response status 503 Service Unavailable")
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		result := NewSender(time.Second).Send(context.Background(), server.URL, "secret", Message{Body: []byte(`{}`)})
+
+		assert.Error(t, result.Err)
+		assert.Zero(t, result.StatusCode)
+	})
+}